@@ -62,7 +62,15 @@ func decisionsFromAlert(alert *models.Alert) string {
 	return ret
 }
 
-func (cli *cliAlerts) alertsToTable(alerts *models.GetAlertsResponse, printMachine bool) error {
+// alertsPage is the json output of a paginated list, with the cursor of the next page.
+type alertsPage struct {
+	Alerts     models.GetAlertsResponse `json:"alerts"`
+	NextCursor string                   `json:"next_cursor"`
+}
+
+// alertsToTable prints the alerts. When paged, the json output is an object with the alerts
+// and the cursor of the next page (empty after the last one), instead of a list.
+func (cli *cliAlerts) alertsToTable(alerts *models.GetAlertsResponse, printMachine bool, paged bool, nextCursor string) error {
 	cfg := cli.cfg()
 	switch cfg.Cscli.Output {
 	case "raw":
@@ -100,6 +108,18 @@ func (cli *cliAlerts) alertsToTable(alerts *models.GetAlertsResponse, printMachi
 
 		csvwriter.Flush()
 	case "json":
+		if paged {
+			page := alertsPage{Alerts: *alerts, NextCursor: nextCursor}
+			if page.Alerts == nil {
+				page.Alerts = models.GetAlertsResponse{}
+			}
+
+			x, _ := json.MarshalIndent(page, "", " ")
+			fmt.Fprint(os.Stdout, string(x))
+
+			return nil
+		}
+
 		if *alerts == nil {
 			// avoid returning "null" in json
 			// could be cleaner if we used slice of alerts directly
@@ -231,8 +251,10 @@ func (cli *cliAlerts) NewCommand() *cobra.Command {
 	}
 
 	cmd.AddCommand(cli.newListCmd())
+	cmd.AddCommand(cli.newStatsCmd())
 	cmd.AddCommand(cli.newInspectCmd())
 	cmd.AddCommand(cli.newFlushCmd())
+	cmd.AddCommand(cli.newImportArchiveCmd())
 	cmd.AddCommand(cli.newDeleteCmd())
 
 	return cmd
@@ -258,15 +280,22 @@ func (cli *cliAlerts) list(ctx context.Context, alertListFilter apiclient.Alerts
 		alertListFilter.Contains = new(bool)
 	}
 
-	alerts, _, err := cli.client.Alerts.List(ctx, alertListFilter)
+	alerts, resp, err := cli.client.Alerts.List(ctx, alertListFilter)
 	if err != nil {
 		return fmt.Errorf("unable to list alerts: %w", err)
 	}
 
-	if err = cli.alertsToTable(alerts, printMachine); err != nil {
+	// a list that fits in one page keeps its json format
+	paged := alertListFilter.Cursor != "" || resp.NextCursor != ""
+
+	if err = cli.alertsToTable(alerts, printMachine, paged, resp.NextCursor); err != nil {
 		return fmt.Errorf("unable to list alerts: %w", err)
 	}
 
+	if cli.cfg().Cscli.Output != "json" && resp.NextCursor != "" {
+		fmt.Fprintf(os.Stderr, "More alerts may be available, use --cursor %s for the next page\n", resp.NextCursor)
+	}
+
 	return nil
 }
 
@@ -288,7 +317,11 @@ func (cli *cliAlerts) newListCmd() *cobra.Command {
 	limit := new(int)
 	contained := new(bool)
 
-	var printMachine bool
+	var (
+		printMachine bool
+		minEvents    int
+		maxEvents    int
+	)
 
 	cmd := &cobra.Command{
 		Use:   "list [filters]",
@@ -298,11 +331,22 @@ cscli alerts list --ip 1.2.3.4
 cscli alerts list --range 1.2.3.0/24
 cscli alerts list --origin lists
 cscli alerts list -s crowdsecurity/ssh-bf
-cscli alerts list --type ban`,
+cscli alerts list --type ban
+cscli alerts list --machine-id mymachine --min-events 10
+cscli alerts list --meta target_fqdn=example.com
+cscli alerts list -l 1000 --cursor <value printed by the previous page>`,
 		Long:              `List alerts with optional filters`,
 		Args:              args.NoArgs,
 		DisableAutoGenTag: true,
 		RunE: func(cmd *cobra.Command, _ []string) error {
+			if cmd.Flags().Changed("min-events") {
+				alertListFilter.MinEvents = &minEvents
+			}
+
+			if cmd.Flags().Changed("max-events") {
+				alertListFilter.MaxEvents = &maxEvents
+			}
+
 			return cli.list(cmd.Context(), alertListFilter, limit, contained, printMachine)
 		},
 	}
@@ -320,9 +364,14 @@ cscli alerts list --type ban`,
 	flags.StringVarP(&alertListFilter.ValueEquals, "value", "v", "", "the value to match for in the specified scope")
 	flags.StringVar(&alertListFilter.OriginEquals, "origin", "", fmt.Sprintf("the value to match for the specified origin (%s ...)", strings.Join(types.GetOrigins(), ",")))
 	flags.StringVar(&alertListFilter.Kind, "kind", "", fmt.Sprintf("the value to match for the specified kind (%s ...)", strings.Join(types.GetAlertKinds(), ",")))
+	flags.StringVar(&alertListFilter.MachineIDEquals, "machine-id", "", "restrict to alerts sent by this machine")
+	flags.StringArrayVar(&alertListFilter.Meta, "meta", nil, "restrict to alerts with this meta, as key=value or key (can be repeated)")
+	flags.IntVar(&minEvents, "min-events", 0, "restrict to alerts with at least this number of events")
+	flags.IntVar(&maxEvents, "max-events", 0, "restrict to alerts with at most this number of events")
 	flags.BoolVar(contained, "contained", false, "query decisions contained by range")
 	flags.BoolVarP(&printMachine, "machine", "m", false, "print machines that sent alerts")
 	flags.IntVarP(limit, "limit", "l", 50, "limit size of alerts list table (0 to view all alerts)")
+	flags.StringVar(&alertListFilter.Cursor, "cursor", "", "list the alerts after this position, printed with the previous page (next_cursor in json)")
 
 	return cmd
 }
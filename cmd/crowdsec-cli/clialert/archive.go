@@ -0,0 +1,106 @@
+package clialert
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/crowdsecurity/crowdsec/cmd/crowdsec-cli/core/args"
+	"github.com/crowdsecurity/crowdsec/cmd/crowdsec-cli/core/require"
+)
+
+// parseArchiveTime reads a timestamp (RFC 3339) or a day (2006-01-02, UTC).
+// A day given as upper bound includes the whole day.
+func parseArchiveTime(s string, upper bool) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q, expected a date (2006-01-02) or RFC 3339 timestamp", s)
+	}
+
+	if upper {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+
+	return t, nil
+}
+
+func (cli *cliAlerts) newImportArchiveCmd() *cobra.Command {
+	var since, until string
+
+	cmd := &cobra.Command{
+		Use:   "import-archive file [file...]",
+		Short: "Import alerts from the flush archive",
+		Long: `Create again in the database the alerts written to the archive files when they were flushed,
+with their decisions, events and meta. The alerts are filtered on their start time.
+/!\ This command can be used only on the same machine than the local API`,
+		Example: `cscli alerts import-archive /var/lib/crowdsec/archive/alerts-2026-03-*.jsonl.gz
+cscli alerts import-archive --since 2026-03-01 --until 2026-03-07 /var/lib/crowdsec/archive/*.jsonl.gz`,
+		Args:              args.MinimumNArgs(1),
+		DisableAutoGenTag: true,
+		RunE: func(cmd *cobra.Command, files []string) error {
+			cfg := cli.cfg()
+			ctx := cmd.Context()
+
+			sinceTime, err := parseArchiveTime(since, false)
+			if err != nil {
+				return fmt.Errorf("--since: %w", err)
+			}
+
+			untilTime, err := parseArchiveTime(until, true)
+			if err != nil {
+				return fmt.Errorf("--until: %w", err)
+			}
+
+			if err := require.LAPI(cfg); err != nil {
+				return err
+			}
+
+			db, err := require.DBClient(ctx, cfg.DbConfig)
+			if err != nil {
+				return err
+			}
+
+			total := 0
+
+			for _, file := range files {
+				f, err := os.Open(file)
+				if err != nil {
+					return err
+				}
+
+				n, err := db.ImportAlertArchive(ctx, f, sinceTime, untilTime)
+				f.Close()
+
+				total += n
+
+				if err != nil {
+					return fmt.Errorf("importing %s: %w", file, err)
+				}
+
+				log.Infof("%s: imported %d alerts", file, n)
+			}
+
+			log.Infof("imported %d alerts", total)
+
+			return nil
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.SortFlags = false
+	flags.StringVar(&since, "since", "", "import alerts started at or after this date or time (ie. 2026-03-01, 2026-03-01T12:00:00Z)")
+	flags.StringVar(&until, "until", "", "import alerts started at or before this date or time (ie. 2026-03-07)")
+
+	return cmd
+}
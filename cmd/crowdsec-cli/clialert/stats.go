@@ -0,0 +1,149 @@
+package clialert
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/crowdsecurity/go-cs-lib/cstime"
+
+	"github.com/crowdsecurity/crowdsec/cmd/crowdsec-cli/core/args"
+	"github.com/crowdsecurity/crowdsec/cmd/crowdsec-cli/core/cstable"
+	"github.com/crowdsecurity/crowdsec/pkg/apiclient"
+	"github.com/crowdsecurity/crowdsec/pkg/models"
+)
+
+var statsGroups = []string{"scenario", "country", "as", "scope", "origin", "machine"}
+
+func statsHeader(stats *models.AlertStatsResponse) []string {
+	header := []string{}
+
+	if stats.Bucket != "" {
+		header = append(header, "start")
+	}
+
+	if stats.GroupBy != "" {
+		header = append(header, stats.GroupBy)
+	}
+
+	return append(header, "alerts", "decisions")
+}
+
+func statsRow(stats *models.AlertStatsResponse, b *models.AlertStatsBucket) []string {
+	row := []string{}
+
+	if stats.Bucket != "" {
+		row = append(row, b.Start)
+	}
+
+	if stats.GroupBy != "" {
+		row = append(row, b.Key)
+	}
+
+	return append(row, strconv.FormatInt(b.Alerts, 10), strconv.FormatInt(b.Decisions, 10))
+}
+
+func statsTable(out io.Writer, wantColor string, stats *models.AlertStatsResponse) {
+	t := cstable.New(out, wantColor)
+	t.SetRowLines(false)
+	t.SetHeaders(statsHeader(stats)...)
+
+	for _, b := range stats.Buckets {
+		row := statsRow(stats, b)
+		if stats.GroupBy != "" && b.Key == "" {
+			row[len(row)-3] = "-"
+		}
+
+		t.AddRow(row...)
+	}
+
+	t.Render()
+}
+
+func (cli *cliAlerts) stats(ctx context.Context, out io.Writer, opts apiclient.AlertsStatsOpts) error {
+	stats, _, err := cli.client.Alerts.Stats(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("unable to get alert statistics: %w", err)
+	}
+
+	cfg := cli.cfg()
+
+	switch cfg.Cscli.Output {
+	case "raw":
+		csvwriter := csv.NewWriter(out)
+
+		if err := csvwriter.Write(statsHeader(stats)); err != nil {
+			return err
+		}
+
+		for _, b := range stats.Buckets {
+			if err := csvwriter.Write(statsRow(stats, b)); err != nil {
+				return err
+			}
+		}
+
+		csvwriter.Flush()
+
+		return csvwriter.Error()
+	case "json":
+		x, err := json.MarshalIndent(stats, "", " ")
+		if err != nil {
+			return fmt.Errorf("failed to serialize statistics: %w", err)
+		}
+
+		fmt.Fprintln(out, string(x))
+	case "human":
+		if len(stats.Buckets) == 0 {
+			fmt.Fprintln(out, "No alerts")
+			return nil
+		}
+
+		statsTable(color.Output, cfg.Cscli.Color, stats)
+	}
+
+	return nil
+}
+
+func (cli *cliAlerts) newStatsCmd() *cobra.Command {
+	opts := apiclient.AlertsStatsOpts{
+		Since:       cstime.DurationWithDays(24 * time.Hour),
+		IncludeCAPI: new(bool),
+	}
+
+	cmd := &cobra.Command{
+		Use:   "stats [filters]",
+		Short: "Count alerts and decisions over time",
+		Long: `Count the alerts and their decisions, per time bucket and per scenario, country, AS...
+The counts are computed by the local API, the alerts are not downloaded.`,
+		Example: `cscli alerts stats --group-by scenario
+cscli alerts stats --since 7d --bucket 1d --group-by country
+cscli alerts stats --since 30d --bucket 1d -o json`,
+		Args:              args.NoArgs,
+		DisableAutoGenTag: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cli.stats(cmd.Context(), os.Stdout, opts)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.SortFlags = false
+	flags.StringVarP(&opts.GroupBy, "group-by", "g", "", "count per "+strings.Join(statsGroups, ", "))
+	flags.VarP(&opts.Bucket, "bucket", "b", "count per time bucket of this size, by alert start (ie. 1h, 1d)")
+	flags.Var(&opts.Since, "since", "restrict to alerts newer than since (ie. 4h, 30d)")
+	flags.Var(&opts.Until, "until", "restrict to alerts older than until (ie. 4h, 30d)")
+	flags.StringVarP(&opts.ScenarioEquals, "scenario", "s", "", "the scenario (ie. crowdsecurity/ssh-bf)")
+	flags.StringVar(&opts.TypeEquals, "type", "", "restrict to alerts with given decision type (ie. ban, captcha)")
+	flags.StringVar(&opts.OriginEquals, "origin", "", "restrict to alerts with decisions of this origin (ie. crowdsec, cscli, lists)")
+	flags.BoolVarP(opts.IncludeCAPI, "all", "a", false, "Include alerts from Central API")
+
+	return cmd
+}
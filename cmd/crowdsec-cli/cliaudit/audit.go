@@ -0,0 +1,229 @@
+package cliaudit
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+
+	"github.com/crowdsecurity/go-cs-lib/cstime"
+
+	"github.com/crowdsecurity/crowdsec/cmd/crowdsec-cli/core/args"
+	"github.com/crowdsecurity/crowdsec/cmd/crowdsec-cli/core/cstable"
+	"github.com/crowdsecurity/crowdsec/cmd/crowdsec-cli/core/require"
+	"github.com/crowdsecurity/crowdsec/pkg/csconfig"
+	"github.com/crowdsecurity/crowdsec/pkg/database"
+	"github.com/crowdsecurity/crowdsec/pkg/database/ent"
+)
+
+type cliAudit struct {
+	db  *database.Client
+	cfg csconfig.Getter
+}
+
+func New(cfg csconfig.Getter) *cliAudit {
+	return &cliAudit{
+		cfg: cfg,
+	}
+}
+
+func (cli *cliAudit) NewCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "audit [action]",
+		Short: "Show the changes made through the local API and cscli [requires local API]",
+		Long: `Show who added or deleted decisions or alerts, registered or validated machines, added bouncers or changed allowlists.
+Note: This command requires database direct access, so is intended to be run on Local API/master.
+`,
+		DisableAutoGenTag: true,
+		Args:              args.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cmd.Usage()
+		},
+		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+			var err error
+
+			cfg := cli.cfg()
+
+			if err = require.LAPI(cfg); err != nil {
+				return err
+			}
+
+			cli.db, err = require.DBClient(cmd.Context(), cfg.DbConfig)
+			if err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+
+	cmd.AddCommand(cli.newListCmd())
+
+	return cmd
+}
+
+// auditRecord is the json representation of an entry of the audit log
+type auditRecord struct {
+	CreatedAt time.Time `json:"created_at"`
+	ActorType string    `json:"actor_type"`
+	Actor     string    `json:"actor"`
+	IPAddress string    `json:"ip_address,omitempty"`
+	Action    string    `json:"action"`
+	Target    string    `json:"target,omitempty"`
+	Count     int       `json:"count"`
+}
+
+func newAuditRecord(r *ent.AuditLog) auditRecord {
+	return auditRecord{
+		CreatedAt: r.CreatedAt,
+		ActorType: string(r.ActorType),
+		Actor:     r.Actor,
+		IPAddress: r.IPAddress,
+		Action:    r.Action,
+		Target:    r.Target,
+		Count:     r.Count,
+	}
+}
+
+func (cli *cliAudit) listHuman(out io.Writer, records []*ent.AuditLog) {
+	if len(records) == 0 {
+		fmt.Fprintln(out, "No audit log records found.")
+		return
+	}
+
+	t := cstable.NewLight(out, cli.cfg().Cscli.Color).Writer
+	t.AppendHeader(table.Row{"Date", "Actor", "IP Address", "Action", "Target", "Count"})
+
+	for _, r := range records {
+		t.AppendRow(table.Row{
+			r.CreatedAt.Format(time.RFC3339),
+			string(r.ActorType) + ":" + r.Actor,
+			r.IPAddress,
+			r.Action,
+			r.Target,
+			r.Count,
+		})
+	}
+
+	fmt.Fprintln(out, t.Render())
+}
+
+func listCSV(out io.Writer, records []*ent.AuditLog) error {
+	csvwriter := csv.NewWriter(out)
+
+	if err := csvwriter.Write([]string{"created_at", "actor_type", "actor", "ip_address", "action", "target", "count"}); err != nil {
+		return fmt.Errorf("failed to write raw header: %w", err)
+	}
+
+	for _, r := range records {
+		row := []string{
+			r.CreatedAt.Format(time.RFC3339),
+			string(r.ActorType),
+			r.Actor,
+			r.IPAddress,
+			r.Action,
+			r.Target,
+			strconv.Itoa(r.Count),
+		}
+
+		if err := csvwriter.Write(row); err != nil {
+			return fmt.Errorf("failed to write raw: %w", err)
+		}
+	}
+
+	csvwriter.Flush()
+
+	return nil
+}
+
+type listFilter struct {
+	actorType string
+	actor     string
+	action    string
+	since     cstime.DurationWithDays
+	until     cstime.DurationWithDays
+	limit     int
+}
+
+func (cli *cliAudit) list(ctx context.Context, out io.Writer, f listFilter) error {
+	filter := database.AuditLogFilter{
+		ActorType: f.actorType,
+		Actor:     f.actor,
+		Action:    f.action,
+		Limit:     f.limit,
+	}
+
+	now := time.Now().UTC()
+
+	if f.since != 0 {
+		filter.Since = now.Add(-time.Duration(f.since))
+	}
+
+	if f.until != 0 {
+		filter.Until = now.Add(-time.Duration(f.until))
+	}
+
+	records, err := cli.db.QueryAuditLog(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	switch cli.cfg().Cscli.Output {
+	case "human":
+		cli.listHuman(out, records)
+	case "json":
+		info := make([]auditRecord, 0, len(records))
+		for _, r := range records {
+			info = append(info, newAuditRecord(r))
+		}
+
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+
+		if err := enc.Encode(info); err != nil {
+			return errors.New("failed to serialize")
+		}
+	case "raw":
+		return listCSV(out, records)
+	}
+
+	return nil
+}
+
+func (cli *cliAudit) newListCmd() *cobra.Command {
+	filter := listFilter{
+		limit: 100,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "list the audit log, most recent first",
+		Example: `cscli audit list
+cscli audit list --action decisions.delete --since 24h
+cscli audit list --actor-type machine --actor mywebserver
+cscli audit list --action allowlists --limit 0 -o json > audit.json`,
+		Args:              args.NoArgs,
+		DisableAutoGenTag: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cli.list(cmd.Context(), color.Output, filter)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&filter.actorType, "actor-type", "", "restrict to an actor type (machine, bouncer, cscli, papi)")
+	flags.StringVar(&filter.actor, "actor", "", "restrict to an actor (machine id, bouncer name, user@host...)")
+	flags.StringVar(&filter.action, "action", "", "restrict to an action or a group of actions (ie. decisions.delete, allowlists)")
+	flags.Var(&filter.since, "since", "restrict to records newer than since (ie. 4h, 30d)")
+	flags.Var(&filter.until, "until", "restrict to records older than until (ie. 4h, 30d)")
+	flags.IntVarP(&filter.limit, "limit", "l", filter.limit, "number of records to show (0 for all)")
+
+	return cmd
+}
@@ -14,7 +14,7 @@ import (
 	"github.com/crowdsecurity/crowdsec/pkg/types"
 )
 
-func (cli *cliBouncers) add(ctx context.Context, bouncerName string, key string) error {
+func (cli *cliBouncers) add(ctx context.Context, bouncerName string, key string, restrictions bouncerRestrictions) error {
 	var err error
 
 	keyLength := 32
@@ -26,11 +26,17 @@ func (cli *cliBouncers) add(ctx context.Context, bouncerName string, key string)
 		}
 	}
 
-	_, err = cli.db.CreateBouncer(ctx, bouncerName, "", middlewares.HashSHA512(key), types.ApiKeyAuthType, false)
+	bouncer, err := cli.db.CreateBouncer(ctx, bouncerName, "", middlewares.HashSHA512(key), types.ApiKeyAuthType, false)
 	if err != nil {
 		return fmt.Errorf("unable to create bouncer: %w", err)
 	}
 
+	if !restrictions.empty() {
+		if err = cli.db.UpdateBouncerRestrictions(ctx, restrictions.scopes, restrictions.origins, restrictions.scenarios, bouncer.ID); err != nil {
+			return fmt.Errorf("unable to restrict bouncer: %w", err)
+		}
+	}
+
 	switch cli.cfg().Cscli.Output {
 	case "human":
 		fmt.Fprintf(os.Stdout, "API key for '%s':\n\n", bouncerName)
@@ -51,17 +57,21 @@ func (cli *cliBouncers) add(ctx context.Context, bouncerName string, key string)
 }
 
 func (cli *cliBouncers) newAddCmd() *cobra.Command {
-	var key string
+	var (
+		key          string
+		restrictions bouncerRestrictions
+	)
 
 	cmd := &cobra.Command{
 		Use:   "add MyBouncerName",
 		Short: "add a single bouncer to the database",
 		Example: `cscli bouncers add MyBouncerName
-cscli bouncers add MyBouncerName --key <random-key>`,
+cscli bouncers add MyBouncerName --key <random-key>
+cscli bouncers add MyEdgeBouncer --allowed-scopes ip,range --allowed-origins crowdsec,cscli`,
 		Args:              args.ExactArgs(1),
 		DisableAutoGenTag: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return cli.add(cmd.Context(), args[0], key)
+			return cli.add(cmd.Context(), args[0], key, restrictions)
 		},
 	}
 
@@ -69,6 +79,9 @@ cscli bouncers add MyBouncerName --key <random-key>`,
 	flags.StringP("length", "l", "", "length of the api key")
 	_ = flags.MarkDeprecated("length", "use --key instead")
 	flags.StringVarP(&key, "key", "k", "", "api key for the bouncer")
+	flags.StringSliceVar(&restrictions.scopes, "allowed-scopes", nil, "only send decisions with these scopes (ip, range, country...)")
+	flags.StringSliceVar(&restrictions.origins, "allowed-origins", nil, "only send decisions with these origins (crowdsec, cscli, CAPI, lists...)")
+	flags.StringSliceVar(&restrictions.scenarios, "allowed-scenarios", nil, "only send decisions for these scenarios")
 
 	return cmd
 }
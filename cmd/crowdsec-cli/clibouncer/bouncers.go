@@ -62,6 +62,7 @@ Note: This command requires database direct access, so is intended to be run on
 	cmd.AddCommand(cli.newDeleteCmd())
 	cmd.AddCommand(cli.newPruneCmd())
 	cmd.AddCommand(cli.newInspectCmd())
+	cmd.AddCommand(cli.newRestrictCmd())
 
 	return cmd
 }
@@ -80,22 +81,29 @@ type bouncerInfo struct {
 	OS           string     `json:"os,omitempty"`
 	Featureflags []string   `json:"featureflags,omitempty"`
 	AutoCreated  bool       `json:"auto_created"`
+	// restrictions on the decisions sent to the bouncer
+	AllowedScopes    []string `json:"allowed_scopes,omitempty"`
+	AllowedOrigins   []string `json:"allowed_origins,omitempty"`
+	AllowedScenarios []string `json:"allowed_scenarios,omitempty"`
 }
 
 func newBouncerInfo(b *ent.Bouncer) bouncerInfo {
 	return bouncerInfo{
-		CreatedAt:    b.CreatedAt,
-		UpdatedAt:    b.UpdatedAt,
-		Name:         b.Name,
-		Revoked:      b.Revoked,
-		IPAddress:    b.IPAddress,
-		Type:         b.Type,
-		Version:      b.Version,
-		LastPull:     b.LastPull,
-		AuthType:     b.AuthType,
-		OS:           clientinfo.GetOSNameAndVersion(b),
-		Featureflags: clientinfo.GetFeatureFlagList(b),
-		AutoCreated:  b.AutoCreated,
+		CreatedAt:        b.CreatedAt,
+		UpdatedAt:        b.UpdatedAt,
+		Name:             b.Name,
+		Revoked:          b.Revoked,
+		IPAddress:        b.IPAddress,
+		Type:             b.Type,
+		Version:          b.Version,
+		LastPull:         b.LastPull,
+		AuthType:         b.AuthType,
+		OS:               clientinfo.GetOSNameAndVersion(b),
+		Featureflags:     clientinfo.GetFeatureFlagList(b),
+		AutoCreated:      b.AutoCreated,
+		AllowedScopes:    b.AllowedScopes,
+		AllowedOrigins:   b.AllowedOrigins,
+		AllowedScenarios: b.AllowedScenarios,
 	}
 }
 
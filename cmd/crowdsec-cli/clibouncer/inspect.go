@@ -48,6 +48,18 @@ func (cli *cliBouncers) inspectHuman(out io.Writer, bouncer *ent.Bouncer) {
 		t.AppendRow(table.Row{"Feature Flags", ff})
 	}
 
+	for _, scope := range bouncer.AllowedScopes {
+		t.AppendRow(table.Row{"Allowed Scopes", scope})
+	}
+
+	for _, origin := range bouncer.AllowedOrigins {
+		t.AppendRow(table.Row{"Allowed Origins", origin})
+	}
+
+	for _, scenario := range bouncer.AllowedScenarios {
+		t.AppendRow(table.Row{"Allowed Scenarios", scenario})
+	}
+
 	fmt.Fprint(out, t.Render())
 }
 
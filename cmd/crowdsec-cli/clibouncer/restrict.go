@@ -0,0 +1,90 @@
+package clibouncer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/crowdsecurity/crowdsec/cmd/crowdsec-cli/core/args"
+)
+
+// bouncerRestrictions are the scopes, origins and scenarios of the decisions a bouncer can get. Empty means no restriction.
+type bouncerRestrictions struct {
+	scopes    []string
+	origins   []string
+	scenarios []string
+}
+
+func (r bouncerRestrictions) empty() bool {
+	return len(r.scopes) == 0 && len(r.origins) == 0 && len(r.scenarios) == 0
+}
+
+func (cli *cliBouncers) restrict(ctx context.Context, bouncerName string, restrictions bouncerRestrictions, changed func(string) bool) error {
+	bouncer, err := cli.db.SelectBouncerByName(ctx, bouncerName)
+	if err != nil {
+		return fmt.Errorf("unable to read bouncer '%s': %w", bouncerName, err)
+	}
+
+	// keep the restrictions that are not on the command line
+	if !changed("scopes") {
+		restrictions.scopes = bouncer.AllowedScopes
+	}
+
+	if !changed("origins") {
+		restrictions.origins = bouncer.AllowedOrigins
+	}
+
+	if !changed("scenarios") {
+		restrictions.scenarios = bouncer.AllowedScenarios
+	}
+
+	if err := cli.db.UpdateBouncerRestrictions(ctx, restrictions.scopes, restrictions.origins, restrictions.scenarios, bouncer.ID); err != nil {
+		return fmt.Errorf("unable to restrict bouncer '%s': %w", bouncerName, err)
+	}
+
+	if restrictions.empty() {
+		log.Infof("bouncer '%s' can now get all the decisions", bouncerName)
+		return nil
+	}
+
+	log.Infof("bouncer '%s' restrictions updated", bouncerName)
+
+	return nil
+}
+
+func (cli *cliBouncers) newRestrictCmd() *cobra.Command {
+	var restrictions bouncerRestrictions
+
+	cmd := &cobra.Command{
+		Use:   "restrict MyBouncerName",
+		Short: "restrict the decisions a bouncer can get",
+		Long: `Restrict the decisions a bouncer can get to some scopes, origins or scenarios.
+The restrictions are applied by the local API and can't be overridden by the bouncer.
+Use an empty value to remove a restriction.`,
+		Example: `cscli bouncers restrict MyBouncerName --scopes ip,range
+cscli bouncers restrict MyBouncerName --origins crowdsec,cscli --scenarios crowdsecurity/http-probing
+cscli bouncers restrict MyBouncerName --scopes "" --origins "" --scenarios ""`,
+		Args:              args.ExactArgs(1),
+		DisableAutoGenTag: true,
+		ValidArgsFunction: cli.validBouncerID,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			flags := cmd.Flags()
+
+			if !flags.Changed("scopes") && !flags.Changed("origins") && !flags.Changed("scenarios") {
+				return errors.New("please specify at least one of --scopes, --origins or --scenarios")
+			}
+
+			return cli.restrict(cmd.Context(), args[0], restrictions, flags.Changed)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringSliceVar(&restrictions.scopes, "scopes", nil, "only send decisions with these scopes (ip, range, country...)")
+	flags.StringSliceVar(&restrictions.origins, "origins", nil, "only send decisions with these origins (crowdsec, cscli, CAPI, lists...)")
+	flags.StringSliceVar(&restrictions.scenarios, "scenarios", nil, "only send decisions for these scenarios")
+
+	return cmd
+}
@@ -0,0 +1,149 @@
+package clidatabase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/jedib0t/go-pretty/v6/table"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/crowdsecurity/crowdsec/cmd/crowdsec-cli/core/args"
+	"github.com/crowdsecurity/crowdsec/cmd/crowdsec-cli/core/cstable"
+	"github.com/crowdsecurity/crowdsec/cmd/crowdsec-cli/core/require"
+	"github.com/crowdsecurity/crowdsec/pkg/csconfig"
+	"github.com/crowdsecurity/crowdsec/pkg/database"
+)
+
+type cliDatabase struct {
+	cfg csconfig.Getter
+}
+
+func New(cfg csconfig.Getter) *cliDatabase {
+	return &cliDatabase{
+		cfg: cfg,
+	}
+}
+
+func (cli *cliDatabase) NewCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "database [action]",
+		Short: "Manage the local API database [requires local API]",
+		Long: `Manage the database of the local API.
+Note: This command requires database direct access, so is intended to be run on Local API/master.
+`,
+		DisableAutoGenTag: true,
+		Args:              args.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cmd.Usage()
+		},
+		PersistentPreRunE: func(_ *cobra.Command, _ []string) error {
+			return require.LAPI(cli.cfg())
+		},
+	}
+
+	cmd.AddCommand(cli.newMigrateCmd())
+
+	return cmd
+}
+
+func (cli *cliDatabase) migrateHuman(out io.Writer, results []database.MigrateResult) {
+	t := cstable.NewLight(out, cli.cfg().Cscli.Color).Writer
+	t.AppendHeader(table.Row{"Entity", "Copied", "Source", "Target"})
+
+	for _, r := range results {
+		t.AppendRow(table.Row{r.Entity, r.Copied, r.Source, r.Target})
+	}
+
+	fmt.Fprintln(out, t.Render())
+}
+
+func (cli *cliDatabase) migrate(ctx context.Context, out io.Writer, to string, batchSize int) error {
+	cfg := cli.cfg()
+
+	target, err := csconfig.LoadDatabaseCfg(to)
+	if err != nil {
+		return fmt.Errorf("loading target database configuration: %w", err)
+	}
+
+	src, err := require.DBClient(ctx, cfg.DbConfig)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := database.NewClient(ctx, target, target.NewLogger())
+	if err != nil {
+		return fmt.Errorf("failed to connect to the target database: %w", err)
+	}
+	defer dst.Close()
+
+	log.Infof("copying the %s database to %s", cfg.DbConfig.Type, target.Type)
+
+	results, err := src.MigrateTo(ctx, dst, batchSize, func(entity string, copied int) {
+		log.Debugf("%s: %d copied", entity, copied)
+	})
+	if err != nil {
+		return fmt.Errorf("migration interrupted, run the command again to resume: %w", err)
+	}
+
+	switch cfg.Cscli.Output {
+	case "human":
+		cli.migrateHuman(color.Output, results)
+	case "json", "raw":
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+
+		if err := enc.Encode(results); err != nil {
+			return errors.New("failed to serialize")
+		}
+	}
+
+	for _, r := range results {
+		if r.Source != r.Target {
+			return fmt.Errorf("%s: %d in the source database, %d in the target: was the source modified during the migration?",
+				r.Entity, r.Source, r.Target)
+		}
+	}
+
+	return nil
+}
+
+func (cli *cliDatabase) newMigrateCmd() *cobra.Command {
+	var (
+		to        string
+		batchSize int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "migrate --to <file>",
+		Short: "Copy the database to another backend",
+		Long: `Copy the machines, bouncers, allowlists, alerts with their decisions, events and meta, metrics and
+audit log to another database, for example from SQLite to PostgreSQL or MySQL.
+
+The target database is described by a file with the same content as the db_config section of the configuration.
+Its schema is created if needed, and it must be empty. The copy is made by batches: if it is interrupted,
+run the command again to resume. Running it again later also copies the rows created in the meantime.
+
+Stop the local API during the migration, then point db_config to the new database.`,
+		Example:           `cscli database migrate --to /etc/crowdsec/postgres.yaml`,
+		Args:              args.NoArgs,
+		DisableAutoGenTag: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cli.migrate(cmd.Context(), os.Stdout, to, batchSize)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&to, "to", "", "configuration file of the target database")
+	flags.IntVar(&batchSize, "batch-size", database.DefaultMigrateBatchSize, "number of rows copied in each transaction")
+
+	_ = cmd.MarkFlagRequired("to")
+
+	return cmd
+}
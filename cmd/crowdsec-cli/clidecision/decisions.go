@@ -167,6 +167,7 @@ func (cli *cliDecisions) NewCommand() *cobra.Command {
 	cmd.AddCommand(cli.newAddCmd())
 	cmd.AddCommand(cli.newDeleteCmd())
 	cmd.AddCommand(cli.newImportCmd())
+	cmd.AddCommand(cli.newExportCmd())
 
 	return cmd
 }
@@ -0,0 +1,95 @@
+package clidecision
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/crowdsecurity/crowdsec/cmd/crowdsec-cli/core/args"
+	"github.com/crowdsecurity/crowdsec/pkg/apiclient"
+	"github.com/crowdsecurity/crowdsec/pkg/decisionexport"
+	"github.com/crowdsecurity/crowdsec/pkg/models"
+)
+
+func (cli *cliDecisions) export(ctx context.Context, out io.Writer, filter apiclient.AlertsListOpts, format string, name string) error {
+	opts := decisionexport.Options{
+		Name: name,
+		Now:  time.Now().UTC(),
+	}
+
+	if err := decisionexport.Validate(format, opts); err != nil {
+		return err
+	}
+
+	filter.ActiveDecisionEquals = new(bool)
+	*filter.ActiveDecisionEquals = true
+	filter.IncludeCAPI = new(bool)
+	*filter.IncludeCAPI = true
+	filter.Limit = new(int)
+
+	alerts, _, err := cli.client.Alerts.List(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("unable to retrieve decisions: %w", err)
+	}
+
+	decisions := []*models.Decision{}
+
+	for _, alert := range *alerts {
+		for _, decision := range alert.Decisions {
+			// an alert can have decisions of several types and origins
+			if filter.TypeEquals != "" && *decision.Type != filter.TypeEquals {
+				continue
+			}
+
+			if filter.OriginEquals != "" && *decision.Origin != filter.OriginEquals {
+				continue
+			}
+
+			decisions = append(decisions, decision)
+		}
+	}
+
+	return decisionexport.Render(out, format, decisions, opts)
+}
+
+func (cli *cliDecisions) newExportCmd() *cobra.Command {
+	filter := apiclient.AlertsListOpts{
+		TypeEquals: "ban",
+	}
+
+	var (
+		format string
+		name   string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "export [options]",
+		Short: "Export the active decisions on ip and ranges as a blocklist",
+		Long: `Export the active decisions on ip and ranges, in a format that a firewall or a DNS server can load.
+The local API can serve the same lists to the bouncers, at /v1/decisions/export.`,
+		Example: `cscli decisions export --format nftables | nft -f -
+cscli decisions export --format ipset --name blocklist | ipset restore
+cscli decisions export --format rpz --origin lists > /etc/bind/crowdsec.rpz`,
+		Args:              args.NoArgs,
+		DisableAutoGenTag: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cli.export(cmd.Context(), os.Stdout, filter, format, name)
+		},
+	}
+
+	flags := cmd.Flags()
+
+	flags.SortFlags = false
+	flags.StringVarP(&format, "format", "f", decisionexport.FormatPlain, "format of the list ("+strings.Join(decisionexport.Formats, ", ")+")")
+	flags.StringVar(&name, "name", decisionexport.DefaultName, "name of the sets or address list")
+	flags.StringVarP(&filter.TypeEquals, "type", "t", filter.TypeEquals, "restrict to this decision type (empty for all)")
+	flags.StringVar(&filter.OriginEquals, "origin", "", "restrict to this origin (ie. crowdsec, cscli, lists)")
+	flags.StringVarP(&filter.ScenarioEquals, "scenario", "s", "", "restrict to this scenario (ie. crowdsecurity/ssh-bf)")
+
+	return cmd
+}
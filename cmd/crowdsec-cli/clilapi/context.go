@@ -339,6 +339,21 @@ func detectNode(node parser.Node, parserCTX parser.UnixParserCtx) []string {
 		}
 	}
 
+	if node.RuntimeStructured != nil {
+		for _, parsedField := range node.RuntimeStructured.Names() {
+			fieldName := "evt.Parsed." + parsedField
+			if !slices.Contains(ret, fieldName) {
+				ret = append(ret, fieldName)
+			}
+		}
+
+		for _, staticField := range detectStaticField(node.RuntimeStructured.Config.Statics) {
+			if !slices.Contains(ret, staticField) {
+				ret = append(ret, staticField)
+			}
+		}
+	}
+
 	if len(node.Grok.Statics) > 0 {
 		staticsField := detectStaticField(node.Grok.Statics)
 		for _, staticField := range staticsField {
@@ -386,6 +401,21 @@ func detectSubNode(node parser.Node, parserCTX parser.UnixParserCtx) []string {
 			}
 		}
 
+		if subnode.RuntimeStructured != nil {
+			for _, parsedField := range subnode.RuntimeStructured.Names() {
+				fieldName := "evt.Parsed." + parsedField
+				if !slices.Contains(ret, fieldName) {
+					ret = append(ret, fieldName)
+				}
+			}
+
+			for _, staticField := range detectStaticField(subnode.RuntimeStructured.Config.Statics) {
+				if !slices.Contains(ret, staticField) {
+					ret = append(ret, staticField)
+				}
+			}
+		}
+
 		if len(subnode.Grok.Statics) > 0 {
 			staticsField := detectStaticField(subnode.Grok.Statics)
 			for _, staticField := range staticsField {
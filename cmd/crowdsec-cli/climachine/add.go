@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/go-openapi/strfmt"
@@ -17,7 +18,7 @@ import (
 	"github.com/crowdsecurity/crowdsec/pkg/types"
 )
 
-func (cli *cliMachines) add(ctx context.Context, args []string, machinePassword string, dumpFile string, apiURL string, interactive bool, autoAdd bool, force bool) error {
+func (cli *cliMachines) add(ctx context.Context, args []string, machinePassword string, dumpFile string, apiURL string, interactive bool, autoAdd bool, force bool, roles []string) error {
 	var (
 		err       error
 		machineID string
@@ -37,6 +38,10 @@ func (cli *cliMachines) add(ctx context.Context, args []string, machinePassword
 		machineID = args[0]
 	}
 
+	if err = types.ValidateRoles(roles); err != nil {
+		return err
+	}
+
 	clientCfg := cli.cfg().API.Client
 	serverCfg := cli.cfg().API.Server
 
@@ -86,6 +91,12 @@ func (cli *cliMachines) add(ctx context.Context, args []string, machinePassword
 		return fmt.Errorf("unable to create machine: %w", err)
 	}
 
+	if len(roles) > 0 {
+		if err = cli.db.UpdateMachineRoles(ctx, machineID, roles); err != nil {
+			return fmt.Errorf("unable to set roles of machine '%s': %w", machineID, err)
+		}
+	}
+
 	fmt.Fprintf(os.Stderr, "Machine '%s' successfully added to the local API.\n", machineID)
 
 	if apiURL == "" {
@@ -130,6 +141,7 @@ func (cli *cliMachines) newAddCmd() *cobra.Command {
 		interactive bool
 		autoAdd     bool
 		force       bool
+		roles       []string
 	)
 
 	cmd := &cobra.Command{
@@ -141,9 +153,10 @@ func (cli *cliMachines) newAddCmd() *cobra.Command {
 		Example: `cscli machines add --auto
 cscli machines add MyTestMachine --auto
 cscli machines add MyTestMachine --password MyPassword
-cscli machines add -f- --auto > /tmp/mycreds.yaml`,
+cscli machines add -f- --auto > /tmp/mycreds.yaml
+cscli machines add MyWebServer --auto --roles agent`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return cli.add(cmd.Context(), args, string(password), dumpFile, apiURL, interactive, autoAdd, force)
+			return cli.add(cmd.Context(), args, string(password), dumpFile, apiURL, interactive, autoAdd, force, roles)
 		},
 	}
 
@@ -154,6 +167,7 @@ cscli machines add -f- --auto > /tmp/mycreds.yaml`,
 	flags.BoolVarP(&interactive, "interactive", "i", false, "interactive mode to enter the password")
 	flags.BoolVarP(&autoAdd, "auto", "a", false, "automatically generate password (and username if not provided)")
 	flags.BoolVar(&force, "force", false, "will force add the machine if it already exists")
+	flags.StringSliceVar(&roles, "roles", nil, "roles of the machine, to restrict its permissions ("+strings.Join(types.Roles(), ", ")+"). No role means all permissions")
 
 	return cmd
 }
@@ -19,6 +19,7 @@ import (
 	"github.com/crowdsecurity/crowdsec/pkg/cwhub"
 	"github.com/crowdsecurity/crowdsec/pkg/database/ent"
 	"github.com/crowdsecurity/crowdsec/pkg/models"
+	"github.com/crowdsecurity/crowdsec/pkg/types"
 )
 
 // metricsInfo contains processed metrics data for JSON output
@@ -93,6 +94,14 @@ func (cli *cliMachines) inspectHuman(out io.Writer, machine *ent.Machine) {
 		{"Auth type", machine.AuthType},
 	})
 
+	if len(machine.Roles) == 0 {
+		t.AppendRow(table.Row{"Roles", types.RoleAdmin + " (default)"})
+	}
+
+	for _, role := range machine.Roles {
+		t.AppendRow(table.Row{"Roles", role})
+	}
+
 	for dsName, dsCount := range machine.Datasources {
 		t.AppendRow(table.Row{"Datasources", fmt.Sprintf("%s: %d", dsName, dsCount)})
 	}
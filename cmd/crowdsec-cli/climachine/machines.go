@@ -58,6 +58,7 @@ Note: This command requires database direct access, so is intended to be run on
 	cmd.AddCommand(cli.newAddCmd())
 	cmd.AddCommand(cli.newDeleteCmd())
 	cmd.AddCommand(cli.newValidateCmd())
+	cmd.AddCommand(cli.newSetRolesCmd())
 	cmd.AddCommand(cli.newPruneCmd())
 	cmd.AddCommand(cli.newInspectCmd())
 
@@ -78,6 +79,7 @@ type machineInfo struct {
 	OS            string           `json:"os,omitempty"`
 	Featureflags  []string         `json:"featureflags,omitempty"`
 	Datasources   map[string]int64 `json:"datasources,omitempty"`
+	Roles         []string         `json:"roles,omitempty"`
 }
 
 func newMachineInfo(m *ent.Machine) machineInfo {
@@ -94,6 +96,7 @@ func newMachineInfo(m *ent.Machine) machineInfo {
 		OS:            clientinfo.GetOSNameAndVersion(m),
 		Featureflags:  clientinfo.GetFeatureFlagList(m),
 		Datasources:   m.Datasources,
+		Roles:         m.Roles,
 	}
 }
 
@@ -0,0 +1,66 @@
+package climachine
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/crowdsecurity/crowdsec/cmd/crowdsec-cli/core/args"
+	"github.com/crowdsecurity/crowdsec/pkg/types"
+)
+
+func (cli *cliMachines) setRoles(ctx context.Context, machineID string, roles []string) error {
+	if err := types.ValidateRoles(roles); err != nil {
+		return err
+	}
+
+	if err := cli.db.UpdateMachineRoles(ctx, machineID, roles); err != nil {
+		return fmt.Errorf("unable to set roles of machine '%s': %w", machineID, err)
+	}
+
+	if len(roles) == 0 {
+		log.Infof("machine '%s' has no role anymore: it has all the permissions", machineID)
+		return nil
+	}
+
+	log.Infof("machine '%s' roles set to: %s", machineID, strings.Join(roles, ", "))
+
+	return nil
+}
+
+func (cli *cliMachines) newSetRolesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set-roles MACHINE [ROLE...]",
+		Short: "set the roles of a machine",
+		Long: `Set the roles of a machine, to restrict what it can do on the local API.
+Without role, the machine has all the permissions.
+The roles are read when the machine logs in: a change takes effect
+with its next token, within an hour.
+
+Roles:
+  admin           all permissions
+  agent           push alerts, check allowlists (log processors)
+  viewer          read alerts, check allowlists
+  decision-admin  push and read alerts, delete decisions`,
+		Example: `cscli machines set-roles mywebserver agent
+cscli machines set-roles dashboard viewer
+cscli machines set-roles mywebserver`,
+		Args:              args.MinimumNArgs(1),
+		DisableAutoGenTag: true,
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) == 0 {
+				return cli.validMachineID(cmd, args, toComplete)
+			}
+
+			return types.Roles(), cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cli.setRoles(cmd.Context(), args[0], args[1:])
+		},
+	}
+
+	return cmd
+}
@@ -22,7 +22,8 @@ type MetricPoint struct {
 // parseMetrics is a helper intended as a lightweight replacement for the prom2json
 // package inside cscli.
 //
-// Only counter, gauge and untyped metrics are returned.
+// Only counter, gauge and untyped metrics are returned, and the sum and
+// count of the histograms.
 // Aggregation and unit convversions are left to the caller.
 func parseMetrics(r io.Reader) ([]MetricPoint, error) {
 	parser := expfmt.NewTextParser(model.UTF8Validation)
@@ -52,8 +53,18 @@ func parseMetrics(r io.Reader) ([]MetricPoint, error) {
 				point.Value = m.GetGauge().GetValue()
 			case dto.MetricType_UNTYPED:
 				point.Value = m.GetUntyped().GetValue()
+			case dto.MetricType_HISTOGRAM:
+				// as the _sum and _count series, the buckets are not used
+				count := point
+				count.Name = name + "_count"
+				count.Value = float64(m.GetHistogram().GetSampleCount())
+
+				point.Name = name + "_sum"
+				point.Value = m.GetHistogram().GetSampleSum()
+
+				out = append(out, count)
 			default:
-				continue // skip histograms/summaries, we don't have them in cscli
+				continue // skip summaries, we don't have them in cscli
 			}
 
 			out = append(out, point)
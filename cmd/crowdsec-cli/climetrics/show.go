@@ -71,7 +71,9 @@ func expandAlias(args []string) []string {
 	for _, section := range args {
 		switch section {
 		case "engine":
-			ret = append(ret, "acquisition", "parsers", "scenarios", "stash", "whitelists")
+			ret = append(ret, "acquisition", "parsers", "scenarios", "stash", "whitelists", "whitelist-entries", "expressions")
+		case "whitelists":
+			ret = append(ret, "whitelists", "whitelist-entries")
 		case "lapi":
 			ret = append(ret, "alerts", "decisions", "lapi", "lapi-bouncer", "lapi-decisions", "lapi-machine")
 		case "appsec":
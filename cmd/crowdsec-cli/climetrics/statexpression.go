@@ -0,0 +1,117 @@
+package climetrics
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+
+	"github.com/crowdsecurity/crowdsec/cmd/crowdsec-cli/core/cstable"
+)
+
+type expressionStats struct {
+	Evaluations int     `json:"evaluations"`
+	Seconds     float64 `json:"seconds"`
+	OverTime    int     `json:"over_time_budget"`
+	OverSteps   int     `json:"over_step_budget"`
+}
+
+// by item, then field
+type statExpression map[string]map[string]*expressionStats
+
+func (statExpression) Description() (string, string) {
+	return "Expression Metrics",
+		`Time spent in the expressions of the parsers and scenarios, the slowest first, and the evaluations aborted over budget. ` +
+			`The durations are only collected with crowdsec_service.expressions.profile.`
+}
+
+func (s statExpression) get(item, field string) *expressionStats {
+	if _, ok := s[item]; !ok {
+		s[item] = make(map[string]*expressionStats)
+	}
+
+	if _, ok := s[item][field]; !ok {
+		s[item][field] = &expressionStats{}
+	}
+
+	return s[item][field]
+}
+
+func (s statExpression) Process(item, field, metric string, val float64) {
+	stats := s.get(item, field)
+
+	switch metric {
+	case "evaluations":
+		stats.Evaluations += int(val)
+	case "seconds":
+		stats.Seconds += val
+	case "over_time":
+		stats.OverTime += int(val)
+	case "over_steps":
+		stats.OverSteps += int(val)
+	}
+}
+
+func formatSeconds(seconds float64) string {
+	return time.Duration(seconds * float64(time.Second)).Round(time.Microsecond).String()
+}
+
+func (s statExpression) Table(out io.Writer, wantColor string, noUnit bool, showEmpty bool) {
+	t := cstable.New(out, wantColor).Writer
+	t.AppendHeader(table.Row{"Item", "Field", "Evaluations", "Total Time", "Share", "Average", "Over Time Budget", "Over Step Budget"})
+
+	type row struct {
+		item  string
+		field string
+		stats *expressionStats
+	}
+
+	rows := []row{}
+	total := 0.0
+
+	for item, fields := range s {
+		for field, stats := range fields {
+			rows = append(rows, row{item: item, field: field, stats: stats})
+			total += stats.Seconds
+		}
+	}
+
+	// the slowest first
+	slices.SortFunc(rows, func(a, b row) int {
+		return cmp.Or(cmp.Compare(b.stats.Seconds, a.stats.Seconds), cmp.Compare(a.item, b.item), cmp.Compare(a.field, b.field))
+	})
+
+	for _, r := range rows {
+		share := "-"
+		average := "-"
+
+		if total > 0 {
+			share = fmt.Sprintf("%.1f%%", 100*r.stats.Seconds/total)
+		}
+
+		if r.stats.Evaluations > 0 {
+			average = formatSeconds(r.stats.Seconds / float64(r.stats.Evaluations))
+		}
+
+		t.AppendRow(table.Row{
+			r.item,
+			r.field,
+			formatNumber(int64(r.stats.Evaluations), !noUnit),
+			formatSeconds(r.stats.Seconds),
+			share,
+			average,
+			strconv.Itoa(r.stats.OverTime),
+			strconv.Itoa(r.stats.OverSteps),
+		})
+	}
+
+	if len(rows) > 0 || showEmpty {
+		title, _ := s.Description()
+		t.SetTitle(title)
+		fmt.Fprintln(out, t.Render())
+	}
+}
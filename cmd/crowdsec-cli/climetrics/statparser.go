@@ -29,9 +29,9 @@ func (s statParser) Process(parser, metric string, val int) {
 
 func (s statParser) Table(out io.Writer, wantColor string, noUnit bool, showEmpty bool) {
 	t := cstable.New(out, wantColor).Writer
-	t.AppendHeader(table.Row{"Parsers", "Hits", "Parsed", "Unparsed"})
+	t.AppendHeader(table.Row{"Parsers", "Hits", "Parsed", "Unparsed", "Grok skipped"})
 
-	keys := []string{"hits", "parsed", "unparsed"}
+	keys := []string{"hits", "parsed", "unparsed", "grok skipped"}
 
 	if numRows, err := metricsToTable(t, s, keys, noUnit); err != nil {
 		log.Warningf("while collecting parsers stats: %s", err)
@@ -0,0 +1,102 @@
+package climetrics
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+
+	"github.com/crowdsecurity/go-cs-lib/maptools"
+
+	"github.com/crowdsecurity/crowdsec/cmd/crowdsec-cli/core/cstable"
+)
+
+type whitelistEntryStats struct {
+	Hits      int `json:"hits"`
+	LastHit   int `json:"last_hit,omitempty"`   // unix timestamp
+	ExpiresAt int `json:"expires_at,omitempty"` // unix timestamp
+}
+
+// by whitelist, then entry
+type statWhitelistEntry map[string]map[string]*whitelistEntryStats
+
+func (statWhitelistEntry) Description() (string, string) {
+	return "Whitelist Entry Metrics",
+		`Tracks the number of events whitelisted by each entry of the parser whitelists, the last one and when the entry expires.`
+}
+
+func (s statWhitelistEntry) get(whitelist, id string) *whitelistEntryStats {
+	if _, ok := s[whitelist]; !ok {
+		s[whitelist] = make(map[string]*whitelistEntryStats)
+	}
+
+	if _, ok := s[whitelist][id]; !ok {
+		s[whitelist][id] = &whitelistEntryStats{}
+	}
+
+	return s[whitelist][id]
+}
+
+func (s statWhitelistEntry) Process(whitelist, id, metric string, val int) {
+	entry := s.get(whitelist, id)
+
+	switch metric {
+	case "hits":
+		entry.Hits += val
+	case "last_hit":
+		entry.LastHit = max(entry.LastHit, val)
+	case "expires_at":
+		entry.ExpiresAt = val
+	}
+}
+
+func formatTimestamp(ts int, now time.Time) string {
+	if ts == 0 {
+		return "-"
+	}
+
+	t := time.Unix(int64(ts), 0)
+
+	if t.After(now) {
+		return t.Format(time.RFC3339) + " (in " + t.Sub(now).Round(time.Minute).String() + ")"
+	}
+
+	return t.Format(time.RFC3339) + " (" + now.Sub(t).Round(time.Minute).String() + " ago)"
+}
+
+func (s statWhitelistEntry) Table(out io.Writer, wantColor string, noUnit bool, showEmpty bool) {
+	t := cstable.New(out, wantColor).Writer
+	t.AppendHeader(table.Row{"Whitelist", "Entry", "Hits", "Last Hit", "Expires"})
+
+	now := time.Now()
+	numRows := 0
+
+	for _, whitelist := range maptools.SortedKeys(s) {
+		for _, id := range maptools.SortedKeys(s[whitelist]) {
+			entry := s[whitelist][id]
+
+			expires := formatTimestamp(entry.ExpiresAt, now)
+			if entry.ExpiresAt != 0 && int64(entry.ExpiresAt) <= now.Unix() {
+				expires = "expired " + expires
+			}
+
+			t.AppendRow(table.Row{
+				whitelist,
+				id,
+				strconv.Itoa(entry.Hits),
+				formatTimestamp(entry.LastHit, now),
+				expires,
+			})
+
+			numRows++
+		}
+	}
+
+	if numRows > 0 || showEmpty {
+		title, _ := s.Description()
+		t.SetTitle(title)
+		fmt.Fprintln(out, t.Render())
+	}
+}
@@ -32,6 +32,7 @@ func NewMetricStore() metricStore {
 		"appsec-challenge":       newStatAppsecChallenge(),
 		"appsec-challenge-infra": statAppsecChallengeInfra{},
 		"decisions":              statDecision{},
+		"expressions":            statExpression{},
 		"lapi":                   statLapi{},
 		"lapi-bouncer":           statLapiBouncer{},
 		"lapi-decisions":         statLapiDecision{},
@@ -40,6 +41,7 @@ func NewMetricStore() metricStore {
 		"scenarios":              statBucket{},
 		"stash":                  statStash{},
 		"whitelists":             statWhitelist{},
+		"whitelist-entries":      statWhitelistEntry{},
 	}
 }
 
@@ -78,6 +80,12 @@ type metricLabels struct {
 	// bundle is carried by the challenge re-obfuscation counter
 	// (dynamic vs library); empty for every other metric.
 	bundle string
+	// id is carried by the whitelist entry metrics
+	id string
+	// item, field and budget are carried by the expression metrics
+	item   string
+	field  string
+	budget string
 	mtype  string
 }
 
@@ -108,6 +116,10 @@ func extractLabels(p MetricPoint) metricLabels {
 		appsecRule:   p.Labels["rule_name"],
 		kind:         p.Labels["kind"],
 		bundle:       p.Labels["bundle"],
+		id:           p.Labels["id"],
+		item:         p.Labels["item"],
+		field:        p.Labels["field"],
+		budget:       p.Labels["budget"],
 		mtype:        p.Labels["type"],
 	}
 }
@@ -118,6 +130,7 @@ func (ms metricStore) processPrometheusMetrics(result []MetricPoint) {
 	mAppsecEngine := ms["appsec-engine"].(statAppsecEngine)
 	mAppsecRule := ms["appsec-rule"].(statAppsecRule)
 	mDecision := ms["decisions"].(statDecision)
+	mExpression := ms["expressions"].(statExpression)
 	mLapi := ms["lapi"].(statLapi)
 	mLapiBouncer := ms["lapi-bouncer"].(statLapiBouncer)
 	mLapiDecision := ms["lapi-decisions"].(statLapiDecision)
@@ -126,6 +139,7 @@ func (ms metricStore) processPrometheusMetrics(result []MetricPoint) {
 	mBucket := ms["scenarios"].(statBucket)
 	mStash := ms["stash"].(statStash)
 	mWhitelist := ms["whitelists"].(statWhitelist)
+	mWhitelistEntry := ms["whitelist-entries"].(statWhitelistEntry)
 
 	for _, p := range result {
 		if !strings.HasPrefix(p.Name, "cs_") {
@@ -166,6 +180,8 @@ func (ms metricStore) processPrometheusMetrics(result []MetricPoint) {
 			mParser.Process(l.name, "parsed", ival)
 		case metrics.NodesHitsKoMetricName:
 			mParser.Process(l.name, "unparsed", ival)
+		case metrics.NodesGrokSkippedMetricName:
+			mParser.Process(l.name, "grok skipped", ival)
 		//
 		// whitelists
 		//
@@ -175,6 +191,21 @@ func (ms metricStore) processPrometheusMetrics(result []MetricPoint) {
 			mWhitelist.Process(l.name, l.reason, "whitelisted", ival)
 			// track as well whitelisted lines at acquis level
 			mAcquis.Process(l.source, "whitelisted", ival)
+		case metrics.NodesWlEntryHitsMetricName:
+			mWhitelistEntry.Process(l.name, l.id, "hits", ival)
+		case metrics.NodesWlEntryLastHitMetricName:
+			mWhitelistEntry.Process(l.name, l.id, "last_hit", ival)
+		case metrics.NodesWlEntryExpiryMetricName:
+			mWhitelistEntry.Process(l.name, l.id, "expires_at", ival)
+		//
+		// expressions
+		//
+		case metrics.ExprDurationMetricName + "_count":
+			mExpression.Process(l.item, l.field, "evaluations", p.Value)
+		case metrics.ExprDurationMetricName + "_sum":
+			mExpression.Process(l.item, l.field, "seconds", p.Value)
+		case metrics.ExprBudgetExceededMetricName:
+			mExpression.Process(l.item, l.field, "over_"+l.budget, p.Value)
 		//
 		// lapi
 		//
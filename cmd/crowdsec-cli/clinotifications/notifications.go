@@ -299,7 +299,7 @@ func (cli *cliNotifications) newTestCmd() *cobra.Command {
 
 			if cfg.API.CTI != nil && cfg.API.CTI.Enabled != nil && *cfg.API.CTI.Enabled {
 				log.Infof("Crowdsec CTI helper enabled")
-				if err := ctiexpr.InitCrowdsecCTI(cfg.API.CTI.Key, cfg.API.CTI.CacheTimeout, cfg.API.CTI.CacheSize, cfg.API.CTI.LogLevel); err != nil {
+				if err := ctiexpr.InitCrowdsecCTI(cfg.API.CTI); err != nil {
 					log.Errorf("failed to init crowdsec cti: %s", err)
 				}
 			}
@@ -428,9 +428,17 @@ cscli notifications reinject <alert_id> -a '{"remediation": true,"scenario":"not
 				log.Warnf("no database client available, expr helpers will not be available")
 			}
 
+			if err := exprhelpers.EnrichersInit(cfg.Enrichers); err != nil {
+				log.Errorf("failed to init enrichers: %s", err)
+			}
+
+			if err := exprhelpers.ThreatIntelInit(ctx, cfg.ThreatIntel); err != nil {
+				log.Errorf("failed to init threat intel feeds: %s", err)
+			}
+
 			if cfg.API.CTI != nil && cfg.API.CTI.Enabled != nil && *cfg.API.CTI.Enabled {
 				log.Infof("Crowdsec CTI helper enabled")
-				if err := ctiexpr.InitCrowdsecCTI(cfg.API.CTI.Key, cfg.API.CTI.CacheTimeout, cfg.API.CTI.CacheSize, cfg.API.CTI.LogLevel); err != nil {
+				if err := ctiexpr.InitCrowdsecCTI(cfg.API.CTI); err != nil {
 					log.Errorf("failed to init crowdsec cti: %s", err)
 				}
 			}
@@ -0,0 +1,199 @@
+package clitap
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/crowdsecurity/crowdsec/cmd/crowdsec-cli/core/args"
+	"github.com/crowdsecurity/crowdsec/cmd/crowdsec-cli/core/require"
+	"github.com/crowdsecurity/crowdsec/pkg/csconfig"
+	"github.com/crowdsecurity/crowdsec/pkg/tap"
+)
+
+// errLimitReached stops the stream after --limit records
+var errLimitReached = errors.New("limit reached")
+
+type cliTap struct {
+	cfg csconfig.Getter
+}
+
+func New(cfg csconfig.Getter) *cliTap {
+	return &cliTap{
+		cfg: cfg,
+	}
+}
+
+func (cli *cliTap) NewCommand() *cobra.Command {
+	var (
+		opts   tap.StreamOptions
+		socket string
+		limit  int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "tap",
+		Short: "Show the events processed by the running log processor",
+		Long: `Show the events processed by the running log processor, as they are parsed and poured in the buckets.
+
+A "parse" record is sent after the parsers, with the nodes that were evaluated and the whitelist decision.
+A "pour" record is sent after the event has been poured in the buckets, with the scenarios.
+
+The tap must be enabled in the configuration (crowdsec_service.tap.listen_socket).
+If cscli can't keep up, the log processor drops the records instead of waiting.`,
+		Example: `cscli tap
+cscli tap --filter 'evt.Line.Src endsWith "/auth.log"'
+cscli tap --kind parse --filter '!evt.Process' --limit 10
+cscli tap --kind pour --filter 'evt.Meta.source_ip == "192.0.2.1"' -o json`,
+		Args:              args.NoArgs,
+		DisableAutoGenTag: true,
+		PreRunE: func(_ *cobra.Command, _ []string) error {
+			cfg := cli.cfg()
+
+			if err := require.Agent(cfg); err != nil {
+				return err
+			}
+
+			if socket == "" {
+				if cfg.Crowdsec.Tap == nil {
+					return errors.New("the event tap is not enabled: set crowdsec_service.tap.listen_socket, or use --socket")
+				}
+
+				socket = cfg.Crowdsec.Tap.ListenSocket
+			}
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cli.run(cmd.Context(), cmd.OutOrStdout(), socket, opts, limit)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&opts.Filter, "filter", "", "only show the events matching this expression (with evt, and the helpers without side effects)")
+	flags.StringVar(&opts.Kind, "kind", "", "only show the records of this kind ("+strings.Join(tap.Kinds, ", ")+")")
+	flags.IntVar(&opts.BufferSize, "buffer", 0, "number of records queued by the log processor before they are dropped (default: from the configuration)")
+	flags.StringVar(&socket, "socket", "", "path to the socket of the tap (default: from the configuration)")
+	flags.IntVar(&limit, "limit", 0, "stop after this number of records")
+
+	return cmd
+}
+
+func (cli *cliTap) run(ctx context.Context, out io.Writer, socket string, opts tap.StreamOptions, limit int) error {
+	output := cli.cfg().Cscli.Output
+
+	count := 0
+
+	if output == "human" {
+		printHeader(out)
+	}
+
+	err := tap.Stream(ctx, socket, opts, func(rec *tap.Record) error {
+		switch output {
+		case "human":
+			printHuman(out, rec)
+		default:
+			data, err := json.Marshal(rec)
+			if err != nil {
+				return fmt.Errorf("failed to serialize: %w", err)
+			}
+
+			fmt.Fprintln(out, string(data))
+		}
+
+		if rec.Kind == tap.KindDropped {
+			return nil
+		}
+
+		count++
+		if limit > 0 && count >= limit {
+			return errLimitReached
+		}
+
+		return nil
+	})
+	if errors.Is(err, errLimitReached) {
+		return nil
+	}
+
+	return err
+}
+
+const rowFormat = "%-12s  %-5s  %-30s  %-11s  %s\n"
+
+func printHeader(out io.Writer) {
+	fmt.Fprintf(out, rowFormat, "Time", "Kind", "Source", "Result", "Details")
+}
+
+// ellipsis keeps the end of the string, which is the most specific part of a path
+func ellipsis(s string, width int) string {
+	if len(s) <= width {
+		return s
+	}
+
+	return "…" + s[len(s)-width+1:]
+}
+
+func printHuman(out io.Writer, rec *tap.Record) {
+	ts := rec.Time.Local().Format("15:04:05.000")
+
+	if rec.Kind == tap.KindDropped {
+		fmt.Fprintf(out, rowFormat, ts, "", "", color.YellowString("%-11s", "dropped"), fmt.Sprintf("%d records", rec.Dropped))
+		return
+	}
+
+	src := ""
+	raw := ""
+
+	if rec.Event != nil {
+		src = rec.Event.Line.Src
+		raw = rec.Event.Line.Raw
+	}
+
+	var (
+		result  string
+		details []string
+	)
+
+	switch rec.Kind {
+	case tap.KindParse:
+		switch {
+		case rec.Whitelisted:
+			result = color.YellowString("%-11s", "whitelisted")
+
+			if rec.WhitelistReason != "" {
+				details = append(details, "reason: "+rec.WhitelistReason)
+			}
+		case rec.Parsed:
+			result = color.GreenString("%-11s", "parsed")
+		default:
+			result = color.RedString("%-11s", "unparsed")
+		}
+
+		for _, n := range rec.Nodes {
+			if n.Success {
+				details = append(details, n.Stage+"/"+n.Node)
+			}
+		}
+	case tap.KindPour:
+		if len(rec.Buckets) > 0 {
+			result = color.GreenString("%-11s", "poured")
+		} else {
+			result = color.RedString("%-11s", "no bucket")
+		}
+
+		details = rec.Buckets
+	}
+
+	fmt.Fprintf(out, rowFormat, ts, rec.Kind, ellipsis(src, 30), result, strings.Join(details, ", "))
+
+	if raw != "" && rec.Kind == tap.KindParse {
+		fmt.Fprintf(out, "%14s%s\n", "", strings.TrimRight(raw, "\n"))
+	}
+}
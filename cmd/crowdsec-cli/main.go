@@ -19,10 +19,12 @@ import (
 
 	"github.com/crowdsecurity/crowdsec/cmd/crowdsec-cli/clialert"
 	"github.com/crowdsecurity/crowdsec/cmd/crowdsec-cli/cliallowlists"
+	"github.com/crowdsecurity/crowdsec/cmd/crowdsec-cli/cliaudit"
 	"github.com/crowdsecurity/crowdsec/cmd/crowdsec-cli/clibouncer"
 	"github.com/crowdsecurity/crowdsec/cmd/crowdsec-cli/clicapi"
 	"github.com/crowdsecurity/crowdsec/cmd/crowdsec-cli/cliconfig"
 	"github.com/crowdsecurity/crowdsec/cmd/crowdsec-cli/cliconsole"
+	"github.com/crowdsecurity/crowdsec/cmd/crowdsec-cli/clidatabase"
 	"github.com/crowdsecurity/crowdsec/cmd/crowdsec-cli/clidecision"
 	"github.com/crowdsecurity/crowdsec/cmd/crowdsec-cli/cliexplain"
 	"github.com/crowdsecurity/crowdsec/cmd/crowdsec-cli/clihub"
@@ -35,8 +37,10 @@ import (
 	"github.com/crowdsecurity/crowdsec/cmd/crowdsec-cli/clipapi"
 	"github.com/crowdsecurity/crowdsec/cmd/crowdsec-cli/clisimulation"
 	"github.com/crowdsecurity/crowdsec/cmd/crowdsec-cli/clisupport"
+	"github.com/crowdsecurity/crowdsec/cmd/crowdsec-cli/clitap"
 	"github.com/crowdsecurity/crowdsec/cmd/crowdsec-cli/core/args"
 	"github.com/crowdsecurity/crowdsec/pkg/csconfig"
+	"github.com/crowdsecurity/crowdsec/pkg/database"
 	"github.com/crowdsecurity/crowdsec/pkg/fflag"
 )
 
@@ -304,6 +308,9 @@ It is meant to allow you to manage bans, parsers/scenarios/etc, api and generall
 	cmd.AddCommand(cliitem.NewAppsecConfig(cli.cfg).NewCommand())
 	cmd.AddCommand(cliitem.NewAppsecRule(cli.cfg).NewCommand())
 	cmd.AddCommand(cliallowlists.New(cli.cfg).NewCommand())
+	cmd.AddCommand(cliaudit.New(cli.cfg).NewCommand())
+	cmd.AddCommand(clidatabase.New(cli.cfg).NewCommand())
+	cmd.AddCommand(clitap.New(cli.cfg).NewCommand())
 
 	cli.addSetup(cmd)
 
@@ -329,6 +336,9 @@ func mainWrap() error {
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
+	// changes made with direct database access are recorded in the audit log as the local user
+	ctx = database.WithAuditActor(ctx, database.LocalAuditActor())
+
 	cmd, err := newCliRoot().NewCommand()
 	if err != nil {
 		return err
@@ -2,10 +2,13 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"os"
+	"path/filepath"
 	"time"
 
+	"github.com/redis/go-redis/v9"
 	log "github.com/sirupsen/logrus"
 	"golang.org/x/sync/errgroup"
 
@@ -15,6 +18,7 @@ import (
 	acquisitionTypes "github.com/crowdsecurity/crowdsec/pkg/acquisition/types"
 	"github.com/crowdsecurity/crowdsec/pkg/alertcontext"
 	"github.com/crowdsecurity/crowdsec/pkg/apiclient"
+	"github.com/crowdsecurity/crowdsec/pkg/cache"
 	"github.com/crowdsecurity/crowdsec/pkg/csconfig"
 	"github.com/crowdsecurity/crowdsec/pkg/cwhub"
 	"github.com/crowdsecurity/crowdsec/pkg/dnscache"
@@ -33,6 +37,8 @@ func initCrowdsec(ctx context.Context, cConfig *csconfig.Config, hub *cwhub.Hub,
 	}
 
 	configureDNSCache(cConfig.Crowdsec.DNSCache)
+	configureStash(cConfig.Crowdsec.Stash, hub.GetDataDir())
+	exprhelpers.ConfigureExpressions(cConfig.Crowdsec.Expressions)
 
 	err = exprhelpers.GeoIPInit(hub.GetDataDir())
 	if err != nil {
@@ -70,6 +76,11 @@ func initCrowdsec(ctx context.Context, cConfig *csconfig.Config, hub *cwhub.Hub,
 		return nil, nil, fmt.Errorf("while loading acquisition config: %w", err)
 	}
 
+	if !testMode {
+		// the data files loaded later (appsec) are watched too
+		exprhelpers.WatchDataFiles(cConfig.Crowdsec.DataFiles)
+	}
+
 	return csParsers, datasources, nil
 }
 
@@ -98,6 +109,33 @@ func configureDNSCache(cfg *csconfig.DNSCacheCfg) {
 	dnscache.Configure(ttl, negTTL, size)
 }
 
+func configureStash(cfg *csconfig.StashCfg, dataDir string) {
+	backends := cache.BackendCfg{
+		DiskPath: filepath.Join(dataDir, "stash.db"),
+	}
+
+	if cfg != nil && cfg.Disk != nil && cfg.Disk.Path != "" {
+		backends.DiskPath = cfg.Disk.Path
+	}
+
+	if cfg != nil && cfg.Redis != nil {
+		backends.Redis = &redis.Options{
+			Addr:     cfg.Redis.Address,
+			Username: cfg.Redis.Username,
+			Password: cfg.Redis.Password,
+			DB:       cfg.Redis.DB,
+		}
+
+		if cfg.Redis.TLS {
+			backends.Redis.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		}
+
+		backends.RedisKeyPrefix = cfg.Redis.KeyPrefix
+	}
+
+	cache.ConfigureBackends(backends)
+}
+
 func startParserRoutines(ctx context.Context, g *errgroup.Group, cConfig *csconfig.Config, parsers *parser.Parsers, stageCollector *parser.StageParseCollector) {
 	for idx := range cConfig.Crowdsec.ParserRoutinesCount {
 		log.WithField("idx", idx).Info("Starting parser routine")
@@ -120,6 +158,31 @@ func startBucketRoutines(ctx context.Context, g *errgroup.Group, cConfig *csconf
 	}
 }
 
+// startWhitelistStaleCheck warns about the whitelist entries of the parsers
+// and postoverflows that don't match anymore, likely left from old incidents.
+func startWhitelistStaleCheck(ctx context.Context, g *errgroup.Group, staleAfter time.Duration, parsers *parser.Parsers) {
+	if staleAfter <= 0 {
+		return
+	}
+
+	g.Go(func() error {
+		defer trace.ReportPanic()
+
+		ticker := time.NewTicker(min(staleAfter, time.Hour))
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case now := <-ticker.C:
+				parser.WarnStaleWhitelists(parsers.Nodes, staleAfter, now)
+				parser.WarnStaleWhitelists(parsers.Povfwnodes, staleAfter, now)
+			}
+		}
+	})
+}
+
 func startHeartBeat(ctx context.Context, _ *csconfig.Config, apiClient *apiclient.ApiClient) {
 	log.Debugf("Starting HeartBeat service")
 	apiClient.HeartBeat.StartHeartBeat(ctx)
@@ -177,6 +240,8 @@ func runCrowdsec(
 	inEvents = make(chan pipeline.Event)
 	logLines = make(chan pipeline.Event)
 
+	startTap(ctx, g, cConfig.Crowdsec.Tap)
+	startWhitelistStaleCheck(ctx, g, cConfig.Crowdsec.WhitelistStaleAfter, parsers)
 	startParserRoutines(ctx, g, cConfig, parsers, sd.StageParse)
 	startBucketRoutines(ctx, g, cConfig, sd.Pour, bucketStore)
 
@@ -195,6 +260,14 @@ func runCrowdsec(
 
 	log.Info("Starting processing data")
 
+	if acquisManager != nil {
+		if err := acquisManager.Run(ctx, logLines, &acquisTomb); err != nil {
+			return fmt.Errorf("starting acquisition error: %w", err)
+		}
+
+		return nil
+	}
+
 	if err := acquisition.StartAcquisition(ctx, datasources, logLines, &acquisTomb); err != nil {
 		return fmt.Errorf("starting acquisition error: %w", err)
 	}
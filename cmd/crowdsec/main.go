@@ -42,6 +42,9 @@ var (
 	// the state of the buckets
 	holders []leakybucket.BucketFactory
 
+	// set when the datasources are reloaded on acquisition file changes
+	acquisManager *acquisition.Manager
+
 	logLines     chan pipeline.Event
 	inEvents     chan pipeline.Event
 	outEvents    chan pipeline.Event // the buckets init returns its own chan that is used for multiplexing
@@ -66,6 +69,8 @@ func LoadBuckets(cConfig *csconfig.Config, hub *cwhub.Hub) error {
 func LoadAcquisition(ctx context.Context, cConfig *csconfig.Config, hub *cwhub.Hub) ([]acquisitionTypes.DataSource, error) {
 	var datasources []acquisitionTypes.DataSource
 
+	acquisManager = nil
+
 	if flags.SingleFileType != "" && flags.OneShotDSN != "" {
 		flags.Labels["type"] = flags.SingleFileType
 
@@ -75,7 +80,11 @@ func LoadAcquisition(ctx context.Context, cConfig *csconfig.Config, hub *cwhub.H
 		}
 		datasources = append(datasources, ds)
 	} else {
-		dss, err := acquisition.LoadAcquisitionFromFiles(ctx, cConfig.Crowdsec, cConfig.Prometheus, hub)
+		// the manager carries the transform and rate limit of each datasource,
+		// and watches the files with acquisition_auto_reload
+		acquisManager = acquisition.NewManager(cConfig.Crowdsec, cConfig.Prometheus, hub)
+
+		dss, err := acquisManager.Load(ctx)
 		if err != nil {
 			return nil, err
 		}
@@ -36,12 +36,28 @@ func parseEvent(
 
 	startParsing := time.Now()
 	/* parse the log using magic */
-	parsed, err := parser.Parse(parserCTX, event, nodes, stageCollector)
+	var (
+		parsed    pipeline.Event
+		nodeTrace []parser.NodeResult
+		err       error
+	)
+
+	if eventTap.Active() {
+		parsed, nodeTrace, err = parser.ParseTraced(parserCTX, event, nodes, stageCollector)
+	} else {
+		parsed, err = parser.Parse(parserCTX, event, nodes, stageCollector)
+	}
+
 	if err != nil {
 		log.Errorf("failed parsing: %v", err)
 	}
 	elapsed := time.Since(startParsing)
 	metrics.GlobalParsingHistogram.With(prometheus.Labels{"source": event.Line.Src, "type": event.Line.Module}).Observe(elapsed.Seconds())
+
+	if eventTap.Active() {
+		tapParsed(&parsed, nodeTrace)
+	}
+
 	if !parsed.Process {
 		metrics.GlobalParserHitsKo.With(prometheus.Labels{"source": event.Line.Src, "type": event.Line.Module, "acquis_type": event.Line.Labels["type"]}).Inc()
 		log.Debugf("Discarding line %+v", parsed)
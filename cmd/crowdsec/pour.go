@@ -52,7 +52,20 @@ func runPour(ctx context.Context, input chan pipeline.Event, holders []leaky.Buc
 				triggerGC(parsed, buckets, cConfig)
 			}
 			// here we can bucketify with parsed
-			poured, err := leaky.PourItemToHolders(ctx, parsed, holders, buckets, pourCollector)
+			var (
+				poured bool
+				err    error
+			)
+
+			var names []string
+
+			if eventTap.Active() {
+				names, err = leaky.PourItemToHoldersTraced(ctx, parsed, holders, buckets, pourCollector)
+				poured = len(names) > 0
+			} else {
+				poured, err = leaky.PourItemToHolders(ctx, parsed, holders, buckets, pourCollector)
+			}
+
 			if err != nil {
 				log.Warningf("bucketify failed for: %v with %s", parsed, err)
 				continue
@@ -61,6 +74,10 @@ func runPour(ctx context.Context, input chan pipeline.Event, holders []leaky.Buc
 			elapsed := time.Since(startTime)
 			metrics.GlobalPourHistogram.With(prometheus.Labels{"type": parsed.Line.Module, "source": parsed.Line.Src}).Observe(elapsed.Seconds())
 
+			if eventTap.Active() {
+				tapPoured(&parsed, names)
+			}
+
 			if poured {
 				metrics.GlobalBucketPourOk.Inc()
 			} else {
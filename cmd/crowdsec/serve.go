@@ -18,6 +18,7 @@ import (
 
 	acquisitionTypes "github.com/crowdsecurity/crowdsec/pkg/acquisition/types"
 	"github.com/crowdsecurity/crowdsec/pkg/apiclient"
+	"github.com/crowdsecurity/crowdsec/pkg/cache"
 	"github.com/crowdsecurity/crowdsec/pkg/csconfig"
 	"github.com/crowdsecurity/crowdsec/pkg/cticlient/ctiexpr"
 	"github.com/crowdsecurity/crowdsec/pkg/cwhub"
@@ -26,6 +27,21 @@ import (
 	"github.com/crowdsecurity/crowdsec/pkg/pipeline"
 )
 
+// initExprFeeds loads the enrichers and the threat intel feeds, used by the
+// parsers and in the profiles. The feeds are closed with the crowdsec routines,
+// so this is needed again on reload.
+func initExprFeeds(ctx context.Context, cConfig *csconfig.Config) error {
+	if err := exprhelpers.EnrichersInit(cConfig.Enrichers); err != nil {
+		return fmt.Errorf("failed to init enrichers: %w", err)
+	}
+
+	if err := exprhelpers.ThreatIntelInit(ctx, cConfig.ThreatIntel); err != nil {
+		return fmt.Errorf("failed to init threat intel feeds: %w", err)
+	}
+
+	return nil
+}
+
 func reloadHandler(ctx context.Context, _ os.Signal) (*csconfig.Config, error) {
 	// re-initialize tombs
 	acquisTomb = tomb.Tomb{}
@@ -41,6 +57,10 @@ func reloadHandler(ctx context.Context, _ os.Signal) (*csconfig.Config, error) {
 		return nil, err
 	}
 
+	if err := initExprFeeds(ctx, cConfig); err != nil {
+		return nil, err
+	}
+
 	if !cConfig.DisableAPI {
 		if flags.DisableCAPI {
 			log.Warningf("Communication with CrowdSec Central API disabled from args")
@@ -148,6 +168,10 @@ func ShutdownCrowdsecRoutines(cancel context.CancelFunc, g *errgroup.Group, data
 	}
 
 	log.Debugf("parsers are done")
+
+	if err := cache.Close(); err != nil {
+		log.Warningf("while closing the stash backends: %s", err)
+	}
 	log.Debugf("buckets are done")
 	log.Debugf("metrics are done")
 
@@ -156,6 +180,8 @@ func ShutdownCrowdsecRoutines(cancel context.CancelFunc, g *errgroup.Group, data
 
 	// close the potential geoips reader we have to avoid leaking ressources on reload
 	exprhelpers.GeoIPClose()
+	exprhelpers.StopWatchingDataFiles()
+	exprhelpers.ThreatIntelClose()
 
 	return reterr
 }
@@ -336,10 +362,14 @@ func Serve(
 		log.Warningln("Exprhelpers loaded without database client.")
 	}
 
+	if err := initExprFeeds(ctx, cConfig); err != nil {
+		return err
+	}
+
 	if cConfig.API.CTI != nil && cConfig.API.CTI.Enabled != nil && *cConfig.API.CTI.Enabled {
 		log.Infof("Crowdsec CTI helper enabled")
 
-		if err := ctiexpr.InitCrowdsecCTI(cConfig.API.CTI.Key, cConfig.API.CTI.CacheTimeout, cConfig.API.CTI.CacheSize, cConfig.API.CTI.LogLevel); err != nil {
+		if err := ctiexpr.InitCrowdsecCTI(cConfig.API.CTI); err != nil {
 			return fmt.Errorf("failed to init crowdsec cti: %w", err)
 		}
 	}
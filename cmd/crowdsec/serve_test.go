@@ -0,0 +1,49 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/crowdsecurity/crowdsec/pkg/csconfig"
+	"github.com/crowdsecurity/crowdsec/pkg/exprhelpers"
+)
+
+func TestReloadThreatIntel(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "feed.txt")
+	require.NoError(t, os.WriteFile(path, []byte("192.0.2.1\n"), 0o644))
+
+	cConfig := &csconfig.Config{
+		ThreatIntel: []*csconfig.ThreatIntelFeedCfg{
+			{Name: "honeypot", Format: csconfig.ThreatIntelPlain, Path: path},
+		},
+	}
+
+	matches := func() bool {
+		ret, err := exprhelpers.TIMatch("192.0.2.1", "honeypot")
+		require.NoError(t, err)
+
+		return ret.(*exprhelpers.TIMatchResult) != nil
+	}
+
+	require.NoError(t, initExprFeeds(t.Context(), cConfig))
+	t.Cleanup(exprhelpers.ThreatIntelClose)
+
+	assert.True(t, matches())
+
+	// what happens on SIGHUP: the routines are stopped, then the configuration is loaded again
+	outputsTomb.Go(func() error {
+		<-outputsTomb.Dying()
+		return nil
+	})
+
+	require.NoError(t, ShutdownCrowdsecRoutines(func() {}, &errgroup.Group{}, nil))
+	assert.False(t, matches())
+
+	require.NoError(t, initExprFeeds(t.Context(), cConfig))
+	assert.True(t, matches())
+}
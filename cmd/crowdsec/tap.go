@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/crowdsecurity/go-cs-lib/trace"
+
+	"github.com/crowdsecurity/crowdsec/pkg/csconfig"
+	"github.com/crowdsecurity/crowdsec/pkg/parser"
+	"github.com/crowdsecurity/crowdsec/pkg/pipeline"
+	"github.com/crowdsecurity/crowdsec/pkg/tap"
+)
+
+// subscribers of cscli tap, if enabled
+var eventTap = tap.NewHub()
+
+func startTap(ctx context.Context, g *errgroup.Group, cfg *csconfig.TapCfg) {
+	if cfg == nil {
+		return
+	}
+
+	srv := tap.NewServer(eventTap, cfg.ListenSocket, cfg.BufferSize)
+
+	g.Go(func() error {
+		defer trace.ReportPanic()
+
+		// the tap is a debugging aid, the agent can run without it
+		if err := srv.Run(ctx); err != nil {
+			log.Errorf("event tap: %s", err)
+		}
+
+		return nil
+	})
+}
+
+func tapParsed(parsed *pipeline.Event, nodes []parser.NodeResult) {
+	eventTap.Publish(&tap.Record{
+		Kind:            tap.KindParse,
+		Time:            time.Now().UTC(),
+		Event:           parsed,
+		Parsed:          parsed.Process,
+		Nodes:           nodes,
+		Whitelisted:     parsed.Whitelisted,
+		WhitelistReason: parsed.WhitelistReason,
+	})
+}
+
+func tapPoured(parsed *pipeline.Event, buckets []string) {
+	eventTap.Publish(&tap.Record{
+		Kind:    tap.KindPour,
+		Time:    time.Now().UTC(),
+		Event:   parsed,
+		Buckets: buckets,
+	})
+}
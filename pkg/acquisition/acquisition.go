@@ -277,6 +277,12 @@ func ParseSourceConfig(ctx context.Context, yamlDoc []byte, metricsLevel metrics
 		return nil, err
 	}
 
+	if sub.RateLimit != nil {
+		if err := sub.RateLimit.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
 	// check for labels now, an error for missing labels has lower priority
 	// than missing or unknown source type
 	if len(sub.Labels) == 0 && sub.Source != "docker" {
@@ -315,17 +321,9 @@ func formatConfigLocation(acquisFile string, withPos bool, idx int) string {
 	return ret
 }
 
-// sourcesFromFile reads and parses one acquisition file into DataSources.
-func sourcesFromFile(
-	ctx context.Context,
-	acquisFile string,
-	metricsLevel metrics.AcquisitionMetricsLevel,
-	hub *cwhub.Hub,
-) ([]types.DataSource, error) {
-	var sources []types.DataSource
-
-	log.Infof("loading acquisition file : %s", acquisFile)
-
+// readAcquisitionDocuments reads one acquisition file, expands the environment
+// variables and splits it into YAML documents.
+func readAcquisitionDocuments(acquisFile string) ([][]byte, error) {
 	yamlFile, err := os.Open(acquisFile)
 	if err != nil {
 		return nil, err
@@ -340,95 +338,67 @@ func sourcesFromFile(
 
 	expandedAcquis := csstring.StrictExpand(string(acquisContent), os.LookupEnv)
 
-	documents, err := csyaml.SplitDocuments(strings.NewReader(expandedAcquis))
-	if err != nil {
-		return nil, err
-	}
-
-	idx := -1
-
-	for _, yamlDoc := range documents {
-		idx += 1
-
-		loc := formatConfigLocation(acquisFile, len(documents) > 1, idx)
-
-		parsed, err := ParseSourceConfig(ctx, yamlDoc, metricsLevel, hub)
+	return csyaml.SplitDocuments(strings.NewReader(expandedAcquis))
+}
 
-		// report data source detection, it can be required to understand an error
-		if parsed != nil {
-			if parsed.SourceMissing {
-				log.Debugf("%s: datasource type missing, detected 'source=%s'", loc, parsed.Common.Source)
-			}
+// parseDocument wraps ParseSourceConfig for a document found at loc. It returns
+// (nil, nil) if the document must be skipped (empty, or datasource unavailable).
+func parseDocument(
+	ctx context.Context,
+	loc string,
+	yamlDoc []byte,
+	metricsLevel metrics.AcquisitionMetricsLevel,
+	hub *cwhub.Hub,
+) (*ParsedSourceConfig, error) {
+	parsed, err := ParseSourceConfig(ctx, yamlDoc, metricsLevel, hub)
 
-			if parsed.SourceOverridden != "" {
-				log.Warnf("%s: datasource type mismatch: found '%s' but should probably be '%s'", loc, parsed.SourceOverridden, parsed.Common.Source)
-			}
+	// report data source detection, it can be required to understand an error
+	if parsed != nil {
+		if parsed.SourceMissing {
+			log.Debugf("%s: datasource type missing, detected 'source=%s'", loc, parsed.Common.Source)
 		}
 
-		if err != nil {
-			if errors.Is(err, ErrEmptyYAMLDocument) {
-				continue
-			}
-
-			var dserr *DataSourceUnavailableError
-			if errors.As(err, &dserr) {
-				log.Error(fmt.Errorf("%s: %w", loc, err))
-				continue
-			}
+		if parsed.SourceOverridden != "" {
+			log.Warnf("%s: datasource type mismatch: found '%s' but should probably be '%s'", loc, parsed.SourceOverridden, parsed.Common.Source)
+		}
+	}
 
-			return nil, fmt.Errorf("%s: %w", loc, err)
+	if err != nil {
+		if errors.Is(err, ErrEmptyYAMLDocument) {
+			return nil, nil
 		}
 
-		if parsed.Transform != nil {
-			transformRuntimes[parsed.Common.UniqueId] = parsed.Transform
+		var dserr *DataSourceUnavailableError
+		if errors.As(err, &dserr) {
+			log.Error(fmt.Errorf("%s: %w", loc, err))
+			return nil, nil
 		}
 
-		sources = append(sources, parsed.Source)
+		return nil, fmt.Errorf("%s: %w", loc, err)
 	}
 
-	return sources, nil
+	return parsed, nil
 }
 
-// LoadAcquisitionFromFiles unmarshals the configuration item and checks its availability
-func LoadAcquisitionFromFiles(
-	ctx context.Context,
-	config *csconfig.CrowdsecServiceCfg,
-	prom *csconfig.PrometheusCfg,
-	hub *cwhub.Hub,
-) ([]types.DataSource, error) {
-	var allSources []types.DataSource
-
-	metricsLevel := GetMetricsLevelFromPromCfg(prom)
-
-	for _, acquisFile := range config.AcquisitionFiles {
-		sources, err := sourcesFromFile(ctx, acquisFile, metricsLevel, hub)
-		if err != nil {
-			return nil, err
-		}
+// sourceMetrics returns the collectors of a datasource. They belong to its module,
+// so the datasources of the same type share them.
+func sourceMetrics(source types.DataSource, aggregated bool) []prometheus.Collector {
+	mp, ok := source.(types.MetricsProvider)
+	if !ok {
+		// the source does not expose metrics
+		return nil
+	}
 
-		allSources = append(allSources, sources...)
+	if aggregated {
+		return mp.GetMetrics()
 	}
 
-	return allSources, nil
+	return mp.GetAggregMetrics()
 }
 
 func GetMetrics(sources []types.DataSource, aggregated bool) error {
 	for i := range sources {
-		mp, ok := sources[i].(types.MetricsProvider)
-		if !ok {
-			// the source does not expose metrics
-			continue
-		}
-
-		var metrics []prometheus.Collector
-
-		if aggregated {
-			metrics = mp.GetMetrics()
-		} else {
-			metrics = mp.GetAggregMetrics()
-		}
-
-		for _, metric := range metrics {
+		for _, metric := range sourceMetrics(sources[i], aggregated) {
 			if err := prometheus.Register(metric); err != nil {
 				var alreadyRegisteredErr prometheus.AlreadyRegisteredError
 				if !errors.As(err, &alreadyRegisteredErr) {
@@ -616,6 +586,91 @@ func acquireSource(
 	return fmt.Errorf("%s: tail mode is set but the datasource does not support streaming acquisition", source.GetName())
 }
 
+// runSource runs one datasource, with its transformer and rate limiter if any,
+// until it's done (cat) or the tomb dies (tail). Errors kill the tomb.
+func runSource(
+	ctx context.Context,
+	subsrc types.DataSource,
+	transformRuntime *vm.Program,
+	rateLimit *configuration.RateLimitCfg,
+	output chan pipeline.Event,
+	acquisTomb *tomb.Tomb,
+) {
+	outChan := output
+
+	var (
+		transformChan chan pipeline.Event
+		throttleChan  chan pipeline.Event
+	)
+
+	log.Debugf("datasource %s UUID: %s", subsrc.GetName(), subsrc.GetUuid())
+
+	// the rate limiter is the last step before the shared output channel,
+	// so it also accounts for the lines created by a transform expression
+	if rateLimit != nil {
+		log.Infof("rate limit found for datasource %s", subsrc.GetName())
+
+		throttleChan = make(chan pipeline.Event)
+		outChan = throttleChan
+		throttler := newThrottler(rateLimit, log.WithFields(log.Fields{
+			"component":  "rate_limit",
+			"datasource": subsrc.GetName(),
+		}))
+
+		acquisTomb.Go(func() error {
+			defer trace.ReportPanic()
+			throttler.run(ctx, throttleChan, output, acquisTomb)
+			return nil
+		})
+	}
+
+	if transformRuntime != nil {
+		log.Infof("transform expression found for datasource %s", subsrc.GetName())
+
+		transformOutput := outChan
+		transformChan = make(chan pipeline.Event)
+		outChan = transformChan
+		transformLogger := log.WithFields(log.Fields{
+			"component":  "transform",
+			"datasource": subsrc.GetName(),
+		})
+
+		acquisTomb.Go(func() error {
+			defer trace.ReportPanic()
+			transform(transformChan, transformOutput, acquisTomb, transformRuntime, transformLogger)
+			// the transformer is the only writer of the rate limiter channel
+			if throttleChan != nil && subsrc.GetMode() == configuration.CAT_MODE {
+				close(throttleChan)
+			}
+			return nil
+		})
+	}
+
+	err := acquireSource(ctx, subsrc, subsrc.GetName(), outChan, acquisTomb)
+
+	// In cat mode the datasource is done writing when acquireSource returns, so we
+	// close the transform (or rate limiter) channel to let the transformer drain and exit:
+	// the tomb can then die on its own, which is what signals the end of a cat run.
+	// In tail mode datasources may keep writing from goroutines they spawned, so
+	// closing here would panic; the transformer exits on Dying() instead.
+	if subsrc.GetMode() == configuration.CAT_MODE {
+		switch {
+		case transformChan != nil:
+			close(transformChan)
+		case throttleChan != nil:
+			close(throttleChan)
+		}
+	}
+
+	if err != nil {
+		// if one of the acquisitions returns an error, we kill the others to properly shutdown
+		acquisTomb.Kill(err)
+	}
+}
+
+// StartAcquisition runs the datasources of LoadAcquisitionFromDSN, with their transform
+// expression if any, until acquisition is over (cat) or the tomb dies (tail).
+// A DSN has no rate_limit: the datasources of the acquisition files are run by a Manager.
 func StartAcquisition(
 	ctx context.Context,
 	sources []types.DataSource,
@@ -633,46 +688,7 @@ func StartAcquisition(
 
 		acquisTomb.Go(func() error {
 			defer trace.ReportPanic()
-
-			outChan := output
-
-			var transformChan chan pipeline.Event
-
-			log.Debugf("datasource %s UUID: %s", subsrc.GetName(), subsrc.GetUuid())
-
-			if transformRuntime, ok := transformRuntimes[subsrc.GetUuid()]; ok {
-				log.Infof("transform expression found for datasource %s", subsrc.GetName())
-
-				transformChan = make(chan pipeline.Event)
-				outChan = transformChan
-				transformLogger := log.WithFields(log.Fields{
-					"component":  "transform",
-					"datasource": subsrc.GetName(),
-				})
-
-				acquisTomb.Go(func() error {
-					defer trace.ReportPanic()
-					transform(transformChan, output, acquisTomb, transformRuntime, transformLogger)
-					return nil
-				})
-			}
-
-			err := acquireSource(ctx, subsrc, subsrc.GetName(), outChan, acquisTomb)
-
-			// In cat mode the datasource is done writing when acquireSource returns, so we
-			// close the transform channel to let the transformer drain and exit: the tomb
-			// can then die on its own, which is what signals the end of a cat run.
-			// In tail mode datasources may keep writing from goroutines they spawned, so
-			// closing here would panic; the transformer exits on Dying() instead.
-			if transformChan != nil && subsrc.GetMode() == configuration.CAT_MODE {
-				close(transformChan)
-			}
-
-			if err != nil {
-				// if one of the acquisitions returns an error, we kill the others to properly shutdown
-				acquisTomb.Kill(err)
-			}
-
+			runSource(ctx, subsrc, transformRuntimes[subsrc.GetUuid()], nil, output, acquisTomb)
 			return nil
 		})
 	}
@@ -223,7 +223,7 @@ filename: foo.log
 	}
 }
 
-func TestLoadAcquisitionFromFiles(t *testing.T) {
+func TestManagerLoad(t *testing.T) {
 	appendMockSource(t)
 	t.Setenv("TEST_ENV", "test_value2")
 
@@ -304,7 +304,7 @@ func TestLoadAcquisitionFromFiles(t *testing.T) {
 	for _, tc := range tests {
 		t.Run(tc.TestName, func(t *testing.T) {
 			hub := cwhub.Hub{}
-			dss, err := LoadAcquisitionFromFiles(ctx, &tc.Config, nil, &hub)
+			dss, err := NewManager(&tc.Config, nil, &hub).Load(ctx)
 			cstest.RequireErrorContains(t, err, tc.ExpectedError)
 
 			if tc.ExpectedError != "" {
@@ -1,6 +1,9 @@
 package configuration
 
 import (
+	"errors"
+	"fmt"
+
 	log "github.com/sirupsen/logrus"
 )
 
@@ -13,6 +16,7 @@ type DataSourceCommonCfg struct {
 	UseTimeMachine bool              `yaml:"use_time_machine,omitempty"`
 	UniqueId       string            `yaml:"unique_id,omitempty"`
 	TransformExpr  string            `yaml:"transform,omitempty"`
+	RateLimit      *RateLimitCfg     `yaml:"rate_limit,omitempty"`
 }
 
 const (
@@ -20,3 +24,60 @@ const (
 	CAT_MODE    = "cat"
 	SERVER_MODE = "server" // No difference with tail, just a bit more verbose
 )
+
+// What to do with a line when the shared acquisition channel is full.
+const (
+	ON_FULL_BLOCK  = "block"  // wait for the pipeline (default, same as without rate_limit)
+	ON_FULL_DROP   = "drop"   // discard the line
+	ON_FULL_SAMPLE = "sample" // keep only sample_ratio of the lines, wait for the pipeline for those
+)
+
+// RateLimitCfg throttles the events that a single datasource can push to the
+// acquisition channel shared with every other datasource, so that a flood on
+// one source does not starve the others.
+type RateLimitCfg struct {
+	// EventsPerSecond caps the throughput of the datasource, 0 means no cap.
+	// Lines above the cap are delayed with on_full: block, and discarded otherwise.
+	EventsPerSecond float64 `yaml:"events_per_second,omitempty"`
+	// Burst is the number of events allowed above the rate, defaults to EventsPerSecond.
+	Burst int `yaml:"burst,omitempty"`
+	// OnFull is one of block, drop or sample.
+	OnFull string `yaml:"on_full,omitempty"`
+	// SampleRatio is the fraction of lines kept when on_full is sample and the pipeline is full.
+	SampleRatio float64 `yaml:"sample_ratio,omitempty"`
+}
+
+// Validate checks the values and fills the defaults.
+func (c *RateLimitCfg) Validate() error {
+	if c.EventsPerSecond < 0 {
+		return errors.New("rate_limit.events_per_second must be positive")
+	}
+
+	if c.Burst < 0 {
+		return errors.New("rate_limit.burst must be positive")
+	}
+
+	if c.Burst == 0 && c.EventsPerSecond > 0 {
+		c.Burst = max(int(c.EventsPerSecond), 1)
+	}
+
+	switch c.OnFull {
+	case "":
+		c.OnFull = ON_FULL_BLOCK
+	case ON_FULL_BLOCK, ON_FULL_DROP:
+	case ON_FULL_SAMPLE:
+		if c.SampleRatio <= 0 || c.SampleRatio > 1 {
+			return errors.New("rate_limit.sample_ratio must be in ]0, 1] when on_full is sample")
+		}
+
+		return nil
+	default:
+		return fmt.Errorf("rate_limit.on_full: unsupported value %q (must be one of block, drop, sample)", c.OnFull)
+	}
+
+	if c.SampleRatio != 0 {
+		return errors.New("rate_limit.sample_ratio can only be used when on_full is sample")
+	}
+
+	return nil
+}
@@ -0,0 +1,455 @@
+package acquisition
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/expr-lang/expr/vm"
+	"github.com/fsnotify/fsnotify"
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	tomb "gopkg.in/tomb.v2"
+
+	"github.com/crowdsecurity/go-cs-lib/trace"
+
+	"github.com/crowdsecurity/crowdsec/pkg/acquisition/configuration"
+	"github.com/crowdsecurity/crowdsec/pkg/acquisition/types"
+	"github.com/crowdsecurity/crowdsec/pkg/csconfig"
+	"github.com/crowdsecurity/crowdsec/pkg/cwhub"
+	"github.com/crowdsecurity/crowdsec/pkg/metrics"
+	"github.com/crowdsecurity/crowdsec/pkg/pipeline"
+)
+
+// reloadDelay is how long the manager waits for the acquisition files to settle
+// before reloading, so that a batch of changes triggers a single reload.
+const reloadDelay = time.Second
+
+// managedSource is a datasource run by the Manager, with its own tomb so it can
+// be stopped without touching the others.
+type managedSource struct {
+	key       string
+	loc       string
+	source    types.DataSource
+	transform *vm.Program
+	rateLimit *configuration.RateLimitCfg
+	tomb      *tomb.Tomb
+}
+
+// Manager runs the datasources described in the acquisition files, each with its
+// transform expression and rate limit. With acquisition_auto_reload, it watches
+// the files to start or stop only the datasources whose configuration changed.
+// Parsers and buckets are not affected by such a reload.
+//
+// Each YAML document is identified by the hash of its content (after environment
+// expansion): a modified document is seen as the removal of the old datasource
+// and the addition of a new one.
+type Manager struct {
+	config       *csconfig.CrowdsecServiceCfg
+	prom         *csconfig.PrometheusCfg
+	metricsLevel metrics.AcquisitionMetricsLevel
+	hub          *cwhub.Hub
+	reloadDelay  time.Duration
+
+	mu      sync.Mutex
+	sources map[string]*managedSource
+
+	// set by Run
+	output     chan pipeline.Event
+	acquisTomb *tomb.Tomb
+}
+
+func NewManager(config *csconfig.CrowdsecServiceCfg, prom *csconfig.PrometheusCfg, hub *cwhub.Hub) *Manager {
+	return &Manager{
+		config:       config,
+		prom:         prom,
+		metricsLevel: GetMetricsLevelFromPromCfg(prom),
+		hub:          hub,
+		reloadDelay:  reloadDelay,
+		sources:      make(map[string]*managedSource),
+	}
+}
+
+// documentKeys returns a key for each document, based on its content.
+// Identical documents are told apart by their rank.
+func documentKeys(documents [][]byte, seen map[string]int) []string {
+	keys := make([]string, 0, len(documents))
+
+	for _, doc := range documents {
+		sum := sha256.Sum256(doc)
+		key := hex.EncodeToString(sum[:])
+
+		seen[key]++
+		if seen[key] > 1 {
+			key += "#" + strconv.Itoa(seen[key])
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+// closeSources releases the resources of datasources that have been configured but won't be run.
+func closeSources(sources []*managedSource) {
+	for _, ms := range sources {
+		c, ok := ms.source.(types.Closer)
+		if !ok {
+			continue
+		}
+
+		if err := c.Close(); err != nil {
+			log.Warningf("%s: while closing datasource %s: %s", ms.loc, ms.source.GetName(), err)
+		}
+	}
+}
+
+// configure reads the acquisition files and configures the datasources that are not already known.
+// It returns the new datasources and the keys of every document. On error, the datasources
+// configured so far are closed.
+func (m *Manager) configure(ctx context.Context, files []string) ([]*managedSource, map[string]bool, error) {
+	var added []*managedSource
+
+	wanted := make(map[string]bool)
+	seen := make(map[string]int)
+
+	for _, acquisFile := range files {
+		documents, err := readAcquisitionDocuments(acquisFile)
+		if err != nil {
+			closeSources(added)
+			return nil, nil, err
+		}
+
+		for idx, key := range documentKeys(documents, seen) {
+			wanted[key] = true
+
+			if _, ok := m.sources[key]; ok {
+				continue
+			}
+
+			loc := formatConfigLocation(acquisFile, len(documents) > 1, idx)
+
+			parsed, err := parseDocument(ctx, loc, documents[idx], m.metricsLevel, m.hub)
+			if err != nil {
+				closeSources(added)
+				return nil, nil, err
+			}
+
+			if parsed == nil {
+				continue
+			}
+
+			added = append(added, &managedSource{
+				key:       key,
+				loc:       loc,
+				source:    parsed.Source,
+				transform: parsed.Transform,
+				rateLimit: parsed.Common.RateLimit,
+			})
+		}
+	}
+
+	return added, wanted, nil
+}
+
+// Load configures the datasources of the acquisition files. They are started by Run.
+func (m *Manager) Load(ctx context.Context) ([]types.DataSource, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, acquisFile := range m.config.AcquisitionFiles {
+		log.Infof("loading acquisition file : %s", acquisFile)
+	}
+
+	added, _, err := m.configure(ctx, m.config.AcquisitionFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	sources := make([]types.DataSource, 0, len(added))
+
+	for _, ms := range added {
+		m.sources[ms.key] = ms
+		sources = append(sources, ms.source)
+	}
+
+	return sources, nil
+}
+
+// start runs a datasource in its own tomb, which is killed with the acquisition tomb.
+func (m *Manager) start(ctx context.Context, ms *managedSource) {
+	ms.tomb = &tomb.Tomb{}
+
+	ms.tomb.Go(func() error {
+		defer trace.ReportPanic()
+		runSource(ctx, ms.source, ms.transform, ms.rateLimit, m.output, ms.tomb)
+		return nil
+	})
+
+	m.acquisTomb.Go(func() error {
+		defer trace.ReportPanic()
+
+		select {
+		case <-m.acquisTomb.Dying():
+			ms.tomb.Kill(nil)
+		case <-ms.tomb.Dying():
+		}
+
+		// an error is reported, and stops the whole acquisition like StartAcquisition does.
+		// A datasource removed by a reload is killed without error.
+		return ms.tomb.Wait()
+	})
+}
+
+func (m *Manager) registerMetrics(sources []types.DataSource) error {
+	if m.prom == nil || !m.prom.Enabled {
+		return nil
+	}
+
+	return GetMetrics(sources, m.prom.Level == metrics.MetricsLevelAggregated)
+}
+
+// unregisterMetrics removes the collectors of stopped datasources, unless a running
+// datasource of the same module still uses them. The series of the stopped datasources
+// go away with them, and a collector registered again starts empty.
+func (m *Manager) unregisterMetrics(sources []types.DataSource) {
+	if m.prom == nil || !m.prom.Enabled {
+		return
+	}
+
+	aggregated := m.prom.Level == metrics.MetricsLevelAggregated
+
+	inUse := make(map[prometheus.Collector]bool)
+
+	for _, ms := range m.sources {
+		for _, c := range sourceMetrics(ms.source, aggregated) {
+			inUse[c] = true
+		}
+	}
+
+	for _, source := range sources {
+		for _, c := range sourceMetrics(source, aggregated) {
+			if inUse[c] {
+				continue
+			}
+
+			inUse[c] = true
+
+			prometheus.Unregister(c)
+
+			if vec, ok := c.(interface{ Reset() }); ok {
+				vec.Reset()
+			}
+		}
+	}
+}
+
+// Reload compares the acquisition files with the running datasources, stops the
+// ones that have been removed or modified and starts the new ones. If a document
+// can't be loaded, nothing is changed.
+func (m *Manager) Reload(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	files, err := m.config.CollectAcquisitionFiles()
+	if err != nil && !errors.Is(err, csconfig.ErrNoAcquisitionDefined) {
+		return err
+	}
+
+	added, wanted, err := m.configure(ctx, files)
+	if err != nil {
+		return err
+	}
+
+	var (
+		stopped []*managedSource
+		removed []types.DataSource
+	)
+
+	for key, ms := range m.sources {
+		if wanted[key] {
+			continue
+		}
+
+		log.Infof("%s: stopping datasource %s", ms.loc, ms.source.GetName())
+
+		if ms.tomb != nil {
+			ms.tomb.Kill(nil)
+		}
+
+		delete(m.sources, key)
+
+		stopped = append(stopped, ms)
+		removed = append(removed, ms.source)
+	}
+
+	// the replacement of a modified document must not read the same lines as the old one
+	for _, ms := range stopped {
+		if ms.tomb == nil {
+			continue
+		}
+
+		if err := ms.tomb.Wait(); err != nil {
+			log.Warningf("%s: datasource %s stopped with: %s", ms.loc, ms.source.GetName(), err)
+		}
+	}
+
+	closeSources(stopped)
+
+	newSources := make([]types.DataSource, 0, len(added))
+
+	for _, ms := range added {
+		log.Infof("%s: starting datasource %s", ms.loc, ms.source.GetName())
+		m.sources[ms.key] = ms
+		m.start(ctx, ms)
+		newSources = append(newSources, ms.source)
+	}
+
+	m.config.AcquisitionFiles = files
+
+	log.Infof("acquisition reloaded: %d datasources added, %d removed, %d running", len(added), len(removed), len(m.sources))
+
+	m.unregisterMetrics(removed)
+
+	return m.registerMetrics(newSources)
+}
+
+// isAcquisitionFile reports whether a path is (or would be) read as acquisition configuration.
+func (m *Manager) isAcquisitionFile(path string) bool {
+	path = filepath.Clean(path)
+
+	if m.config.AcquisitionFilePath != "" && path == filepath.Clean(m.config.AcquisitionFilePath) {
+		return true
+	}
+
+	if m.config.AcquisitionDirPath != "" && filepath.Dir(path) == filepath.Clean(m.config.AcquisitionDirPath) {
+		ext := filepath.Ext(path)
+		return ext == ".yaml" || ext == ".yml"
+	}
+
+	return false
+}
+
+func (m *Manager) watchedDirs() []string {
+	var dirs []string
+
+	if m.config.AcquisitionFilePath != "" {
+		// watch the parent directory, editors and config management tools often replace the file
+		dirs = append(dirs, filepath.Dir(m.config.AcquisitionFilePath))
+	}
+
+	if m.config.AcquisitionDirPath != "" && !slices.Contains(dirs, m.config.AcquisitionDirPath) {
+		dirs = append(dirs, m.config.AcquisitionDirPath)
+	}
+
+	return dirs
+}
+
+func (m *Manager) newWatcher() (*fsnotify.Watcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("could not create fsnotify watcher: %w", err)
+	}
+
+	for _, dir := range m.watchedDirs() {
+		if err := watcher.Add(dir); err != nil {
+			log.Warningf("acquisition changes in %s will not be detected: %s", dir, err)
+			continue
+		}
+
+		log.Infof("watching %s for acquisition changes", dir)
+	}
+
+	return watcher, nil
+}
+
+func (m *Manager) watch(ctx context.Context, watcher *fsnotify.Watcher) error {
+	defer watcher.Close()
+
+	timer := time.NewTimer(m.reloadDelay)
+	timer.Stop()
+
+	for {
+		select {
+		case <-m.acquisTomb.Dying():
+			timer.Stop()
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+
+			if !m.isAcquisitionFile(event.Name) || event.Op == fsnotify.Chmod {
+				continue
+			}
+
+			log.Debugf("acquisition file changed: %s", event)
+			timer.Reset(m.reloadDelay)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+
+			log.Errorf("acquisition watcher: %s", err)
+		case <-timer.C:
+			if err := m.Reload(ctx); err != nil {
+				log.Errorf("acquisition reload failed, keeping the running datasources: %s", err)
+			}
+		}
+	}
+}
+
+// Run starts the loaded datasources until the tomb dies. With acquisition_auto_reload,
+// it also reloads them when the acquisition files change and does not return at the
+// end of a cat run; otherwise it returns like StartAcquisition.
+func (m *Manager) Run(ctx context.Context, output chan pipeline.Event, acquisTomb *tomb.Tomb) error {
+	var watcher *fsnotify.Watcher
+
+	// the files are watched before anything is started, not to miss a change
+	if m.config.AcquisitionAutoReload {
+		var err error
+
+		watcher, err = m.newWatcher()
+		if err != nil {
+			return err
+		}
+	}
+
+	// keeps the tomb alive while the datasources are started: a cat run can be over
+	// before the last one is, and the tomb can't be used anymore once it's dead
+	started := make(chan struct{})
+
+	acquisTomb.Go(func() error {
+		<-started
+		return nil
+	})
+
+	m.mu.Lock()
+
+	m.output = output
+	m.acquisTomb = acquisTomb
+
+	for _, ms := range m.sources {
+		m.start(ctx, ms)
+	}
+
+	if watcher != nil {
+		acquisTomb.Go(func() error {
+			defer trace.ReportPanic()
+			return m.watch(ctx, watcher)
+		})
+	}
+
+	m.mu.Unlock()
+
+	close(started)
+
+	return acquisTomb.Wait()
+}
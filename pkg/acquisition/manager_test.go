@@ -0,0 +1,382 @@
+package acquisition
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tomb "gopkg.in/tomb.v2"
+
+	"github.com/crowdsecurity/crowdsec/pkg/acquisition/configuration"
+	"github.com/crowdsecurity/crowdsec/pkg/acquisition/registry"
+	"github.com/crowdsecurity/crowdsec/pkg/acquisition/types"
+	"github.com/crowdsecurity/crowdsec/pkg/csconfig"
+	"github.com/crowdsecurity/crowdsec/pkg/cwhub"
+	"github.com/crowdsecurity/crowdsec/pkg/metrics"
+	"github.com/crowdsecurity/crowdsec/pkg/pipeline"
+)
+
+// runningMocks keeps track of the MockTailManaged datasources currently streaming.
+type runningMocks struct {
+	mu      sync.Mutex
+	running map[string]int
+	started map[string]int
+	closed  map[string]int
+}
+
+func (r *runningMocks) inc(name string, delta int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.running[name] += delta
+
+	if delta > 0 {
+		r.started[name]++
+	}
+}
+
+func (r *runningMocks) close(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.closed[name]++
+}
+
+func (r *runningMocks) names() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ret := []string{}
+
+	for name, n := range r.running {
+		if n > 0 {
+			ret = append(ret, name)
+		}
+	}
+
+	slices.Sort(ret)
+
+	return ret
+}
+
+// MockTailManaged emits one event with its configured name, then waits to be stopped.
+type MockTailManaged struct {
+	configuration.DataSourceCommonCfg `yaml:",inline"`
+	Toto                              string `yaml:"toto"`
+	mocks                             *runningMocks
+}
+
+func (f *MockTailManaged) UnmarshalConfig(cfg []byte) error {
+	if err := yaml.UnmarshalWithOptions(cfg, f, yaml.Strict()); err != nil {
+		return errors.New(yaml.FormatError(err, false, false))
+	}
+
+	if f.Toto == "" {
+		return errors.New("expect non-empty toto")
+	}
+
+	return nil
+}
+
+func (f *MockTailManaged) Configure(_ context.Context, cfg []byte, _ *log.Entry, _ metrics.AcquisitionMetricsLevel) error {
+	f.Mode = configuration.TAIL_MODE
+	return f.UnmarshalConfig(cfg)
+}
+
+func (*MockTailManaged) GetName() string   { return "mock_tail_managed" }
+func (f *MockTailManaged) GetMode() string { return f.Mode }
+func (*MockTailManaged) CanRun() error     { return nil }
+func (f *MockTailManaged) Dump() any       { return f }
+func (*MockTailManaged) GetUuid() string   { return "" }
+
+func (f *MockTailManaged) Close() error {
+	f.mocks.close(f.Toto)
+	return nil
+}
+
+// mockManagedLines is shared by the MockTailManaged datasources, like the collectors of a module.
+var mockManagedLines = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "cs_mock_tail_managed_lines_total",
+	Help: "Lines read by the mock datasource.",
+}, []string{"toto"})
+
+func (*MockTailManaged) GetMetrics() []prometheus.Collector {
+	return []prometheus.Collector{mockManagedLines}
+}
+
+func (*MockTailManaged) GetAggregMetrics() []prometheus.Collector {
+	return []prometheus.Collector{mockManagedLines}
+}
+
+func (f *MockTailManaged) StreamingAcquisition(_ context.Context, out chan pipeline.Event, t *tomb.Tomb) error {
+	f.mocks.inc(f.Toto, 1)
+
+	t.Go(func() error {
+		defer f.mocks.inc(f.Toto, -1)
+
+		evt := pipeline.Event{}
+		evt.Line.Raw = f.Toto
+
+		select {
+		case out <- evt:
+		case <-t.Dying():
+			return nil
+		}
+
+		<-t.Dying()
+
+		return nil
+	})
+
+	return nil
+}
+
+type managerTest struct {
+	dir   string
+	mocks *runningMocks
+	out   chan pipeline.Event
+}
+
+func newManagerTest(t *testing.T) *managerTest {
+	t.Helper()
+
+	mt := &managerTest{
+		dir:   t.TempDir(),
+		mocks: &runningMocks{running: map[string]int{}, started: map[string]int{}, closed: map[string]int{}},
+		out:   make(chan pipeline.Event, 100),
+	}
+
+	restore := registry.RegisterTestFactory("mock_tail_managed", func() types.DataSource { return &MockTailManaged{mocks: mt.mocks} })
+	t.Cleanup(restore)
+
+	return mt
+}
+
+func (mt *managerTest) writeFile(t *testing.T, name string, totos ...string) {
+	t.Helper()
+
+	content := ""
+
+	for _, toto := range totos {
+		content += "---\nsource: mock_tail_managed\nlabels:\n  type: test\ntoto: " + toto + "\n"
+	}
+
+	require.NoError(t, os.WriteFile(filepath.Join(mt.dir, name), []byte(content), 0o600))
+}
+
+func (mt *managerTest) newManager(t *testing.T) *Manager {
+	t.Helper()
+
+	config := &csconfig.CrowdsecServiceCfg{AcquisitionDirPath: mt.dir}
+
+	files, err := config.CollectAcquisitionFiles()
+	require.NoError(t, err)
+
+	config.AcquisitionFiles = files
+
+	return NewManager(config, nil, &cwhub.Hub{})
+}
+
+func (mt *managerTest) requireRunning(t *testing.T, expected ...string) {
+	t.Helper()
+
+	assert.Eventually(t, func() bool { return slices.Equal(mt.mocks.names(), expected) },
+		acquisitionTimeout, 10*time.Millisecond, "running datasources: %v, expected %v", mt.mocks.names(), expected)
+}
+
+func rawLines(evts []pipeline.Event) []string {
+	ret := []string{}
+	for _, evt := range evts {
+		ret = append(ret, evt.Line.Raw)
+	}
+
+	slices.Sort(ret)
+
+	return ret
+}
+
+func TestManagerReload(t *testing.T) {
+	ctx := t.Context()
+	mt := newManagerTest(t)
+
+	mt.writeFile(t, "a.yaml", "a")
+	mt.writeFile(t, "b.yaml", "b", "keep")
+
+	m := mt.newManager(t)
+
+	sources, err := m.Load(ctx)
+	require.NoError(t, err)
+	assert.Len(t, sources, 3)
+
+	acquisTomb := tomb.Tomb{}
+	done := make(chan error, 1)
+
+	go func() { done <- m.Run(ctx, mt.out, &acquisTomb) }()
+
+	assert.Equal(t, []string{"a", "b", "keep"}, rawLines(readEvents(t, mt.out, 3)))
+	mt.requireRunning(t, "a", "b", "keep")
+
+	// remove a file, modify a document, add a file
+	require.NoError(t, os.Remove(filepath.Join(mt.dir, "a.yaml")))
+	mt.writeFile(t, "b.yaml", "c", "keep")
+	mt.writeFile(t, "d.yml", "d")
+
+	require.NoError(t, m.Reload(ctx))
+
+	assert.Equal(t, []string{"c", "d"}, rawLines(readEvents(t, mt.out, 2)))
+	requireNoMoreEvents(t, mt.out)
+	mt.requireRunning(t, "c", "d", "keep")
+
+	// the unchanged document has not been restarted, the removed ones are closed
+	assert.Equal(t, 1, mt.mocks.started["keep"])
+	assert.Equal(t, map[string]int{"a": 1, "b": 1}, mt.mocks.closed)
+
+	// a broken document aborts the reload
+	mt.writeFile(t, "d.yml", "")
+	mt.writeFile(t, "e.yaml", "e")
+
+	require.ErrorContains(t, m.Reload(ctx), "expect non-empty toto")
+	requireNoMoreEvents(t, mt.out)
+	mt.requireRunning(t, "c", "d", "keep")
+
+	acquisTomb.Kill(nil)
+	require.NoError(t, waitForAcquisition(t, done))
+	mt.requireRunning(t)
+}
+
+func TestManagerWatch(t *testing.T) {
+	ctx := t.Context()
+	mt := newManagerTest(t)
+
+	mt.writeFile(t, "a.yaml", "a")
+
+	m := mt.newManager(t)
+	m.config.AcquisitionAutoReload = true
+	m.reloadDelay = 50 * time.Millisecond
+
+	_, err := m.Load(ctx)
+	require.NoError(t, err)
+
+	acquisTomb := tomb.Tomb{}
+	done := make(chan error, 1)
+
+	go func() { done <- m.Run(ctx, mt.out, &acquisTomb) }()
+
+	readEvents(t, mt.out, 1)
+
+	// not an acquisition file
+	require.NoError(t, os.WriteFile(filepath.Join(mt.dir, "notes.txt"), []byte("toto"), 0o600))
+
+	mt.writeFile(t, "b.yaml", "b")
+
+	assert.Equal(t, []string{"b"}, rawLines(readEvents(t, mt.out, 1)))
+	mt.requireRunning(t, "a", "b")
+
+	acquisTomb.Kill(nil)
+	require.NoError(t, waitForAcquisition(t, done))
+}
+
+func TestManagerRateLimit(t *testing.T) {
+	ctx := t.Context()
+	mt := newManagerTest(t)
+
+	content := "source: mock_tail_managed\nlabels:\n  type: test\ntoto: a\nrate_limit:\n  events_per_second: 10\n  on_full: drop\n"
+	require.NoError(t, os.WriteFile(filepath.Join(mt.dir, "a.yaml"), []byte(content), 0o600))
+	mt.writeFile(t, "b.yaml", "b")
+
+	m := mt.newManager(t)
+
+	_, err := m.Load(ctx)
+	require.NoError(t, err)
+	require.Len(t, m.sources, 2)
+
+	// each datasource carries its own rate limit
+	rateLimits := map[string]*configuration.RateLimitCfg{}
+	for _, ms := range m.sources {
+		rateLimits[filepath.Base(ms.loc)] = ms.rateLimit
+	}
+
+	assert.Equal(t, map[string]*configuration.RateLimitCfg{
+		"a.yaml": {EventsPerSecond: 10, Burst: 10, OnFull: "drop"},
+		"b.yaml": nil,
+	}, rateLimits)
+}
+
+// isRegistered reports whether a collector is registered with the default registry.
+func isRegistered(t *testing.T, c prometheus.Collector) bool {
+	t.Helper()
+
+	err := prometheus.Register(c)
+	if err == nil {
+		prometheus.Unregister(c)
+		return false
+	}
+
+	var alreadyRegistered prometheus.AlreadyRegisteredError
+	require.ErrorAs(t, err, &alreadyRegistered)
+
+	return true
+}
+
+func TestManagerReloadCleanup(t *testing.T) {
+	ctx := t.Context()
+	mt := newManagerTest(t)
+
+	mt.writeFile(t, "a.yaml", "a")
+	mt.writeFile(t, "b.yaml", "b")
+
+	m := mt.newManager(t)
+	m.prom = &csconfig.PrometheusCfg{Enabled: true, Level: metrics.MetricsLevelFull}
+
+	sources, err := m.Load(ctx)
+	require.NoError(t, err)
+	require.NoError(t, m.registerMetrics(sources))
+	t.Cleanup(func() { prometheus.Unregister(mockManagedLines) })
+
+	acquisTomb := tomb.Tomb{}
+	done := make(chan error, 1)
+
+	go func() { done <- m.Run(ctx, mt.out, &acquisTomb) }()
+
+	readEvents(t, mt.out, 2)
+	mt.requireRunning(t, "a", "b")
+
+	// a broken document: the datasources that were configured before it are closed
+	mt.writeFile(t, "c.yaml", "c")
+	mt.writeFile(t, "d.yaml", "")
+
+	require.ErrorContains(t, m.Reload(ctx), "expect non-empty toto")
+	assert.Equal(t, map[string]int{"c": 1}, mt.mocks.closed)
+	mt.requireRunning(t, "a", "b")
+
+	require.NoError(t, os.Remove(filepath.Join(mt.dir, "c.yaml")))
+	require.NoError(t, os.Remove(filepath.Join(mt.dir, "d.yaml")))
+
+	// the collectors are kept as long as a datasource of the module runs
+	require.NoError(t, os.Remove(filepath.Join(mt.dir, "a.yaml")))
+	require.NoError(t, m.Reload(ctx))
+	// the removed datasource is stopped and closed when Reload returns
+	assert.Equal(t, []string{"b"}, mt.mocks.names())
+	assert.Equal(t, map[string]int{"a": 1, "c": 1}, mt.mocks.closed)
+	assert.True(t, isRegistered(t, mockManagedLines))
+
+	require.NoError(t, os.Remove(filepath.Join(mt.dir, "b.yaml")))
+	require.NoError(t, m.Reload(ctx))
+	mt.requireRunning(t)
+	assert.False(t, isRegistered(t, mockManagedLines))
+
+	assert.Equal(t, map[string]int{"a": 1, "b": 1, "c": 1}, mt.mocks.closed)
+
+	acquisTomb.Kill(nil)
+	require.NoError(t, waitForAcquisition(t, done))
+}
@@ -0,0 +1,5 @@
+//go:build !no_datasource_cri
+
+package modules
+
+import _ "github.com/crowdsecurity/crowdsec/pkg/acquisition/modules/cri" // register the datasource
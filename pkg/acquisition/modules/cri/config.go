@@ -0,0 +1,157 @@
+package criacquisition
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	yaml "github.com/goccy/go-yaml"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/crowdsecurity/crowdsec/pkg/acquisition/configuration"
+	"github.com/crowdsecurity/crowdsec/pkg/metrics"
+)
+
+const (
+	defaultLogDir            = "/var/log/pods"
+	defaultDiscoveryInterval = 5 * time.Second
+)
+
+type Configuration struct {
+	configuration.DataSourceCommonCfg `yaml:",inline"`
+
+	LogDir                     string        `yaml:"log_dir"`
+	DiscoveryInterval          time.Duration `yaml:"discovery_interval"`
+	PollWithoutInotify         bool          `yaml:"poll_without_inotify"`
+	FollowStdout               bool          `yaml:"follow_stdout"`
+	FollowStdErr               bool          `yaml:"follow_stderr"`
+	NamespaceRegexp            []string      `yaml:"namespace_regexp"`
+	PodNameRegexp              []string      `yaml:"pod_name_regexp"`
+	ContainerNameRegexp        []string      `yaml:"container_name_regexp"`
+	ExcludeNamespaceRegexp     []string      `yaml:"exclude_namespace_regexp"`
+	ExcludePodNameRegexp       []string      `yaml:"exclude_pod_name_regexp"`
+	ExcludeContainerNameRegexp []string      `yaml:"exclude_container_name_regexp"`
+}
+
+// matcher selects names with include and exclude regexps. An empty include list matches everything.
+type matcher struct {
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+}
+
+func compileRegexps(option string, exprs []string) ([]*regexp.Regexp, error) {
+	ret := make([]*regexp.Regexp, 0, len(exprs))
+
+	for _, expr := range exprs {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", option, err)
+		}
+
+		ret = append(ret, re)
+	}
+
+	return ret, nil
+}
+
+func newMatcher(includeOption string, include []string, excludeOption string, exclude []string) (matcher, error) {
+	var (
+		m   matcher
+		err error
+	)
+
+	if m.include, err = compileRegexps(includeOption, include); err != nil {
+		return m, err
+	}
+
+	if m.exclude, err = compileRegexps(excludeOption, exclude); err != nil {
+		return m, err
+	}
+
+	return m, nil
+}
+
+func (m matcher) match(name string) bool {
+	for _, re := range m.exclude {
+		if re.MatchString(name) {
+			return false
+		}
+	}
+
+	if len(m.include) == 0 {
+		return true
+	}
+
+	for _, re := range m.include {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *Source) UnmarshalConfig(yamlConfig []byte) error {
+	s.config = Configuration{
+		LogDir:            defaultLogDir,
+		DiscoveryInterval: defaultDiscoveryInterval,
+		FollowStdout:      true, // default
+		FollowStdErr:      true, // default
+	}
+
+	if err := yaml.UnmarshalWithOptions(yamlConfig, &s.config, yaml.Strict()); err != nil {
+		return fmt.Errorf("cannot parse CRI acquisition configuration: %s", yaml.FormatError(err, false, false))
+	}
+
+	if s.logger != nil {
+		s.logger.Tracef("CRI acquisition configuration: %+v", s.config)
+	}
+
+	if s.config.LogDir == "" {
+		return errors.New("log_dir cannot be empty")
+	}
+
+	if s.config.DiscoveryInterval <= 0 {
+		return errors.New("discovery_interval must be positive")
+	}
+
+	if !s.config.FollowStdout && !s.config.FollowStdErr {
+		return errors.New("at least one of follow_stdout and follow_stderr must be enabled")
+	}
+
+	if s.config.Mode == "" {
+		s.config.Mode = configuration.TAIL_MODE
+	}
+
+	if s.config.Mode != configuration.CAT_MODE && s.config.Mode != configuration.TAIL_MODE {
+		return fmt.Errorf("unsupported mode %s for cri datasource", s.config.Mode)
+	}
+
+	var err error
+
+	s.namespaces, err = newMatcher("namespace_regexp", s.config.NamespaceRegexp, "exclude_namespace_regexp", s.config.ExcludeNamespaceRegexp)
+	if err != nil {
+		return err
+	}
+
+	s.pods, err = newMatcher("pod_name_regexp", s.config.PodNameRegexp, "exclude_pod_name_regexp", s.config.ExcludePodNameRegexp)
+	if err != nil {
+		return err
+	}
+
+	s.containers, err = newMatcher("container_name_regexp", s.config.ContainerNameRegexp, "exclude_container_name_regexp", s.config.ExcludeContainerNameRegexp)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *Source) Configure(_ context.Context, yamlConfig []byte, logger *log.Entry, metricsLevel metrics.AcquisitionMetricsLevel) error {
+	s.logger = logger
+	s.metricsLevel = metricsLevel
+
+	return s.UnmarshalConfig(yamlConfig)
+}
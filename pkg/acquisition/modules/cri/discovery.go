@@ -0,0 +1,154 @@
+package criacquisition
+
+import (
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// containerLog is a log file of a container, with the metadata found in its path.
+type containerLog struct {
+	path      string
+	namespace string
+	pod       string
+	container string
+}
+
+// source is the value of the "source" metric label and of the event Src.
+func (cl containerLog) source() string {
+	return cl.namespace + "/" + cl.pod + "/" + cl.container
+}
+
+// parsePodsPath reads the metadata of a file in the kubelet layout:
+//
+//	<namespace>_<pod name>_<pod uid>/<container name>/<restart count>.log
+func parsePodsPath(parts []string) (containerLog, bool) {
+	var cl containerLog
+
+	podFields := strings.Split(parts[0], "_")
+	if len(podFields) != 3 || podFields[0] == "" || podFields[1] == "" {
+		return cl, false
+	}
+
+	restart, ok := strings.CutSuffix(parts[2], ".log")
+	if !ok {
+		return cl, false
+	}
+
+	if _, err := strconv.Atoi(restart); err != nil {
+		return cl, false
+	}
+
+	cl.namespace = podFields[0]
+	cl.pod = podFields[1]
+	cl.container = parts[1]
+
+	return cl, true
+}
+
+// parseContainersPath reads the metadata of a file in the flat layout, usually symlinks to the kubelet layout:
+//
+//	<pod name>_<namespace>_<container name>-<container id>.log
+func parseContainersPath(name string) (containerLog, bool) {
+	var cl containerLog
+
+	name, ok := strings.CutSuffix(name, ".log")
+	if !ok {
+		return cl, false
+	}
+
+	// pod names and namespaces can't contain underscores
+	fields := strings.SplitN(name, "_", 3)
+	if len(fields) != 3 || fields[0] == "" || fields[1] == "" {
+		return cl, false
+	}
+
+	idx := strings.LastIndex(fields[2], "-")
+	if idx <= 0 || idx == len(fields[2])-1 {
+		return cl, false
+	}
+
+	cl.pod = fields[0]
+	cl.namespace = fields[1]
+	cl.container = fields[2][:idx]
+
+	return cl, true
+}
+
+// parseLogPath returns the metadata of a log file, found under logDir in either layout.
+func parseLogPath(logDir string, path string) (containerLog, bool) {
+	rel, err := filepath.Rel(logDir, path)
+	if err != nil {
+		return containerLog{}, false
+	}
+
+	var (
+		cl containerLog
+		ok bool
+	)
+
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+
+	switch len(parts) {
+	case 1:
+		cl, ok = parseContainersPath(parts[0])
+	case 3:
+		cl, ok = parsePodsPath(parts)
+	}
+
+	cl.path = path
+
+	return cl, ok
+}
+
+// discover returns the log files of the selected containers, sorted by path.
+func (s *Source) discover() ([]containerLog, error) {
+	var paths []string
+
+	for _, pattern := range []string{"*.log", filepath.Join("*", "*", "*.log")} {
+		matches, err := filepath.Glob(filepath.Join(s.config.LogDir, pattern))
+		if err != nil {
+			return nil, fmt.Errorf("while listing %s: %w", s.config.LogDir, err)
+		}
+
+		paths = append(paths, matches...)
+	}
+
+	slices.Sort(paths)
+
+	ret := make([]containerLog, 0, len(paths))
+
+	for _, path := range paths {
+		cl, ok := parseLogPath(s.config.LogDir, path)
+		if !ok {
+			s.logger.Tracef("ignoring %s: not a container log", path)
+			continue
+		}
+
+		if !s.namespaces.match(cl.namespace) || !s.pods.match(cl.pod) || !s.containers.match(cl.container) {
+			s.logger.Tracef("ignoring %s: container %s is not selected", path, cl.source())
+			continue
+		}
+
+		ret = append(ret, cl)
+	}
+
+	return ret, nil
+}
+
+// labels returns the configured labels, with the metadata of the container.
+func (s *Source) labels(cl containerLog) map[string]string {
+	labels := maps.Clone(s.config.Labels)
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+
+	labels["namespace"] = cl.namespace
+	labels["pod"] = cl.pod
+	labels["container"] = cl.container
+
+	return labels
+}
@@ -0,0 +1,102 @@
+package criacquisition
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	streamStdout = "stdout"
+	streamStderr = "stderr"
+
+	tagPartial = "P"
+
+	// maxPartialSize bounds the size of a reassembled line, in case a container
+	// writes a huge amount of data without a newline. The runtime splits lines
+	// at 16KiB, so this is only reached by abnormal output.
+	maxPartialSize = 1 << 20
+)
+
+// criLine is a line of a container log file, in the format written by containerd and CRI-O:
+//
+//	2016-10-06T00:17:09.669794202Z stdout F the log message
+//
+// The tag is P for a partial line, which is continued by the next line of the same stream,
+// or F for a full line (or the last part of a partial one).
+type criLine struct {
+	time    time.Time
+	stream  string
+	partial bool
+	content string
+}
+
+func parseCRILine(text string) (criLine, error) {
+	var l criLine
+
+	// the content is allowed to be empty, with or without the separator
+	parts := strings.SplitN(text, " ", 4)
+	if len(parts) < 3 {
+		return l, errors.New("not enough fields")
+	}
+
+	ts, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return l, fmt.Errorf("invalid timestamp: %w", err)
+	}
+
+	l.time = ts
+
+	switch parts[1] {
+	case streamStdout, streamStderr:
+		l.stream = parts[1]
+	default:
+		return l, fmt.Errorf("invalid stream %q", parts[1])
+	}
+
+	// tags are separated by ':', only the first one is defined for now
+	tag, _, _ := strings.Cut(parts[2], ":")
+	l.partial = tag == tagPartial
+
+	if len(parts) == 4 {
+		l.content = parts[3]
+	}
+
+	return l, nil
+}
+
+// assembler joins the partial lines of a log file. Each stream is reassembled separately.
+type assembler struct {
+	partial map[string]*strings.Builder
+}
+
+func newAssembler() *assembler {
+	return &assembler{partial: make(map[string]*strings.Builder)}
+}
+
+// add returns the complete line once the last part of it has been read.
+func (a *assembler) add(l criLine) (criLine, bool) {
+	buf, pending := a.partial[l.stream]
+
+	if l.partial && (!pending || buf.Len()+len(l.content) < maxPartialSize) {
+		if !pending {
+			buf = &strings.Builder{}
+			a.partial[l.stream] = buf
+		}
+
+		buf.WriteString(l.content)
+
+		return l, false
+	}
+
+	if !pending {
+		return l, true
+	}
+
+	buf.WriteString(l.content)
+	l.content = buf.String()
+	delete(a.partial, l.stream)
+
+	return l, true
+}
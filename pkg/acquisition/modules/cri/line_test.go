@@ -0,0 +1,132 @@
+package criacquisition
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/crowdsecurity/go-cs-lib/cstest"
+)
+
+func TestParseCRILine(t *testing.T) {
+	tests := []struct {
+		name        string
+		line        string
+		expected    criLine
+		expectedErr string
+	}{
+		{
+			name: "full line",
+			line: "2016-10-06T00:17:09.669794202Z stdout F the log message",
+			expected: criLine{
+				time:    time.Date(2016, 10, 6, 0, 17, 9, 669794202, time.UTC),
+				stream:  "stdout",
+				content: "the log message",
+			},
+		},
+		{
+			name: "partial line",
+			line: "2016-10-06T00:17:09.669794202+02:00 stderr P part ",
+			expected: criLine{
+				time:    time.Date(2016, 10, 5, 22, 17, 9, 669794202, time.UTC),
+				stream:  "stderr",
+				partial: true,
+				content: "part ",
+			},
+		},
+		{
+			name: "empty content",
+			line: "2016-10-06T00:17:09Z stdout F",
+			expected: criLine{
+				time:   time.Date(2016, 10, 6, 0, 17, 9, 0, time.UTC),
+				stream: "stdout",
+			},
+		},
+		{
+			name: "unknown tags",
+			line: "2016-10-06T00:17:09Z stdout P:X message",
+			expected: criLine{
+				time:    time.Date(2016, 10, 6, 0, 17, 9, 0, time.UTC),
+				stream:  "stdout",
+				partial: true,
+				content: "message",
+			},
+		},
+		{
+			name:        "json-file line",
+			line:        `{"log":"message\n","stream":"stdout","time":"2016-10-06T00:17:09Z"}`,
+			expectedErr: "not enough fields",
+		},
+		{
+			name:        "bad timestamp",
+			line:        "Oct 6 00:17:09 host message",
+			expectedErr: "invalid timestamp",
+		},
+		{
+			name:        "bad stream",
+			line:        "2016-10-06T00:17:09Z stdin F message",
+			expectedErr: `invalid stream "stdin"`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			l, err := parseCRILine(tc.line)
+			cstest.RequireErrorContains(t, err, tc.expectedErr)
+
+			if tc.expectedErr != "" {
+				return
+			}
+
+			assert.True(t, tc.expected.time.Equal(l.time), "expected %s, got %s", tc.expected.time, l.time)
+			assert.Equal(t, tc.expected.stream, l.stream)
+			assert.Equal(t, tc.expected.partial, l.partial)
+			assert.Equal(t, tc.expected.content, l.content)
+		})
+	}
+}
+
+func TestAssembler(t *testing.T) {
+	a := newAssembler()
+
+	add := func(stream string, partial bool, content string) (string, bool) {
+		l, ok := a.add(criLine{stream: stream, partial: partial, content: content})
+		return l.content, ok
+	}
+
+	_, ok := add("stdout", true, "hello ")
+	require.False(t, ok)
+
+	// the streams are interleaved
+	content, ok := add("stderr", false, "an error")
+	require.True(t, ok)
+	assert.Equal(t, "an error", content)
+
+	_, ok = add("stdout", true, "wonderful ")
+	require.False(t, ok)
+
+	content, ok = add("stdout", false, "world")
+	require.True(t, ok)
+	assert.Equal(t, "hello wonderful world", content)
+
+	content, ok = add("stdout", false, "next")
+	require.True(t, ok)
+	assert.Equal(t, "next", content)
+
+	// a runaway partial line is flushed
+	chunk := strings.Repeat("a", maxPartialSize/2)
+
+	_, ok = add("stdout", true, chunk)
+	require.False(t, ok)
+
+	content, ok = add("stdout", true, chunk)
+	require.True(t, ok)
+	assert.Len(t, content, maxPartialSize)
+
+	content, ok = add("stdout", false, "end")
+	require.True(t, ok)
+	assert.Equal(t, "end", content)
+}
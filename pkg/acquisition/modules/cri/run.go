@@ -0,0 +1,307 @@
+package criacquisition
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/nxadm/tail"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/crowdsecurity/go-cs-lib/trace"
+
+	"github.com/crowdsecurity/crowdsec/pkg/metrics"
+	"github.com/crowdsecurity/crowdsec/pkg/pipeline"
+)
+
+// logReader turns the lines of a container log file into events.
+type logReader struct {
+	s      *Source
+	cl     containerLog
+	labels map[string]string
+	asm    *assembler
+	warned bool
+}
+
+func (s *Source) newLogReader(cl containerLog) *logReader {
+	return &logReader{
+		s:      s,
+		cl:     cl,
+		labels: s.labels(cl),
+		asm:    newAssembler(),
+	}
+}
+
+func (s *Source) followStream(stream string) bool {
+	switch stream {
+	case streamStdout:
+		return s.config.FollowStdout
+	case streamStderr:
+		return s.config.FollowStdErr
+	}
+
+	return false
+}
+
+// event returns the event for a line of the log file, when it completes a log line.
+func (r *logReader) event(text string, timeMachine bool) (pipeline.Event, bool) {
+	l, err := parseCRILine(text)
+	if err != nil {
+		if !r.warned {
+			r.s.logger.Warningf("%s: ignoring line not in CRI format (%s), is this a container runtime log?", r.cl.path, err)
+			r.warned = true
+		}
+
+		r.s.logger.Debugf("%s: invalid line: %s", r.cl.path, text)
+
+		return pipeline.Event{}, false
+	}
+
+	if !r.s.followStream(l.stream) {
+		return pipeline.Event{}, false
+	}
+
+	l, complete := r.asm.add(l)
+	if !complete || l.content == "" {
+		return pipeline.Event{}, false
+	}
+
+	src := r.cl.source()
+
+	if r.s.metricsLevel != metrics.AcquisitionMetricsLevelNone {
+		metrics.CRIDataSourceLinesRead.With(prometheus.Labels{"source": src, "datasource_type": ModuleName, "acquis_type": r.labels["type"]}).Inc()
+	}
+
+	evt := pipeline.MakeEvent(timeMachine, pipeline.LOG, true)
+	evt.Line = pipeline.Line{
+		Raw:     l.content,
+		Labels:  r.labels,
+		Time:    l.time,
+		Src:     src,
+		Process: true,
+		Module:  ModuleName,
+	}
+
+	return evt, true
+}
+
+func (s *Source) OneShot(ctx context.Context, out chan pipeline.Event) error {
+	logs, err := s.discover()
+	if err != nil {
+		return err
+	}
+
+	for _, cl := range logs {
+		s.logger.Infof("reading %s at once", cl.path)
+
+		if err := s.readFile(ctx, cl, out); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Source) readFile(ctx context.Context, cl containerLog, out chan pipeline.Event) error {
+	fd, err := os.Open(cl.path)
+	if err != nil {
+		return fmt.Errorf("failed opening %s: %w", cl.path, err)
+	}
+
+	defer fd.Close()
+
+	r := s.newLogReader(cl)
+
+	scanner := bufio.NewScanner(fd)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxPartialSize)
+
+	for scanner.Scan() {
+		// we're reading logs at once, it must be time-machine buckets
+		evt, ok := r.event(scanner.Text(), true)
+		if !ok {
+			continue
+		}
+
+		select {
+		case out <- evt:
+		case <-ctx.Done():
+			s.logger.Info("CRI datasource stopping")
+			return nil
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("while reading %s: %w", cl.path, err)
+	}
+
+	return nil
+}
+
+// tailedLog is a log file being followed by Stream.
+type tailedLog struct {
+	tail *tail.Tail
+	// the file was not found by the last discovery
+	missing bool
+}
+
+func (s *Source) Stream(ctx context.Context, out chan pipeline.Event) error {
+	var wg sync.WaitGroup
+
+	s.logger.Infof("starting CRI acquisition in %s", s.config.LogDir)
+
+	tails := make(map[string]*tailedLog)
+
+	defer func() {
+		for path, tl := range tails {
+			s.stopTail(path, tl)
+		}
+
+		wg.Wait()
+	}()
+
+	ticker := time.NewTicker(s.config.DiscoveryInterval)
+	defer ticker.Stop()
+
+	// the files that exist at startup are read from the end, like the file datasource
+	seekEnd := true
+
+	for {
+		if err := s.refreshTails(ctx, tails, seekEnd, out, &wg); err != nil {
+			return err
+		}
+
+		seekEnd = false
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// refreshTails starts following the new log files, and stops following the removed ones.
+func (s *Source) refreshTails(ctx context.Context, tails map[string]*tailedLog, seekEnd bool, out chan pipeline.Event, wg *sync.WaitGroup) error {
+	logs, err := s.discover()
+	if err != nil {
+		return err
+	}
+
+	found := make(map[string]bool, len(logs))
+
+	for _, cl := range logs {
+		found[cl.path] = true
+
+		fromEnd := seekEnd
+
+		if tl, ok := tails[cl.path]; ok {
+			tl.missing = false
+
+			select {
+			case <-tl.tail.Dying():
+				// don't read again what has already been sent
+				s.logger.Infof("restarting dead tail on %s", cl.path)
+				delete(tails, cl.path)
+
+				fromEnd = true
+			default:
+				continue
+			}
+		}
+
+		t, err := s.tailFile(cl.path, fromEnd)
+		if err != nil {
+			s.logger.Errorf("could not tail %s: %s", cl.path, err)
+			continue
+		}
+
+		tails[cl.path] = &tailedLog{tail: t}
+
+		wg.Go(func() {
+			defer trace.ReportPanic()
+			s.readTail(ctx, t, cl, out)
+		})
+	}
+
+	for path, tl := range tails {
+		if found[path] {
+			continue
+		}
+
+		// log rotation replaces the file: give it a discovery interval to come back
+		if !tl.missing {
+			tl.missing = true
+			continue
+		}
+
+		s.logger.Infof("%s has been removed", path)
+		s.stopTail(path, tl)
+		delete(tails, path)
+	}
+
+	return nil
+}
+
+func (s *Source) tailFile(path string, seekEnd bool) (*tail.Tail, error) {
+	seekInfo := &tail.SeekInfo{Offset: 0, Whence: io.SeekStart}
+	if seekEnd {
+		seekInfo.Whence = io.SeekEnd
+	}
+
+	s.logger.Infof("starting tail of %s (offset: %d, whence: %d)", path, seekInfo.Offset, seekInfo.Whence)
+
+	return tail.TailFile(path, tail.Config{
+		ReOpen:   true,
+		Follow:   true,
+		Poll:     s.config.PollWithoutInotify,
+		Location: seekInfo,
+		Logger:   s.logger,
+	})
+}
+
+func (s *Source) stopTail(path string, tl *tailedLog) {
+	if err := tl.tail.Stop(); err != nil {
+		s.logger.Debugf("while stopping tail of %s: %s", path, err)
+	}
+}
+
+func (s *Source) readTail(ctx context.Context, t *tail.Tail, cl containerLog, out chan pipeline.Event) {
+	r := s.newLogReader(cl)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.Dying():
+			if err := t.Err(); err != nil {
+				s.logger.Warningf("stopped reading %s: %s", cl.path, err)
+			}
+
+			return
+		case line, ok := <-t.Lines:
+			if !ok {
+				return
+			}
+
+			if line.Err != nil {
+				s.logger.Warningf("%s: %s", cl.path, line.Err)
+				continue
+			}
+
+			evt, ok := r.event(line.Text, s.config.UseTimeMachine)
+			if !ok {
+				continue
+			}
+
+			select {
+			case out <- evt:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
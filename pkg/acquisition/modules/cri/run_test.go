@@ -0,0 +1,280 @@
+package criacquisition
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/crowdsecurity/go-cs-lib/cstest"
+
+	"github.com/crowdsecurity/crowdsec/pkg/metrics"
+	"github.com/crowdsecurity/crowdsec/pkg/pipeline"
+)
+
+// waitTimeout is only reached when what we're waiting for never happens.
+const waitTimeout = 10 * time.Second
+
+func testLogger() *log.Entry {
+	return log.WithField("type", ModuleName)
+}
+
+func newTestSource(t *testing.T, config string) *Source {
+	t.Helper()
+
+	s := &Source{}
+	err := s.Configure(t.Context(), []byte(config), testLogger(), metrics.AcquisitionMetricsLevelNone)
+	require.NoError(t, err)
+
+	return s
+}
+
+func writeLog(t *testing.T, path string, lines ...string) {
+	t.Helper()
+
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+
+	fd, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	require.NoError(t, err)
+
+	for _, line := range lines {
+		_, err = fd.WriteString(line + "\n")
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, fd.Close())
+}
+
+func readEvents(t *testing.T, out chan pipeline.Event, n int) []pipeline.Event {
+	t.Helper()
+
+	evts := make([]pipeline.Event, 0, n)
+
+	for range n {
+		select {
+		case evt := <-out:
+			evts = append(evts, evt)
+		case <-time.After(waitTimeout):
+			require.FailNow(t, "timeout waiting for events", "got %d, expected %d", len(evts), n)
+		}
+	}
+
+	return evts
+}
+
+func TestConfigure(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      string
+		expectedErr string
+	}{
+		{
+			name:   "defaults",
+			config: "source: cri",
+		},
+		{
+			name:        "empty log_dir",
+			config:      "source: cri\nlog_dir: \"\"",
+			expectedErr: "log_dir cannot be empty",
+		},
+		{
+			name:        "bad regexp",
+			config:      "source: cri\npod_name_regexp: ['(']",
+			expectedErr: "pod_name_regexp: error parsing regexp: missing closing )",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := Source{}
+			err := s.Configure(t.Context(), []byte(tc.config), testLogger(), metrics.AcquisitionMetricsLevelNone)
+			cstest.RequireErrorContains(t, err, tc.expectedErr)
+		})
+	}
+
+	s := newTestSource(t, "source: cri")
+	assert.Equal(t, defaultLogDir, s.config.LogDir)
+	assert.Equal(t, defaultDiscoveryInterval, s.config.DiscoveryInterval)
+	assert.Equal(t, "tail", s.GetMode())
+	assert.True(t, s.config.FollowStdout)
+	assert.True(t, s.config.FollowStdErr)
+}
+
+func TestParseLogPath(t *testing.T) {
+	logDir := filepath.FromSlash("/var/log/pods")
+
+	tests := []struct {
+		path     string
+		expected containerLog
+		ok       bool
+	}{
+		{
+			path:     "default_nginx-7c5ddbdf54-8x2kq_0f3c1e6a-5b1d-4b8e-9f2a-3c4d5e6f7a8b/nginx/0.log",
+			expected: containerLog{namespace: "default", pod: "nginx-7c5ddbdf54-8x2kq", container: "nginx"},
+			ok:       true,
+		},
+		{
+			path:     "nginx-7c5ddbdf54-8x2kq_default_nginx-proxy-4b1e2f0c9d8a.log",
+			expected: containerLog{namespace: "default", pod: "nginx-7c5ddbdf54-8x2kq", container: "nginx-proxy"},
+			ok:       true,
+		},
+		{path: "default_nginx_uid/nginx/0.log.20240101-000000"},
+		{path: "default_nginx_uid/nginx/0.log.20240101-000000.gz"},
+		{path: "default_nginx_uid/nginx/current.log"},
+		{path: "nginx_default_nginx.log"},
+		{path: "nginx_default.log"},
+		{path: "syslog"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.path, func(t *testing.T) {
+			path := filepath.Join(logDir, filepath.FromSlash(tc.path))
+			cl, ok := parseLogPath(logDir, path)
+			require.Equal(t, tc.ok, ok)
+
+			if !tc.ok {
+				return
+			}
+
+			tc.expected.path = path
+			assert.Equal(t, tc.expected, cl)
+		})
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	dir := t.TempDir()
+
+	writeLog(t, filepath.Join(dir, "prod_web-1_uid1", "nginx", "0.log"))
+	writeLog(t, filepath.Join(dir, "prod_web-1_uid1", "nginx", "1.log"))
+	writeLog(t, filepath.Join(dir, "prod_web-1_uid1", "istio-proxy", "0.log"))
+	writeLog(t, filepath.Join(dir, "prod_web-canary-1_uid2", "nginx", "0.log"))
+	writeLog(t, filepath.Join(dir, "kube-system_coredns-1_uid3", "coredns", "0.log"))
+	writeLog(t, filepath.Join(dir, "README.log"))
+
+	s := newTestSource(t, `
+source: cri
+log_dir: `+dir+`
+exclude_namespace_regexp: [^kube-system$]
+exclude_pod_name_regexp: [-canary-]
+container_name_regexp: [^nginx$, ^coredns$]
+`)
+
+	logs, err := s.discover()
+	require.NoError(t, err)
+
+	paths := []string{}
+	for _, cl := range logs {
+		paths = append(paths, cl.path)
+	}
+
+	assert.Equal(t, []string{
+		filepath.Join(dir, "prod_web-1_uid1", "nginx", "0.log"),
+		filepath.Join(dir, "prod_web-1_uid1", "nginx", "1.log"),
+	}, paths)
+}
+
+func TestOneShot(t *testing.T) {
+	ctx := t.Context()
+	dir := t.TempDir()
+
+	writeLog(t, filepath.Join(dir, "web-1_prod_nginx-0123abcd.log"),
+		"2024-01-02T03:04:05.000000001Z stdout F GET /index.html",
+		"2024-01-02T03:04:06Z stdout P GET /",
+		"2024-01-02T03:04:06Z stderr F upstream timed out",
+		"2024-01-02T03:04:06Z stdout P very/long/",
+		"2024-01-02T03:04:07Z stdout F path.html",
+		"not a CRI line",
+		"2024-01-02T03:04:08Z stdout F",
+	)
+
+	s := newTestSource(t, `
+source: cri
+mode: cat
+log_dir: `+dir+`
+follow_stderr: false
+labels:
+  type: nginx
+`)
+
+	out := make(chan pipeline.Event, 10)
+	require.NoError(t, s.OneShot(ctx, out))
+	close(out)
+
+	evts := []pipeline.Event{}
+	for evt := range out {
+		evts = append(evts, evt)
+	}
+
+	require.Len(t, evts, 2)
+
+	assert.Equal(t, "GET /index.html", evts[0].Line.Raw)
+	assert.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 1, time.UTC), evts[0].Line.Time)
+	assert.Equal(t, pipeline.TIMEMACHINE, evts[0].ExpectMode)
+	assert.Equal(t, "GET /very/long/path.html", evts[1].Line.Raw)
+
+	for _, evt := range evts {
+		assert.Equal(t, "prod/web-1/nginx", evt.Line.Src)
+		assert.Equal(t, ModuleName, evt.Line.Module)
+		assert.Equal(t, map[string]string{
+			"type":      "nginx",
+			"namespace": "prod",
+			"pod":       "web-1",
+			"container": "nginx",
+		}, evt.Line.Labels)
+	}
+}
+
+func TestStream(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "prod_web-1_uid1", "nginx", "0.log")
+
+	writeLog(t, existing, "2024-01-02T03:04:05Z stdout F already there")
+
+	s := newTestSource(t, `
+source: cri
+log_dir: `+dir+`
+discovery_interval: 50ms
+labels:
+  type: nginx
+`)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	out := make(chan pipeline.Event, 10)
+	done := make(chan error, 1)
+
+	go func() { done <- s.Stream(ctx, out) }()
+
+	// wait for the initial discovery before writing
+	time.Sleep(200 * time.Millisecond)
+
+	writeLog(t, existing, "2024-01-02T03:04:06Z stdout F new line")
+
+	evts := readEvents(t, out, 1)
+	assert.Equal(t, "new line", evts[0].Line.Raw)
+	assert.Equal(t, pipeline.LIVE, evts[0].ExpectMode)
+
+	// a container created after startup is read from the beginning
+	writeLog(t, filepath.Join(dir, "prod_api-1_uid2", "api", "0.log"),
+		"2024-01-02T03:04:07Z stdout P first ",
+		"2024-01-02T03:04:07Z stdout F line",
+	)
+
+	evts = readEvents(t, out, 1)
+	assert.Equal(t, "first line", evts[0].Line.Raw)
+	assert.Equal(t, "prod/api-1/api", evts[0].Line.Src)
+
+	cancel()
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(waitTimeout):
+		require.FailNow(t, "timeout waiting for Stream to return")
+	}
+}
@@ -57,6 +57,14 @@ func (*Source) CanRun() error {
 	return nil
 }
 
+func (d *Source) Close() error {
+	if d.Client == nil {
+		return nil
+	}
+
+	return d.Client.Close()
+}
+
 func (d *Source) getContainerTTY(ctx context.Context, containerID string) bool {
 	containerDetails, err := d.Client.ContainerInspect(ctx, containerID, client.ContainerInspectOptions{})
 	if err != nil {
@@ -0,0 +1,5 @@
+//go:build !no_datasource_elasticsearch
+
+package modules
+
+import _ "github.com/crowdsecurity/crowdsec/pkg/acquisition/modules/elasticsearch" // register the datasource
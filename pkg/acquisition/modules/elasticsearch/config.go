@@ -0,0 +1,358 @@
+package elasticsearch
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	yaml "github.com/goccy/go-yaml"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/crowdsecurity/crowdsec/pkg/acquisition/configuration"
+	"github.com/crowdsecurity/crowdsec/pkg/acquisition/modules/elasticsearch/internal/esclient"
+	"github.com/crowdsecurity/crowdsec/pkg/metrics"
+)
+
+const (
+	defaultLimit          = 100
+	defaultTimestampField = "@timestamp"
+	defaultMessageField   = "message"
+	defaultPollInterval   = 5 * time.Second
+	defaultWaitForReady   = 10 * time.Second
+)
+
+type AuthConfiguration struct {
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	APIKey   string `yaml:"api_key"` // base64 encoded "id:api_key", as returned by the create API key API
+}
+
+type TLSConfig struct {
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify"`
+	ClientCert         string `yaml:"client_cert"`
+	ClientKey          string `yaml:"client_key"`
+	CaCert             string `yaml:"ca_cert"`
+}
+
+type Configuration struct {
+	URL             string            `yaml:"url"`              // Elasticsearch or OpenSearch url
+	Index           string            `yaml:"index"`            // index, alias or pattern to search
+	Query           string            `yaml:"query"`            // query in Lucene syntax
+	QueryDSL        map[string]any    `yaml:"query_dsl"`        // query in query DSL, exclusive with query
+	TimestampField  string            `yaml:"timestamp_field"`  // field used to sort and page through documents
+	TiebreakerField string            `yaml:"tiebreaker_field"` // unique field used to page through documents with the same timestamp, _shard_doc in a point in time by default
+	MessageField    string            `yaml:"message_field"`    // field of the document used as the log line
+	Limit           int               `yaml:"limit"`            // number of documents per request
+	Since           time.Duration     `yaml:"since"`            // read the documents from this long ago
+	Until           time.Duration     `yaml:"until"`            // cat mode: stop at the documents from this long ago
+	DelayFor        time.Duration     `yaml:"delay_for"`        // don't read documents more recent than this, they might not be searchable yet
+	PollInterval    time.Duration     `yaml:"poll_interval"`    // delay between requests when all the documents have been read
+	Headers         map[string]string `yaml:"headers"`          // HTTP headers for talking to Elasticsearch
+	WaitForReady    time.Duration     `yaml:"wait_for_ready"`
+	Auth            AuthConfiguration `yaml:"auth"`
+	TLS             *TLSConfig        `yaml:"tls"`
+
+	configuration.DataSourceCommonCfg `yaml:",inline"`
+}
+
+func (s *Source) UnmarshalConfig(yamlConfig []byte) error {
+	err := yaml.UnmarshalWithOptions(yamlConfig, &s.Config, yaml.Strict())
+	if err != nil {
+		return fmt.Errorf("cannot parse Elasticsearch acquisition configuration: %s", yaml.FormatError(err, false, false))
+	}
+
+	if s.Config.URL == "" {
+		return errors.New("Elasticsearch url is mandatory")
+	}
+
+	if s.Config.Index == "" {
+		return errors.New("Elasticsearch index is mandatory")
+	}
+
+	if s.Config.Mode == "" {
+		s.Config.Mode = configuration.TAIL_MODE
+	}
+
+	if s.Config.Mode != configuration.CAT_MODE && s.Config.Mode != configuration.TAIL_MODE {
+		return fmt.Errorf("unsupported mode %s for Elasticsearch datasource", s.Config.Mode)
+	}
+
+	if s.Config.Mode == configuration.TAIL_MODE && s.Config.Until != 0 {
+		return errors.New("until can only be used in cat mode")
+	}
+
+	if err := s.Config.validate(); err != nil {
+		return err
+	}
+
+	if s.Config.Until != 0 && s.Config.Since != 0 && s.Config.Until >= s.Config.Since {
+		return errors.New("until must be lower than since")
+	}
+
+	return nil
+}
+
+// validate checks the options shared by the YAML and DSN configurations, and sets their defaults.
+func (c *Configuration) validate() error {
+	if c.Query == "" && len(c.QueryDSL) == 0 {
+		return errors.New("Elasticsearch query or query_dsl is mandatory")
+	}
+
+	if c.Query != "" && len(c.QueryDSL) > 0 {
+		return errors.New("query and query_dsl are mutually exclusive")
+	}
+
+	if c.Auth.APIKey != "" && (c.Auth.Username != "" || c.Auth.Password != "") {
+		return errors.New("auth.api_key and auth.username/password are mutually exclusive")
+	}
+
+	if c.Limit < 0 {
+		return errors.New("limit cannot be negative")
+	}
+
+	if c.Limit == 0 {
+		c.Limit = defaultLimit
+	}
+
+	if c.TimestampField == "" {
+		c.TimestampField = defaultTimestampField
+	}
+
+	if c.MessageField == "" {
+		c.MessageField = defaultMessageField
+	}
+
+	if c.PollInterval == 0 {
+		c.PollInterval = defaultPollInterval
+	}
+
+	if c.WaitForReady == 0 {
+		c.WaitForReady = defaultWaitForReady
+	}
+
+	if c.Since < 0 || c.Until < 0 || c.DelayFor < 0 || c.PollInterval < 0 {
+		return errors.New("since, until, delay_for and poll_interval cannot be negative")
+	}
+
+	return nil
+}
+
+func (c *Configuration) NewTLSConfig() (*tls.Config, error) {
+	tlsConfig := tls.Config{
+		InsecureSkipVerify: c.TLS.InsecureSkipVerify,
+	}
+
+	if c.TLS.ClientCert != "" || c.TLS.ClientKey != "" {
+		cert, err := tls.LoadX509KeyPair(c.TLS.ClientCert, c.TLS.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client cert/key: %w", err)
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if c.TLS.CaCert != "" {
+		caCert, err := os.ReadFile(c.TLS.CaCert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ca cert: %w", err)
+		}
+
+		caCertPool, err := x509.SystemCertPool()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load system cert pool: %w", err)
+		}
+
+		if caCertPool == nil {
+			caCertPool = x509.NewCertPool()
+		}
+
+		caCertPool.AppendCertsFromPEM(caCert)
+		tlsConfig.RootCAs = caCertPool
+	}
+
+	return &tlsConfig, nil
+}
+
+// queryClause returns the query of the search request.
+func (c *Configuration) queryClause() map[string]any {
+	if len(c.QueryDSL) > 0 {
+		return c.QueryDSL
+	}
+
+	return map[string]any{
+		"query_string": map[string]any{"query": c.Query},
+	}
+}
+
+func (s *Source) newClient(logger *log.Entry) error {
+	clientConfig := esclient.Config{
+		URL:             s.Config.URL,
+		Index:           s.Config.Index,
+		Query:           s.Config.queryClause(),
+		TimestampField:  s.Config.TimestampField,
+		TiebreakerField: s.Config.TiebreakerField,
+		Headers:         s.Config.Headers,
+		Username:        s.Config.Auth.Username,
+		Password:        s.Config.Auth.Password,
+		APIKey:          s.Config.Auth.APIKey,
+		Limit:           s.Config.Limit,
+	}
+
+	if s.Config.TLS != nil {
+		tlsConfig, err := s.Config.NewTLSConfig()
+		if err != nil {
+			return err
+		}
+
+		clientConfig.TLS = tlsConfig
+	}
+
+	s.Client = esclient.NewESClient(clientConfig)
+	s.Client.Logger = logger.WithFields(log.Fields{"component": "elasticsearch-client", "source": s.Config.URL})
+
+	return nil
+}
+
+func (s *Source) Configure(_ context.Context, config []byte, logger *log.Entry, metricsLevel metrics.AcquisitionMetricsLevel) error {
+	s.Config = Configuration{}
+	s.logger = logger
+	s.metricsLevel = metricsLevel
+
+	if err := s.UnmarshalConfig(config); err != nil {
+		return err
+	}
+
+	return s.newClient(logger)
+}
+
+// parseTimeParam reads a DSN time boundary, as a duration before now or a RFC3339 timestamp.
+func parseTimeParam(name string, value string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s in dsn: must be a duration or a RFC3339 timestamp", name)
+	}
+
+	return t, nil
+}
+
+func (s *Source) ConfigureByDSN(_ context.Context, dsn string, labels map[string]string, logger *log.Entry, uuid string) error {
+	s.logger = logger
+	s.Config = Configuration{}
+	s.Config.Mode = configuration.CAT_MODE
+	s.Config.Labels = labels
+	s.Config.UniqueId = uuid
+
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return fmt.Errorf("while parsing dsn '%s': %w", dsn, err)
+	}
+
+	if u.Scheme != ModuleName {
+		return fmt.Errorf("invalid DSN %s for Elasticsearch source, must start with %s://", dsn, ModuleName)
+	}
+
+	if u.Host == "" {
+		return errors.New("empty host")
+	}
+
+	s.Config.Index = u.Path
+	if len(s.Config.Index) > 0 && s.Config.Index[0] == '/' {
+		s.Config.Index = s.Config.Index[1:]
+	}
+
+	if s.Config.Index == "" {
+		return errors.New("empty index, the DSN must be elasticsearch://host:port/<index>?query=...")
+	}
+
+	scheme := "http"
+
+	params := u.Query()
+	now := time.Now()
+
+	if q := params.Get("ssl"); q != "" {
+		scheme = "https"
+	}
+
+	s.Config.Query = params.Get("query")
+	s.Config.TimestampField = params.Get("timestamp_field")
+	s.Config.TiebreakerField = params.Get("tiebreaker_field")
+	s.Config.MessageField = params.Get("message_field")
+	s.Config.Auth.APIKey = params.Get("api_key")
+
+	if insecure := params.Get("insecure_skip_verify"); insecure != "" {
+		skip, err := strconv.ParseBool(insecure)
+		if err != nil {
+			return fmt.Errorf("invalid insecure_skip_verify in dsn: %w", err)
+		}
+
+		s.Config.TLS = &TLSConfig{InsecureSkipVerify: skip}
+	}
+
+	if caCert := params.Get("ca_cert"); caCert != "" {
+		if s.Config.TLS == nil {
+			s.Config.TLS = &TLSConfig{}
+		}
+
+		s.Config.TLS.CaCert = caCert
+	}
+
+	if since := params.Get("since"); since != "" {
+		if s.start, err = parseTimeParam("since", since, now); err != nil {
+			return err
+		}
+	}
+
+	if until := params.Get("until"); until != "" {
+		if s.end, err = parseTimeParam("until", until, now); err != nil {
+			return err
+		}
+	}
+
+	if !s.start.IsZero() && !s.end.IsZero() && !s.end.After(s.start) {
+		return errors.New("until must be after since")
+	}
+
+	if w := params.Get("wait_for_ready"); w != "" {
+		if s.Config.WaitForReady, err = time.ParseDuration(w); err != nil {
+			return fmt.Errorf("invalid wait_for_ready in dsn: %w", err)
+		}
+	}
+
+	if limit := params.Get("limit"); limit != "" {
+		if s.Config.Limit, err = strconv.Atoi(limit); err != nil {
+			return fmt.Errorf("invalid limit in dsn: %w", err)
+		}
+	}
+
+	if logLevel := params.Get("log_level"); logLevel != "" {
+		level, err := log.ParseLevel(logLevel)
+		if err != nil {
+			return fmt.Errorf("invalid log_level in dsn: %w", err)
+		}
+
+		s.Config.LogLevel = level
+		s.logger.Logger.SetLevel(level)
+	}
+
+	s.Config.URL = fmt.Sprintf("%s://%s", scheme, u.Host)
+	if u.User != nil {
+		s.Config.Auth.Username = u.User.Username()
+		s.Config.Auth.Password, _ = u.User.Password()
+	}
+
+	if err := s.Config.validate(); err != nil {
+		return err
+	}
+
+	return s.newClient(logger)
+}
@@ -0,0 +1,390 @@
+package esclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/crowdsecurity/crowdsec/pkg/apiclient/useragent"
+)
+
+// PITKeepAlive is how long a point in time is kept without a search. It covers the
+// delay between the retries of a search that failed.
+const PITKeepAlive = "5m"
+
+type ESClient struct {
+	Logger *log.Entry
+
+	config         Config
+	requestHeaders map[string]string
+
+	client *http.Client
+}
+
+type Config struct {
+	URL   string
+	Index string
+	// Query is the query clause of the search, it's used as a filter
+	Query map[string]any
+
+	TimestampField string
+	// TiebreakerField is an optional secondary sort field, to page through documents with the same timestamp
+	TiebreakerField string
+
+	Headers map[string]string
+
+	Username string
+	Password string
+	APIKey   string
+
+	TLS *tls.Config
+
+	Limit int
+}
+
+func NewESClient(config Config) *ESClient {
+	headers := make(map[string]string)
+	maps.Copy(headers, config.Headers)
+
+	switch {
+	case config.APIKey != "":
+		headers["Authorization"] = "ApiKey " + config.APIKey
+	case config.Username != "" || config.Password != "":
+		headers["Authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(config.Username+":"+config.Password))
+	}
+
+	headers["User-Agent"] = useragent.Default()
+
+	client := &http.Client{}
+
+	if config.TLS != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = config.TLS
+		client.Transport = transport
+	}
+
+	return &ESClient{
+		Logger:         log.WithField("component", "elasticsearch-client"),
+		config:         config,
+		requestHeaders: headers,
+		client:         client,
+	}
+}
+
+func (c *ESClient) do(ctx context.Context, method string, uri string, body []byte) (*http.Response, error) {
+	var reader io.Reader = http.NoBody
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, uri, reader)
+	if err != nil {
+		return nil, err
+	}
+
+	for k, v := range c.requestHeaders {
+		request.Header.Set(k, v)
+	}
+
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	c.Logger.Debugf("%s %s", method, uri)
+
+	return c.client.Do(request)
+}
+
+// ResponseError is a request refused by the cluster.
+type ResponseError struct {
+	StatusCode int
+	Type       string
+	Reason     string
+	// types of the root causes, as reported by the cluster
+	rootCauses []string
+	body       string
+}
+
+func (e *ResponseError) Error() string {
+	if e.Reason != "" {
+		return fmt.Sprintf("bad HTTP response code %d: %s: %s", e.StatusCode, e.Type, e.Reason)
+	}
+
+	return fmt.Sprintf("bad HTTP response code %d: %s", e.StatusCode, e.body)
+}
+
+func (e *ResponseError) hasType(errType string) bool {
+	return e.Type == errType || slices.Contains(e.rootCauses, errType)
+}
+
+// responseError returns the reason of a failed request, as reported by the cluster if possible.
+func responseError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+
+	ret := &ResponseError{StatusCode: resp.StatusCode, body: string(body)}
+
+	var errResp errorResponse
+
+	if err := json.Unmarshal(body, &errResp); err == nil && errResp.Error.Reason != "" {
+		ret.Type = errResp.Error.Type
+		ret.Reason = errResp.Error.Reason
+
+		for _, cause := range errResp.Error.RootCause {
+			ret.rootCauses = append(ret.rootCauses, cause.Type)
+		}
+	}
+
+	return ret
+}
+
+// IsPermanent reports whether a request can't succeed by retrying it: it's invalid,
+// or the credentials are refused.
+func IsPermanent(err error) bool {
+	var respErr *ResponseError
+	if !errors.As(err, &respErr) {
+		return false
+	}
+
+	switch respErr.StatusCode {
+	case http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden:
+		return true
+	}
+
+	return false
+}
+
+// IsPITMissing reports whether a search failed because its point in time has expired.
+func IsPITMissing(err error) bool {
+	var respErr *ResponseError
+
+	return errors.As(err, &respErr) && respErr.hasType("search_context_missing_exception")
+}
+
+// IsPITUnsupported reports whether the cluster has no point in time API, like OpenSearch.
+func IsPITUnsupported(err error) bool {
+	var respErr *ResponseError
+	if !errors.As(err, &respErr) {
+		return false
+	}
+
+	switch respErr.StatusCode {
+	case http.StatusBadRequest, http.StatusNotFound, http.StatusMethodNotAllowed:
+		return !respErr.hasType("index_not_found_exception")
+	}
+
+	return false
+}
+
+// Ready waits until the cluster answers, or the context is done.
+func (c *ESClient) Ready(ctx context.Context) error {
+	tick := time.NewTicker(500 * time.Millisecond)
+	defer tick.Stop()
+
+	var lastErr error
+
+	for {
+		c.Logger.Debug("Checking if Elasticsearch is ready")
+
+		resp, err := c.do(ctx, http.MethodGet, c.config.URL, nil)
+		if err == nil {
+			if resp.StatusCode == http.StatusOK {
+				resp.Body.Close()
+				c.Logger.Info("Elasticsearch is ready")
+
+				return nil
+			}
+
+			err = responseError(resp)
+			resp.Body.Close()
+		}
+
+		// don't hide the actual reason behind the request being canceled
+		if lastErr == nil || ctx.Err() == nil {
+			lastErr = err
+		}
+
+		c.Logger.Debugf("Elasticsearch is not ready: %s", err)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w (last error: %w)", ctx.Err(), lastErr)
+		case <-tick.C:
+		}
+	}
+}
+
+// SearchRequest is a page of the documents matching the query with a timestamp between
+// Start and End (a zero time is no bound), sorted by timestamp. SearchAfter is the sort
+// values of the last document of the previous page.
+type SearchRequest struct {
+	// PIT is the point in time the search runs in, if any. The documents are then
+	// sorted on _shard_doc after the timestamp, unless a tiebreaker field is set.
+	PIT         string
+	Start       time.Time
+	End         time.Time
+	SearchAfter []any
+}
+
+type SearchResult struct {
+	Hits []Hit
+	// PIT is the id of the point in time to use for the next page
+	PIT string
+}
+
+func (c *ESClient) searchBody(req SearchRequest) map[string]any {
+	timeRange := map[string]any{"format": "epoch_millis"}
+
+	if !req.Start.IsZero() {
+		timeRange["gte"] = req.Start.UnixMilli()
+	}
+
+	if !req.End.IsZero() {
+		timeRange["lte"] = req.End.UnixMilli()
+	}
+
+	sort := []any{
+		map[string]any{c.config.TimestampField: map[string]any{"order": "asc"}},
+	}
+
+	switch {
+	case c.config.TiebreakerField != "":
+		sort = append(sort, map[string]any{c.config.TiebreakerField: map[string]any{"order": "asc"}})
+	case req.PIT != "":
+		sort = append(sort, map[string]any{"_shard_doc": map[string]any{"order": "asc"}})
+	}
+
+	body := map[string]any{
+		"size": c.config.Limit,
+		"query": map[string]any{
+			"bool": map[string]any{
+				"filter": []any{
+					c.config.Query,
+					map[string]any{"range": map[string]any{c.config.TimestampField: timeRange}},
+				},
+			},
+		},
+		"sort":             sort,
+		"track_total_hits": false,
+	}
+
+	if req.PIT != "" {
+		body["pit"] = map[string]any{"id": req.PIT, "keep_alive": PITKeepAlive}
+	}
+
+	if len(req.SearchAfter) > 0 {
+		body["search_after"] = req.SearchAfter
+	}
+
+	return body
+}
+
+// Search returns a page of documents.
+// See: https://www.elastic.co/guide/en/elasticsearch/reference/current/paginate-search-results.html#search-after
+func (c *ESClient) Search(ctx context.Context, req SearchRequest) (*SearchResult, error) {
+	// a search in a point in time has no index, it's the one of the point in time
+	uri, err := url.JoinPath(c.config.URL, "_search")
+	if req.PIT == "" {
+		uri, err = url.JoinPath(c.config.URL, c.config.Index, "_search")
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid Elasticsearch URL %q: %w", c.config.URL, err)
+	}
+
+	body, err := json.Marshal(c.searchBody(req))
+	if err != nil {
+		return nil, fmt.Errorf("while encoding the search request: %w", err)
+	}
+
+	c.Logger.Tracef("search request: %s", body)
+
+	resp, err := c.do(ctx, http.MethodPost, uri, body)
+	if err != nil {
+		return nil, fmt.Errorf("searching %s: %w", c.config.Index, err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("searching %s: %w", c.config.Index, responseError(resp))
+	}
+
+	var result searchResponse
+
+	dec := json.NewDecoder(resp.Body)
+	// keep the sort values as they are, they are sent back in search_after
+	dec.UseNumber()
+
+	if err := dec.Decode(&result); err != nil {
+		return nil, fmt.Errorf("cannot decode search response: %w", err)
+	}
+
+	return &SearchResult{Hits: result.Hits.Hits, PIT: result.PitID}, nil
+}
+
+// OpenPIT opens a point in time on the index, to page through the documents as they are now.
+// See: https://www.elastic.co/guide/en/elasticsearch/reference/current/point-in-time-api.html
+func (c *ESClient) OpenPIT(ctx context.Context) (string, error) {
+	uri, err := url.JoinPath(c.config.URL, c.config.Index, "_pit")
+	if err != nil {
+		return "", fmt.Errorf("invalid Elasticsearch URL %q: %w", c.config.URL, err)
+	}
+
+	resp, err := c.do(ctx, http.MethodPost, uri+"?keep_alive="+PITKeepAlive, nil)
+	if err != nil {
+		return "", fmt.Errorf("opening a point in time on %s: %w", c.config.Index, err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("opening a point in time on %s: %w", c.config.Index, responseError(resp))
+	}
+
+	var result pitResponse
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("cannot decode point in time response: %w", err)
+	}
+
+	return result.ID, nil
+}
+
+// ClosePIT releases a point in time before it expires.
+func (c *ESClient) ClosePIT(ctx context.Context, pit string) error {
+	uri, err := url.JoinPath(c.config.URL, "_pit")
+	if err != nil {
+		return fmt.Errorf("invalid Elasticsearch URL %q: %w", c.config.URL, err)
+	}
+
+	body, err := json.Marshal(map[string]any{"id": pit})
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.do(ctx, http.MethodDelete, uri, body)
+	if err != nil {
+		return fmt.Errorf("closing a point in time: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	// it may have expired already
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("closing a point in time: %w", responseError(resp))
+	}
+
+	return nil
+}
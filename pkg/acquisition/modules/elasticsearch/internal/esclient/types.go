@@ -0,0 +1,35 @@
+package esclient
+
+// Hit is a document returned by a search.
+// See: https://www.elastic.co/guide/en/elasticsearch/reference/current/search-search.html#search-api-response-body
+type Hit struct {
+	Index  string         `json:"_index"`
+	ID     string         `json:"_id"`
+	Source map[string]any `json:"_source"`
+	// Sort holds the sort values of the hit, used as search_after for the next page
+	Sort []any `json:"sort"`
+}
+
+type searchResponse struct {
+	// the id of the point in time can change between searches
+	PitID string `json:"pit_id"`
+	Hits  struct {
+		Hits []Hit `json:"hits"`
+	} `json:"hits"`
+}
+
+type pitResponse struct {
+	ID string `json:"id"`
+}
+
+type errorCause struct {
+	Type   string `json:"type"`
+	Reason string `json:"reason"`
+}
+
+type errorResponse struct {
+	Error struct {
+		errorCause
+		RootCause []errorCause `json:"root_cause"`
+	} `json:"error"`
+}
@@ -0,0 +1,379 @@
+package elasticsearch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cenkalti/backoff/v5"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/crowdsecurity/crowdsec/pkg/acquisition/modules/elasticsearch/internal/esclient"
+	"github.com/crowdsecurity/crowdsec/pkg/metrics"
+	"github.com/crowdsecurity/crowdsec/pkg/pipeline"
+)
+
+const (
+	// maxRetryInterval caps the delay between the retries of a failed search
+	maxRetryInterval = time.Minute
+	// closeTimeout bounds the release of a point in time
+	closeTimeout = 5 * time.Second
+)
+
+// lookupField returns the value of a field of a document. Dots in the name are
+// either part of the key, or separate the keys of nested objects.
+func lookupField(doc map[string]any, name string) (any, bool) {
+	if v, ok := doc[name]; ok {
+		return v, true
+	}
+
+	for i := range len(name) {
+		if name[i] != '.' {
+			continue
+		}
+
+		sub, ok := doc[name[:i]].(map[string]any)
+		if !ok {
+			continue
+		}
+
+		if v, ok := lookupField(sub, name[i+1:]); ok {
+			return v, true
+		}
+	}
+
+	return nil, false
+}
+
+func (s *Source) hitMessage(hit *esclient.Hit) (string, bool) {
+	v, ok := lookupField(hit.Source, s.Config.MessageField)
+	if !ok || v == nil {
+		return "", false
+	}
+
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return "", false
+		}
+
+		return string(b), true
+	}
+}
+
+func (s *Source) hitTime(hit *esclient.Hit) time.Time {
+	v, _ := lookupField(hit.Source, s.Config.TimestampField)
+
+	switch v := v.(type) {
+	case string:
+		if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			return t
+		}
+	case json.Number:
+		if ms, err := v.Int64(); err == nil {
+			return time.UnixMilli(ms).UTC()
+		}
+	}
+
+	return time.Now().UTC()
+}
+
+// sendHit sends a document to the output channel. It returns false if the context is done.
+func (s *Source) sendHit(ctx context.Context, hit *esclient.Hit, out chan pipeline.Event, timeMachine bool) bool {
+	msg, ok := s.hitMessage(hit)
+	if !ok {
+		s.logger.Debugf("document %s/%s has no field %q, ignoring it", hit.Index, hit.ID, s.Config.MessageField)
+		return true
+	}
+
+	if s.metricsLevel != metrics.AcquisitionMetricsLevelNone {
+		metrics.ElasticsearchDataSourceLinesRead.With(prometheus.Labels{"source": s.Config.URL, "datasource_type": ModuleName, "acquis_type": s.Config.Labels["type"]}).Inc()
+	}
+
+	evt := pipeline.MakeEvent(timeMachine, pipeline.LOG, true)
+	evt.Line = pipeline.Line{
+		Raw:     msg,
+		Time:    s.hitTime(hit),
+		Src:     s.Config.URL,
+		Labels:  s.Config.Labels,
+		Process: true,
+		Module:  s.GetName(),
+	}
+
+	select {
+	case out <- evt:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (s *Source) waitForReady(ctx context.Context) error {
+	readyCtx, cancel := context.WithTimeout(ctx, s.Config.WaitForReady)
+	defer cancel()
+
+	if err := s.Client.Ready(readyCtx); err != nil {
+		return fmt.Errorf("Elasticsearch is not ready: %w", err)
+	}
+
+	return nil
+}
+
+// cursorOf returns the search_after value to get the documents after a hit.
+func cursorOf(hit *esclient.Hit) ([]any, error) {
+	if len(hit.Sort) == 0 {
+		return nil, fmt.Errorf("document %s/%s has no sort values, cannot page through the results", hit.Index, hit.ID)
+	}
+
+	return hit.Sort, nil
+}
+
+// pager is where a search is in the documents of a time range.
+type pager struct {
+	// point in time of the search, if any
+	pit string
+	// sort values of the last document read
+	cursor []any
+	// in a point in time, the documents read with the timestamp of the cursor: the
+	// _shard_doc of the cursor is not valid in the next point in time
+	read map[string]bool
+}
+
+func hitKey(hit *esclient.Hit) string {
+	return hit.Index + "/" + hit.ID
+}
+
+func sameTimestamp(a []any, b []any) bool {
+	return len(a) > 0 && len(b) > 0 && fmt.Sprint(a[0]) == fmt.Sprint(b[0])
+}
+
+// advance moves the cursor after a hit.
+func (p *pager) advance(hit *esclient.Hit, cursor []any) {
+	if p.pit != "" {
+		if !sameTimestamp(p.cursor, cursor) {
+			p.read = make(map[string]bool)
+		}
+
+		p.read[hitKey(hit)] = true
+	}
+
+	p.cursor = cursor
+}
+
+// usesPIT reports whether the documents are read in a point in time and sorted on _shard_doc,
+// to page through the ones with the same timestamp. It's the case without a tiebreaker field,
+// if the cluster supports it.
+func (s *Source) usesPIT() bool {
+	return s.Config.TiebreakerField == "" && !s.noPIT
+}
+
+func (s *Source) openPIT(ctx context.Context, p *pager) error {
+	pit, err := s.Client.OpenPIT(ctx)
+
+	switch {
+	case err == nil:
+		p.pit = pit
+
+		if len(p.cursor) == 2 {
+			// start again before the documents with the timestamp of the cursor,
+			// the ones already read are skipped
+			p.cursor = []any{p.cursor[0], -1}
+		}
+
+		return nil
+	case esclient.IsPITUnsupported(err):
+		s.logger.Warningf("%s, the documents with the same timestamp can be skipped between two pages: set a tiebreaker_field", err)
+		s.noPIT = true
+		// the sort values have only the timestamp
+		p.cursor, p.read = nil, nil
+
+		return nil
+	default:
+		return err
+	}
+}
+
+// readPages sends the documents between start and end, after the cursor of p. It returns
+// after the last page, or when the context is done.
+func (s *Source) readPages(ctx context.Context, p *pager, start time.Time, end time.Time, out chan pipeline.Event, timeMachine bool) error {
+	if p.pit == "" && s.usesPIT() {
+		if err := s.openPIT(ctx, p); err != nil {
+			return err
+		}
+	}
+
+	for {
+		res, err := s.Client.Search(ctx, esclient.SearchRequest{PIT: p.pit, Start: start, End: end, SearchAfter: p.cursor})
+		if err != nil {
+			if esclient.IsPITMissing(err) {
+				// it has expired during an outage, the next attempt opens another one
+				p.pit = ""
+			}
+
+			return err
+		}
+
+		if res.PIT != "" {
+			p.pit = res.PIT
+		}
+
+		for i := range res.Hits {
+			hit := &res.Hits[i]
+
+			cursor, err := cursorOf(hit)
+			if err != nil {
+				return err
+			}
+
+			if p.pit != "" && sameTimestamp(p.cursor, cursor) && p.read[hitKey(hit)] {
+				p.cursor = cursor
+				continue
+			}
+
+			if !s.sendHit(ctx, hit, out, timeMachine) {
+				return nil
+			}
+
+			p.advance(hit, cursor)
+		}
+
+		if len(res.Hits) < s.Config.Limit || ctx.Err() != nil {
+			return nil
+		}
+	}
+}
+
+// closePIT releases the point in time of p, if any. The cursor is kept for the next one.
+func (s *Source) closePIT(p *pager) {
+	if p.pit == "" {
+		return
+	}
+
+	// the context of the search may be done
+	ctx, cancel := context.WithTimeout(context.Background(), closeTimeout)
+	defer cancel()
+
+	if err := s.Client.ClosePIT(ctx, p.pit); err != nil {
+		s.logger.Debugf("while closing the point in time: %s", err)
+	}
+
+	p.pit = ""
+}
+
+// OneShot reads the documents in the configured time range, and returns when done.
+func (s *Source) OneShot(ctx context.Context, out chan pipeline.Event) error {
+	s.logger.Debug("Elasticsearch one shot acquisition")
+
+	if err := s.waitForReady(ctx); err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	start, end := s.start, s.end
+
+	if start.IsZero() && s.Config.Since != 0 {
+		start = now.Add(-s.Config.Since)
+	}
+
+	if end.IsZero() {
+		// don't chase the documents indexed while reading
+		end = now.Add(-s.Config.Until)
+	}
+
+	s.logger.Infof("reading %s from %s to %s", s.Config.Index, start.Format(time.RFC3339), end.Format(time.RFC3339))
+
+	p := pager{}
+	defer s.closePIT(&p)
+
+	// we're reading logs at once, it must be time-machine buckets
+	err := s.readPages(ctx, &p, start, end, out, true)
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return nil
+	case err != nil:
+		return err
+	case ctx.Err() == nil:
+		s.logger.Info("Elasticsearch acquisition completed")
+	}
+
+	return nil
+}
+
+func newStreamBackOff() backoff.BackOff {
+	bo := backoff.NewExponentialBackOff()
+	bo.InitialInterval = time.Second
+	bo.MaxInterval = maxRetryInterval
+
+	return bo
+}
+
+// Stream polls for new documents, sorted by timestamp, until the context is done. A failed
+// search is retried, unless the request is refused for good (bad query or credentials).
+func (s *Source) Stream(ctx context.Context, out chan pipeline.Event) error {
+	if err := s.waitForReady(ctx); err != nil {
+		return err
+	}
+
+	if s.tailStart.IsZero() {
+		s.tailStart = time.Now().Add(-s.Config.Since)
+	}
+
+	if s.tail.cursor == nil {
+		s.logger.Infof("tailing %s from %s", s.Config.Index, s.tailStart.Format(time.RFC3339))
+	} else {
+		s.logger.Infof("tailing %s after %v", s.Config.Index, s.tail.cursor)
+	}
+
+	defer s.closePIT(&s.tail)
+
+	bo := newStreamBackOff()
+
+	for {
+		end := time.Now().Add(-s.Config.DelayFor)
+
+		err := s.readPages(ctx, &s.tail, s.tailStart, end, out, s.Config.UseTimeMachine)
+
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		if err != nil {
+			if esclient.IsPermanent(err) {
+				return err
+			}
+
+			delay := bo.NextBackOff()
+			s.logger.Errorf("%s, retrying in %s", err, delay)
+
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(delay):
+			}
+
+			continue
+		}
+
+		bo.Reset()
+
+		s.logger.Debugf("read %s until %s", s.Config.Index, end.Format(time.RFC3339Nano))
+
+		// a point in time doesn't see the documents indexed after it's opened
+		s.closePIT(&s.tail)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(s.Config.PollInterval):
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package elasticsearch
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/crowdsecurity/crowdsec/pkg/acquisition/modules/elasticsearch/internal/esclient"
+	"github.com/crowdsecurity/crowdsec/pkg/metrics"
+)
+
+type Source struct {
+	metricsLevel metrics.AcquisitionMetricsLevel
+	Config       Configuration
+
+	Client *esclient.ESClient
+
+	logger *log.Entry
+
+	// time range given as timestamps in a DSN
+	start time.Time
+	end   time.Time
+
+	// where Stream is in the index, kept when it's restarted after an error
+	tailStart time.Time
+	tail      pager
+
+	// the cluster has no point in time API
+	noPIT bool
+}
+
+func (s *Source) GetMode() string {
+	return s.Config.Mode
+}
+
+func (*Source) GetName() string {
+	return ModuleName
+}
+
+func (*Source) CanRun() error {
+	return nil
+}
+
+func (s *Source) GetUuid() string {
+	return s.Config.UniqueId
+}
+
+func (s *Source) Dump() any {
+	return s
+}
@@ -0,0 +1,5 @@
+//go:build !no_datasource_journalremote
+
+package modules
+
+import _ "github.com/crowdsecurity/crowdsec/pkg/acquisition/modules/journalremote" // register the datasource
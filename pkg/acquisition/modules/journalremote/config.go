@@ -0,0 +1,181 @@
+package journalremoteacquisition
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+
+	yaml "github.com/goccy/go-yaml"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/crowdsecurity/crowdsec/pkg/acquisition/configuration"
+	"github.com/crowdsecurity/crowdsec/pkg/metrics"
+)
+
+const (
+	formatSyslog  = "syslog"
+	formatMessage = "message"
+)
+
+// defaultMaxEntrySize is the maximum size of a journal entry, with all its fields.
+const defaultMaxEntrySize = 1024 * 1024
+
+// defaultFields are the journal fields copied to the labels of the events.
+var defaultFields = []string{
+	"_HOSTNAME",
+	"_SYSTEMD_UNIT",
+	"SYSLOG_IDENTIFIER",
+	"PRIORITY",
+}
+
+type TLSConfig struct {
+	ServerCert string `yaml:"server_cert"`
+	ServerKey  string `yaml:"server_key"`
+	// CaCert enables mutual TLS: uploads are only accepted from clients with a certificate signed by this CA
+	CaCert string `yaml:"ca_cert"`
+}
+
+type Configuration struct {
+	ListenAddr string `yaml:"listen_addr"`
+	// Format is the format of the log lines: "syslog" (as printed by journalctl) or "message" (the MESSAGE field only)
+	Format string `yaml:"format"`
+	// Fields are the journal fields added to the labels of the events
+	Fields       []string   `yaml:"fields"`
+	MaxEntrySize int        `yaml:"max_entry_size"`
+	TLS          *TLSConfig `yaml:"tls"`
+
+	configuration.DataSourceCommonCfg `yaml:",inline"`
+}
+
+func ConfigurationFromYAML(y []byte) (Configuration, error) {
+	var cfg Configuration
+
+	if err := yaml.UnmarshalWithOptions(y, &cfg, yaml.Strict()); err != nil {
+		return cfg, fmt.Errorf("cannot parse journalremote acquisition configuration: %s", yaml.FormatError(err, false, false))
+	}
+
+	cfg.SetDefaults()
+
+	if err := cfg.Validate(); err != nil {
+		return cfg, err
+	}
+
+	return cfg, nil
+}
+
+func (c *Configuration) SetDefaults() {
+	if c.Mode == "" {
+		c.Mode = configuration.TAIL_MODE
+	}
+
+	if c.Format == "" {
+		c.Format = formatSyslog
+	}
+
+	if c.Fields == nil {
+		c.Fields = defaultFields
+	}
+
+	if c.MaxEntrySize == 0 {
+		c.MaxEntrySize = defaultMaxEntrySize
+	}
+}
+
+func (c *Configuration) Validate() error {
+	if c.Mode != configuration.TAIL_MODE {
+		return fmt.Errorf("unsupported mode %s for journalremote datasource", c.Mode)
+	}
+
+	if c.ListenAddr == "" {
+		return errors.New("listen_addr is required")
+	}
+
+	switch c.Format {
+	case formatSyslog, formatMessage:
+	default:
+		return fmt.Errorf("invalid format %q: must be one of %s, %s", c.Format, formatSyslog, formatMessage)
+	}
+
+	for _, field := range c.Fields {
+		if !validFieldName(field) {
+			return fmt.Errorf("invalid journal field name %q", field)
+		}
+	}
+
+	if c.MaxEntrySize < 0 {
+		return errors.New("max_entry_size must be positive")
+	}
+
+	if c.TLS != nil {
+		if c.TLS.ServerCert == "" {
+			return errors.New("server_cert is required")
+		}
+
+		if c.TLS.ServerKey == "" {
+			return errors.New("server_key is required")
+		}
+	}
+
+	return nil
+}
+
+func (c *Configuration) NewTLSConfig() (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(c.TLS.ServerCert, c.TLS.ServerKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load server cert/key: %w", err)
+	}
+
+	tlsConfig := tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if c.TLS.CaCert != "" {
+		caCert, err := os.ReadFile(c.TLS.CaCert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ca cert: %w", err)
+		}
+
+		caCertPool := x509.NewCertPool()
+		if !caCertPool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("no certificate found in %s", c.TLS.CaCert)
+		}
+
+		tlsConfig.ClientCAs = caCertPool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return &tlsConfig, nil
+}
+
+func (s *Source) UnmarshalConfig(yamlConfig []byte) error {
+	cfg, err := ConfigurationFromYAML(yamlConfig)
+	if err != nil {
+		return err
+	}
+
+	s.config = cfg
+
+	return nil
+}
+
+func (s *Source) Configure(_ context.Context, yamlConfig []byte, logger *log.Entry, metricsLevel metrics.AcquisitionMetricsLevel) error {
+	s.logger = logger
+	s.metricsLevel = metricsLevel
+
+	if err := s.UnmarshalConfig(yamlConfig); err != nil {
+		return err
+	}
+
+	// fail early instead of in the restart loop of Stream()
+	if s.config.TLS != nil {
+		if _, err := s.config.NewTLSConfig(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
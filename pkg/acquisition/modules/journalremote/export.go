@@ -0,0 +1,163 @@
+package journalremoteacquisition
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// The journal export format is a stream of entries separated by empty lines.
+// Each field is either "NAME=value\n" when the value is text, or
+// "NAME\n" followed by the size of the value as a 64-bit little-endian integer,
+// the value and "\n" when the value is binary or contains newlines.
+// See: https://systemd.io/JOURNAL_EXPORT_FORMATS/
+
+var errEntryTooLarge = errors.New("journal entry too large")
+
+// validFieldName checks the syntax of journal field names, as accepted by journald.
+func validFieldName(name string) bool {
+	if name == "" || len(name) > 64 {
+		return false
+	}
+
+	if name[0] >= '0' && name[0] <= '9' {
+		return false
+	}
+
+	for i := range len(name) {
+		c := name[i]
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') && c != '_' {
+			return false
+		}
+	}
+
+	return true
+}
+
+type exportReader struct {
+	r *bufio.Reader
+	// maxEntrySize bounds the memory used by an entry, including the field names
+	maxEntrySize int
+}
+
+func newExportReader(r io.Reader, maxEntrySize int) *exportReader {
+	return &exportReader{
+		r:            bufio.NewReader(r),
+		maxEntrySize: maxEntrySize,
+	}
+}
+
+// readLine returns a line without its terminating newline, reading at most limit bytes.
+func (e *exportReader) readLine(limit int) ([]byte, error) {
+	var line []byte
+
+	for {
+		chunk, err := e.r.ReadSlice('\n')
+		line = append(line, chunk...)
+
+		if len(line) > limit+1 {
+			return nil, errEntryTooLarge
+		}
+
+		switch {
+		case err == nil:
+			return line[:len(line)-1], nil
+		case errors.Is(err, bufio.ErrBufferFull):
+			continue
+		case errors.Is(err, io.EOF) && len(line) > 0:
+			return nil, io.ErrUnexpectedEOF
+		default:
+			return nil, err
+		}
+	}
+}
+
+// Next returns the fields of the next entry, or io.EOF at the end of the stream.
+// When a field appears several times in an entry, the first value is kept.
+func (e *exportReader) Next() (map[string]string, error) {
+	var fields map[string]string
+
+	size := 0
+
+	for {
+		line, err := e.readLine(e.maxEntrySize - size)
+		if err != nil {
+			// the last entry may not be followed by an empty line
+			if errors.Is(err, io.EOF) && fields != nil {
+				return fields, nil
+			}
+
+			return nil, err
+		}
+
+		if len(line) == 0 {
+			if fields != nil {
+				return fields, nil
+			}
+
+			// skip extra separators
+			continue
+		}
+
+		size += len(line) + 1
+
+		name, value, text := bytes.Cut(line, []byte("="))
+
+		// this also accepts the address fields added by the exporter, like __CURSOR
+		if !validFieldName(string(name)) {
+			return nil, fmt.Errorf("invalid field name %q", name)
+		}
+
+		if !text {
+			if value, err = e.readBinary(e.maxEntrySize - size); err != nil {
+				return nil, fmt.Errorf("field %s: %w", name, err)
+			}
+
+			size += len(value) + 9
+		}
+
+		if fields == nil {
+			fields = make(map[string]string)
+		}
+
+		if _, ok := fields[string(name)]; !ok {
+			fields[string(name)] = string(value)
+		}
+	}
+}
+
+// readBinary reads the size and the value of a binary field.
+func (e *exportReader) readBinary(limit int) ([]byte, error) {
+	var size uint64
+
+	if err := binary.Read(e.r, binary.LittleEndian, &size); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+
+	if size > uint64(max(limit, 0)) {
+		return nil, errEntryTooLarge
+	}
+
+	value := make([]byte, size+1)
+
+	if _, err := io.ReadFull(e.r, value); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+
+	if value[size] != '\n' {
+		return nil, errors.New("missing newline after binary value")
+	}
+
+	return value[:size], nil
+}
+
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+
+	return err
+}
@@ -0,0 +1,156 @@
+package journalremoteacquisition
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/crowdsecurity/go-cs-lib/cstest"
+)
+
+// binaryField encodes a field in the binary form of the export format.
+func binaryField(name string, value string) string {
+	size := make([]byte, 8)
+	binary.LittleEndian.PutUint64(size, uint64(len(value)))
+
+	return name + "\n" + string(size) + value + "\n"
+}
+
+func readAll(t *testing.T, data string, maxEntrySize int) ([]map[string]string, error) {
+	t.Helper()
+
+	r := newExportReader(strings.NewReader(data), maxEntrySize)
+	entries := []map[string]string{}
+
+	for {
+		fields, err := r.Next()
+		if err == io.EOF {
+			return entries, nil
+		}
+
+		if err != nil {
+			return entries, err
+		}
+
+		entries = append(entries, fields)
+	}
+}
+
+func TestExportReader(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		expected    []map[string]string
+		expectedErr string
+	}{
+		{
+			name:     "empty",
+			data:     "",
+			expected: []map[string]string{},
+		},
+		{
+			name: "text fields",
+			data: "__CURSOR=s=1\n__REALTIME_TIMESTAMP=1700000000000000\nMESSAGE=hello\n_HOSTNAME=web1\n\n" +
+				"MESSAGE=a=b\n\n",
+			expected: []map[string]string{
+				{"__CURSOR": "s=1", "__REALTIME_TIMESTAMP": "1700000000000000", "MESSAGE": "hello", "_HOSTNAME": "web1"},
+				{"MESSAGE": "a=b"},
+			},
+		},
+		{
+			name: "binary field",
+			data: "_HOSTNAME=web1\n" + binaryField("MESSAGE", "multi\nline") + "PRIORITY=6\n\n",
+			expected: []map[string]string{
+				{"_HOSTNAME": "web1", "MESSAGE": "multi\nline", "PRIORITY": "6"},
+			},
+		},
+		{
+			name: "no trailing separator, extra separators",
+			data: "\n\nMESSAGE=one\n\n\n\nMESSAGE=two\n",
+			expected: []map[string]string{
+				{"MESSAGE": "one"},
+				{"MESSAGE": "two"},
+			},
+		},
+		{
+			name: "repeated field",
+			data: "MESSAGE=one\nMESSAGE=two\n\n",
+			expected: []map[string]string{
+				{"MESSAGE": "one"},
+			},
+		},
+		{
+			name:        "truncated line",
+			data:        "MESSAGE=one\n\nMESSAGE=tw",
+			expectedErr: "unexpected EOF",
+		},
+		{
+			name:        "truncated binary",
+			data:        binaryField("MESSAGE", "hello")[:12],
+			expectedErr: "field MESSAGE: unexpected EOF",
+		},
+		{
+			name:        "binary without newline",
+			data:        strings.TrimSuffix(binaryField("MESSAGE", "hello"), "\n") + "X",
+			expectedErr: "field MESSAGE: missing newline after binary value",
+		},
+		{
+			name:        "invalid field name",
+			data:        "message=hello\n\n",
+			expectedErr: `invalid field name "message"`,
+		},
+		{
+			name:        "line too long",
+			data:        "MESSAGE=" + strings.Repeat("x", 200) + "\n\n",
+			expectedErr: "journal entry too large",
+		},
+		{
+			name:        "binary too large",
+			data:        binaryField("MESSAGE", strings.Repeat("x", 200)),
+			expectedErr: "field MESSAGE: journal entry too large",
+		},
+		{
+			name:        "entry too large",
+			data:        strings.Repeat("MESSAGE=xxxxxxxx\n", 10) + "\n",
+			expectedErr: "journal entry too large",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			entries, err := readAll(t, tc.data, 128)
+			cstest.RequireErrorContains(t, err, tc.expectedErr)
+
+			if tc.expectedErr != "" {
+				return
+			}
+
+			assert.Equal(t, tc.expected, entries)
+		})
+	}
+}
+
+func TestExportReaderLongLine(t *testing.T) {
+	// longer than the buffer of the reader
+	msg := strings.Repeat("x", 10000)
+
+	entries, err := readAll(t, "MESSAGE="+msg+"\n\n", defaultMaxEntrySize)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, msg, entries[0]["MESSAGE"])
+}
+
+func TestValidFieldName(t *testing.T) {
+	for _, name := range []string{"MESSAGE", "_HOSTNAME", "__CURSOR", "CODE_LINE", "X1"} {
+		assert.True(t, validFieldName(name), name)
+	}
+
+	for _, name := range []string{"", "message", "1X", "A-B", "A B", string(bytes.Repeat([]byte("A"), 65))} {
+		assert.False(t, validFieldName(name), name)
+	}
+}
@@ -0,0 +1,342 @@
+package journalremoteacquisition
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/crowdsecurity/go-cs-lib/cstest"
+
+	"github.com/crowdsecurity/crowdsec/pkg/metrics"
+	"github.com/crowdsecurity/crowdsec/pkg/pipeline"
+)
+
+const testEntries = "__CURSOR=s=1;i=1\n__REALTIME_TIMESTAMP=1700000000123456\n_HOSTNAME=web1\n_SYSTEMD_UNIT=ssh.service\n" +
+	"SYSLOG_IDENTIFIER=sshd\n_PID=1234\nPRIORITY=6\nMESSAGE=Failed password for root from 192.0.2.1 port 22 ssh2\n\n" +
+	"__CURSOR=s=1;i=2\n__REALTIME_TIMESTAMP=1700000001000000\n_HOSTNAME=web1\n_COMM=kernel\nCOREDUMP=1\n\n" +
+	"__CURSOR=s=1;i=3\n__REALTIME_TIMESTAMP=1700000002000000\n_HOSTNAME=web1\n_COMM=cron\nMESSAGE=job done\n\n"
+
+func testLogger() *log.Entry {
+	return log.WithField("type", ModuleName)
+}
+
+func newTestSource(t *testing.T, config string) *Source {
+	t.Helper()
+
+	s := &Source{}
+	err := s.Configure(t.Context(), []byte(config), testLogger(), metrics.AcquisitionMetricsLevelNone)
+	require.NoError(t, err)
+
+	return s
+}
+
+func upload(t *testing.T, url string, contentType string, body string) (int, string) {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodPost, url+uploadPath, strings.NewReader(body))
+	require.NoError(t, err)
+
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	return resp.StatusCode, string(respBody)
+}
+
+func TestConfigure(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      string
+		expectedErr string
+	}{
+		{
+			name:   "minimal",
+			config: "source: journalremote\nlisten_addr: 127.0.0.1:19532",
+		},
+		{
+			name:        "missing listen_addr",
+			config:      "source: journalremote",
+			expectedErr: "listen_addr is required",
+		},
+		{
+			name:        "cat mode",
+			config:      "source: journalremote\nmode: cat\nlisten_addr: 127.0.0.1:19532",
+			expectedErr: "unsupported mode cat for journalremote datasource",
+		},
+		{
+			name:        "bad format",
+			config:      "source: journalremote\nlisten_addr: 127.0.0.1:19532\nformat: json",
+			expectedErr: `invalid format "json": must be one of syslog, message`,
+		},
+		{
+			name:        "bad field",
+			config:      "source: journalremote\nlisten_addr: 127.0.0.1:19532\nfields: [_hostname]",
+			expectedErr: `invalid journal field name "_hostname"`,
+		},
+		{
+			name:        "missing server key",
+			config:      "source: journalremote\nlisten_addr: 127.0.0.1:19532\ntls:\n  server_cert: cert.pem",
+			expectedErr: "server_key is required",
+		},
+		{
+			name:        "missing cert file",
+			config:      "source: journalremote\nlisten_addr: 127.0.0.1:19532\ntls:\n  server_cert: /does/not/exist.pem\n  server_key: /does/not/exist.key",
+			expectedErr: "failed to load server cert/key",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := Source{}
+			err := s.Configure(t.Context(), []byte(tc.config), testLogger(), metrics.AcquisitionMetricsLevelNone)
+			cstest.RequireErrorContains(t, err, tc.expectedErr)
+		})
+	}
+
+	s := newTestSource(t, "source: journalremote\nlisten_addr: 127.0.0.1:19532")
+	assert.Equal(t, "tail", s.GetMode())
+	assert.Equal(t, formatSyslog, s.config.Format)
+	assert.Equal(t, defaultFields, s.config.Fields)
+	assert.Equal(t, defaultMaxEntrySize, s.config.MaxEntrySize)
+}
+
+func TestFormatSyslogLine(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.Local)
+
+	tests := []struct {
+		fields   map[string]string
+		expected string
+	}{
+		{
+			fields:   map[string]string{"_HOSTNAME": "web1", "SYSLOG_IDENTIFIER": "sshd", "_PID": "1234", "MESSAGE": "hello"},
+			expected: "Jan  2 03:04:05 web1 sshd[1234]: hello",
+		},
+		{
+			fields:   map[string]string{"_HOSTNAME": "web1", "_COMM": "kernel", "SYSLOG_PID": "1", "MESSAGE": "hello"},
+			expected: "Jan  2 03:04:05 web1 kernel[1]: hello",
+		},
+		{
+			fields:   map[string]string{"MESSAGE": "hello"},
+			expected: "Jan  2 03:04:05 unknownhost: hello",
+		},
+	}
+
+	for _, tc := range tests {
+		assert.Equal(t, tc.expected, formatSyslogLine(ts, tc.fields))
+	}
+}
+
+func TestUpload(t *testing.T) {
+	s := newTestSource(t, `
+source: journalremote
+listen_addr: 127.0.0.1:19532
+fields: [_HOSTNAME, _SYSTEMD_UNIT, _PID]
+labels:
+  type: syslog
+`)
+
+	out := make(chan pipeline.Event, 10)
+
+	srv := httptest.NewServer(s.handleUpload(t.Context(), out))
+	t.Cleanup(srv.Close)
+
+	status, body := upload(t, srv.URL, contentType, testEntries)
+	assert.Equal(t, http.StatusAccepted, status)
+	assert.Equal(t, "OK.\n", body)
+
+	require.Len(t, out, 2)
+
+	evt := <-out
+	assert.Equal(t, time.UnixMicro(1700000000123456).Local().Format(time.Stamp)+" web1 sshd[1234]: Failed password for root from 192.0.2.1 port 22 ssh2", evt.Line.Raw)
+	assert.Equal(t, time.UnixMicro(1700000000123456).UTC(), evt.Line.Time)
+	assert.Equal(t, "127.0.0.1", evt.Line.Src)
+	assert.Equal(t, ModuleName, evt.Line.Module)
+	assert.Equal(t, pipeline.LIVE, evt.ExpectMode)
+	assert.Equal(t, map[string]string{
+		"type":          "syslog",
+		"_HOSTNAME":     "web1",
+		"_SYSTEMD_UNIT": "ssh.service",
+		"_PID":          "1234",
+	}, evt.Line.Labels)
+
+	// the entry without MESSAGE is ignored
+	evt = <-out
+	assert.Equal(t, time.UnixMicro(1700000002000000).Local().Format(time.Stamp)+" web1 cron: job done", evt.Line.Raw)
+	assert.Equal(t, map[string]string{"type": "syslog", "_HOSTNAME": "web1"}, evt.Line.Labels)
+
+	status, _ = upload(t, srv.URL, "application/json", testEntries)
+	assert.Equal(t, http.StatusUnsupportedMediaType, status)
+
+	status, _ = upload(t, srv.URL, contentType, "MESSAGE=one\n\nmessage=two\n\n")
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	resp, err := http.Get(srv.URL + uploadPath)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
+
+func TestUploadMessageFormat(t *testing.T) {
+	s := newTestSource(t, `
+source: journalremote
+listen_addr: 127.0.0.1:19532
+format: message
+max_entry_size: 128
+`)
+
+	out := make(chan pipeline.Event, 10)
+
+	srv := httptest.NewServer(s.handleUpload(t.Context(), out))
+	t.Cleanup(srv.Close)
+
+	status, _ := upload(t, srv.URL, contentType+"; charset=utf-8", "MESSAGE=one\n\n"+binaryField("MESSAGE", "two\nlines"))
+	assert.Equal(t, http.StatusAccepted, status)
+
+	require.Len(t, out, 2)
+	assert.Equal(t, "one", (<-out).Line.Raw)
+	assert.Equal(t, "two\nlines", (<-out).Line.Raw)
+
+	status, _ = upload(t, srv.URL, contentType, "MESSAGE="+strings.Repeat("x", 200)+"\n\n")
+	assert.Equal(t, http.StatusRequestEntityTooLarge, status)
+}
+
+func TestStream(t *testing.T) {
+	// find a free port
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	addr := l.Addr().String()
+	require.NoError(t, l.Close())
+
+	s := newTestSource(t, "source: journalremote\nlisten_addr: "+addr)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	out := make(chan pipeline.Event, 10)
+	done := make(chan error, 1)
+
+	go func() { done <- s.Stream(ctx, out) }()
+
+	require.Eventually(t, func() bool {
+		conn, err := net.Dial("tcp", addr)
+		if err != nil {
+			return false
+		}
+
+		conn.Close()
+
+		return true
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// a streaming upload, like systemd-journal-upload --follow
+	pr, pw := io.Pipe()
+
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodPost, "http://"+addr+uploadPath, pr)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", contentType)
+
+	respErr := make(chan error, 1)
+
+	go func() {
+		resp, err := http.DefaultClient.Do(req)
+		if err == nil {
+			resp.Body.Close()
+		}
+
+		respErr <- err
+	}()
+
+	_, err = pw.Write([]byte("_HOSTNAME=web1\nMESSAGE=first\n\n"))
+	require.NoError(t, err)
+
+	select {
+	case evt := <-out:
+		assert.Contains(t, evt.Line.Raw, "web1: first")
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "timeout waiting for event")
+	}
+
+	// stopping the datasource must not wait for the upload to end
+	cancel()
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "timeout waiting for Stream to return")
+	}
+
+	pw.Close()
+	<-respErr
+}
+
+// writeTestCert writes a self-signed certificate and its key, and returns their paths.
+func writeTestCert(t *testing.T) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "cert.pem")
+	keyPath := filepath.Join(dir, "key.pem")
+
+	require.NoError(t, os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0o600))
+
+	return certPath, keyPath
+}
+
+func TestNewTLSConfig(t *testing.T) {
+	certPath, keyPath := writeTestCert(t)
+
+	c := &Configuration{TLS: &TLSConfig{ServerCert: certPath, ServerKey: keyPath}}
+
+	tlsConfig, err := c.NewTLSConfig()
+	require.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS12), tlsConfig.MinVersion)
+	assert.Equal(t, tls.NoClientCert, tlsConfig.ClientAuth)
+
+	c.TLS.CaCert = certPath
+
+	tlsConfig, err = c.NewTLSConfig()
+	require.NoError(t, err)
+	assert.Equal(t, tls.RequireAndVerifyClientCert, tlsConfig.ClientAuth)
+}
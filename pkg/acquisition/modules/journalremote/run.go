@@ -0,0 +1,263 @@
+package journalremoteacquisition
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/crowdsecurity/go-cs-lib/trace"
+
+	"github.com/crowdsecurity/crowdsec/pkg/metrics"
+	"github.com/crowdsecurity/crowdsec/pkg/pipeline"
+)
+
+const (
+	uploadPath  = "/upload"
+	contentType = "application/vnd.fdo.journal"
+	// there is no timeout on the body: in follow mode, systemd-journal-upload keeps the request open
+	readHeaderTimeout = 30 * time.Second
+)
+
+var errStopped = errors.New("datasource is stopping")
+
+// entryTime returns the time of a journal entry, as recorded by journald.
+func entryTime(fields map[string]string) time.Time {
+	if usec, err := strconv.ParseInt(fields["__REALTIME_TIMESTAMP"], 10, 64); err == nil {
+		return time.UnixMicro(usec)
+	}
+
+	return time.Now()
+}
+
+// formatSyslogLine builds the line printed by journalctl in its default "short" output mode,
+// so that it can be handled by the same parsers.
+func formatSyslogLine(ts time.Time, fields map[string]string) string {
+	host := fields["_HOSTNAME"]
+	if host == "" {
+		host = "unknownhost"
+	}
+
+	ident := fields["SYSLOG_IDENTIFIER"]
+	if ident == "" {
+		ident = fields["_COMM"]
+	}
+
+	pid := fields["_PID"]
+	if pid == "" {
+		pid = fields["SYSLOG_PID"]
+	}
+
+	ret := ts.Local().Format(time.Stamp) + " " + host
+
+	if ident != "" {
+		ret += " " + ident
+	}
+
+	if pid != "" {
+		ret += "[" + pid + "]"
+	}
+
+	return ret + ": " + fields["MESSAGE"]
+}
+
+// entryToEvent returns the event for a journal entry. It returns false for entries without a message.
+func (s *Source) entryToEvent(fields map[string]string, client string) (pipeline.Event, bool) {
+	msg, ok := fields["MESSAGE"]
+	if !ok {
+		return pipeline.Event{}, false
+	}
+
+	ts := entryTime(fields)
+
+	if s.config.Format == formatSyslog {
+		msg = formatSyslogLine(ts, fields)
+	}
+
+	labels := make(map[string]string, len(s.config.Labels)+len(s.config.Fields))
+
+	for _, field := range s.config.Fields {
+		if v, ok := fields[field]; ok {
+			labels[field] = v
+		}
+	}
+
+	// configured labels take precedence
+	maps.Copy(labels, s.config.Labels)
+
+	evt := pipeline.MakeEvent(s.config.UseTimeMachine, pipeline.LOG, true)
+	evt.Line = pipeline.Line{
+		Raw:     msg,
+		Src:     client,
+		Time:    ts.UTC(),
+		Labels:  labels,
+		Process: true,
+		Module:  s.GetName(),
+	}
+
+	return evt, true
+}
+
+// readEntries sends the events for the entries of an upload, until the end of the body.
+func (s *Source) readEntries(ctx context.Context, r *http.Request, client string, out chan pipeline.Event) (int, error) {
+	reader := newExportReader(r.Body, s.config.MaxEntrySize)
+	count := 0
+
+	for {
+		fields, err := reader.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return count, nil
+			}
+
+			// the connection was closed by Stream()
+			if ctx.Err() != nil {
+				return count, errStopped
+			}
+
+			return count, err
+		}
+
+		evt, ok := s.entryToEvent(fields, client)
+		if !ok {
+			s.logger.Tracef("ignoring entry without message from %s", client)
+			continue
+		}
+
+		if s.metricsLevel != metrics.AcquisitionMetricsLevelNone {
+			metrics.JournalRemoteDataSourceLinesRead.With(prometheus.Labels{"source": client, "datasource_type": ModuleName, "acquis_type": s.config.Labels["type"]}).Inc()
+		}
+
+		select {
+		case out <- evt:
+			count++
+		case <-ctx.Done():
+			return count, errStopped
+		}
+	}
+}
+
+// handleUpload implements the upload endpoint of systemd-journal-remote, with the same status codes,
+// so that systemd-journal-upload reports errors in a meaningful way.
+func (s *Source) handleUpload(ctx context.Context, out chan pipeline.Event) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "Unsupported method.", http.StatusMethodNotAllowed)
+
+			return
+		}
+
+		if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != contentType {
+			http.Error(w, "Content-Type: "+contentType+" is required.", http.StatusUnsupportedMediaType)
+			return
+		}
+
+		if enc := r.Header.Get("Content-Encoding"); enc != "" && enc != "identity" {
+			http.Error(w, "Unsupported Content-Encoding type: "+enc, http.StatusUnsupportedMediaType)
+			return
+		}
+
+		client, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			client = r.RemoteAddr
+		}
+
+		count, err := s.readEntries(ctx, r, client, out)
+
+		switch {
+		case errors.Is(err, errStopped):
+			http.Error(w, "Server is shutting down.", http.StatusServiceUnavailable)
+			return
+		case err != nil:
+			s.logger.Errorf("failed to read upload from %s: %s", client, err)
+
+			if errors.Is(err, errEntryTooLarge) {
+				http.Error(w, "Entry is too large.", http.StatusRequestEntityTooLarge)
+				return
+			}
+
+			http.Error(w, "Failed to process data.", http.StatusBadRequest)
+
+			return
+		}
+
+		s.logger.Debugf("received %d entries from %s", count, client)
+
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte("OK.\n"))
+	}
+}
+
+// Stream receives journal uploads until the context is canceled.
+func (s *Source) Stream(ctx context.Context, out chan pipeline.Event) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc(uploadPath, s.handleUpload(ctx, out))
+
+	server := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+
+	if s.config.TLS != nil {
+		tlsConfig, err := s.config.NewTLSConfig()
+		if err != nil {
+			return err
+		}
+
+		server.TLSConfig = tlsConfig
+	}
+
+	listenConfig := net.ListenConfig{}
+
+	listener, err := listenConfig.Listen(ctx, "tcp", s.config.ListenAddr)
+	if err != nil {
+		return fmt.Errorf("could not listen on %s: %w", s.config.ListenAddr, err)
+	}
+
+	g, gctx := errgroup.WithContext(ctx)
+
+	g.Go(func() error {
+		defer trace.ReportPanic()
+
+		var err error
+
+		if server.TLSConfig != nil {
+			s.logger.Infof("start https server on %s", listener.Addr())
+			err = server.ServeTLS(listener, "", "")
+		} else {
+			s.logger.Infof("start http server on %s", listener.Addr())
+			err = server.Serve(listener)
+		}
+
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("journal upload server failed: %w", err)
+		}
+
+		return nil
+	})
+
+	g.Go(func() error {
+		<-gctx.Done()
+
+		s.logger.Infof("%s datasource stopping", s.GetName())
+
+		// don't wait for the uploads to complete, they can last forever
+		if err := server.Close(); err != nil {
+			return fmt.Errorf("while stopping %s server: %w", s.GetName(), err)
+		}
+
+		return nil
+	})
+
+	return g.Wait()
+}
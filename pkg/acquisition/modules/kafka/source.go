@@ -33,3 +33,11 @@ func (*Source) CanRun() error {
 func (s *Source) Dump() any {
 	return s
 }
+
+func (s *Source) Close() error {
+	if s.Reader == nil {
+		return nil
+	}
+
+	return s.Reader.Close()
+}
@@ -0,0 +1,118 @@
+package acquisition
+
+import (
+	"context"
+	"math/rand/v2"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+	tomb "gopkg.in/tomb.v2"
+
+	"github.com/crowdsecurity/crowdsec/pkg/acquisition/configuration"
+	"github.com/crowdsecurity/crowdsec/pkg/metrics"
+	"github.com/crowdsecurity/crowdsec/pkg/pipeline"
+)
+
+// throttler sits between one datasource (or its transformer) and the shared output
+// channel, and applies the rate_limit policy of the datasource.
+type throttler struct {
+	cfg     *configuration.RateLimitCfg
+	limiter *rate.Limiter
+	logger  *log.Entry
+	// used in tests to make sampling deterministic
+	keep func(ratio float64) bool
+}
+
+func newThrottler(cfg *configuration.RateLimitCfg, logger *log.Entry) *throttler {
+	t := &throttler{
+		cfg:    cfg,
+		logger: logger,
+		keep: func(ratio float64) bool {
+			return rand.Float64() < ratio //nolint:gosec
+		},
+	}
+
+	if cfg.EventsPerSecond > 0 {
+		t.limiter = rate.NewLimiter(rate.Limit(cfg.EventsPerSecond), cfg.Burst)
+	}
+
+	return t
+}
+
+func metricLabels(evt *pipeline.Event) prometheus.Labels {
+	return prometheus.Labels{"source": evt.Line.Src, "datasource_type": evt.Line.Module}
+}
+
+// allow applies the rate cap. It waits for a token with on_full: block, and
+// reports whether the event can go through otherwise.
+func (t *throttler) allow(ctx context.Context, evt *pipeline.Event) bool {
+	if t.limiter == nil {
+		return true
+	}
+
+	if t.cfg.OnFull == configuration.ON_FULL_BLOCK {
+		return t.limiter.Wait(ctx) == nil
+	}
+
+	if t.limiter.Allow() {
+		return true
+	}
+
+	metrics.AcquisitionThrottledLines.With(metricLabels(evt)).Inc()
+
+	return false
+}
+
+// send forwards the event to the output channel, or drops it according to the
+// on_full policy when the channel is full. It returns false if the tomb is dying.
+func (t *throttler) send(evt pipeline.Event, output chan pipeline.Event, acquisTomb *tomb.Tomb) bool {
+	if t.cfg.OnFull != configuration.ON_FULL_BLOCK {
+		select {
+		case output <- evt:
+			return true
+		default:
+		}
+
+		if t.cfg.OnFull == configuration.ON_FULL_DROP || !t.keep(t.cfg.SampleRatio) {
+			t.logger.Tracef("pipeline is full, dropping event %s", evt.Line.Raw)
+			metrics.AcquisitionDroppedLines.With(metricLabels(&evt)).Inc()
+
+			return true
+		}
+	}
+
+	select {
+	case output <- evt:
+		return true
+	case <-acquisTomb.Dying():
+		return false
+	}
+}
+
+func (t *throttler) run(ctx context.Context, input chan pipeline.Event, output chan pipeline.Event, acquisTomb *tomb.Tomb) {
+	t.logger.Info("rate limiter started")
+
+	ctx = acquisTomb.Context(ctx)
+
+	for {
+		select {
+		case <-acquisTomb.Dying():
+			t.logger.Debugf("rate limiter is dying")
+			return
+		case evt, ok := <-input:
+			if !ok {
+				t.logger.Debugf("rate limiter channel is closed, rate limiter is exiting")
+				return
+			}
+
+			if !t.allow(ctx, &evt) {
+				continue
+			}
+
+			if !t.send(evt, output, acquisTomb) {
+				return
+			}
+		}
+	}
+}
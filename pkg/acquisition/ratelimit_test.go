@@ -0,0 +1,158 @@
+package acquisition
+
+import (
+	"testing"
+
+	"github.com/expr-lang/expr/vm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tomb "gopkg.in/tomb.v2"
+
+	"github.com/crowdsecurity/crowdsec/pkg/acquisition/configuration"
+	"github.com/crowdsecurity/crowdsec/pkg/acquisition/types"
+	"github.com/crowdsecurity/crowdsec/pkg/csconfig"
+	"github.com/crowdsecurity/crowdsec/pkg/cwhub"
+	"github.com/crowdsecurity/crowdsec/pkg/pipeline"
+)
+
+// newRateLimitManager returns a manager that runs the given datasource with a
+// rate_limit configuration, and a transform expression if not nil.
+func newRateLimitManager(t *testing.T, source types.DataSource, transform *vm.Program, cfg configuration.RateLimitCfg) *Manager {
+	t.Helper()
+
+	require.NoError(t, cfg.Validate())
+
+	m := NewManager(&csconfig.CrowdsecServiceCfg{}, nil, &cwhub.Hub{})
+	m.sources["test"] = &managedSource{
+		key:       "test",
+		loc:       "test",
+		source:    source,
+		transform: transform,
+		rateLimit: &cfg,
+	}
+
+	return m
+}
+
+// runManager runs the manager in the background, like launchAcquisition.
+func runManager(t *testing.T, m *Manager, out chan pipeline.Event, acquisTomb *tomb.Tomb) chan error {
+	t.Helper()
+
+	done := make(chan error, 1)
+	go func() { done <- m.Run(t.Context(), out, acquisTomb) }()
+
+	return done
+}
+
+// runCatManager runs a cat-mode acquisition to completion, like runCatAcquisition.
+func runCatManager(t *testing.T, m *Manager) []string {
+	t.Helper()
+
+	out := make(chan pipeline.Event, 100)
+	acquisTomb := tomb.Tomb{}
+
+	done := runManager(t, m, out, &acquisTomb)
+
+	if err := waitForAcquisition(t, done); err != nil {
+		acquisTomb.Kill(nil)
+		require.NoError(t, err)
+	}
+
+	got := []string{}
+	for len(out) > 0 {
+		got = append(got, (<-out).Line.Raw)
+	}
+
+	return got
+}
+
+func TestRateLimitValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         configuration.RateLimitCfg
+		expected    configuration.RateLimitCfg
+		expectedErr string
+	}{
+		{
+			name:     "defaults",
+			cfg:      configuration.RateLimitCfg{EventsPerSecond: 0.5},
+			expected: configuration.RateLimitCfg{EventsPerSecond: 0.5, Burst: 1, OnFull: "block"},
+		},
+		{
+			name:     "burst from rate",
+			cfg:      configuration.RateLimitCfg{EventsPerSecond: 100, OnFull: "drop"},
+			expected: configuration.RateLimitCfg{EventsPerSecond: 100, Burst: 100, OnFull: "drop"},
+		},
+		{
+			name:        "negative rate",
+			cfg:         configuration.RateLimitCfg{EventsPerSecond: -1},
+			expectedErr: "rate_limit.events_per_second must be positive",
+		},
+		{
+			name:        "sample ratio out of range",
+			cfg:         configuration.RateLimitCfg{OnFull: "sample", SampleRatio: 2},
+			expectedErr: "rate_limit.sample_ratio must be in ]0, 1] when on_full is sample",
+		},
+		{
+			name:        "sample ratio without sampling",
+			cfg:         configuration.RateLimitCfg{OnFull: "drop", SampleRatio: 0.5},
+			expectedErr: "rate_limit.sample_ratio can only be used when on_full is sample",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.cfg.Validate()
+			if tc.expectedErr != "" {
+				require.EqualError(t, err, tc.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, tc.cfg)
+		})
+	}
+}
+
+func TestRateLimitCap(t *testing.T) {
+	// the rate is low enough for the test to only see the burst
+	m := newRateLimitManager(t, &MockCat{}, nil, configuration.RateLimitCfg{EventsPerSecond: 0.001, Burst: 3, OnFull: "drop"})
+
+	got := runCatManager(t, m)
+
+	assert.Len(t, got, 3)
+}
+
+func TestRateLimitBlock(t *testing.T) {
+	m := newRateLimitManager(t, &MockCat{}, nil, configuration.RateLimitCfg{EventsPerSecond: 1000, Burst: 1})
+
+	got := runCatManager(t, m)
+
+	assert.Len(t, got, 10)
+}
+
+func TestRateLimitDropWhenFull(t *testing.T) {
+	m := newRateLimitManager(t, &MockCat{}, nil, configuration.RateLimitCfg{OnFull: "drop"})
+
+	// nobody reads the output: every event is dropped, and acquisition still terminates
+	out := make(chan pipeline.Event, 2)
+	acquisTomb := tomb.Tomb{}
+
+	done := runManager(t, m, out, &acquisTomb)
+
+	require.NoError(t, waitForAcquisition(t, done))
+	assert.Len(t, out, 2)
+}
+
+func TestRateLimitWithTransform(t *testing.T) {
+	uuid := "transform-rate-limit"
+
+	registerTransform(t, uuid, `[evt.Line.Raw + "-1", evt.Line.Raw + "-2", evt.Line.Raw + "-3"]`)
+
+	m := newRateLimitManager(t, &MockCatTransform{uuid: uuid}, transformRuntimes[uuid],
+		configuration.RateLimitCfg{EventsPerSecond: 0.001, Burst: 2, OnFull: "drop"})
+
+	got := runCatManager(t, m)
+
+	assert.Equal(t, []string{"original-1", "original-2"}, got)
+}
@@ -9,8 +9,11 @@ import (
 	"sort"
 	"strings"
 
-	"github.com/santhosh-tekuri/jsonschema/v6"
 	"github.com/goccy/go-yaml"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/santhosh-tekuri/jsonschema/v6/kind"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
 )
 
 // format a compact error without schema location for testing purposes.
@@ -20,28 +23,56 @@ func compactSchemaErr(err error) error {
 		return err
 	}
 
-	out := ve.BasicOutput()
-	if out == nil || len(out.Errors) == 0 {
-		// Fallback; this may include schema URL, but it's better than losing the error.
-		return err
-	}
+	// like BasicOutput, which reports the error of a $ref instead of the one of its target
+	var msgs []string
 
-	msgs := make([]string, 0, len(out.Errors))
-	for _, u := range out.Errors {
-		if u.Error == nil {
-			continue
-		}
-		loc := u.InstanceLocation
-		if loc == "" {
-			loc = "/"
+	p := message.NewPrinter(language.English)
+
+	var walk func(e *jsonschema.ValidationError)
+	walk = func(e *jsonschema.ValidationError) {
+		for _, cause := range e.Causes {
+			if _, ok := cause.ErrorKind.(*kind.Reference); !ok {
+				loc := "/" + strings.Join(cause.InstanceLocation, "/")
+				msgs = append(msgs, fmt.Sprintf("%s: %s", loc, cause.ErrorKind.LocalizedString(p)))
+			}
+
+			walk(cause)
 		}
-		msgs = append(msgs, fmt.Sprintf("%s: %s", loc, u.Error.String()))
+	}
+
+	walk(ve)
+
+	if len(msgs) == 0 {
+		// Fallback; this may include schema URL, but it's better than losing the error.
+		return err
 	}
 
 	sort.Strings(msgs)
 	return fmt.Errorf("%s", strings.Join(msgs, "; "))
 }
 
+// yamlFileLoader loads the schemas referenced with $ref, which are YAML files too.
+type yamlFileLoader struct{}
+
+func (yamlFileLoader) Load(url string) (any, error) {
+	path, err := jsonschema.FileLoader{}.ToFile(url)
+	if err != nil {
+		return nil, err
+	}
+
+	schemaYAML, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	schemaJSON, err := yaml.YAMLToJSON(schemaYAML)
+	if err != nil {
+		return nil, fmt.Errorf("schema %q: YAML->JSON: %w", path, err)
+	}
+
+	return jsonschema.UnmarshalJSON(bytes.NewReader(schemaJSON))
+}
+
 // ValidateYAML validates configYAML against schemaPath.
 func ValidateYAML(configYAML []byte, schemaPath string) error {
 	if schemaPath == "" {
@@ -60,6 +91,7 @@ func ValidateYAML(configYAML []byte, schemaPath string) error {
 
 	c := jsonschema.NewCompiler()
 	c.DefaultDraft(jsonschema.Draft2020)
+	c.UseLoader(jsonschema.SchemeURLLoader{"file": yamlFileLoader{}})
 
 	schemaYAML, err := os.ReadFile(schemaPath)
 	if err != nil {
@@ -104,6 +104,13 @@ type MetricsProvider interface {
 	GetAggregMetrics() []prometheus.Collector
 }
 
+// Closer is implemented by datasources that hold resources (clients, connections) once
+// configured. Close is only called for a datasource that is configured but never run:
+// a running datasource releases them when its tomb dies.
+type Closer interface {
+	Close() error
+}
+
 // DSNConfigurer is implemented by datasources that support command-line / DSN-based configuration.
 type DSNConfigurer interface {
 	// ConfigureByDSN configures the datasource from a DSN string and labels.
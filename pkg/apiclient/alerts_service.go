@@ -30,9 +30,25 @@ type AlertsListOpts struct {
 	Limit                *int                    `url:"limit,omitempty"`
 	Contains             *bool                   `url:"contains,omitempty"`
 	Kind                 string                  `url:"kind,omitempty"`
+	MachineIDEquals      string                  `url:"machine_id,omitempty"`
+	Meta                 []string                `url:"meta,omitempty"`
+	MinEvents            *int                    `url:"min_events,omitempty"`
+	MaxEvents            *int                    `url:"max_events,omitempty"`
+	Cursor               string                  `url:"cursor,omitempty"`
 	ListOpts
 }
 
+type AlertsStatsOpts struct {
+	GroupBy        string                  `url:"group_by,omitempty"`
+	Bucket         cstime.DurationWithDays `url:"bucket,omitempty"`
+	ScenarioEquals string                  `url:"scenario,omitempty"`
+	OriginEquals   string                  `url:"origin,omitempty"`
+	TypeEquals     string                  `url:"decision_type,omitempty"`
+	Since          cstime.DurationWithDays `url:"since,omitempty"`
+	Until          cstime.DurationWithDays `url:"until,omitempty"`
+	IncludeCAPI    *bool                   `url:"include_capi,omitempty"`
+}
+
 type AlertsDeleteOpts struct {
 	ScopeEquals          string                  `url:"scope,omitempty"`
 	ValueEquals          string                  `url:"value,omitempty"`
@@ -96,6 +112,29 @@ func (s *AlertsService) List(ctx context.Context, opts AlertsListOpts) (*models.
 	return &alerts, resp, nil
 }
 
+func (s *AlertsService) Stats(ctx context.Context, opts AlertsStatsOpts) (*models.AlertStatsResponse, *Response, error) {
+	params, err := qs.Values(opts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("building query: %w", err)
+	}
+
+	u := fmt.Sprintf("%s/alerts/stats?%s", s.client.URLPrefix, params.Encode())
+
+	req, err := s.client.PrepareRequest(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("building request: %w", err)
+	}
+
+	stats := models.AlertStatsResponse{}
+
+	resp, err := s.client.Do(ctx, req, &stats)
+	if err != nil {
+		return nil, resp, fmt.Errorf("performing request: %w", err)
+	}
+
+	return &stats, resp, nil
+}
+
 // to demo query arguments
 func (s *AlertsService) Delete(ctx context.Context, opts AlertsDeleteOpts) (*models.DeleteAlertsResponse, *Response, error) {
 	params, err := qs.Values(opts)
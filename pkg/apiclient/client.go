@@ -324,13 +324,15 @@ func createTransport(url *url.URL) (*http.Transport, *url.URL) {
 
 type Response struct {
 	Response *http.Response
-	// add our pagination stuff
-	// NextPage int
-	// ...
+	// NextCursor is the position after the last item of a list, to ask for the next page. Empty after the last page.
+	NextCursor string
 }
 
 func newResponse(r *http.Response) *Response {
-	return &Response{Response: r}
+	return &Response{
+		Response:   r,
+		NextCursor: r.Header.Get("X-Next-Cursor"),
+	}
 }
 
 type ListOpts struct {
@@ -149,6 +149,34 @@ func TestCreateAlert(t *testing.T) {
 	assert.Equal(t, `["1"]`, w.Body.String())
 }
 
+func TestCreateAlertAudit(t *testing.T) {
+	ctx := t.Context()
+	lapi := SetupLAPITest(t, ctx)
+
+	// the alerts of the agents and the decisions of the profiles are not recorded
+	lapi.InsertAlertFromFile(t, ctx, "./tests/alert_minibulk.json")
+
+	records, err := lapi.DBClient.QueryAuditLog(ctx, database.AuditLogFilter{Action: "alerts"})
+	require.NoError(t, err)
+	assert.Empty(t, records)
+
+	records, err = lapi.DBClient.QueryAuditLog(ctx, database.AuditLogFilter{Action: "decisions"})
+	require.NoError(t, err)
+	assert.Empty(t, records)
+
+	// the decisions that come with the alert, like with cscli decisions add, are
+	w := lapi.InsertAlertFromFile(t, ctx, "./tests/alert_duplicate.json")
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	records, err = lapi.DBClient.QueryAuditLog(ctx, database.AuditLogFilter{Action: "decisions"})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, database.AuditDecisionsCreate, records[0].Action)
+	assert.Equal(t, database.AuditActorMachine, records[0].ActorType)
+	assert.Equal(t, "test", records[0].Actor)
+	assert.Equal(t, 8, records[0].Count)
+}
+
 func TestCreateAllowlistedAlert(t *testing.T) {
 	ctx := t.Context()
 	lapi := SetupLAPITest(t, ctx)
@@ -564,3 +592,72 @@ func TestDeleteAlertTrustedIPS(t *testing.T) {
 	lapi.InsertAlertFromFile(t, ctx, "./tests/alert_sample.json")
 	assertAlertDeletedFromIP("127.0.0.1")
 }
+
+func TestAlertStats(t *testing.T) {
+	ctx := t.Context()
+	lapi := SetupLAPITest(t, ctx)
+	lapi.InsertAlertFromFile(t, ctx, "./tests/alert_ssh-bf.json")
+
+	w := lapi.RecordResponse(t, ctx, http.MethodGet, "/v1/alerts/stats?group_by=country", emptyBody, "password")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"group_by":"country","buckets":[{"key":"FR","alerts":1,"decisions":1}]}`, w.Body.String())
+
+	w = lapi.RecordResponse(t, ctx, http.MethodGet, "/v1/alerts/stats?group_by=scenario&bucket=1d&scenario=crowdsecurity/nope", emptyBody, "password")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"group_by":"scenario","bucket":"1d","buckets":[]}`, w.Body.String())
+
+	w = lapi.RecordResponse(t, ctx, http.MethodGet, "/v1/alerts/stats?group_by=ip", emptyBody, "password")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"message":"cannot group by 'ip' (supported: scenario, country, as, scope, origin, machine): invalid filter"}`, w.Body.String())
+
+	w = lapi.RecordResponse(t, ctx, http.MethodGet, "/v1/alerts/stats?bucket=often", emptyBody, "password")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAlertListCursor(t *testing.T) {
+	ctx := t.Context()
+	lapi := SetupLAPITest(t, ctx)
+	lapi.InsertAlertFromFile(t, ctx, "./tests/alert_bulk.json")
+
+	// the pages of the 20 alerts, the last one has no cursor
+	listPages := func(limit int) []int {
+		seen := map[int64]bool{}
+		sizes := []int{}
+		url := fmt.Sprintf("/v1/alerts?limit=%d", limit)
+
+		for range 5 {
+			w := lapi.RecordResponse(t, ctx, http.MethodGet, url, emptyBody, "password")
+			require.Equal(t, http.StatusOK, w.Code)
+
+			alerts := models.GetAlertsResponse{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &alerts))
+
+			sizes = append(sizes, len(alerts))
+
+			for _, a := range alerts {
+				assert.False(t, seen[a.ID], "alert %d returned twice", a.ID)
+				seen[a.ID] = true
+			}
+
+			cursor := w.Header().Get("X-Next-Cursor")
+			if cursor == "" {
+				break
+			}
+
+			url = fmt.Sprintf("/v1/alerts?limit=%d&cursor=%s", limit, cursor)
+		}
+
+		assert.Len(t, seen, 20)
+
+		return sizes
+	}
+
+	assert.Equal(t, []int{8, 8, 4}, listPages(8))
+	// a full last page can't be told from the others, the next one is empty
+	assert.Equal(t, []int{10, 10, 0}, listPages(10))
+	assert.Equal(t, []int{20}, listPages(0))
+
+	w := lapi.RecordResponse(t, ctx, http.MethodGet, "/v1/alerts?machine_id=test&min_events=1&meta=foo", emptyBody, "password")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "null", w.Body.String())
+}
@@ -8,6 +8,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/crowdsecurity/crowdsec/pkg/database"
 )
 
 func TestAPIKey(t *testing.T) {
@@ -96,4 +98,16 @@ func TestAPIKey(t *testing.T) {
 	assert.Equal(t, bouncers[0].AuthType, bouncers[1].AuthType)
 	assert.False(t, bouncers[0].AutoCreated)
 	assert.True(t, bouncers[1].AutoCreated)
+
+	// created by the bouncer that shares its key
+	dbClient, err := database.NewClient(ctx, config.API.Server.DbConfig, nil)
+	require.NoError(t, err)
+
+	records, err := dbClient.QueryAuditLog(ctx, database.AuditLogFilter{Action: database.AuditBouncersCreate})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, database.AuditActorBouncer, records[0].ActorType)
+	assert.Equal(t, "test", records[0].Actor)
+	assert.Equal(t, "4.3.2.1", records[0].IPAddress)
+	assert.Equal(t, "test@4.3.2.1", records[0].Target)
 }
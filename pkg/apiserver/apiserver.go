@@ -19,6 +19,7 @@ import (
 	log "github.com/sirupsen/logrus"
 	"gopkg.in/tomb.v2"
 
+	"github.com/crowdsecurity/go-cs-lib/ptr"
 	"github.com/crowdsecurity/go-cs-lib/trace"
 
 	"github.com/crowdsecurity/crowdsec/pkg/apiserver/controllers"
@@ -27,6 +28,7 @@ import (
 	"github.com/crowdsecurity/crowdsec/pkg/csnet"
 	"github.com/crowdsecurity/crowdsec/pkg/csplugin"
 	"github.com/crowdsecurity/crowdsec/pkg/database"
+	"github.com/crowdsecurity/crowdsec/pkg/dnsbl"
 	"github.com/crowdsecurity/crowdsec/pkg/logging"
 )
 
@@ -41,6 +43,7 @@ type APIServer struct {
 	httpServer     *http.Server
 	apic           *apic
 	papi           *Papi
+	dnsbl          *dnsbl.Server
 	httpServerTomb tomb.Tomb
 }
 
@@ -134,6 +137,10 @@ func NewServer(ctx context.Context, config *csconfig.LocalApiServerCfg, accessLo
 		}
 	}
 
+	if err := dbClient.StartDecisionIndex(ctx, config.DecisionIndex); err != nil {
+		return nil, err
+	}
+
 	if !log.IsLevelEnabled(log.DebugLevel) {
 		gin.SetMode(gin.ReleaseMode)
 	}
@@ -165,7 +172,7 @@ func NewServer(ctx context.Context, config *csconfig.LocalApiServerCfg, accessLo
 			param.ClientIP,
 			param.TimeStamp.Format(time.RFC1123),
 			param.Method,
-			param.Path,
+			v1.RedactKey(param.Path),
 			param.Request.Proto,
 			param.StatusCode,
 			param.Latency,
@@ -183,6 +190,7 @@ func NewServer(ctx context.Context, config *csconfig.LocalApiServerCfg, accessLo
 		DBClient:                      dbClient,
 		Router:                        router,
 		Profiles:                      config.Profiles,
+		DecisionViews:                 config.DecisionViews,
 		Log:                           accessLogger,
 		ConsoleConfig:                 config.ConsoleConfig,
 		DisableRemoteLapiRegistration: config.DisableRemoteLapiRegistration,
@@ -232,6 +240,15 @@ func NewServer(ctx context.Context, config *csconfig.LocalApiServerCfg, accessLo
 
 	controller.TrustedIPs = trustedIPs
 
+	var dnsblServer *dnsbl.Server
+
+	if config.DNSBL != nil && ptr.OrEmpty(config.DNSBL.Enable) {
+		dnsblServer, err = dnsbl.New(config.DNSBL, dbClient)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &APIServer{
 		cfg:            config,
 		dbClient:       dbClient,
@@ -240,6 +257,7 @@ func NewServer(ctx context.Context, config *csconfig.LocalApiServerCfg, accessLo
 		router:         router,
 		apic:           apiClient,
 		papi:           papiClient,
+		dnsbl:          dnsblServer,
 		httpServerTomb: tomb.Tomb{},
 	}, nil
 }
@@ -354,6 +372,12 @@ func (s *APIServer) Run(ctx context.Context, apiReady chan bool) error {
 		return s.listenAndServeLAPI(ctx, apiReady)
 	})
 
+	if s.dnsbl != nil {
+		s.httpServerTomb.Go(func() error {
+			return s.dnsbl.ListenAndServe(ctx, s.httpServerTomb.Dying())
+		})
+	}
+
 	if err := s.httpServerTomb.Wait(); err != nil {
 		return fmt.Errorf("local API server stopped with error: %w", err)
 	}
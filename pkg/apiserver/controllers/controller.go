@@ -15,12 +15,14 @@ import (
 	"github.com/crowdsecurity/crowdsec/pkg/database"
 	"github.com/crowdsecurity/crowdsec/pkg/logging"
 	"github.com/crowdsecurity/crowdsec/pkg/models"
+	"github.com/crowdsecurity/crowdsec/pkg/types"
 )
 
 type Controller struct {
 	DBClient                      *database.Client
 	Router                        *gin.Engine
 	Profiles                      []*csconfig.ProfileCfg
+	DecisionViews                 []*csconfig.DecisionViewCfg
 	AlertsAddChan                 chan []*models.Alert
 	DecisionDeleteChan            chan []*models.Decision
 	PluginChannel                 chan models.ProfileAlert
@@ -83,6 +85,7 @@ func (c *Controller) NewV1() error {
 	v1Config := v1.ControllerV1Config{
 		DbClient:           c.DBClient,
 		ProfilesCfg:        c.Profiles,
+		DecisionViewsCfg:   c.DecisionViews,
 		DecisionDeleteChan: c.DecisionDeleteChan,
 		AlertsAddChan:      c.AlertsAddChan,
 		PluginChannel:      c.PluginChannel,
@@ -120,23 +123,27 @@ func (c *Controller) NewV1() error {
 
 	jwtAuth := groupV1.Group("")
 	jwtAuth.GET("/refresh_token", c.HandlerV1.Middlewares.JWT.Middleware.RefreshHandler)
-	jwtAuth.Use(authBodyLimit, c.HandlerV1.Middlewares.JWT.Middleware.MiddlewareFunc(), v1.PrometheusMachinesMiddleware)
+	jwtAuth.Use(authBodyLimit, c.HandlerV1.Middlewares.JWT.Middleware.MiddlewareFunc(), v1.PrometheusMachinesMiddleware, c.HandlerV1.SetAuditActor)
 	{
-		jwtAuth.POST("/alerts", c.HandlerV1.CreateAlert)
-		jwtAuth.GET("/alerts", c.HandlerV1.FindAlerts)
-		jwtAuth.HEAD("/alerts", c.HandlerV1.FindAlerts)
-		jwtAuth.GET("/alerts/:alert_id", c.HandlerV1.FindAlertByID)
-		jwtAuth.HEAD("/alerts/:alert_id", c.HandlerV1.FindAlertByID)
-		jwtAuth.DELETE("/alerts/:alert_id", c.HandlerV1.DeleteAlertByID)
-		jwtAuth.DELETE("/alerts", c.HandlerV1.DeleteAlerts)
-		jwtAuth.DELETE("/decisions", c.HandlerV1.DeleteDecisions)
-		jwtAuth.DELETE("/decisions/:decision_id", c.HandlerV1.DeleteDecisionById)
+		// the permissions granted by the roles of the machine
+		perm := c.HandlerV1.Middlewares.JWT.RequirePermission
+
+		jwtAuth.POST("/alerts", perm(types.PermAlertsCreate), c.HandlerV1.CreateAlert)
+		jwtAuth.GET("/alerts", perm(types.PermAlertsRead), c.HandlerV1.FindAlerts)
+		jwtAuth.HEAD("/alerts", perm(types.PermAlertsRead), c.HandlerV1.FindAlerts)
+		jwtAuth.GET("/alerts/stats", perm(types.PermAlertsRead), c.HandlerV1.AlertStats)
+		jwtAuth.GET("/alerts/:alert_id", perm(types.PermAlertsRead), c.HandlerV1.FindAlertByID)
+		jwtAuth.HEAD("/alerts/:alert_id", perm(types.PermAlertsRead), c.HandlerV1.FindAlertByID)
+		jwtAuth.DELETE("/alerts/:alert_id", perm(types.PermAlertsDelete), c.HandlerV1.DeleteAlertByID)
+		jwtAuth.DELETE("/alerts", perm(types.PermAlertsDelete), c.HandlerV1.DeleteAlerts)
+		jwtAuth.DELETE("/decisions", perm(types.PermDecisionsDelete), c.HandlerV1.DeleteDecisions)
+		jwtAuth.DELETE("/decisions/:decision_id", perm(types.PermDecisionsDelete), c.HandlerV1.DeleteDecisionById)
 		jwtAuth.GET("/heartbeat", c.HandlerV1.HeartBeat)
-		jwtAuth.GET("/allowlists", c.HandlerV1.GetAllowlists)
-		jwtAuth.GET("/allowlists/:allowlist_name", c.HandlerV1.GetAllowlist)
-		jwtAuth.GET("/allowlists/check/:ip_or_range", c.HandlerV1.CheckInAllowlist)
-		jwtAuth.HEAD("/allowlists/check/:ip_or_range", c.HandlerV1.CheckInAllowlist)
-		jwtAuth.POST("/allowlists/check", c.HandlerV1.CheckInAllowlistBulk)
+		jwtAuth.GET("/allowlists", perm(types.PermAllowlistsRead), c.HandlerV1.GetAllowlists)
+		jwtAuth.GET("/allowlists/:allowlist_name", perm(types.PermAllowlistsRead), c.HandlerV1.GetAllowlist)
+		jwtAuth.GET("/allowlists/check/:ip_or_range", perm(types.PermAllowlistsRead), c.HandlerV1.CheckInAllowlist)
+		jwtAuth.HEAD("/allowlists/check/:ip_or_range", perm(types.PermAllowlistsRead), c.HandlerV1.CheckInAllowlist)
+		jwtAuth.POST("/allowlists/check", perm(types.PermAllowlistsRead), c.HandlerV1.CheckInAllowlistBulk)
 		jwtAuth.DELETE("/watchers/self", c.HandlerV1.DeleteMachine)
 	}
 
@@ -149,6 +156,14 @@ func (c *Controller) NewV1() error {
 		apiKeyAuth.HEAD("/decisions/stream", c.HandlerV1.StreamDecision)
 	}
 
+	// for the devices that download a blocklist, the key can also be in the URL
+	urlKeyAuth := groupV1.Group("")
+	urlKeyAuth.Use(authBodyLimit, middlewaresv1.KeyFromURL, c.HandlerV1.Middlewares.APIKey.Middleware, v1.PrometheusBouncersMiddleware)
+	{
+		urlKeyAuth.GET("/decisions/export", c.HandlerV1.ExportDecisions)
+		urlKeyAuth.HEAD("/decisions/export", c.HandlerV1.ExportDecisions)
+	}
+
 	eitherAuth := groupV1.Group("")
 	eitherAuth.Use(authBodyLimit, eitherAuthMiddleware(c.HandlerV1.Middlewares.JWT.Middleware.MiddlewareFunc(), c.HandlerV1.Middlewares.APIKey.Middleware))
 	{
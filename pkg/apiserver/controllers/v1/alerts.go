@@ -3,6 +3,7 @@ package v1
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
@@ -14,6 +15,9 @@ import (
 	"github.com/google/uuid"
 	log "github.com/sirupsen/logrus"
 
+	"github.com/crowdsecurity/go-cs-lib/cstime"
+
+	"github.com/crowdsecurity/crowdsec/pkg/database"
 	"github.com/crowdsecurity/crowdsec/pkg/database/ent"
 	"github.com/crowdsecurity/crowdsec/pkg/models"
 	"github.com/crowdsecurity/crowdsec/pkg/types"
@@ -296,7 +300,15 @@ func (c *Controller) CreateAlert(gctx *gin.Context) {
 func (c *Controller) FindAlerts(gctx *gin.Context) {
 	ctx := gctx.Request.Context()
 
-	result, err := c.DBClient.QueryAlertWithFilter(ctx, gctx.Request.URL.Query())
+	filter := gctx.Request.URL.Query()
+
+	limit, err := database.AlertsLimit(filter)
+	if err != nil {
+		c.HandleDBErrors(gctx, err)
+		return
+	}
+
+	result, err := c.DBClient.QueryAlertWithFilter(ctx, filter)
 	if err != nil {
 		c.HandleDBErrors(gctx, err)
 		return
@@ -304,6 +316,11 @@ func (c *Controller) FindAlerts(gctx *gin.Context) {
 
 	data := FormatAlerts(result)
 
+	// pass it back as the "cursor" filter, for the next page. A shorter page is the last one.
+	if limit > 0 && len(result) == limit {
+		gctx.Header("X-Next-Cursor", database.AlertCursor(result[len(result)-1]))
+	}
+
 	if gctx.Request.Method == http.MethodHead {
 		gctx.String(http.StatusOK, "")
 		return
@@ -312,6 +329,63 @@ func (c *Controller) FindAlerts(gctx *gin.Context) {
 	gctx.JSON(http.StatusOK, data)
 }
 
+// AlertStats counts the alerts matching the filters and their decisions, per time bucket and per key
+func (c *Controller) AlertStats(gctx *gin.Context) {
+	ctx := gctx.Request.Context()
+
+	filter := gctx.Request.URL.Query()
+	groupBy := filter.Get("group_by")
+	bucketStr := filter.Get("bucket")
+
+	delete(filter, "group_by")
+	delete(filter, "bucket")
+
+	var bucket time.Duration
+
+	if bucketStr != "" {
+		var err error
+
+		bucket, err = cstime.ParseDurationWithDays(bucketStr)
+		if err != nil {
+			gctx.JSON(http.StatusBadRequest, gin.H{"message": fmt.Sprintf("invalid bucket: %s", err)})
+			return
+		}
+	}
+
+	rows, err := c.DBClient.AlertStats(ctx, filter, groupBy, bucket)
+	if errors.Is(err, database.InvalidFilter) {
+		gctx.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	if err != nil {
+		c.HandleDBErrors(gctx, err)
+		return
+	}
+
+	ret := models.AlertStatsResponse{
+		GroupBy: groupBy,
+		Bucket:  bucketStr,
+		Buckets: make([]*models.AlertStatsBucket, 0, len(rows)),
+	}
+
+	for _, row := range rows {
+		b := &models.AlertStatsBucket{
+			Key:       row.GroupKey,
+			Alerts:    row.Alerts,
+			Decisions: row.Decisions,
+		}
+
+		if bucket > 0 {
+			b.Start = time.Unix(row.BucketStart, 0).UTC().Format(time.RFC3339)
+		}
+
+		ret.Buckets = append(ret.Buckets, b)
+	}
+
+	gctx.JSON(http.StatusOK, ret)
+}
+
 // FindAlertByID returns the alert associated with the ID
 func (c *Controller) FindAlertByID(gctx *gin.Context) {
 	ctx := gctx.Request.Context()
@@ -16,6 +16,8 @@ type Controller struct {
 	APIKeyHeader string
 	Middlewares  *middlewares.Middlewares
 	Profiles     []*csprofiles.Runtime
+	// DecisionViews restrict the decisions sent to some bouncers
+	DecisionViews []*DecisionView
 
 	AlertsAddChan      chan []*models.Alert
 	DecisionDeleteChan chan []*models.Decision
@@ -29,6 +31,8 @@ type Controller struct {
 type ControllerV1Config struct {
 	DbClient    *database.Client
 	ProfilesCfg []*csconfig.ProfileCfg
+	// DecisionViewsCfg are the decision views of the bouncers
+	DecisionViewsCfg []*csconfig.DecisionViewCfg
 
 	AlertsAddChan      chan []*models.Alert
 	DecisionDeleteChan chan []*models.Decision
@@ -47,10 +51,16 @@ func New(cfg *ControllerV1Config) (*Controller, error) {
 		return &Controller{}, fmt.Errorf("failed to compile profiles: %w", err)
 	}
 
+	views, err := NewDecisionViews(cfg.DecisionViewsCfg)
+	if err != nil {
+		return &Controller{}, fmt.Errorf("failed to compile decision views: %w", err)
+	}
+
 	v1 := &Controller{
 		DBClient:           cfg.DbClient,
 		APIKeyHeader:       middlewares.APIKeyHeader,
 		Profiles:           profiles,
+		DecisionViews:      views,
 		AlertsAddChan:      cfg.AlertsAddChan,
 		DecisionDeleteChan: cfg.DecisionDeleteChan,
 		PluginChannel:      cfg.PluginChannel,
@@ -11,6 +11,7 @@ import (
 	"github.com/gin-gonic/gin"
 	log "github.com/sirupsen/logrus"
 
+	"github.com/crowdsecurity/crowdsec/pkg/database"
 	"github.com/crowdsecurity/crowdsec/pkg/database/ent"
 	"github.com/crowdsecurity/crowdsec/pkg/models"
 )
@@ -52,6 +53,26 @@ func formatOneDecision(dbDecision *ent.Decision) *models.Decision {
 	}
 }
 
+// restrictDecisions limits the decisions sent to a bouncer to the scopes, origins and scenarios it's allowed to get.
+func restrictDecisions(bouncerInfo *ent.Bouncer, filters map[string][]string) {
+	// never trust the query string for these
+	delete(filters, database.RestrictScopesFilter)
+	delete(filters, database.RestrictOriginsFilter)
+	delete(filters, database.RestrictScenariosFilter)
+
+	if len(bouncerInfo.AllowedScopes) > 0 {
+		filters[database.RestrictScopesFilter] = bouncerInfo.AllowedScopes
+	}
+
+	if len(bouncerInfo.AllowedOrigins) > 0 {
+		filters[database.RestrictOriginsFilter] = bouncerInfo.AllowedOrigins
+	}
+
+	if len(bouncerInfo.AllowedScenarios) > 0 {
+		filters[database.RestrictScenariosFilter] = bouncerInfo.AllowedScenarios
+	}
+}
+
 func (c *Controller) GetDecision(gctx *gin.Context) {
 	var (
 		results []*models.Decision
@@ -67,14 +88,17 @@ func (c *Controller) GetDecision(gctx *gin.Context) {
 		return
 	}
 
-	data, err = c.DBClient.QueryDecisionWithFilter(ctx, gctx.Request.URL.Query())
+	filters := gctx.Request.URL.Query()
+	restrictDecisions(bouncerInfo, filters)
+
+	data, err = c.DBClient.QueryDecisionWithFilter(ctx, filters)
 	if err != nil {
 		c.HandleDBErrors(gctx, err)
 
 		return
 	}
 
-	results = FormatDecisions(data)
+	results = c.decisionView(gctx, bouncerInfo).filter(FormatDecisions(data))
 	/*let's follow a naive logic : when a bouncer queries /decisions, if the answer is empty, we assume there is no decision for this ip/user/...,
 	but if it's non-empty, it means that there is one or more decisions for this target*/
 	if len(results) > 0 {
@@ -155,7 +179,7 @@ func (c *Controller) DeleteDecisions(gctx *gin.Context) {
 	gctx.JSON(http.StatusOK, deleteDecisionResp)
 }
 
-func writeStartupDecisions(gctx *gin.Context, now time.Time, filters map[string][]string, dbFunc func(context.Context, time.Time, map[string][]string) ([]*ent.Decision, error)) error {
+func writeStartupDecisions(gctx *gin.Context, view *DecisionView, now time.Time, filters map[string][]string, dbFunc func(context.Context, time.Time, map[string][]string) ([]*ent.Decision, error)) error {
 	limit := 30000 // FIXME : make it configurable
 	needComma := false
 	lastId := 0
@@ -183,6 +207,11 @@ func writeStartupDecisions(gctx *gin.Context, now time.Time, filters map[string]
 		}
 
 		for _, d := range data {
+			decision := formatOneDecision(d)
+			if !view.Match(decision) {
+				continue
+			}
+
 			if needComma {
 				gctx.Writer.WriteString(",")
 			} else {
@@ -190,7 +219,7 @@ func writeStartupDecisions(gctx *gin.Context, now time.Time, filters map[string]
 			}
 
 			buf.Reset()
-			if err := enc.Encode(formatOneDecision(d)); err != nil {
+			if err := enc.Encode(decision); err != nil {
 				gctx.Writer.Flush()
 
 				return err
@@ -223,7 +252,7 @@ func writeStartupDecisions(gctx *gin.Context, now time.Time, filters map[string]
 	return nil
 }
 
-func writeDeltaDecisions(gctx *gin.Context, now time.Time, filters map[string][]string, lastPull *time.Time, dbFunc func(context.Context, time.Time, *time.Time, map[string][]string) ([]*ent.Decision, error)) error {
+func writeDeltaDecisions(gctx *gin.Context, view *DecisionView, now time.Time, filters map[string][]string, lastPull *time.Time, dbFunc func(context.Context, time.Time, *time.Time, map[string][]string) ([]*ent.Decision, error)) error {
 	limit := 30000 // FIXME : make it configurable
 	needComma := false
 	lastId := 0
@@ -251,6 +280,11 @@ func writeDeltaDecisions(gctx *gin.Context, now time.Time, filters map[string][]
 		}
 
 		for _, d := range data {
+			decision := formatOneDecision(d)
+			if !view.Match(decision) {
+				continue
+			}
+
 			if needComma {
 				gctx.Writer.WriteString(",")
 			} else {
@@ -258,7 +292,7 @@ func writeDeltaDecisions(gctx *gin.Context, now time.Time, filters map[string][]
 			}
 
 			buf.Reset()
-			if err := enc.Encode(formatOneDecision(d)); err != nil {
+			if err := enc.Encode(decision); err != nil {
 				gctx.Writer.Flush()
 
 				return err
@@ -297,12 +331,14 @@ func (c *Controller) streamDecisions(gctx *gin.Context, bouncerInfo *ent.Bouncer
 	gctx.Writer.Header().Set("Content-Type", "application/json")
 	gctx.Writer.Header().Set("Transfer-Encoding", "chunked")
 	gctx.Writer.WriteHeader(http.StatusOK)
+	view := c.decisionView(gctx, bouncerInfo)
+
 	gctx.Writer.WriteString(`{"new": [`) // No need to check for errors, the doc says it always returns nil
 
 	// if the blocker just started, return all decisions
 	if val, ok := gctx.Request.URL.Query()["startup"]; ok && val[0] == "true" {
 		// Active decisions
-		err := writeStartupDecisions(gctx, now, filters, c.DBClient.QueryAllDecisionsWithFilters)
+		err := writeStartupDecisions(gctx, view, now, filters, c.DBClient.QueryAllDecisionsWithFilters)
 		if err != nil {
 			log.Errorf("failed sending new decisions for startup: %v", err)
 			gctx.Writer.WriteString(`], "deleted": []}`)
@@ -313,7 +349,7 @@ func (c *Controller) streamDecisions(gctx *gin.Context, bouncerInfo *ent.Bouncer
 
 		gctx.Writer.WriteString(`], "deleted": [`)
 		// Expired decisions
-		err = writeStartupDecisions(gctx, now, filters, c.DBClient.QueryExpiredDecisionsWithFilters)
+		err = writeStartupDecisions(gctx, view, now, filters, c.DBClient.QueryExpiredDecisionsWithFilters)
 		if err != nil {
 			log.Errorf("failed sending expired decisions for startup: %v", err)
 			gctx.Writer.WriteString(`]}`)
@@ -325,7 +361,7 @@ func (c *Controller) streamDecisions(gctx *gin.Context, bouncerInfo *ent.Bouncer
 		gctx.Writer.WriteString(`]}`)
 		gctx.Writer.Flush()
 	} else {
-		err = writeDeltaDecisions(gctx, now, filters, bouncerInfo.LastPull, c.DBClient.QueryNewDecisionsSinceWithFilters)
+		err = writeDeltaDecisions(gctx, view, now, filters, bouncerInfo.LastPull, c.DBClient.QueryNewDecisionsSinceWithFilters)
 		if err != nil {
 			log.Errorf("failed sending new decisions for delta: %v", err)
 			gctx.Writer.WriteString(`], "deleted": []}`)
@@ -343,7 +379,7 @@ func (c *Controller) streamDecisions(gctx *gin.Context, bouncerInfo *ent.Bouncer
 			expiredSince = &since
 		}
 
-		err = writeDeltaDecisions(gctx, now, filters, expiredSince, c.DBClient.QueryExpiredDecisionsSinceWithFilters)
+		err = writeDeltaDecisions(gctx, view, now, filters, expiredSince, c.DBClient.QueryExpiredDecisionsSinceWithFilters)
 		if err != nil {
 			log.Errorf("failed sending expired decisions for delta: %v", err)
 			gctx.Writer.WriteString("]}")
@@ -382,6 +418,8 @@ func (c *Controller) StreamDecision(gctx *gin.Context) {
 		filters["scopes"] = []string{"ip,range"}
 	}
 
+	restrictDecisions(bouncerInfo, filters)
+
 	err = c.streamDecisions(gctx, bouncerInfo, streamStartTime, filters)
 
 	if err == nil {
@@ -0,0 +1,130 @@
+package v1
+
+import (
+	"bytes"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/crowdsecurity/crowdsec/pkg/decisionexport"
+)
+
+// etagMatch returns true if the If-None-Match header matches the (weak) etag.
+func etagMatch(header string, etag string) bool {
+	if header == "" {
+		return false
+	}
+
+	etag = strings.TrimPrefix(etag, "W/")
+
+	for candidate := range strings.SplitSeq(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ExportDecisions renders the active decisions as a blocklist, for the devices that can only download one.
+// The filters are the same as the stream's, by default the bans on ip and ranges. The key of the
+// bouncer can be in the api_key query parameter or the basic auth password, for the devices that
+// can't send a header.
+func (c *Controller) ExportDecisions(gctx *gin.Context) {
+	now := time.Now().UTC()
+
+	bouncerInfo, err := getBouncerFromContext(gctx)
+	if err != nil {
+		gctx.JSON(http.StatusUnauthorized, gin.H{"message": "not allowed"})
+
+		return
+	}
+
+	filters := gctx.Request.URL.Query()
+
+	format := gctx.DefaultQuery("format", decisionexport.FormatPlain)
+	opts := decisionexport.Options{
+		Name: gctx.DefaultQuery("name", decisionexport.DefaultName),
+		Now:  now,
+	}
+
+	if err := decisionexport.Validate(format, opts); err != nil {
+		gctx.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+
+		return
+	}
+
+	delete(filters, "format")
+	delete(filters, "name")
+
+	if _, ok := filters["scopes"]; !ok {
+		filters["scopes"] = []string{"ip,range"}
+	}
+
+	if _, ok := filters["type"]; !ok {
+		filters["type"] = []string{"ban"}
+	}
+
+	restrictDecisions(bouncerInfo, filters)
+
+	view := c.decisionView(gctx, bouncerInfo)
+
+	viewName := ""
+	if view != nil {
+		viewName = view.Cfg.Name
+	}
+
+	// the list can only change with the decisions: an unchanged one is not queried again
+	version, err := c.DBClient.ActiveDecisionsVersion(gctx.Request.Context(), now)
+	if err != nil {
+		c.HandleDBErrors(gctx, err)
+
+		return
+	}
+
+	if bouncerInfo.LastPull == nil || now.Sub(*bouncerInfo.LastPull) >= time.Minute {
+		if err := c.DBClient.UpdateBouncerLastPull(gctx.Request.Context(), now, bouncerInfo.ID); err != nil {
+			log.Errorf("failed to update bouncer last pull: %v", err)
+		}
+	}
+
+	etag := decisionexport.ETag(format, opts, version, url.Values(filters).Encode(), viewName)
+	gctx.Header("ETag", etag)
+
+	if etagMatch(gctx.GetHeader("If-None-Match"), etag) {
+		gctx.Status(http.StatusNotModified)
+
+		return
+	}
+
+	if gctx.Request.Method == http.MethodHead {
+		gctx.Header("Content-Type", decisionexport.ContentType(format))
+		gctx.Status(http.StatusOK)
+
+		return
+	}
+
+	data, err := c.DBClient.QueryAllDecisionsWithFilters(gctx.Request.Context(), now, filters)
+	if err != nil {
+		c.HandleDBErrors(gctx, err)
+
+		return
+	}
+
+	decisions := view.filter(FormatDecisions(data))
+
+	var buf bytes.Buffer
+
+	if err := decisionexport.Render(&buf, format, decisions, opts); err != nil {
+		gctx.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+
+		return
+	}
+
+	gctx.Data(http.StatusOK, decisionexport.ContentType(format), buf.Bytes())
+}
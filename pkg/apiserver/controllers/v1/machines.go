@@ -9,6 +9,7 @@ import (
 	"github.com/go-openapi/strfmt"
 	log "github.com/sirupsen/logrus"
 
+	"github.com/crowdsecurity/crowdsec/pkg/database"
 	"github.com/crowdsecurity/crowdsec/pkg/models"
 	"github.com/crowdsecurity/crowdsec/pkg/types"
 )
@@ -68,6 +69,13 @@ func (c *Controller) CreateMachine(gctx *gin.Context) {
 		return
 	}
 
+	// the machine registers itself
+	ctx = database.WithAuditActor(ctx, database.AuditActor{
+		Type: database.AuditActorMachine,
+		Name: *input.MachineID,
+		IP:   gctx.ClientIP(),
+	})
+
 	if _, err := c.DBClient.CreateMachine(ctx, input.MachineID, input.Password, gctx.ClientIP(), autoRegister, false, types.PasswordAuthType); err != nil {
 		c.HandleDBErrors(gctx, err)
 		return
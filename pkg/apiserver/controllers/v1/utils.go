@@ -10,6 +10,7 @@ import (
 	"github.com/gin-gonic/gin"
 
 	middlewares "github.com/crowdsecurity/crowdsec/pkg/apiserver/middlewares/v1"
+	"github.com/crowdsecurity/crowdsec/pkg/database"
 	"github.com/crowdsecurity/crowdsec/pkg/database/ent"
 )
 
@@ -55,6 +56,23 @@ func getMachineIDFromContext(ctx *gin.Context) (string, error) {
 	return id, nil
 }
 
+// SetAuditActor records the authenticated machine in the context of the request,
+// so that the changes made by the request are attributed to it in the audit log.
+func (*Controller) SetAuditActor(gctx *gin.Context) {
+	machineID, err := getMachineIDFromContext(gctx)
+	if err != nil || machineID == "" {
+		return
+	}
+
+	ctx := database.WithAuditActor(gctx.Request.Context(), database.AuditActor{
+		Type: database.AuditActorMachine,
+		Name: machineID,
+		IP:   gctx.ClientIP(),
+	})
+
+	gctx.Request = gctx.Request.WithContext(ctx)
+}
+
 func (*Controller) AbortRemoteIf(option bool) gin.HandlerFunc {
 	return func(gctx *gin.Context) {
 		if !option {
@@ -0,0 +1,139 @@
+package v1
+
+import (
+	"fmt"
+	"path"
+	"slices"
+
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/vm"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/crowdsecurity/crowdsec/pkg/csconfig"
+	"github.com/crowdsecurity/crowdsec/pkg/database/ent"
+	"github.com/crowdsecurity/crowdsec/pkg/exprhelpers"
+	"github.com/crowdsecurity/crowdsec/pkg/models"
+)
+
+// DecisionView is a filter on the decisions sent to the bouncers it's bound to.
+type DecisionView struct {
+	Cfg           *csconfig.DecisionViewCfg
+	RuntimeFilter *vm.Program
+}
+
+func NewDecisionViews(cfgs []*csconfig.DecisionViewCfg) ([]*DecisionView, error) {
+	views := make([]*DecisionView, 0, len(cfgs))
+	names := make(map[string]struct{}, len(cfgs))
+
+	for idx, cfg := range cfgs {
+		if cfg.Name == "" {
+			return nil, fmt.Errorf("decision view #%d: name is required", idx)
+		}
+
+		if _, ok := names[cfg.Name]; ok {
+			return nil, fmt.Errorf("decision view '%s' is defined more than once", cfg.Name)
+		}
+
+		names[cfg.Name] = struct{}{}
+
+		if cfg.Filter == "" {
+			return nil, fmt.Errorf("decision view '%s': filter is required", cfg.Name)
+		}
+
+		if len(cfg.Bouncers) == 0 && len(cfg.TLSOU) == 0 {
+			return nil, fmt.Errorf("decision view '%s': bouncers or tls_ou is required", cfg.Name)
+		}
+
+		for _, pattern := range cfg.Bouncers {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("decision view '%s': invalid bouncer pattern '%s': %w", cfg.Name, pattern, err)
+			}
+		}
+
+		opts := append(exprhelpers.GetExprOptions(map[string]any{"Decision": &models.Decision{}}), expr.AsBool())
+
+		program, err := expr.Compile(cfg.Filter, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("error compiling filter of decision view '%s': %w", cfg.Name, err)
+		}
+
+		views = append(views, &DecisionView{
+			Cfg:           cfg,
+			RuntimeFilter: program,
+		})
+	}
+
+	return views, nil
+}
+
+// boundTo returns true if the bouncer, with the organizational units of its certificate (if any), gets this view.
+func (v *DecisionView) boundTo(bouncerName string, ous []string) bool {
+	for _, pattern := range v.Cfg.Bouncers {
+		if ok, _ := path.Match(pattern, bouncerName); ok {
+			return true
+		}
+	}
+
+	for _, ou := range ous {
+		if slices.Contains(v.Cfg.TLSOU, ou) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Match returns true if the decision can be sent. Decisions for which the filter fails are not sent.
+func (v *DecisionView) Match(decision *models.Decision) bool {
+	if v == nil {
+		return true
+	}
+
+	output, err := expr.Run(v.RuntimeFilter, map[string]any{"Decision": decision})
+	if err != nil {
+		log.Warningf("decision view '%s': failed to run filter on decision %d: %s", v.Cfg.Name, decision.ID, err)
+		return false
+	}
+
+	match, ok := output.(bool)
+	if !ok {
+		log.Warningf("decision view '%s': filter returned %T instead of bool", v.Cfg.Name, output)
+		return false
+	}
+
+	return match
+}
+
+// filter returns the decisions of the view.
+func (v *DecisionView) filter(decisions []*models.Decision) []*models.Decision {
+	if v == nil {
+		return decisions
+	}
+
+	return slices.DeleteFunc(decisions, func(d *models.Decision) bool {
+		return !v.Match(d)
+	})
+}
+
+// decisionView returns the first view bound to the bouncer, or nil if the bouncer can get all the decisions.
+func (c *Controller) decisionView(gctx *gin.Context, bouncerInfo *ent.Bouncer) *DecisionView {
+	if len(c.DecisionViews) == 0 {
+		return nil
+	}
+
+	var ous []string
+
+	if gctx.Request.TLS != nil && len(gctx.Request.TLS.PeerCertificates) > 0 {
+		ous = gctx.Request.TLS.PeerCertificates[0].Subject.OrganizationalUnit
+	}
+
+	for _, view := range c.DecisionViews {
+		if view.boundTo(bouncerInfo.Name, ous) {
+			log.Debugf("bouncer '%s' gets decision view '%s'", bouncerInfo.Name, view.Cfg.Name)
+			return view
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package v1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/crowdsecurity/go-cs-lib/cstest"
+
+	"github.com/crowdsecurity/crowdsec/pkg/csconfig"
+	"github.com/crowdsecurity/crowdsec/pkg/models"
+)
+
+func TestNewDecisionViews(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         csconfig.DecisionViewCfg
+		expectedErr string
+	}{
+		{
+			name: "valid",
+			cfg:  csconfig.DecisionViewCfg{Name: "web", Filter: `Decision.Type == "captcha"`, Bouncers: []string{"nginx-*"}},
+		},
+		{
+			name:        "no name",
+			cfg:         csconfig.DecisionViewCfg{Filter: "true", Bouncers: []string{"nginx"}},
+			expectedErr: "decision view #0: name is required",
+		},
+		{
+			name:        "no filter",
+			cfg:         csconfig.DecisionViewCfg{Name: "web", Bouncers: []string{"nginx"}},
+			expectedErr: "decision view 'web': filter is required",
+		},
+		{
+			name:        "not bound",
+			cfg:         csconfig.DecisionViewCfg{Name: "web", Filter: "true"},
+			expectedErr: "decision view 'web': bouncers or tls_ou is required",
+		},
+		{
+			name:        "bad pattern",
+			cfg:         csconfig.DecisionViewCfg{Name: "web", Filter: "true", Bouncers: []string{"nginx-["}},
+			expectedErr: "decision view 'web': invalid bouncer pattern 'nginx-['",
+		},
+		{
+			name:        "bad filter",
+			cfg:         csconfig.DecisionViewCfg{Name: "web", Filter: "Decision.Foo ==", TLSOU: []string{"web"}},
+			expectedErr: "error compiling filter of decision view 'web'",
+		},
+		{
+			name:        "not a bool",
+			cfg:         csconfig.DecisionViewCfg{Name: "web", Filter: "Decision.Value", TLSOU: []string{"web"}},
+			expectedErr: "error compiling filter of decision view 'web'",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewDecisionViews([]*csconfig.DecisionViewCfg{&tc.cfg})
+			cstest.RequireErrorContains(t, err, tc.expectedErr)
+		})
+	}
+
+	_, err := NewDecisionViews([]*csconfig.DecisionViewCfg{
+		{Name: "web", Filter: "true", Bouncers: []string{"a"}},
+		{Name: "web", Filter: "true", Bouncers: []string{"b"}},
+	})
+	cstest.RequireErrorContains(t, err, "decision view 'web' is defined more than once")
+}
+
+func TestDecisionViewMatch(t *testing.T) {
+	views, err := NewDecisionViews([]*csconfig.DecisionViewCfg{
+		{
+			Name:     "web",
+			Filter:   `Decision.Type == "captcha"`,
+			Bouncers: []string{"nginx-*"},
+			TLSOU:    []string{"web"},
+		},
+		{
+			Name:     "firewall",
+			Filter:   `Decision.Type == "ban" && Decision.Scope in ["Ip", "Range"] && Decision.Origin != "lists"`,
+			Bouncers: []string{"firewall", "nginx-fw"},
+		},
+	})
+	require.NoError(t, err)
+
+	web, firewall := views[0], views[1]
+
+	assert.True(t, web.boundTo("nginx-1", nil))
+	assert.True(t, web.boundTo("nginx-fw", nil))
+	assert.True(t, web.boundTo("cn@192.0.2.1", []string{"other", "web"}))
+	assert.False(t, web.boundTo("firewall", nil))
+	assert.True(t, firewall.boundTo("firewall", nil))
+	assert.False(t, firewall.boundTo("firewall-2", []string{"firewall"}))
+
+	decision := func(typ string, scope string, origin string) *models.Decision {
+		value := "192.0.2.1"
+		return &models.Decision{Type: &typ, Scope: &scope, Origin: &origin, Value: &value}
+	}
+
+	assert.True(t, web.Match(decision("captcha", "Ip", "crowdsec")))
+	assert.False(t, web.Match(decision("ban", "Ip", "crowdsec")))
+	assert.True(t, firewall.Match(decision("ban", "Range", "cscli")))
+	assert.False(t, firewall.Match(decision("ban", "Country", "cscli")))
+	assert.False(t, firewall.Match(decision("ban", "Ip", "lists")))
+
+	// no view, no filtering
+	var none *DecisionView
+
+	assert.True(t, none.Match(decision("ban", "Country", "lists")))
+
+	decisions := []*models.Decision{
+		decision("captcha", "Ip", "crowdsec"),
+		decision("ban", "Ip", "crowdsec"),
+		decision("captcha", "Range", "cscli"),
+	}
+
+	assert.Len(t, none.filter(decisions), 3)
+	assert.Len(t, web.filter(decisions), 2)
+}
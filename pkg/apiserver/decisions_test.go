@@ -1,9 +1,16 @@
 package apiserver
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/crowdsecurity/crowdsec/pkg/csconfig"
+	"github.com/crowdsecurity/crowdsec/pkg/database"
 )
 
 const (
@@ -340,3 +347,166 @@ type DecisionTest struct {
 	DelChecks     []DecisionCheck
 	AuthType      string
 }
+
+func TestDecisionViews(t *testing.T) {
+	ctx := t.Context()
+
+	apiServer, config := NewAPIServer(t, ctx)
+	apiServer.controller.DecisionViews = []*csconfig.DecisionViewCfg{
+		{
+			Name:     "firewall",
+			Filter:   `Decision.Scenario == "crowdsecurity/ssh-bf"`,
+			Bouncers: []string{"te*"},
+		},
+	}
+
+	require.NoError(t, apiServer.InitController())
+
+	router, err := apiServer.Router()
+	require.NoError(t, err)
+
+	apiKey, _ := CreateTestBouncer(t, ctx, config.API.Server.DbConfig)
+
+	lapi := LAPI{
+		router:     router,
+		loginResp:  LoginToTestAPI(t, ctx, router, config),
+		bouncerKey: apiKey,
+	}
+
+	lapi.InsertAlertFromFile(t, ctx, "./tests/alert_minibulk.json")
+	lapi.InsertAlertFromFile(t, ctx, "./tests/alert_sample.json")
+
+	w := lapi.RecordResponse(t, ctx, "GET", "/v1/decisions", emptyBody, APIKEY)
+	decisions, code := readDecisionsGetResp(t, w)
+	assert.Equal(t, 200, code)
+	require.Len(t, decisions, 2)
+	assert.Equal(t, "crowdsecurity/ssh-bf", *decisions[0].Scenario)
+	assert.Equal(t, "crowdsecurity/ssh-bf", *decisions[1].Scenario)
+
+	// the view applies whatever the bouncer asks for
+	w = lapi.RecordResponse(t, ctx, "GET", "/v1/decisions?scenarios_containing=test", emptyBody, APIKEY)
+	decisions, _ = readDecisionsGetResp(t, w)
+	assert.Empty(t, decisions)
+
+	w = lapi.RecordResponse(t, ctx, "GET", "/v1/decisions/stream?startup=true", emptyBody, APIKEY)
+	stream, code := readDecisionsStreamResp(t, w)
+	assert.Equal(t, 200, code)
+	require.Len(t, stream["new"], 2)
+	assert.Equal(t, "crowdsecurity/ssh-bf", *stream["new"][0].Scenario)
+	assert.Equal(t, "crowdsecurity/ssh-bf", *stream["new"][1].Scenario)
+}
+
+func TestDeleteDecisionAudit(t *testing.T) {
+	ctx := t.Context()
+	lapi := SetupLAPITest(t, ctx)
+
+	lapi.InsertAlertFromFile(t, ctx, "./tests/alert_minibulk.json")
+
+	w := lapi.RecordResponse(t, ctx, "DELETE", "/v1/decisions?ip=91.121.79.179", emptyBody, PASSWORD)
+	assert.Equal(t, 200, w.Code)
+
+	w = lapi.RecordResponse(t, ctx, "DELETE", "/v1/decisions/2", emptyBody, PASSWORD)
+	assert.Equal(t, 200, w.Code)
+
+	records, err := lapi.DBClient.QueryAuditLog(ctx, database.AuditLogFilter{Action: database.AuditDecisionsDelete})
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+
+	for _, r := range records {
+		assert.Equal(t, database.AuditActorMachine, r.ActorType)
+		assert.Equal(t, "test", r.Actor)
+		assert.Equal(t, "127.0.0.1", r.IPAddress)
+		assert.Equal(t, 1, r.Count)
+	}
+
+	assert.Equal(t, "id=2", records[0].Target)
+	assert.Equal(t, "ip=91.121.79.179", records[1].Target)
+
+	// the registration of the test machine
+	records, err = lapi.DBClient.QueryAuditLog(ctx, database.AuditLogFilter{Action: "machines"})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, database.AuditMachinesCreate, records[0].Action)
+	assert.Equal(t, "test", records[0].Actor)
+}
+
+func TestExportDecisions(t *testing.T) {
+	ctx := t.Context()
+	lapi := SetupLAPITest(t, ctx)
+
+	lapi.InsertAlertFromFile(t, ctx, "./tests/alert_minibulk.json")
+
+	w := lapi.RecordResponse(t, ctx, "GET", "/v1/decisions/export", emptyBody, APIKEY)
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "91.121.79.178\n91.121.79.179\n", w.Body.String())
+	assert.Equal(t, "text/plain; charset=utf-8", w.Header().Get("Content-Type"))
+
+	etag := w.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	w = lapi.RecordResponse(t, ctx, "GET", "/v1/decisions/export?format=ipset&name=blocklist", emptyBody, APIKEY)
+	assert.Equal(t, 200, w.Code)
+	assert.Contains(t, w.Body.String(), "add blocklist-ipv4 91.121.79.178 -exist\n")
+	assert.NotEqual(t, etag, w.Header().Get("ETag"))
+
+	// unchanged
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "/v1/decisions/export", http.NoBody)
+	require.NoError(t, err)
+	req.Header.Add("X-Api-Key", lapi.bouncerKey)
+	req.Header.Add("If-None-Match", etag)
+	req.RemoteAddr = "127.0.0.1:1234"
+
+	w = httptest.NewRecorder()
+	lapi.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.String())
+
+	// changed
+	w = lapi.RecordResponse(t, ctx, "DELETE", "/v1/decisions?ip=91.121.79.179", emptyBody, PASSWORD)
+	require.Equal(t, 200, w.Code)
+
+	w = httptest.NewRecorder()
+	lapi.router.ServeHTTP(w, req)
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "91.121.79.178\n", w.Body.String())
+
+	// the key can be in the URL, for the devices that can't send a header
+	w = httptest.NewRecorder()
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, "/v1/decisions/export?api_key="+url.QueryEscape(lapi.bouncerKey), http.NoBody)
+	require.NoError(t, err)
+	req.RemoteAddr = "127.0.0.1:1234"
+	lapi.router.ServeHTTP(w, req)
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "91.121.79.178\n", w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, "/v1/decisions/export", http.NoBody)
+	require.NoError(t, err)
+	req.SetBasicAuth("firewall", lapi.bouncerKey)
+	req.RemoteAddr = "127.0.0.1:1234"
+	lapi.router.ServeHTTP(w, req)
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "91.121.79.178\n", w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, "/v1/decisions/export?api_key=bad", http.NoBody)
+	require.NoError(t, err)
+	req.RemoteAddr = "127.0.0.1:1234"
+	lapi.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// only on this route
+	w = httptest.NewRecorder()
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, "/v1/decisions?api_key="+url.QueryEscape(lapi.bouncerKey), http.NoBody)
+	require.NoError(t, err)
+	req.RemoteAddr = "127.0.0.1:1234"
+	lapi.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = lapi.RecordResponse(t, ctx, "GET", "/v1/decisions/export?format=iptables", emptyBody, APIKEY)
+	assert.Equal(t, 400, w.Code)
+	assert.Contains(t, w.Body.String(), "unknown format 'iptables'")
+
+	w = lapi.RecordResponse(t, ctx, "GET", "/v1/decisions/export?name=foo%20bar", emptyBody, APIKEY)
+	assert.Equal(t, 400, w.Code)
+}
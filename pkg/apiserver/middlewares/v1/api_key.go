@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/netip"
+	"net/url"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -19,6 +20,7 @@ import (
 
 const (
 	APIKeyHeader      = "X-Api-Key"
+	APIKeyQueryParam  = "api_key"
 	BouncerContextKey = "bouncer_info"
 	dummyAPIKeySize   = 54
 	// max allowed by bcrypt 72 = 54 bytes in base64
@@ -115,6 +117,12 @@ func (a *APIKey) authTLS(c *gin.Context, logger *log.Entry) *ent.Bouncer {
 
 		logger.Infof("Creating bouncer %s", bouncerName)
 
+		ctx = database.WithAuditActor(ctx, database.AuditActor{
+			Type: database.AuditActorBouncer,
+			Name: extractedCN,
+			IP:   c.ClientIP(),
+		})
+
 		bouncer, err = a.DbClient.CreateBouncer(ctx, bouncerName, c.ClientIP(), HashSHA512(apiKey), types.TlsAuthType, true)
 		if err != nil {
 			logger.Errorf("while creating bouncer db entry: %s", err)
@@ -207,6 +215,13 @@ func (a *APIKey) authPlain(c *gin.Context, logger *log.Entry) *ent.Bouncer {
 
 	logger.Infof("Creating bouncer %s", bouncerName)
 
+	// the bouncer that shares its key is recorded as the one creating the other
+	ctx = database.WithAuditActor(ctx, database.AuditActor{
+		Type: database.AuditActorBouncer,
+		Name: bouncers[0].Name,
+		IP:   clientIP,
+	})
+
 	bouncer, err = a.DbClient.CreateBouncer(ctx, bouncerName, clientIP, hashStr, types.ApiKeyAuthType, true)
 	if err != nil {
 		logger.Errorf("while creating bouncer db entry: %s", err)
@@ -216,6 +231,46 @@ func (a *APIKey) authPlain(c *gin.Context, logger *log.Entry) *ent.Bouncer {
 	return bouncer
 }
 
+// KeyFromURL lets the devices that can only download a URL authenticate with the key as the
+// api_key query parameter, or as the password of basic auth. It must come before Middleware,
+// on the routes meant for them. The parameter is removed from the query.
+func KeyFromURL(c *gin.Context) {
+	query := c.Request.URL.Query()
+
+	key := query.Get(APIKeyQueryParam)
+	if query.Has(APIKeyQueryParam) {
+		query.Del(APIKeyQueryParam)
+		c.Request.URL.RawQuery = query.Encode()
+	}
+
+	if key == "" {
+		if _, password, ok := c.Request.BasicAuth(); ok {
+			key = password
+		}
+	}
+
+	if key != "" && c.Request.Header.Get(APIKeyHeader) == "" {
+		c.Request.Header.Set(APIKeyHeader, key)
+	}
+}
+
+// RedactKey hides the api_key query parameter of a request path, for the logs.
+func RedactKey(path string) string {
+	base, rawQuery, ok := strings.Cut(path, "?")
+	if !ok || !strings.Contains(rawQuery, APIKeyQueryParam) {
+		return path
+	}
+
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil || !query.Has(APIKeyQueryParam) {
+		return path
+	}
+
+	query.Set(APIKeyQueryParam, "xxx")
+
+	return base + "?" + query.Encode()
+}
+
 func (a *APIKey) Middleware(c *gin.Context) {
 	var bouncer *ent.Bouncer
 
@@ -21,7 +21,11 @@ import (
 	"github.com/crowdsecurity/crowdsec/pkg/types"
 )
 
-const MachineIDKey = "id"
+const (
+	MachineIDKey = "id"
+	// RolesKey is the claim with the roles of the machine at login
+	RolesKey = "roles"
+)
 
 type JWT struct {
 	Middleware *jwt.GinJWTMiddleware
@@ -29,8 +33,20 @@ type JWT struct {
 	TlsAuth    *TLSAuth
 }
 
+// machineIdentity is what the token of a machine tells about it.
+type machineIdentity struct {
+	machineID string
+	roles     []string
+}
+
 func PayloadFunc(data any) jwt.MapClaims {
-	if value, ok := data.(*models.WatcherAuthRequest); ok {
+	switch value := data.(type) {
+	case *machineIdentity:
+		return jwt.MapClaims{
+			MachineIDKey: value.machineID,
+			RolesKey:     value.roles,
+		}
+	case *models.WatcherAuthRequest:
 		return jwt.MapClaims{
 			MachineIDKey: &value.MachineID,
 		}
@@ -232,8 +248,9 @@ func (j *JWT) Authenticator(c *gin.Context) (any, error) {
 		return nil, jwt.ErrFailedAuthentication
 	}
 
-	return &models.WatcherAuthRequest{
-		MachineID: &auth.machineID,
+	return &machineIdentity{
+		machineID: auth.machineID,
+		roles:     auth.clientMachine.Roles,
 	}, nil
 }
 
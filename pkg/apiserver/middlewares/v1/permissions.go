@@ -0,0 +1,91 @@
+package v1
+
+import (
+	"net/http"
+
+	jwt "github.com/appleboy/gin-jwt/v2"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/crowdsecurity/crowdsec/pkg/database/ent"
+	"github.com/crowdsecurity/crowdsec/pkg/database/ent/machine"
+	"github.com/crowdsecurity/crowdsec/pkg/types"
+)
+
+// claimRoles returns the roles of the token, and false if it has none (issued by a previous version).
+func claimRoles(claims jwt.MapClaims) ([]string, bool) {
+	raw, ok := claims[RolesKey]
+	if !ok {
+		return nil, false
+	}
+
+	// no role, all permissions
+	if raw == nil {
+		return nil, true
+	}
+
+	items, ok := raw.([]any)
+	if !ok {
+		return nil, false
+	}
+
+	roles := make([]string, 0, len(items))
+
+	for _, item := range items {
+		role, ok := item.(string)
+		if !ok {
+			return nil, false
+		}
+
+		roles = append(roles, role)
+	}
+
+	return roles, true
+}
+
+// RequirePermission returns a middleware that rejects the requests of the machines whose roles
+// don't grant the permission. It must be used after the JWT middleware.
+// The roles are those of the token, so a change takes effect when the machine logs in again.
+func (j *JWT) RequirePermission(perm types.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims := jwt.ExtractClaims(c)
+
+		machineID, _ := claims[MachineIDKey].(string)
+		if machineID == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "not allowed"})
+			return
+		}
+
+		roles, ok := claimRoles(claims)
+		if !ok {
+			m, err := j.DbClient.Ent.Machine.Query().
+				Where(machine.MachineIdEQ(machineID)).
+				Only(c.Request.Context())
+
+			switch {
+			case ent.IsNotFound(err):
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"message": "access forbidden"})
+				return
+			case err != nil:
+				log.Errorf("while reading the roles of machine %s: %s", machineID, err)
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "unable to check permissions"})
+
+				return
+			}
+
+			roles = m.Roles
+		}
+
+		if !types.RolesPermit(roles, perm) {
+			log.WithFields(log.Fields{
+				"machine": machineID,
+				"ip":      c.ClientIP(),
+				"roles":   roles,
+			}).Warningf("permission denied: %s %s requires %s", c.Request.Method, c.Request.URL.Path, perm)
+
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"message": "permission denied: " + string(perm)})
+
+			return
+		}
+	}
+}
@@ -152,6 +152,12 @@ func (p *Papi) handleEvent(ctx context.Context, event longpollclient.Event, sync
 
 	logger.Debugf("Calling operation '%s'", message.Header.OperationType)
 
+	// the changes are made on behalf of the console user
+	ctx = database.WithAuditActor(ctx, database.AuditActor{
+		Type: database.AuditActorPAPI,
+		Name: message.Header.Source.User,
+	})
+
 	err := operationFunc(ctx, message, p, sync)
 	if err != nil {
 		return fmt.Errorf("'%s %s failed: %w", message.Header.OperationType, message.Header.OperationCmd, err)
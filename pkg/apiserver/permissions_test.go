@@ -0,0 +1,154 @@
+package apiserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/crowdsecurity/crowdsec/pkg/types"
+)
+
+// setMachineRoles changes the roles of the test machine, and logs it in again to get them in its token.
+func setMachineRoles(t *testing.T, lapi *LAPI, roles []string) {
+	ctx := t.Context()
+
+	err := lapi.DBClient.UpdateMachineRoles(ctx, testMachineID, roles)
+	require.NoError(t, err)
+
+	body, err := json.Marshal(MachineTest)
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "/v1/watchers/login", bytes.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Add("User-Agent", UserAgent)
+	lapi.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	err = json.NewDecoder(w.Body).Decode(&lapi.loginResp)
+	require.NoError(t, err)
+}
+
+func TestMachineRoles(t *testing.T) {
+	ctx := t.Context()
+	lapi := SetupLAPITest(t, ctx)
+
+	// a log processor can push alerts, but not remove decisions
+	setMachineRoles(t, &lapi, []string{types.RoleAgent})
+
+	w := lapi.InsertAlertFromFile(t, ctx, "./tests/alert_minibulk.json")
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	w = lapi.RecordResponse(t, ctx, http.MethodDelete, "/v1/decisions", emptyBody, PASSWORD)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.JSONEq(t, `{"message":"permission denied: decisions:delete"}`, w.Body.String())
+
+	w = lapi.RecordResponse(t, ctx, http.MethodDelete, "/v1/decisions/1", emptyBody, PASSWORD)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = lapi.RecordResponse(t, ctx, http.MethodDelete, "/v1/alerts", emptyBody, PASSWORD)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = lapi.RecordResponse(t, ctx, http.MethodGet, "/v1/alerts", emptyBody, PASSWORD)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = lapi.RecordResponse(t, ctx, http.MethodGet, "/v1/heartbeat", emptyBody, PASSWORD)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// the decisions are still there
+	w = lapi.RecordResponse(t, ctx, http.MethodGet, "/v1/decisions", emptyBody, APIKEY)
+	decisions, _ := readDecisionsGetResp(t, w)
+	assert.Len(t, decisions, 2)
+
+	// read-only
+	setMachineRoles(t, &lapi, []string{types.RoleViewer})
+
+	w = lapi.RecordResponse(t, ctx, http.MethodGet, "/v1/alerts", emptyBody, PASSWORD)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = lapi.InsertAlertFromFile(t, ctx, "./tests/alert_minibulk.json")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// roles are cumulative
+	setMachineRoles(t, &lapi, []string{types.RoleViewer, types.RoleDecisionAdmin})
+
+	w = lapi.RecordResponse(t, ctx, http.MethodDelete, "/v1/decisions?ip=91.121.79.179", emptyBody, PASSWORD)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"nbDeleted":"1"}`, w.Body.String())
+
+	w = lapi.RecordResponse(t, ctx, http.MethodDelete, "/v1/alerts", emptyBody, PASSWORD)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// no role, all permissions
+	setMachineRoles(t, &lapi, nil)
+
+	w = lapi.RecordResponse(t, ctx, http.MethodDelete, "/v1/decisions", emptyBody, PASSWORD)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"nbDeleted":"1"}`, w.Body.String())
+
+	// the roles of the token apply until the next login
+	err := lapi.DBClient.UpdateMachineRoles(ctx, testMachineID, []string{types.RoleViewer})
+	require.NoError(t, err)
+
+	w = lapi.RecordResponse(t, ctx, http.MethodDelete, "/v1/decisions", emptyBody, PASSWORD)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestBouncerRestrictions(t *testing.T) {
+	ctx := t.Context()
+	lapi := SetupLAPITest(t, ctx)
+
+	lapi.InsertAlertFromFile(t, ctx, "./tests/alert_minibulk.json")
+	lapi.InsertAlertFromFile(t, ctx, "./tests/alert_sample.json")
+
+	bouncer, err := lapi.DBClient.SelectBouncerByName(ctx, "test")
+	require.NoError(t, err)
+
+	w := lapi.RecordResponse(t, ctx, http.MethodGet, "/v1/decisions", emptyBody, APIKEY)
+	decisions, _ := readDecisionsGetResp(t, w)
+	assert.Len(t, decisions, 5)
+
+	err = lapi.DBClient.UpdateBouncerRestrictions(ctx, nil, nil, []string{"crowdsecurity/ssh-bf"}, bouncer.ID)
+	require.NoError(t, err)
+
+	w = lapi.RecordResponse(t, ctx, http.MethodGet, "/v1/decisions", emptyBody, APIKEY)
+	decisions, _ = readDecisionsGetResp(t, w)
+	require.Len(t, decisions, 2)
+	assert.Equal(t, "crowdsecurity/ssh-bf", *decisions[0].Scenario)
+	assert.Equal(t, "crowdsecurity/ssh-bf", *decisions[1].Scenario)
+
+	// the restrictions can't be overridden by the bouncer
+	w = lapi.RecordResponse(t, ctx, http.MethodGet, "/v1/decisions?restrict_scenarios=crowdsecurity/test", emptyBody, APIKEY)
+	decisions, _ = readDecisionsGetResp(t, w)
+	assert.Len(t, decisions, 2)
+
+	// and are combined with the filters of the query
+	w = lapi.RecordResponse(t, ctx, http.MethodGet, "/v1/decisions?scenarios_containing=test", emptyBody, APIKEY)
+	decisions, _ = readDecisionsGetResp(t, w)
+	assert.Empty(t, decisions)
+
+	w = lapi.RecordResponse(t, ctx, http.MethodGet, "/v1/decisions/stream?startup=true", emptyBody, APIKEY)
+	stream, _ := readDecisionsStreamResp(t, w)
+	require.Len(t, stream["new"], 2)
+	assert.Equal(t, "crowdsecurity/ssh-bf", *stream["new"][0].Scenario)
+
+	err = lapi.DBClient.UpdateBouncerRestrictions(ctx, []string{"range"}, nil, nil, bouncer.ID)
+	require.NoError(t, err)
+
+	w = lapi.RecordResponse(t, ctx, http.MethodGet, "/v1/decisions/stream?startup=true", emptyBody, APIKEY)
+	stream, _ = readDecisionsStreamResp(t, w)
+	assert.Empty(t, stream["new"])
+
+	err = lapi.DBClient.UpdateBouncerRestrictions(ctx, nil, []string{"test"}, nil, bouncer.ID)
+	require.NoError(t, err)
+
+	w = lapi.RecordResponse(t, ctx, http.MethodGet, "/v1/decisions", emptyBody, APIKEY)
+	decisions, _ = readDecisionsGetResp(t, w)
+	require.Len(t, decisions, 3)
+	assert.Equal(t, "test", *decisions[0].Origin)
+}
@@ -959,7 +959,7 @@ func (wc *AppsecConfig) Build(ctx context.Context, hub *cwhub.Hub) (*AppsecRunti
 			continue
 		}
 
-		if err := exprhelpers.FileInit(hub.GetDataDir(), d.DestPath, d.Type); err != nil {
+		if err := exprhelpers.DataProviderInit(hub.GetDataDir(), d); err != nil {
 			wc.Logger.Errorf("unable to initialize data file %s: %s", d.DestPath, err)
 			continue
 		}
@@ -163,7 +163,7 @@ func initRuleData(appsecRule AppsecCollectionConfig, hub *cwhub.Hub, logger *log
 			continue
 		}
 
-		if err := exprhelpers.FileInit(hub.GetDataDir(), appsecRuleData.DestPath, appsecRuleData.Type); err != nil {
+		if err := exprhelpers.DataProviderInit(hub.GetDataDir(), appsecRuleData); err != nil {
 			logger.Errorf("unable to initialize data file %s : %s", appsecRuleData.DestPath, err)
 			continue
 		}
@@ -0,0 +1,178 @@
+package cache
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+	bolterrors "go.etcd.io/bbolt/errors"
+)
+
+// the file can be locked by another process, for example cscli while crowdsec is running
+const boltOpenTimeout = time.Second
+
+// OpenBolt opens the file of persistent entries, and creates its directory if needed.
+// Without fsync, the entries survive a restart but the last ones can be lost if the
+// system crashes.
+func OpenBolt(path string) (*bolt.DB, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: boltOpenTimeout, NoSync: true})
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", path, err)
+	}
+
+	return db, nil
+}
+
+// IsLocked tells if OpenBolt failed because another process has the file open.
+func IsLocked(err error) bool {
+	return errors.Is(err, bolterrors.ErrTimeout)
+}
+
+// TTLEntry is an entry of a TTLBucket.
+type TTLEntry struct {
+	Key     string
+	Value   []byte
+	Expires time.Time
+}
+
+// TTLBucket keeps entries and their expiration in a bucket of a bbolt file, to load
+// them in a memory cache after a restart.
+type TTLBucket struct {
+	db   *bolt.DB
+	name []byte
+}
+
+func NewTTLBucket(db *bolt.DB, name string) *TTLBucket {
+	return &TTLBucket{
+		db:   db,
+		name: []byte(name),
+	}
+}
+
+func encodeTTLEntry(value []byte, expires time.Time) []byte {
+	buf := make([]byte, 8+len(value))
+	binary.BigEndian.PutUint64(buf, uint64(expires.UnixNano()))
+	copy(buf[8:], value)
+
+	return buf
+}
+
+func decodeTTLEntry(key []byte, buf []byte) (TTLEntry, bool) {
+	if len(buf) < 8 {
+		return TTLEntry{}, false
+	}
+
+	return TTLEntry{
+		Key:     string(key),
+		Value:   append([]byte(nil), buf[8:]...),
+		Expires: time.Unix(0, int64(binary.BigEndian.Uint64(buf))),
+	}, true
+}
+
+// Load returns the entries that have not expired, ordered by expiration: if they don't all
+// fit in the cache, the last ones are the most recent. The other entries are removed, and
+// the bucket is created if needed.
+func (b *TTLBucket) Load() ([]TTLEntry, error) {
+	var entries []TTLEntry
+
+	now := time.Now()
+
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(b.name)
+		if err != nil {
+			return err
+		}
+
+		var expired [][]byte
+
+		err = bucket.ForEach(func(k, v []byte) error {
+			e, ok := decodeTTLEntry(k, v)
+			if !ok || !e.Expires.After(now) {
+				expired = append(expired, k)
+				return nil
+			}
+
+			entries = append(entries, e)
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, k := range expired {
+			if err := bucket.Delete(k); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Expires.Before(entries[j].Expires)
+	})
+
+	return entries, nil
+}
+
+func (b *TTLBucket) Put(key string, value []byte, expires time.Time) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(b.name).Put([]byte(key), encodeTTLEntry(value, expires))
+	})
+}
+
+func (b *TTLBucket) Delete(key string) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(b.name).Delete([]byte(key))
+	})
+}
+
+// Write puts or removes several entries in a single transaction: a nil entry
+// is a removal.
+func (b *TTLBucket) Write(entries map[string]*TTLEntry) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(b.name)
+
+		for key, e := range entries {
+			var err error
+
+			if e == nil {
+				err = bucket.Delete([]byte(key))
+			} else {
+				err = bucket.Put([]byte(key), encodeTTLEntry(e.Value, e.Expires))
+			}
+
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// Clear removes all the entries.
+func (b *TTLBucket) Clear() error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.DeleteBucket(b.name); err != nil && !errors.Is(err, bolterrors.ErrBucketNotFound) {
+			return err
+		}
+
+		_, err := tx.CreateBucket(b.name)
+
+		return err
+	})
+}
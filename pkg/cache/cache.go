@@ -2,28 +2,66 @@ package cache
 
 import (
 	"errors"
+	"fmt"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/bluele/gcache"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/crowdsecurity/crowdsec/pkg/logging"
 	"github.com/crowdsecurity/crowdsec/pkg/metrics"
 )
 
+const (
+	BackendMemory = "memory"
+	BackendDisk   = "disk"
+	BackendRedis  = "redis"
+)
+
+// the registry of the caches, guarded by cachesMu: Close can remove caches while
+// the parsers and the metrics handler use them
 var (
-	Caches      = []gcache.Cache{}
+	cachesMu    sync.RWMutex
+	Caches      = []Store{}
 	CacheNames  []string
 	CacheConfig = []CacheCfg{}
 )
 
+// Store holds the entries of a cache.
+type Store interface {
+	Set(key string, value string, expiration time.Duration) error
+	// Get returns found=false if the key doesn't exist or has expired
+	Get(key string) (value string, found bool, err error)
+	Len() int
+}
+
+// BackendCfg holds the settings of the persistent backends, shared by the caches.
+type BackendCfg struct {
+	DiskPath       string
+	Redis          *redis.Options
+	RedisKeyPrefix string
+}
+
+var backendCfg BackendCfg
+
+// ConfigureBackends must be called before creating disk or redis caches.
+func ConfigureBackends(cfg BackendCfg) {
+	backendCfg = cfg
+}
+
 // UpdateCacheMetrics is called directly by the prom handler
 func UpdateCacheMetrics() {
+	cachesMu.RLock()
+	defer cachesMu.RUnlock()
+
 	metrics.CacheMetrics.Reset()
 
 	for i, name := range CacheNames {
-		metrics.CacheMetrics.With(prometheus.Labels{"name": name, "type": CacheConfig[i].Strategy}).Set(float64(Caches[i].Len(false)))
+		metrics.CacheMetrics.With(prometheus.Labels{"name": name, "type": CacheConfig[i].Strategy}).Set(float64(Caches[i].Len()))
 	}
 }
 
@@ -32,6 +70,7 @@ type CacheCfg struct {
 	Size     int
 	TTL      time.Duration
 	Strategy string
+	Backend  string // memory (default), disk or redis
 	LogLevel log.Level
 	Logger   log.FieldLogger
 }
@@ -41,42 +80,131 @@ func (cfg CacheCfg) NewLogger() *log.Entry {
 	return clog.WithField("cache", cfg.Name)
 }
 
+// newMemory returns a gcache with the size and eviction strategy of the configuration.
+func newMemory(cfg *CacheCfg, evicted gcache.EvictedFunc) gcache.Cache {
+	tmpCache := gcache.New(cfg.Size)
+
+	switch cfg.Strategy {
+	case "LRU":
+		tmpCache = tmpCache.LRU()
+	case "LFU":
+		tmpCache = tmpCache.LFU()
+	case "ARC":
+		tmpCache = tmpCache.ARC()
+	default:
+		cfg.Strategy = "LRU"
+		tmpCache = tmpCache.LRU()
+	}
+
+	if evicted != nil {
+		tmpCache = tmpCache.EvictedFunc(evicted)
+	}
+
+	return tmpCache.Build()
+}
+
+type memoryStore struct {
+	cache gcache.Cache
+}
+
+func (s *memoryStore) Set(key string, value string, expiration time.Duration) error {
+	return s.cache.SetWithExpire(key, value, expiration)
+}
+
+func (s *memoryStore) Get(key string) (string, bool, error) {
+	value, err := s.cache.Get(key)
+	if err != nil {
+		if errors.Is(err, gcache.KeyNotFoundError) {
+			return "", false, nil
+		}
+
+		return "", false, err
+	}
+
+	return value.(string), true, nil
+}
+
+func (s *memoryStore) Len() int {
+	return s.cache.Len(false)
+}
+
 func CacheInit(cfg CacheCfg, logger log.FieldLogger) error {
+	var (
+		store Store
+		err   error
+	)
+
 	if logger == nil {
 		logger = log.StandardLogger()
 	}
 
 	cfg.Logger = logger
 
+	cachesMu.Lock()
+	defer cachesMu.Unlock()
+
 	for _, name := range CacheNames {
 		if name == cfg.Name {
 			log.Infof("Cache %s already exists", cfg.Name)
 		}
 	}
 
-	tmpCache := gcache.New(cfg.Size)
-
-	switch cfg.Strategy {
-	case "LRU":
-		tmpCache = tmpCache.LRU()
-	case "LFU":
-		tmpCache = tmpCache.LFU()
-	case "ARC":
-		tmpCache = tmpCache.ARC()
+	switch cfg.Backend {
+	case "", BackendMemory:
+		cfg.Backend = BackendMemory
+		store = &memoryStore{cache: newMemory(&cfg, nil)}
+	case BackendDisk:
+		store, err = newDiskStore(&cfg)
+	case BackendRedis:
+		store, err = newRedisStore(&cfg)
 	default:
-		cfg.Strategy = "LRU"
-		tmpCache = tmpCache.LRU()
+		return fmt.Errorf("unknown cache backend %q", cfg.Backend)
 	}
 
-	CTICache := tmpCache.Build()
-	Caches = append(Caches, CTICache)
+	if err != nil {
+		return fmt.Errorf("cache %s: %w", cfg.Name, err)
+	}
+
+	Caches = append(Caches, store)
 	CacheNames = append(CacheNames, cfg.Name)
 	CacheConfig = append(CacheConfig, cfg)
 
 	return nil
 }
 
+// Close releases the disk and redis backends. The caches which use them are
+// removed, they are loaded again with the parsers.
+func Close() error {
+	var errs []error
+
+	cachesMu.Lock()
+	defer cachesMu.Unlock()
+
+	for i := len(Caches) - 1; i >= 0; i-- {
+		if CacheConfig[i].Backend == BackendMemory {
+			continue
+		}
+
+		Caches = slices.Delete(Caches, i, i+1)
+		CacheNames = slices.Delete(CacheNames, i, i+1)
+		CacheConfig = slices.Delete(CacheConfig, i, i+1)
+	}
+
+	if err := closeDisk(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := closeRedis(); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
 func SetKey(cacheName string, key string, value string, expiration *time.Duration) error {
+	cachesMu.RLock()
+	defer cachesMu.RUnlock()
+
 	for i, name := range CacheNames {
 		if name == cacheName {
 			if expiration == nil {
@@ -85,7 +213,7 @@ func SetKey(cacheName string, key string, value string, expiration *time.Duratio
 
 			CacheConfig[i].Logger.Debugf("Setting key %s to %s with expiration %v", key, value, *expiration)
 
-			if err := Caches[i].SetWithExpire(key, value, *expiration); err != nil {
+			if err := Caches[i].Set(key, value, *expiration); err != nil {
 				CacheConfig[i].Logger.Warningf("While setting key %s in cache %s: %s", key, cacheName, err)
 			}
 		}
@@ -95,20 +223,24 @@ func SetKey(cacheName string, key string, value string, expiration *time.Duratio
 }
 
 func GetKey(cacheName string, key string) (string, error) {
+	cachesMu.RLock()
+	defer cachesMu.RUnlock()
+
 	for i, name := range CacheNames {
 		if name == cacheName {
-			value, err := Caches[i].Get(key)
+			value, found, err := Caches[i].Get(key)
 			if err != nil {
-				// do not warn or log if key not found
-				if errors.Is(err, gcache.KeyNotFoundError) {
-					return "", nil
-				}
 				CacheConfig[i].Logger.Warningf("While getting key %s in cache %s: %s", key, cacheName, err)
 
 				return "", err
 			}
 
-			return value.(string), nil
+			// do not warn or log if key not found
+			if !found {
+				return "", nil
+			}
+
+			return value, nil
 		}
 	}
 
@@ -1,11 +1,17 @@
 package cache
 
 import (
+	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/crowdsecurity/go-cs-lib/cstest"
 )
 
 func TestCreateSetGet(t *testing.T) {
@@ -29,3 +35,188 @@ func TestCreateSetGet(t *testing.T) {
 	assert.Empty(t, ret)
 	require.NoError(t, err)
 }
+
+func TestDiskBackend(t *testing.T) {
+	ConfigureBackends(BackendCfg{DiskPath: filepath.Join(t.TempDir(), "stash.db")})
+
+	t.Cleanup(func() { _ = Close() })
+
+	cfg := CacheCfg{Name: "disk", Size: 2, TTL: time.Hour, Backend: BackendDisk}
+
+	require.NoError(t, CacheInit(cfg, nil))
+	require.NoError(t, SetKey("disk", "k1", "v1", nil))
+	require.NoError(t, SetKey("disk", "k2", "v2", nil))
+	require.NoError(t, SetKey("disk", "short", "v3", new(time.Millisecond)))
+	time.Sleep(10 * time.Millisecond)
+
+	// a restart
+	require.NoError(t, Close())
+	require.NoError(t, CacheInit(cfg, nil))
+
+	ret, err := GetKey("disk", "k2")
+	require.NoError(t, err)
+	assert.Equal(t, "v2", ret)
+
+	ret, err = GetKey("disk", "short")
+	require.NoError(t, err)
+	assert.Empty(t, ret)
+
+	// beyond the size, the least recently used entry is evicted, from disk too
+	require.NoError(t, SetKey("disk", "k3", "v3", nil))
+	require.NoError(t, Close())
+	require.NoError(t, CacheInit(cfg, nil))
+
+	ret, err = GetKey("disk", "k1")
+	require.NoError(t, err)
+	assert.Empty(t, ret)
+
+	ret, err = GetKey("disk", "k3")
+	require.NoError(t, err)
+	assert.Equal(t, "v3", ret)
+}
+
+func TestCloseConcurrent(t *testing.T) {
+	ConfigureBackends(BackendCfg{DiskPath: filepath.Join(t.TempDir(), "stash.db")})
+
+	t.Cleanup(func() { _ = Close() })
+
+	cfg := CacheCfg{Name: "concurrent", Size: 10, TTL: time.Hour, Backend: BackendDisk}
+	require.NoError(t, CacheInit(cfg, nil))
+
+	stop := make(chan struct{})
+	wg := sync.WaitGroup{}
+
+	for range 4 {
+		wg.Go(func() {
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+
+				_ = SetKey("concurrent", "k", "v", nil)
+				_, _ = GetKey("concurrent", "k")
+				UpdateCacheMetrics()
+			}
+		})
+	}
+
+	// the parsers are reloaded while the events are processed
+	for range 20 {
+		require.NoError(t, Close())
+		require.NoError(t, CacheInit(cfg, nil))
+	}
+
+	close(stop)
+	wg.Wait()
+
+	require.NoError(t, Close())
+	require.NoError(t, CacheInit(cfg, nil))
+
+	ret, err := GetKey("concurrent", "k")
+	require.NoError(t, err)
+	assert.Equal(t, "v", ret)
+}
+
+func TestRedisBackend(t *testing.T) {
+	mr := miniredis.RunT(t)
+
+	ConfigureBackends(BackendCfg{
+		Redis:          &redis.Options{Addr: mr.Addr()},
+		RedisKeyPrefix: "test:",
+	})
+
+	t.Cleanup(func() { _ = Close() })
+
+	// two agents sharing the same stash
+	cfg := CacheCfg{Name: "shared", Size: 2, TTL: time.Minute, Strategy: "ARC", Backend: BackendRedis}
+	require.NoError(t, CacheInit(cfg, nil))
+	require.NoError(t, CacheInit(cfg, nil))
+	assert.Equal(t, "LRU", CacheConfig[len(CacheConfig)-1].Strategy)
+
+	require.NoError(t, Caches[len(Caches)-2].Set("k1", "v1", time.Minute))
+
+	value, found, err := Caches[len(Caches)-1].Get("k1")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "v1", value)
+	assert.Equal(t, time.Minute, mr.TTL("test:shared:k:k1"))
+
+	// k1 was read last, k2 is evicted
+	store := Caches[len(Caches)-1]
+	require.NoError(t, store.Set("k2", "v2", time.Minute))
+	mr.SetTime(time.Now().Add(time.Second))
+	_, _, err = store.Get("k1")
+	require.NoError(t, err)
+	require.NoError(t, store.Set("k3", "v3", time.Minute))
+
+	assert.Equal(t, 2, store.Len())
+	assert.False(t, mr.Exists("test:shared:k:k2"))
+
+	_, found, err = store.Get("k2")
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	mr.FastForward(2 * time.Minute)
+
+	_, found, err = store.Get("k3")
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	mr.Close()
+
+	_, _, err = store.Get("k1")
+	require.Error(t, err)
+}
+
+func TestUnconfiguredBackends(t *testing.T) {
+	ConfigureBackends(BackendCfg{})
+
+	err := CacheInit(CacheCfg{Name: "x", Backend: BackendDisk}, nil)
+	cstest.RequireErrorContains(t, err, "cache x: the disk backend is not configured")
+
+	err = CacheInit(CacheCfg{Name: "x", Backend: BackendRedis}, nil)
+	cstest.RequireErrorContains(t, err, "cache x: the redis backend is not configured")
+
+	err = CacheInit(CacheCfg{Name: "x", Backend: "foo"}, nil)
+	cstest.RequireErrorContains(t, err, `unknown cache backend "foo"`)
+}
+
+func TestTTLBucket(t *testing.T) {
+	db, err := OpenBolt(filepath.Join(t.TempDir(), "dir", "ttl.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	b := NewTTLBucket(db, "test")
+
+	entries, err := b.Load()
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	now := time.Now()
+
+	require.NoError(t, b.Put("late", []byte("v1"), now.Add(2*time.Hour)))
+	require.NoError(t, b.Put("early", []byte("v2"), now.Add(time.Hour)))
+	require.NoError(t, b.Put("expired", []byte("v3"), now.Add(-time.Second)))
+
+	// ordered by expiration, without the expired ones
+	entries, err = b.Load()
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "early", entries[0].Key)
+	assert.Equal(t, []byte("v2"), entries[0].Value)
+	assert.Equal(t, "late", entries[1].Key)
+
+	require.NoError(t, b.Delete("late"))
+
+	entries, err = b.Load()
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	require.NoError(t, b.Clear())
+
+	entries, err = b.Load()
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
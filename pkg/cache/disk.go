@@ -0,0 +1,193 @@
+package cache
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	bolt "go.etcd.io/bbolt"
+)
+
+// the entries are written to disk by batches, not to add a write transaction to
+// each event that sets a key. The last ones can be lost if crowdsec is killed.
+const diskFlushInterval = time.Second
+
+// all the disk caches share the same file, with a bucket per cache
+var (
+	diskMu     sync.Mutex
+	diskDB     *bolt.DB
+	diskStores []*diskStore
+	diskStop   chan struct{}
+	diskDone   chan struct{}
+)
+
+func openDisk() (*bolt.DB, error) {
+	diskMu.Lock()
+	defer diskMu.Unlock()
+
+	if diskDB != nil {
+		return diskDB, nil
+	}
+
+	path := backendCfg.DiskPath
+	if path == "" {
+		return nil, errors.New("the disk backend is not configured")
+	}
+
+	db, err := OpenBolt(path)
+	if err != nil {
+		return nil, err
+	}
+
+	diskDB = db
+	diskStop = make(chan struct{})
+	diskDone = make(chan struct{})
+
+	go flushDiskLoop(diskStop, diskDone)
+
+	return diskDB, nil
+}
+
+func flushDisk() {
+	diskMu.Lock()
+	defer diskMu.Unlock()
+
+	for _, s := range diskStores {
+		s.flush()
+	}
+}
+
+func flushDiskLoop(stop chan struct{}, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(diskFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			flushDisk()
+		}
+	}
+}
+
+// closeDisk writes the pending entries and closes the file.
+func closeDisk() error {
+	diskMu.Lock()
+	stop, done := diskStop, diskDone
+	diskMu.Unlock()
+
+	if stop == nil {
+		return nil
+	}
+
+	close(stop)
+	<-done
+
+	flushDisk()
+
+	diskMu.Lock()
+	defer diskMu.Unlock()
+
+	err := diskDB.Close()
+	diskDB = nil
+	diskStores = nil
+	diskStop = nil
+	diskDone = nil
+
+	return err
+}
+
+// diskStore is a memory cache, with the same size and strategy, which writes
+// its entries to disk to load them again after a restart.
+type diskStore struct {
+	memoryStore
+	bucket *TTLBucket
+	logger log.FieldLogger
+
+	mu sync.Mutex
+	// the changes since the last flush, by key: a nil entry is a removal
+	pending map[string]*TTLEntry
+}
+
+func newDiskStore(cfg *CacheCfg) (Store, error) {
+	db, err := openDisk()
+	if IsLocked(err) {
+		cfg.Logger.Warningf("%s is locked by another process, the entries of %s will not be persisted", backendCfg.DiskPath, cfg.Name)
+		cfg.Backend = BackendMemory
+
+		return &memoryStore{cache: newMemory(cfg, nil)}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	s := &diskStore{
+		bucket:  NewTTLBucket(db, cfg.Name),
+		logger:  cfg.Logger,
+		pending: make(map[string]*TTLEntry),
+	}
+	s.cache = newMemory(cfg, s.evicted)
+
+	entries, err := s.bucket.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range entries {
+		if err := s.cache.SetWithExpire(e.Key, string(e.Value), time.Until(e.Expires)); err != nil {
+			return nil, err
+		}
+	}
+
+	cfg.Logger.Debugf("loaded %d entries from %s", len(entries), backendCfg.DiskPath)
+
+	diskMu.Lock()
+	diskStores = append(diskStores, s)
+	diskMu.Unlock()
+
+	return s, nil
+}
+
+func (s *diskStore) evicted(key any, _ any) {
+	k, ok := key.(string)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	s.pending[k] = nil
+	s.mu.Unlock()
+}
+
+func (s *diskStore) Set(key string, value string, expiration time.Duration) error {
+	if err := s.cache.SetWithExpire(key, value, expiration); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.pending[key] = &TTLEntry{Key: key, Value: []byte(value), Expires: time.Now().Add(expiration)}
+	s.mu.Unlock()
+
+	return nil
+}
+
+// flush writes the pending changes in a single transaction.
+func (s *diskStore) flush() {
+	s.mu.Lock()
+	pending := s.pending
+	s.pending = make(map[string]*TTLEntry)
+	s.mu.Unlock()
+
+	if len(pending) == 0 {
+		return
+	}
+
+	if err := s.bucket.Write(pending); err != nil {
+		s.logger.Warningf("while writing %d entries to disk: %s", len(pending), err)
+	}
+}
@@ -0,0 +1,175 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const redisConnectTimeout = 5 * time.Second
+
+// all the redis caches share the same client
+var redisClient *redis.Client
+
+func openRedis() (*redis.Client, error) {
+	if redisClient != nil {
+		return redisClient, nil
+	}
+
+	if backendCfg.Redis == nil {
+		return nil, errors.New("the redis backend is not configured")
+	}
+
+	client := redis.NewClient(backendCfg.Redis)
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisConnectTimeout)
+	defer cancel()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("connecting to redis at %s: %w", backendCfg.Redis.Addr, err)
+	}
+
+	redisClient = client
+
+	return redisClient, nil
+}
+
+func closeRedis() error {
+	if redisClient == nil {
+		return nil
+	}
+
+	err := redisClient.Close()
+	redisClient = nil
+
+	return err
+}
+
+// redisStore keeps the entries in redis, to share them between agents. The
+// expiration is handled by redis. The size is enforced with a sorted set of
+// the keys, scored by last access (LRU) or number of accesses (LFU): when it
+// grows beyond the size, the keys with the lowest scores are removed. Expired
+// keys stay in the set until they are evicted, so the size is an upper bound.
+type redisStore struct {
+	client   *redis.Client
+	prefix   string
+	size     int
+	strategy string
+}
+
+func newRedisStore(cfg *CacheCfg) (Store, error) {
+	client, err := openRedis()
+	if err != nil {
+		return nil, err
+	}
+
+	switch cfg.Strategy {
+	case "LRU", "LFU":
+	case "ARC":
+		cfg.Logger.Infof("the ARC strategy is not available with redis, using LRU")
+		cfg.Strategy = "LRU"
+	default:
+		cfg.Strategy = "LRU"
+	}
+
+	return &redisStore{
+		client:   client,
+		prefix:   backendCfg.RedisKeyPrefix + cfg.Name + ":",
+		size:     cfg.Size,
+		strategy: cfg.Strategy,
+	}, nil
+}
+
+func (s *redisStore) dataKey(key string) string {
+	return s.prefix + "k:" + key
+}
+
+func (s *redisStore) indexKey() string {
+	return s.prefix + "idx"
+}
+
+func (s *redisStore) touch(ctx context.Context, c redis.Cmdable, key string) {
+	if s.strategy == "LFU" {
+		c.ZIncrBy(ctx, s.indexKey(), 1, key)
+		return
+	}
+
+	c.ZAdd(ctx, s.indexKey(), redis.Z{Score: float64(time.Now().UnixMicro()), Member: key})
+}
+
+func (s *redisStore) Set(key string, value string, expiration time.Duration) error {
+	ctx := context.Background()
+
+	pipe := s.client.TxPipeline()
+	pipe.Set(ctx, s.dataKey(key), value, expiration)
+	s.touch(ctx, pipe, key)
+	card := pipe.ZCard(ctx, s.indexKey())
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+
+	over := card.Val() - int64(s.size)
+	if s.size <= 0 || over <= 0 {
+		return nil
+	}
+
+	// the lowest scores, except the key that was just set: with LFU, it has the lowest count
+	lowest, err := s.client.ZRange(ctx, s.indexKey(), 0, over).Result()
+	if err != nil {
+		return err
+	}
+
+	members := make([]any, 0, over)
+	keys := make([]string, 0, over)
+
+	for _, member := range lowest {
+		if member == key || int64(len(keys)) == over {
+			continue
+		}
+
+		members = append(members, member)
+		keys = append(keys, s.dataKey(member))
+	}
+
+	if len(keys) == 0 {
+		return nil
+	}
+
+	pipe = s.client.TxPipeline()
+	pipe.ZRem(ctx, s.indexKey(), members...)
+	pipe.Del(ctx, keys...)
+	_, err = pipe.Exec(ctx)
+
+	return err
+}
+
+func (s *redisStore) Get(key string) (string, bool, error) {
+	ctx := context.Background()
+
+	value, err := s.client.Get(ctx, s.dataKey(key)).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", false, nil
+	}
+
+	if err != nil {
+		return "", false, err
+	}
+
+	s.touch(ctx, s.client, key)
+
+	return value, true, nil
+}
+
+func (s *redisStore) Len() int {
+	n, err := s.client.ZCard(context.Background(), s.indexKey()).Result()
+	if err != nil {
+		return 0
+	}
+
+	return int(n)
+}
@@ -68,8 +68,15 @@ type CTICfg struct {
 	Key          *string        `yaml:"key,omitempty"`
 	CacheTimeout *time.Duration `yaml:"cache_timeout,omitempty"`
 	CacheSize    *int           `yaml:"cache_size,omitempty"`
-	Enabled      *bool          `yaml:"enabled,omitempty"`
-	LogLevel     log.Level      `yaml:"log_level,omitempty"`
+	// the cache is kept in this file across restarts, relative paths are in the data directory.
+	// Without it, the cache is in memory only.
+	CachePath string `yaml:"cache_path,omitempty"`
+	// how long the expired entries are kept, to be used when the API can't be queried
+	StaleTimeout *time.Duration `yaml:"stale_timeout,omitempty"`
+	// the maximum number of IPs in a request, when several are looked up at once
+	BatchSize *int      `yaml:"batch_size,omitempty"`
+	Enabled   *bool     `yaml:"enabled,omitempty"`
+	LogLevel  log.Level `yaml:"log_level,omitempty"`
 }
 
 func (a *CTICfg) Load() error {
@@ -95,6 +102,20 @@ func (a *CTICfg) Load() error {
 		*a.CacheSize = 100
 	}
 
+	if a.StaleTimeout == nil {
+		a.StaleTimeout = new(time.Duration)
+		*a.StaleTimeout = 24 * time.Hour
+	}
+
+	if a.BatchSize == nil {
+		a.BatchSize = new(int)
+		*a.BatchSize = 10
+	}
+
+	if *a.BatchSize < 1 {
+		return errors.New("cti batch_size must be at least 1")
+	}
+
 	return nil
 }
 
@@ -246,6 +267,9 @@ type LocalApiServerCfg struct {
 	CapiWhitelists                *CapiWhitelist           `yaml:"-"`
 	AutoRegister                  *LocalAPIAutoRegisterCfg `yaml:"auto_registration,omitempty"`
 	DisableUsageMetricsExport     bool                     `yaml:"disable_usage_metrics_export"`
+	DecisionViews                 []*DecisionViewCfg       `yaml:"decision_views,omitempty"`
+	DecisionIndex                 *DecisionIndexCfg        `yaml:"decision_index,omitempty"`
+	DNSBL                         *DNSBLCfg                `yaml:"dnsbl,omitempty"`
 }
 
 // NewAccessLogger builds and returns a logger configured for HTTP access
@@ -321,6 +345,49 @@ type LocalAPIAutoRegisterCfg struct {
 	AllowedRangesParsed []*net.IPNet `yaml:"-"`
 }
 
+// DecisionViewCfg restricts the decisions sent to some bouncers, whatever the filters they ask for.
+type DecisionViewCfg struct {
+	Name string `yaml:"name"`
+	// Filter is an expression on the Decision (*models.Decision), which must return true for the decisions to send
+	Filter string `yaml:"filter"`
+	// Bouncers are the names of the bouncers that get this view. Patterns like "nginx-*" are allowed.
+	Bouncers []string `yaml:"bouncers,omitempty"`
+	// TLSOU are the organizational units of the client certificates of the bouncers that get this view
+	TLSOU []string `yaml:"tls_ou,omitempty"`
+}
+
+// DecisionIndexCfg keeps the decisions in memory, to answer the bouncers without querying the database.
+// The database is still the source of truth: several LAPIs can share it.
+type DecisionIndexCfg struct {
+	Enable *bool `yaml:"enable"`
+	// RefreshInterval is how often the changes made by other LAPIs, or by cscli, are loaded from the database
+	RefreshInterval time.Duration `yaml:"refresh_interval,omitempty"`
+	// ExpiredRetention is how long the expired decisions are kept, to send them to the bouncers in stream mode
+	ExpiredRetention time.Duration `yaml:"expired_retention,omitempty"`
+}
+
+// DNSBLCfg answers DNS queries on the active decisions, for the software that can check a DNS blocklist
+// (mail servers, proxies...) but can't run a bouncer.
+type DNSBLCfg struct {
+	Enable *bool `yaml:"enable"`
+	// ListenAddr is the UDP address of the DNS server
+	ListenAddr string `yaml:"listen_addr,omitempty"`
+	// Zone is the domain of the blocklist, ie. "bl.example.com": 192.0.2.1 is listed as 1.2.0.192.bl.example.com
+	Zone string `yaml:"zone"`
+	// TTL of the answers, in seconds
+	TTL uint32 `yaml:"ttl,omitempty"`
+	// Codes map the decisions to the addresses of the A records (127.0.0.<code>). The first match applies.
+	Codes []*DNSBLCodeCfg `yaml:"codes,omitempty"`
+}
+
+type DNSBLCodeCfg struct {
+	// Type of the decision (ban, captcha...), empty for all
+	Type string `yaml:"type,omitempty"`
+	// Scenario of the decision, patterns like "crowdsecurity/http-*" are allowed. Empty for all.
+	Scenario string `yaml:"scenario,omitempty"`
+	Code     uint8  `yaml:"code"`
+}
+
 func (c *LocalApiServerCfg) ClientURL() string {
 	if c == nil {
 		return ""
@@ -438,6 +505,10 @@ func (c *Config) LoadAPIServer(inCli bool, skipOnlineCreds bool) error {
 		if err := c.API.CTI.Load(); err != nil {
 			return fmt.Errorf("loading CTI configuration: %w", err)
 		}
+
+		if c.API.CTI.CachePath != "" && !filepath.IsAbs(c.API.CTI.CachePath) {
+			c.API.CTI.CachePath = filepath.Join(c.ConfigPaths.DataDir, c.API.CTI.CachePath)
+		}
 	}
 
 	return nil
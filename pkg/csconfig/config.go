@@ -29,19 +29,21 @@ var globalConfig = Config{}
 // Config contains top-level defaults -> overridden by configuration file -> overridden by CLI flags
 type Config struct {
 	// just a path to ourselves :p
-	FilePath     string              `yaml:"-"`
-	Self         []byte              `yaml:"-"`
-	Common       *CommonCfg          `yaml:"common,omitempty"`
-	Prometheus   *PrometheusCfg      `yaml:"prometheus,omitempty"`
-	Crowdsec     *CrowdsecServiceCfg `yaml:"crowdsec_service,omitempty"`
-	Cscli        *CscliCfg           `yaml:"cscli,omitempty"`
-	DbConfig     *DatabaseCfg        `yaml:"db_config,omitempty"`
-	API          *APICfg             `yaml:"api,omitempty"`
-	ConfigPaths  *ConfigurationPaths `yaml:"config_paths,omitempty"`
-	PluginConfig *PluginCfg          `yaml:"plugin_config,omitempty"`
-	DisableAPI   bool                `yaml:"-"`
-	DisableAgent bool                `yaml:"-"`
-	Hub          *LocalHubCfg        `yaml:"-"`
+	FilePath     string                `yaml:"-"`
+	Self         []byte                `yaml:"-"`
+	Common       *CommonCfg            `yaml:"common,omitempty"`
+	Prometheus   *PrometheusCfg        `yaml:"prometheus,omitempty"`
+	Crowdsec     *CrowdsecServiceCfg   `yaml:"crowdsec_service,omitempty"`
+	Cscli        *CscliCfg             `yaml:"cscli,omitempty"`
+	DbConfig     *DatabaseCfg          `yaml:"db_config,omitempty"`
+	API          *APICfg               `yaml:"api,omitempty"`
+	ConfigPaths  *ConfigurationPaths   `yaml:"config_paths,omitempty"`
+	PluginConfig *PluginCfg            `yaml:"plugin_config,omitempty"`
+	Enrichers    []*EnricherCfg        `yaml:"enrichers,omitempty"`
+	ThreatIntel  []*ThreatIntelFeedCfg `yaml:"threat_intel,omitempty"`
+	DisableAPI   bool                  `yaml:"-"`
+	DisableAgent bool                  `yaml:"-"`
+	Hub          *LocalHubCfg          `yaml:"-"`
 }
 
 func NewConfig(configFile string, disableAgent bool, disableAPI bool, quiet bool) (*Config, string, error) {
@@ -93,6 +95,14 @@ func NewConfig(configFile string, disableAgent bool, disableAPI bool, quiet bool
 		return nil, "", err
 	}
 
+	if err = cfg.loadEnrichers(); err != nil {
+		return nil, "", err
+	}
+
+	if err = cfg.loadThreatIntel(); err != nil {
+		return nil, "", err
+	}
+
 	cfg.loadHub()
 	cfg.loadCSCLI()
 
@@ -17,6 +17,7 @@ type CrowdsecServiceCfg struct {
 	Enable                    *bool            `yaml:"enable"`
 	AcquisitionFilePath       string           `yaml:"acquisition_path,omitempty"`
 	AcquisitionDirPath        string           `yaml:"acquisition_dir,omitempty"`
+	AcquisitionAutoReload     bool             `yaml:"acquisition_auto_reload,omitempty"` // start/stop datasources when the acquisition files change
 	ConsoleContextPath        string           `yaml:"console_context_path"`
 	ConsoleContextValueLength int              `yaml:"console_context_value_length"`
 	AcquisitionFiles          []string         `yaml:"-"`
@@ -28,6 +29,11 @@ type CrowdsecServiceCfg struct {
 	BucketStateDumpDir        string           `yaml:"state_output_dir,omitempty"` // if we need to unserialize buckets on shutdown
 	BucketsGCEnabled          bool             `yaml:"-"`                          // we need to garbage collect buckets when in forensic mode
 	DNSCache                  *DNSCacheCfg     `yaml:"dns_cache,omitempty"`
+	Stash                     *StashCfg        `yaml:"stash,omitempty"`
+	DataFiles                 *DataFilesCfg    `yaml:"data_files,omitempty"`
+	Tap                       *TapCfg          `yaml:"tap,omitempty"`
+	Expressions               *ExpressionsCfg  `yaml:"expressions,omitempty"`
+	WhitelistStaleAfter       time.Duration    `yaml:"whitelist_stale_after,omitempty"` // warn about the whitelist entries that don't match for this long
 
 	SimulationFilePath string              `yaml:"-"`
 	ContextToSend      map[string][]string `yaml:"-"`
@@ -40,6 +46,93 @@ type DNSCacheCfg struct {
 	Size        *int           `yaml:"size,omitempty"`
 }
 
+// Reload of the data files used by the parsers, scenarios and appsec rules
+type DataFilesCfg struct {
+	AutoReload      *bool         `yaml:"auto_reload,omitempty"`      // reload when they change on disk, default true
+	PollInterval    time.Duration `yaml:"poll_interval,omitempty"`    // when inotify is not available, default 30s
+	RefreshInterval time.Duration `yaml:"refresh_interval,omitempty"` // download from source_url, disabled by default
+}
+
+// Live stream of the processed events, for cscli tap
+type TapCfg struct {
+	ListenSocket string `yaml:"listen_socket"`
+	BufferSize   int    `yaml:"buffer_size,omitempty"` // events queued per subscriber before they are dropped
+}
+
+const defaultTapBufferSize = 1000
+
+func (c *TapCfg) load() error {
+	if c.ListenSocket == "" {
+		return errors.New("tap.listen_socket is required")
+	}
+
+	if err := ensureAbsolutePath(&c.ListenSocket); err != nil {
+		return err
+	}
+
+	if c.BufferSize <= 0 {
+		c.BufferSize = defaultTapBufferSize
+	}
+
+	return nil
+}
+
+// Profiling and limits of the expressions of the parsers and scenarios
+type ExpressionsCfg struct {
+	Profile    bool          `yaml:"profile,omitempty"`     // duration histograms by item and field
+	TimeBudget time.Duration `yaml:"time_budget,omitempty"` // abort the evaluations that take longer
+	StepBudget uint          `yaml:"step_budget,omitempty"` // abort the evaluations that run more instructions
+}
+
+func (c *ExpressionsCfg) load() error {
+	if c.TimeBudget < 0 {
+		return errors.New("expressions.time_budget can't be negative")
+	}
+
+	return nil
+}
+
+// Persistent backends for the parser stashes (backend: disk or redis)
+type StashCfg struct {
+	Disk  *StashDiskCfg  `yaml:"disk,omitempty"`
+	Redis *StashRedisCfg `yaml:"redis,omitempty"`
+}
+
+type StashDiskCfg struct {
+	Path string `yaml:"path,omitempty"` // default: <data_dir>/stash.db
+}
+
+type StashRedisCfg struct {
+	Address   string `yaml:"address"`
+	Username  string `yaml:"username,omitempty"`
+	Password  string `yaml:"password,omitempty"`
+	DB        int    `yaml:"db,omitempty"`
+	TLS       bool   `yaml:"tls,omitempty"`
+	KeyPrefix string `yaml:"key_prefix,omitempty"`
+}
+
+const defaultStashRedisKeyPrefix = "crowdsec:stash:"
+
+func (c *StashCfg) load() error {
+	if c.Disk != nil {
+		if err := ensureAbsolutePath(&c.Disk.Path); err != nil {
+			return err
+		}
+	}
+
+	if c.Redis != nil {
+		if c.Redis.Address == "" {
+			return errors.New("stash.redis.address is required")
+		}
+
+		if c.Redis.KeyPrefix == "" {
+			c.Redis.KeyPrefix = defaultStashRedisKeyPrefix
+		}
+	}
+
+	return nil
+}
+
 var ErrNoAcquisitionDefined = errors.New("no acquisition_path or acquisition_dir specified")
 
 func (c *CrowdsecServiceCfg) CollectAcquisitionFiles() ([]string, error) {
@@ -155,6 +248,24 @@ func (c *Config) LoadCrowdsec() error {
 		c.Crowdsec.OutputRoutinesCount = 1
 	}
 
+	if c.Crowdsec.Stash != nil {
+		if err = c.Crowdsec.Stash.load(); err != nil {
+			return err
+		}
+	}
+
+	if c.Crowdsec.Tap != nil {
+		if err = c.Crowdsec.Tap.load(); err != nil {
+			return err
+		}
+	}
+
+	if c.Crowdsec.Expressions != nil {
+		if err = c.Crowdsec.Expressions.load(); err != nil {
+			return err
+		}
+	}
+
 	if err = c.LoadAPIClient(); err != nil {
 		return fmt.Errorf("loading api client: %w", err)
 	}
@@ -224,3 +224,28 @@ dns_cache:
 	require.NoError(t, yaml.Unmarshal([]byte("acquisition_path: ./testdata/acquis.yaml"), &bare))
 	assert.Nil(t, bare.DNSCache)
 }
+
+func TestStashCfgLoad(t *testing.T) {
+	cfg := &StashCfg{
+		Disk:  &StashDiskCfg{Path: "./data/stash.db"},
+		Redis: &StashRedisCfg{Address: "127.0.0.1:6379"},
+	}
+
+	require.NoError(t, cfg.load())
+	assert.True(t, filepath.IsAbs(cfg.Disk.Path))
+	assert.Equal(t, "crowdsec:stash:", cfg.Redis.KeyPrefix)
+
+	cfg = &StashCfg{Redis: &StashRedisCfg{}}
+	cstest.RequireErrorContains(t, cfg.load(), "stash.redis.address is required")
+}
+
+func TestTapCfgLoad(t *testing.T) {
+	cfg := &TapCfg{ListenSocket: "./run/tap.sock"}
+
+	require.NoError(t, cfg.load())
+	assert.True(t, filepath.IsAbs(cfg.ListenSocket))
+	assert.Equal(t, 1000, cfg.BufferSize)
+
+	cfg = &TapCfg{BufferSize: 10}
+	cstest.RequireErrorContains(t, cfg.load(), "tap.listen_socket is required")
+}
@@ -8,12 +8,15 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"entgo.io/ent/dialect"
 	"github.com/go-sql-driver/mysql"
 	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
 
+	"github.com/crowdsecurity/go-cs-lib/csstring"
 	"github.com/crowdsecurity/go-cs-lib/cstime"
 
 	"github.com/crowdsecurity/crowdsec/pkg/fsutil"
@@ -26,6 +29,8 @@ const (
 	// we need an upper bound due to the sqlite limit of 32k variables in a query
 	// we have 15 variables per decision, so 32768/15 = 2184.5333
 	maxDecisionBulkSize = 2000
+	// size of an alert archive file before a new one is started, in megabytes
+	defaultAlertArchiveMaxSize = 100
 )
 
 type DatabaseCfg struct {
@@ -67,6 +72,46 @@ type FlushDBCfg struct {
 	BouncersGC    *AuthGCCfg              `yaml:"bouncers_autodelete,omitempty"`
 	AgentsGC      *AuthGCCfg              `yaml:"agents_autodelete,omitempty"`
 	MetricsMaxAge cstime.DurationWithDays `yaml:"metrics_max_age,omitempty"`
+	AuditMaxAge   cstime.DurationWithDays `yaml:"audit_max_age,omitempty"`
+	Archive       *AlertArchiveCfg        `yaml:"archive,omitempty"`
+}
+
+// AlertArchiveCfg enables writing the alerts removed by the flush job to
+// compressed JSONL files, one or more per day.
+type AlertArchiveCfg struct {
+	Dir string `yaml:"dir"`
+	// in megabytes
+	MaxSize int `yaml:"max_size,omitempty"`
+}
+
+// LoadDatabaseCfg reads a database configuration from a file, with the
+// same content as the db_config section of the main configuration.
+func LoadDatabaseCfg(path string) (*DatabaseCfg, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	dbcfg := &DatabaseCfg{}
+
+	dec := yaml.NewDecoder(strings.NewReader(csstring.StrictExpand(string(content), os.LookupEnv)))
+	dec.KnownFields(true)
+
+	if err := dec.Decode(dbcfg); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if dbcfg.Type == "" {
+		return nil, fmt.Errorf("%s: missing database type", path)
+	}
+
+	// set the defaults
+	cfg := &Config{DbConfig: dbcfg}
+	if err := cfg.LoadDBConfig(true); err != nil {
+		return nil, err
+	}
+
+	return dbcfg, nil
 }
 
 func (c *Config) LoadDBConfig(inCli bool) error {
@@ -126,6 +171,21 @@ func (c *Config) LoadDBConfig(inCli bool) error {
 		c.DbConfig.DecisionBulkSize = maxDecisionBulkSize
 	}
 
+	if c.DbConfig.Flush != nil && c.DbConfig.Flush.Archive != nil {
+		archive := c.DbConfig.Flush.Archive
+
+		if archive.Dir == "" {
+			return errors.New("flush.archive.dir is required to archive alerts")
+		}
+
+		switch {
+		case archive.MaxSize < 0:
+			return fmt.Errorf("invalid flush.archive.max_size %d", archive.MaxSize)
+		case archive.MaxSize == 0:
+			archive.MaxSize = defaultAlertArchiveMaxSize
+		}
+	}
+
 	return nil
 }
 
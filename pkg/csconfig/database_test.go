@@ -1,9 +1,12 @@
 package csconfig
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/crowdsecurity/go-cs-lib/cstest"
 )
@@ -36,6 +39,36 @@ func TestLoadDBConfig(t *testing.T) {
 				DecisionBulkSize: defaultDecisionBulkSize,
 			},
 		},
+		{
+			name: "alert archive",
+			input: &Config{
+				DbConfig: &DatabaseCfg{
+					Type:             "mysql",
+					DecisionBulkSize: 10,
+					Flush: &FlushDBCfg{
+						Archive: &AlertArchiveCfg{Dir: "/var/lib/crowdsec/archive"},
+					},
+				},
+			},
+			expected: &DatabaseCfg{
+				Type:             "mysql",
+				MaxOpenConns:     DEFAULT_MAX_OPEN_CONNS,
+				DecisionBulkSize: 10,
+				Flush: &FlushDBCfg{
+					Archive: &AlertArchiveCfg{Dir: "/var/lib/crowdsec/archive", MaxSize: defaultAlertArchiveMaxSize},
+				},
+			},
+		},
+		{
+			name: "alert archive without directory",
+			input: &Config{
+				DbConfig: &DatabaseCfg{
+					Type:  "mysql",
+					Flush: &FlushDBCfg{Archive: &AlertArchiveCfg{}},
+				},
+			},
+			expectedErr: "flush.archive.dir is required to archive alerts",
+		},
 		{
 			name:        "no configuration path",
 			input:       &Config{},
@@ -57,3 +90,23 @@ func TestLoadDBConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestLoadDatabaseCfg(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "target.yaml")
+	t.Setenv("TEST_DB_PASSWORD", "secret")
+
+	err := os.WriteFile(path, []byte("type: postgresql\nhost: db.example.com\nport: 5432\nuser: crowdsec\npassword: ${TEST_DB_PASSWORD}\n"), 0o600)
+	require.NoError(t, err)
+
+	dbcfg, err := LoadDatabaseCfg(path)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", dbcfg.Password)
+	assert.Equal(t, DEFAULT_MAX_OPEN_CONNS, dbcfg.MaxOpenConns)
+	assert.Equal(t, defaultDecisionBulkSize, dbcfg.DecisionBulkSize)
+
+	err = os.WriteFile(path, []byte("type: postgresql\nhostname: db.example.com\n"), 0o600)
+	require.NoError(t, err)
+
+	_, err = LoadDatabaseCfg(path)
+	cstest.RequireErrorContains(t, err, "field hostname not found")
+}
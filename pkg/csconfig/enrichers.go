@@ -0,0 +1,76 @@
+package csconfig
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	EnricherMMDB      = "mmdb"
+	EnricherInventory = "inventory"
+)
+
+// EnricherCfg declares a data file which can be used by the parsers (as an
+// enricher method, with the same name) and in expressions.
+type EnricherCfg struct {
+	Name string `yaml:"name"`
+	// mmdb (any MaxMind-format database) or inventory (a CSV or JSON list of assets)
+	Type string `yaml:"type"`
+	// relative paths are in the data directory
+	Path string `yaml:"path"`
+	// evt.Enriched key -> path in the mmdb record (country.iso_code) or inventory column.
+	// For an inventory, all the columns are copied if it's empty.
+	Fields map[string]string `yaml:"fields,omitempty"`
+	// inventory: the column with the IP address, range or hostname of the asset
+	Key string `yaml:"key,omitempty"`
+}
+
+func (c *Config) loadEnrichers() error {
+	seen := make(map[string]bool, len(c.Enrichers))
+
+	for i, e := range c.Enrichers {
+		if e == nil {
+			return fmt.Errorf("enrichers: entry %d is empty", i)
+		}
+
+		if e.Name == "" {
+			return fmt.Errorf("enrichers: entry %d has no name", i)
+		}
+
+		if seen[e.Name] {
+			return fmt.Errorf("enrichers: duplicate name %q", e.Name)
+		}
+
+		seen[e.Name] = true
+
+		if e.Path == "" {
+			return fmt.Errorf("enrichers: %s: path is required", e.Name)
+		}
+
+		if !filepath.IsAbs(e.Path) {
+			e.Path = filepath.Join(c.ConfigPaths.DataDir, e.Path)
+		}
+
+		switch e.Type {
+		case EnricherMMDB:
+			if len(e.Fields) == 0 {
+				return fmt.Errorf("enrichers: %s: fields are required for a mmdb database", e.Name)
+			}
+		case EnricherInventory:
+			switch strings.ToLower(filepath.Ext(e.Path)) {
+			case ".csv", ".json":
+			default:
+				return fmt.Errorf("enrichers: %s: the inventory must be a .csv or .json file", e.Name)
+			}
+
+			if e.Key == "" {
+				e.Key = "key"
+			}
+		default:
+			return fmt.Errorf("enrichers: %s: unknown type %q (must be %s or %s)", e.Name, e.Type, EnricherMMDB, EnricherInventory)
+		}
+	}
+
+	return nil
+}
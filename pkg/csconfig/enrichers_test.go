@@ -0,0 +1,75 @@
+package csconfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/crowdsecurity/go-cs-lib/cstest"
+)
+
+func TestLoadEnrichers(t *testing.T) {
+	tests := []struct {
+		name        string
+		enrichers   []*EnricherCfg
+		expectedErr string
+	}{
+		{
+			name: "valid",
+			enrichers: []*EnricherCfg{
+				{Name: "zones", Type: EnricherMMDB, Path: "zones.mmdb", Fields: map[string]string{"Zone": "zone.name"}},
+				{Name: "assets", Type: EnricherInventory, Path: "/etc/crowdsec/assets.csv"},
+			},
+		},
+		{
+			name:        "no name",
+			enrichers:   []*EnricherCfg{{Type: EnricherMMDB, Path: "zones.mmdb"}},
+			expectedErr: "enrichers: entry 0 has no name",
+		},
+		{
+			name: "duplicate",
+			enrichers: []*EnricherCfg{
+				{Name: "assets", Type: EnricherInventory, Path: "a.csv"},
+				{Name: "assets", Type: EnricherInventory, Path: "b.csv"},
+			},
+			expectedErr: `enrichers: duplicate name "assets"`,
+		},
+		{
+			name:        "no fields",
+			enrichers:   []*EnricherCfg{{Name: "zones", Type: EnricherMMDB, Path: "zones.mmdb"}},
+			expectedErr: "fields are required for a mmdb database",
+		},
+		{
+			name:        "bad extension",
+			enrichers:   []*EnricherCfg{{Name: "assets", Type: EnricherInventory, Path: "assets.txt"}},
+			expectedErr: "the inventory must be a .csv or .json file",
+		},
+		{
+			name:        "bad type",
+			enrichers:   []*EnricherCfg{{Name: "assets", Type: "ldap", Path: "assets.csv"}},
+			expectedErr: `unknown type "ldap"`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &Config{
+				ConfigPaths: &ConfigurationPaths{DataDir: "/var/lib/crowdsec/data"},
+				Enrichers:   tc.enrichers,
+			}
+
+			err := cfg.loadEnrichers()
+			cstest.RequireErrorContains(t, err, tc.expectedErr)
+
+			if tc.expectedErr != "" {
+				return
+			}
+
+			require.Len(t, cfg.Enrichers, 2)
+			assert.Equal(t, "/var/lib/crowdsec/data/zones.mmdb", cfg.Enrichers[0].Path)
+			assert.Equal(t, "/etc/crowdsec/assets.csv", cfg.Enrichers[1].Path)
+			assert.Equal(t, "key", cfg.Enrichers[1].Key)
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package csconfig
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"time"
+)
+
+const (
+	ThreatIntelPlain = "plain"
+	ThreatIntelCSV   = "csv"
+	ThreatIntelSTIX  = "stix"
+)
+
+const defaultThreatIntelRefresh = time.Hour
+
+// ThreatIntelFeedCfg declares a local threat intelligence feed, with IP
+// addresses, ranges, domains and file hashes that can be matched in
+// expressions with TIMatch.
+type ThreatIntelFeedCfg struct {
+	Name string `yaml:"name"`
+	// plain (one indicator per line), csv (with a header) or stix (a STIX 2.1 bundle)
+	Format string `yaml:"format"`
+	// relative paths are in the data directory
+	Path string `yaml:"path"`
+	// if set, the feed is downloaded to path when it changes
+	URL             string        `yaml:"url,omitempty"`
+	RefreshInterval time.Duration `yaml:"refresh_interval,omitempty"` // default 1h with an url
+	// added to the tags of the indicators
+	Tags []string `yaml:"tags,omitempty"`
+	// for the indicators without one, 0 to 100
+	Confidence int `yaml:"confidence,omitempty"`
+	// csv: the column with the indicator
+	Key string `yaml:"key,omitempty"`
+}
+
+func (c *Config) loadThreatIntel() error {
+	seen := make(map[string]bool, len(c.ThreatIntel))
+
+	for i, f := range c.ThreatIntel {
+		if f == nil {
+			return fmt.Errorf("threat_intel: entry %d is empty", i)
+		}
+
+		if f.Name == "" {
+			return fmt.Errorf("threat_intel: entry %d has no name", i)
+		}
+
+		if seen[f.Name] {
+			return fmt.Errorf("threat_intel: duplicate name %q", f.Name)
+		}
+
+		seen[f.Name] = true
+
+		if f.Path == "" {
+			return fmt.Errorf("threat_intel: %s: path is required", f.Name)
+		}
+
+		if !filepath.IsAbs(f.Path) {
+			f.Path = filepath.Join(c.ConfigPaths.DataDir, f.Path)
+		}
+
+		switch f.Format {
+		case ThreatIntelPlain, ThreatIntelSTIX:
+		case ThreatIntelCSV:
+			if f.Key == "" {
+				f.Key = "indicator"
+			}
+		default:
+			return fmt.Errorf("threat_intel: %s: unknown format %q (must be %s, %s or %s)", f.Name, f.Format, ThreatIntelPlain, ThreatIntelCSV, ThreatIntelSTIX)
+		}
+
+		if f.Confidence < 0 || f.Confidence > 100 {
+			return fmt.Errorf("threat_intel: %s: confidence must be between 0 and 100", f.Name)
+		}
+
+		if f.URL == "" {
+			continue
+		}
+
+		if u, err := url.Parse(f.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return fmt.Errorf("threat_intel: %s: invalid url %q", f.Name, f.URL)
+		}
+
+		if f.RefreshInterval <= 0 {
+			f.RefreshInterval = defaultThreatIntelRefresh
+		}
+	}
+
+	return nil
+}
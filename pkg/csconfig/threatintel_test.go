@@ -0,0 +1,84 @@
+package csconfig
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/crowdsecurity/go-cs-lib/cstest"
+)
+
+func TestLoadThreatIntel(t *testing.T) {
+	tests := []struct {
+		name        string
+		feeds       []*ThreatIntelFeedCfg
+		expectedErr string
+	}{
+		{
+			name: "valid",
+			feeds: []*ThreatIntelFeedCfg{
+				{Name: "honeypot", Format: ThreatIntelPlain, Path: "honeypot.txt"},
+				{Name: "partners", Format: ThreatIntelCSV, Path: "/etc/crowdsec/partners.csv"},
+				{Name: "misp", Format: ThreatIntelSTIX, Path: "misp.json", URL: "https://misp.example.com/stix2"},
+			},
+		},
+		{
+			name:        "no name",
+			feeds:       []*ThreatIntelFeedCfg{{Format: ThreatIntelPlain, Path: "honeypot.txt"}},
+			expectedErr: "threat_intel: entry 0 has no name",
+		},
+		{
+			name: "duplicate",
+			feeds: []*ThreatIntelFeedCfg{
+				{Name: "honeypot", Format: ThreatIntelPlain, Path: "a.txt"},
+				{Name: "honeypot", Format: ThreatIntelPlain, Path: "b.txt"},
+			},
+			expectedErr: `threat_intel: duplicate name "honeypot"`,
+		},
+		{
+			name:        "no path",
+			feeds:       []*ThreatIntelFeedCfg{{Name: "honeypot", Format: ThreatIntelPlain}},
+			expectedErr: "threat_intel: honeypot: path is required",
+		},
+		{
+			name:        "bad format",
+			feeds:       []*ThreatIntelFeedCfg{{Name: "honeypot", Format: "taxii", Path: "honeypot.txt"}},
+			expectedErr: `unknown format "taxii"`,
+		},
+		{
+			name:        "bad confidence",
+			feeds:       []*ThreatIntelFeedCfg{{Name: "honeypot", Format: ThreatIntelPlain, Path: "honeypot.txt", Confidence: 101}},
+			expectedErr: "confidence must be between 0 and 100",
+		},
+		{
+			name:        "bad url",
+			feeds:       []*ThreatIntelFeedCfg{{Name: "honeypot", Format: ThreatIntelPlain, Path: "honeypot.txt", URL: "ftp://example.com/feed"}},
+			expectedErr: `invalid url "ftp://example.com/feed"`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &Config{
+				ConfigPaths: &ConfigurationPaths{DataDir: "/var/lib/crowdsec/data"},
+				ThreatIntel: tc.feeds,
+			}
+
+			err := cfg.loadThreatIntel()
+			cstest.RequireErrorContains(t, err, tc.expectedErr)
+
+			if tc.expectedErr != "" {
+				return
+			}
+
+			require.Len(t, cfg.ThreatIntel, 3)
+			assert.Equal(t, "/var/lib/crowdsec/data/honeypot.txt", cfg.ThreatIntel[0].Path)
+			assert.Equal(t, "/etc/crowdsec/partners.csv", cfg.ThreatIntel[1].Path)
+			assert.Equal(t, "indicator", cfg.ThreatIntel[1].Key)
+			assert.Equal(t, time.Hour, cfg.ThreatIntel[2].RefreshInterval)
+			assert.Zero(t, cfg.ThreatIntel[0].RefreshInterval)
+		})
+	}
+}
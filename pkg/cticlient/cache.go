@@ -0,0 +1,207 @@
+package cticlient
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/bluele/gcache"
+	log "github.com/sirupsen/logrus"
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/crowdsecurity/crowdsec/pkg/cache"
+)
+
+const cacheBucket = "smoke"
+
+type cacheEntry struct {
+	ip      string
+	item    *SmokeItem
+	fetched time.Time
+}
+
+// storedEntry is the value of an entry on disk.
+type storedEntry struct {
+	Fetched time.Time  `json:"fetched"`
+	Item    *SmokeItem `json:"item"`
+}
+
+// Cache keeps the smoke items of the IPs that have been looked up. They are
+// fresh for ttl, then they are kept for staleTTL to be returned when the API
+// can't be queried. If it has a path, the entries are written to disk to load
+// them again after a restart.
+type Cache struct {
+	mem      gcache.Cache
+	ttl      time.Duration
+	staleTTL time.Duration
+	db       *bolt.DB
+	bucket   *cache.TTLBucket
+	logger   *log.Entry
+}
+
+// NewCache returns a cache of size entries. With an empty path, the entries are only kept in memory.
+func NewCache(size int, ttl time.Duration, staleTTL time.Duration, path string, logger *log.Entry) (*Cache, error) {
+	if logger == nil {
+		logger = log.NewEntry(log.StandardLogger())
+	}
+
+	c := &Cache{
+		ttl:      ttl,
+		staleTTL: max(staleTTL, 0),
+		logger:   logger,
+	}
+
+	if path == "" {
+		c.mem = gcache.New(size).LRU().Build()
+		return c, nil
+	}
+
+	// the file can be locked by another process, for example cscli notifications reinject while crowdsec is running
+	db, err := cache.OpenBolt(path)
+	if cache.IsLocked(err) {
+		logger.Warningf("%s is locked by another process, the CTI cache will not be persisted", path)
+
+		c.mem = gcache.New(size).LRU().Build()
+
+		return c, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	c.db = db
+	c.bucket = cache.NewTTLBucket(db, cacheBucket)
+	c.mem = gcache.New(size).LRU().EvictedFunc(c.evicted).Build()
+
+	if err := c.load(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("loading %s: %w", path, err)
+	}
+
+	return c, nil
+}
+
+func (c *Cache) expires(e *cacheEntry) time.Time {
+	return e.fetched.Add(c.ttl + c.staleTTL)
+}
+
+// load reads the entries of the disk. The TTLs may have changed since they were written:
+// the ones that are too old to be used are removed.
+func (c *Cache) load() error {
+	stored, err := c.bucket.Load()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	loaded := 0
+
+	for _, s := range stored {
+		v := storedEntry{}
+
+		if err := json.Unmarshal(s.Value, &v); err != nil || v.Item == nil || !c.expires(&cacheEntry{fetched: v.Fetched}).After(now) {
+			if err := c.bucket.Delete(s.Key); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		e := &cacheEntry{ip: s.Key, item: v.Item, fetched: v.Fetched}
+
+		if err := c.mem.SetWithExpire(e.ip, e, time.Until(c.expires(e))); err != nil {
+			return err
+		}
+
+		loaded++
+	}
+
+	c.logger.Debugf("loaded %d CTI entries", loaded)
+
+	return nil
+}
+
+func (c *Cache) evicted(key any, _ any) {
+	ip, ok := key.(string)
+	if !ok {
+		return
+	}
+
+	if err := c.bucket.Delete(ip); err != nil {
+		c.logger.Warningf("while removing %s from the CTI cache: %s", ip, err)
+	}
+}
+
+// Get returns the item of an IP, and whether it's still fresh.
+func (c *Cache) Get(ip string) (*SmokeItem, bool, bool) {
+	val, err := c.mem.Get(ip)
+	if err != nil {
+		return nil, false, false
+	}
+
+	e, ok := val.(*cacheEntry)
+	if !ok {
+		c.mem.Remove(ip)
+		return nil, false, false
+	}
+
+	return e.item, time.Since(e.fetched) < c.ttl, true
+}
+
+func (c *Cache) Set(ip string, item *SmokeItem) error {
+	e := &cacheEntry{ip: ip, item: item, fetched: time.Now()}
+
+	if err := c.mem.SetWithExpire(ip, e, c.ttl+c.staleTTL); err != nil {
+		return err
+	}
+
+	if c.bucket == nil {
+		return nil
+	}
+
+	buf, err := json.Marshal(storedEntry{Fetched: e.fetched, Item: item})
+	if err != nil {
+		return err
+	}
+
+	return c.bucket.Put(ip, buf, c.expires(e))
+}
+
+// Len returns the number of entries, only the fresh ones if checkExpired is true.
+func (c *Cache) Len(checkExpired bool) int {
+	if !checkExpired {
+		return c.mem.Len(true)
+	}
+
+	n := 0
+
+	for _, val := range c.mem.GetALL(true) {
+		if e, ok := val.(*cacheEntry); ok && time.Since(e.fetched) < c.ttl {
+			n++
+		}
+	}
+
+	return n
+}
+
+// Purge removes all the entries, in memory and on disk.
+func (c *Cache) Purge() error {
+	c.mem.Purge()
+
+	if c.bucket == nil {
+		return nil
+	}
+
+	return c.bucket.Clear()
+}
+
+// Close releases the file of the cache, which can't be used anymore. The
+// entries are kept on disk.
+func (c *Cache) Close() error {
+	if c.db == nil {
+		return nil
+	}
+
+	return c.db.Close()
+}
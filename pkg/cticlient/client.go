@@ -7,7 +7,10 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/crowdsecurity/crowdsec/pkg/apiclient/useragent"
 	log "github.com/sirupsen/logrus"
@@ -28,15 +31,59 @@ var (
 	defaultUserAgent = useragent.Default()
 )
 
+// limitError is an ErrLimit with the delay sent by the API, if any.
+type limitError struct {
+	retryAfter time.Duration
+}
+
+func (*limitError) Error() string {
+	return ErrLimit.Error()
+}
+
+func (*limitError) Is(target error) bool {
+	return target == ErrLimit
+}
+
+// parseRetryAfter reads a Retry-After header, in seconds or as a date.
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(max(seconds, 0)) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0)
+	}
+
+	return 0
+}
+
 type CrowdsecCTIClient struct {
 	httpClient *http.Client
 	apiKey     string
+	baseURL    string
 	Logger     *log.Entry
 	UserAgent  string
+
+	// used by LookupIP
+	cache      *Cache
+	batchSize  int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+
+	mu           sync.Mutex
+	calls        map[string]*lookupCall
+	pending      []string
+	flushing     bool
+	backoff      time.Duration
+	backoffUntil time.Time
 }
 
 func (c *CrowdsecCTIClient) doRequest(ctx context.Context, method string, endpoint string, params map[string]string) ([]byte, error) {
-	url := CTIBaseUrl + endpoint
+	url := c.baseURL + endpoint
 	if len(params) > 0 {
 		url += "?"
 		for k, v := range params {
@@ -64,7 +111,7 @@ func (c *CrowdsecCTIClient) doRequest(ctx context.Context, method string, endpoi
 		}
 
 		if resp.StatusCode == http.StatusTooManyRequests {
-			return nil, ErrLimit
+			return nil, &limitError{retryAfter: parseRetryAfter(resp.Header.Get("Retry-After"))}
 		}
 
 		if resp.StatusCode == http.StatusNotFound {
@@ -174,6 +221,24 @@ func NewCrowdsecCTIClient(options ...func(*CrowdsecCTIClient)) *CrowdsecCTIClien
 		client.UserAgent = defaultUserAgent
 	}
 
+	if client.baseURL == "" {
+		client.baseURL = CTIBaseUrl
+	}
+
+	if client.batchSize <= 0 {
+		client.batchSize = defaultBatchSize
+	}
+
+	if client.minBackoff <= 0 {
+		client.minBackoff = defaultMinBackoff
+	}
+
+	if client.maxBackoff < client.minBackoff {
+		client.maxBackoff = max(defaultMaxBackoff, client.minBackoff)
+	}
+
+	client.calls = make(map[string]*lookupCall)
+
 	return client
 }
 
@@ -200,3 +265,32 @@ func WithUserAgent(userAgent string) func(*CrowdsecCTIClient) {
 		c.UserAgent = userAgent
 	}
 }
+
+func WithBaseURL(baseURL string) func(*CrowdsecCTIClient) {
+	return func(c *CrowdsecCTIClient) {
+		c.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// WithCache keeps the results of LookupIP.
+func WithCache(cache *Cache) func(*CrowdsecCTIClient) {
+	return func(c *CrowdsecCTIClient) {
+		c.cache = cache
+	}
+}
+
+// WithBatchSize sets the maximum number of IPs that LookupIP sends in a single request.
+func WithBatchSize(size int) func(*CrowdsecCTIClient) {
+	return func(c *CrowdsecCTIClient) {
+		c.batchSize = size
+	}
+}
+
+// WithBackoff sets how long LookupIP stops querying the API when the quota is
+// exceeded, if the API doesn't say. It doubles each time, up to maxBackoff.
+func WithBackoff(minBackoff time.Duration, maxBackoff time.Duration) func(*CrowdsecCTIClient) {
+	return func(c *CrowdsecCTIClient) {
+		c.minBackoff = minBackoff
+		c.maxBackoff = maxBackoff
+	}
+}
@@ -1,13 +1,14 @@
 package ctiexpr
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"time"
 
-	"github.com/bluele/gcache"
 	log "github.com/sirupsen/logrus"
 
+	"github.com/crowdsecurity/crowdsec/pkg/csconfig"
 	"github.com/crowdsecurity/crowdsec/pkg/cticlient"
 	"github.com/crowdsecurity/crowdsec/pkg/logging"
 )
@@ -21,69 +22,109 @@ var (
 // this is set for non-recoverable errors, such as 403 when querying API or empty API key
 var CTIApiEnabled = false
 
-// when hitting quotas or auth errors, we temporarily disable the API
-var (
-	CTIBackOffUntil    time.Time
-	CTIBackOffDuration = 5 * time.Minute
-)
+// when hitting quotas, the client stops querying the API for a while, longer each time
+var CTIBackOffDuration = 5 * time.Minute
+
+// the longest a lookup can wait for the API
+var CTILookupTimeout = 30 * time.Second
 
 var ctiClient *cticlient.CrowdsecCTIClient
 
-func InitCrowdsecCTI(key *string, ttl *time.Duration, size *int, logLevel log.Level) error {
-	if key == nil || *key == "" {
+func InitCrowdsecCTI(cfg *csconfig.CTICfg) error {
+	// on reload, the file of the previous cache must be released first
+	ShutdownCrowdsecCTI()
+
+	if cfg == nil || cfg.Key == nil || *cfg.Key == "" {
 		log.Warningf("CTI API key not set or empty, CTI will not be available")
 		return cticlient.ErrDisabled
 	}
 
-	CTIApiKey = *key
+	CTIApiKey = *cfg.Key
 
-	if size == nil {
-		size = new(int)
-		*size = 1000
+	size := 1000
+	if cfg.CacheSize != nil {
+		size = *cfg.CacheSize
 	}
 
-	if ttl == nil {
-		ttl = new(time.Duration)
-		*ttl = 5 * time.Minute
+	ttl := 5 * time.Minute
+	if cfg.CacheTimeout != nil {
+		ttl = *cfg.CacheTimeout
 	}
 
-	clog := logging.SubLogger(log.StandardLogger(), "cti", logLevel)
+	var staleTTL time.Duration
+	if cfg.StaleTimeout != nil {
+		staleTTL = *cfg.StaleTimeout
+	}
+
+	var batchSize int
+	if cfg.BatchSize != nil {
+		batchSize = *cfg.BatchSize
+	}
+
+	clog := logging.SubLogger(log.StandardLogger(), "cti", cfg.LogLevel)
 
 	subLogger := clog.WithField("type", "crowdsec-cti")
 
-	CrowdsecCTIInitCache(*size, *ttl)
+	if err := CrowdsecCTIInitCache(size, ttl, staleTTL, cfg.CachePath, subLogger); err != nil {
+		return err
+	}
 
-	ctiClient = cticlient.NewCrowdsecCTIClient(cticlient.WithAPIKey(CTIApiKey), cticlient.WithLogger(subLogger))
+	ctiClient = cticlient.NewCrowdsecCTIClient(
+		cticlient.WithAPIKey(CTIApiKey),
+		cticlient.WithLogger(subLogger),
+		cticlient.WithCache(CTICache),
+		cticlient.WithBatchSize(batchSize),
+		cticlient.WithBackoff(CTIBackOffDuration, 12*CTIBackOffDuration),
+	)
 	CTIApiEnabled = true
 
 	return nil
 }
 
 func ShutdownCrowdsecCTI() {
+	CTIApiEnabled = false
+
+	// the client must not use the cache once it's closed
+	ctiClient = nil
+
 	if CTICache != nil {
-		CTICache.Purge()
+		if err := CTICache.Close(); err != nil {
+			log.Warningf("while closing the CTI cache: %s", err)
+		}
+
+		CTICache = nil
 	}
 
 	CTIApiKey = ""
-	CTIApiEnabled = false
 }
 
 // Cache for responses
-var (
-	CTICache        gcache.Cache
-	CacheExpiration time.Duration
-)
+var CTICache *cticlient.Cache
 
-func CrowdsecCTIInitCache(size int, ttl time.Duration) {
-	CTICache = gcache.New(size).LRU().Build()
-	CacheExpiration = ttl
+// CrowdsecCTIInitCache creates the cache of the responses, kept in path if it's not empty.
+func CrowdsecCTIInitCache(size int, ttl time.Duration, staleTTL time.Duration, path string, logger *log.Entry) error {
+	cache, err := cticlient.NewCache(size, ttl, staleTTL, path, logger)
+	if err != nil {
+		return fmt.Errorf("CTI cache: %w", err)
+	}
+
+	CTICache = cache
+
+	return nil
 }
 
 // func CrowdsecCTI(ip string) (*cticlient.SmokeItem, error) {
 func CrowdsecCTI(params ...any) (any, error) {
+	return CrowdsecCTIContext(context.Background(), params...)
+}
+
+// CrowdsecCTIContext is CrowdsecCTI, bounded by the context and CTILookupTimeout.
+func CrowdsecCTIContext(ctx context.Context, params ...any) (any, error) {
 	var ip string
 
-	if !CTIApiEnabled {
+	client := ctiClient
+
+	if !CTIApiEnabled || client == nil {
 		return &cticlient.SmokeItem{}, cticlient.ErrDisabled
 	}
 
@@ -93,52 +134,29 @@ func CrowdsecCTI(params ...any) (any, error) {
 		return &cticlient.SmokeItem{}, fmt.Errorf("invalid type for ip : %T", params[0])
 	}
 
-	if val, err := CTICache.Get(ip); err == nil && val != nil {
-		ctiClient.Logger.Debugf("cti cache fetch for %s", ip)
-
-		ret, ok := val.(*cticlient.SmokeItem)
-		if ok {
-			return ret, nil
-		}
-
-		ctiClient.Logger.Warningf("CrowdsecCTI: invalid type in cache, removing")
-
-		CTICache.Remove(ip)
-	}
-
-	if !CTIBackOffUntil.IsZero() && time.Now().Before(CTIBackOffUntil) {
-		// ctiClient.Logger.Warningf("Crowdsec CTI client is in backoff mode, ending in %s", time.Until(CTIBackOffUntil))
-		return &cticlient.SmokeItem{}, cticlient.ErrLimit
-	}
-
-	ctiClient.Logger.Infof("cti call for %s", ip)
+	ctx, cancel := context.WithTimeout(ctx, CTILookupTimeout)
+	defer cancel()
 
 	before := time.Now()
 
-	ctiResp, err := ctiClient.GetIPInfo(ip)
-	ctiClient.Logger.Debugf("request for %s took %v", ip, time.Since(before))
+	ctiResp, err := client.LookupIP(ctx, ip)
+	client.Logger.Debugf("lookup for %s took %v", ip, time.Since(before))
 	if err != nil {
 		switch {
 		case errors.Is(err, cticlient.ErrUnauthorized):
 			CTIApiEnabled = false
-			ctiClient.Logger.Errorf("Invalid API key provided, disabling CTI API")
+			client.Logger.Errorf("Invalid API key provided, disabling CTI API")
 			return &cticlient.SmokeItem{}, cticlient.ErrUnauthorized
 		case errors.Is(err, cticlient.ErrLimit):
-			CTIBackOffUntil = time.Now().Add(CTIBackOffDuration)
-			ctiClient.Logger.Errorf("CTI API is throttled, will try again in %s", CTIBackOffDuration)
+			// the client logs when it starts backing off
 			return &cticlient.SmokeItem{}, cticlient.ErrLimit
 		default:
-			ctiClient.Logger.Warnf("CTI API error : %s", err)
+			client.Logger.Warnf("CTI API error : %s", err)
 			return &cticlient.SmokeItem{}, fmt.Errorf("unexpected error: %w", err)
 		}
 	}
 
-	if err := CTICache.SetWithExpire(ip, ctiResp, CacheExpiration); err != nil {
-		ctiClient.Logger.Warningf("IpCTI : error while caching CTI : %s", err)
-		return &cticlient.SmokeItem{}, cticlient.ErrUnknown
-	}
-
-	ctiClient.Logger.Tracef("CTI response : %v", *ctiResp)
+	client.Logger.Tracef("CTI response : %v", *ctiResp)
 
 	return ctiResp, nil
 }
@@ -2,6 +2,7 @@ package ctiexpr
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"io"
@@ -13,6 +14,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/crowdsecurity/crowdsec/pkg/csconfig"
 	"github.com/crowdsecurity/crowdsec/pkg/cticlient"
 )
 
@@ -112,7 +114,7 @@ func smokeHandler(req *http.Request) *http.Response {
 func TestNilClient(t *testing.T) {
 	defer ShutdownCrowdsecCTI()
 
-	if err := InitCrowdsecCTI(new(""), nil, nil, 0); !errors.Is(err, cticlient.ErrDisabled) {
+	if err := InitCrowdsecCTI(&csconfig.CTICfg{Key: new("")}); !errors.Is(err, cticlient.ErrDisabled) {
 		t.Fatalf("failed to init CTI : %s", err)
 	}
 
@@ -124,7 +126,7 @@ func TestNilClient(t *testing.T) {
 func TestInvalidAuth(t *testing.T) {
 	defer ShutdownCrowdsecCTI()
 
-	if err := InitCrowdsecCTI(new("asdasd"), nil, nil, 0); err != nil {
+	if err := InitCrowdsecCTI(&csconfig.CTICfg{Key: new("asdasd")}); err != nil {
 		t.Fatalf("failed to init CTI : %s", err)
 	}
 	// Replace the client created by InitCrowdsecCTI with one that uses a custom transport
@@ -151,7 +153,7 @@ func TestInvalidAuth(t *testing.T) {
 func TestNoKey(t *testing.T) {
 	defer ShutdownCrowdsecCTI()
 
-	err := InitCrowdsecCTI(nil, nil, nil, 0)
+	err := InitCrowdsecCTI(&csconfig.CTICfg{})
 	require.ErrorIs(t, err, cticlient.ErrDisabled)
 	// Replace the client created by InitCrowdsecCTI with one that uses a custom transport
 	ctiClient = cticlient.NewCrowdsecCTIClient(cticlient.WithAPIKey("asdasd"), cticlient.WithHTTPClient(&http.Client{
@@ -168,11 +170,11 @@ func TestCache(t *testing.T) {
 	defer ShutdownCrowdsecCTI()
 
 	cacheDuration := 1 * time.Second
-	if err := InitCrowdsecCTI(new(validApiKey), &cacheDuration, nil, 0); err != nil {
+	if err := InitCrowdsecCTI(&csconfig.CTICfg{Key: new(validApiKey), CacheTimeout: &cacheDuration}); err != nil {
 		t.Fatalf("failed to init CTI : %s", err)
 	}
 	// Replace the client created by InitCrowdsecCTI with one that uses a custom transport
-	ctiClient = cticlient.NewCrowdsecCTIClient(cticlient.WithAPIKey(validApiKey), cticlient.WithHTTPClient(&http.Client{
+	ctiClient = cticlient.NewCrowdsecCTIClient(cticlient.WithAPIKey(validApiKey), cticlient.WithCache(CTICache), cticlient.WithHTTPClient(&http.Client{
 		Transport: RoundTripFunc(smokeHandler),
 	}))
 
@@ -203,3 +205,49 @@ func TestCache(t *testing.T) {
 	assert.Equal(t, 1, CTICache.Len(true))
 	require.NoError(t, err)
 }
+
+func TestShutdown(t *testing.T) {
+	defer ShutdownCrowdsecCTI()
+
+	err := InitCrowdsecCTI(&csconfig.CTICfg{Key: new(validApiKey)})
+	require.NoError(t, err)
+
+	ctiClient = cticlient.NewCrowdsecCTIClient(cticlient.WithAPIKey(validApiKey), cticlient.WithCache(CTICache), cticlient.WithHTTPClient(&http.Client{
+		Transport: RoundTripFunc(smokeHandler),
+	}))
+
+	_, err = CrowdsecCTI("1.2.3.4")
+	require.NoError(t, err)
+
+	// the client is dropped with its cache
+	ShutdownCrowdsecCTI()
+	assert.Nil(t, ctiClient)
+	assert.Nil(t, CTICache)
+
+	item, err := CrowdsecCTI("1.2.3.4")
+	assert.Equal(t, &cticlient.SmokeItem{}, item)
+	require.ErrorIs(t, err, cticlient.ErrDisabled)
+}
+
+func TestLookupTimeout(t *testing.T) {
+	defer ShutdownCrowdsecCTI()
+
+	timeout := CTILookupTimeout
+	CTILookupTimeout = 100 * time.Millisecond
+
+	t.Cleanup(func() { CTILookupTimeout = timeout })
+
+	err := InitCrowdsecCTI(&csconfig.CTICfg{Key: new(validApiKey)})
+	require.NoError(t, err)
+
+	// the API never answers
+	ctiClient = cticlient.NewCrowdsecCTIClient(cticlient.WithAPIKey(validApiKey), cticlient.WithHTTPClient(&http.Client{
+		Transport: RoundTripFunc(func(req *http.Request) *http.Response {
+			<-req.Context().Done()
+			return nil
+		}),
+	}))
+
+	_, err = CrowdsecCTIContext(t.Context(), "1.2.3.4")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
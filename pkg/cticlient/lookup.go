@@ -0,0 +1,210 @@
+package cticlient
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"strings"
+	"time"
+)
+
+const (
+	defaultBatchSize  = 10
+	defaultMinBackoff = 5 * time.Minute
+	defaultMaxBackoff = time.Hour
+)
+
+type lookupCall struct {
+	done chan struct{}
+	item *SmokeItem
+	err  error
+}
+
+// LookupIP returns the smoke item of an IP, from the cache if it's fresh. The
+// concurrent lookups of an IP share the same request, and the IPs that are
+// looked up while a request is running are sent together with SearchIPs.
+// When the API can't be queried, the stale item of the IP is returned if the
+// cache still has it.
+func (c *CrowdsecCTIClient) LookupIP(ctx context.Context, ip string) (*SmokeItem, error) {
+	if c.cache != nil {
+		if item, fresh, ok := c.cache.Get(ip); ok && fresh {
+			return item, nil
+		}
+	}
+
+	call := c.enqueue(ip)
+
+	select {
+	case <-call.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	if call.err == nil {
+		return call.item, nil
+	}
+
+	if c.cache != nil && !errors.Is(call.err, ErrUnauthorized) {
+		if item, _, ok := c.cache.Get(ip); ok {
+			c.Logger.Debugf("returning stale CTI data for %s: %s", ip, call.err)
+			return item, nil
+		}
+	}
+
+	return nil, call.err
+}
+
+func (c *CrowdsecCTIClient) enqueue(ip string) *lookupCall {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if call, ok := c.calls[ip]; ok {
+		return call
+	}
+
+	call := &lookupCall{done: make(chan struct{})}
+	c.calls[ip] = call
+	c.pending = append(c.pending, ip)
+
+	// one request at a time: the IPs that are looked up meanwhile are pending for the next one
+	if !c.flushing {
+		c.flushing = true
+
+		go c.flush()
+	}
+
+	return call
+}
+
+func (c *CrowdsecCTIClient) flush() {
+	for {
+		c.mu.Lock()
+
+		if len(c.pending) == 0 {
+			c.pending = nil
+			c.flushing = false
+			c.mu.Unlock()
+
+			return
+		}
+
+		n := min(len(c.pending), c.batchSize)
+		ips := c.pending[:n:n]
+		c.pending = c.pending[n:]
+
+		c.mu.Unlock()
+
+		items, err := c.fetch(ips)
+
+		if err == nil && c.cache != nil {
+			for ip, item := range items {
+				if err := c.cache.Set(ip, item); err != nil {
+					c.Logger.Warningf("while caching CTI data for %s: %s", ip, err)
+				}
+			}
+		}
+
+		c.mu.Lock()
+
+		for _, ip := range ips {
+			call := c.calls[ip]
+			delete(c.calls, ip)
+
+			call.item, call.err = items[ip], err
+			close(call.done)
+		}
+
+		c.mu.Unlock()
+	}
+}
+
+// normalizeIP is used to match the IPs of a search with the items of the response.
+func normalizeIP(ip string) string {
+	if addr, err := netip.ParseAddr(ip); err == nil {
+		return addr.String()
+	}
+
+	return ip
+}
+
+func (c *CrowdsecCTIClient) fetch(ips []string) (map[string]*SmokeItem, error) {
+	c.mu.Lock()
+	backoffUntil := c.backoffUntil
+	c.mu.Unlock()
+
+	if time.Now().Before(backoffUntil) {
+		return nil, ErrLimit
+	}
+
+	c.Logger.Debugf("cti call for %s", strings.Join(ips, ", "))
+
+	items := make(map[string]*SmokeItem, len(ips))
+
+	var err error
+
+	if len(ips) == 1 {
+		var item *SmokeItem
+
+		item, err = c.GetIPInfo(ips[0])
+		items[ips[0]] = item
+	} else {
+		var resp *SearchIPResponse
+
+		resp, err = c.SearchIPs(ips)
+		if err == nil {
+			found := make(map[string]*SmokeItem, len(resp.Items))
+			for i := range resp.Items {
+				found[normalizeIP(resp.Items[i].Ip)] = &resp.Items[i]
+			}
+
+			for _, ip := range ips {
+				item, ok := found[normalizeIP(ip)]
+				if !ok {
+					// unknown IPs have an empty item, like with GetIPInfo
+					item = &SmokeItem{}
+				}
+
+				items[ip] = item
+			}
+		}
+	}
+
+	c.updateBackoff(err)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// updateBackoff stops the requests for a while when the quota is exceeded: as
+// long as the API says, or for longer each time.
+func (c *CrowdsecCTIClient) updateBackoff(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err == nil {
+		c.backoff = 0
+		return
+	}
+
+	if !errors.Is(err, ErrLimit) {
+		return
+	}
+
+	var delay time.Duration
+
+	if le := (*limitError)(nil); errors.As(err, &le) {
+		delay = le.retryAfter
+	}
+
+	if delay <= 0 {
+		c.backoff = min(max(2*c.backoff, c.minBackoff), c.maxBackoff)
+		delay = c.backoff
+	}
+
+	c.backoffUntil = time.Now().Add(delay)
+
+	c.Logger.Warningf("CTI API quota exceeded, no requests for %s", delay)
+}
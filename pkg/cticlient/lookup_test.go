@@ -0,0 +1,367 @@
+package cticlient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ctiServer answers the smoke requests for the known IPs, and records them
+type ctiServer struct {
+	*httptest.Server
+	known    map[string]bool
+	status   atomic.Int32
+	mu       sync.Mutex
+	requests []string
+	// if set, the first request waits until it's closed
+	hold chan struct{}
+	held chan struct{}
+}
+
+func newCTIServer(t *testing.T, known ...string) *ctiServer {
+	t.Helper()
+
+	s := &ctiServer{known: make(map[string]bool)}
+	s.status.Store(http.StatusOK)
+
+	for _, ip := range known {
+		s.known[ip] = true
+	}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func (s *ctiServer) handle(w http.ResponseWriter, req *http.Request) {
+	request := req.URL.Path
+	if ips := req.URL.Query().Get("ips"); ips != "" {
+		request += "?ips=" + ips
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, request)
+	first := len(s.requests) == 1
+	s.mu.Unlock()
+
+	if first && s.hold != nil {
+		close(s.held)
+		<-s.hold
+	}
+
+	if req.Header.Get("X-Api-Key") != validApiKey {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	if status := int(s.status.Load()); status != http.StatusOK {
+		if status == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", "3600")
+		}
+
+		w.WriteHeader(status)
+
+		return
+	}
+
+	if ip, ok := strings.CutPrefix(req.URL.Path, "/smoke/"); ok {
+		if !s.known[ip] {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		fmt.Fprintf(w, `{"ip": %q, "ip_range": "%s/32"}`, ip, ip)
+
+		return
+	}
+
+	items := []string{}
+
+	for ip := range strings.SplitSeq(req.URL.Query().Get("ips"), ",") {
+		if s.known[ip] {
+			items = append(items, fmt.Sprintf(`{"ip": %q, "ip_range": "%s/32"}`, ip, ip))
+		}
+	}
+
+	fmt.Fprintf(w, `{"total": %d, "items": [%s]}`, len(items), strings.Join(items, ","))
+}
+
+func (s *ctiServer) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.requests)
+}
+
+func newLookupClient(s *ctiServer, cache *Cache, options ...func(*CrowdsecCTIClient)) *CrowdsecCTIClient {
+	options = append([]func(*CrowdsecCTIClient){
+		WithAPIKey(validApiKey),
+		WithBaseURL(s.URL),
+		WithCache(cache),
+	}, options...)
+
+	return NewCrowdsecCTIClient(options...)
+}
+
+func TestLookupIPBatch(t *testing.T) {
+	s := newCTIServer(t, "192.0.2.1", "192.0.2.2", "192.0.2.3")
+	s.hold = make(chan struct{})
+	s.held = make(chan struct{})
+
+	c := newLookupClient(s, nil)
+
+	first := c.enqueue("192.0.2.1")
+
+	<-s.held
+
+	// the concurrent lookups of an IP share the same call
+	call := c.enqueue("192.0.2.2")
+	assert.Same(t, call, c.enqueue("192.0.2.2"))
+
+	results := make(chan *SmokeItem, 2)
+
+	for _, ip := range []string{"192.0.2.3", "192.0.2.4"} {
+		go func() {
+			item, err := c.LookupIP(t.Context(), ip)
+			assert.NoError(t, err)
+			results <- item
+		}()
+	}
+
+	require.Eventually(t, func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		return len(c.pending) == 3
+	}, 5*time.Second, time.Millisecond)
+
+	close(s.hold)
+
+	<-first.done
+	require.NoError(t, first.err)
+	assert.Equal(t, "192.0.2.1", first.item.Ip)
+
+	<-call.done
+	require.NoError(t, call.err)
+	assert.Equal(t, "192.0.2.2", call.item.Ip)
+
+	ips := []string{}
+	for range 2 {
+		ips = append(ips, (<-results).Ip)
+	}
+
+	// 192.0.2.4 is not known
+	assert.ElementsMatch(t, []string{"192.0.2.3", ""}, ips)
+
+	requests := s.Requests()
+	require.Len(t, requests, 2)
+	assert.Equal(t, "/smoke/192.0.2.1", requests[0])
+	assert.Regexp(t, `^/smoke\?ips=192\.0\.2\.[234]`, requests[1])
+}
+
+func TestLookupIPBatchSize(t *testing.T) {
+	s := newCTIServer(t, "192.0.2.1")
+	s.hold = make(chan struct{})
+	s.held = make(chan struct{})
+
+	c := newLookupClient(s, nil, WithBatchSize(2))
+
+	calls := []*lookupCall{c.enqueue("192.0.2.1")}
+
+	<-s.held
+
+	for _, ip := range []string{"192.0.2.2", "192.0.2.3", "192.0.2.4", "192.0.2.5", "192.0.2.6"} {
+		calls = append(calls, c.enqueue(ip))
+	}
+
+	close(s.hold)
+
+	for _, call := range calls {
+		<-call.done
+		require.NoError(t, call.err)
+	}
+
+	assert.Equal(t, []string{
+		"/smoke/192.0.2.1",
+		"/smoke?ips=192.0.2.2,192.0.2.3",
+		"/smoke?ips=192.0.2.4,192.0.2.5",
+		"/smoke/192.0.2.6",
+	}, s.Requests())
+}
+
+func TestLookupIPPersistentCache(t *testing.T) {
+	s := newCTIServer(t, "192.0.2.1")
+	path := filepath.Join(t.TempDir(), "cti.db")
+
+	cache, err := NewCache(10, time.Hour, time.Hour, path, nil)
+	require.NoError(t, err)
+
+	c := newLookupClient(s, cache)
+
+	for range 2 {
+		item, err := c.LookupIP(t.Context(), "192.0.2.1")
+		require.NoError(t, err)
+		assert.Equal(t, "192.0.2.1", item.Ip)
+
+		// unknown IPs are cached too
+		item, err = c.LookupIP(t.Context(), "192.0.2.2")
+		require.NoError(t, err)
+		assert.Empty(t, item.Ip)
+	}
+
+	assert.Len(t, s.Requests(), 2)
+	require.NoError(t, cache.Close())
+
+	// after a restart
+	cache, err = NewCache(10, time.Hour, time.Hour, path, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { cache.Close() })
+
+	assert.Equal(t, 2, cache.Len(true))
+
+	c = newLookupClient(s, cache)
+
+	item, err := c.LookupIP(t.Context(), "192.0.2.1")
+	require.NoError(t, err)
+	assert.Equal(t, "192.0.2.1", item.Ip)
+	assert.Equal(t, new("192.0.2.1/32"), item.IpRange)
+
+	item, err = c.LookupIP(t.Context(), "192.0.2.2")
+	require.NoError(t, err)
+	assert.Empty(t, item.Ip)
+
+	assert.Len(t, s.Requests(), 2)
+
+	// the entries that can't be used anymore are not loaded
+	require.NoError(t, cache.Close())
+
+	cache, err = NewCache(10, time.Nanosecond, 0, path, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 0, cache.Len(false))
+	require.NoError(t, cache.Close())
+}
+
+func TestLookupIPStale(t *testing.T) {
+	s := newCTIServer(t, "192.0.2.1")
+
+	cache, err := NewCache(10, 10*time.Millisecond, time.Hour, "", nil)
+	require.NoError(t, err)
+
+	c := newLookupClient(s, cache)
+
+	_, err = c.LookupIP(t.Context(), "192.0.2.1")
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return cache.Len(true) == 0
+	}, 5*time.Second, time.Millisecond)
+
+	assert.Equal(t, 1, cache.Len(false))
+
+	s.status.Store(http.StatusTooManyRequests)
+
+	// the API refuses, the stale item is returned
+	item, err := c.LookupIP(t.Context(), "192.0.2.1")
+	require.NoError(t, err)
+	assert.Equal(t, "192.0.2.1", item.Ip)
+	assert.Len(t, s.Requests(), 2)
+
+	// no more requests until the delay sent by the API
+	assert.WithinDuration(t, time.Now().Add(time.Hour), c.backoffUntil, time.Minute)
+
+	item, err = c.LookupIP(t.Context(), "192.0.2.1")
+	require.NoError(t, err)
+	assert.Equal(t, "192.0.2.1", item.Ip)
+
+	_, err = c.LookupIP(t.Context(), "192.0.2.2")
+	require.ErrorIs(t, err, ErrLimit)
+
+	assert.Len(t, s.Requests(), 2)
+}
+
+func TestLookupIPUnauthorized(t *testing.T) {
+	s := newCTIServer(t, "192.0.2.1")
+
+	cache, err := NewCache(10, 10*time.Millisecond, time.Hour, "", nil)
+	require.NoError(t, err)
+
+	c := newLookupClient(s, cache)
+
+	_, err = c.LookupIP(t.Context(), "192.0.2.1")
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return cache.Len(true) == 0
+	}, 5*time.Second, time.Millisecond)
+
+	// a bad key is not a reason to use stale data
+	c = newLookupClient(s, cache, WithAPIKey("asdasd"))
+
+	_, err = c.LookupIP(t.Context(), "192.0.2.1")
+	require.ErrorIs(t, err, ErrUnauthorized)
+}
+
+func TestLookupIPContext(t *testing.T) {
+	s := newCTIServer(t, "192.0.2.1")
+	s.hold = make(chan struct{})
+	s.held = make(chan struct{})
+
+	c := newLookupClient(s, nil)
+
+	ctx, cancel := context.WithCancel(t.Context())
+
+	go func() {
+		<-s.held
+		cancel()
+	}()
+
+	_, err := c.LookupIP(ctx, "192.0.2.1")
+	require.ErrorIs(t, err, context.Canceled)
+
+	close(s.hold)
+}
+
+func TestBackoff(t *testing.T) {
+	c := NewCrowdsecCTIClient(WithBackoff(time.Minute, 3*time.Minute))
+
+	for _, expected := range []time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute, 3 * time.Minute} {
+		c.updateBackoff(ErrLimit)
+		assert.Equal(t, expected, c.backoff)
+		assert.WithinDuration(t, time.Now().Add(expected), c.backoffUntil, time.Second)
+	}
+
+	// other errors don't change it
+	c.updateBackoff(errors.New("connection refused"))
+	assert.Equal(t, 3*time.Minute, c.backoff)
+
+	c.updateBackoff(&limitError{retryAfter: 10 * time.Second})
+	assert.Equal(t, 3*time.Minute, c.backoff)
+	assert.WithinDuration(t, time.Now().Add(10*time.Second), c.backoffUntil, time.Second)
+
+	c.updateBackoff(nil)
+	assert.Zero(t, c.backoff)
+
+	c.updateBackoff(ErrLimit)
+	assert.Equal(t, time.Minute, c.backoff)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	assert.Equal(t, 120*time.Second, parseRetryAfter("120"))
+	assert.Zero(t, parseRetryAfter(""))
+	assert.Zero(t, parseRetryAfter("soon"))
+	assert.Zero(t, parseRetryAfter("-5"))
+	assert.InDelta(t, time.Hour, parseRetryAfter(time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)), float64(2*time.Second))
+}
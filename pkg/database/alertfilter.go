@@ -1,6 +1,7 @@
 package database
 
 import (
+	"encoding/base64"
 	"fmt"
 	"strconv"
 	"strings"
@@ -14,6 +15,8 @@ import (
 	"github.com/crowdsecurity/crowdsec/pkg/database/ent"
 	"github.com/crowdsecurity/crowdsec/pkg/database/ent/alert"
 	"github.com/crowdsecurity/crowdsec/pkg/database/ent/decision"
+	"github.com/crowdsecurity/crowdsec/pkg/database/ent/machine"
+	"github.com/crowdsecurity/crowdsec/pkg/database/ent/meta"
 	"github.com/crowdsecurity/crowdsec/pkg/database/ent/predicate"
 	"github.com/crowdsecurity/crowdsec/pkg/types"
 )
@@ -177,6 +180,94 @@ func handleIncludeCapiFilter(value string, predicates *[]predicate.Alert) error
 	return nil
 }
 
+// AlertsLimit returns the maximum number of alerts of a list, from the "limit" filter: 0 for no limit.
+func AlertsLimit(filter map[string][]string) (int, error) {
+	val, ok := filter["limit"]
+	if !ok {
+		return defaultLimit, nil
+	}
+
+	limit, err := strconv.Atoi(val[0])
+	if err != nil {
+		return 0, fmt.Errorf("bad limit in parameters: %s: %w", val, QueryFail)
+	}
+
+	return limit, nil
+}
+
+// AlertCursor returns the position of an alert in the lists, to get the next page with the "cursor" filter.
+// It's opaque for the clients.
+func AlertCursor(a *ent.Alert) string {
+	return base64.RawURLEncoding.EncodeToString(fmt.Appendf(nil, "%d.%d", a.CreatedAt.UnixNano(), a.ID))
+}
+
+// afterAlert matches the alerts after the given one, in the order of the lists (by creation, then id).
+func afterAlert(createdAt time.Time, id int, ascending bool) predicate.Alert {
+	if ascending {
+		return alert.Or(
+			alert.CreatedAtGT(createdAt),
+			alert.And(alert.CreatedAtEQ(createdAt), alert.IDGT(id)),
+		)
+	}
+
+	return alert.Or(
+		alert.CreatedAtLT(createdAt),
+		alert.And(alert.CreatedAtEQ(createdAt), alert.IDLT(id)),
+	)
+}
+
+func handleCursorFilter(value string, ascending bool, predicates *[]predicate.Alert) error {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return fmt.Errorf("invalid cursor: %w", InvalidFilter)
+	}
+
+	nanos, id, found := strings.Cut(string(raw), ".")
+	if !found {
+		return fmt.Errorf("invalid cursor: %w", InvalidFilter)
+	}
+
+	n, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid cursor: %w", InvalidFilter)
+	}
+
+	i, err := strconv.Atoi(id)
+	if err != nil {
+		return fmt.Errorf("invalid cursor: %w", InvalidFilter)
+	}
+
+	*predicates = append(*predicates, afterAlert(time.Unix(0, n).UTC(), i, ascending))
+
+	return nil
+}
+
+// handleMetaFilter matches the alerts with a meta "key=value", or with a "key" whatever the value.
+func handleMetaFilter(value string, predicates *[]predicate.Alert) {
+	key, val, found := strings.Cut(value, "=")
+	if !found {
+		*predicates = append(*predicates, alert.HasMetasWith(meta.KeyEQ(key)))
+		return
+	}
+
+	*predicates = append(*predicates, alert.HasMetasWith(meta.KeyEQ(key), meta.ValueEQ(val)))
+}
+
+func handleEventsCountFilter(param string, value string, predicates *[]predicate.Alert) error {
+	count, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		return fmt.Errorf("invalid %s value '%s': %w", param, value, InvalidFilter)
+	}
+
+	if param == "min_events" {
+		*predicates = append(*predicates, alert.EventsCountGTE(int32(count)))
+	} else {
+		*predicates = append(*predicates, alert.EventsCountLTE(int32(count)))
+	}
+
+	return nil
+}
+
 func alertPredicatesFromFilter(filter map[string][]string) ([]predicate.Alert, error) {
 	predicates := make([]predicate.Alert, 0)
 
@@ -239,6 +330,21 @@ func alertPredicatesFromFilter(filter map[string][]string) ([]predicate.Alert, e
 			}
 		case "kind":
 			predicates = append(predicates, alert.KindEQ(value[0]))
+		case "machine_id":
+			predicates = append(predicates, alert.HasOwnerWith(machine.MachineIdEQ(value[0])))
+		case "meta":
+			for _, v := range value {
+				handleMetaFilter(v, &predicates)
+			}
+		case "min_events", "max_events":
+			if err := handleEventsCountFilter(param, value[0], &predicates); err != nil {
+				return nil, err
+			}
+		case "cursor":
+			ascending := len(filter["sort"]) > 0 && filter["sort"][0] == "ASC"
+			if err := handleCursorFilter(value[0], ascending, &predicates); err != nil {
+				return nil, err
+			}
 		case "limit":
 			continue
 		case "sort":
@@ -0,0 +1,138 @@
+package database
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/crowdsecurity/crowdsec/pkg/database/ent"
+	"github.com/crowdsecurity/crowdsec/pkg/models"
+)
+
+func alertIDs(alerts []*ent.Alert) []int {
+	ids := make([]int, len(alerts))
+	for i, a := range alerts {
+		ids[i] = a.ID
+	}
+
+	return ids
+}
+
+func TestQueryAlertCursor(t *testing.T) {
+	ctx := t.Context()
+	dbClient := getDBClient(t, ctx)
+
+	addAlerts := func(n int) {
+		t.Helper()
+
+		alerts := make([]*models.Alert, n)
+		for i := range alerts {
+			alerts[i] = makeFlushAlert("192.0.2."+strconv.Itoa(i+1), false)
+		}
+
+		_, err := dbClient.CreateAlert(ctx, "", alerts)
+		require.NoError(t, err)
+	}
+
+	addAlerts(5)
+
+	all, err := dbClient.QueryAlertWithFilter(ctx, map[string][]string{"limit": {"0"}})
+	require.NoError(t, err)
+	require.Len(t, all, 5)
+
+	for _, sort := range []string{"DESC", "ASC"} {
+		t.Run(sort, func(t *testing.T) {
+			want, err := dbClient.QueryAlertWithFilter(ctx, map[string][]string{"limit": {"0"}, "sort": {sort}})
+			require.NoError(t, err)
+
+			filter := map[string][]string{"limit": {"2"}, "sort": {sort}}
+
+			var got []*ent.Alert
+
+			for range 4 {
+				page, err := dbClient.QueryAlertWithFilter(ctx, filter)
+				require.NoError(t, err)
+
+				got = append(got, page...)
+
+				if len(page) == 0 {
+					break
+				}
+
+				filter["cursor"] = []string{AlertCursor(page[len(page)-1])}
+			}
+
+			assert.Equal(t, alertIDs(want), alertIDs(got))
+		})
+	}
+
+	// new alerts don't shift the pages
+	first, err := dbClient.QueryAlertWithFilter(ctx, map[string][]string{"limit": {"2"}})
+	require.NoError(t, err)
+
+	addAlerts(3)
+
+	next, err := dbClient.QueryAlertWithFilter(ctx, map[string][]string{"limit": {"3"}, "cursor": {AlertCursor(first[1])}})
+	require.NoError(t, err)
+	assert.Equal(t, alertIDs(all[2:]), alertIDs(next))
+
+	_, err = dbClient.QueryAlertWithFilter(ctx, map[string][]string{"cursor": {"not a cursor"}})
+	require.ErrorIs(t, err, InvalidFilter)
+}
+
+func TestQueryAlertFilters(t *testing.T) {
+	ctx := t.Context()
+	dbClient := getDBClient(t, ctx)
+
+	registerFlushTestMachine(t, ctx, dbClient, "machine1")
+	registerFlushTestMachine(t, ctx, dbClient, "machine2")
+
+	addAlert := func(machineID string, events int32, meta map[string]string) int {
+		t.Helper()
+
+		alert := makeFlushAlert("192.0.2.1", false)
+		alert.EventsCount = &events
+
+		for k, v := range meta {
+			alert.Meta = append(alert.Meta, &models.MetaItems0{Key: k, Value: v})
+		}
+
+		ids, err := dbClient.CreateAlert(ctx, machineID, []*models.Alert{alert})
+		require.NoError(t, err)
+
+		id, err := strconv.Atoi(ids[0])
+		require.NoError(t, err)
+
+		return id
+	}
+
+	a1 := addAlert("machine1", 1, map[string]string{"target_fqdn": "example.com"})
+	a2 := addAlert("machine1", 10, map[string]string{"target_fqdn": "example.org", "service": "http"})
+	a3 := addAlert("machine2", 50, nil)
+
+	tests := []struct {
+		filter map[string][]string
+		want   []int
+	}{
+		{map[string][]string{"machine_id": {"machine1"}}, []int{a2, a1}},
+		{map[string][]string{"machine_id": {"machine3"}}, []int{}},
+		{map[string][]string{"meta": {"target_fqdn"}}, []int{a2, a1}},
+		{map[string][]string{"meta": {"target_fqdn=example.com"}}, []int{a1}},
+		{map[string][]string{"meta": {"target_fqdn=example.org", "service=http"}}, []int{a2}},
+		{map[string][]string{"meta": {"target_fqdn=example.com", "service=http"}}, []int{}},
+		{map[string][]string{"min_events": {"10"}}, []int{a3, a2}},
+		{map[string][]string{"min_events": {"5"}, "max_events": {"10"}}, []int{a2}},
+		{map[string][]string{"max_events": {"10"}, "machine_id": {"machine2"}}, []int{}},
+	}
+
+	for _, tc := range tests {
+		got, err := dbClient.QueryAlertWithFilter(ctx, tc.filter)
+		require.NoError(t, err)
+		assert.Equal(t, tc.want, alertIDs(got), "%v", tc.filter)
+	}
+
+	_, err := dbClient.QueryAlertWithFilter(ctx, map[string][]string{"min_events": {"many"}})
+	require.ErrorIs(t, err, InvalidFilter)
+}
@@ -183,6 +183,9 @@ func (c *Client) CreateOrUpdateAlert(ctx context.Context, machineID string, aler
 		return "", err
 	}
 
+	c.decisionIndex.add(decisions)
+	c.auditDecisionsCreate(ctx, decisions)
+
 	return "", nil
 }
 
@@ -327,13 +330,18 @@ func (c *Client) UpdateCommunityBlocklist(ctx context.Context, alertItem *models
 
 	// Delete older decisions from capi
 
+	deletedIDs := []int{}
+
 	if err := slicetools.Batch(ctx, valueList, c.decisionBulkSize, func(ctx context.Context, vals []string) error {
+		older := decision.And(
+			decision.OriginEQ(decOrigin),
+			decision.Not(decision.HasOwnerWith(alert.IDEQ(alertRef.ID))),
+			decision.ValueIn(vals...),
+		)
+		deletedIDs = append(deletedIDs, c.decisionIDsForIndex(ctx, txClient.Decision, older)...)
+
 		deletedDecisions, err := txClient.Decision.Delete().
-			Where(decision.And(
-				decision.OriginEQ(decOrigin),
-				decision.Not(decision.HasOwnerWith(alert.IDEQ(alertRef.ID))),
-				decision.ValueIn(vals...),
-			)).Exec(ctx)
+			Where(older).Exec(ctx)
 		if err != nil {
 			return err
 		}
@@ -345,12 +353,15 @@ func (c *Client) UpdateCommunityBlocklist(ctx context.Context, alertItem *models
 
 	// Insert new decisions
 
+	insertedAll := []*ent.Decision{}
+
 	if err := slicetools.Batch(ctx, decisionBuilders, c.decisionBulkSize, func(ctx context.Context, b []*ent.DecisionCreate) error {
 		insertedDecisions, err := txClient.Decision.CreateBulk(b...).Save(ctx)
 		if err != nil {
 			return err
 		}
 		inserted += len(insertedDecisions)
+		insertedAll = append(insertedAll, insertedDecisions...)
 		return nil
 	}); err != nil {
 		return 0, 0, 0, rollbackOnError(txClient, err, "bulk creating decisions")
@@ -363,6 +374,9 @@ func (c *Client) UpdateCommunityBlocklist(ctx context.Context, alertItem *models
 		return 0, 0, 0, rollbackOnError(txClient, err, "error committing transaction")
 	}
 
+	c.decisionIndex.remove(deletedIDs)
+	c.decisionIndex.add(insertedAll)
+
 	return alertRef.ID, inserted, deleted, nil
 }
 
@@ -716,6 +730,16 @@ func (c *Client) createAlertBatch(ctx context.Context, machineID string, owner *
 		return nil, fmt.Errorf("committing alert transaction: %w: %w", err, BulkError)
 	}
 
+	decisions := []*ent.Decision{}
+
+	for _, plan := range batch {
+		c.decisionIndex.add(plan.decisions)
+
+		decisions = append(decisions, plan.decisions...)
+	}
+
+	c.auditDecisionsCreate(ctx, decisions)
+
 	return ids, nil
 }
 
@@ -804,20 +828,16 @@ func (c *Client) QueryAlertWithFilter(ctx context.Context, filter map[string][]s
 		}
 	}
 
-	limit := defaultLimit
-
-	if val, ok := filter["limit"]; ok {
-		limitConv, err := strconv.Atoi(val[0])
-		if err != nil {
-			return nil, fmt.Errorf("bad limit in parameters: %s: %w", val, QueryFail)
-		}
-
-		limit = limitConv
+	limit, err := AlertsLimit(filter)
+	if err != nil {
+		return nil, err
 	}
 
-	offset := 0
 	ret := make([]*ent.Alert, 0)
 
+	// the pages are fetched by keyset: new alerts can't shift them
+	var last *ent.Alert
+
 	for {
 		alerts := c.Ent.Alert.Query()
 
@@ -826,6 +846,10 @@ func (c *Client) QueryAlertWithFilter(ctx context.Context, filter map[string][]s
 			return nil, err
 		}
 
+		if last != nil {
+			alerts = alerts.Where(afterAlert(last.CreatedAt, last.ID, sort == "ASC"))
+		}
+
 		// only if with_decisions is present and set to false, we exclude this
 		if val, ok := filter["with_decisions"]; ok && val[0] == "false" {
 			c.Log.Debugf("skipping decisions")
@@ -852,17 +876,17 @@ func (c *Client) QueryAlertWithFilter(ctx context.Context, filter map[string][]s
 			alerts = alerts.Order(ent.Desc(alert.FieldCreatedAt), ent.Desc(alert.FieldID))
 		}
 
-		result, err := alerts.Limit(paginationSize).Offset(offset).All(ctx)
+		result, err := alerts.Limit(paginationSize).All(ctx)
 		if err != nil {
-			return nil, fmt.Errorf("pagination size: %d, offset: %d: %w: %w", paginationSize, offset, err, QueryFail)
+			return nil, fmt.Errorf("pagination size: %d, after: %d alerts: %w: %w", paginationSize, len(ret), err, QueryFail)
 		}
 
 		if len(result) == 0 { // no results, no need to try to paginate further
-			c.Log.Debugf("Pagination done because no results found at offset %d | len(ret) %d", offset, len(ret))
+			c.Log.Debugf("Pagination done because no results found after %d alerts", len(ret))
 			break
 		}
 
-		log.Debugf("QueryAlertWithFilter: pagination size %d, offset %d, got %d results", paginationSize, offset, len(result))
+		log.Debugf("QueryAlertWithFilter: pagination size %d, after %d alerts, got %d results", paginationSize, len(ret), len(result))
 		log.Debugf("diff is %d, limit is %d", limit-len(ret), limit)
 
 		if diff := limit - len(ret); diff < paginationSize {
@@ -883,7 +907,7 @@ func (c *Client) QueryAlertWithFilter(ctx context.Context, filter map[string][]s
 			break
 		}
 
-		offset += paginationSize
+		last = result[len(result)-1]
 	}
 
 	return ret, nil
@@ -909,6 +933,8 @@ func (c *Client) DeleteAlertGraphBatch(ctx context.Context, alertItems []*ent.Al
 		return 0, fmt.Errorf("alert graph delete batch meta: %w", DeleteFail)
 	}
 
+	decisionIDs := c.decisionIDsForIndex(ctx, c.Ent.Decision, decision.HasOwnerWith(alert.IDIn(idList...)))
+
 	_, err = c.Ent.Decision.Delete().
 		Where(decision.HasOwnerWith(alert.IDIn(idList...))).Exec(ctx)
 	if err != nil {
@@ -916,6 +942,8 @@ func (c *Client) DeleteAlertGraphBatch(ctx context.Context, alertItems []*ent.Al
 		return 0, fmt.Errorf("alert graph delete batch decisions: %w", DeleteFail)
 	}
 
+	c.decisionIndex.remove(decisionIDs)
+
 	deleted, err := c.Ent.Alert.Delete().
 		Where(alert.IDIn(idList...)).Exec(ctx)
 	if err != nil {
@@ -946,6 +974,8 @@ func (c *Client) DeleteAlertGraph(ctx context.Context, alertItem *ent.Alert) err
 	}
 
 	// delete the associated decisions
+	decisionIDs := c.decisionIDsForIndex(ctx, c.Ent.Decision, decision.HasOwnerWith(alert.IDEQ(alertItem.ID)))
+
 	_, err = c.Ent.Decision.Delete().
 		Where(decision.HasOwnerWith(alert.IDEQ(alertItem.ID))).Exec(ctx)
 	if err != nil {
@@ -953,6 +983,8 @@ func (c *Client) DeleteAlertGraph(ctx context.Context, alertItem *ent.Alert) err
 		return fmt.Errorf("decision with alert ID '%d': %w", alertItem.ID, DeleteFail)
 	}
 
+	c.decisionIndex.remove(decisionIDs)
+
 	// delete the alert
 	err = c.Ent.Alert.DeleteOne(alertItem).Exec(ctx)
 	if err != nil {
@@ -969,7 +1001,13 @@ func (c *Client) DeleteAlertByID(ctx context.Context, id int) error {
 		return err
 	}
 
-	return c.DeleteAlertGraph(ctx, alertItem)
+	if err := c.DeleteAlertGraph(ctx, alertItem); err != nil {
+		return err
+	}
+
+	c.audit(ctx, AuditAlertsDelete, "id="+strconv.Itoa(id), 1)
+
+	return nil
 }
 
 func (c *Client) DeleteAlertWithFilter(ctx context.Context, filter map[string][]string) (int, error) {
@@ -978,7 +1016,19 @@ func (c *Client) DeleteAlertWithFilter(ctx context.Context, filter map[string][]
 		return 0, err
 	}
 
-	return c.Ent.Alert.Delete().Where(preds...).Exec(ctx)
+	// the decisions are deleted by cascade
+	decisionIDs := c.decisionIDsForIndex(ctx, c.Ent.Decision, decision.HasOwnerWith(preds...))
+
+	count, err := c.Ent.Alert.Delete().Where(preds...).Exec(ctx)
+	if err != nil {
+		return count, err
+	}
+
+	c.decisionIndex.remove(decisionIDs)
+
+	c.audit(ctx, AuditAlertsDelete, auditFilter(filter), count)
+
+	return count, nil
 }
 
 func (c *Client) GetAlertByID(ctx context.Context, alertID int) (*ent.Alert, error) {
@@ -0,0 +1,127 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"entgo.io/ent/dialect"
+	"entgo.io/ent/dialect/sql"
+
+	"github.com/crowdsecurity/crowdsec/pkg/database/ent/alert"
+	"github.com/crowdsecurity/crowdsec/pkg/database/ent/decision"
+	"github.com/crowdsecurity/crowdsec/pkg/database/ent/machine"
+)
+
+// AlertStatsGroups are the keys the alert statistics can be grouped by.
+var AlertStatsGroups = []string{"scenario", "country", "as", "scope", "origin", "machine"}
+
+// AlertStatsRow is the number of alerts, and of their decisions, in a time bucket for a key.
+type AlertStatsRow struct {
+	// BucketStart is a unix timestamp, 0 if the statistics are not bucketed
+	BucketStart int64  `json:"bucket_start"`
+	GroupKey    string `json:"group_key"`
+	Alerts      int64  `json:"alerts"`
+	Decisions   int64  `json:"decisions"`
+}
+
+// bucketExpr returns the start of the time bucket of a column, as a unix timestamp.
+func bucketExpr(dia string, column string, bucket time.Duration) string {
+	seconds := int64(bucket.Seconds())
+
+	switch dia {
+	case dialect.MySQL:
+		return fmt.Sprintf("CAST(FLOOR(UNIX_TIMESTAMP(%s) / %d) * %d AS SIGNED)", column, seconds, seconds)
+	case dialect.Postgres:
+		return fmt.Sprintf("CAST(FLOOR(EXTRACT(EPOCH FROM %s) / %d) * %d AS BIGINT)", column, seconds, seconds)
+	default:
+		return fmt.Sprintf("(CAST(strftime('%%s', %s) AS INTEGER) / %d * %d)", column, seconds, seconds)
+	}
+}
+
+// AlertStats counts the alerts matching the filter, and their decisions, per time bucket (of the alert start)
+// and per groupBy key. Both are optional: with no bucket and no group, the result is a single row.
+// The aggregation is done by the database, the alerts are not loaded.
+func (c *Client) AlertStats(ctx context.Context, filter map[string][]string, groupBy string, bucket time.Duration) ([]AlertStatsRow, error) {
+	if groupBy != "" && !slices.Contains(AlertStatsGroups, groupBy) {
+		return nil, fmt.Errorf("cannot group by '%s' (supported: %s): %w", groupBy, strings.Join(AlertStatsGroups, ", "), InvalidFilter)
+	}
+
+	if bucket < 0 || (bucket > 0 && bucket < time.Minute) {
+		return nil, fmt.Errorf("invalid bucket '%s', at least 1m: %w", bucket, InvalidFilter)
+	}
+
+	query, err := applyAlertFilter(c.Ent.Alert.Query(), filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []AlertStatsRow
+
+	decisions := sql.Table(decision.Table)
+
+	// the joins and the grouping are done by the first aggregation, the others only select
+	groupAndBucket := func(s *sql.Selector) string {
+		s.LeftJoin(decisions).On(s.C(alert.FieldID), decisions.C(decision.FieldAlertDecisions))
+
+		var key string
+
+		switch groupBy {
+		case "scenario":
+			key = s.C(alert.FieldScenario)
+		case "country":
+			key = s.C(alert.FieldSourceCountry)
+		case "as":
+			key = s.C(alert.FieldSourceAsNumber)
+		case "scope":
+			key = s.C(alert.FieldSourceScope)
+		case "origin":
+			key = decisions.C(decision.FieldOrigin)
+		case "machine":
+			machines := sql.Table(machine.Table)
+			s.LeftJoin(machines).On(s.C(alert.OwnerColumn), machines.C(machine.FieldID))
+			key = machines.C(machine.FieldMachineId)
+		}
+
+		// constants can't be in a GROUP BY
+		groups := []string{}
+
+		start := "0"
+		if bucket > 0 {
+			start = bucketExpr(s.Dialect(), s.C(alert.FieldStartedAt), bucket)
+			groups = append(groups, start)
+		}
+
+		if key != "" {
+			key = "COALESCE(" + key + ", '')"
+			groups = append(groups, key)
+		} else {
+			key = "''"
+		}
+
+		if len(groups) > 0 {
+			s.GroupBy(groups...)
+			s.OrderBy(groups...)
+		}
+
+		return start + " AS bucket_start, " + key + " AS group_key"
+	}
+
+	countAlerts := func(s *sql.Selector) string {
+		return "COUNT(DISTINCT " + s.C(alert.FieldID) + ") AS alerts"
+	}
+
+	countDecisions := func(*sql.Selector) string {
+		return "COUNT(" + decisions.C(decision.FieldID) + ") AS decisions"
+	}
+
+	err = query.Select().Aggregate(groupAndBucket, countAlerts, countDecisions).Scan(ctx, &rows)
+	if err != nil {
+		c.Log.Warningf("AlertStats: %s", err)
+		return nil, fmt.Errorf("alert statistics: %w", QueryFail)
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,77 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/crowdsecurity/go-cs-lib/cstest"
+
+	"github.com/crowdsecurity/crowdsec/pkg/models"
+)
+
+func TestAlertStats(t *testing.T) {
+	ctx := t.Context()
+	dbClient := getDBClient(t, ctx)
+
+	registerFlushTestMachine(t, ctx, dbClient, "machine1")
+
+	hour := time.Now().UTC().Truncate(time.Hour)
+
+	alertAt := func(start time.Time, country string, decisions ...string) {
+		t.Helper()
+
+		alert := makeFlushAlert("192.0.2.1", false)
+		alert.StartAt = new(start.Format(time.RFC3339))
+		alert.Source.Cn = country
+
+		for _, origin := range decisions {
+			alert.Decisions = append(alert.Decisions, makeIndexDecision("Ip", "192.0.2.1", "1h", origin))
+		}
+
+		_, err := dbClient.CreateAlert(ctx, "machine1", []*models.Alert{alert})
+		require.NoError(t, err)
+	}
+
+	alertAt(hour.Add(-2*time.Hour+time.Minute), "FR", "crowdsec")
+	alertAt(hour.Add(-2*time.Hour+2*time.Minute), "FR", "crowdsec", "cscli")
+	alertAt(hour.Add(-time.Hour+time.Minute), "US")
+	alertAt(hour.Add(-time.Hour+2*time.Minute), "")
+
+	rows, err := dbClient.AlertStats(ctx, map[string][]string{}, "", 0)
+	require.NoError(t, err)
+	assert.Equal(t, []AlertStatsRow{{Alerts: 4, Decisions: 3}}, rows)
+
+	rows, err = dbClient.AlertStats(ctx, map[string][]string{}, "country", 0)
+	require.NoError(t, err)
+	assert.Equal(t, []AlertStatsRow{
+		{GroupKey: "", Alerts: 1},
+		{GroupKey: "FR", Alerts: 2, Decisions: 3},
+		{GroupKey: "US", Alerts: 1},
+	}, rows)
+
+	rows, err = dbClient.AlertStats(ctx, map[string][]string{}, "origin", time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, []AlertStatsRow{
+		{BucketStart: hour.Add(-2 * time.Hour).Unix(), GroupKey: "crowdsec", Alerts: 2, Decisions: 2},
+		{BucketStart: hour.Add(-2 * time.Hour).Unix(), GroupKey: "cscli", Alerts: 1, Decisions: 1},
+		{BucketStart: hour.Add(-time.Hour).Unix(), GroupKey: "", Alerts: 2},
+	}, rows)
+
+	rows, err = dbClient.AlertStats(ctx, map[string][]string{"since": {"3h"}}, "machine", 0)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, "machine1", rows[0].GroupKey)
+	assert.Equal(t, int64(4), rows[0].Alerts)
+
+	_, err = dbClient.AlertStats(ctx, map[string][]string{}, "ip", 0)
+	cstest.RequireErrorContains(t, err, "cannot group by 'ip'")
+
+	_, err = dbClient.AlertStats(ctx, map[string][]string{}, "", time.Second)
+	cstest.RequireErrorContains(t, err, "invalid bucket '1s', at least 1m")
+
+	_, err = dbClient.AlertStats(ctx, map[string][]string{"foo": {"bar"}}, "", 0)
+	require.ErrorIs(t, err, InvalidFilter)
+}
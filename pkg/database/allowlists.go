@@ -36,6 +36,8 @@ func (c *Client) CreateAllowList(ctx context.Context, name string, description s
 		return nil, fmt.Errorf("unable to create allowlist: %w", err)
 	}
 
+	c.audit(ctx, AuditAllowlistsCreate, name, 1)
+
 	return allowlist, nil
 }
 
@@ -59,6 +61,8 @@ func (c *Client) DeleteAllowList(ctx context.Context, name string, fromConsole b
 		return fmt.Errorf("allowlist %s not found", name)
 	}
 
+	c.audit(ctx, AuditAllowlistsDelete, name, nbDeleted)
+
 	return nil
 }
 
@@ -82,6 +86,8 @@ func (c *Client) DeleteAllowListByID(ctx context.Context, name string, allowlist
 		return fmt.Errorf("allowlist %s not found", name)
 	}
 
+	c.audit(ctx, AuditAllowlistsDelete, name, nbDeleted)
+
 	return nil
 }
 
@@ -132,6 +138,22 @@ func (c *Client) GetAllowListByID(ctx context.Context, allowlistID string, withC
 }
 
 func (c *Client) AddToAllowlist(ctx context.Context, list *ent.AllowList, items []*models.AllowlistItem) (int, error) {
+	added, err := c.addToAllowlist(ctx, list, items)
+	if err != nil {
+		return added, err
+	}
+
+	values := make([]string, len(items))
+	for i, item := range items {
+		values[i] = item.Value
+	}
+
+	c.audit(ctx, AuditAllowlistsAdd, list.Name+" "+strings.Join(values, ","), added)
+
+	return added, nil
+}
+
+func (c *Client) addToAllowlist(ctx context.Context, list *ent.AllowList, items []*models.AllowlistItem) (int, error) {
 	added := 0
 
 	c.Log.Debugf("adding %d values to allowlist %s", len(items), list.Name)
@@ -201,6 +223,8 @@ func (c *Client) RemoveFromAllowlist(ctx context.Context, list *ent.AllowList, v
 		return 0, fmt.Errorf("unable to remove values from allowlist: %w", err)
 	}
 
+	c.audit(ctx, AuditAllowlistsRemove, list.Name+" "+strings.Join(values, ","), nbDeleted)
+
 	return nbDeleted, nil
 }
 
@@ -212,6 +236,8 @@ func (c *Client) UpdateAllowlistMeta(ctx context.Context, allowlistID string, na
 		return fmt.Errorf("unable to update allowlist: %w", err)
 	}
 
+	c.audit(ctx, AuditAllowlistsUpdate, name, 1)
+
 	return nil
 }
 
@@ -224,7 +250,7 @@ func (c *Client) ReplaceAllowlist(ctx context.Context, list *ent.AllowList, item
 		return 0, fmt.Errorf("unable to delete allowlist contents: %w", err)
 	}
 
-	added, err := c.AddToAllowlist(ctx, list, items)
+	added, err := c.addToAllowlist(ctx, list, items)
 	if err != nil {
 		return 0, fmt.Errorf("unable to add values to allowlist: %w", err)
 	}
@@ -238,6 +264,8 @@ func (c *Client) ReplaceAllowlist(ctx context.Context, list *ent.AllowList, item
 		}
 	}
 
+	c.audit(ctx, AuditAllowlistsReplace, list.Name, added)
+
 	return added, nil
 }
 
@@ -543,5 +571,10 @@ func (c *Client) applyAllowlistBatch(ctx context.Context, items []*ent.AllowList
 		c.Log.Debugf("expired %d decisions for batch of %d allowlist items", count, len(batch))
 	}
 
+	if totalCount > 0 {
+		// the expired decisions are not known here, the index gets them from the database
+		c.decisionIndex.poke()
+	}
+
 	return totalCount, nil
 }
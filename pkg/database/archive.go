@@ -0,0 +1,351 @@
+package database
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+
+	"github.com/crowdsecurity/crowdsec/pkg/csconfig"
+	"github.com/crowdsecurity/crowdsec/pkg/database/ent"
+	"github.com/crowdsecurity/crowdsec/pkg/database/ent/alert"
+	"github.com/crowdsecurity/crowdsec/pkg/database/ent/predicate"
+	"github.com/crowdsecurity/crowdsec/pkg/models"
+)
+
+const (
+	// alerts read, archived and deleted at once by the flush job
+	archiveBatchSize = 500
+	// alerts created at once by an archive import
+	archiveImportBatchSize = 1000
+	// longest line accepted when reading an archive
+	maxArchiveLineSize = 64 * 1024 * 1024
+)
+
+// AlertArchive writes alerts to gzip-compressed JSONL files, one alert per line,
+// in a single directory. Files are named after the UTC day they are written
+// (alerts-2006-01-02.jsonl.gz), and a numbered file (alerts-2006-01-02.1.jsonl.gz)
+// is started when one grows over the maximum size.
+//
+// Each call to Write appends a new gzip member to the current file: the result
+// is a regular multi-member gzip file, readable by zcat or gzip.Reader.
+type AlertArchive struct {
+	dir     string
+	maxSize int64
+	now     func() time.Time
+}
+
+func NewAlertArchive(cfg *csconfig.AlertArchiveCfg) *AlertArchive {
+	return &AlertArchive{
+		dir:     cfg.Dir,
+		maxSize: int64(cfg.MaxSize) * 1024 * 1024,
+		now:     time.Now,
+	}
+}
+
+// currentFile returns the path of the file to append to: the first of the day
+// that has not reached the maximum size.
+func (a *AlertArchive) currentFile() (string, error) {
+	day := a.now().UTC().Format(time.DateOnly)
+
+	for n := 0; ; n++ {
+		name := "alerts-" + day
+		if n > 0 {
+			name += "." + strconv.Itoa(n)
+		}
+
+		path := filepath.Join(a.dir, name+".jsonl.gz")
+
+		info, err := os.Stat(path)
+
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			return path, nil
+		case err != nil:
+			return "", err
+		case a.maxSize <= 0 || info.Size() < a.maxSize:
+			return path, nil
+		}
+	}
+}
+
+// Write appends the alerts to the current archive file, and syncs it to disk
+// before returning: the caller can delete them from the database afterwards.
+func (a *AlertArchive) Write(alerts []*models.Alert) error {
+	if len(alerts) == 0 {
+		return nil
+	}
+
+	if err := os.MkdirAll(a.dir, 0o750); err != nil {
+		return fmt.Errorf("creating archive directory: %w", err)
+	}
+
+	path, err := a.currentFile()
+	if err != nil {
+		return fmt.Errorf("looking for the archive file: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o640)
+	if err != nil {
+		return fmt.Errorf("opening archive file: %w", err)
+	}
+
+	if err := writeArchive(f, alerts); err != nil {
+		f.Close()
+		return fmt.Errorf("writing to %s: %w", path, err)
+	}
+
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("syncing %s: %w", path, err)
+	}
+
+	return f.Close()
+}
+
+func writeArchive(w io.Writer, alerts []*models.Alert) error {
+	zw := gzip.NewWriter(w)
+	enc := json.NewEncoder(zw)
+
+	for _, a := range alerts {
+		if err := enc.Encode(a); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+// archivedAlert converts an alert, with its edges, to the form stored in the
+// archive. Unlike the API representation, the decisions keep their expiration
+// date (until), and their duration is relative to the end of the alert, so
+// that they are restored as they were when the archive is imported.
+func archivedAlert(a *ent.Alert) *models.Alert {
+	ret := &models.Alert{
+		ID:              int64(a.ID),
+		CreatedAt:       a.CreatedAt.UTC().Format(time.RFC3339),
+		Scenario:        new(a.Scenario),
+		ScenarioVersion: new(a.ScenarioVersion),
+		ScenarioHash:    new(a.ScenarioHash),
+		Message:         new(a.Message),
+		EventsCount:     new(a.EventsCount),
+		StartAt:         new(a.StartedAt.UTC().Format(time.RFC3339)),
+		StopAt:          new(a.StoppedAt.UTC().Format(time.RFC3339)),
+		Capacity:        new(a.Capacity),
+		Leakspeed:       new(a.LeakSpeed),
+		Simulated:       new(a.Simulated),
+		Remediation:     a.Remediation,
+		UUID:            a.UUID,
+		Kind:            a.Kind,
+		Events:          []*models.Event{},
+		Source: &models.Source{
+			Scope:     new(a.SourceScope),
+			Value:     new(a.SourceValue),
+			IP:        a.SourceIp,
+			Range:     a.SourceRange,
+			AsNumber:  a.SourceAsNumber,
+			AsName:    a.SourceAsName,
+			Cn:        a.SourceCountry,
+			Latitude:  a.SourceLatitude,
+			Longitude: a.SourceLongitude,
+		},
+	}
+
+	if a.Edges.Owner != nil {
+		ret.MachineID = a.Edges.Owner.MachineId
+	}
+
+	for _, e := range a.Edges.Events {
+		var meta models.Meta
+
+		if err := json.Unmarshal([]byte(e.Serialized), &meta); err != nil {
+			meta = models.Meta{}
+		}
+
+		ret.Events = append(ret.Events, &models.Event{
+			Timestamp: new(e.Time.UTC().Format(time.RFC3339)),
+			Meta:      meta,
+		})
+	}
+
+	for _, m := range a.Edges.Metas {
+		ret.Meta = append(ret.Meta, &models.MetaItems0{Key: m.Key, Value: m.Value})
+	}
+
+	for _, d := range a.Edges.Decisions {
+		dec := &models.Decision{
+			ID:        int64(d.ID),
+			Origin:    new(d.Origin),
+			Scenario:  new(d.Scenario),
+			Scope:     new(d.Scope),
+			Value:     new(d.Value),
+			Type:      new(d.Type),
+			Simulated: new(d.Simulated),
+			UUID:      d.UUID,
+			Duration:  new("0s"),
+		}
+
+		if d.Until != nil {
+			dec.Until = d.Until.UTC().Format(time.RFC3339)
+			dec.Duration = new(d.Until.Sub(a.StoppedAt).Round(time.Second).String())
+		}
+
+		ret.Decisions = append(ret.Decisions, dec)
+	}
+
+	return ret
+}
+
+// archiveAndDeleteAlerts deletes the alerts matching the predicates, like
+// Alert.Delete(), but writes them to the archive first if there is one.
+// Alerts are processed by batches: a batch is only deleted once it has been
+// written, and an archive error stops the flush.
+func (c *Client) archiveAndDeleteAlerts(ctx context.Context, predicates ...predicate.Alert) (int, error) {
+	if c.alertArchive == nil {
+		return c.Ent.Alert.Delete().Where(predicates...).Exec(ctx)
+	}
+
+	deleted := 0
+	lastID := 0
+
+	for {
+		batch, err := c.Ent.Alert.Query().
+			Where(predicates...).
+			Where(alert.IDGT(lastID)).
+			Order(ent.Asc(alert.FieldID)).
+			Limit(archiveBatchSize).
+			WithOwner().
+			WithDecisions().
+			WithEvents().
+			WithMetas().
+			All(ctx)
+		if err != nil {
+			return deleted, fmt.Errorf("querying alerts to archive: %w", err)
+		}
+
+		if len(batch) == 0 {
+			return deleted, nil
+		}
+
+		records := make([]*models.Alert, len(batch))
+		ids := make([]int, len(batch))
+
+		for i, a := range batch {
+			records[i] = archivedAlert(a)
+			ids[i] = a.ID
+		}
+
+		if err := c.alertArchive.Write(records); err != nil {
+			return deleted, fmt.Errorf("archiving alerts: %w", err)
+		}
+
+		n, err := c.Ent.Alert.Delete().Where(alert.IDIn(ids...)).Exec(ctx)
+		if err != nil {
+			return deleted, err
+		}
+
+		deleted += n
+		lastID = ids[len(ids)-1]
+	}
+}
+
+// ImportAlertArchive reads an archive file written by the flush job, and
+// creates again the alerts that started between since and until (ignored
+// if zero) with their decisions, events and meta. The alerts are attached
+// to their original machine if it still exists. It returns the number of
+// imported alerts.
+func (c *Client) ImportAlertArchive(ctx context.Context, r io.Reader, since time.Time, until time.Time) (int, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return 0, fmt.Errorf("reading archive: %w", err)
+	}
+	defer zr.Close()
+
+	resume := c.PauseFlush()
+	defer resume()
+
+	imported := 0
+	pending := map[string][]*models.Alert{}
+	npending := 0
+
+	flush := func() error {
+		for machineID, alerts := range pending {
+			ids, err := c.CreateAlert(ctx, machineID, alerts)
+			if err != nil {
+				return err
+			}
+
+			imported += len(ids)
+		}
+
+		clear(pending)
+		npending = 0
+
+		return nil
+	}
+
+	scanner := bufio.NewScanner(zr)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxArchiveLineSize)
+
+	line := 0
+
+	for scanner.Scan() {
+		line++
+
+		a := &models.Alert{}
+		if err := json.Unmarshal(scanner.Bytes(), a); err != nil {
+			return imported, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		// events are required by the API, not here
+		if a.Events == nil {
+			a.Events = []*models.Event{}
+		}
+
+		if err := a.Validate(strfmt.Default); err != nil {
+			return imported, fmt.Errorf("line %d: invalid alert: %w", line, err)
+		}
+
+		startAt, err := time.Parse(time.RFC3339, *a.StartAt)
+		if err != nil {
+			return imported, fmt.Errorf("line %d: invalid start_at: %w", line, err)
+		}
+
+		if (!since.IsZero() && startAt.Before(since)) || (!until.IsZero() && startAt.After(until)) {
+			continue
+		}
+
+		a.ID = 0
+		for _, d := range a.Decisions {
+			d.ID = 0
+		}
+
+		pending[a.MachineID] = append(pending[a.MachineID], a)
+		npending++
+
+		if npending >= archiveImportBatchSize {
+			if err := flush(); err != nil {
+				return imported, err
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return imported, fmt.Errorf("reading archive: %w", err)
+	}
+
+	if err := flush(); err != nil {
+		return imported, err
+	}
+
+	return imported, nil
+}
@@ -0,0 +1,114 @@
+package database
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/crowdsecurity/crowdsec/pkg/csconfig"
+	"github.com/crowdsecurity/crowdsec/pkg/models"
+)
+
+func TestAlertArchive(t *testing.T) {
+	ctx := t.Context()
+	src := getDBClient(t, ctx)
+
+	dir := t.TempDir()
+	src.alertArchive = NewAlertArchive(&csconfig.AlertArchiveCfg{Dir: dir})
+
+	registerFlushTestMachine(t, ctx, src, "machine1")
+
+	old := time.Now().UTC().Add(-48 * time.Hour).Truncate(time.Second)
+
+	expired := makeFlushAlert("192.0.2.1", false)
+	expired.StartAt = new(old.Format(time.RFC3339))
+	expired.StopAt = new(old.Format(time.RFC3339))
+	expired.Decisions = []*models.Decision{makeIndexDecision("Ip", "192.0.2.1", "4h", "crowdsec")}
+	expired.Meta = models.Meta{{Key: "target_fqdn", Value: "example.com"}}
+	expired.Events = []*models.Event{{
+		Timestamp: new(old.Format(time.RFC3339)),
+		Meta:      models.Meta{{Key: "log_type", Value: "ssh_failed-auth"}},
+	}}
+
+	recent := makeFlushAlert("192.0.2.2", false)
+
+	_, err := src.CreateAlert(ctx, "machine1", []*models.Alert{expired, recent, makeFlushAlert("192.0.2.3", true)})
+	require.NoError(t, err)
+
+	time.Sleep(20 * time.Millisecond)
+
+	// the alert with an active decision stays, the two others are archived
+	require.NoError(t, src.FlushAlerts(ctx, 5*time.Millisecond, 0))
+	assert.Equal(t, map[string]bool{"192.0.2.3": true}, remainingAlertValues(t, ctx, src))
+
+	files, err := filepath.Glob(filepath.Join(dir, "alerts-*.jsonl.gz"))
+	require.NoError(t, err)
+	require.Equal(t, []string{filepath.Join(dir, "alerts-"+time.Now().UTC().Format(time.DateOnly)+".jsonl.gz")}, files)
+
+	dst, err := NewClient(ctx, &csconfig.DatabaseCfg{
+		Type:   "sqlite",
+		DbName: "crowdsec",
+		DbPath: filepath.Join(t.TempDir(), "crowdsec.db"),
+	}, nil)
+	require.NoError(t, err)
+
+	registerFlushTestMachine(t, ctx, dst, "machine1")
+
+	f, err := os.Open(files[0])
+	require.NoError(t, err)
+
+	defer f.Close()
+
+	// only the old alert started in the range
+	n, err := dst.ImportAlertArchive(ctx, f, old.Add(-time.Hour), old.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	alerts, err := dst.Ent.Alert.Query().WithOwner().WithDecisions().WithEvents().WithMetas().All(ctx)
+	require.NoError(t, err)
+	require.Len(t, alerts, 1)
+
+	a := alerts[0]
+	assert.Equal(t, "192.0.2.1", a.SourceValue)
+	assert.Equal(t, old, a.StartedAt.UTC())
+	assert.Equal(t, "machine1", a.Edges.Owner.MachineId)
+	require.Len(t, a.Edges.Metas, 1)
+	assert.Equal(t, "example.com", a.Edges.Metas[0].Value)
+	require.Len(t, a.Edges.Events, 1)
+	assert.Contains(t, a.Edges.Events[0].Serialized, "ssh_failed-auth")
+	require.Len(t, a.Edges.Decisions, 1)
+	assert.Equal(t, old.Add(4*time.Hour), a.Edges.Decisions[0].Until.UTC())
+	assert.Equal(t, "crowdsec", a.Edges.Decisions[0].Origin)
+}
+
+func TestAlertArchiveRotation(t *testing.T) {
+	dir := t.TempDir()
+	archive := NewAlertArchive(&csconfig.AlertArchiveCfg{Dir: dir})
+	archive.now = func() time.Time { return time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC) }
+
+	first := filepath.Join(dir, "alerts-2026-03-01.jsonl.gz")
+	require.NoError(t, archive.Write([]*models.Alert{makeFlushAlert("192.0.2.1", false)}))
+	require.NoError(t, archive.Write([]*models.Alert{makeFlushAlert("192.0.2.2", false)}))
+	require.FileExists(t, first)
+
+	// a full file is not appended to
+	archive.maxSize = 1
+	require.NoError(t, archive.Write([]*models.Alert{makeFlushAlert("192.0.2.3", false)}))
+	require.FileExists(t, filepath.Join(dir, "alerts-2026-03-01.1.jsonl.gz"))
+
+	// both members of the first file are read
+	dbClient := getDBClient(t, t.Context())
+
+	f, err := os.Open(first)
+	require.NoError(t, err)
+
+	defer f.Close()
+
+	n, err := dbClient.ImportAlertArchive(t.Context(), f, time.Time{}, time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+}
@@ -0,0 +1,229 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"os/user"
+	"strings"
+	"time"
+
+	"github.com/crowdsecurity/crowdsec/pkg/database/ent"
+	"github.com/crowdsecurity/crowdsec/pkg/database/ent/auditlog"
+	"github.com/crowdsecurity/crowdsec/pkg/database/ent/predicate"
+	"github.com/crowdsecurity/crowdsec/pkg/types"
+)
+
+// how long to keep the audit log if not configured
+const defaultAuditMaxAge = 90 * 24 * time.Hour
+
+const (
+	AuditActorMachine = auditlog.ActorTypeMachine
+	AuditActorBouncer = auditlog.ActorTypeBouncer
+	AuditActorCscli   = auditlog.ActorTypeCscli
+	AuditActorPAPI    = auditlog.ActorTypePapi
+)
+
+// The actions recorded in the audit log.
+const (
+	AuditDecisionsCreate   = "decisions.create"
+	AuditDecisionsDelete   = "decisions.delete"
+	AuditAlertsDelete      = "alerts.delete"
+	AuditMachinesCreate    = "machines.create"
+	AuditMachinesValidate  = "machines.validate"
+	AuditMachinesDelete    = "machines.delete"
+	AuditMachinesSetRoles  = "machines.set_roles"
+	AuditBouncersCreate    = "bouncers.create"
+	AuditBouncersDelete    = "bouncers.delete"
+	AuditBouncersRestrict  = "bouncers.restrict"
+	AuditAllowlistsCreate  = "allowlists.create"
+	AuditAllowlistsDelete  = "allowlists.delete"
+	AuditAllowlistsUpdate  = "allowlists.update"
+	AuditAllowlistsAdd     = "allowlists.add"
+	AuditAllowlistsRemove  = "allowlists.remove"
+	AuditAllowlistsReplace = "allowlists.replace"
+)
+
+// AuditActor is who makes a change. It's carried by the context, so that the database functions
+// can record the changes without knowing where they come from.
+type AuditActor struct {
+	Type auditlog.ActorType
+	Name string
+	IP   string
+}
+
+type auditActorKey struct{}
+
+// WithAuditActor returns a context for the changes made by the actor.
+// The changes made with a context without actor (flush, CAPI pulls...) are not recorded.
+func WithAuditActor(ctx context.Context, actor AuditActor) context.Context {
+	return context.WithValue(ctx, auditActorKey{}, actor)
+}
+
+func auditActorFromContext(ctx context.Context) (AuditActor, bool) {
+	actor, ok := ctx.Value(auditActorKey{}).(AuditActor)
+	return actor, ok
+}
+
+// LocalAuditActor is the actor for the changes made by cscli: the local user, on this host.
+func LocalAuditActor() AuditActor {
+	name := "unknown"
+
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+
+	if host, err := os.Hostname(); err == nil {
+		name += "@" + host
+	}
+
+	return AuditActor{
+		Type: AuditActorCscli,
+		Name: name,
+	}
+}
+
+// auditFilter is the target of a change made with a filter, in a stable form.
+func auditFilter(filter map[string][]string) string {
+	return url.Values(filter).Encode()
+}
+
+// auditMaxTargets is the number of values listed in the target of a change on many items
+const auditMaxTargets = 10
+
+// auditList is the target of a change on several items: the first ones, then how many others.
+func auditList(items []string) string {
+	if len(items) <= auditMaxTargets {
+		return strings.Join(items, ",")
+	}
+
+	return fmt.Sprintf("%s,+%d", strings.Join(items[:auditMaxTargets], ","), len(items)-auditMaxTargets)
+}
+
+// audit records a change, if the context has an actor. A failure doesn't cancel the change,
+// it's only logged.
+func (c *Client) audit(ctx context.Context, action string, target string, count int) {
+	actor, ok := auditActorFromContext(ctx)
+	if !ok {
+		return
+	}
+
+	// the change has been made, record it even if the request has been canceled in the meantime
+	ctx = context.WithoutCancel(ctx)
+
+	err := c.Ent.AuditLog.Create().
+		SetActorType(actor.Type).
+		SetActor(actor.Name).
+		SetIPAddress(actor.IP).
+		SetAction(action).
+		SetTarget(target).
+		SetCount(count).
+		Exec(ctx)
+	if err != nil {
+		c.Log.Errorf("unable to record %s by %s '%s' in the audit log: %s", action, actor.Type, actor.Name, err)
+	}
+}
+
+// isAgentDecision tells if a decision has been made by the profiles for the alert of an agent.
+func isAgentDecision(d *ent.Decision) bool {
+	return d.Origin == types.CrowdSecOrigin || strings.HasPrefix(d.Origin, types.CrowdSecOrigin+"/")
+}
+
+// auditDecisionsCreate records the decisions created with an alert. The decisions of the agents
+// are not recorded: they are not administrative changes, and there's one for each alert.
+func (c *Client) auditDecisionsCreate(ctx context.Context, decisions []*ent.Decision) {
+	targets := make([]string, 0, len(decisions))
+
+	for _, d := range decisions {
+		if isAgentDecision(d) {
+			continue
+		}
+
+		targets = append(targets, d.Type+" "+d.Scope+":"+d.Value)
+	}
+
+	if len(targets) == 0 {
+		return
+	}
+
+	c.audit(ctx, AuditDecisionsCreate, auditList(targets), len(targets))
+}
+
+// AuditLogFilter selects records of the audit log. Empty fields match everything.
+type AuditLogFilter struct {
+	ActorType string
+	Actor     string
+	// Action matches the actions with this prefix: "decisions" or "decisions.delete"
+	Action string
+	Since  time.Time
+	Until  time.Time
+	Limit  int
+}
+
+// QueryAuditLog returns the matching records, most recent first.
+func (c *Client) QueryAuditLog(ctx context.Context, filter AuditLogFilter) ([]*ent.AuditLog, error) {
+	preds := []predicate.AuditLog{}
+
+	if filter.ActorType != "" {
+		actorType := auditlog.ActorType(filter.ActorType)
+		if err := auditlog.ActorTypeValidator(actorType); err != nil {
+			return nil, fmt.Errorf("invalid actor type '%s'", filter.ActorType)
+		}
+
+		preds = append(preds, auditlog.ActorTypeEQ(actorType))
+	}
+
+	if filter.Actor != "" {
+		preds = append(preds, auditlog.ActorEQ(filter.Actor))
+	}
+
+	if filter.Action != "" {
+		action := strings.TrimSuffix(filter.Action, ".")
+		preds = append(preds, auditlog.Or(auditlog.ActionEQ(action), auditlog.ActionHasPrefix(action+".")))
+	}
+
+	if !filter.Since.IsZero() {
+		preds = append(preds, auditlog.CreatedAtGTE(filter.Since))
+	}
+
+	if !filter.Until.IsZero() {
+		preds = append(preds, auditlog.CreatedAtLTE(filter.Until))
+	}
+
+	query := c.Ent.AuditLog.Query().
+		Where(preds...).
+		Order(ent.Desc(auditlog.FieldCreatedAt), ent.Desc(auditlog.FieldID))
+
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	records, err := query.All(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("querying audit log: %w", err)
+	}
+
+	return records, nil
+}
+
+// flushAuditLog deletes the records older than maxAge.
+func (c *Client) flushAuditLog(ctx context.Context, maxAge time.Duration) {
+	if maxAge == 0 {
+		maxAge = defaultAuditMaxAge
+	}
+
+	c.Log.Debugf("flushing audit log older than %s", maxAge)
+
+	deleted, err := c.Ent.AuditLog.Delete().Where(
+		auditlog.CreatedAtLTE(time.Now().UTC().Add(-maxAge)),
+	).Exec(ctx)
+	if err != nil {
+		c.Log.Errorf("while flushing audit log: %s", err)
+		return
+	}
+
+	if deleted > 0 {
+		c.Log.Debugf("flushed %d audit log records", deleted)
+	}
+}
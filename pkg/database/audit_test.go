@@ -0,0 +1,122 @@
+package database
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/crowdsecurity/go-cs-lib/cstest"
+
+	"github.com/crowdsecurity/crowdsec/pkg/models"
+	"github.com/crowdsecurity/crowdsec/pkg/types"
+)
+
+func TestAuditLog(t *testing.T) {
+	ctx := t.Context()
+	dbClient := getDBClient(t, ctx)
+
+	machineID := "mymachine"
+	password := strfmt.Password("password")
+
+	// no actor, not recorded
+	_, err := dbClient.CreateMachine(ctx, &machineID, &password, "", false, false, types.PasswordAuthType)
+	require.NoError(t, err)
+
+	cscliCtx := WithAuditActor(ctx, AuditActor{Type: AuditActorCscli, Name: "root@lapi"})
+
+	require.NoError(t, dbClient.ValidateMachine(cscliCtx, machineID))
+
+	allowlist, err := dbClient.CreateAllowList(cscliCtx, "mylist", "", "", false)
+	require.NoError(t, err)
+
+	_, err = dbClient.AddToAllowlist(cscliCtx, allowlist, []*models.AllowlistItem{{Value: "192.0.2.1"}, {Value: "192.0.2.0/24"}})
+	require.NoError(t, err)
+
+	machineCtx := WithAuditActor(ctx, AuditActor{Type: AuditActorMachine, Name: machineID, IP: "127.0.0.1"})
+
+	count, _, err := dbClient.ExpireDecisionsWithFilter(machineCtx, map[string][]string{"scenario": {"crowdsecurity/ssh-bf"}, "ip": {"192.0.2.1"}})
+	require.NoError(t, err)
+	assert.Zero(t, count)
+
+	// a failed change is not recorded
+	_, _, err = dbClient.ExpireDecisionsWithFilter(machineCtx, map[string][]string{"foo": {"bar"}})
+	require.Error(t, err)
+
+	records, err := dbClient.QueryAuditLog(ctx, AuditLogFilter{})
+	require.NoError(t, err)
+	require.Len(t, records, 4)
+
+	// most recent first
+	assert.Equal(t, AuditDecisionsDelete, records[0].Action)
+	assert.Equal(t, AuditActorMachine, records[0].ActorType)
+	assert.Equal(t, machineID, records[0].Actor)
+	assert.Equal(t, "127.0.0.1", records[0].IPAddress)
+	assert.Equal(t, "ip=192.0.2.1&scenario=crowdsecurity%2Fssh-bf", records[0].Target)
+	assert.Equal(t, 0, records[0].Count)
+
+	assert.Equal(t, AuditAllowlistsAdd, records[1].Action)
+	assert.Equal(t, "mylist 192.0.2.1,192.0.2.0/24", records[1].Target)
+	assert.Equal(t, 2, records[1].Count)
+
+	assert.Equal(t, AuditAllowlistsCreate, records[2].Action)
+	assert.Equal(t, AuditMachinesValidate, records[3].Action)
+	assert.Equal(t, AuditActorCscli, records[3].ActorType)
+	assert.Equal(t, "root@lapi", records[3].Actor)
+	assert.Equal(t, machineID, records[3].Target)
+
+	records, err = dbClient.QueryAuditLog(ctx, AuditLogFilter{Action: "allowlists"})
+	require.NoError(t, err)
+	assert.Len(t, records, 2)
+
+	records, err = dbClient.QueryAuditLog(ctx, AuditLogFilter{Action: "allowlists.add"})
+	require.NoError(t, err)
+	assert.Len(t, records, 1)
+
+	records, err = dbClient.QueryAuditLog(ctx, AuditLogFilter{Action: "allow"})
+	require.NoError(t, err)
+	assert.Empty(t, records)
+
+	records, err = dbClient.QueryAuditLog(ctx, AuditLogFilter{ActorType: "machine", Actor: machineID})
+	require.NoError(t, err)
+	assert.Len(t, records, 1)
+
+	records, err = dbClient.QueryAuditLog(ctx, AuditLogFilter{ActorType: "cscli", Limit: 2})
+	require.NoError(t, err)
+	assert.Len(t, records, 2)
+
+	records, err = dbClient.QueryAuditLog(ctx, AuditLogFilter{Until: time.Now().UTC().Add(-time.Hour)})
+	require.NoError(t, err)
+	assert.Empty(t, records)
+
+	_, err = dbClient.QueryAuditLog(ctx, AuditLogFilter{ActorType: "root"})
+	cstest.RequireErrorContains(t, err, "invalid actor type 'root'")
+
+	// retention
+	dbClient.flushAuditLog(ctx, time.Hour)
+
+	records, err = dbClient.QueryAuditLog(ctx, AuditLogFilter{})
+	require.NoError(t, err)
+	assert.Len(t, records, 4)
+
+	time.Sleep(10 * time.Millisecond)
+	dbClient.flushAuditLog(ctx, time.Millisecond)
+
+	records, err = dbClient.QueryAuditLog(ctx, AuditLogFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, records)
+}
+
+func TestAuditList(t *testing.T) {
+	assert.Equal(t, "1,2", auditList([]string{"1", "2"}))
+
+	items := []string{}
+	for i := range 12 {
+		items = append(items, strconv.Itoa(i))
+	}
+
+	assert.Equal(t, "0,1,2,3,4,5,6,7,8,9,+2", auditList(items))
+}
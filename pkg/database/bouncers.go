@@ -96,6 +96,8 @@ func (c *Client) CreateBouncer(ctx context.Context, name string, ipAddr string,
 		return nil, fmt.Errorf("unable to create bouncer: %w", err)
 	}
 
+	c.audit(ctx, AuditBouncersCreate, name, 1)
+
 	return bouncer, nil
 }
 
@@ -112,13 +114,18 @@ func (c *Client) DeleteBouncer(ctx context.Context, name string) error {
 		return &BouncerNotFoundError{BouncerName: name}
 	}
 
+	c.audit(ctx, AuditBouncersDelete, name, nbDeleted)
+
 	return nil
 }
 
 func (c *Client) BulkDeleteBouncers(ctx context.Context, bouncers []*ent.Bouncer) (int, error) {
 	ids := make([]int, len(bouncers))
+	names := make([]string, len(bouncers))
+
 	for i, b := range bouncers {
 		ids[i] = b.ID
+		names[i] = b.Name
 	}
 
 	nbDeleted, err := c.Ent.Bouncer.Delete().Where(bouncer.IDIn(ids...)).Exec(ctx)
@@ -126,6 +133,8 @@ func (c *Client) BulkDeleteBouncers(ctx context.Context, bouncers []*ent.Bouncer
 		return nbDeleted, fmt.Errorf("unable to delete bouncers: %w", err)
 	}
 
+	c.audit(ctx, AuditBouncersDelete, strings.Join(names, ","), nbDeleted)
+
 	return nbDeleted, nil
 }
 
@@ -158,6 +167,26 @@ func (c *Client) UpdateBouncerTypeAndVersion(ctx context.Context, bType string,
 	return nil
 }
 
+// UpdateBouncerRestrictions sets the scopes, origins and scenarios of the decisions a bouncer can get. Empty means no restriction.
+func (c *Client) UpdateBouncerRestrictions(ctx context.Context, scopes []string, origins []string, scenarios []string, id int) error {
+	b, err := c.Ent.Bouncer.UpdateOneID(id).
+		SetAllowedScopes(scopes).
+		SetAllowedOrigins(origins).
+		SetAllowedScenarios(scenarios).
+		Save(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to update bouncer restrictions in database: %w", err)
+	}
+
+	c.audit(ctx, AuditBouncersRestrict, b.Name+" "+auditFilter(map[string][]string{
+		"scopes":    scopes,
+		"origins":   origins,
+		"scenarios": scenarios,
+	}), 1)
+
+	return nil
+}
+
 func (c *Client) QueryBouncersInactiveSince(ctx context.Context, t time.Time) ([]*ent.Bouncer, error) {
 	return c.Ent.Bouncer.Query().Where(
 		// poor man's coalesce
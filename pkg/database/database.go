@@ -24,6 +24,10 @@ type Client struct {
 	// imports hold the read lock; the flush job takes it exclusively.
 	flushGuard       sync.RWMutex
 	decisionBulkSize int
+	// decisionIndex answers the bouncer queries, if enabled
+	decisionIndex *DecisionIndex
+	// alertArchive receives the alerts before they are flushed, if enabled
+	alertArchive *AlertArchive
 }
 
 // PauseFlush pauses the alert flush job until the returned function is called.
@@ -117,12 +121,19 @@ func NewClient(ctx context.Context, config *csconfig.DatabaseCfg, logger *log.En
 		return nil, fmt.Errorf("failed creating schema resources: %w", err)
 	}
 
+	var alertArchive *AlertArchive
+
+	if config.Flush != nil && config.Flush.Archive != nil {
+		alertArchive = NewAlertArchive(config.Flush.Archive)
+	}
+
 	return &Client{
 		Ent:              client,
 		Log:              logger,
 		Type:             config.Type,
 		WalMode:          config.UseWal,
 		decisionBulkSize: config.DecisionBulkSize,
+		alertArchive:     alertArchive,
 	}, nil
 }
 
@@ -12,6 +12,37 @@ import (
 	"github.com/crowdsecurity/crowdsec/pkg/types"
 )
 
+// The restrict_* filters limit the decisions to those a bouncer is allowed to get.
+// Unlike the other filters, they have one value per item, and they are set by the LAPI,
+// not from the query string.
+const (
+	RestrictScopesFilter    = "restrict_scopes"
+	RestrictOriginsFilter   = "restrict_origins"
+	RestrictScenariosFilter = "restrict_scenarios"
+)
+
+// normalizeScopes returns the scopes with the case used in the database for the well-known ones.
+func normalizeScopes(scopes []string) []string {
+	ret := make([]string, len(scopes))
+
+	for i, scope := range scopes {
+		switch strings.ToLower(scope) {
+		case "ip":
+			ret[i] = types.Ip
+		case "range":
+			ret[i] = types.Range
+		case "country":
+			ret[i] = types.Country
+		case "as":
+			ret[i] = types.AS
+		default:
+			ret[i] = scope
+		}
+	}
+
+	return ret
+}
+
 func applyDecisionFilter(query *ent.DecisionQuery, filter map[string][]string) (*ent.DecisionQuery, error) {
 	var (
 		rng csnet.Range
@@ -41,21 +72,7 @@ func applyDecisionFilter(query *ent.DecisionQuery, filter map[string][]string) (
 				return nil, fmt.Errorf("invalid contains value: %w: %w", err, InvalidFilter)
 			}
 		case "scopes", "scope": // Swagger mentions both of them, let's just support both to make sure we don't break anything
-			scopes := strings.Split(value[0], ",")
-			for i, scope := range scopes {
-				switch strings.ToLower(scope) {
-				case "ip":
-					scopes[i] = types.Ip
-				case "range":
-					scopes[i] = types.Range
-				case "country":
-					scopes[i] = types.Country
-				case "as":
-					scopes[i] = types.AS
-				}
-			}
-
-			query = query.Where(decision.ScopeIn(scopes...))
+			query = query.Where(decision.ScopeIn(normalizeScopes(strings.Split(value[0], ","))...))
 		case "value":
 			query = query.Where(decision.ValueEQ(value[0]))
 		case "type":
@@ -74,6 +91,12 @@ func applyDecisionFilter(query *ent.DecisionQuery, filter map[string][]string) (
 					predicates...,
 				),
 			))
+		case RestrictScopesFilter:
+			query = query.Where(decision.ScopeIn(normalizeScopes(value)...))
+		case RestrictOriginsFilter:
+			query = query.Where(decision.OriginIn(value...))
+		case RestrictScenariosFilter:
+			query = query.Where(decision.ScenarioIn(value...))
 		case "ip", "range":
 			rng, err = csnet.NewRange(value[0])
 			if err != nil {
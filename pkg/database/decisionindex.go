@@ -0,0 +1,791 @@
+package database
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/crowdsecurity/go-cs-lib/ptr"
+
+	"github.com/crowdsecurity/crowdsec/pkg/csconfig"
+	"github.com/crowdsecurity/crowdsec/pkg/database/ent"
+	"github.com/crowdsecurity/crowdsec/pkg/database/ent/decision"
+	"github.com/crowdsecurity/crowdsec/pkg/database/ent/predicate"
+)
+
+const (
+	defaultDecisionIndexRefresh   = 5 * time.Second
+	defaultDecisionIndexRetention = time.Hour
+	// the changes are loaded from a bit before the last refresh, to get the transactions committed late
+	// and to allow for small clock differences between the LAPIs
+	decisionIndexSyncOverlap = 30 * time.Second
+)
+
+// indexedDecision is a decision of the index. The *ent.Decision is never modified once indexed,
+// it is replaced when the decision changes: the queries can return it without copy.
+type indexedDecision struct {
+	*ent.Decision
+	// prefix is the address or range of the decisions with an ip_size, invalid for the other ones
+	prefix netip.Prefix
+	// seenAt is when the index got the current state of the decision, zero if it was loaded on start.
+	// A decision made by another LAPI reaches the index after its creation (or expiration):
+	// the deltas sent to the bouncers must include it even if they have pulled in the meantime.
+	seenAt time.Time
+}
+
+// DecisionIndex holds the active and recently expired decisions, to answer the bouncers without
+// querying the database. It is updated by the changes made through the client, and refreshed with the
+// decisions changed since the last refresh, for the changes made by other LAPIs or cscli.
+type DecisionIndex struct {
+	mu sync.RWMutex
+	// ready is false until the index is loaded, and when it's out of sync: the queries go to the database
+	ready    bool
+	byID     map[int]*indexedDecision
+	byValue  map[string]map[int]*indexedDecision
+	byPrefix map[netip.Prefix]map[int]*indexedDecision
+	// number of decisions by prefix length, for IPv4 and IPv6, to look up only the lengths in use
+	prefixLens [2]map[int]int
+	// syncedAt is the start of the last refresh
+	syncedAt  time.Time
+	retention time.Duration
+	refresh   chan struct{}
+}
+
+func newDecisionIndex(retention time.Duration) *DecisionIndex {
+	ix := &DecisionIndex{
+		retention: retention,
+		refresh:   make(chan struct{}, 1),
+	}
+	ix.reset()
+
+	return ix
+}
+
+func (ix *DecisionIndex) reset() {
+	ix.byID = make(map[int]*indexedDecision)
+	ix.byValue = make(map[string]map[int]*indexedDecision)
+	ix.byPrefix = make(map[netip.Prefix]map[int]*indexedDecision)
+	ix.prefixLens = [2]map[int]int{make(map[int]int), make(map[int]int)}
+}
+
+func familyIdx(p netip.Prefix) int {
+	if p.Addr().Is4() {
+		return 0
+	}
+
+	return 1
+}
+
+// parseDecisionPrefix returns the address or range of an ip or range value, as stored in the database:
+// IPv4-mapped IPv6 addresses are IPv4 addresses.
+func parseDecisionPrefix(value string) (netip.Prefix, error) {
+	var (
+		p   netip.Prefix
+		err error
+	)
+
+	if strings.Contains(value, "/") {
+		p, err = netip.ParsePrefix(value)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+	} else {
+		addr, err := netip.ParseAddr(value)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+
+		p = netip.PrefixFrom(addr, addr.BitLen())
+	}
+
+	if p.Addr().Is4In6() {
+		bits := max(p.Bits()-96, 0)
+		p = netip.PrefixFrom(p.Addr().Unmap(), bits)
+	}
+
+	return p.Masked(), nil
+}
+
+// put adds or replaces a decision. The lock must be held.
+func (ix *DecisionIndex) put(d *ent.Decision, seenAt time.Time) {
+	if d.Until == nil {
+		return
+	}
+
+	ix.del(d.ID)
+
+	e := &indexedDecision{Decision: d, seenAt: seenAt}
+
+	if d.IPSize != 0 {
+		if p, err := parseDecisionPrefix(d.Value); err == nil {
+			e.prefix = p
+		}
+	}
+
+	ix.byID[d.ID] = e
+
+	if ix.byValue[d.Value] == nil {
+		ix.byValue[d.Value] = make(map[int]*indexedDecision)
+	}
+
+	ix.byValue[d.Value][d.ID] = e
+
+	if e.prefix.IsValid() {
+		if ix.byPrefix[e.prefix] == nil {
+			ix.byPrefix[e.prefix] = make(map[int]*indexedDecision)
+		}
+
+		ix.byPrefix[e.prefix][d.ID] = e
+		ix.prefixLens[familyIdx(e.prefix)][e.prefix.Bits()]++
+	}
+}
+
+// del removes a decision. The lock must be held.
+func (ix *DecisionIndex) del(id int) {
+	e, ok := ix.byID[id]
+	if !ok {
+		return
+	}
+
+	delete(ix.byID, id)
+
+	delete(ix.byValue[e.Value], id)
+
+	if len(ix.byValue[e.Value]) == 0 {
+		delete(ix.byValue, e.Value)
+	}
+
+	if e.prefix.IsValid() {
+		delete(ix.byPrefix[e.prefix], id)
+
+		if len(ix.byPrefix[e.prefix]) == 0 {
+			delete(ix.byPrefix, e.prefix)
+		}
+
+		lens := ix.prefixLens[familyIdx(e.prefix)]
+
+		lens[e.prefix.Bits()]--
+		if lens[e.prefix.Bits()] == 0 {
+			delete(lens, e.prefix.Bits())
+		}
+	}
+}
+
+// add indexes the decisions created through the client.
+func (ix *DecisionIndex) add(decisions []*ent.Decision) {
+	if ix == nil || len(decisions) == 0 {
+		return
+	}
+
+	now := time.Now().UTC()
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	for _, d := range decisions {
+		ix.put(d, now)
+	}
+}
+
+// expire sets the expiration of the decisions expired through the client.
+func (ix *DecisionIndex) expire(ids []int, until time.Time) {
+	if ix == nil || len(ids) == 0 {
+		return
+	}
+
+	now := time.Now().UTC()
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	for _, id := range ids {
+		e, ok := ix.byID[id]
+		if !ok {
+			continue
+		}
+
+		d := *e.Decision
+		d.Until = &until
+		ix.put(&d, now)
+	}
+}
+
+// remove drops the decisions deleted through the client.
+func (ix *DecisionIndex) remove(ids []int) {
+	if ix == nil || len(ids) == 0 {
+		return
+	}
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	for _, id := range ids {
+		ix.del(id)
+	}
+}
+
+// invalidate sends the queries to the database until the index is rebuilt,
+// when the changes made through the client are not known.
+func (ix *DecisionIndex) invalidate() {
+	if ix == nil {
+		return
+	}
+
+	ix.mu.Lock()
+	ix.ready = false
+	ix.mu.Unlock()
+
+	ix.poke()
+}
+
+// poke asks for a refresh, for the changes made through the client that are easier to get from the database.
+func (ix *DecisionIndex) poke() {
+	if ix == nil {
+		return
+	}
+
+	select {
+	case ix.refresh <- struct{}{}:
+	default:
+	}
+}
+
+// decisionMatcher is the in-memory version of applyDecisionFilter.
+type decisionMatcher struct {
+	simulated              bool
+	scopes                 []string
+	value                  *string
+	typ                    *string
+	origins                []string
+	scenariosContaining    []string
+	scenariosNotContaining []string
+	restrictScopes         []string
+	restrictOrigins        []string
+	restrictScenarios      []string
+	prefix                 netip.Prefix
+	contains               bool
+	limit                  int
+	offset                 int
+	idGT                   int
+}
+
+// newDecisionMatcher returns an error for the invalid filters, which are left to the database to report.
+func newDecisionMatcher(filter map[string][]string) (*decisionMatcher, error) {
+	var err error
+
+	m := &decisionMatcher{contains: true}
+
+	if v, ok := filter["simulated"]; ok {
+		m.simulated = v[0] != "false"
+	}
+
+	for param, value := range filter {
+		switch param {
+		case "contains":
+			m.contains, err = strconv.ParseBool(value[0])
+		case "scopes", "scope":
+			m.scopes = normalizeScopes(strings.Split(value[0], ","))
+		case "value":
+			m.value = &value[0]
+		case "type":
+			m.typ = &value[0]
+		case "origins":
+			m.origins = strings.Split(value[0], ",")
+		case "scenarios_containing":
+			m.scenariosContaining = strings.Split(strings.ToLower(value[0]), ",")
+		case "scenarios_not_containing":
+			m.scenariosNotContaining = strings.Split(strings.ToLower(value[0]), ",")
+		case RestrictScopesFilter:
+			m.restrictScopes = normalizeScopes(value)
+		case RestrictOriginsFilter:
+			m.restrictOrigins = value
+		case RestrictScenariosFilter:
+			m.restrictScenarios = value
+		case "ip", "range":
+			m.prefix, err = parseDecisionPrefix(value[0])
+		case "limit":
+			m.limit, err = strconv.Atoi(value[0])
+		case "offset":
+			m.offset, err = strconv.Atoi(value[0])
+		case "id_gt":
+			m.idGT, err = strconv.Atoi(value[0])
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value: %w", param, err)
+		}
+	}
+
+	return m, nil
+}
+
+func containsAny(s string, words []string) bool {
+	s = strings.ToLower(s)
+
+	for _, w := range words {
+		if strings.Contains(s, w) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m *decisionMatcher) match(e *indexedDecision) bool {
+	switch {
+	case e.Simulated && !m.simulated:
+		return false
+	case m.scopes != nil && !slices.Contains(m.scopes, e.Scope):
+		return false
+	case m.value != nil && e.Value != *m.value:
+		return false
+	case m.typ != nil && e.Type != *m.typ:
+		return false
+	case m.origins != nil && !slices.Contains(m.origins, e.Origin):
+		return false
+	case m.scenariosContaining != nil && !containsAny(e.Scenario, m.scenariosContaining):
+		return false
+	case m.scenariosNotContaining != nil && containsAny(e.Scenario, m.scenariosNotContaining):
+		return false
+	case m.restrictScopes != nil && !slices.Contains(m.restrictScopes, e.Scope):
+		return false
+	case m.restrictOrigins != nil && !slices.Contains(m.restrictOrigins, e.Origin):
+		return false
+	case m.restrictScenarios != nil && !slices.Contains(m.restrictScenarios, e.Scenario):
+		return false
+	}
+
+	if !m.prefix.IsValid() {
+		return true
+	}
+
+	if !e.prefix.IsValid() || e.prefix.Addr().Is4() != m.prefix.Addr().Is4() {
+		return false
+	}
+
+	if m.contains {
+		// the decision contains the address or range
+		return e.prefix.Bits() <= m.prefix.Bits() && e.prefix.Contains(m.prefix.Addr())
+	}
+
+	// the decision is contained in the range
+	return e.prefix.Bits() >= m.prefix.Bits() && m.prefix.Contains(e.prefix.Addr())
+}
+
+// candidates returns the decisions to match, using the value or the address when possible. The lock must be held.
+func (ix *DecisionIndex) candidates(m *decisionMatcher) []*indexedDecision {
+	var ret []*indexedDecision
+
+	switch {
+	case m.value != nil:
+		for _, e := range ix.byValue[*m.value] {
+			ret = append(ret, e)
+		}
+	case m.prefix.IsValid() && m.contains:
+		// only the decisions on the enclosing ranges, one lookup by prefix length in use
+		for bits := range ix.prefixLens[familyIdx(m.prefix)] {
+			if bits > m.prefix.Bits() {
+				continue
+			}
+
+			p := netip.PrefixFrom(m.prefix.Addr(), bits).Masked()
+			for _, e := range ix.byPrefix[p] {
+				ret = append(ret, e)
+			}
+		}
+	default:
+		ret = make([]*indexedDecision, 0, len(ix.byID))
+		for _, e := range ix.byID {
+			ret = append(ret, e)
+		}
+	}
+
+	return ret
+}
+
+// isLongest is the in-memory version of longestDecisionForScopeTypeValue. The lock must be held.
+func (ix *DecisionIndex) isLongest(e *indexedDecision) bool {
+	for _, other := range ix.byValue[e.Value] {
+		if other.Scope == e.Scope && other.Type == e.Type && other.Until.After(*e.Until) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// query returns the decisions matching the filter and keep, ordered by id.
+// It returns false if the database must be queried instead.
+func (ix *DecisionIndex) query(filter map[string][]string, keep func(e *indexedDecision) bool) ([]*ent.Decision, bool) {
+	if ix == nil {
+		return nil, false
+	}
+
+	m, err := newDecisionMatcher(filter)
+	if err != nil {
+		return nil, false
+	}
+
+	dedup := true
+	if v, ok := filter["dedup"]; ok && v[0] == "false" {
+		dedup = false
+	}
+
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	if !ix.ready {
+		return nil, false
+	}
+
+	matches := []*indexedDecision{}
+
+	for _, e := range ix.candidates(m) {
+		if e.ID <= m.idGT || !keep(e) || !m.match(e) {
+			continue
+		}
+
+		if dedup && !ix.isLongest(e) {
+			continue
+		}
+
+		matches = append(matches, e)
+	}
+
+	slices.SortFunc(matches, func(a, b *indexedDecision) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	matches = matches[min(m.offset, len(matches)):]
+
+	if m.limit > 0 && len(matches) > m.limit {
+		matches = matches[:m.limit]
+	}
+
+	ret := make([]*ent.Decision, len(matches))
+	for i, e := range matches {
+		ret[i] = e.Decision
+	}
+
+	return ret, true
+}
+
+// active answers QueryDecisionWithFilter. There is no deduplication for these ones.
+func (ix *DecisionIndex) active(now time.Time, filter map[string][]string) ([]*ent.Decision, bool) {
+	if ix == nil {
+		return nil, false
+	}
+
+	filter = cloneFilter(filter)
+	filter["dedup"] = []string{"false"}
+
+	return ix.query(filter, func(e *indexedDecision) bool {
+		return !e.Until.Before(now)
+	})
+}
+
+// newSince answers QueryNewDecisionsSinceWithFilters.
+func (ix *DecisionIndex) newSince(now time.Time, since *time.Time, filter map[string][]string) ([]*ent.Decision, bool) {
+	return ix.query(filter, func(e *indexedDecision) bool {
+		if !e.Until.After(now) {
+			return false
+		}
+
+		return since == nil || e.CreatedAt.After(*since) || e.seenAt.After(*since)
+	})
+}
+
+// expiredSince answers QueryExpiredDecisionsSinceWithFilters, if the decisions expired since then are still in the index.
+func (ix *DecisionIndex) expiredSince(now time.Time, since *time.Time, filter map[string][]string) ([]*ent.Decision, bool) {
+	if ix == nil || since == nil || since.Before(now.Add(-ix.retention)) {
+		return nil, false
+	}
+
+	return ix.query(filter, func(e *indexedDecision) bool {
+		if !e.Until.Before(now) {
+			return false
+		}
+
+		return e.Until.After(*since) || e.seenAt.After(*since)
+	})
+}
+
+// sumActive answers ActiveDecisionsVersion.
+func (ix *DecisionIndex) sumActive(now time.Time) (decisionSetSum, bool) {
+	if ix == nil {
+		return decisionSetSum{}, false
+	}
+
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	if !ix.ready {
+		return decisionSetSum{}, false
+	}
+
+	sum := decisionSetSum{}
+
+	for id, e := range ix.byID {
+		if !e.Until.After(now) {
+			continue
+		}
+
+		sum.Count++
+		sum.Max = max(sum.Max, int64(id))
+		sum.Sum += int64(id)
+	}
+
+	return sum, true
+}
+
+func cloneFilter(filter map[string][]string) map[string][]string {
+	ret := make(map[string][]string, len(filter)+1)
+	for k, v := range filter {
+		ret[k] = v
+	}
+
+	return ret
+}
+
+// the fields of the decisions kept in the index
+var decisionIndexFields = []string{
+	decision.FieldID,
+	decision.FieldCreatedAt,
+	decision.FieldUpdatedAt,
+	decision.FieldUntil,
+	decision.FieldScenario,
+	decision.FieldType,
+	decision.FieldStartIP,
+	decision.FieldEndIP,
+	decision.FieldIPSize,
+	decision.FieldScope,
+	decision.FieldValue,
+	decision.FieldOrigin,
+	decision.FieldSimulated,
+	decision.FieldUUID,
+}
+
+// StartDecisionIndex loads the decisions in memory, to answer the bouncers, and keeps them up to date until ctx is done.
+func (c *Client) StartDecisionIndex(ctx context.Context, config *csconfig.DecisionIndexCfg) error {
+	if config == nil || !ptr.OrEmpty(config.Enable) {
+		return nil
+	}
+
+	ix := newDecisionIndex(cmp.Or(config.ExpiredRetention, defaultDecisionIndexRetention))
+
+	if err := c.rebuildDecisionIndex(ctx, ix); err != nil {
+		return fmt.Errorf("loading decision index: %w", err)
+	}
+
+	c.decisionIndex = ix
+
+	go c.runDecisionIndex(ctx, ix, cmp.Or(config.RefreshInterval, defaultDecisionIndexRefresh))
+
+	return nil
+}
+
+func (c *Client) runDecisionIndex(ctx context.Context, ix *DecisionIndex, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-ix.refresh:
+		}
+
+		if err := c.refreshDecisionIndex(ctx, ix); err != nil {
+			c.Log.Warningf("refreshing decision index: %s", err)
+		}
+	}
+}
+
+// rebuildDecisionIndex loads the active and recently expired decisions.
+func (c *Client) rebuildDecisionIndex(ctx context.Context, ix *DecisionIndex) error {
+	start := time.Now().UTC()
+
+	decisions, err := c.Ent.Decision.Query().
+		Where(decision.UntilGT(start.Add(-ix.retention))).
+		Select(decisionIndexFields...).
+		All(ctx)
+	if err != nil {
+		return err
+	}
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	// on start, the bouncers know nothing of the index. Later, the decisions it had missed
+	// must be seen as new, for the deltas
+	initial := ix.syncedAt.IsZero()
+	previous := ix.byID
+
+	ix.reset()
+
+	for _, d := range decisions {
+		seenAt := time.Time{}
+
+		if !initial {
+			if old, ok := previous[d.ID]; ok && old.Until.Equal(*d.Until) {
+				seenAt = old.seenAt
+			} else {
+				seenAt = start
+			}
+		}
+
+		ix.put(d, seenAt)
+	}
+
+	ix.syncedAt = start
+	ix.ready = true
+
+	c.Log.Debugf("decision index: %d decisions loaded", len(decisions))
+
+	return nil
+}
+
+// refreshDecisionIndex loads the decisions changed since the last refresh and drops the ones expired for too long.
+// The deletions made by other LAPIs are not seen that way, nor the decisions with a date older than the
+// last refresh: if the ids of the index and the database don't add up, they are reconciled.
+func (c *Client) refreshDecisionIndex(ctx context.Context, ix *DecisionIndex) error {
+	ix.mu.RLock()
+	ready := ix.ready
+	since := ix.syncedAt.Add(-decisionIndexSyncOverlap)
+	ix.mu.RUnlock()
+
+	if !ready {
+		return c.rebuildDecisionIndex(ctx, ix)
+	}
+
+	start := time.Now().UTC()
+	cutoff := start.Add(-ix.retention)
+
+	changed, err := c.Ent.Decision.Query().
+		Where(decision.UpdatedAtGT(since), decision.UntilGT(cutoff)).
+		Select(decisionIndexFields...).
+		All(ctx)
+	if err != nil {
+		return err
+	}
+
+	// the decisions created from now on are left out, they can be missing from one side or the other
+	stored, err := sumDecisions(ctx, c.Ent.Decision.Query().Where(decision.UntilGT(cutoff), decision.CreatedAtLTE(start)))
+	if err != nil {
+		return err
+	}
+
+	ix.mu.Lock()
+
+	for _, d := range changed {
+		if old, ok := ix.byID[d.ID]; ok && old.Until.Equal(*d.Until) {
+			continue
+		}
+
+		ix.put(d, start)
+	}
+
+	indexed := decisionSetSum{}
+
+	for id, e := range ix.byID {
+		switch {
+		case !e.Until.After(cutoff):
+			ix.del(id)
+		case !e.CreatedAt.After(start):
+			indexed.Count++
+			indexed.Max = max(indexed.Max, int64(id))
+			indexed.Sum += int64(id)
+		}
+	}
+
+	ix.syncedAt = start
+
+	ix.mu.Unlock()
+
+	if indexed != stored {
+		c.Log.Debugf("decision index: %d decisions in the index, %d in the database, reconciling", indexed.Count, stored.Count)
+		return c.reconcileDecisionIndex(ctx, ix, start, cutoff)
+	}
+
+	return nil
+}
+
+// reconcileDecisionIndex drops the decisions deleted from the database, and loads the ones the index has missed.
+// Only their ids are queried, then the missing decisions.
+func (c *Client) reconcileDecisionIndex(ctx context.Context, ix *DecisionIndex, start time.Time, cutoff time.Time) error {
+	ids, err := c.Ent.Decision.Query().
+		Where(decision.UntilGT(cutoff), decision.CreatedAtLTE(start)).
+		IDs(ctx)
+	if err != nil {
+		return err
+	}
+
+	stored := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		stored[id] = true
+	}
+
+	ix.mu.Lock()
+
+	deleted := 0
+
+	for id, e := range ix.byID {
+		if !stored[id] && !e.CreatedAt.After(start) {
+			ix.del(id)
+
+			deleted++
+		}
+	}
+
+	missing := []int{}
+
+	for _, id := range ids {
+		if _, ok := ix.byID[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+
+	ix.mu.Unlock()
+
+	for chunk := range slices.Chunk(missing, decisionDeleteBulkSize) {
+		decisions, err := c.Ent.Decision.Query().
+			Where(decision.IDIn(chunk...)).
+			Select(decisionIndexFields...).
+			All(ctx)
+		if err != nil {
+			return err
+		}
+
+		// the bouncers have not seen them
+		ix.add(decisions)
+	}
+
+	c.Log.Debugf("decision index: %d decisions dropped, %d loaded", deleted, len(missing))
+
+	return nil
+}
+
+// decisionIDsForIndex returns the ids of the decisions about to be deleted, if the index is enabled:
+// the deletions don't return the deleted rows. If they can't be found, the index is rebuilt.
+func (c *Client) decisionIDsForIndex(ctx context.Context, client *ent.DecisionClient, preds ...predicate.Decision) []int {
+	if c.decisionIndex == nil {
+		return nil
+	}
+
+	ids, err := client.Query().Where(preds...).IDs(ctx)
+	if err != nil {
+		c.Log.Warningf("decision index: %s", err)
+		c.decisionIndex.invalidate()
+
+		return nil
+	}
+
+	return ids
+}
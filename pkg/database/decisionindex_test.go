@@ -0,0 +1,245 @@
+package database
+
+import (
+	"context"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/crowdsecurity/go-cs-lib/ptr"
+
+	"github.com/crowdsecurity/crowdsec/pkg/csconfig"
+	"github.com/crowdsecurity/crowdsec/pkg/database/ent/decision"
+	"github.com/crowdsecurity/crowdsec/pkg/models"
+)
+
+// getSharedDBClients returns two clients on the same database, like two LAPIs.
+// The first one has a decision index.
+func getSharedDBClients(t *testing.T, ctx context.Context) (*Client, *Client) {
+	t.Helper()
+
+	cfg := &csconfig.DatabaseCfg{
+		Type:   "sqlite",
+		DbName: "crowdsec",
+		DbPath: filepath.Join(t.TempDir(), "crowdsec.db"),
+	}
+
+	indexed, err := NewClient(ctx, cfg, nil)
+	require.NoError(t, err)
+
+	other, err := NewClient(ctx, cfg, nil)
+	require.NoError(t, err)
+
+	// refresh by hand in the tests
+	err = indexed.StartDecisionIndex(ctx, &csconfig.DecisionIndexCfg{
+		Enable:          ptr.Of(true),
+		RefreshInterval: time.Hour,
+	})
+	require.NoError(t, err)
+
+	return indexed, other
+}
+
+func makeIndexAlert(decisions ...*models.Decision) *models.Alert {
+	alert := makeFlushAlert("192.0.2.1", false)
+	alert.Decisions = decisions
+
+	return alert
+}
+
+func makeIndexDecision(scope string, value string, duration string, origin string) *models.Decision {
+	return &models.Decision{
+		Duration:  ptr.Of(duration),
+		Type:      ptr.Of("ban"),
+		Scope:     ptr.Of(scope),
+		Value:     ptr.Of(value),
+		Origin:    ptr.Of(origin),
+		Scenario:  ptr.Of("crowdsecurity/ssh-bf"),
+		Simulated: ptr.Of(false),
+	}
+}
+
+func TestDecisionIndexMatchesDatabase(t *testing.T) {
+	ctx := t.Context()
+	indexed, other := getSharedDBClients(t, ctx)
+
+	_, err := other.CreateAlert(ctx, "", []*models.Alert{makeIndexAlert(
+		makeIndexDecision("Ip", "192.0.2.1", "4h", "cscli"),
+		makeIndexDecision("Ip", "192.0.2.1", "1h", "crowdsec"),
+		makeIndexDecision("Ip", "192.0.2.1", "-10m", "crowdsec"),
+		makeIndexDecision("Range", "198.51.100.0/24", "4h", "crowdsec"),
+		makeIndexDecision("Range", "198.51.0.0/16", "4h", "lists"),
+		makeIndexDecision("Ip", "::ffff:198.51.100.9", "4h", "crowdsec"),
+		makeIndexDecision("Ip", "2001:db8::1", "4h", "crowdsec"),
+		makeIndexDecision("Range", "2001:db8::/32", "-5m", "lists"),
+		makeIndexDecision("Range", "2001:db8:1::/48", "4h", "lists"),
+		makeIndexDecision("Country", "FR", "4h", "cscli"),
+		makeIndexDecision("AS", "64496", "-20m", "cscli"),
+	)})
+	require.NoError(t, err)
+
+	require.NoError(t, indexed.refreshDecisionIndex(ctx, indexed.decisionIndex))
+
+	filters := []map[string][]string{
+		{},
+		{"ip": {"192.0.2.1"}},
+		{"ip": {"198.51.100.9"}},
+		{"ip": {"198.51.3.4"}},
+		{"ip": {"203.0.113.1"}},
+		{"ip": {"2001:db8:1::5"}},
+		{"ip": {"2001:db8::1"}},
+		{"range": {"198.51.100.0/28"}},
+		{"range": {"198.51.0.0/16"}, "contains": {"false"}},
+		{"range": {"2001:db8::/32"}, "contains": {"false"}},
+		{"scopes": {"ip,range"}},
+		{"scope": {"country"}, "value": {"FR"}},
+		{"value": {"192.0.2.1"}, "origins": {"crowdsec"}},
+		{"scenarios_containing": {"SSH"}},
+		{"scenarios_not_containing": {"ssh"}},
+		{"type": {"captcha"}},
+		{"dedup": {"false"}, "scopes": {"ip"}},
+		{"limit": {"2"}, "id_gt": {"1"}},
+		{RestrictOriginsFilter: {"lists", "cscli"}, RestrictScopesFilter: {"range"}},
+	}
+
+	now := time.Now().UTC()
+	since := now.Add(-30 * time.Minute)
+
+	for _, filter := range filters {
+		want, err := other.QueryDecisionWithFilter(ctx, cloneFilter(filter))
+		require.NoError(t, err)
+
+		got, ok := indexed.decisionIndex.active(now, cloneFilter(filter))
+		require.True(t, ok)
+		assert.ElementsMatch(t, decisionIDs(want), decisionIDs(got), "active %v", filter)
+
+		want, err = other.QueryNewDecisionsSinceWithFilters(ctx, now, &since, cloneFilter(filter))
+		require.NoError(t, err)
+
+		got, ok = indexed.decisionIndex.newSince(now, &since, cloneFilter(filter))
+		require.True(t, ok)
+		assert.Equal(t, decisionIDs(want), decisionIDs(got), "new %v", filter)
+
+		want, err = other.QueryExpiredDecisionsSinceWithFilters(ctx, now, &since, cloneFilter(filter))
+		require.NoError(t, err)
+
+		got, ok = indexed.decisionIndex.expiredSince(now, &since, cloneFilter(filter))
+		require.True(t, ok)
+		assert.Equal(t, decisionIDs(want), decisionIDs(got), "expired %v", filter)
+	}
+
+	// the version of the active decisions is the same, with or without the index
+	version, err := other.ActiveDecisionsVersion(ctx, now)
+	require.NoError(t, err)
+	assert.Regexp(t, `^8-\d+-\d+$`, version)
+
+	got, err := indexed.ActiveDecisionsVersion(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, version, got)
+
+	// too old for the index
+	longAgo := now.Add(-2 * time.Hour)
+	_, ok := indexed.decisionIndex.expiredSince(now, &longAgo, map[string][]string{})
+	assert.False(t, ok)
+
+	// invalid filters are left to the database
+	_, err = indexed.QueryDecisionWithFilter(ctx, map[string][]string{"ip": {"foo"}})
+	require.ErrorIs(t, err, InvalidIPOrRange)
+}
+
+func TestDecisionIndexUpdates(t *testing.T) {
+	ctx := t.Context()
+	indexed, other := getSharedDBClients(t, ctx)
+
+	query := map[string][]string{"ip": {"192.0.2.1"}}
+
+	// made through the indexed client: no refresh needed
+	_, err := indexed.CreateAlert(ctx, "", []*models.Alert{makeIndexAlert(makeIndexDecision("Ip", "192.0.2.1", "4h", "cscli"))})
+	require.NoError(t, err)
+
+	got, ok := indexed.decisionIndex.active(time.Now().UTC(), query)
+	require.True(t, ok)
+	require.Len(t, got, 1)
+
+	count, _, err := indexed.ExpireDecisionsWithFilter(ctx, map[string][]string{"ip": {"192.0.2.1"}})
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	got, _ = indexed.decisionIndex.active(time.Now().UTC(), query)
+	assert.Empty(t, got)
+
+	since := time.Now().UTC().Add(-time.Minute)
+	got, ok = indexed.decisionIndex.expiredSince(time.Now().UTC(), &since, query)
+	require.True(t, ok)
+	assert.Len(t, got, 1)
+
+	// the decisions are deleted by cascade
+	_, err = indexed.DeleteAlertWithFilter(ctx, map[string][]string{"origin": {"cscli"}})
+	require.NoError(t, err)
+
+	got, _ = indexed.decisionIndex.expiredSince(time.Now().UTC(), &since, query)
+	assert.Empty(t, got)
+
+	// made by another LAPI: seen on refresh, and sent in the deltas even to the bouncers which pulled in the meantime
+	ids, err := other.CreateAlert(ctx, "", []*models.Alert{makeIndexAlert(makeIndexDecision("Ip", "192.0.2.1", "4h", "cscli"))})
+	require.NoError(t, err)
+
+	lastPull := time.Now().UTC()
+
+	got, _ = indexed.decisionIndex.newSince(time.Now().UTC(), &lastPull, map[string][]string{})
+	assert.Empty(t, got)
+
+	require.NoError(t, indexed.refreshDecisionIndex(ctx, indexed.decisionIndex))
+
+	got, ok = indexed.decisionIndex.newSince(time.Now().UTC(), &lastPull, map[string][]string{})
+	require.True(t, ok)
+	assert.Len(t, got, 1)
+
+	// deleted by another LAPI: it is dropped from the index
+	alertID, err := strconv.Atoi(ids[0])
+	require.NoError(t, err)
+	require.NoError(t, other.DeleteAlertByID(ctx, alertID))
+
+	got, _ = indexed.decisionIndex.active(time.Now().UTC(), query)
+	assert.Len(t, got, 1)
+
+	require.NoError(t, indexed.refreshDecisionIndex(ctx, indexed.decisionIndex))
+
+	got, ok = indexed.decisionIndex.active(time.Now().UTC(), query)
+	require.True(t, ok)
+	assert.Empty(t, got)
+}
+
+func TestDecisionIndexReconcile(t *testing.T) {
+	ctx := t.Context()
+	indexed, other := getSharedDBClients(t, ctx)
+
+	ids, err := other.CreateAlert(ctx, "", []*models.Alert{makeIndexAlert(makeIndexDecision("Ip", "192.0.2.1", "4h", "cscli"))})
+	require.NoError(t, err)
+
+	require.NoError(t, indexed.refreshDecisionIndex(ctx, indexed.decisionIndex))
+
+	// another LAPI deletes a decision and adds one that is too old to be seen as a change:
+	// there are as many decisions as before
+	alertID, err := strconv.Atoi(ids[0])
+	require.NoError(t, err)
+	require.NoError(t, other.DeleteAlertByID(ctx, alertID))
+
+	_, err = other.CreateAlert(ctx, "", []*models.Alert{makeIndexAlert(makeIndexDecision("Ip", "192.0.2.2", "4h", "cscli"))})
+	require.NoError(t, err)
+
+	longAgo := time.Now().UTC().Add(-10 * time.Minute)
+	_, err = other.Ent.Decision.Update().Where(decision.ValueEQ("192.0.2.2")).SetUpdatedAt(longAgo).Save(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, indexed.refreshDecisionIndex(ctx, indexed.decisionIndex))
+
+	got, ok := indexed.decisionIndex.active(time.Now().UTC(), map[string][]string{})
+	require.True(t, ok)
+	require.Len(t, got, 1)
+	assert.Equal(t, "192.0.2.2", got[0].Value)
+}
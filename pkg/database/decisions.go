@@ -24,6 +24,51 @@ type DecisionsByScenario struct {
 	Type     string
 }
 
+// decisionSetSum identifies a set of decisions: it changes when one of them is added, removed or expires.
+type decisionSetSum struct {
+	Count int64
+	Max   int64
+	Sum   int64
+}
+
+func (s decisionSetSum) String() string {
+	return fmt.Sprintf("%d-%d-%d", s.Count, s.Max, s.Sum)
+}
+
+func sumDecisions(ctx context.Context, query *ent.DecisionQuery) (decisionSetSum, error) {
+	var rows []struct {
+		Count int64
+		Max   sql.NullInt64
+		Sum   sql.NullInt64
+	}
+
+	if err := query.Aggregate(ent.Count(), ent.Max(decision.FieldID), ent.Sum(decision.FieldID)).Scan(ctx, &rows); err != nil {
+		return decisionSetSum{}, err
+	}
+
+	if len(rows) == 0 {
+		return decisionSetSum{}, nil
+	}
+
+	return decisionSetSum{Count: rows[0].Count, Max: rows[0].Max.Int64, Sum: rows[0].Sum.Int64}, nil
+}
+
+// ActiveDecisionsVersion changes when the active decisions change, to tell if the result of
+// QueryAllDecisionsWithFilters can be different without running it.
+func (c *Client) ActiveDecisionsVersion(ctx context.Context, now time.Time) (string, error) {
+	if sum, ok := c.decisionIndex.sumActive(now); ok {
+		return sum.String(), nil
+	}
+
+	sum, err := sumDecisions(ctx, c.Ent.Decision.Query().Where(decision.UntilGT(now)))
+	if err != nil {
+		c.Log.Warningf("ActiveDecisionsVersion : %s", err)
+		return "", fmt.Errorf("get active decisions version: %w", QueryFail)
+	}
+
+	return sum.String(), nil
+}
+
 func (c *Client) QueryAllDecisionsWithFilters(ctx context.Context, now time.Time, filter map[string][]string) ([]*ent.Decision, error) {
 	// Do not select all fields.
 	// This can get pretty expensive network-wise if there are a lot of decisions and you are using a remote database
@@ -110,6 +155,10 @@ func (c *Client) QueryDecisionWithFilter(ctx context.Context, filter map[string]
 		data []*ent.Decision
 	)
 
+	if data, ok := c.decisionIndex.active(time.Now().UTC(), filter); ok {
+		return data, nil
+	}
+
 	query := c.Ent.Decision.Query().
 		Where(decision.UntilGTE(time.Now().UTC()))
 
@@ -166,6 +215,10 @@ func longestDecisionForScopeTypeValue(s *sql.Selector) {
 }
 
 func (c *Client) QueryExpiredDecisionsSinceWithFilters(ctx context.Context, now time.Time, since *time.Time, filter map[string][]string) ([]*ent.Decision, error) {
+	if data, ok := c.decisionIndex.expiredSince(now, since, filter); ok {
+		return data, nil
+	}
+
 	query := c.Ent.Decision.Query().
 		Select(decision.FieldID, decision.FieldUntil, decision.FieldScenario, decision.FieldScope, decision.FieldValue, decision.FieldType, decision.FieldOrigin, decision.FieldUUID).
 		Where(
@@ -199,6 +252,10 @@ func (c *Client) QueryExpiredDecisionsSinceWithFilters(ctx context.Context, now
 }
 
 func (c *Client) QueryNewDecisionsSinceWithFilters(ctx context.Context, now time.Time, since *time.Time, filter map[string][]string) ([]*ent.Decision, error) {
+	if data, ok := c.decisionIndex.newSince(now, since, filter); ok {
+		return data, nil
+	}
+
 	query := c.Ent.Decision.Query().
 		Select(decision.FieldID, decision.FieldUntil, decision.FieldScenario, decision.FieldScope, decision.FieldValue, decision.FieldType, decision.FieldOrigin, decision.FieldUUID).
 		Where(
@@ -294,6 +351,8 @@ func (c *Client) ExpireDecisionsWithFilter(ctx context.Context, filter map[strin
 		return 0, nil, fmt.Errorf("expire decisions with provided filter: %w: %w", err, DeleteFail)
 	}
 
+	c.audit(ctx, AuditDecisionsDelete, auditFilter(filter), count)
+
 	return count, decisionsToDelete, err
 }
 
@@ -319,6 +378,8 @@ func (c *Client) expireDecisionBatch(ctx context.Context, batch []*ent.Decision,
 		return 0, fmt.Errorf("expire decisions with provided filter: %w", err)
 	}
 
+	c.decisionIndex.expire(ids, now)
+
 	return rows, nil
 }
 
@@ -357,6 +418,8 @@ func (c *Client) deleteDecisionBatch(ctx context.Context, batch []*ent.Decision)
 		return 0, fmt.Errorf("hard delete decisions with provided filter: %w", err)
 	}
 
+	c.decisionIndex.remove(ids)
+
 	return rows, nil
 }
 
@@ -396,6 +459,9 @@ func (c *Client) ExpireDecisionByID(ctx context.Context, decisionID int) (int, [
 	}
 
 	count, err := c.ExpireDecisions(ctx, toUpdate)
+	if err == nil {
+		c.audit(ctx, AuditDecisionsDelete, "id="+strconv.Itoa(decisionID), count)
+	}
 
 	return count, toUpdate, err
 }
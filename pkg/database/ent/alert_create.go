@@ -368,6 +368,12 @@ func (_c *AlertCreate) SetNillableKind(v *string) *AlertCreate {
 	return _c
 }
 
+// SetID sets the "id" field.
+func (_c *AlertCreate) SetID(v int) *AlertCreate {
+	_c.mutation.SetID(v)
+	return _c
+}
+
 // SetOwnerID sets the "owner" edge to the Machine entity by ID.
 func (_c *AlertCreate) SetOwnerID(id int) *AlertCreate {
 	_c.mutation.SetOwnerID(id)
@@ -529,8 +535,10 @@ func (_c *AlertCreate) sqlSave(ctx context.Context) (*Alert, error) {
 		}
 		return nil, err
 	}
-	id := _spec.ID.Value.(int64)
-	_node.ID = int(id)
+	if _spec.ID.Value != _node.ID {
+		id := _spec.ID.Value.(int64)
+		_node.ID = int(id)
+	}
 	_c.mutation.id = &_node.ID
 	_c.mutation.done = true
 	return _node, nil
@@ -542,6 +550,10 @@ func (_c *AlertCreate) createSpec() (*Alert, *sqlgraph.CreateSpec) {
 		_spec = sqlgraph.NewCreateSpec(alert.Table, sqlgraph.NewFieldSpec(alert.FieldID, field.TypeInt))
 	)
 	_spec.OnConflict = _c.conflict
+	if id, ok := _c.mutation.ID(); ok {
+		_node.ID = id
+		_spec.ID.Value = id
+	}
 	if value, ok := _c.mutation.CreatedAt(); ok {
 		_spec.SetField(alert.FieldCreatedAt, field.TypeTime, value)
 		_node.CreatedAt = value
@@ -771,17 +783,23 @@ func (u *AlertUpsert) UpdateUpdatedAt() *AlertUpsert {
 	return u
 }
 
-// UpdateNewValues updates the mutable fields using the new values that were set on create.
+// UpdateNewValues updates the mutable fields using the new values that were set on create except the ID field.
 // Using this option is equivalent to using:
 //
 //	client.Alert.Create().
 //		OnConflict(
 //			sql.ResolveWithNewValues(),
+//			sql.ResolveWith(func(u *sql.UpdateSet) {
+//				u.SetIgnore(alert.FieldID)
+//			}),
 //		).
 //		Exec(ctx)
 func (u *AlertUpsertOne) UpdateNewValues() *AlertUpsertOne {
 	u.create.conflict = append(u.create.conflict, sql.ResolveWithNewValues())
 	u.create.conflict = append(u.create.conflict, sql.ResolveWith(func(s *sql.UpdateSet) {
+		if _, exists := u.create.mutation.ID(); exists {
+			s.SetIgnore(alert.FieldID)
+		}
 		if _, exists := u.create.mutation.CreatedAt(); exists {
 			s.SetIgnore(alert.FieldCreatedAt)
 		}
@@ -979,7 +997,7 @@ func (_c *AlertCreateBulk) Save(ctx context.Context) ([]*Alert, error) {
 					return nil, err
 				}
 				mutation.id = &nodes[i].ID
-				if specs[i].ID.Value != nil {
+				if specs[i].ID.Value != nil && nodes[i].ID == 0 {
 					id := specs[i].ID.Value.(int64)
 					nodes[i].ID = int(id)
 				}
@@ -1069,12 +1087,18 @@ type AlertUpsertBulk struct {
 //	client.Alert.Create().
 //		OnConflict(
 //			sql.ResolveWithNewValues(),
+//			sql.ResolveWith(func(u *sql.UpdateSet) {
+//				u.SetIgnore(alert.FieldID)
+//			}),
 //		).
 //		Exec(ctx)
 func (u *AlertUpsertBulk) UpdateNewValues() *AlertUpsertBulk {
 	u.create.conflict = append(u.create.conflict, sql.ResolveWithNewValues())
 	u.create.conflict = append(u.create.conflict, sql.ResolveWith(func(s *sql.UpdateSet) {
 		for _, b := range u.create.builders {
+			if _, exists := b.mutation.ID(); exists {
+				s.SetIgnore(alert.FieldID)
+			}
 			if _, exists := b.mutation.CreatedAt(); exists {
 				s.SetIgnore(alert.FieldCreatedAt)
 			}
@@ -155,6 +155,12 @@ func (_c *AllowListItemCreate) SetNillableIPSize(v *int64) *AllowListItemCreate
 	return _c
 }
 
+// SetID sets the "id" field.
+func (_c *AllowListItemCreate) SetID(v int) *AllowListItemCreate {
+	_c.mutation.SetID(v)
+	return _c
+}
+
 // AddAllowlistIDs adds the "allowlist" edge to the AllowList entity by IDs.
 func (_c *AllowListItemCreate) AddAllowlistIDs(ids ...int) *AllowListItemCreate {
 	_c.mutation.AddAllowlistIDs(ids...)
@@ -240,8 +246,10 @@ func (_c *AllowListItemCreate) sqlSave(ctx context.Context) (*AllowListItem, err
 		}
 		return nil, err
 	}
-	id := _spec.ID.Value.(int64)
-	_node.ID = int(id)
+	if _spec.ID.Value != _node.ID {
+		id := _spec.ID.Value.(int64)
+		_node.ID = int(id)
+	}
 	_c.mutation.id = &_node.ID
 	_c.mutation.done = true
 	return _node, nil
@@ -253,6 +261,10 @@ func (_c *AllowListItemCreate) createSpec() (*AllowListItem, *sqlgraph.CreateSpe
 		_spec = sqlgraph.NewCreateSpec(allowlistitem.Table, sqlgraph.NewFieldSpec(allowlistitem.FieldID, field.TypeInt))
 	)
 	_spec.OnConflict = _c.conflict
+	if id, ok := _c.mutation.ID(); ok {
+		_node.ID = id
+		_spec.ID.Value = id
+	}
 	if value, ok := _c.mutation.CreatedAt(); ok {
 		_spec.SetField(allowlistitem.FieldCreatedAt, field.TypeTime, value)
 		_node.CreatedAt = value
@@ -391,17 +403,23 @@ func (u *AllowListItemUpsert) ClearExpiresAt() *AllowListItemUpsert {
 	return u
 }
 
-// UpdateNewValues updates the mutable fields using the new values that were set on create.
+// UpdateNewValues updates the mutable fields using the new values that were set on create except the ID field.
 // Using this option is equivalent to using:
 //
 //	client.AllowListItem.Create().
 //		OnConflict(
 //			sql.ResolveWithNewValues(),
+//			sql.ResolveWith(func(u *sql.UpdateSet) {
+//				u.SetIgnore(allowlistitem.FieldID)
+//			}),
 //		).
 //		Exec(ctx)
 func (u *AllowListItemUpsertOne) UpdateNewValues() *AllowListItemUpsertOne {
 	u.create.conflict = append(u.create.conflict, sql.ResolveWithNewValues())
 	u.create.conflict = append(u.create.conflict, sql.ResolveWith(func(s *sql.UpdateSet) {
+		if _, exists := u.create.mutation.ID(); exists {
+			s.SetIgnore(allowlistitem.FieldID)
+		}
 		if _, exists := u.create.mutation.CreatedAt(); exists {
 			s.SetIgnore(allowlistitem.FieldCreatedAt)
 		}
@@ -572,7 +590,7 @@ func (_c *AllowListItemCreateBulk) Save(ctx context.Context) ([]*AllowListItem,
 					return nil, err
 				}
 				mutation.id = &nodes[i].ID
-				if specs[i].ID.Value != nil {
+				if specs[i].ID.Value != nil && nodes[i].ID == 0 {
 					id := specs[i].ID.Value.(int64)
 					nodes[i].ID = int(id)
 				}
@@ -662,12 +680,18 @@ type AllowListItemUpsertBulk struct {
 //	client.AllowListItem.Create().
 //		OnConflict(
 //			sql.ResolveWithNewValues(),
+//			sql.ResolveWith(func(u *sql.UpdateSet) {
+//				u.SetIgnore(allowlistitem.FieldID)
+//			}),
 //		).
 //		Exec(ctx)
 func (u *AllowListItemUpsertBulk) UpdateNewValues() *AllowListItemUpsertBulk {
 	u.create.conflict = append(u.create.conflict, sql.ResolveWithNewValues())
 	u.create.conflict = append(u.create.conflict, sql.ResolveWith(func(s *sql.UpdateSet) {
 		for _, b := range u.create.builders {
+			if _, exists := b.mutation.ID(); exists {
+				s.SetIgnore(allowlistitem.FieldID)
+			}
 			if _, exists := b.mutation.CreatedAt(); exists {
 				s.SetIgnore(allowlistitem.FieldCreatedAt)
 			}
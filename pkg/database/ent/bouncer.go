@@ -3,6 +3,7 @@
 package ent
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
@@ -46,8 +47,14 @@ type Bouncer struct {
 	// Featureflags holds the value of the "featureflags" field.
 	Featureflags string `json:"featureflags,omitempty"`
 	// AutoCreated holds the value of the "auto_created" field.
-	AutoCreated  bool `json:"auto_created"`
-	selectValues sql.SelectValues
+	AutoCreated bool `json:"auto_created"`
+	// AllowedScopes holds the value of the "allowed_scopes" field.
+	AllowedScopes []string `json:"allowed_scopes,omitempty"`
+	// AllowedOrigins holds the value of the "allowed_origins" field.
+	AllowedOrigins []string `json:"allowed_origins,omitempty"`
+	// AllowedScenarios holds the value of the "allowed_scenarios" field.
+	AllowedScenarios []string `json:"allowed_scenarios,omitempty"`
+	selectValues     sql.SelectValues
 }
 
 // scanValues returns the types for scanning values from sql.Rows.
@@ -55,6 +62,8 @@ func (*Bouncer) scanValues(columns []string) ([]any, error) {
 	values := make([]any, len(columns))
 	for i := range columns {
 		switch columns[i] {
+		case bouncer.FieldAllowedScopes, bouncer.FieldAllowedOrigins, bouncer.FieldAllowedScenarios:
+			values[i] = new([]byte)
 		case bouncer.FieldRevoked, bouncer.FieldAutoCreated:
 			values[i] = new(sql.NullBool)
 		case bouncer.FieldID:
@@ -175,6 +184,30 @@ func (_m *Bouncer) assignValues(columns []string, values []any) error {
 			} else if value.Valid {
 				_m.AutoCreated = value.Bool
 			}
+		case bouncer.FieldAllowedScopes:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field allowed_scopes", values[i])
+			} else if value != nil && len(*value) > 0 {
+				if err := json.Unmarshal(*value, &_m.AllowedScopes); err != nil {
+					return fmt.Errorf("unmarshal field allowed_scopes: %w", err)
+				}
+			}
+		case bouncer.FieldAllowedOrigins:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field allowed_origins", values[i])
+			} else if value != nil && len(*value) > 0 {
+				if err := json.Unmarshal(*value, &_m.AllowedOrigins); err != nil {
+					return fmt.Errorf("unmarshal field allowed_origins: %w", err)
+				}
+			}
+		case bouncer.FieldAllowedScenarios:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field allowed_scenarios", values[i])
+			} else if value != nil && len(*value) > 0 {
+				if err := json.Unmarshal(*value, &_m.AllowedScenarios); err != nil {
+					return fmt.Errorf("unmarshal field allowed_scenarios: %w", err)
+				}
+			}
 		default:
 			_m.selectValues.Set(columns[i], values[i])
 		}
@@ -256,6 +289,15 @@ func (_m *Bouncer) String() string {
 	builder.WriteString(", ")
 	builder.WriteString("auto_created=")
 	builder.WriteString(fmt.Sprintf("%v", _m.AutoCreated))
+	builder.WriteString(", ")
+	builder.WriteString("allowed_scopes=")
+	builder.WriteString(fmt.Sprintf("%v", _m.AllowedScopes))
+	builder.WriteString(", ")
+	builder.WriteString("allowed_origins=")
+	builder.WriteString(fmt.Sprintf("%v", _m.AllowedOrigins))
+	builder.WriteString(", ")
+	builder.WriteString("allowed_scenarios=")
+	builder.WriteString(fmt.Sprintf("%v", _m.AllowedScenarios))
 	builder.WriteByte(')')
 	return builder.String()
 }
@@ -43,6 +43,12 @@ const (
 	FieldFeatureflags = "featureflags"
 	// FieldAutoCreated holds the string denoting the auto_created field in the database.
 	FieldAutoCreated = "auto_created"
+	// FieldAllowedScopes holds the string denoting the allowed_scopes field in the database.
+	FieldAllowedScopes = "allowed_scopes"
+	// FieldAllowedOrigins holds the string denoting the allowed_origins field in the database.
+	FieldAllowedOrigins = "allowed_origins"
+	// FieldAllowedScenarios holds the string denoting the allowed_scenarios field in the database.
+	FieldAllowedScenarios = "allowed_scenarios"
 	// Table holds the table name of the bouncer in the database.
 	Table = "bouncers"
 )
@@ -65,6 +71,9 @@ var Columns = []string{
 	FieldOsversion,
 	FieldFeatureflags,
 	FieldAutoCreated,
+	FieldAllowedScopes,
+	FieldAllowedOrigins,
+	FieldAllowedScenarios,
 }
 
 // ValidColumn reports if the column name is valid (part of the table columns).
@@ -999,6 +999,36 @@ func AutoCreatedNEQ(v bool) predicate.Bouncer {
 	return predicate.Bouncer(sql.FieldNEQ(FieldAutoCreated, v))
 }
 
+// AllowedScopesIsNil applies the IsNil predicate on the "allowed_scopes" field.
+func AllowedScopesIsNil() predicate.Bouncer {
+	return predicate.Bouncer(sql.FieldIsNull(FieldAllowedScopes))
+}
+
+// AllowedScopesNotNil applies the NotNil predicate on the "allowed_scopes" field.
+func AllowedScopesNotNil() predicate.Bouncer {
+	return predicate.Bouncer(sql.FieldNotNull(FieldAllowedScopes))
+}
+
+// AllowedOriginsIsNil applies the IsNil predicate on the "allowed_origins" field.
+func AllowedOriginsIsNil() predicate.Bouncer {
+	return predicate.Bouncer(sql.FieldIsNull(FieldAllowedOrigins))
+}
+
+// AllowedOriginsNotNil applies the NotNil predicate on the "allowed_origins" field.
+func AllowedOriginsNotNil() predicate.Bouncer {
+	return predicate.Bouncer(sql.FieldNotNull(FieldAllowedOrigins))
+}
+
+// AllowedScenariosIsNil applies the IsNil predicate on the "allowed_scenarios" field.
+func AllowedScenariosIsNil() predicate.Bouncer {
+	return predicate.Bouncer(sql.FieldIsNull(FieldAllowedScenarios))
+}
+
+// AllowedScenariosNotNil applies the NotNil predicate on the "allowed_scenarios" field.
+func AllowedScenariosNotNil() predicate.Bouncer {
+	return predicate.Bouncer(sql.FieldNotNull(FieldAllowedScenarios))
+}
+
 // And groups predicates with the AND operator between them.
 func And(predicates ...predicate.Bouncer) predicate.Bouncer {
 	return predicate.Bouncer(sql.AndPredicates(predicates...))
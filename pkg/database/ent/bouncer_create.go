@@ -208,6 +208,30 @@ func (_c *BouncerCreate) SetNillableAutoCreated(v *bool) *BouncerCreate {
 	return _c
 }
 
+// SetAllowedScopes sets the "allowed_scopes" field.
+func (_c *BouncerCreate) SetAllowedScopes(v []string) *BouncerCreate {
+	_c.mutation.SetAllowedScopes(v)
+	return _c
+}
+
+// SetAllowedOrigins sets the "allowed_origins" field.
+func (_c *BouncerCreate) SetAllowedOrigins(v []string) *BouncerCreate {
+	_c.mutation.SetAllowedOrigins(v)
+	return _c
+}
+
+// SetAllowedScenarios sets the "allowed_scenarios" field.
+func (_c *BouncerCreate) SetAllowedScenarios(v []string) *BouncerCreate {
+	_c.mutation.SetAllowedScenarios(v)
+	return _c
+}
+
+// SetID sets the "id" field.
+func (_c *BouncerCreate) SetID(v int) *BouncerCreate {
+	_c.mutation.SetID(v)
+	return _c
+}
+
 // Mutation returns the BouncerMutation object of the builder.
 func (_c *BouncerCreate) Mutation() *BouncerMutation {
 	return _c.mutation
@@ -302,8 +326,10 @@ func (_c *BouncerCreate) sqlSave(ctx context.Context) (*Bouncer, error) {
 		}
 		return nil, err
 	}
-	id := _spec.ID.Value.(int64)
-	_node.ID = int(id)
+	if _spec.ID.Value != _node.ID {
+		id := _spec.ID.Value.(int64)
+		_node.ID = int(id)
+	}
 	_c.mutation.id = &_node.ID
 	_c.mutation.done = true
 	return _node, nil
@@ -315,6 +341,10 @@ func (_c *BouncerCreate) createSpec() (*Bouncer, *sqlgraph.CreateSpec) {
 		_spec = sqlgraph.NewCreateSpec(bouncer.Table, sqlgraph.NewFieldSpec(bouncer.FieldID, field.TypeInt))
 	)
 	_spec.OnConflict = _c.conflict
+	if id, ok := _c.mutation.ID(); ok {
+		_node.ID = id
+		_spec.ID.Value = id
+	}
 	if value, ok := _c.mutation.CreatedAt(); ok {
 		_spec.SetField(bouncer.FieldCreatedAt, field.TypeTime, value)
 		_node.CreatedAt = value
@@ -375,6 +405,18 @@ func (_c *BouncerCreate) createSpec() (*Bouncer, *sqlgraph.CreateSpec) {
 		_spec.SetField(bouncer.FieldAutoCreated, field.TypeBool, value)
 		_node.AutoCreated = value
 	}
+	if value, ok := _c.mutation.AllowedScopes(); ok {
+		_spec.SetField(bouncer.FieldAllowedScopes, field.TypeJSON, value)
+		_node.AllowedScopes = value
+	}
+	if value, ok := _c.mutation.AllowedOrigins(); ok {
+		_spec.SetField(bouncer.FieldAllowedOrigins, field.TypeJSON, value)
+		_node.AllowedOrigins = value
+	}
+	if value, ok := _c.mutation.AllowedScenarios(); ok {
+		_spec.SetField(bouncer.FieldAllowedScenarios, field.TypeJSON, value)
+		_node.AllowedScenarios = value
+	}
 	return _node, _spec
 }
 
@@ -619,17 +661,77 @@ func (u *BouncerUpsert) ClearFeatureflags() *BouncerUpsert {
 	return u
 }
 
-// UpdateNewValues updates the mutable fields using the new values that were set on create.
+// SetAllowedScopes sets the "allowed_scopes" field.
+func (u *BouncerUpsert) SetAllowedScopes(v []string) *BouncerUpsert {
+	u.Set(bouncer.FieldAllowedScopes, v)
+	return u
+}
+
+// UpdateAllowedScopes sets the "allowed_scopes" field to the value that was provided on create.
+func (u *BouncerUpsert) UpdateAllowedScopes() *BouncerUpsert {
+	u.SetExcluded(bouncer.FieldAllowedScopes)
+	return u
+}
+
+// ClearAllowedScopes clears the value of the "allowed_scopes" field.
+func (u *BouncerUpsert) ClearAllowedScopes() *BouncerUpsert {
+	u.SetNull(bouncer.FieldAllowedScopes)
+	return u
+}
+
+// SetAllowedOrigins sets the "allowed_origins" field.
+func (u *BouncerUpsert) SetAllowedOrigins(v []string) *BouncerUpsert {
+	u.Set(bouncer.FieldAllowedOrigins, v)
+	return u
+}
+
+// UpdateAllowedOrigins sets the "allowed_origins" field to the value that was provided on create.
+func (u *BouncerUpsert) UpdateAllowedOrigins() *BouncerUpsert {
+	u.SetExcluded(bouncer.FieldAllowedOrigins)
+	return u
+}
+
+// ClearAllowedOrigins clears the value of the "allowed_origins" field.
+func (u *BouncerUpsert) ClearAllowedOrigins() *BouncerUpsert {
+	u.SetNull(bouncer.FieldAllowedOrigins)
+	return u
+}
+
+// SetAllowedScenarios sets the "allowed_scenarios" field.
+func (u *BouncerUpsert) SetAllowedScenarios(v []string) *BouncerUpsert {
+	u.Set(bouncer.FieldAllowedScenarios, v)
+	return u
+}
+
+// UpdateAllowedScenarios sets the "allowed_scenarios" field to the value that was provided on create.
+func (u *BouncerUpsert) UpdateAllowedScenarios() *BouncerUpsert {
+	u.SetExcluded(bouncer.FieldAllowedScenarios)
+	return u
+}
+
+// ClearAllowedScenarios clears the value of the "allowed_scenarios" field.
+func (u *BouncerUpsert) ClearAllowedScenarios() *BouncerUpsert {
+	u.SetNull(bouncer.FieldAllowedScenarios)
+	return u
+}
+
+// UpdateNewValues updates the mutable fields using the new values that were set on create except the ID field.
 // Using this option is equivalent to using:
 //
 //	client.Bouncer.Create().
 //		OnConflict(
 //			sql.ResolveWithNewValues(),
+//			sql.ResolveWith(func(u *sql.UpdateSet) {
+//				u.SetIgnore(bouncer.FieldID)
+//			}),
 //		).
 //		Exec(ctx)
 func (u *BouncerUpsertOne) UpdateNewValues() *BouncerUpsertOne {
 	u.create.conflict = append(u.create.conflict, sql.ResolveWithNewValues())
 	u.create.conflict = append(u.create.conflict, sql.ResolveWith(func(s *sql.UpdateSet) {
+		if _, exists := u.create.mutation.ID(); exists {
+			s.SetIgnore(bouncer.FieldID)
+		}
 		if _, exists := u.create.mutation.CreatedAt(); exists {
 			s.SetIgnore(bouncer.FieldCreatedAt)
 		}
@@ -894,6 +996,69 @@ func (u *BouncerUpsertOne) ClearFeatureflags() *BouncerUpsertOne {
 	})
 }
 
+// SetAllowedScopes sets the "allowed_scopes" field.
+func (u *BouncerUpsertOne) SetAllowedScopes(v []string) *BouncerUpsertOne {
+	return u.Update(func(s *BouncerUpsert) {
+		s.SetAllowedScopes(v)
+	})
+}
+
+// UpdateAllowedScopes sets the "allowed_scopes" field to the value that was provided on create.
+func (u *BouncerUpsertOne) UpdateAllowedScopes() *BouncerUpsertOne {
+	return u.Update(func(s *BouncerUpsert) {
+		s.UpdateAllowedScopes()
+	})
+}
+
+// ClearAllowedScopes clears the value of the "allowed_scopes" field.
+func (u *BouncerUpsertOne) ClearAllowedScopes() *BouncerUpsertOne {
+	return u.Update(func(s *BouncerUpsert) {
+		s.ClearAllowedScopes()
+	})
+}
+
+// SetAllowedOrigins sets the "allowed_origins" field.
+func (u *BouncerUpsertOne) SetAllowedOrigins(v []string) *BouncerUpsertOne {
+	return u.Update(func(s *BouncerUpsert) {
+		s.SetAllowedOrigins(v)
+	})
+}
+
+// UpdateAllowedOrigins sets the "allowed_origins" field to the value that was provided on create.
+func (u *BouncerUpsertOne) UpdateAllowedOrigins() *BouncerUpsertOne {
+	return u.Update(func(s *BouncerUpsert) {
+		s.UpdateAllowedOrigins()
+	})
+}
+
+// ClearAllowedOrigins clears the value of the "allowed_origins" field.
+func (u *BouncerUpsertOne) ClearAllowedOrigins() *BouncerUpsertOne {
+	return u.Update(func(s *BouncerUpsert) {
+		s.ClearAllowedOrigins()
+	})
+}
+
+// SetAllowedScenarios sets the "allowed_scenarios" field.
+func (u *BouncerUpsertOne) SetAllowedScenarios(v []string) *BouncerUpsertOne {
+	return u.Update(func(s *BouncerUpsert) {
+		s.SetAllowedScenarios(v)
+	})
+}
+
+// UpdateAllowedScenarios sets the "allowed_scenarios" field to the value that was provided on create.
+func (u *BouncerUpsertOne) UpdateAllowedScenarios() *BouncerUpsertOne {
+	return u.Update(func(s *BouncerUpsert) {
+		s.UpdateAllowedScenarios()
+	})
+}
+
+// ClearAllowedScenarios clears the value of the "allowed_scenarios" field.
+func (u *BouncerUpsertOne) ClearAllowedScenarios() *BouncerUpsertOne {
+	return u.Update(func(s *BouncerUpsert) {
+		s.ClearAllowedScenarios()
+	})
+}
+
 // Exec executes the query.
 func (u *BouncerUpsertOne) Exec(ctx context.Context) error {
 	if len(u.create.conflict) == 0 {
@@ -974,7 +1139,7 @@ func (_c *BouncerCreateBulk) Save(ctx context.Context) ([]*Bouncer, error) {
 					return nil, err
 				}
 				mutation.id = &nodes[i].ID
-				if specs[i].ID.Value != nil {
+				if specs[i].ID.Value != nil && nodes[i].ID == 0 {
 					id := specs[i].ID.Value.(int64)
 					nodes[i].ID = int(id)
 				}
@@ -1064,12 +1229,18 @@ type BouncerUpsertBulk struct {
 //	client.Bouncer.Create().
 //		OnConflict(
 //			sql.ResolveWithNewValues(),
+//			sql.ResolveWith(func(u *sql.UpdateSet) {
+//				u.SetIgnore(bouncer.FieldID)
+//			}),
 //		).
 //		Exec(ctx)
 func (u *BouncerUpsertBulk) UpdateNewValues() *BouncerUpsertBulk {
 	u.create.conflict = append(u.create.conflict, sql.ResolveWithNewValues())
 	u.create.conflict = append(u.create.conflict, sql.ResolveWith(func(s *sql.UpdateSet) {
 		for _, b := range u.create.builders {
+			if _, exists := b.mutation.ID(); exists {
+				s.SetIgnore(bouncer.FieldID)
+			}
 			if _, exists := b.mutation.CreatedAt(); exists {
 				s.SetIgnore(bouncer.FieldCreatedAt)
 			}
@@ -1335,6 +1506,69 @@ func (u *BouncerUpsertBulk) ClearFeatureflags() *BouncerUpsertBulk {
 	})
 }
 
+// SetAllowedScopes sets the "allowed_scopes" field.
+func (u *BouncerUpsertBulk) SetAllowedScopes(v []string) *BouncerUpsertBulk {
+	return u.Update(func(s *BouncerUpsert) {
+		s.SetAllowedScopes(v)
+	})
+}
+
+// UpdateAllowedScopes sets the "allowed_scopes" field to the value that was provided on create.
+func (u *BouncerUpsertBulk) UpdateAllowedScopes() *BouncerUpsertBulk {
+	return u.Update(func(s *BouncerUpsert) {
+		s.UpdateAllowedScopes()
+	})
+}
+
+// ClearAllowedScopes clears the value of the "allowed_scopes" field.
+func (u *BouncerUpsertBulk) ClearAllowedScopes() *BouncerUpsertBulk {
+	return u.Update(func(s *BouncerUpsert) {
+		s.ClearAllowedScopes()
+	})
+}
+
+// SetAllowedOrigins sets the "allowed_origins" field.
+func (u *BouncerUpsertBulk) SetAllowedOrigins(v []string) *BouncerUpsertBulk {
+	return u.Update(func(s *BouncerUpsert) {
+		s.SetAllowedOrigins(v)
+	})
+}
+
+// UpdateAllowedOrigins sets the "allowed_origins" field to the value that was provided on create.
+func (u *BouncerUpsertBulk) UpdateAllowedOrigins() *BouncerUpsertBulk {
+	return u.Update(func(s *BouncerUpsert) {
+		s.UpdateAllowedOrigins()
+	})
+}
+
+// ClearAllowedOrigins clears the value of the "allowed_origins" field.
+func (u *BouncerUpsertBulk) ClearAllowedOrigins() *BouncerUpsertBulk {
+	return u.Update(func(s *BouncerUpsert) {
+		s.ClearAllowedOrigins()
+	})
+}
+
+// SetAllowedScenarios sets the "allowed_scenarios" field.
+func (u *BouncerUpsertBulk) SetAllowedScenarios(v []string) *BouncerUpsertBulk {
+	return u.Update(func(s *BouncerUpsert) {
+		s.SetAllowedScenarios(v)
+	})
+}
+
+// UpdateAllowedScenarios sets the "allowed_scenarios" field to the value that was provided on create.
+func (u *BouncerUpsertBulk) UpdateAllowedScenarios() *BouncerUpsertBulk {
+	return u.Update(func(s *BouncerUpsert) {
+		s.UpdateAllowedScenarios()
+	})
+}
+
+// ClearAllowedScenarios clears the value of the "allowed_scenarios" field.
+func (u *BouncerUpsertBulk) ClearAllowedScenarios() *BouncerUpsertBulk {
+	return u.Update(func(s *BouncerUpsert) {
+		s.ClearAllowedScenarios()
+	})
+}
+
 // Exec executes the query.
 func (u *BouncerUpsertBulk) Exec(ctx context.Context) error {
 	if u.create.err != nil {
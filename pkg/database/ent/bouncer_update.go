@@ -10,6 +10,7 @@ import (
 
 	"entgo.io/ent/dialect/sql"
 	"entgo.io/ent/dialect/sql/sqlgraph"
+	"entgo.io/ent/dialect/sql/sqljson"
 	"entgo.io/ent/schema/field"
 	"github.com/crowdsecurity/crowdsec/pkg/database/ent/bouncer"
 	"github.com/crowdsecurity/crowdsec/pkg/database/ent/predicate"
@@ -236,6 +237,60 @@ func (_u *BouncerUpdate) ClearFeatureflags() *BouncerUpdate {
 	return _u
 }
 
+// SetAllowedScopes sets the "allowed_scopes" field.
+func (_u *BouncerUpdate) SetAllowedScopes(v []string) *BouncerUpdate {
+	_u.mutation.SetAllowedScopes(v)
+	return _u
+}
+
+// AppendAllowedScopes appends value to the "allowed_scopes" field.
+func (_u *BouncerUpdate) AppendAllowedScopes(v []string) *BouncerUpdate {
+	_u.mutation.AppendAllowedScopes(v)
+	return _u
+}
+
+// ClearAllowedScopes clears the value of the "allowed_scopes" field.
+func (_u *BouncerUpdate) ClearAllowedScopes() *BouncerUpdate {
+	_u.mutation.ClearAllowedScopes()
+	return _u
+}
+
+// SetAllowedOrigins sets the "allowed_origins" field.
+func (_u *BouncerUpdate) SetAllowedOrigins(v []string) *BouncerUpdate {
+	_u.mutation.SetAllowedOrigins(v)
+	return _u
+}
+
+// AppendAllowedOrigins appends value to the "allowed_origins" field.
+func (_u *BouncerUpdate) AppendAllowedOrigins(v []string) *BouncerUpdate {
+	_u.mutation.AppendAllowedOrigins(v)
+	return _u
+}
+
+// ClearAllowedOrigins clears the value of the "allowed_origins" field.
+func (_u *BouncerUpdate) ClearAllowedOrigins() *BouncerUpdate {
+	_u.mutation.ClearAllowedOrigins()
+	return _u
+}
+
+// SetAllowedScenarios sets the "allowed_scenarios" field.
+func (_u *BouncerUpdate) SetAllowedScenarios(v []string) *BouncerUpdate {
+	_u.mutation.SetAllowedScenarios(v)
+	return _u
+}
+
+// AppendAllowedScenarios appends value to the "allowed_scenarios" field.
+func (_u *BouncerUpdate) AppendAllowedScenarios(v []string) *BouncerUpdate {
+	_u.mutation.AppendAllowedScenarios(v)
+	return _u
+}
+
+// ClearAllowedScenarios clears the value of the "allowed_scenarios" field.
+func (_u *BouncerUpdate) ClearAllowedScenarios() *BouncerUpdate {
+	_u.mutation.ClearAllowedScenarios()
+	return _u
+}
+
 // Mutation returns the BouncerMutation object of the builder.
 func (_u *BouncerUpdate) Mutation() *BouncerMutation {
 	return _u.mutation
@@ -346,6 +401,39 @@ func (_u *BouncerUpdate) sqlSave(ctx context.Context) (_node int, err error) {
 	if _u.mutation.FeatureflagsCleared() {
 		_spec.ClearField(bouncer.FieldFeatureflags, field.TypeString)
 	}
+	if value, ok := _u.mutation.AllowedScopes(); ok {
+		_spec.SetField(bouncer.FieldAllowedScopes, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AppendedAllowedScopes(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, bouncer.FieldAllowedScopes, value)
+		})
+	}
+	if _u.mutation.AllowedScopesCleared() {
+		_spec.ClearField(bouncer.FieldAllowedScopes, field.TypeJSON)
+	}
+	if value, ok := _u.mutation.AllowedOrigins(); ok {
+		_spec.SetField(bouncer.FieldAllowedOrigins, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AppendedAllowedOrigins(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, bouncer.FieldAllowedOrigins, value)
+		})
+	}
+	if _u.mutation.AllowedOriginsCleared() {
+		_spec.ClearField(bouncer.FieldAllowedOrigins, field.TypeJSON)
+	}
+	if value, ok := _u.mutation.AllowedScenarios(); ok {
+		_spec.SetField(bouncer.FieldAllowedScenarios, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AppendedAllowedScenarios(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, bouncer.FieldAllowedScenarios, value)
+		})
+	}
+	if _u.mutation.AllowedScenariosCleared() {
+		_spec.ClearField(bouncer.FieldAllowedScenarios, field.TypeJSON)
+	}
 	if _node, err = sqlgraph.UpdateNodes(ctx, _u.driver, _spec); err != nil {
 		if _, ok := err.(*sqlgraph.NotFoundError); ok {
 			err = &NotFoundError{bouncer.Label}
@@ -574,6 +662,60 @@ func (_u *BouncerUpdateOne) ClearFeatureflags() *BouncerUpdateOne {
 	return _u
 }
 
+// SetAllowedScopes sets the "allowed_scopes" field.
+func (_u *BouncerUpdateOne) SetAllowedScopes(v []string) *BouncerUpdateOne {
+	_u.mutation.SetAllowedScopes(v)
+	return _u
+}
+
+// AppendAllowedScopes appends value to the "allowed_scopes" field.
+func (_u *BouncerUpdateOne) AppendAllowedScopes(v []string) *BouncerUpdateOne {
+	_u.mutation.AppendAllowedScopes(v)
+	return _u
+}
+
+// ClearAllowedScopes clears the value of the "allowed_scopes" field.
+func (_u *BouncerUpdateOne) ClearAllowedScopes() *BouncerUpdateOne {
+	_u.mutation.ClearAllowedScopes()
+	return _u
+}
+
+// SetAllowedOrigins sets the "allowed_origins" field.
+func (_u *BouncerUpdateOne) SetAllowedOrigins(v []string) *BouncerUpdateOne {
+	_u.mutation.SetAllowedOrigins(v)
+	return _u
+}
+
+// AppendAllowedOrigins appends value to the "allowed_origins" field.
+func (_u *BouncerUpdateOne) AppendAllowedOrigins(v []string) *BouncerUpdateOne {
+	_u.mutation.AppendAllowedOrigins(v)
+	return _u
+}
+
+// ClearAllowedOrigins clears the value of the "allowed_origins" field.
+func (_u *BouncerUpdateOne) ClearAllowedOrigins() *BouncerUpdateOne {
+	_u.mutation.ClearAllowedOrigins()
+	return _u
+}
+
+// SetAllowedScenarios sets the "allowed_scenarios" field.
+func (_u *BouncerUpdateOne) SetAllowedScenarios(v []string) *BouncerUpdateOne {
+	_u.mutation.SetAllowedScenarios(v)
+	return _u
+}
+
+// AppendAllowedScenarios appends value to the "allowed_scenarios" field.
+func (_u *BouncerUpdateOne) AppendAllowedScenarios(v []string) *BouncerUpdateOne {
+	_u.mutation.AppendAllowedScenarios(v)
+	return _u
+}
+
+// ClearAllowedScenarios clears the value of the "allowed_scenarios" field.
+func (_u *BouncerUpdateOne) ClearAllowedScenarios() *BouncerUpdateOne {
+	_u.mutation.ClearAllowedScenarios()
+	return _u
+}
+
 // Mutation returns the BouncerMutation object of the builder.
 func (_u *BouncerUpdateOne) Mutation() *BouncerMutation {
 	return _u.mutation
@@ -714,6 +856,39 @@ func (_u *BouncerUpdateOne) sqlSave(ctx context.Context) (_node *Bouncer, err er
 	if _u.mutation.FeatureflagsCleared() {
 		_spec.ClearField(bouncer.FieldFeatureflags, field.TypeString)
 	}
+	if value, ok := _u.mutation.AllowedScopes(); ok {
+		_spec.SetField(bouncer.FieldAllowedScopes, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AppendedAllowedScopes(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, bouncer.FieldAllowedScopes, value)
+		})
+	}
+	if _u.mutation.AllowedScopesCleared() {
+		_spec.ClearField(bouncer.FieldAllowedScopes, field.TypeJSON)
+	}
+	if value, ok := _u.mutation.AllowedOrigins(); ok {
+		_spec.SetField(bouncer.FieldAllowedOrigins, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AppendedAllowedOrigins(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, bouncer.FieldAllowedOrigins, value)
+		})
+	}
+	if _u.mutation.AllowedOriginsCleared() {
+		_spec.ClearField(bouncer.FieldAllowedOrigins, field.TypeJSON)
+	}
+	if value, ok := _u.mutation.AllowedScenarios(); ok {
+		_spec.SetField(bouncer.FieldAllowedScenarios, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AppendedAllowedScenarios(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, bouncer.FieldAllowedScenarios, value)
+		})
+	}
+	if _u.mutation.AllowedScenariosCleared() {
+		_spec.ClearField(bouncer.FieldAllowedScenarios, field.TypeJSON)
+	}
 	_node = &Bouncer{config: _u.config}
 	_spec.Assign = _node.assignValues
 	_spec.ScanValues = _node.scanValues
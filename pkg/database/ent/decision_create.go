@@ -207,6 +207,12 @@ func (_c *DecisionCreate) SetNillableAlertDecisions(v *int) *DecisionCreate {
 	return _c
 }
 
+// SetID sets the "id" field.
+func (_c *DecisionCreate) SetID(v int) *DecisionCreate {
+	_c.mutation.SetID(v)
+	return _c
+}
+
 // SetOwnerID sets the "owner" edge to the Alert entity by ID.
 func (_c *DecisionCreate) SetOwnerID(id int) *DecisionCreate {
 	_c.mutation.SetOwnerID(id)
@@ -315,8 +321,10 @@ func (_c *DecisionCreate) sqlSave(ctx context.Context) (*Decision, error) {
 		}
 		return nil, err
 	}
-	id := _spec.ID.Value.(int64)
-	_node.ID = int(id)
+	if _spec.ID.Value != _node.ID {
+		id := _spec.ID.Value.(int64)
+		_node.ID = int(id)
+	}
 	_c.mutation.id = &_node.ID
 	_c.mutation.done = true
 	return _node, nil
@@ -328,6 +336,10 @@ func (_c *DecisionCreate) createSpec() (*Decision, *sqlgraph.CreateSpec) {
 		_spec = sqlgraph.NewCreateSpec(decision.Table, sqlgraph.NewFieldSpec(decision.FieldID, field.TypeInt))
 	)
 	_spec.OnConflict = _c.conflict
+	if id, ok := _c.mutation.ID(); ok {
+		_node.ID = id
+		_spec.ID.Value = id
+	}
 	if value, ok := _c.mutation.CreatedAt(); ok {
 		_spec.SetField(decision.FieldCreatedAt, field.TypeTime, value)
 		_node.CreatedAt = value
@@ -505,17 +517,23 @@ func (u *DecisionUpsert) ClearAlertDecisions() *DecisionUpsert {
 	return u
 }
 
-// UpdateNewValues updates the mutable fields using the new values that were set on create.
+// UpdateNewValues updates the mutable fields using the new values that were set on create except the ID field.
 // Using this option is equivalent to using:
 //
 //	client.Decision.Create().
 //		OnConflict(
 //			sql.ResolveWithNewValues(),
+//			sql.ResolveWith(func(u *sql.UpdateSet) {
+//				u.SetIgnore(decision.FieldID)
+//			}),
 //		).
 //		Exec(ctx)
 func (u *DecisionUpsertOne) UpdateNewValues() *DecisionUpsertOne {
 	u.create.conflict = append(u.create.conflict, sql.ResolveWithNewValues())
 	u.create.conflict = append(u.create.conflict, sql.ResolveWith(func(s *sql.UpdateSet) {
+		if _, exists := u.create.mutation.ID(); exists {
+			s.SetIgnore(decision.FieldID)
+		}
 		if _, exists := u.create.mutation.CreatedAt(); exists {
 			s.SetIgnore(decision.FieldCreatedAt)
 		}
@@ -722,7 +740,7 @@ func (_c *DecisionCreateBulk) Save(ctx context.Context) ([]*Decision, error) {
 					return nil, err
 				}
 				mutation.id = &nodes[i].ID
-				if specs[i].ID.Value != nil {
+				if specs[i].ID.Value != nil && nodes[i].ID == 0 {
 					id := specs[i].ID.Value.(int64)
 					nodes[i].ID = int(id)
 				}
@@ -812,12 +830,18 @@ type DecisionUpsertBulk struct {
 //	client.Decision.Create().
 //		OnConflict(
 //			sql.ResolveWithNewValues(),
+//			sql.ResolveWith(func(u *sql.UpdateSet) {
+//				u.SetIgnore(decision.FieldID)
+//			}),
 //		).
 //		Exec(ctx)
 func (u *DecisionUpsertBulk) UpdateNewValues() *DecisionUpsertBulk {
 	u.create.conflict = append(u.create.conflict, sql.ResolveWithNewValues())
 	u.create.conflict = append(u.create.conflict, sql.ResolveWith(func(s *sql.UpdateSet) {
 		for _, b := range u.create.builders {
+			if _, exists := b.mutation.ID(); exists {
+				s.SetIgnore(decision.FieldID)
+			}
 			if _, exists := b.mutation.CreatedAt(); exists {
 				s.SetIgnore(decision.FieldCreatedAt)
 			}
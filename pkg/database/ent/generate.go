@@ -1,4 +1,4 @@
 package ent
 
-//go:generate go run -mod=mod entgo.io/ent/cmd/ent@v0.14.6 generate ./schema --feature sql/upsert
+//go:generate go run -mod=mod entgo.io/ent/cmd/ent@v0.14.6 generate ./schema --feature sql/upsert,sql/execquery
 
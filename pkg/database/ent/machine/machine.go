@@ -48,6 +48,8 @@ const (
 	FieldHubstate = "hubstate"
 	// FieldDatasources holds the string denoting the datasources field in the database.
 	FieldDatasources = "datasources"
+	// FieldRoles holds the string denoting the roles field in the database.
+	FieldRoles = "roles"
 	// EdgeAlerts holds the string denoting the alerts edge name in mutations.
 	EdgeAlerts = "alerts"
 	// Table holds the table name of the machine in the database.
@@ -81,6 +83,7 @@ var Columns = []string{
 	FieldFeatureflags,
 	FieldHubstate,
 	FieldDatasources,
+	FieldRoles,
 }
 
 // ValidColumn reports if the column name is valid (part of the table columns).
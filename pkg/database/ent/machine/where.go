@@ -1050,6 +1050,16 @@ func DatasourcesNotNil() predicate.Machine {
 	return predicate.Machine(sql.FieldNotNull(FieldDatasources))
 }
 
+// RolesIsNil applies the IsNil predicate on the "roles" field.
+func RolesIsNil() predicate.Machine {
+	return predicate.Machine(sql.FieldIsNull(FieldRoles))
+}
+
+// RolesNotNil applies the NotNil predicate on the "roles" field.
+func RolesNotNil() predicate.Machine {
+	return predicate.Machine(sql.FieldNotNull(FieldRoles))
+}
+
 // HasAlerts applies the HasEdge predicate on the "alerts" edge.
 func HasAlerts() predicate.Machine {
 	return predicate.Machine(func(s *sql.Selector) {
@@ -222,6 +222,12 @@ func (_c *MachineCreate) SetDatasources(v map[string]int64) *MachineCreate {
 	return _c
 }
 
+// SetRoles sets the "roles" field.
+func (_c *MachineCreate) SetRoles(v []string) *MachineCreate {
+	_c.mutation.SetRoles(v)
+	return _c
+}
+
 // AddAlertIDs adds the "alerts" edge to the Alert entity by IDs.
 func (_c *MachineCreate) AddAlertIDs(ids ...int) *MachineCreate {
 	_c.mutation.AddAlertIDs(ids...)
@@ -417,6 +423,10 @@ func (_c *MachineCreate) createSpec() (*Machine, *sqlgraph.CreateSpec) {
 		_spec.SetField(machine.FieldDatasources, field.TypeJSON, value)
 		_node.Datasources = value
 	}
+	if value, ok := _c.mutation.Roles(); ok {
+		_spec.SetField(machine.FieldRoles, field.TypeJSON, value)
+		_node.Roles = value
+	}
 	if nodes := _c.mutation.AlertsIDs(); len(nodes) > 0 {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.O2M,
@@ -725,6 +735,24 @@ func (u *MachineUpsert) ClearDatasources() *MachineUpsert {
 	return u
 }
 
+// SetRoles sets the "roles" field.
+func (u *MachineUpsert) SetRoles(v []string) *MachineUpsert {
+	u.Set(machine.FieldRoles, v)
+	return u
+}
+
+// UpdateRoles sets the "roles" field to the value that was provided on create.
+func (u *MachineUpsert) UpdateRoles() *MachineUpsert {
+	u.SetExcluded(machine.FieldRoles)
+	return u
+}
+
+// ClearRoles clears the value of the "roles" field.
+func (u *MachineUpsert) ClearRoles() *MachineUpsert {
+	u.SetNull(machine.FieldRoles)
+	return u
+}
+
 // UpdateNewValues updates the mutable fields using the new values that were set on create.
 // Using this option is equivalent to using:
 //
@@ -1053,6 +1081,27 @@ func (u *MachineUpsertOne) ClearDatasources() *MachineUpsertOne {
 	})
 }
 
+// SetRoles sets the "roles" field.
+func (u *MachineUpsertOne) SetRoles(v []string) *MachineUpsertOne {
+	return u.Update(func(s *MachineUpsert) {
+		s.SetRoles(v)
+	})
+}
+
+// UpdateRoles sets the "roles" field to the value that was provided on create.
+func (u *MachineUpsertOne) UpdateRoles() *MachineUpsertOne {
+	return u.Update(func(s *MachineUpsert) {
+		s.UpdateRoles()
+	})
+}
+
+// ClearRoles clears the value of the "roles" field.
+func (u *MachineUpsertOne) ClearRoles() *MachineUpsertOne {
+	return u.Update(func(s *MachineUpsert) {
+		s.ClearRoles()
+	})
+}
+
 // Exec executes the query.
 func (u *MachineUpsertOne) Exec(ctx context.Context) error {
 	if len(u.create.conflict) == 0 {
@@ -1547,6 +1596,27 @@ func (u *MachineUpsertBulk) ClearDatasources() *MachineUpsertBulk {
 	})
 }
 
+// SetRoles sets the "roles" field.
+func (u *MachineUpsertBulk) SetRoles(v []string) *MachineUpsertBulk {
+	return u.Update(func(s *MachineUpsert) {
+		s.SetRoles(v)
+	})
+}
+
+// UpdateRoles sets the "roles" field to the value that was provided on create.
+func (u *MachineUpsertBulk) UpdateRoles() *MachineUpsertBulk {
+	return u.Update(func(s *MachineUpsert) {
+		s.UpdateRoles()
+	})
+}
+
+// ClearRoles clears the value of the "roles" field.
+func (u *MachineUpsertBulk) ClearRoles() *MachineUpsertBulk {
+	return u.Update(func(s *MachineUpsert) {
+		s.ClearRoles()
+	})
+}
+
 // Exec executes the query.
 func (u *MachineUpsertBulk) Exec(ctx context.Context) error {
 	if u.create.err != nil {
@@ -10,6 +10,7 @@ import (
 
 	"entgo.io/ent/dialect/sql"
 	"entgo.io/ent/dialect/sql/sqlgraph"
+	"entgo.io/ent/dialect/sql/sqljson"
 	"entgo.io/ent/schema/field"
 	"github.com/crowdsecurity/crowdsec/pkg/database/ent/alert"
 	"github.com/crowdsecurity/crowdsec/pkg/database/ent/machine"
@@ -276,6 +277,24 @@ func (_u *MachineUpdate) ClearDatasources() *MachineUpdate {
 	return _u
 }
 
+// SetRoles sets the "roles" field.
+func (_u *MachineUpdate) SetRoles(v []string) *MachineUpdate {
+	_u.mutation.SetRoles(v)
+	return _u
+}
+
+// AppendRoles appends value to the "roles" field.
+func (_u *MachineUpdate) AppendRoles(v []string) *MachineUpdate {
+	_u.mutation.AppendRoles(v)
+	return _u
+}
+
+// ClearRoles clears the value of the "roles" field.
+func (_u *MachineUpdate) ClearRoles() *MachineUpdate {
+	_u.mutation.ClearRoles()
+	return _u
+}
+
 // AddAlertIDs adds the "alerts" edge to the Alert entity by IDs.
 func (_u *MachineUpdate) AddAlertIDs(ids ...int) *MachineUpdate {
 	_u.mutation.AddAlertIDs(ids...)
@@ -450,6 +469,17 @@ func (_u *MachineUpdate) sqlSave(ctx context.Context) (_node int, err error) {
 	if _u.mutation.DatasourcesCleared() {
 		_spec.ClearField(machine.FieldDatasources, field.TypeJSON)
 	}
+	if value, ok := _u.mutation.Roles(); ok {
+		_spec.SetField(machine.FieldRoles, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AppendedRoles(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, machine.FieldRoles, value)
+		})
+	}
+	if _u.mutation.RolesCleared() {
+		_spec.ClearField(machine.FieldRoles, field.TypeJSON)
+	}
 	if _u.mutation.AlertsCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.O2M,
@@ -761,6 +791,24 @@ func (_u *MachineUpdateOne) ClearDatasources() *MachineUpdateOne {
 	return _u
 }
 
+// SetRoles sets the "roles" field.
+func (_u *MachineUpdateOne) SetRoles(v []string) *MachineUpdateOne {
+	_u.mutation.SetRoles(v)
+	return _u
+}
+
+// AppendRoles appends value to the "roles" field.
+func (_u *MachineUpdateOne) AppendRoles(v []string) *MachineUpdateOne {
+	_u.mutation.AppendRoles(v)
+	return _u
+}
+
+// ClearRoles clears the value of the "roles" field.
+func (_u *MachineUpdateOne) ClearRoles() *MachineUpdateOne {
+	_u.mutation.ClearRoles()
+	return _u
+}
+
 // AddAlertIDs adds the "alerts" edge to the Alert entity by IDs.
 func (_u *MachineUpdateOne) AddAlertIDs(ids ...int) *MachineUpdateOne {
 	_u.mutation.AddAlertIDs(ids...)
@@ -965,6 +1013,17 @@ func (_u *MachineUpdateOne) sqlSave(ctx context.Context) (_node *Machine, err er
 	if _u.mutation.DatasourcesCleared() {
 		_spec.ClearField(machine.FieldDatasources, field.TypeJSON)
 	}
+	if value, ok := _u.mutation.Roles(); ok {
+		_spec.SetField(machine.FieldRoles, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AppendedRoles(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, machine.FieldRoles, value)
+		})
+	}
+	if _u.mutation.RolesCleared() {
+		_spec.ClearField(machine.FieldRoles, field.TypeJSON)
+	}
 	if _u.mutation.AlertsCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.O2M,
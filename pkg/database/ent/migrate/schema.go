@@ -139,6 +139,40 @@ var (
 			},
 		},
 	}
+	// AuditLogsColumns holds the columns for the "audit_logs" table.
+	AuditLogsColumns = []*schema.Column{
+		{Name: "id", Type: field.TypeInt, Increment: true},
+		{Name: "created_at", Type: field.TypeTime},
+		{Name: "actor_type", Type: field.TypeEnum, Enums: []string{"machine", "bouncer", "cscli", "papi"}},
+		{Name: "actor", Type: field.TypeString},
+		{Name: "ip_address", Type: field.TypeString, Nullable: true, Default: ""},
+		{Name: "action", Type: field.TypeString},
+		{Name: "target", Type: field.TypeString, Nullable: true, Size: 2147483647, Default: ""},
+		{Name: "count", Type: field.TypeInt, Default: 0},
+	}
+	// AuditLogsTable holds the schema information for the "audit_logs" table.
+	AuditLogsTable = &schema.Table{
+		Name:       "audit_logs",
+		Columns:    AuditLogsColumns,
+		PrimaryKey: []*schema.Column{AuditLogsColumns[0]},
+		Indexes: []*schema.Index{
+			{
+				Name:    "auditlog_created_at",
+				Unique:  false,
+				Columns: []*schema.Column{AuditLogsColumns[1]},
+			},
+			{
+				Name:    "auditlog_actor_type_actor",
+				Unique:  false,
+				Columns: []*schema.Column{AuditLogsColumns[2], AuditLogsColumns[3]},
+			},
+			{
+				Name:    "auditlog_action",
+				Unique:  false,
+				Columns: []*schema.Column{AuditLogsColumns[5]},
+			},
+		},
+	}
 	// BouncersColumns holds the columns for the "bouncers" table.
 	BouncersColumns = []*schema.Column{
 		{Name: "id", Type: field.TypeInt, Increment: true},
@@ -157,6 +191,9 @@ var (
 		{Name: "osversion", Type: field.TypeString, Nullable: true},
 		{Name: "featureflags", Type: field.TypeString, Nullable: true},
 		{Name: "auto_created", Type: field.TypeBool, Default: false},
+		{Name: "allowed_scopes", Type: field.TypeJSON, Nullable: true},
+		{Name: "allowed_origins", Type: field.TypeJSON, Nullable: true},
+		{Name: "allowed_scenarios", Type: field.TypeJSON, Nullable: true},
 	}
 	// BouncersTable holds the schema information for the "bouncers" table.
 	BouncersTable = &schema.Table{
@@ -313,6 +350,7 @@ var (
 		{Name: "featureflags", Type: field.TypeString, Nullable: true},
 		{Name: "hubstate", Type: field.TypeJSON, Nullable: true},
 		{Name: "datasources", Type: field.TypeJSON, Nullable: true},
+		{Name: "roles", Type: field.TypeJSON, Nullable: true},
 	}
 	// MachinesTable holds the schema information for the "machines" table.
 	MachinesTable = &schema.Table{
@@ -407,6 +445,7 @@ var (
 		AlertsTable,
 		AllowListsTable,
 		AllowListItemsTable,
+		AuditLogsTable,
 		BouncersTable,
 		ConfigItemsTable,
 		DecisionsTable,
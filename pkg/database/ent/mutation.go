@@ -14,6 +14,7 @@ import (
 	"github.com/crowdsecurity/crowdsec/pkg/database/ent/alert"
 	"github.com/crowdsecurity/crowdsec/pkg/database/ent/allowlist"
 	"github.com/crowdsecurity/crowdsec/pkg/database/ent/allowlistitem"
+	"github.com/crowdsecurity/crowdsec/pkg/database/ent/auditlog"
 	"github.com/crowdsecurity/crowdsec/pkg/database/ent/bouncer"
 	"github.com/crowdsecurity/crowdsec/pkg/database/ent/configitem"
 	"github.com/crowdsecurity/crowdsec/pkg/database/ent/decision"
@@ -38,6 +39,7 @@ const (
 	TypeAlert         = "Alert"
 	TypeAllowList     = "AllowList"
 	TypeAllowListItem = "AllowListItem"
+	TypeAuditLog      = "AuditLog"
 	TypeBouncer       = "Bouncer"
 	TypeConfigItem    = "ConfigItem"
 	TypeDecision      = "Decision"
@@ -170,6 +172,12 @@ func (m AlertMutation) Tx() (*Tx, error) {
 	return tx, nil
 }
 
+// SetID sets the value of the id field. Note that this
+// operation is only accepted on creation of Alert entities.
+func (m *AlertMutation) SetID(id int) {
+	m.id = &id
+}
+
 // ID returns the ID value in the mutation. Note that the ID is only available
 // if it was provided to the builder or after it was returned from the database.
 func (m *AlertMutation) ID() (id int, exists bool) {
@@ -3359,6 +3367,12 @@ func (m AllowListItemMutation) Tx() (*Tx, error) {
 	return tx, nil
 }
 
+// SetID sets the value of the id field. Note that this
+// operation is only accepted on creation of AllowListItem entities.
+func (m *AllowListItemMutation) SetID(id int) {
+	m.id = &id
+}
+
 // ID returns the ID value in the mutation. Note that the ID is only available
 // if it was provided to the builder or after it was returned from the database.
 func (m *AllowListItemMutation) ID() (id int, exists bool) {
@@ -4344,160 +4358,893 @@ func (m *AllowListItemMutation) ClearField(name string) error {
 	case allowlistitem.FieldEndSuffix:
 		m.ClearEndSuffix()
 		return nil
-	case allowlistitem.FieldIPSize:
-		m.ClearIPSize()
+	case allowlistitem.FieldIPSize:
+		m.ClearIPSize()
+		return nil
+	}
+	return fmt.Errorf("unknown AllowListItem nullable field %s", name)
+}
+
+// ResetField resets all changes in the mutation for the field with the given name.
+// It returns an error if the field is not defined in the schema.
+func (m *AllowListItemMutation) ResetField(name string) error {
+	switch name {
+	case allowlistitem.FieldCreatedAt:
+		m.ResetCreatedAt()
+		return nil
+	case allowlistitem.FieldUpdatedAt:
+		m.ResetUpdatedAt()
+		return nil
+	case allowlistitem.FieldExpiresAt:
+		m.ResetExpiresAt()
+		return nil
+	case allowlistitem.FieldComment:
+		m.ResetComment()
+		return nil
+	case allowlistitem.FieldValue:
+		m.ResetValue()
+		return nil
+	case allowlistitem.FieldStartIP:
+		m.ResetStartIP()
+		return nil
+	case allowlistitem.FieldEndIP:
+		m.ResetEndIP()
+		return nil
+	case allowlistitem.FieldStartSuffix:
+		m.ResetStartSuffix()
+		return nil
+	case allowlistitem.FieldEndSuffix:
+		m.ResetEndSuffix()
+		return nil
+	case allowlistitem.FieldIPSize:
+		m.ResetIPSize()
+		return nil
+	}
+	return fmt.Errorf("unknown AllowListItem field %s", name)
+}
+
+// AddedEdges returns all edge names that were set/added in this mutation.
+func (m *AllowListItemMutation) AddedEdges() []string {
+	edges := make([]string, 0, 1)
+	if m.allowlist != nil {
+		edges = append(edges, allowlistitem.EdgeAllowlist)
+	}
+	return edges
+}
+
+// AddedIDs returns all IDs (to other nodes) that were added for the given edge
+// name in this mutation.
+func (m *AllowListItemMutation) AddedIDs(name string) []ent.Value {
+	switch name {
+	case allowlistitem.EdgeAllowlist:
+		ids := make([]ent.Value, 0, len(m.allowlist))
+		for id := range m.allowlist {
+			ids = append(ids, id)
+		}
+		return ids
+	}
+	return nil
+}
+
+// RemovedEdges returns all edge names that were removed in this mutation.
+func (m *AllowListItemMutation) RemovedEdges() []string {
+	edges := make([]string, 0, 1)
+	if m.removedallowlist != nil {
+		edges = append(edges, allowlistitem.EdgeAllowlist)
+	}
+	return edges
+}
+
+// RemovedIDs returns all IDs (to other nodes) that were removed for the edge with
+// the given name in this mutation.
+func (m *AllowListItemMutation) RemovedIDs(name string) []ent.Value {
+	switch name {
+	case allowlistitem.EdgeAllowlist:
+		ids := make([]ent.Value, 0, len(m.removedallowlist))
+		for id := range m.removedallowlist {
+			ids = append(ids, id)
+		}
+		return ids
+	}
+	return nil
+}
+
+// ClearedEdges returns all edge names that were cleared in this mutation.
+func (m *AllowListItemMutation) ClearedEdges() []string {
+	edges := make([]string, 0, 1)
+	if m.clearedallowlist {
+		edges = append(edges, allowlistitem.EdgeAllowlist)
+	}
+	return edges
+}
+
+// EdgeCleared returns a boolean which indicates if the edge with the given name
+// was cleared in this mutation.
+func (m *AllowListItemMutation) EdgeCleared(name string) bool {
+	switch name {
+	case allowlistitem.EdgeAllowlist:
+		return m.clearedallowlist
+	}
+	return false
+}
+
+// ClearEdge clears the value of the edge with the given name. It returns an error
+// if that edge is not defined in the schema.
+func (m *AllowListItemMutation) ClearEdge(name string) error {
+	switch name {
+	}
+	return fmt.Errorf("unknown AllowListItem unique edge %s", name)
+}
+
+// ResetEdge resets all changes to the edge with the given name in this mutation.
+// It returns an error if the edge is not defined in the schema.
+func (m *AllowListItemMutation) ResetEdge(name string) error {
+	switch name {
+	case allowlistitem.EdgeAllowlist:
+		m.ResetAllowlist()
+		return nil
+	}
+	return fmt.Errorf("unknown AllowListItem edge %s", name)
+}
+
+// AuditLogMutation represents an operation that mutates the AuditLog nodes in the graph.
+type AuditLogMutation struct {
+	config
+	op            Op
+	typ           string
+	id            *int
+	created_at    *time.Time
+	actor_type    *auditlog.ActorType
+	actor         *string
+	ip_address    *string
+	action        *string
+	target        *string
+	count         *int
+	addcount      *int
+	clearedFields map[string]struct{}
+	done          bool
+	oldValue      func(context.Context) (*AuditLog, error)
+	predicates    []predicate.AuditLog
+}
+
+var _ ent.Mutation = (*AuditLogMutation)(nil)
+
+// auditlogOption allows management of the mutation configuration using functional options.
+type auditlogOption func(*AuditLogMutation)
+
+// newAuditLogMutation creates new mutation for the AuditLog entity.
+func newAuditLogMutation(c config, op Op, opts ...auditlogOption) *AuditLogMutation {
+	m := &AuditLogMutation{
+		config:        c,
+		op:            op,
+		typ:           TypeAuditLog,
+		clearedFields: make(map[string]struct{}),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// withAuditLogID sets the ID field of the mutation.
+func withAuditLogID(id int) auditlogOption {
+	return func(m *AuditLogMutation) {
+		var (
+			err   error
+			once  sync.Once
+			value *AuditLog
+		)
+		m.oldValue = func(ctx context.Context) (*AuditLog, error) {
+			once.Do(func() {
+				if m.done {
+					err = errors.New("querying old values post mutation is not allowed")
+				} else {
+					value, err = m.Client().AuditLog.Get(ctx, id)
+				}
+			})
+			return value, err
+		}
+		m.id = &id
+	}
+}
+
+// withAuditLog sets the old AuditLog of the mutation.
+func withAuditLog(node *AuditLog) auditlogOption {
+	return func(m *AuditLogMutation) {
+		m.oldValue = func(context.Context) (*AuditLog, error) {
+			return node, nil
+		}
+		m.id = &node.ID
+	}
+}
+
+// Client returns a new `ent.Client` from the mutation. If the mutation was
+// executed in a transaction (ent.Tx), a transactional client is returned.
+func (m AuditLogMutation) Client() *Client {
+	client := &Client{config: m.config}
+	client.init()
+	return client
+}
+
+// Tx returns an `ent.Tx` for mutations that were executed in transactions;
+// it returns an error otherwise.
+func (m AuditLogMutation) Tx() (*Tx, error) {
+	if _, ok := m.driver.(*txDriver); !ok {
+		return nil, errors.New("ent: mutation is not running in a transaction")
+	}
+	tx := &Tx{config: m.config}
+	tx.init()
+	return tx, nil
+}
+
+// ID returns the ID value in the mutation. Note that the ID is only available
+// if it was provided to the builder or after it was returned from the database.
+func (m *AuditLogMutation) ID() (id int, exists bool) {
+	if m.id == nil {
+		return
+	}
+	return *m.id, true
+}
+
+// IDs queries the database and returns the entity ids that match the mutation's predicate.
+// That means, if the mutation is applied within a transaction with an isolation level such
+// as sql.LevelSerializable, the returned ids match the ids of the rows that will be updated
+// or updated by the mutation.
+func (m *AuditLogMutation) IDs(ctx context.Context) ([]int, error) {
+	switch {
+	case m.op.Is(OpUpdateOne | OpDeleteOne):
+		id, exists := m.ID()
+		if exists {
+			return []int{id}, nil
+		}
+		fallthrough
+	case m.op.Is(OpUpdate | OpDelete):
+		return m.Client().AuditLog.Query().Where(m.predicates...).IDs(ctx)
+	default:
+		return nil, fmt.Errorf("IDs is not allowed on %s operations", m.op)
+	}
+}
+
+// SetCreatedAt sets the "created_at" field.
+func (m *AuditLogMutation) SetCreatedAt(t time.Time) {
+	m.created_at = &t
+}
+
+// CreatedAt returns the value of the "created_at" field in the mutation.
+func (m *AuditLogMutation) CreatedAt() (r time.Time, exists bool) {
+	v := m.created_at
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldCreatedAt returns the old "created_at" field's value of the AuditLog entity.
+// If the AuditLog object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *AuditLogMutation) OldCreatedAt(ctx context.Context) (v time.Time, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldCreatedAt is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldCreatedAt requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldCreatedAt: %w", err)
+	}
+	return oldValue.CreatedAt, nil
+}
+
+// ResetCreatedAt resets all changes to the "created_at" field.
+func (m *AuditLogMutation) ResetCreatedAt() {
+	m.created_at = nil
+}
+
+// SetActorType sets the "actor_type" field.
+func (m *AuditLogMutation) SetActorType(at auditlog.ActorType) {
+	m.actor_type = &at
+}
+
+// ActorType returns the value of the "actor_type" field in the mutation.
+func (m *AuditLogMutation) ActorType() (r auditlog.ActorType, exists bool) {
+	v := m.actor_type
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldActorType returns the old "actor_type" field's value of the AuditLog entity.
+// If the AuditLog object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *AuditLogMutation) OldActorType(ctx context.Context) (v auditlog.ActorType, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldActorType is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldActorType requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldActorType: %w", err)
+	}
+	return oldValue.ActorType, nil
+}
+
+// ResetActorType resets all changes to the "actor_type" field.
+func (m *AuditLogMutation) ResetActorType() {
+	m.actor_type = nil
+}
+
+// SetActor sets the "actor" field.
+func (m *AuditLogMutation) SetActor(s string) {
+	m.actor = &s
+}
+
+// Actor returns the value of the "actor" field in the mutation.
+func (m *AuditLogMutation) Actor() (r string, exists bool) {
+	v := m.actor
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldActor returns the old "actor" field's value of the AuditLog entity.
+// If the AuditLog object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *AuditLogMutation) OldActor(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldActor is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldActor requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldActor: %w", err)
+	}
+	return oldValue.Actor, nil
+}
+
+// ResetActor resets all changes to the "actor" field.
+func (m *AuditLogMutation) ResetActor() {
+	m.actor = nil
+}
+
+// SetIPAddress sets the "ip_address" field.
+func (m *AuditLogMutation) SetIPAddress(s string) {
+	m.ip_address = &s
+}
+
+// IPAddress returns the value of the "ip_address" field in the mutation.
+func (m *AuditLogMutation) IPAddress() (r string, exists bool) {
+	v := m.ip_address
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldIPAddress returns the old "ip_address" field's value of the AuditLog entity.
+// If the AuditLog object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *AuditLogMutation) OldIPAddress(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldIPAddress is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldIPAddress requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldIPAddress: %w", err)
+	}
+	return oldValue.IPAddress, nil
+}
+
+// ClearIPAddress clears the value of the "ip_address" field.
+func (m *AuditLogMutation) ClearIPAddress() {
+	m.ip_address = nil
+	m.clearedFields[auditlog.FieldIPAddress] = struct{}{}
+}
+
+// IPAddressCleared returns if the "ip_address" field was cleared in this mutation.
+func (m *AuditLogMutation) IPAddressCleared() bool {
+	_, ok := m.clearedFields[auditlog.FieldIPAddress]
+	return ok
+}
+
+// ResetIPAddress resets all changes to the "ip_address" field.
+func (m *AuditLogMutation) ResetIPAddress() {
+	m.ip_address = nil
+	delete(m.clearedFields, auditlog.FieldIPAddress)
+}
+
+// SetAction sets the "action" field.
+func (m *AuditLogMutation) SetAction(s string) {
+	m.action = &s
+}
+
+// Action returns the value of the "action" field in the mutation.
+func (m *AuditLogMutation) Action() (r string, exists bool) {
+	v := m.action
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldAction returns the old "action" field's value of the AuditLog entity.
+// If the AuditLog object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *AuditLogMutation) OldAction(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldAction is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldAction requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldAction: %w", err)
+	}
+	return oldValue.Action, nil
+}
+
+// ResetAction resets all changes to the "action" field.
+func (m *AuditLogMutation) ResetAction() {
+	m.action = nil
+}
+
+// SetTarget sets the "target" field.
+func (m *AuditLogMutation) SetTarget(s string) {
+	m.target = &s
+}
+
+// Target returns the value of the "target" field in the mutation.
+func (m *AuditLogMutation) Target() (r string, exists bool) {
+	v := m.target
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldTarget returns the old "target" field's value of the AuditLog entity.
+// If the AuditLog object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *AuditLogMutation) OldTarget(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldTarget is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldTarget requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldTarget: %w", err)
+	}
+	return oldValue.Target, nil
+}
+
+// ClearTarget clears the value of the "target" field.
+func (m *AuditLogMutation) ClearTarget() {
+	m.target = nil
+	m.clearedFields[auditlog.FieldTarget] = struct{}{}
+}
+
+// TargetCleared returns if the "target" field was cleared in this mutation.
+func (m *AuditLogMutation) TargetCleared() bool {
+	_, ok := m.clearedFields[auditlog.FieldTarget]
+	return ok
+}
+
+// ResetTarget resets all changes to the "target" field.
+func (m *AuditLogMutation) ResetTarget() {
+	m.target = nil
+	delete(m.clearedFields, auditlog.FieldTarget)
+}
+
+// SetCount sets the "count" field.
+func (m *AuditLogMutation) SetCount(i int) {
+	m.count = &i
+	m.addcount = nil
+}
+
+// Count returns the value of the "count" field in the mutation.
+func (m *AuditLogMutation) Count() (r int, exists bool) {
+	v := m.count
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldCount returns the old "count" field's value of the AuditLog entity.
+// If the AuditLog object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *AuditLogMutation) OldCount(ctx context.Context) (v int, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldCount is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldCount requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldCount: %w", err)
+	}
+	return oldValue.Count, nil
+}
+
+// AddCount adds i to the "count" field.
+func (m *AuditLogMutation) AddCount(i int) {
+	if m.addcount != nil {
+		*m.addcount += i
+	} else {
+		m.addcount = &i
+	}
+}
+
+// AddedCount returns the value that was added to the "count" field in this mutation.
+func (m *AuditLogMutation) AddedCount() (r int, exists bool) {
+	v := m.addcount
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ResetCount resets all changes to the "count" field.
+func (m *AuditLogMutation) ResetCount() {
+	m.count = nil
+	m.addcount = nil
+}
+
+// Where appends a list predicates to the AuditLogMutation builder.
+func (m *AuditLogMutation) Where(ps ...predicate.AuditLog) {
+	m.predicates = append(m.predicates, ps...)
+}
+
+// WhereP appends storage-level predicates to the AuditLogMutation builder. Using this method,
+// users can use type-assertion to append predicates that do not depend on any generated package.
+func (m *AuditLogMutation) WhereP(ps ...func(*sql.Selector)) {
+	p := make([]predicate.AuditLog, len(ps))
+	for i := range ps {
+		p[i] = ps[i]
+	}
+	m.Where(p...)
+}
+
+// Op returns the operation name.
+func (m *AuditLogMutation) Op() Op {
+	return m.op
+}
+
+// SetOp allows setting the mutation operation.
+func (m *AuditLogMutation) SetOp(op Op) {
+	m.op = op
+}
+
+// Type returns the node type of this mutation (AuditLog).
+func (m *AuditLogMutation) Type() string {
+	return m.typ
+}
+
+// Fields returns all fields that were changed during this mutation. Note that in
+// order to get all numeric fields that were incremented/decremented, call
+// AddedFields().
+func (m *AuditLogMutation) Fields() []string {
+	fields := make([]string, 0, 7)
+	if m.created_at != nil {
+		fields = append(fields, auditlog.FieldCreatedAt)
+	}
+	if m.actor_type != nil {
+		fields = append(fields, auditlog.FieldActorType)
+	}
+	if m.actor != nil {
+		fields = append(fields, auditlog.FieldActor)
+	}
+	if m.ip_address != nil {
+		fields = append(fields, auditlog.FieldIPAddress)
+	}
+	if m.action != nil {
+		fields = append(fields, auditlog.FieldAction)
+	}
+	if m.target != nil {
+		fields = append(fields, auditlog.FieldTarget)
+	}
+	if m.count != nil {
+		fields = append(fields, auditlog.FieldCount)
+	}
+	return fields
+}
+
+// Field returns the value of a field with the given name. The second boolean
+// return value indicates that this field was not set, or was not defined in the
+// schema.
+func (m *AuditLogMutation) Field(name string) (ent.Value, bool) {
+	switch name {
+	case auditlog.FieldCreatedAt:
+		return m.CreatedAt()
+	case auditlog.FieldActorType:
+		return m.ActorType()
+	case auditlog.FieldActor:
+		return m.Actor()
+	case auditlog.FieldIPAddress:
+		return m.IPAddress()
+	case auditlog.FieldAction:
+		return m.Action()
+	case auditlog.FieldTarget:
+		return m.Target()
+	case auditlog.FieldCount:
+		return m.Count()
+	}
+	return nil, false
+}
+
+// OldField returns the old value of the field from the database. An error is
+// returned if the mutation operation is not UpdateOne, or the query to the
+// database failed.
+func (m *AuditLogMutation) OldField(ctx context.Context, name string) (ent.Value, error) {
+	switch name {
+	case auditlog.FieldCreatedAt:
+		return m.OldCreatedAt(ctx)
+	case auditlog.FieldActorType:
+		return m.OldActorType(ctx)
+	case auditlog.FieldActor:
+		return m.OldActor(ctx)
+	case auditlog.FieldIPAddress:
+		return m.OldIPAddress(ctx)
+	case auditlog.FieldAction:
+		return m.OldAction(ctx)
+	case auditlog.FieldTarget:
+		return m.OldTarget(ctx)
+	case auditlog.FieldCount:
+		return m.OldCount(ctx)
+	}
+	return nil, fmt.Errorf("unknown AuditLog field %s", name)
+}
+
+// SetField sets the value of a field with the given name. It returns an error if
+// the field is not defined in the schema, or if the type mismatched the field
+// type.
+func (m *AuditLogMutation) SetField(name string, value ent.Value) error {
+	switch name {
+	case auditlog.FieldCreatedAt:
+		v, ok := value.(time.Time)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetCreatedAt(v)
+		return nil
+	case auditlog.FieldActorType:
+		v, ok := value.(auditlog.ActorType)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetActorType(v)
+		return nil
+	case auditlog.FieldActor:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetActor(v)
+		return nil
+	case auditlog.FieldIPAddress:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetIPAddress(v)
+		return nil
+	case auditlog.FieldAction:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetAction(v)
+		return nil
+	case auditlog.FieldTarget:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetTarget(v)
+		return nil
+	case auditlog.FieldCount:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetCount(v)
+		return nil
+	}
+	return fmt.Errorf("unknown AuditLog field %s", name)
+}
+
+// AddedFields returns all numeric fields that were incremented/decremented during
+// this mutation.
+func (m *AuditLogMutation) AddedFields() []string {
+	var fields []string
+	if m.addcount != nil {
+		fields = append(fields, auditlog.FieldCount)
+	}
+	return fields
+}
+
+// AddedField returns the numeric value that was incremented/decremented on a field
+// with the given name. The second boolean return value indicates that this field
+// was not set, or was not defined in the schema.
+func (m *AuditLogMutation) AddedField(name string) (ent.Value, bool) {
+	switch name {
+	case auditlog.FieldCount:
+		return m.AddedCount()
+	}
+	return nil, false
+}
+
+// AddField adds the value to the field with the given name. It returns an error if
+// the field is not defined in the schema, or if the type mismatched the field
+// type.
+func (m *AuditLogMutation) AddField(name string, value ent.Value) error {
+	switch name {
+	case auditlog.FieldCount:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddCount(v)
+		return nil
+	}
+	return fmt.Errorf("unknown AuditLog numeric field %s", name)
+}
+
+// ClearedFields returns all nullable fields that were cleared during this
+// mutation.
+func (m *AuditLogMutation) ClearedFields() []string {
+	var fields []string
+	if m.FieldCleared(auditlog.FieldIPAddress) {
+		fields = append(fields, auditlog.FieldIPAddress)
+	}
+	if m.FieldCleared(auditlog.FieldTarget) {
+		fields = append(fields, auditlog.FieldTarget)
+	}
+	return fields
+}
+
+// FieldCleared returns a boolean indicating if a field with the given name was
+// cleared in this mutation.
+func (m *AuditLogMutation) FieldCleared(name string) bool {
+	_, ok := m.clearedFields[name]
+	return ok
+}
+
+// ClearField clears the value of the field with the given name. It returns an
+// error if the field is not defined in the schema.
+func (m *AuditLogMutation) ClearField(name string) error {
+	switch name {
+	case auditlog.FieldIPAddress:
+		m.ClearIPAddress()
+		return nil
+	case auditlog.FieldTarget:
+		m.ClearTarget()
 		return nil
 	}
-	return fmt.Errorf("unknown AllowListItem nullable field %s", name)
+	return fmt.Errorf("unknown AuditLog nullable field %s", name)
 }
 
 // ResetField resets all changes in the mutation for the field with the given name.
 // It returns an error if the field is not defined in the schema.
-func (m *AllowListItemMutation) ResetField(name string) error {
+func (m *AuditLogMutation) ResetField(name string) error {
 	switch name {
-	case allowlistitem.FieldCreatedAt:
+	case auditlog.FieldCreatedAt:
 		m.ResetCreatedAt()
 		return nil
-	case allowlistitem.FieldUpdatedAt:
-		m.ResetUpdatedAt()
-		return nil
-	case allowlistitem.FieldExpiresAt:
-		m.ResetExpiresAt()
-		return nil
-	case allowlistitem.FieldComment:
-		m.ResetComment()
-		return nil
-	case allowlistitem.FieldValue:
-		m.ResetValue()
+	case auditlog.FieldActorType:
+		m.ResetActorType()
 		return nil
-	case allowlistitem.FieldStartIP:
-		m.ResetStartIP()
+	case auditlog.FieldActor:
+		m.ResetActor()
 		return nil
-	case allowlistitem.FieldEndIP:
-		m.ResetEndIP()
+	case auditlog.FieldIPAddress:
+		m.ResetIPAddress()
 		return nil
-	case allowlistitem.FieldStartSuffix:
-		m.ResetStartSuffix()
+	case auditlog.FieldAction:
+		m.ResetAction()
 		return nil
-	case allowlistitem.FieldEndSuffix:
-		m.ResetEndSuffix()
+	case auditlog.FieldTarget:
+		m.ResetTarget()
 		return nil
-	case allowlistitem.FieldIPSize:
-		m.ResetIPSize()
+	case auditlog.FieldCount:
+		m.ResetCount()
 		return nil
 	}
-	return fmt.Errorf("unknown AllowListItem field %s", name)
+	return fmt.Errorf("unknown AuditLog field %s", name)
 }
 
 // AddedEdges returns all edge names that were set/added in this mutation.
-func (m *AllowListItemMutation) AddedEdges() []string {
-	edges := make([]string, 0, 1)
-	if m.allowlist != nil {
-		edges = append(edges, allowlistitem.EdgeAllowlist)
-	}
+func (m *AuditLogMutation) AddedEdges() []string {
+	edges := make([]string, 0, 0)
 	return edges
 }
 
 // AddedIDs returns all IDs (to other nodes) that were added for the given edge
 // name in this mutation.
-func (m *AllowListItemMutation) AddedIDs(name string) []ent.Value {
-	switch name {
-	case allowlistitem.EdgeAllowlist:
-		ids := make([]ent.Value, 0, len(m.allowlist))
-		for id := range m.allowlist {
-			ids = append(ids, id)
-		}
-		return ids
-	}
+func (m *AuditLogMutation) AddedIDs(name string) []ent.Value {
 	return nil
 }
 
 // RemovedEdges returns all edge names that were removed in this mutation.
-func (m *AllowListItemMutation) RemovedEdges() []string {
-	edges := make([]string, 0, 1)
-	if m.removedallowlist != nil {
-		edges = append(edges, allowlistitem.EdgeAllowlist)
-	}
+func (m *AuditLogMutation) RemovedEdges() []string {
+	edges := make([]string, 0, 0)
 	return edges
 }
 
 // RemovedIDs returns all IDs (to other nodes) that were removed for the edge with
 // the given name in this mutation.
-func (m *AllowListItemMutation) RemovedIDs(name string) []ent.Value {
-	switch name {
-	case allowlistitem.EdgeAllowlist:
-		ids := make([]ent.Value, 0, len(m.removedallowlist))
-		for id := range m.removedallowlist {
-			ids = append(ids, id)
-		}
-		return ids
-	}
+func (m *AuditLogMutation) RemovedIDs(name string) []ent.Value {
 	return nil
 }
 
 // ClearedEdges returns all edge names that were cleared in this mutation.
-func (m *AllowListItemMutation) ClearedEdges() []string {
-	edges := make([]string, 0, 1)
-	if m.clearedallowlist {
-		edges = append(edges, allowlistitem.EdgeAllowlist)
-	}
+func (m *AuditLogMutation) ClearedEdges() []string {
+	edges := make([]string, 0, 0)
 	return edges
 }
 
 // EdgeCleared returns a boolean which indicates if the edge with the given name
 // was cleared in this mutation.
-func (m *AllowListItemMutation) EdgeCleared(name string) bool {
-	switch name {
-	case allowlistitem.EdgeAllowlist:
-		return m.clearedallowlist
-	}
+func (m *AuditLogMutation) EdgeCleared(name string) bool {
 	return false
 }
 
 // ClearEdge clears the value of the edge with the given name. It returns an error
 // if that edge is not defined in the schema.
-func (m *AllowListItemMutation) ClearEdge(name string) error {
-	switch name {
-	}
-	return fmt.Errorf("unknown AllowListItem unique edge %s", name)
+func (m *AuditLogMutation) ClearEdge(name string) error {
+	return fmt.Errorf("unknown AuditLog unique edge %s", name)
 }
 
 // ResetEdge resets all changes to the edge with the given name in this mutation.
 // It returns an error if the edge is not defined in the schema.
-func (m *AllowListItemMutation) ResetEdge(name string) error {
-	switch name {
-	case allowlistitem.EdgeAllowlist:
-		m.ResetAllowlist()
-		return nil
-	}
-	return fmt.Errorf("unknown AllowListItem edge %s", name)
+func (m *AuditLogMutation) ResetEdge(name string) error {
+	return fmt.Errorf("unknown AuditLog edge %s", name)
 }
 
 // BouncerMutation represents an operation that mutates the Bouncer nodes in the graph.
 type BouncerMutation struct {
 	config
-	op            Op
-	typ           string
-	id            *int
-	created_at    *time.Time
-	updated_at    *time.Time
-	name          *string
-	api_key       *string
-	revoked       *bool
-	ip_address    *string
-	_type         *string
-	version       *string
-	last_pull     *time.Time
-	auth_type     *string
-	osname        *string
-	osfamily      *string
-	osversion     *string
-	featureflags  *string
-	auto_created  *bool
-	clearedFields map[string]struct{}
-	done          bool
-	oldValue      func(context.Context) (*Bouncer, error)
-	predicates    []predicate.Bouncer
+	op                      Op
+	typ                     string
+	id                      *int
+	created_at              *time.Time
+	updated_at              *time.Time
+	name                    *string
+	api_key                 *string
+	revoked                 *bool
+	ip_address              *string
+	_type                   *string
+	version                 *string
+	last_pull               *time.Time
+	auth_type               *string
+	osname                  *string
+	osfamily                *string
+	osversion               *string
+	featureflags            *string
+	auto_created            *bool
+	allowed_scopes          *[]string
+	appendallowed_scopes    []string
+	allowed_origins         *[]string
+	appendallowed_origins   []string
+	allowed_scenarios       *[]string
+	appendallowed_scenarios []string
+	clearedFields           map[string]struct{}
+	done                    bool
+	oldValue                func(context.Context) (*Bouncer, error)
+	predicates              []predicate.Bouncer
 }
 
 var _ ent.Mutation = (*BouncerMutation)(nil)
@@ -4570,6 +5317,12 @@ func (m BouncerMutation) Tx() (*Tx, error) {
 	return tx, nil
 }
 
+// SetID sets the value of the id field. Note that this
+// operation is only accepted on creation of Bouncer entities.
+func (m *BouncerMutation) SetID(id int) {
+	m.id = &id
+}
+
 // ID returns the ID value in the mutation. Note that the ID is only available
 // if it was provided to the builder or after it was returned from the database.
 func (m *BouncerMutation) ID() (id int, exists bool) {
@@ -5242,6 +5995,201 @@ func (m *BouncerMutation) ResetAutoCreated() {
 	m.auto_created = nil
 }
 
+// SetAllowedScopes sets the "allowed_scopes" field.
+func (m *BouncerMutation) SetAllowedScopes(s []string) {
+	m.allowed_scopes = &s
+	m.appendallowed_scopes = nil
+}
+
+// AllowedScopes returns the value of the "allowed_scopes" field in the mutation.
+func (m *BouncerMutation) AllowedScopes() (r []string, exists bool) {
+	v := m.allowed_scopes
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldAllowedScopes returns the old "allowed_scopes" field's value of the Bouncer entity.
+// If the Bouncer object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *BouncerMutation) OldAllowedScopes(ctx context.Context) (v []string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldAllowedScopes is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldAllowedScopes requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldAllowedScopes: %w", err)
+	}
+	return oldValue.AllowedScopes, nil
+}
+
+// AppendAllowedScopes adds s to the "allowed_scopes" field.
+func (m *BouncerMutation) AppendAllowedScopes(s []string) {
+	m.appendallowed_scopes = append(m.appendallowed_scopes, s...)
+}
+
+// AppendedAllowedScopes returns the list of values that were appended to the "allowed_scopes" field in this mutation.
+func (m *BouncerMutation) AppendedAllowedScopes() ([]string, bool) {
+	if len(m.appendallowed_scopes) == 0 {
+		return nil, false
+	}
+	return m.appendallowed_scopes, true
+}
+
+// ClearAllowedScopes clears the value of the "allowed_scopes" field.
+func (m *BouncerMutation) ClearAllowedScopes() {
+	m.allowed_scopes = nil
+	m.appendallowed_scopes = nil
+	m.clearedFields[bouncer.FieldAllowedScopes] = struct{}{}
+}
+
+// AllowedScopesCleared returns if the "allowed_scopes" field was cleared in this mutation.
+func (m *BouncerMutation) AllowedScopesCleared() bool {
+	_, ok := m.clearedFields[bouncer.FieldAllowedScopes]
+	return ok
+}
+
+// ResetAllowedScopes resets all changes to the "allowed_scopes" field.
+func (m *BouncerMutation) ResetAllowedScopes() {
+	m.allowed_scopes = nil
+	m.appendallowed_scopes = nil
+	delete(m.clearedFields, bouncer.FieldAllowedScopes)
+}
+
+// SetAllowedOrigins sets the "allowed_origins" field.
+func (m *BouncerMutation) SetAllowedOrigins(s []string) {
+	m.allowed_origins = &s
+	m.appendallowed_origins = nil
+}
+
+// AllowedOrigins returns the value of the "allowed_origins" field in the mutation.
+func (m *BouncerMutation) AllowedOrigins() (r []string, exists bool) {
+	v := m.allowed_origins
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldAllowedOrigins returns the old "allowed_origins" field's value of the Bouncer entity.
+// If the Bouncer object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *BouncerMutation) OldAllowedOrigins(ctx context.Context) (v []string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldAllowedOrigins is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldAllowedOrigins requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldAllowedOrigins: %w", err)
+	}
+	return oldValue.AllowedOrigins, nil
+}
+
+// AppendAllowedOrigins adds s to the "allowed_origins" field.
+func (m *BouncerMutation) AppendAllowedOrigins(s []string) {
+	m.appendallowed_origins = append(m.appendallowed_origins, s...)
+}
+
+// AppendedAllowedOrigins returns the list of values that were appended to the "allowed_origins" field in this mutation.
+func (m *BouncerMutation) AppendedAllowedOrigins() ([]string, bool) {
+	if len(m.appendallowed_origins) == 0 {
+		return nil, false
+	}
+	return m.appendallowed_origins, true
+}
+
+// ClearAllowedOrigins clears the value of the "allowed_origins" field.
+func (m *BouncerMutation) ClearAllowedOrigins() {
+	m.allowed_origins = nil
+	m.appendallowed_origins = nil
+	m.clearedFields[bouncer.FieldAllowedOrigins] = struct{}{}
+}
+
+// AllowedOriginsCleared returns if the "allowed_origins" field was cleared in this mutation.
+func (m *BouncerMutation) AllowedOriginsCleared() bool {
+	_, ok := m.clearedFields[bouncer.FieldAllowedOrigins]
+	return ok
+}
+
+// ResetAllowedOrigins resets all changes to the "allowed_origins" field.
+func (m *BouncerMutation) ResetAllowedOrigins() {
+	m.allowed_origins = nil
+	m.appendallowed_origins = nil
+	delete(m.clearedFields, bouncer.FieldAllowedOrigins)
+}
+
+// SetAllowedScenarios sets the "allowed_scenarios" field.
+func (m *BouncerMutation) SetAllowedScenarios(s []string) {
+	m.allowed_scenarios = &s
+	m.appendallowed_scenarios = nil
+}
+
+// AllowedScenarios returns the value of the "allowed_scenarios" field in the mutation.
+func (m *BouncerMutation) AllowedScenarios() (r []string, exists bool) {
+	v := m.allowed_scenarios
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldAllowedScenarios returns the old "allowed_scenarios" field's value of the Bouncer entity.
+// If the Bouncer object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *BouncerMutation) OldAllowedScenarios(ctx context.Context) (v []string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldAllowedScenarios is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldAllowedScenarios requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldAllowedScenarios: %w", err)
+	}
+	return oldValue.AllowedScenarios, nil
+}
+
+// AppendAllowedScenarios adds s to the "allowed_scenarios" field.
+func (m *BouncerMutation) AppendAllowedScenarios(s []string) {
+	m.appendallowed_scenarios = append(m.appendallowed_scenarios, s...)
+}
+
+// AppendedAllowedScenarios returns the list of values that were appended to the "allowed_scenarios" field in this mutation.
+func (m *BouncerMutation) AppendedAllowedScenarios() ([]string, bool) {
+	if len(m.appendallowed_scenarios) == 0 {
+		return nil, false
+	}
+	return m.appendallowed_scenarios, true
+}
+
+// ClearAllowedScenarios clears the value of the "allowed_scenarios" field.
+func (m *BouncerMutation) ClearAllowedScenarios() {
+	m.allowed_scenarios = nil
+	m.appendallowed_scenarios = nil
+	m.clearedFields[bouncer.FieldAllowedScenarios] = struct{}{}
+}
+
+// AllowedScenariosCleared returns if the "allowed_scenarios" field was cleared in this mutation.
+func (m *BouncerMutation) AllowedScenariosCleared() bool {
+	_, ok := m.clearedFields[bouncer.FieldAllowedScenarios]
+	return ok
+}
+
+// ResetAllowedScenarios resets all changes to the "allowed_scenarios" field.
+func (m *BouncerMutation) ResetAllowedScenarios() {
+	m.allowed_scenarios = nil
+	m.appendallowed_scenarios = nil
+	delete(m.clearedFields, bouncer.FieldAllowedScenarios)
+}
+
 // Where appends a list predicates to the BouncerMutation builder.
 func (m *BouncerMutation) Where(ps ...predicate.Bouncer) {
 	m.predicates = append(m.predicates, ps...)
@@ -5276,7 +6224,7 @@ func (m *BouncerMutation) Type() string {
 // order to get all numeric fields that were incremented/decremented, call
 // AddedFields().
 func (m *BouncerMutation) Fields() []string {
-	fields := make([]string, 0, 15)
+	fields := make([]string, 0, 18)
 	if m.created_at != nil {
 		fields = append(fields, bouncer.FieldCreatedAt)
 	}
@@ -5322,6 +6270,15 @@ func (m *BouncerMutation) Fields() []string {
 	if m.auto_created != nil {
 		fields = append(fields, bouncer.FieldAutoCreated)
 	}
+	if m.allowed_scopes != nil {
+		fields = append(fields, bouncer.FieldAllowedScopes)
+	}
+	if m.allowed_origins != nil {
+		fields = append(fields, bouncer.FieldAllowedOrigins)
+	}
+	if m.allowed_scenarios != nil {
+		fields = append(fields, bouncer.FieldAllowedScenarios)
+	}
 	return fields
 }
 
@@ -5360,6 +6317,12 @@ func (m *BouncerMutation) Field(name string) (ent.Value, bool) {
 		return m.Featureflags()
 	case bouncer.FieldAutoCreated:
 		return m.AutoCreated()
+	case bouncer.FieldAllowedScopes:
+		return m.AllowedScopes()
+	case bouncer.FieldAllowedOrigins:
+		return m.AllowedOrigins()
+	case bouncer.FieldAllowedScenarios:
+		return m.AllowedScenarios()
 	}
 	return nil, false
 }
@@ -5399,6 +6362,12 @@ func (m *BouncerMutation) OldField(ctx context.Context, name string) (ent.Value,
 		return m.OldFeatureflags(ctx)
 	case bouncer.FieldAutoCreated:
 		return m.OldAutoCreated(ctx)
+	case bouncer.FieldAllowedScopes:
+		return m.OldAllowedScopes(ctx)
+	case bouncer.FieldAllowedOrigins:
+		return m.OldAllowedOrigins(ctx)
+	case bouncer.FieldAllowedScenarios:
+		return m.OldAllowedScenarios(ctx)
 	}
 	return nil, fmt.Errorf("unknown Bouncer field %s", name)
 }
@@ -5513,6 +6482,27 @@ func (m *BouncerMutation) SetField(name string, value ent.Value) error {
 		}
 		m.SetAutoCreated(v)
 		return nil
+	case bouncer.FieldAllowedScopes:
+		v, ok := value.([]string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetAllowedScopes(v)
+		return nil
+	case bouncer.FieldAllowedOrigins:
+		v, ok := value.([]string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetAllowedOrigins(v)
+		return nil
+	case bouncer.FieldAllowedScenarios:
+		v, ok := value.([]string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetAllowedScenarios(v)
+		return nil
 	}
 	return fmt.Errorf("unknown Bouncer field %s", name)
 }
@@ -5567,6 +6557,15 @@ func (m *BouncerMutation) ClearedFields() []string {
 	if m.FieldCleared(bouncer.FieldFeatureflags) {
 		fields = append(fields, bouncer.FieldFeatureflags)
 	}
+	if m.FieldCleared(bouncer.FieldAllowedScopes) {
+		fields = append(fields, bouncer.FieldAllowedScopes)
+	}
+	if m.FieldCleared(bouncer.FieldAllowedOrigins) {
+		fields = append(fields, bouncer.FieldAllowedOrigins)
+	}
+	if m.FieldCleared(bouncer.FieldAllowedScenarios) {
+		fields = append(fields, bouncer.FieldAllowedScenarios)
+	}
 	return fields
 }
 
@@ -5605,6 +6604,15 @@ func (m *BouncerMutation) ClearField(name string) error {
 	case bouncer.FieldFeatureflags:
 		m.ClearFeatureflags()
 		return nil
+	case bouncer.FieldAllowedScopes:
+		m.ClearAllowedScopes()
+		return nil
+	case bouncer.FieldAllowedOrigins:
+		m.ClearAllowedOrigins()
+		return nil
+	case bouncer.FieldAllowedScenarios:
+		m.ClearAllowedScenarios()
+		return nil
 	}
 	return fmt.Errorf("unknown Bouncer nullable field %s", name)
 }
@@ -5658,6 +6666,15 @@ func (m *BouncerMutation) ResetField(name string) error {
 	case bouncer.FieldAutoCreated:
 		m.ResetAutoCreated()
 		return nil
+	case bouncer.FieldAllowedScopes:
+		m.ResetAllowedScopes()
+		return nil
+	case bouncer.FieldAllowedOrigins:
+		m.ResetAllowedOrigins()
+		return nil
+	case bouncer.FieldAllowedScenarios:
+		m.ResetAllowedScenarios()
+		return nil
 	}
 	return fmt.Errorf("unknown Bouncer field %s", name)
 }
@@ -6302,6 +7319,12 @@ func (m DecisionMutation) Tx() (*Tx, error) {
 	return tx, nil
 }
 
+// SetID sets the value of the id field. Note that this
+// operation is only accepted on creation of Decision entities.
+func (m *DecisionMutation) SetID(id int) {
+	m.id = &id
+}
+
 // ID returns the ID value in the mutation. Note that the ID is only available
 // if it was provided to the builder or after it was returned from the database.
 func (m *DecisionMutation) ID() (id int, exists bool) {
@@ -8766,6 +9789,8 @@ type MachineMutation struct {
 	featureflags   *string
 	hubstate       *map[string][]schema.ItemState
 	datasources    *map[string]int64
+	roles          *[]string
+	appendroles    []string
 	clearedFields  map[string]struct{}
 	alerts         map[int]struct{}
 	removedalerts  map[int]struct{}
@@ -9615,6 +10640,71 @@ func (m *MachineMutation) ResetDatasources() {
 	delete(m.clearedFields, machine.FieldDatasources)
 }
 
+// SetRoles sets the "roles" field.
+func (m *MachineMutation) SetRoles(s []string) {
+	m.roles = &s
+	m.appendroles = nil
+}
+
+// Roles returns the value of the "roles" field in the mutation.
+func (m *MachineMutation) Roles() (r []string, exists bool) {
+	v := m.roles
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldRoles returns the old "roles" field's value of the Machine entity.
+// If the Machine object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *MachineMutation) OldRoles(ctx context.Context) (v []string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldRoles is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldRoles requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldRoles: %w", err)
+	}
+	return oldValue.Roles, nil
+}
+
+// AppendRoles adds s to the "roles" field.
+func (m *MachineMutation) AppendRoles(s []string) {
+	m.appendroles = append(m.appendroles, s...)
+}
+
+// AppendedRoles returns the list of values that were appended to the "roles" field in this mutation.
+func (m *MachineMutation) AppendedRoles() ([]string, bool) {
+	if len(m.appendroles) == 0 {
+		return nil, false
+	}
+	return m.appendroles, true
+}
+
+// ClearRoles clears the value of the "roles" field.
+func (m *MachineMutation) ClearRoles() {
+	m.roles = nil
+	m.appendroles = nil
+	m.clearedFields[machine.FieldRoles] = struct{}{}
+}
+
+// RolesCleared returns if the "roles" field was cleared in this mutation.
+func (m *MachineMutation) RolesCleared() bool {
+	_, ok := m.clearedFields[machine.FieldRoles]
+	return ok
+}
+
+// ResetRoles resets all changes to the "roles" field.
+func (m *MachineMutation) ResetRoles() {
+	m.roles = nil
+	m.appendroles = nil
+	delete(m.clearedFields, machine.FieldRoles)
+}
+
 // AddAlertIDs adds the "alerts" edge to the Alert entity by ids.
 func (m *MachineMutation) AddAlertIDs(ids ...int) {
 	if m.alerts == nil {
@@ -9703,7 +10793,7 @@ func (m *MachineMutation) Type() string {
 // order to get all numeric fields that were incremented/decremented, call
 // AddedFields().
 func (m *MachineMutation) Fields() []string {
-	fields := make([]string, 0, 17)
+	fields := make([]string, 0, 18)
 	if m.created_at != nil {
 		fields = append(fields, machine.FieldCreatedAt)
 	}
@@ -9755,6 +10845,9 @@ func (m *MachineMutation) Fields() []string {
 	if m.datasources != nil {
 		fields = append(fields, machine.FieldDatasources)
 	}
+	if m.roles != nil {
+		fields = append(fields, machine.FieldRoles)
+	}
 	return fields
 }
 
@@ -9797,6 +10890,8 @@ func (m *MachineMutation) Field(name string) (ent.Value, bool) {
 		return m.Hubstate()
 	case machine.FieldDatasources:
 		return m.Datasources()
+	case machine.FieldRoles:
+		return m.Roles()
 	}
 	return nil, false
 }
@@ -9840,6 +10935,8 @@ func (m *MachineMutation) OldField(ctx context.Context, name string) (ent.Value,
 		return m.OldHubstate(ctx)
 	case machine.FieldDatasources:
 		return m.OldDatasources(ctx)
+	case machine.FieldRoles:
+		return m.OldRoles(ctx)
 	}
 	return nil, fmt.Errorf("unknown Machine field %s", name)
 }
@@ -9968,6 +11065,13 @@ func (m *MachineMutation) SetField(name string, value ent.Value) error {
 		}
 		m.SetDatasources(v)
 		return nil
+	case machine.FieldRoles:
+		v, ok := value.([]string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetRoles(v)
+		return nil
 	}
 	return fmt.Errorf("unknown Machine field %s", name)
 }
@@ -10028,6 +11132,9 @@ func (m *MachineMutation) ClearedFields() []string {
 	if m.FieldCleared(machine.FieldDatasources) {
 		fields = append(fields, machine.FieldDatasources)
 	}
+	if m.FieldCleared(machine.FieldRoles) {
+		fields = append(fields, machine.FieldRoles)
+	}
 	return fields
 }
 
@@ -10072,6 +11179,9 @@ func (m *MachineMutation) ClearField(name string) error {
 	case machine.FieldDatasources:
 		m.ClearDatasources()
 		return nil
+	case machine.FieldRoles:
+		m.ClearRoles()
+		return nil
 	}
 	return fmt.Errorf("unknown Machine nullable field %s", name)
 }
@@ -10131,6 +11241,9 @@ func (m *MachineMutation) ResetField(name string) error {
 	case machine.FieldDatasources:
 		m.ResetDatasources()
 		return nil
+	case machine.FieldRoles:
+		m.ResetRoles()
+		return nil
 	}
 	return fmt.Errorf("unknown Machine field %s", name)
 }
@@ -15,6 +15,9 @@ type AllowList func(*sql.Selector)
 // AllowListItem is the predicate function for allowlistitem builders.
 type AllowListItem func(*sql.Selector)
 
+// AuditLog is the predicate function for auditlog builders.
+type AuditLog func(*sql.Selector)
+
 // Bouncer is the predicate function for bouncer builders.
 type Bouncer func(*sql.Selector)
 
@@ -8,6 +8,7 @@ import (
 	"github.com/crowdsecurity/crowdsec/pkg/database/ent/alert"
 	"github.com/crowdsecurity/crowdsec/pkg/database/ent/allowlist"
 	"github.com/crowdsecurity/crowdsec/pkg/database/ent/allowlistitem"
+	"github.com/crowdsecurity/crowdsec/pkg/database/ent/auditlog"
 	"github.com/crowdsecurity/crowdsec/pkg/database/ent/bouncer"
 	"github.com/crowdsecurity/crowdsec/pkg/database/ent/configitem"
 	"github.com/crowdsecurity/crowdsec/pkg/database/ent/decision"
@@ -25,37 +26,37 @@ func init() {
 	alertFields := schema.Alert{}.Fields()
 	_ = alertFields
 	// alertDescCreatedAt is the schema descriptor for created_at field.
-	alertDescCreatedAt := alertFields[0].Descriptor()
+	alertDescCreatedAt := alertFields[1].Descriptor()
 	// alert.DefaultCreatedAt holds the default value on creation for the created_at field.
 	alert.DefaultCreatedAt = alertDescCreatedAt.Default.(func() time.Time)
 	// alertDescUpdatedAt is the schema descriptor for updated_at field.
-	alertDescUpdatedAt := alertFields[1].Descriptor()
+	alertDescUpdatedAt := alertFields[2].Descriptor()
 	// alert.DefaultUpdatedAt holds the default value on creation for the updated_at field.
 	alert.DefaultUpdatedAt = alertDescUpdatedAt.Default.(func() time.Time)
 	// alert.UpdateDefaultUpdatedAt holds the default value on update for the updated_at field.
 	alert.UpdateDefaultUpdatedAt = alertDescUpdatedAt.UpdateDefault.(func() time.Time)
 	// alertDescBucketId is the schema descriptor for bucketId field.
-	alertDescBucketId := alertFields[3].Descriptor()
+	alertDescBucketId := alertFields[4].Descriptor()
 	// alert.DefaultBucketId holds the default value on creation for the bucketId field.
 	alert.DefaultBucketId = alertDescBucketId.Default.(string)
 	// alertDescMessage is the schema descriptor for message field.
-	alertDescMessage := alertFields[4].Descriptor()
+	alertDescMessage := alertFields[5].Descriptor()
 	// alert.DefaultMessage holds the default value on creation for the message field.
 	alert.DefaultMessage = alertDescMessage.Default.(string)
 	// alertDescEventsCount is the schema descriptor for eventsCount field.
-	alertDescEventsCount := alertFields[5].Descriptor()
+	alertDescEventsCount := alertFields[6].Descriptor()
 	// alert.DefaultEventsCount holds the default value on creation for the eventsCount field.
 	alert.DefaultEventsCount = alertDescEventsCount.Default.(int32)
 	// alertDescStartedAt is the schema descriptor for startedAt field.
-	alertDescStartedAt := alertFields[6].Descriptor()
+	alertDescStartedAt := alertFields[7].Descriptor()
 	// alert.DefaultStartedAt holds the default value on creation for the startedAt field.
 	alert.DefaultStartedAt = alertDescStartedAt.Default.(func() time.Time)
 	// alertDescStoppedAt is the schema descriptor for stoppedAt field.
-	alertDescStoppedAt := alertFields[7].Descriptor()
+	alertDescStoppedAt := alertFields[8].Descriptor()
 	// alert.DefaultStoppedAt holds the default value on creation for the stoppedAt field.
 	alert.DefaultStoppedAt = alertDescStoppedAt.Default.(func() time.Time)
 	// alertDescSimulated is the schema descriptor for simulated field.
-	alertDescSimulated := alertFields[21].Descriptor()
+	alertDescSimulated := alertFields[22].Descriptor()
 	// alert.DefaultSimulated holds the default value on creation for the simulated field.
 	alert.DefaultSimulated = alertDescSimulated.Default.(bool)
 	allowlistFields := schema.AllowList{}.Fields()
@@ -73,37 +74,55 @@ func init() {
 	allowlistitemFields := schema.AllowListItem{}.Fields()
 	_ = allowlistitemFields
 	// allowlistitemDescCreatedAt is the schema descriptor for created_at field.
-	allowlistitemDescCreatedAt := allowlistitemFields[0].Descriptor()
+	allowlistitemDescCreatedAt := allowlistitemFields[1].Descriptor()
 	// allowlistitem.DefaultCreatedAt holds the default value on creation for the created_at field.
 	allowlistitem.DefaultCreatedAt = allowlistitemDescCreatedAt.Default.(func() time.Time)
 	// allowlistitemDescUpdatedAt is the schema descriptor for updated_at field.
-	allowlistitemDescUpdatedAt := allowlistitemFields[1].Descriptor()
+	allowlistitemDescUpdatedAt := allowlistitemFields[2].Descriptor()
 	// allowlistitem.DefaultUpdatedAt holds the default value on creation for the updated_at field.
 	allowlistitem.DefaultUpdatedAt = allowlistitemDescUpdatedAt.Default.(func() time.Time)
 	// allowlistitem.UpdateDefaultUpdatedAt holds the default value on update for the updated_at field.
 	allowlistitem.UpdateDefaultUpdatedAt = allowlistitemDescUpdatedAt.UpdateDefault.(func() time.Time)
+	auditlogFields := schema.AuditLog{}.Fields()
+	_ = auditlogFields
+	// auditlogDescCreatedAt is the schema descriptor for created_at field.
+	auditlogDescCreatedAt := auditlogFields[0].Descriptor()
+	// auditlog.DefaultCreatedAt holds the default value on creation for the created_at field.
+	auditlog.DefaultCreatedAt = auditlogDescCreatedAt.Default.(func() time.Time)
+	// auditlogDescIPAddress is the schema descriptor for ip_address field.
+	auditlogDescIPAddress := auditlogFields[3].Descriptor()
+	// auditlog.DefaultIPAddress holds the default value on creation for the ip_address field.
+	auditlog.DefaultIPAddress = auditlogDescIPAddress.Default.(string)
+	// auditlogDescTarget is the schema descriptor for target field.
+	auditlogDescTarget := auditlogFields[5].Descriptor()
+	// auditlog.DefaultTarget holds the default value on creation for the target field.
+	auditlog.DefaultTarget = auditlogDescTarget.Default.(string)
+	// auditlogDescCount is the schema descriptor for count field.
+	auditlogDescCount := auditlogFields[6].Descriptor()
+	// auditlog.DefaultCount holds the default value on creation for the count field.
+	auditlog.DefaultCount = auditlogDescCount.Default.(int)
 	bouncerFields := schema.Bouncer{}.Fields()
 	_ = bouncerFields
 	// bouncerDescCreatedAt is the schema descriptor for created_at field.
-	bouncerDescCreatedAt := bouncerFields[0].Descriptor()
+	bouncerDescCreatedAt := bouncerFields[1].Descriptor()
 	// bouncer.DefaultCreatedAt holds the default value on creation for the created_at field.
 	bouncer.DefaultCreatedAt = bouncerDescCreatedAt.Default.(func() time.Time)
 	// bouncerDescUpdatedAt is the schema descriptor for updated_at field.
-	bouncerDescUpdatedAt := bouncerFields[1].Descriptor()
+	bouncerDescUpdatedAt := bouncerFields[2].Descriptor()
 	// bouncer.DefaultUpdatedAt holds the default value on creation for the updated_at field.
 	bouncer.DefaultUpdatedAt = bouncerDescUpdatedAt.Default.(func() time.Time)
 	// bouncer.UpdateDefaultUpdatedAt holds the default value on update for the updated_at field.
 	bouncer.UpdateDefaultUpdatedAt = bouncerDescUpdatedAt.UpdateDefault.(func() time.Time)
 	// bouncerDescIPAddress is the schema descriptor for ip_address field.
-	bouncerDescIPAddress := bouncerFields[5].Descriptor()
+	bouncerDescIPAddress := bouncerFields[6].Descriptor()
 	// bouncer.DefaultIPAddress holds the default value on creation for the ip_address field.
 	bouncer.DefaultIPAddress = bouncerDescIPAddress.Default.(string)
 	// bouncerDescAuthType is the schema descriptor for auth_type field.
-	bouncerDescAuthType := bouncerFields[9].Descriptor()
+	bouncerDescAuthType := bouncerFields[10].Descriptor()
 	// bouncer.DefaultAuthType holds the default value on creation for the auth_type field.
 	bouncer.DefaultAuthType = bouncerDescAuthType.Default.(string)
 	// bouncerDescAutoCreated is the schema descriptor for auto_created field.
-	bouncerDescAutoCreated := bouncerFields[14].Descriptor()
+	bouncerDescAutoCreated := bouncerFields[15].Descriptor()
 	// bouncer.DefaultAutoCreated holds the default value on creation for the auto_created field.
 	bouncer.DefaultAutoCreated = bouncerDescAutoCreated.Default.(bool)
 	configitemFields := schema.ConfigItem{}.Fields()
@@ -121,17 +140,17 @@ func init() {
 	decisionFields := schema.Decision{}.Fields()
 	_ = decisionFields
 	// decisionDescCreatedAt is the schema descriptor for created_at field.
-	decisionDescCreatedAt := decisionFields[0].Descriptor()
+	decisionDescCreatedAt := decisionFields[1].Descriptor()
 	// decision.DefaultCreatedAt holds the default value on creation for the created_at field.
 	decision.DefaultCreatedAt = decisionDescCreatedAt.Default.(func() time.Time)
 	// decisionDescUpdatedAt is the schema descriptor for updated_at field.
-	decisionDescUpdatedAt := decisionFields[1].Descriptor()
+	decisionDescUpdatedAt := decisionFields[2].Descriptor()
 	// decision.DefaultUpdatedAt holds the default value on creation for the updated_at field.
 	decision.DefaultUpdatedAt = decisionDescUpdatedAt.Default.(func() time.Time)
 	// decision.UpdateDefaultUpdatedAt holds the default value on update for the updated_at field.
 	decision.UpdateDefaultUpdatedAt = decisionDescUpdatedAt.UpdateDefault.(func() time.Time)
 	// decisionDescSimulated is the schema descriptor for simulated field.
-	decisionDescSimulated := decisionFields[13].Descriptor()
+	decisionDescSimulated := decisionFields[14].Descriptor()
 	// decision.DefaultSimulated holds the default value on creation for the simulated field.
 	decision.DefaultSimulated = decisionDescSimulated.Default.(bool)
 	eventFields := schema.Event{}.Fields()
@@ -16,6 +16,8 @@ type Alert struct {
 // Fields of the Alert.
 func (Alert) Fields() []ent.Field {
 	return []ent.Field{
+		// auto-incremented, declared to be kept by the migrations to another database
+		field.Int("id"),
 		field.Time("created_at").
 			Default(UtcNow).
 			Immutable(),
@@ -15,6 +15,8 @@ type AllowListItem struct {
 // Fields of the AllowListItem.
 func (AllowListItem) Fields() []ent.Field {
 	return []ent.Field{
+		// like the alert id
+		field.Int("id"),
 		field.Time("created_at").
 			Default(UtcNow).
 			Immutable(),
@@ -0,0 +1,52 @@
+package schema
+
+import (
+	"entgo.io/ent"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+)
+
+// AuditLog is a record of a change made through the local API or cscli.
+// The records are never updated, they are only deleted by the flush scheduler.
+type AuditLog struct {
+	ent.Schema
+}
+
+func (AuditLog) Fields() []ent.Field {
+	return []ent.Field{
+		field.Time("created_at").
+			Default(UtcNow).
+			Immutable(),
+		field.Enum("actor_type").
+			Values("machine", "bouncer", "cscli", "papi").
+			Immutable().
+			Comment("Who made the change: a machine or bouncer through the API, cscli with direct database access, or the console through PAPI"),
+		field.String("actor").
+			Immutable().
+			Comment("Machine id, bouncer name, local user..."),
+		field.String("ip_address").
+			Default("").
+			Optional().
+			Immutable(),
+		field.String("action").
+			Immutable().
+			Comment("What was done, for example decisions.delete"),
+		field.Text("target").
+			Default("").
+			Optional().
+			Immutable().
+			Comment("The filter or the name of the modified items"),
+		field.Int("count").
+			Default(0).
+			Immutable().
+			Comment("Number of modified items"),
+	}
+}
+
+func (AuditLog) Indexes() []ent.Index {
+	return []ent.Index{
+		index.Fields("created_at"),
+		index.Fields("actor_type", "actor"),
+		index.Fields("action"),
+	}
+}
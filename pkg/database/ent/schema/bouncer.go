@@ -16,6 +16,8 @@ type Bouncer struct {
 // Fields of the Bouncer.
 func (Bouncer) Fields() []ent.Field {
 	return []ent.Field{
+		// like the alert id
+		field.Int("id"),
 		field.Time("created_at").
 			Default(UtcNow).
 			StructTag(`json:"created_at"`).
@@ -37,6 +39,10 @@ func (Bouncer) Fields() []ent.Field {
 		field.String("featureflags").Optional(),
 		// Old auto-created TLS bouncers will have a wrong value for this field
 		field.Bool("auto_created").StructTag(`json:"auto_created"`).Default(false).Immutable(),
+		// restrict the decisions sent to the bouncer, empty means no restriction
+		field.Strings("allowed_scopes").Optional().StructTag(`json:"allowed_scopes,omitempty"`),
+		field.Strings("allowed_origins").Optional().StructTag(`json:"allowed_origins,omitempty"`),
+		field.Strings("allowed_scenarios").Optional().StructTag(`json:"allowed_scenarios,omitempty"`),
 	}
 }
 
@@ -16,6 +16,8 @@ type Decision struct {
 // Fields of the Decision.
 func (Decision) Fields() []ent.Field {
 	return []ent.Field{
+		// like the alert id
+		field.Int("id"),
 		field.Time("created_at").
 			Default(UtcNow).
 			Immutable(),
@@ -51,6 +51,8 @@ func (Machine) Fields() []ent.Field {
 		field.String("featureflags").Optional(),
 		field.JSON("hubstate", map[string][]ItemState{}).Optional(),
 		field.JSON("datasources", map[string]int64{}).Optional(),
+		// no roles means all permissions, for compatibility with the machines created before roles existed
+		field.Strings("roles").Optional().StructTag(`json:"roles,omitempty"`),
 	}
 }
 
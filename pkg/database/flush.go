@@ -125,6 +125,15 @@ func (c *Client) StartFlushScheduler(ctx context.Context, config *csconfig.Flush
 		return nil, fmt.Errorf("while starting flushMetrics scheduler: %w", err)
 	}
 
+	_, err = scheduler.NewJob(
+		gocron.DurationJob(flushInterval),
+		gocron.NewTask(c.flushAuditLog, ctx, time.Duration(config.AuditMaxAge)),
+		gocron.WithSingletonMode(gocron.LimitModeReschedule),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("while starting flushAuditLog scheduler: %w", err)
+	}
+
 	_, err = scheduler.NewJob(
 		gocron.DurationJob(flushInterval),
 		gocron.NewTask(c.flushAllowlists, ctx),
@@ -281,10 +290,10 @@ func (c *Client) FlushAlerts(ctx context.Context, maxAge time.Duration, maxItems
 
 		// Delete alerts older than maxAge, but never one that still has an
 		// active decision (the cascade would take the live decision with it).
-		nbDeleted, err := c.Ent.Alert.Delete().Where(
+		nbDeleted, err := c.archiveAndDeleteAlerts(ctx,
 			alert.CreatedAtLTE(now.Add(-maxAge)),
 			alertWithoutActiveDecision(now),
-		).Exec(ctx)
+		)
 		if err != nil {
 			c.Log.Warningf("FlushAlerts (max age): %s", err)
 			return fmt.Errorf("unable to flush alerts older than %s: %w", maxAge, err)
@@ -322,10 +331,10 @@ func (c *Client) FlushAlerts(ctx context.Context, maxAge time.Duration, maxItems
 				// This may lead to orphan alerts (at least on MySQL), but the next time the flush job will run, they will be deleted
 				// Alerts that still carry an active decision are kept regardless of the count: deleting them would
 				// cascade-delete the live decision. They are flushed on a later run, once their decisions expire.
-				deletedByNbItem, err = c.Ent.Alert.Delete().Where(
+				deletedByNbItem, err = c.archiveAndDeleteAlerts(ctx,
 					alert.IDLT(maxid),
 					alertWithoutActiveDecision(time.Now().UTC()),
-				).Exec(ctx)
+				)
 				if err != nil {
 					c.Log.Errorf("FlushAlerts: Could not delete alerts: %s", err)
 					return fmt.Errorf("could not delete alerts: %w", err)
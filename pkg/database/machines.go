@@ -92,6 +92,8 @@ func (c *Client) CreateMachine(ctx context.Context, machineID *string, password
 				return nil, fmt.Errorf("machine '%s': %w: %w", *machineID, err, QueryFail)
 			}
 
+			c.audit(ctx, AuditMachinesCreate, *machineID+" (password reset)", 1)
+
 			return machine, nil
 		}
 
@@ -111,6 +113,8 @@ func (c *Client) CreateMachine(ctx context.Context, machineID *string, password
 		return nil, fmt.Errorf("creating machine '%s': %w", *machineID, InsertFail)
 	}
 
+	c.audit(ctx, AuditMachinesCreate, *machineID, 1)
+
 	return machine, nil
 }
 
@@ -146,6 +150,8 @@ func (c *Client) ValidateMachine(ctx context.Context, machineID string) error {
 		return errors.New("machine not found")
 	}
 
+	c.audit(ctx, AuditMachinesValidate, machineID, rets)
+
 	return nil
 }
 
@@ -172,13 +178,18 @@ func (c *Client) DeleteWatcher(ctx context.Context, name string) error {
 		return &MachineNotFoundError{MachineID: name}
 	}
 
+	c.audit(ctx, AuditMachinesDelete, name, nbDeleted)
+
 	return nil
 }
 
 func (c *Client) BulkDeleteWatchers(ctx context.Context, machines []*ent.Machine) (int, error) {
 	ids := make([]int, len(machines))
+	names := make([]string, len(machines))
+
 	for i, b := range machines {
 		ids[i] = b.ID
+		names[i] = b.MachineId
 	}
 
 	nbDeleted, err := c.Ent.Machine.Delete().Where(machine.IDIn(ids...)).Exec(ctx)
@@ -186,6 +197,8 @@ func (c *Client) BulkDeleteWatchers(ctx context.Context, machines []*ent.Machine
 		return nbDeleted, err
 	}
 
+	c.audit(ctx, AuditMachinesDelete, strings.Join(names, ","), nbDeleted)
+
 	return nbDeleted, nil
 }
 
@@ -232,6 +245,25 @@ func (c *Client) UpdateMachineVersion(ctx context.Context, ipAddr string, id int
 	return nil
 }
 
+// UpdateMachineRoles replaces the roles of a machine. No role means all permissions.
+func (c *Client) UpdateMachineRoles(ctx context.Context, machineID string, roles []string) error {
+	rets, err := c.Ent.Machine.Update().
+		Where(machine.MachineIdEQ(machineID)).
+		SetRoles(roles).
+		Save(ctx)
+	if err != nil {
+		return fmt.Errorf("updating machine roles: %w: %w", err, UpdateFail)
+	}
+
+	if rets == 0 {
+		return &MachineNotFoundError{MachineID: machineID}
+	}
+
+	c.audit(ctx, AuditMachinesSetRoles, machineID+" roles="+strings.Join(roles, ","), rets)
+
+	return nil
+}
+
 func (c *Client) QueryMachinesInactiveSince(ctx context.Context, t time.Time) ([]*ent.Machine, error) {
 	return c.Ent.Machine.Query().Where(
 		machine.Or(
@@ -0,0 +1,678 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/crowdsecurity/go-cs-lib/slicetools"
+
+	"github.com/crowdsecurity/crowdsec/pkg/database/ent"
+	"github.com/crowdsecurity/crowdsec/pkg/database/ent/alert"
+	"github.com/crowdsecurity/crowdsec/pkg/database/ent/allowlist"
+	"github.com/crowdsecurity/crowdsec/pkg/database/ent/allowlistitem"
+	"github.com/crowdsecurity/crowdsec/pkg/database/ent/auditlog"
+	"github.com/crowdsecurity/crowdsec/pkg/database/ent/bouncer"
+	"github.com/crowdsecurity/crowdsec/pkg/database/ent/configitem"
+	"github.com/crowdsecurity/crowdsec/pkg/database/ent/decision"
+	"github.com/crowdsecurity/crowdsec/pkg/database/ent/event"
+	"github.com/crowdsecurity/crowdsec/pkg/database/ent/machine"
+	"github.com/crowdsecurity/crowdsec/pkg/database/ent/meta"
+	"github.com/crowdsecurity/crowdsec/pkg/database/ent/metric"
+)
+
+const (
+	// config item of the target database, with the last copied id of each entity
+	migrateProgressKey = "migration_progress"
+
+	DefaultMigrateBatchSize = 500
+	// we need an upper bound due to the sqlite limit of 32k variables in a query
+	maxMigrateBatchSize = 1000
+)
+
+// MigrateResult is the outcome of a migration for one entity.
+type MigrateResult struct {
+	Entity string `json:"entity"`
+	// rows copied by this run
+	Copied int `json:"copied"`
+	// rows in each database after the copy
+	Source int `json:"source"`
+	Target int `json:"target"`
+}
+
+// migrateStep copies one entity, by batches in the order of the source ids.
+type migrateStep struct {
+	entity string
+	// copy copies up to limit rows with an id greater than after, in the
+	// transaction, and returns the last copied id and the number of rows.
+	copy func(ctx context.Context, src *ent.Client, tx *ent.Tx, after int, limit int) (int, int, error)
+}
+
+// migrateCount counts the rows that a migration is expected to copy.
+type migrateCount struct {
+	entity string
+	count  func(ctx context.Context, c *ent.Client) (int, error)
+}
+
+var migrateSteps = []migrateStep{
+	{"machines", migrateMachines},
+	{"bouncers", migrateBouncers},
+	{"config_items", migrateConfigItems},
+	{"allowlists", migrateAllowLists},
+	{"alerts", migrateAlerts},
+	{"orphan_decisions", migrateOrphanDecisions},
+	{"metrics", migrateMetrics},
+	{"audit_logs", migrateAuditLogs},
+}
+
+var migrateCounts = []migrateCount{
+	{"machines", func(ctx context.Context, c *ent.Client) (int, error) {
+		return c.Machine.Query().Count(ctx)
+	}},
+	{"bouncers", func(ctx context.Context, c *ent.Client) (int, error) {
+		return c.Bouncer.Query().Count(ctx)
+	}},
+	{"config_items", func(ctx context.Context, c *ent.Client) (int, error) {
+		return c.ConfigItem.Query().Where(configitem.NameNEQ(migrateProgressKey)).Count(ctx)
+	}},
+	{"allowlists", func(ctx context.Context, c *ent.Client) (int, error) {
+		return c.AllowList.Query().Count(ctx)
+	}},
+	{"allowlist_items", func(ctx context.Context, c *ent.Client) (int, error) {
+		return c.AllowListItem.Query().Count(ctx)
+	}},
+	{"alerts", func(ctx context.Context, c *ent.Client) (int, error) {
+		return c.Alert.Query().Count(ctx)
+	}},
+	{"decisions", func(ctx context.Context, c *ent.Client) (int, error) {
+		return c.Decision.Query().Count(ctx)
+	}},
+	// orphan events and meta are not copied, they would be flushed anyway
+	{"events", func(ctx context.Context, c *ent.Client) (int, error) {
+		return c.Event.Query().Where(event.HasOwner()).Count(ctx)
+	}},
+	{"metas", func(ctx context.Context, c *ent.Client) (int, error) {
+		return c.Meta.Query().Where(meta.HasOwner()).Count(ctx)
+	}},
+	{"metrics", func(ctx context.Context, c *ent.Client) (int, error) {
+		return c.Metric.Query().Count(ctx)
+	}},
+	{"audit_logs", func(ctx context.Context, c *ent.Client) (int, error) {
+		return c.AuditLog.Query().Count(ctx)
+	}},
+}
+
+// MigrateTo copies the content of the database to another one, which can use a
+// different backend. Relations are kept. The ids of the alerts, decisions,
+// bouncers and allowlist items are kept too, since they are shown to the users
+// and used in the cscli commands; the other ids are assigned by the target.
+//
+// Each batch is committed with the last copied id of its entity, so an
+// interrupted migration can run again and resume. For the same reason, running
+// it again later copies the rows created in the meantime; rows modified or
+// deleted in the source are not updated. The target must be empty for the first
+// run, and should not be used in between: its new rows could take the ids of
+// the rows to copy. The results compare the number of rows in both databases,
+// the caller decides what a difference means.
+func (c *Client) MigrateTo(ctx context.Context, dst *Client, batchSize int, progress func(entity string, copied int)) ([]MigrateResult, error) {
+	if batchSize <= 0 {
+		batchSize = DefaultMigrateBatchSize
+	}
+
+	batchSize = min(batchSize, maxMigrateBatchSize)
+
+	done, err := dst.migrateProgress(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	before, err := countRows(ctx, dst.Ent)
+	if err != nil {
+		return nil, fmt.Errorf("counting target rows: %w", err)
+	}
+
+	if done == nil {
+		for _, n := range before {
+			if n > 0 {
+				return nil, errors.New("the target database is not empty, and was not the target of a previous migration")
+			}
+		}
+
+		done = map[string]int{}
+	}
+
+	for _, step := range migrateSteps {
+		copied := 0
+
+		for {
+			n, err := c.migrateBatch(ctx, dst, step, done, batchSize)
+			if err != nil {
+				return nil, fmt.Errorf("copying %s: %w", step.entity, err)
+			}
+
+			if n == 0 {
+				break
+			}
+
+			copied += n
+
+			if progress != nil {
+				progress(step.entity, copied)
+			}
+		}
+	}
+
+	if err := dst.resetSequences(ctx); err != nil {
+		return nil, fmt.Errorf("resetting the id sequences: %w", err)
+	}
+
+	srcCounts, err := countRows(ctx, c.Ent)
+	if err != nil {
+		return nil, fmt.Errorf("counting source rows: %w", err)
+	}
+
+	dstCounts, err := countRows(ctx, dst.Ent)
+	if err != nil {
+		return nil, fmt.Errorf("counting target rows: %w", err)
+	}
+
+	results := make([]MigrateResult, len(migrateCounts))
+
+	for i, mc := range migrateCounts {
+		results[i] = MigrateResult{
+			Entity: mc.entity,
+			Copied: dstCounts[i] - before[i],
+			Source: srcCounts[i],
+			Target: dstCounts[i],
+		}
+	}
+
+	return results, nil
+}
+
+// countRows returns the number of rows of each entity, in the order of migrateCounts.
+func countRows(ctx context.Context, c *ent.Client) ([]int, error) {
+	counts := make([]int, len(migrateCounts))
+
+	for i, mc := range migrateCounts {
+		n, err := mc.count(ctx, c)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", mc.entity, err)
+		}
+
+		counts[i] = n
+	}
+
+	return counts, nil
+}
+
+// migrateBatch copies one batch of an entity, and records the progress in the same transaction.
+func (c *Client) migrateBatch(ctx context.Context, dst *Client, step migrateStep, done map[string]int, batchSize int) (int, error) {
+	tx, err := dst.Ent.Tx(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	last, n, err := step.copy(ctx, c.Ent, tx, done[step.entity], batchSize)
+	if err != nil {
+		return 0, rollbackOnError(tx, err, fmt.Sprintf("after id %d", done[step.entity]))
+	}
+
+	if n == 0 {
+		return 0, tx.Rollback()
+	}
+
+	done[step.entity] = last
+
+	value, err := json.Marshal(done)
+	if err != nil {
+		return 0, rollbackOnError(tx, err, "serializing progress")
+	}
+
+	err = tx.ConfigItem.Create().
+		SetName(migrateProgressKey).
+		SetValue(string(value)).
+		OnConflictColumns(configitem.FieldName).
+		UpdateNewValues().
+		Exec(ctx)
+	if err != nil {
+		return 0, rollbackOnError(tx, err, "saving progress")
+	}
+
+	return n, tx.Commit()
+}
+
+// resetSequences moves the id sequences of postgres after the ids copied by
+// the migration. With mysql and sqlite, the next id already follows the
+// greatest one.
+func (c *Client) resetSequences(ctx context.Context) error {
+	switch c.Type {
+	case "postgres", "postgresql", "pgx":
+	default:
+		return nil
+	}
+
+	for _, table := range []string{alert.Table, decision.Table, bouncer.Table, allowlistitem.Table} {
+		query := fmt.Sprintf("SELECT setval(pg_get_serial_sequence('%[1]s', 'id'), COALESCE(MAX(id), 0) + 1, false) FROM %[1]s", table)
+		if _, err := c.Ent.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("%s: %w", table, err)
+		}
+	}
+
+	return nil
+}
+
+// migrateProgress returns the last copied id of each entity, or nil if the
+// database was never the target of a migration.
+func (c *Client) migrateProgress(ctx context.Context) (map[string]int, error) {
+	value, err := c.GetConfigItem(ctx, migrateProgressKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if value == "" {
+		return nil, nil
+	}
+
+	done := map[string]int{}
+	if err := json.Unmarshal([]byte(value), &done); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", migrateProgressKey, err)
+	}
+
+	return done, nil
+}
+
+func migrateMachines(ctx context.Context, src *ent.Client, tx *ent.Tx, after int, limit int) (int, int, error) {
+	rows, err := src.Machine.Query().Where(machine.IDGT(after)).Order(ent.Asc(machine.FieldID)).Limit(limit).All(ctx)
+	if err != nil || len(rows) == 0 {
+		return 0, 0, err
+	}
+
+	builders := make([]*ent.MachineCreate, len(rows))
+	for i, m := range rows {
+		builders[i] = tx.Machine.Create().
+			SetCreatedAt(m.CreatedAt).
+			SetUpdatedAt(m.UpdatedAt).
+			SetNillableLastPush(m.LastPush).
+			SetNillableLastHeartbeat(m.LastHeartbeat).
+			SetMachineId(m.MachineId).
+			SetPassword(m.Password).
+			SetIpAddress(m.IpAddress).
+			SetScenarios(m.Scenarios).
+			SetVersion(m.Version).
+			SetIsValidated(m.IsValidated).
+			SetAuthType(m.AuthType).
+			SetOsname(m.Osname).
+			SetOsfamily(m.Osfamily).
+			SetOsversion(m.Osversion).
+			SetFeatureflags(m.Featureflags).
+			SetHubstate(m.Hubstate).
+			SetDatasources(m.Datasources).
+			SetRoles(m.Roles)
+	}
+
+	if err := tx.Machine.CreateBulk(builders...).Exec(ctx); err != nil {
+		return 0, 0, err
+	}
+
+	return rows[len(rows)-1].ID, len(rows), nil
+}
+
+func migrateBouncers(ctx context.Context, src *ent.Client, tx *ent.Tx, after int, limit int) (int, int, error) {
+	rows, err := src.Bouncer.Query().Where(bouncer.IDGT(after)).Order(ent.Asc(bouncer.FieldID)).Limit(limit).All(ctx)
+	if err != nil || len(rows) == 0 {
+		return 0, 0, err
+	}
+
+	builders := make([]*ent.BouncerCreate, len(rows))
+	for i, b := range rows {
+		builders[i] = tx.Bouncer.Create().
+			SetID(b.ID).
+			SetCreatedAt(b.CreatedAt).
+			SetUpdatedAt(b.UpdatedAt).
+			SetName(b.Name).
+			SetAPIKey(b.APIKey).
+			SetRevoked(b.Revoked).
+			SetIPAddress(b.IPAddress).
+			SetType(b.Type).
+			SetVersion(b.Version).
+			SetNillableLastPull(b.LastPull).
+			SetAuthType(b.AuthType).
+			SetOsname(b.Osname).
+			SetOsfamily(b.Osfamily).
+			SetOsversion(b.Osversion).
+			SetFeatureflags(b.Featureflags).
+			SetAutoCreated(b.AutoCreated).
+			SetAllowedScopes(b.AllowedScopes).
+			SetAllowedOrigins(b.AllowedOrigins).
+			SetAllowedScenarios(b.AllowedScenarios)
+	}
+
+	if err := tx.Bouncer.CreateBulk(builders...).Exec(ctx); err != nil {
+		return 0, 0, err
+	}
+
+	return rows[len(rows)-1].ID, len(rows), nil
+}
+
+func migrateConfigItems(ctx context.Context, src *ent.Client, tx *ent.Tx, after int, limit int) (int, int, error) {
+	rows, err := src.ConfigItem.Query().
+		Where(configitem.IDGT(after), configitem.NameNEQ(migrateProgressKey)).
+		Order(ent.Asc(configitem.FieldID)).
+		Limit(limit).
+		All(ctx)
+	if err != nil || len(rows) == 0 {
+		return 0, 0, err
+	}
+
+	builders := make([]*ent.ConfigItemCreate, len(rows))
+	for i, item := range rows {
+		builders[i] = tx.ConfigItem.Create().
+			SetCreatedAt(item.CreatedAt).
+			SetUpdatedAt(item.UpdatedAt).
+			SetName(item.Name).
+			SetValue(item.Value)
+	}
+
+	if err := tx.ConfigItem.CreateBulk(builders...).Exec(ctx); err != nil {
+		return 0, 0, err
+	}
+
+	return rows[len(rows)-1].ID, len(rows), nil
+}
+
+func migrateAllowLists(ctx context.Context, src *ent.Client, tx *ent.Tx, after int, limit int) (int, int, error) {
+	rows, err := src.AllowList.Query().
+		Where(allowlist.IDGT(after)).
+		Order(ent.Asc(allowlist.FieldID)).
+		Limit(limit).
+		WithAllowlistItems().
+		All(ctx)
+	if err != nil || len(rows) == 0 {
+		return 0, 0, err
+	}
+
+	for _, l := range rows {
+		list, err := tx.AllowList.Create().
+			SetCreatedAt(l.CreatedAt).
+			SetUpdatedAt(l.UpdatedAt).
+			SetName(l.Name).
+			SetFromConsole(l.FromConsole).
+			SetDescription(l.Description).
+			SetAllowlistID(l.AllowlistID).
+			Save(ctx)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		if err := slicetools.Batch(ctx, l.Edges.AllowlistItems, limit, func(ctx context.Context, part []*ent.AllowListItem) error {
+			partIDs := make([]int, len(part))
+			for i, item := range part {
+				partIDs[i] = item.ID
+			}
+
+			// an item can belong to several allowlists, it's copied with the first one
+			existing, err := tx.AllowListItem.Query().Where(allowlistitem.IDIn(partIDs...)).IDs(ctx)
+			if err != nil {
+				return err
+			}
+
+			builders := []*ent.AllowListItemCreate{}
+
+			for _, item := range part {
+				if slices.Contains(existing, item.ID) {
+					continue
+				}
+
+				builder := tx.AllowListItem.Create().
+					SetID(item.ID).
+					SetCreatedAt(item.CreatedAt).
+					SetUpdatedAt(item.UpdatedAt).
+					SetComment(item.Comment).
+					SetValue(item.Value).
+					SetStartIP(item.StartIP).
+					SetEndIP(item.EndIP).
+					SetStartSuffix(item.StartSuffix).
+					SetEndSuffix(item.EndSuffix).
+					SetIPSize(item.IPSize)
+
+				// a zero time would be an expiration date in the past
+				if !item.ExpiresAt.IsZero() {
+					builder.SetExpiresAt(item.ExpiresAt)
+				}
+
+				builders = append(builders, builder)
+			}
+
+			if err := tx.AllowListItem.CreateBulk(builders...).Exec(ctx); err != nil {
+				return err
+			}
+
+			// the items are attached by batches too, to bound the size of the statement
+			return tx.AllowList.UpdateOneID(list.ID).AddAllowlistItemIDs(partIDs...).Exec(ctx)
+		}); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	return rows[len(rows)-1].ID, len(rows), nil
+}
+
+// migrateAlerts copies the alerts with their decisions, events and meta, and
+// attaches them to the machine with the same machine_id in the target.
+func migrateAlerts(ctx context.Context, src *ent.Client, tx *ent.Tx, after int, limit int) (int, int, error) {
+	rows, err := src.Alert.Query().
+		Where(alert.IDGT(after)).
+		Order(ent.Asc(alert.FieldID)).
+		Limit(limit).
+		WithOwner().
+		WithDecisions().
+		WithEvents().
+		WithMetas().
+		All(ctx)
+	if err != nil || len(rows) == 0 {
+		return 0, 0, err
+	}
+
+	machineIDs := []string{}
+
+	for _, a := range rows {
+		if a.Edges.Owner != nil {
+			machineIDs = append(machineIDs, a.Edges.Owner.MachineId)
+		}
+	}
+
+	owners, err := tx.Machine.Query().Where(machine.MachineIdIn(machineIDs...)).All(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	ownerByMachineID := make(map[string]int, len(owners))
+	for _, m := range owners {
+		ownerByMachineID[m.MachineId] = m.ID
+	}
+
+	builders := make([]*ent.AlertCreate, len(rows))
+	for i, a := range rows {
+		builders[i] = tx.Alert.Create().
+			SetID(a.ID).
+			SetCreatedAt(a.CreatedAt).
+			SetUpdatedAt(a.UpdatedAt).
+			SetScenario(a.Scenario).
+			SetBucketId(a.BucketId).
+			SetMessage(a.Message).
+			SetEventsCount(a.EventsCount).
+			SetStartedAt(a.StartedAt).
+			SetStoppedAt(a.StoppedAt).
+			SetSourceIp(a.SourceIp).
+			SetSourceRange(a.SourceRange).
+			SetSourceAsNumber(a.SourceAsNumber).
+			SetSourceAsName(a.SourceAsName).
+			SetSourceCountry(a.SourceCountry).
+			SetSourceLatitude(a.SourceLatitude).
+			SetSourceLongitude(a.SourceLongitude).
+			SetSourceScope(a.SourceScope).
+			SetSourceValue(a.SourceValue).
+			SetCapacity(a.Capacity).
+			SetLeakSpeed(a.LeakSpeed).
+			SetScenarioVersion(a.ScenarioVersion).
+			SetScenarioHash(a.ScenarioHash).
+			SetSimulated(a.Simulated).
+			SetUUID(a.UUID).
+			SetRemediation(a.Remediation).
+			SetKind(a.Kind)
+
+		if a.Edges.Owner != nil {
+			if id, ok := ownerByMachineID[a.Edges.Owner.MachineId]; ok {
+				builders[i].SetOwnerID(id)
+			}
+		}
+	}
+
+	if err := tx.Alert.CreateBulk(builders...).Exec(ctx); err != nil {
+		return 0, 0, err
+	}
+
+	var (
+		decisions []*ent.DecisionCreate
+		events    []*ent.EventCreate
+		metas     []*ent.MetaCreate
+	)
+
+	for _, a := range rows {
+		for _, d := range a.Edges.Decisions {
+			decisions = append(decisions, decisionCopy(tx, d).SetOwnerID(a.ID))
+		}
+
+		for _, e := range a.Edges.Events {
+			events = append(events, tx.Event.Create().
+				SetCreatedAt(e.CreatedAt).
+				SetUpdatedAt(e.UpdatedAt).
+				SetTime(e.Time).
+				SetSerialized(e.Serialized).
+				SetOwnerID(a.ID))
+		}
+
+		for _, m := range a.Edges.Metas {
+			metas = append(metas, tx.Meta.Create().
+				SetCreatedAt(m.CreatedAt).
+				SetUpdatedAt(m.UpdatedAt).
+				SetKey(m.Key).
+				SetValue(m.Value).
+				SetOwnerID(a.ID))
+		}
+	}
+
+	if err := slicetools.Batch(ctx, decisions, limit, func(ctx context.Context, part []*ent.DecisionCreate) error {
+		return tx.Decision.CreateBulk(part...).Exec(ctx)
+	}); err != nil {
+		return 0, 0, fmt.Errorf("decisions: %w", err)
+	}
+
+	if err := slicetools.Batch(ctx, events, limit, func(ctx context.Context, part []*ent.EventCreate) error {
+		return tx.Event.CreateBulk(part...).Exec(ctx)
+	}); err != nil {
+		return 0, 0, fmt.Errorf("events: %w", err)
+	}
+
+	if err := slicetools.Batch(ctx, metas, limit, func(ctx context.Context, part []*ent.MetaCreate) error {
+		return tx.Meta.CreateBulk(part...).Exec(ctx)
+	}); err != nil {
+		return 0, 0, fmt.Errorf("metas: %w", err)
+	}
+
+	return rows[len(rows)-1].ID, len(rows), nil
+}
+
+func decisionCopy(tx *ent.Tx, d *ent.Decision) *ent.DecisionCreate {
+	return tx.Decision.Create().
+		SetID(d.ID).
+		SetCreatedAt(d.CreatedAt).
+		SetUpdatedAt(d.UpdatedAt).
+		SetNillableUntil(d.Until).
+		SetScenario(d.Scenario).
+		SetType(d.Type).
+		SetStartIP(d.StartIP).
+		SetEndIP(d.EndIP).
+		SetStartSuffix(d.StartSuffix).
+		SetEndSuffix(d.EndSuffix).
+		SetIPSize(d.IPSize).
+		SetScope(d.Scope).
+		SetValue(d.Value).
+		SetOrigin(d.Origin).
+		SetSimulated(d.Simulated).
+		SetUUID(d.UUID)
+}
+
+// migrateOrphanDecisions copies the decisions that are not attached to an alert.
+func migrateOrphanDecisions(ctx context.Context, src *ent.Client, tx *ent.Tx, after int, limit int) (int, int, error) {
+	rows, err := src.Decision.Query().
+		Where(decision.IDGT(after), decision.Not(decision.HasOwner())).
+		Order(ent.Asc(decision.FieldID)).
+		Limit(limit).
+		All(ctx)
+	if err != nil || len(rows) == 0 {
+		return 0, 0, err
+	}
+
+	builders := make([]*ent.DecisionCreate, len(rows))
+	for i, d := range rows {
+		builders[i] = decisionCopy(tx, d)
+	}
+
+	if err := tx.Decision.CreateBulk(builders...).Exec(ctx); err != nil {
+		return 0, 0, err
+	}
+
+	return rows[len(rows)-1].ID, len(rows), nil
+}
+
+func migrateMetrics(ctx context.Context, src *ent.Client, tx *ent.Tx, after int, limit int) (int, int, error) {
+	rows, err := src.Metric.Query().Where(metric.IDGT(after)).Order(ent.Asc(metric.FieldID)).Limit(limit).All(ctx)
+	if err != nil || len(rows) == 0 {
+		return 0, 0, err
+	}
+
+	builders := make([]*ent.MetricCreate, len(rows))
+	for i, m := range rows {
+		builders[i] = tx.Metric.Create().
+			SetGeneratedType(m.GeneratedType).
+			SetGeneratedBy(m.GeneratedBy).
+			SetReceivedAt(m.ReceivedAt).
+			SetNillablePushedAt(m.PushedAt).
+			SetPayload(m.Payload)
+	}
+
+	if err := tx.Metric.CreateBulk(builders...).Exec(ctx); err != nil {
+		return 0, 0, err
+	}
+
+	return rows[len(rows)-1].ID, len(rows), nil
+}
+
+func migrateAuditLogs(ctx context.Context, src *ent.Client, tx *ent.Tx, after int, limit int) (int, int, error) {
+	rows, err := src.AuditLog.Query().Where(auditlog.IDGT(after)).Order(ent.Asc(auditlog.FieldID)).Limit(limit).All(ctx)
+	if err != nil || len(rows) == 0 {
+		return 0, 0, err
+	}
+
+	builders := make([]*ent.AuditLogCreate, len(rows))
+	for i, l := range rows {
+		builders[i] = tx.AuditLog.Create().
+			SetCreatedAt(l.CreatedAt).
+			SetActorType(l.ActorType).
+			SetActor(l.Actor).
+			SetIPAddress(l.IPAddress).
+			SetAction(l.Action).
+			SetTarget(l.Target).
+			SetCount(l.Count)
+	}
+
+	if err := tx.AuditLog.CreateBulk(builders...).Exec(ctx); err != nil {
+		return 0, 0, err
+	}
+
+	return rows[len(rows)-1].ID, len(rows), nil
+}
@@ -0,0 +1,201 @@
+package database
+
+import (
+	"context"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/crowdsecurity/go-cs-lib/cstest"
+
+	"github.com/crowdsecurity/crowdsec/pkg/csconfig"
+	"github.com/crowdsecurity/crowdsec/pkg/database/ent"
+	"github.com/crowdsecurity/crowdsec/pkg/database/ent/alert"
+	"github.com/crowdsecurity/crowdsec/pkg/database/ent/auditlog"
+	"github.com/crowdsecurity/crowdsec/pkg/database/ent/metric"
+	"github.com/crowdsecurity/crowdsec/pkg/models"
+	"github.com/crowdsecurity/crowdsec/pkg/types"
+)
+
+func getFileDBClient(t *testing.T, ctx context.Context) *Client {
+	t.Helper()
+
+	dbClient, err := NewClient(ctx, &csconfig.DatabaseCfg{
+		Type:   "sqlite",
+		DbName: "crowdsec",
+		DbPath: filepath.Join(t.TempDir(), "crowdsec.db"),
+	}, nil)
+	require.NoError(t, err)
+
+	return dbClient
+}
+
+func migrateResultsByEntity(results []MigrateResult) map[string]MigrateResult {
+	ret := make(map[string]MigrateResult, len(results))
+	for _, r := range results {
+		ret[r.Entity] = r
+	}
+
+	return ret
+}
+
+func TestMigrateTo(t *testing.T) {
+	ctx := t.Context()
+	src := getFileDBClient(t, ctx)
+	dst := getFileDBClient(t, ctx)
+
+	// shift the ids of the source, to check they are kept
+	registerFlushTestMachine(t, ctx, src, "deleted")
+	_, err := src.CreateAlert(ctx, "deleted", []*models.Alert{makeFlushAlert("192.0.2.99", true)})
+	require.NoError(t, err)
+	_, err = src.Ent.Alert.Delete().Exec(ctx)
+	require.NoError(t, err)
+	_, err = src.Ent.Decision.Delete().Exec(ctx)
+	require.NoError(t, err)
+	err = src.DeleteWatcher(ctx, "deleted")
+	require.NoError(t, err)
+	_, err = src.CreateBouncer(ctx, "deleted", "127.0.0.1", "deleted", types.ApiKeyAuthType, false)
+	require.NoError(t, err)
+	err = src.DeleteBouncer(ctx, "deleted")
+	require.NoError(t, err)
+
+	registerFlushTestMachine(t, ctx, src, "machine1")
+	registerFlushTestMachine(t, ctx, src, "machine2")
+
+	srcBouncer, err := src.CreateBouncer(ctx, "bouncer1", "127.0.0.1", "hash", types.ApiKeyAuthType, false)
+	require.NoError(t, err)
+
+	require.NoError(t, src.SetConfigItem(ctx, "some_key", `{"a": 1}`))
+
+	list, err := src.CreateAllowList(ctx, "list1", "test", "", false)
+	require.NoError(t, err)
+	_, err = src.AddToAllowlist(ctx, list, []*models.AllowlistItem{
+		{CreatedAt: strfmt.DateTime(time.Now()), Value: "198.51.100.1"},
+		{CreatedAt: strfmt.DateTime(time.Now()), Value: "198.51.100.0/24", Expiration: strfmt.DateTime(time.Now().Add(time.Hour))},
+	})
+	require.NoError(t, err)
+
+	// the items are shared with another list
+	list, err = src.GetAllowList(ctx, "list1", true)
+	require.NoError(t, err)
+	list2, err := src.CreateAllowList(ctx, "list2", "test", "", false)
+	require.NoError(t, err)
+	err = src.Ent.AllowList.UpdateOne(list2).AddAllowlistItems(list.Edges.AllowlistItems...).Exec(ctx)
+	require.NoError(t, err)
+
+	withDetails := makeFlushAlert("192.0.2.1", true)
+	withDetails.Meta = models.Meta{{Key: "target_fqdn", Value: "example.com"}}
+	withDetails.Events = []*models.Event{{
+		Timestamp: new(time.Now().UTC().Format(time.RFC3339)),
+		Meta:      models.Meta{{Key: "log_type", Value: "ssh_failed-auth"}},
+	}}
+
+	_, err = src.CreateAlert(ctx, "machine1", []*models.Alert{withDetails, makeFlushAlert("192.0.2.2", false)})
+	require.NoError(t, err)
+	_, err = src.CreateAlert(ctx, "machine2", []*models.Alert{makeFlushAlert("192.0.2.3", true)})
+	require.NoError(t, err)
+
+	_, err = src.CreateMetric(ctx, metric.GeneratedTypeLP, "machine1", time.Now().UTC(), "{}")
+	require.NoError(t, err)
+
+	err = src.Ent.AuditLog.Create().
+		SetActorType(auditlog.ActorTypeCscli).
+		SetActor("root").
+		SetAction(AuditAlertsDelete).
+		SetCount(1).
+		Exec(ctx)
+	require.NoError(t, err)
+
+	progress := map[string]int{}
+
+	results, err := src.MigrateTo(ctx, dst, 1, func(entity string, copied int) {
+		progress[entity] = copied
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]int{
+		"machines":     2,
+		"bouncers":     1,
+		"config_items": 1,
+		"allowlists":   2,
+		"alerts":       3,
+		"metrics":      1,
+		"audit_logs":   1,
+	}, progress)
+
+	byEntity := migrateResultsByEntity(results)
+	for _, r := range results {
+		assert.Equal(t, r.Source, r.Target, r.Entity)
+		assert.Equal(t, r.Source, r.Copied, r.Entity)
+	}
+
+	assert.Equal(t, 2, byEntity["decisions"].Target)
+	assert.Equal(t, 2, byEntity["allowlist_items"].Target)
+	assert.Equal(t, 1, byEntity["events"].Target)
+
+	orig, err := src.Ent.Alert.Query().Where(alert.SourceValueEQ("192.0.2.1")).WithDecisions().Only(ctx)
+	require.NoError(t, err)
+
+	copied, err := dst.Ent.Alert.Query().
+		Where(alert.SourceValueEQ("192.0.2.1")).
+		WithOwner().
+		WithDecisions().
+		WithEvents().
+		WithMetas().
+		Only(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, orig.ID, copied.ID)
+	assert.Equal(t, "machine1", copied.Edges.Owner.MachineId)
+	require.Len(t, copied.Edges.Decisions, 1)
+	assert.Equal(t, orig.Edges.Decisions[0].ID, copied.Edges.Decisions[0].ID)
+	assert.Len(t, copied.Edges.Events, 1)
+	require.Len(t, copied.Edges.Metas, 1)
+	assert.Equal(t, "example.com", copied.Edges.Metas[0].Value)
+
+	b, err := dst.SelectBouncerByName(ctx, "bouncer1")
+	require.NoError(t, err)
+	assert.Equal(t, "hash", b.APIKey)
+	assert.Equal(t, srcBouncer.ID, b.ID)
+
+	value, err := dst.GetConfigItem(ctx, "some_key")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"a": 1}`, value)
+
+	allowlisted, _, err := dst.IsAllowlisted(ctx, "198.51.100.7")
+	require.NoError(t, err)
+	assert.True(t, allowlisted)
+
+	list2, err = dst.GetAllowList(ctx, "list2", true)
+	require.NoError(t, err)
+	assert.Len(t, list2.Edges.AllowlistItems, 2)
+
+	// a new run only copies what was added in the meantime
+	_, err = src.CreateAlert(ctx, "machine2", []*models.Alert{makeFlushAlert("192.0.2.4", false)})
+	require.NoError(t, err)
+
+	results, err = src.MigrateTo(ctx, dst, 0, nil)
+	require.NoError(t, err)
+
+	byEntity = migrateResultsByEntity(results)
+	assert.Equal(t, 1, byEntity["alerts"].Copied)
+	assert.Equal(t, 0, byEntity["machines"].Copied)
+	assert.Equal(t, 4, byEntity["alerts"].Target)
+
+	// the sequences follow the copied ids
+	ids, err := dst.CreateAlert(ctx, "machine1", []*models.Alert{makeFlushAlert("192.0.2.5", true)})
+	require.NoError(t, err)
+	require.Len(t, ids, 1)
+
+	last, err := src.Ent.Alert.Query().Aggregate(ent.Max(alert.FieldID)).Int(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, strconv.Itoa(last+1), ids[0])
+
+	// a database which was not migrated to is not overwritten
+	_, err = dst.MigrateTo(ctx, src, 0, nil)
+	cstest.RequireErrorContains(t, err, "the target database is not empty")
+}
@@ -0,0 +1,406 @@
+// Package decisionexport renders the decisions as blocklists, for the firewalls and DNS servers
+// that can't run a bouncer but can download a list.
+package decisionexport
+
+import (
+	"bufio"
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/netip"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/crowdsecurity/go-cs-lib/ptr"
+
+	"github.com/crowdsecurity/crowdsec/pkg/models"
+)
+
+const (
+	// FormatPlain is one address or range per line
+	FormatPlain = "plain"
+	// FormatNFTables is an nft script that fills an IPv4 and an IPv6 set
+	FormatNFTables = "nftables"
+	// FormatIPSet is an "ipset restore" file that fills an IPv4 and an IPv6 set
+	FormatIPSet = "ipset"
+	// FormatMikroTik is a RouterOS script that fills an address list, with the decision timeouts
+	FormatMikroTik = "mikrotik"
+	// FormatFortiGate is an external threat feed (IP address list)
+	FormatFortiGate = "fortigate"
+	// FormatPfSense is an URL table alias
+	FormatPfSense = "pfsense"
+	// FormatRPZ is a response policy zone, which blocks the queries of the listed clients
+	FormatRPZ = "rpz"
+)
+
+var Formats = []string{
+	FormatPlain,
+	FormatNFTables,
+	FormatIPSet,
+	FormatMikroTik,
+	FormatFortiGate,
+	FormatPfSense,
+	FormatRPZ,
+}
+
+// DefaultName is the name of the sets and lists
+const DefaultName = "crowdsec"
+
+// names end up in shell-like scripts: keep them simple (and short enough for ipset)
+var validName = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]{0,25}$`)
+
+// mikrotik comments are quoted strings, where $ and \ have a meaning
+var unsafeComment = regexp.MustCompile(`[^A-Za-z0-9/_.:-]`)
+
+// mikrotik timeouts can't be longer than that
+const maxMikroTikTimeout = 35*7*24*time.Hour + 3*24*time.Hour + 13*time.Hour
+
+// nft and ipset lines are split to keep them reasonably short
+const elementsPerLine = 1000
+
+type Options struct {
+	// Name is the name of the sets or the address list
+	Name string
+	// Now is the time of the export, used for the serial of the RPZ zone
+	Now time.Time
+}
+
+// Validate checks the format and the options.
+func Validate(format string, opts Options) error {
+	if !slices.Contains(Formats, format) {
+		return fmt.Errorf("unknown format '%s' (supported: %s)", format, strings.Join(Formats, ", "))
+	}
+
+	if !validName.MatchString(opts.Name) {
+		return fmt.Errorf("invalid name '%s': letters, digits, '-' and '_' only, 26 characters at most", opts.Name)
+	}
+
+	return nil
+}
+
+// ContentType returns the media type of a format.
+func ContentType(format string) string {
+	if format == FormatRPZ {
+		return "text/dns; charset=utf-8"
+	}
+
+	return "text/plain; charset=utf-8"
+}
+
+type entry struct {
+	prefix   netip.Prefix
+	timeout  time.Duration
+	scenario string
+}
+
+func (e entry) String() string {
+	if e.prefix.IsSingleIP() {
+		return e.prefix.Addr().String()
+	}
+
+	return e.prefix.String()
+}
+
+func parsePrefix(value string) (netip.Prefix, error) {
+	if strings.Contains(value, "/") {
+		p, err := netip.ParsePrefix(value)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+
+		if p.Addr().Is4In6() && p.Bits() >= 96 {
+			p = netip.PrefixFrom(p.Addr().Unmap(), p.Bits()-96)
+		}
+
+		return p.Masked(), nil
+	}
+
+	addr, err := netip.ParseAddr(value)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+
+	addr = addr.Unmap()
+
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// entries returns the addresses and ranges of the active decisions, once each with the longest timeout,
+// IPv4 first. The decisions on other scopes are ignored.
+func entries(decisions []*models.Decision) []entry {
+	byPrefix := make(map[netip.Prefix]entry, len(decisions))
+
+	for _, d := range decisions {
+		if d.Value == nil || d.Duration == nil {
+			continue
+		}
+
+		p, err := parsePrefix(*d.Value)
+		if err != nil {
+			continue
+		}
+
+		timeout, err := time.ParseDuration(*d.Duration)
+		if err != nil || timeout <= 0 {
+			continue
+		}
+
+		if prev, ok := byPrefix[p]; ok && prev.timeout >= timeout {
+			continue
+		}
+
+		byPrefix[p] = entry{
+			prefix:   p,
+			timeout:  timeout,
+			scenario: ptr.OrEmpty(d.Scenario),
+		}
+	}
+
+	ret := make([]entry, 0, len(byPrefix))
+	for _, e := range byPrefix {
+		ret = append(ret, e)
+	}
+
+	slices.SortFunc(ret, func(a, b entry) int {
+		return cmp.Or(
+			a.prefix.Addr().Compare(b.prefix.Addr()),
+			cmp.Compare(a.prefix.Bits(), b.prefix.Bits()),
+		)
+	})
+
+	return ret
+}
+
+// withoutCovered drops the entries included in a larger range of the list.
+func withoutCovered(list []entry) []entry {
+	prefixes := make(map[netip.Prefix]struct{}, len(list))
+	for _, e := range list {
+		prefixes[e.prefix] = struct{}{}
+	}
+
+	return slices.DeleteFunc(slices.Clone(list), func(e entry) bool {
+		for bits := range e.prefix.Bits() {
+			if _, ok := prefixes[netip.PrefixFrom(e.prefix.Addr(), bits).Masked()]; ok {
+				return true
+			}
+		}
+
+		return false
+	})
+}
+
+func splitFamilies(list []entry) ([]entry, []entry) {
+	// sorted, IPv4 first
+	idx := slices.IndexFunc(list, func(e entry) bool {
+		return !e.prefix.Addr().Is4()
+	})
+
+	if idx == -1 {
+		return list, nil
+	}
+
+	return list[:idx], list[idx:]
+}
+
+// ETag identifies an export from the state of the decisions it's made of: their version and how
+// they are selected. It can be computed before querying them. It doesn't depend on the time: the
+// timeouts and zone serial may differ for the same ETag, so it's a weak one.
+func ETag(format string, opts Options, state ...string) string {
+	h := sha256.New()
+
+	fmt.Fprintf(h, "%s\n%s\n", format, opts.Name)
+
+	for _, s := range state {
+		fmt.Fprintln(h, s)
+	}
+
+	return `W/"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+}
+
+// Render writes the decisions in the given format.
+func Render(w io.Writer, format string, decisions []*models.Decision, opts Options) error {
+	if err := Validate(format, opts); err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	list := entries(decisions)
+
+	switch format {
+	case FormatPlain:
+		renderPlain(bw, list)
+	case FormatFortiGate, FormatPfSense:
+		// both of them ignore the comments
+		fmt.Fprintf(bw, "# %s: %d addresses and ranges\n", opts.Name, len(list))
+		renderPlain(bw, list)
+	case FormatNFTables:
+		renderNFTables(bw, withoutCovered(list), opts)
+	case FormatIPSet:
+		renderIPSet(bw, list, opts)
+	case FormatMikroTik:
+		renderMikroTik(bw, list, opts)
+	case FormatRPZ:
+		renderRPZ(bw, list, opts)
+	}
+
+	return bw.Flush()
+}
+
+func renderPlain(w io.Writer, list []entry) {
+	for _, e := range list {
+		fmt.Fprintln(w, e.String())
+	}
+}
+
+func renderNFTables(w io.Writer, list []entry, opts Options) {
+	v4, v6 := splitFamilies(list)
+
+	fmt.Fprintf(w, "add table inet %s\n", opts.Name)
+
+	for _, set := range []struct {
+		suffix   string
+		addrType string
+		elements []entry
+	}{
+		{"ipv4", "ipv4_addr", v4},
+		{"ipv6", "ipv6_addr", v6},
+	} {
+		name := opts.Name + "-" + set.suffix
+
+		fmt.Fprintf(w, "add set inet %s %s { type %s; flags interval; auto-merge; }\n", opts.Name, name, set.addrType)
+		fmt.Fprintf(w, "flush set inet %s %s\n", opts.Name, name)
+
+		for chunk := range slices.Chunk(set.elements, elementsPerLine) {
+			values := make([]string, len(chunk))
+			for i, e := range chunk {
+				values[i] = e.String()
+			}
+
+			fmt.Fprintf(w, "add element inet %s %s { %s }\n", opts.Name, name, strings.Join(values, ", "))
+		}
+	}
+}
+
+func renderIPSet(w io.Writer, list []entry, opts Options) {
+	v4, v6 := splitFamilies(list)
+
+	for _, set := range []struct {
+		suffix   string
+		family   string
+		elements []entry
+	}{
+		{"ipv4", "inet", v4},
+		{"ipv6", "inet6", v6},
+	} {
+		name := opts.Name + "-" + set.suffix
+
+		fmt.Fprintf(w, "create %s hash:net family %s maxelem %d -exist\n", name, set.family, max(65536, len(set.elements)))
+		fmt.Fprintf(w, "flush %s\n", name)
+
+		for _, e := range set.elements {
+			fmt.Fprintf(w, "add %s %s -exist\n", name, e.String())
+		}
+	}
+}
+
+func mikroTikTimeout(d time.Duration) string {
+	return min(d, maxMikroTikTimeout).Truncate(time.Second).String()
+}
+
+func renderMikroTik(w io.Writer, list []entry, opts Options) {
+	v4, v6 := splitFamilies(list)
+
+	for _, al := range []struct {
+		menu     string
+		elements []entry
+	}{
+		{"/ip firewall address-list", v4},
+		{"/ipv6 firewall address-list", v6},
+	} {
+		fmt.Fprintf(w, "%s remove [find where list=\"%s\"]\n", al.menu, opts.Name)
+
+		for _, e := range al.elements {
+			address := e.String()
+			if !e.prefix.Addr().Is4() {
+				// RouterOS wants a prefix for IPv6
+				address = e.prefix.String()
+			}
+
+			fmt.Fprintf(w, "%s add list=\"%s\" address=%s timeout=%s comment=\"%s\"\n",
+				al.menu, opts.Name, address, mikroTikTimeout(e.timeout), unsafeComment.ReplaceAllString(e.scenario, "_"))
+		}
+	}
+}
+
+// rpzOwner returns the trigger of an address or range, as in draft-vixie-dnsop-dns-rpz:
+// the prefix length, then the address in reverse order (16 bit words for IPv6, the longest run of zeros as "zz").
+func rpzOwner(p netip.Prefix) string {
+	labels := []string{strconv.Itoa(p.Bits())}
+
+	if p.Addr().Is4() {
+		b := p.Addr().As4()
+		for i := 3; i >= 0; i-- {
+			labels = append(labels, strconv.Itoa(int(b[i])))
+		}
+
+		return strings.Join(labels, ".") + ".rpz-client-ip"
+	}
+
+	b := p.Addr().As16()
+
+	words := make([]uint16, 8)
+	for i := range words {
+		words[i] = uint16(b[2*i])<<8 | uint16(b[2*i+1])
+	}
+
+	// longest run of zeros, at least 2 words long
+	zStart, zLen := -1, 1
+
+	for i := 0; i < 8; {
+		if words[i] != 0 {
+			i++
+			continue
+		}
+
+		j := i
+		for j < 8 && words[j] == 0 {
+			j++
+		}
+
+		if j-i > zLen {
+			zStart, zLen = i, j-i
+		}
+
+		i = j
+	}
+
+	for i := 7; i >= 0; i-- {
+		switch {
+		case i == zStart+zLen-1 && zStart >= 0:
+			labels = append(labels, "zz")
+		case zStart >= 0 && i >= zStart && i < zStart+zLen:
+			continue
+		default:
+			labels = append(labels, strconv.FormatUint(uint64(words[i]), 16))
+		}
+	}
+
+	return strings.Join(labels, ".") + ".rpz-client-ip"
+}
+
+func renderRPZ(w io.Writer, list []entry, opts Options) {
+	fmt.Fprintln(w, "$TTL 60")
+	fmt.Fprintf(w, "@ IN SOA localhost. hostmaster.localhost. %d 3600 600 86400 60\n", uint32(opts.Now.Unix()))
+	fmt.Fprintln(w, "@ IN NS localhost.")
+	fmt.Fprintf(w, "; %s: %d addresses and ranges\n", opts.Name, len(list))
+
+	for _, e := range list {
+		// NXDOMAIN for all the queries of the client
+		fmt.Fprintf(w, "%s CNAME .\n", rpzOwner(e.prefix))
+	}
+}
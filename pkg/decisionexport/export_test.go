@@ -0,0 +1,151 @@
+package decisionexport
+
+import (
+	"bytes"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/crowdsecurity/go-cs-lib/cstest"
+	"github.com/crowdsecurity/go-cs-lib/ptr"
+
+	"github.com/crowdsecurity/crowdsec/pkg/models"
+)
+
+func decision(id int64, value string, duration string) *models.Decision {
+	return &models.Decision{
+		ID:       id,
+		Value:    ptr.Of(value),
+		Duration: ptr.Of(duration),
+		Scenario: ptr.Of(`crowdsecurity/ssh-bf "$x"`),
+	}
+}
+
+var testDecisions = []*models.Decision{
+	decision(1, "192.0.2.1", "3h59m59.5s"),
+	decision(2, "192.0.2.1", "1h"),
+	decision(3, "198.51.100.0/24", "24h"),
+	decision(4, "198.51.100.7", "1h"),
+	decision(5, "2001:db8::1", "1h"),
+	decision(6, "::ffff:203.0.113.9", "1h"),
+	decision(7, "203.0.113.10", "-1s"),
+	decision(8, "FR", "1h"),
+}
+
+func render(t *testing.T, format string) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	err := Render(&buf, format, testDecisions, Options{Name: DefaultName, Now: time.Unix(1700000000, 0)})
+	require.NoError(t, err)
+
+	return buf.String()
+}
+
+func TestRender(t *testing.T) {
+	assert.Equal(t, `192.0.2.1
+198.51.100.0/24
+198.51.100.7
+203.0.113.9
+2001:db8::1
+`, render(t, FormatPlain))
+
+	assert.Equal(t, `# crowdsec: 5 addresses and ranges
+192.0.2.1
+198.51.100.0/24
+198.51.100.7
+203.0.113.9
+2001:db8::1
+`, render(t, FormatFortiGate))
+
+	// the address in the range is dropped, nft would refuse the overlap
+	assert.Equal(t, `add table inet crowdsec
+add set inet crowdsec crowdsec-ipv4 { type ipv4_addr; flags interval; auto-merge; }
+flush set inet crowdsec crowdsec-ipv4
+add element inet crowdsec crowdsec-ipv4 { 192.0.2.1, 198.51.100.0/24, 203.0.113.9 }
+add set inet crowdsec crowdsec-ipv6 { type ipv6_addr; flags interval; auto-merge; }
+flush set inet crowdsec crowdsec-ipv6
+add element inet crowdsec crowdsec-ipv6 { 2001:db8::1 }
+`, render(t, FormatNFTables))
+
+	assert.Equal(t, `create crowdsec-ipv4 hash:net family inet maxelem 65536 -exist
+flush crowdsec-ipv4
+add crowdsec-ipv4 192.0.2.1 -exist
+add crowdsec-ipv4 198.51.100.0/24 -exist
+add crowdsec-ipv4 198.51.100.7 -exist
+add crowdsec-ipv4 203.0.113.9 -exist
+create crowdsec-ipv6 hash:net family inet6 maxelem 65536 -exist
+flush crowdsec-ipv6
+add crowdsec-ipv6 2001:db8::1 -exist
+`, render(t, FormatIPSet))
+
+	// longest timeout, no quote or variable in the comments
+	assert.Equal(t, `/ip firewall address-list remove [find where list="crowdsec"]
+/ip firewall address-list add list="crowdsec" address=192.0.2.1 timeout=3h59m59s comment="crowdsecurity/ssh-bf___x_"
+/ip firewall address-list add list="crowdsec" address=198.51.100.0/24 timeout=24h0m0s comment="crowdsecurity/ssh-bf___x_"
+/ip firewall address-list add list="crowdsec" address=198.51.100.7 timeout=1h0m0s comment="crowdsecurity/ssh-bf___x_"
+/ip firewall address-list add list="crowdsec" address=203.0.113.9 timeout=1h0m0s comment="crowdsecurity/ssh-bf___x_"
+/ipv6 firewall address-list remove [find where list="crowdsec"]
+/ipv6 firewall address-list add list="crowdsec" address=2001:db8::1/128 timeout=1h0m0s comment="crowdsecurity/ssh-bf___x_"
+`, render(t, FormatMikroTik))
+
+	assert.Equal(t, `$TTL 60
+@ IN SOA localhost. hostmaster.localhost. 1700000000 3600 600 86400 60
+@ IN NS localhost.
+; crowdsec: 5 addresses and ranges
+32.1.2.0.192.rpz-client-ip CNAME .
+24.0.100.51.198.rpz-client-ip CNAME .
+32.7.100.51.198.rpz-client-ip CNAME .
+32.9.113.0.203.rpz-client-ip CNAME .
+128.1.zz.db8.2001.rpz-client-ip CNAME .
+`, render(t, FormatRPZ))
+}
+
+func TestRPZOwner(t *testing.T) {
+	tests := []struct {
+		prefix string
+		want   string
+	}{
+		{"2001:db8::/32", "32.zz.db8.2001.rpz-client-ip"},
+		{"::1/128", "128.1.zz.rpz-client-ip"},
+		{"2001:db8:0:1:0:0:0:1/128", "128.1.zz.1.0.db8.2001.rpz-client-ip"},
+		{"2001:db8:1:2:3:4:5:6/128", "128.6.5.4.3.2.1.db8.2001.rpz-client-ip"},
+		{"2001:db8:0:1:1:1:1:1/128", "128.1.1.1.1.1.0.db8.2001.rpz-client-ip"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.prefix, func(t *testing.T) {
+			assert.Equal(t, tc.want, rpzOwner(netip.MustParsePrefix(tc.prefix)))
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	require.NoError(t, Validate(FormatIPSet, Options{Name: "crowdsec_v2-list"}))
+
+	err := Validate("iptables", Options{Name: DefaultName})
+	cstest.RequireErrorContains(t, err, "unknown format 'iptables'")
+
+	err = Validate(FormatMikroTik, Options{Name: `x" ; /system reboot`})
+	cstest.RequireErrorContains(t, err, "invalid name")
+
+	err = Validate(FormatIPSet, Options{Name: "a-very-long-name-for-ipset-sets"})
+	cstest.RequireErrorContains(t, err, "invalid name")
+}
+
+func TestETag(t *testing.T) {
+	opts := Options{Name: DefaultName}
+
+	etag := ETag(FormatPlain, opts, "3-12-21")
+	assert.Regexp(t, `^W/"[0-9a-f]{32}"$`, etag)
+	assert.Equal(t, etag, ETag(FormatPlain, Options{Name: DefaultName}, "3-12-21"))
+
+	assert.NotEqual(t, etag, ETag(FormatNFTables, opts, "3-12-21"))
+	assert.NotEqual(t, etag, ETag(FormatPlain, Options{Name: "blocklist"}, "3-12-21"))
+	assert.NotEqual(t, etag, ETag(FormatPlain, opts, "2-12-9"))
+	assert.NotEqual(t, etag, ETag(FormatPlain, opts, "3-12-21", "type=captcha"))
+}
@@ -0,0 +1,426 @@
+// Package dnsbl is a DNS blocklist server (RFC 5782) answering from the active decisions,
+// for the software that can query a DNSBL but can't run a bouncer.
+package dnsbl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/net/dns/dnsmessage"
+
+	"github.com/crowdsecurity/go-cs-lib/ptr"
+
+	"github.com/crowdsecurity/crowdsec/pkg/csconfig"
+	"github.com/crowdsecurity/crowdsec/pkg/database"
+	"github.com/crowdsecurity/crowdsec/pkg/database/ent"
+)
+
+const (
+	defaultListenAddr = "127.0.0.1:5353"
+	defaultTTL        = 60
+	// defaultCode is the last octet of the A records of the decisions that match no code
+	defaultCode = 2
+	// maxUDPSize is the size of a response without EDNS: the records that don't fit are dropped
+	maxUDPSize = 512
+	// maxRequestSize bounds the queries, which can be larger than maxUDPSize with EDNS options
+	maxRequestSize = 4096
+	// maxConcurrent is the number of queries answered at the same time
+	maxConcurrent = 64
+	// queryTimeout bounds the time spent on the database for a query
+	queryTimeout = 5 * time.Second
+)
+
+// testAddr is always listed, so that clients can check the blocklist works (RFC 5782 section 5)
+var testAddr = netip.MustParseAddr("127.0.0.2")
+
+type code struct {
+	decisionType string
+	scenario     string
+	value        byte
+}
+
+type Server struct {
+	db         *database.Client
+	listenAddr string
+	zone       string
+	ttl        uint32
+	codes      []code
+	logger     *log.Entry
+}
+
+// New checks the configuration and returns a server. It doesn't listen yet.
+func New(cfg *csconfig.DNSBLCfg, db *database.Client) (*Server, error) {
+	zone := strings.ToLower(strings.Trim(cfg.Zone, "."))
+	if zone == "" {
+		return nil, errors.New("dnsbl: a zone is required")
+	}
+
+	if _, err := dnsmessage.NewName(zone + "."); err != nil {
+		return nil, fmt.Errorf("dnsbl: invalid zone '%s': %w", cfg.Zone, err)
+	}
+
+	s := &Server{
+		db:         db,
+		listenAddr: cfg.ListenAddr,
+		zone:       "." + zone + ".",
+		ttl:        cfg.TTL,
+		logger:     log.WithField("component", "dnsbl"),
+	}
+
+	if s.listenAddr == "" {
+		s.listenAddr = defaultListenAddr
+	}
+
+	if s.ttl == 0 {
+		s.ttl = defaultTTL
+	}
+
+	for i, c := range cfg.Codes {
+		if c.Code < 2 {
+			return nil, fmt.Errorf("dnsbl: code #%d: must be between 2 and 255", i)
+		}
+
+		if _, err := path.Match(c.Scenario, ""); err != nil {
+			return nil, fmt.Errorf("dnsbl: code #%d: invalid scenario pattern '%s': %w", i, c.Scenario, err)
+		}
+
+		s.codes = append(s.codes, code{
+			decisionType: c.Type,
+			scenario:     c.Scenario,
+			value:        c.Code,
+		})
+	}
+
+	return s, nil
+}
+
+// ListenAndServe answers the queries until the stop channel is closed.
+func (s *Server) ListenAndServe(ctx context.Context, stop <-chan struct{}) error {
+	var lc net.ListenConfig
+
+	conn, err := lc.ListenPacket(ctx, "udp", s.listenAddr)
+	if err != nil {
+		return fmt.Errorf("dnsbl: %w", err)
+	}
+
+	s.logger.Infof("DNSBL server listening on %s for zone %s", conn.LocalAddr(), strings.Trim(s.zone, "."))
+
+	return s.serve(ctx, conn, stop)
+}
+
+func (s *Server) serve(ctx context.Context, conn net.PacketConn, stop <-chan struct{}) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	go func() {
+		select {
+		case <-stop:
+		case <-ctx.Done():
+		}
+
+		conn.Close()
+	}()
+
+	sem := make(chan struct{}, maxConcurrent)
+
+	for {
+		buf := make([]byte, maxRequestSize)
+
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			select {
+			case <-stop:
+				return nil
+			case <-ctx.Done():
+				return nil
+			default:
+			}
+
+			return fmt.Errorf("dnsbl: %w", err)
+		}
+
+		select {
+		case sem <- struct{}{}:
+		default:
+			// overloaded, the client will retry
+			continue
+		}
+
+		go func() {
+			defer func() { <-sem }()
+
+			resp, err := s.answer(ctx, buf[:n])
+			if err != nil {
+				s.logger.Debugf("query from %s: %s", addr, err)
+				return
+			}
+
+			if _, err := conn.WriteTo(resp, addr); err != nil {
+				s.logger.Debugf("answer to %s: %s", addr, err)
+			}
+		}()
+	}
+}
+
+// parseName returns the address of a query: the labels before the zone, reversed
+// (1.2.0.192 for IPv4, 32 nibbles for IPv6).
+// The boolean is false if the name is not in the zone; the address is invalid for the apex or a name that is not an address.
+func (s *Server) parseName(name string) (netip.Addr, bool) {
+	name = strings.ToLower(name)
+
+	if name == s.zone[1:] {
+		return netip.Addr{}, true
+	}
+
+	prefix, found := strings.CutSuffix(name, s.zone)
+	if !found {
+		return netip.Addr{}, false
+	}
+
+	labels := strings.Split(prefix, ".")
+	slices.Reverse(labels)
+
+	switch len(labels) {
+	case 4:
+		var b [4]byte
+
+		for i, l := range labels {
+			v, err := strconv.ParseUint(l, 10, 8)
+			if err != nil || (len(l) > 1 && l[0] == '0') {
+				return netip.Addr{}, true
+			}
+
+			b[i] = byte(v)
+		}
+
+		return netip.AddrFrom4(b), true
+	case 32:
+		var b [16]byte
+
+		for i, l := range labels {
+			v, err := strconv.ParseUint(l, 16, 4)
+			if err != nil || len(l) != 1 {
+				return netip.Addr{}, true
+			}
+
+			b[i/2] |= byte(v) << (4 * (1 - i%2))
+		}
+
+		return netip.AddrFrom16(b), true
+	}
+
+	return netip.Addr{}, true
+}
+
+// codeFor returns the last octet of the A record of a decision.
+func (s *Server) codeFor(d *ent.Decision) byte {
+	for _, c := range s.codes {
+		if c.decisionType != "" && !strings.EqualFold(c.decisionType, d.Type) {
+			continue
+		}
+
+		if c.scenario != "" {
+			if ok, _ := path.Match(c.scenario, d.Scenario); !ok {
+				continue
+			}
+		}
+
+		return c.value
+	}
+
+	return defaultCode
+}
+
+// listing returns the codes and reasons of an address, nothing if it's not listed.
+func (s *Server) listing(ctx context.Context, addr netip.Addr) ([]byte, []string, error) {
+	if addr == testAddr {
+		return []byte{defaultCode}, []string{"test entry"}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	decisions, err := s.db.QueryDecisionWithFilter(ctx, map[string][]string{"ip": {addr.Unmap().String()}})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	slices.SortFunc(decisions, func(a, b *ent.Decision) int {
+		return ptr.OrEmpty(b.Until).Compare(ptr.OrEmpty(a.Until))
+	})
+
+	var (
+		codes   []byte
+		reasons []string
+	)
+
+	for _, d := range decisions {
+		if c := s.codeFor(d); !slices.Contains(codes, c) {
+			codes = append(codes, c)
+		}
+
+		reason := fmt.Sprintf("%s: %s until %s", d.Type, d.Scenario, ptr.OrEmpty(d.Until).UTC().Format(time.RFC3339))
+		// a character string is 255 bytes at most
+		if len(reason) > 255 {
+			reason = reason[:255]
+		}
+
+		if !slices.Contains(reasons, reason) {
+			reasons = append(reasons, reason)
+		}
+	}
+
+	slices.Sort(codes)
+
+	return codes, reasons, nil
+}
+
+// answer builds the response to a query.
+func (s *Server) answer(ctx context.Context, req []byte) ([]byte, error) {
+	var p dnsmessage.Parser
+
+	hdr, err := p.Start(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if hdr.Response {
+		return nil, errors.New("not a query")
+	}
+
+	respHdr := dnsmessage.Header{
+		ID:               hdr.ID,
+		Response:         true,
+		OpCode:           hdr.OpCode,
+		RecursionDesired: hdr.RecursionDesired,
+	}
+
+	q, err := p.Question()
+	if err != nil {
+		respHdr.RCode = dnsmessage.RCodeFormatError
+		return s.build(respHdr, nil, nil, nil)
+	}
+
+	if hdr.OpCode != 0 {
+		respHdr.RCode = dnsmessage.RCodeNotImplemented
+		return s.build(respHdr, &q, nil, nil)
+	}
+
+	addr, inZone := s.parseName(q.Name.String())
+	if !inZone || q.Class != dnsmessage.ClassINET {
+		respHdr.RCode = dnsmessage.RCodeRefused
+		return s.build(respHdr, &q, nil, nil)
+	}
+
+	respHdr.Authoritative = true
+
+	if !addr.IsValid() {
+		// the apex exists, with no record
+		if strings.ToLower(q.Name.String()) != s.zone[1:] {
+			respHdr.RCode = dnsmessage.RCodeNameError
+		}
+
+		return s.build(respHdr, &q, nil, nil)
+	}
+
+	codes, reasons, err := s.listing(ctx, addr)
+	if err != nil {
+		s.logger.Warningf("looking up %s: %s", addr, err)
+
+		respHdr.RCode = dnsmessage.RCodeServerFailure
+
+		return s.build(respHdr, &q, nil, nil)
+	}
+
+	if len(codes) == 0 {
+		respHdr.RCode = dnsmessage.RCodeNameError
+		return s.build(respHdr, &q, nil, nil)
+	}
+
+	switch q.Type {
+	case dnsmessage.TypeA:
+		reasons = nil
+	case dnsmessage.TypeTXT:
+		codes = nil
+	case dnsmessage.TypeALL:
+	default:
+		// the name exists, with no record of this type
+		codes, reasons = nil, nil
+	}
+
+	return s.build(respHdr, &q, codes, reasons)
+}
+
+// build returns the response with as many records as fit in maxUDPSize. The last
+// reasons, then the last codes, are dropped otherwise, and the response is marked
+// as truncated.
+func (s *Server) build(hdr dnsmessage.Header, q *dnsmessage.Question, codes []byte, reasons []string) ([]byte, error) {
+	for {
+		resp, err := s.encode(hdr, q, codes, reasons)
+		if err != nil || len(resp) <= maxUDPSize {
+			return resp, err
+		}
+
+		hdr.Truncated = true
+
+		switch {
+		case len(reasons) > 0:
+			reasons = reasons[:len(reasons)-1]
+		case len(codes) > 0:
+			codes = codes[:len(codes)-1]
+		default:
+			// a question always fits
+			return resp, nil
+		}
+	}
+}
+
+func (s *Server) encode(hdr dnsmessage.Header, q *dnsmessage.Question, codes []byte, reasons []string) ([]byte, error) {
+	b := dnsmessage.NewBuilder(make([]byte, 0, maxUDPSize), hdr)
+	b.EnableCompression()
+
+	if q == nil {
+		return b.Finish()
+	}
+
+	if err := b.StartQuestions(); err != nil {
+		return nil, err
+	}
+
+	if err := b.Question(*q); err != nil {
+		return nil, err
+	}
+
+	if err := b.StartAnswers(); err != nil {
+		return nil, err
+	}
+
+	rh := dnsmessage.ResourceHeader{
+		Name:  q.Name,
+		Class: dnsmessage.ClassINET,
+		TTL:   s.ttl,
+	}
+
+	for _, c := range codes {
+		if err := b.AResource(rh, dnsmessage.AResource{A: [4]byte{127, 0, 0, c}}); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, r := range reasons {
+		if err := b.TXTResource(rh, dnsmessage.TXTResource{TXT: []string{r}}); err != nil {
+			return nil, err
+		}
+	}
+
+	return b.Finish()
+}
@@ -0,0 +1,244 @@
+package dnsbl
+
+import (
+	"fmt"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/dns/dnsmessage"
+
+	"github.com/crowdsecurity/go-cs-lib/cstest"
+	"github.com/crowdsecurity/go-cs-lib/ptr"
+
+	"github.com/crowdsecurity/crowdsec/pkg/csconfig"
+	"github.com/crowdsecurity/crowdsec/pkg/database"
+	"github.com/crowdsecurity/crowdsec/pkg/models"
+)
+
+func decision(decisionType string, scope string, value string, scenario string) *models.Decision {
+	return &models.Decision{
+		Duration:  ptr.Of("4h"),
+		Type:      ptr.Of(decisionType),
+		Scope:     ptr.Of(scope),
+		Value:     ptr.Of(value),
+		Origin:    ptr.Of("crowdsec"),
+		Scenario:  ptr.Of(scenario),
+		Simulated: ptr.Of(false),
+	}
+}
+
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+
+	ctx := t.Context()
+
+	db, err := database.NewClient(ctx, &csconfig.DatabaseCfg{
+		Type:   "sqlite",
+		DbName: "crowdsec",
+		DbPath: filepath.Join(t.TempDir(), "crowdsec.db"),
+	}, nil)
+	require.NoError(t, err)
+
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	_, err = db.CreateAlert(ctx, "", []*models.Alert{{
+		Scenario:        ptr.Of("crowdsecurity/ssh-bf"),
+		ScenarioHash:    ptr.Of(""),
+		ScenarioVersion: ptr.Of(""),
+		Message:         ptr.Of("test"),
+		EventsCount:     ptr.Of(int32(1)),
+		StartAt:         ptr.Of(now),
+		StopAt:          ptr.Of(now),
+		Capacity:        ptr.Of(int32(5)),
+		Leakspeed:       ptr.Of("10s"),
+		Simulated:       ptr.Of(false),
+		Source: &models.Source{
+			Scope: ptr.Of("Ip"),
+			Value: ptr.Of("192.0.2.1"),
+		},
+		Decisions: []*models.Decision{
+			decision("ban", "Ip", "192.0.2.1", "crowdsecurity/ssh-bf"),
+			decision("ban", "Ip", "192.0.2.1", "crowdsecurity/http-probing"),
+			decision("captcha", "Range", "198.51.100.0/24", "crowdsecurity/ssh-slow-bf"),
+			decision("ban", "Ip", "2001:db8::1", "crowdsecurity/ssh-bf"),
+		},
+	}})
+	require.NoError(t, err)
+
+	s, err := New(&csconfig.DNSBLCfg{
+		Zone: "BL.example.com.",
+		Codes: []*csconfig.DNSBLCodeCfg{
+			{Scenario: "crowdsecurity/http-*", Code: 3},
+			{Type: "captcha", Code: 4},
+		},
+	}, db)
+	require.NoError(t, err)
+
+	return s
+}
+
+type result struct {
+	rcode     dnsmessage.RCode
+	truncated bool
+	size      int
+	a         []net.IP
+	txt       []string
+}
+
+func query(t *testing.T, s *Server, name string, qtype dnsmessage.Type) result {
+	t.Helper()
+
+	b := dnsmessage.NewBuilder(nil, dnsmessage.Header{ID: 42, RecursionDesired: true})
+	require.NoError(t, b.StartQuestions())
+	require.NoError(t, b.Question(dnsmessage.Question{
+		Name:  dnsmessage.MustNewName(name),
+		Type:  qtype,
+		Class: dnsmessage.ClassINET,
+	}))
+
+	req, err := b.Finish()
+	require.NoError(t, err)
+
+	resp, err := s.answer(t.Context(), req)
+	require.NoError(t, err)
+
+	var msg dnsmessage.Message
+	require.NoError(t, msg.Unpack(resp))
+
+	assert.Equal(t, uint16(42), msg.ID)
+	assert.True(t, msg.Response)
+
+	ret := result{rcode: msg.RCode, truncated: msg.Truncated, size: len(resp)}
+
+	for _, rr := range msg.Answers {
+		assert.Equal(t, uint32(defaultTTL), rr.Header.TTL)
+
+		switch body := rr.Body.(type) {
+		case *dnsmessage.AResource:
+			ret.a = append(ret.a, net.IP(body.A[:]))
+		case *dnsmessage.TXTResource:
+			ret.txt = append(ret.txt, body.TXT...)
+		}
+	}
+
+	return ret
+}
+
+func TestAnswer(t *testing.T) {
+	s := newTestServer(t)
+
+	// two scenarios, two codes
+	got := query(t, s, "1.2.0.192.bl.example.com.", dnsmessage.TypeA)
+	assert.Equal(t, dnsmessage.RCodeSuccess, got.rcode)
+	assert.Equal(t, []net.IP{net.IPv4(127, 0, 0, 2).To4(), net.IPv4(127, 0, 0, 3).To4()}, got.a)
+	assert.Empty(t, got.txt)
+
+	got = query(t, s, "1.2.0.192.BL.example.com.", dnsmessage.TypeTXT)
+	assert.Equal(t, dnsmessage.RCodeSuccess, got.rcode)
+	assert.Empty(t, got.a)
+	require.Len(t, got.txt, 2)
+	assert.Regexp(t, `^ban: crowdsecurity/(ssh-bf|http-probing) until \d{4}-`, got.txt[0])
+
+	// in a range
+	got = query(t, s, "9.100.51.198.bl.example.com.", dnsmessage.TypeALL)
+	assert.Equal(t, dnsmessage.RCodeSuccess, got.rcode)
+	assert.Equal(t, []net.IP{net.IPv4(127, 0, 0, 4).To4()}, got.a)
+	assert.Len(t, got.txt, 1)
+
+	got = query(t, s, "1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2.bl.example.com.", dnsmessage.TypeA)
+	assert.Equal(t, []net.IP{net.IPv4(127, 0, 0, 2).To4()}, got.a)
+
+	// the name exists, but has no MX
+	got = query(t, s, "1.2.0.192.bl.example.com.", dnsmessage.TypeMX)
+	assert.Equal(t, dnsmessage.RCodeSuccess, got.rcode)
+	assert.Empty(t, got.a)
+
+	got = query(t, s, "2.0.0.127.bl.example.com.", dnsmessage.TypeA)
+	assert.Equal(t, []net.IP{net.IPv4(127, 0, 0, 2).To4()}, got.a)
+
+	got = query(t, s, "bl.example.com.", dnsmessage.TypeA)
+	assert.Equal(t, dnsmessage.RCodeSuccess, got.rcode)
+	assert.Empty(t, got.a)
+
+	for _, name := range []string{
+		"2.2.0.192.bl.example.com.",
+		"01.2.0.192.bl.example.com.",
+		"256.2.0.192.bl.example.com.",
+		"2.0.192.bl.example.com.",
+		"www.bl.example.com.",
+	} {
+		got = query(t, s, name, dnsmessage.TypeA)
+		assert.Equal(t, dnsmessage.RCodeNameError, got.rcode, name)
+	}
+
+	got = query(t, s, "1.2.0.192.example.com.", dnsmessage.TypeA)
+	assert.Equal(t, dnsmessage.RCodeRefused, got.rcode)
+}
+
+func TestAnswerTruncated(t *testing.T) {
+	s := newTestServer(t)
+
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	decisions := []*models.Decision{}
+	for i := range 10 {
+		decisions = append(decisions, decision("ban", "Ip", "192.0.2.9", fmt.Sprintf("crowdsecurity/%d-%s", i, strings.Repeat("x", 200))))
+	}
+
+	_, err := s.db.CreateAlert(t.Context(), "", []*models.Alert{{
+		Scenario:        ptr.Of("crowdsecurity/long"),
+		ScenarioHash:    ptr.Of(""),
+		ScenarioVersion: ptr.Of(""),
+		Message:         ptr.Of("test"),
+		EventsCount:     ptr.Of(int32(1)),
+		StartAt:         ptr.Of(now),
+		StopAt:          ptr.Of(now),
+		Capacity:        ptr.Of(int32(5)),
+		Leakspeed:       ptr.Of("10s"),
+		Simulated:       ptr.Of(false),
+		Source: &models.Source{
+			Scope: ptr.Of("Ip"),
+			Value: ptr.Of("192.0.2.9"),
+		},
+		Decisions: decisions,
+	}})
+	require.NoError(t, err)
+
+	// the reasons don't fit in a UDP response
+	got := query(t, s, "9.2.0.192.bl.example.com.", dnsmessage.TypeALL)
+	assert.Equal(t, dnsmessage.RCodeSuccess, got.rcode)
+	assert.True(t, got.truncated)
+	assert.LessOrEqual(t, got.size, maxUDPSize)
+	assert.Equal(t, []net.IP{net.IPv4(127, 0, 0, 2).To4()}, got.a)
+	require.NotEmpty(t, got.txt)
+	assert.Less(t, len(got.txt), len(decisions))
+
+	// the codes fit
+	got = query(t, s, "9.2.0.192.bl.example.com.", dnsmessage.TypeA)
+	assert.False(t, got.truncated)
+	assert.Equal(t, []net.IP{net.IPv4(127, 0, 0, 2).To4()}, got.a)
+
+	got = query(t, s, "1.2.0.192.bl.example.com.", dnsmessage.TypeTXT)
+	assert.False(t, got.truncated)
+	assert.Len(t, got.txt, 2)
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(&csconfig.DNSBLCfg{}, nil)
+	cstest.RequireErrorContains(t, err, "dnsbl: a zone is required")
+
+	_, err = New(&csconfig.DNSBLCfg{Zone: "bl.example.com", Codes: []*csconfig.DNSBLCodeCfg{{Code: 1}}}, nil)
+	cstest.RequireErrorContains(t, err, "dnsbl: code #0: must be between 2 and 255")
+
+	_, err = New(&csconfig.DNSBLCfg{Zone: "bl.example.com", Codes: []*csconfig.DNSBLCodeCfg{{Scenario: "[", Code: 3}}}, nil)
+	cstest.RequireErrorContains(t, err, "invalid scenario pattern")
+
+	s, err := New(&csconfig.DNSBLCfg{Zone: "bl.example.com"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, defaultListenAddr, s.listenAddr)
+}
@@ -45,36 +45,37 @@ func compileBotRegex(pattern string) (*regexp.Regexp, error) {
 	return regexp.Compile("(?i)" + pattern) // Force case insensitive match
 }
 
-func botFileInit(filename string, line string) error {
+// parseBotLine parses and compiles one line of a "bots" data file.
+func parseBotLine(filename string, line string) (*botEntry, error) {
 	entry := &botEntry{}
 
 	dec := json.NewDecoder(strings.NewReader(line))
 	dec.DisallowUnknownFields()
 
 	if err := dec.Decode(entry); err != nil {
-		return fmt.Errorf("failed to parse JSON line in %s: %w", filename, err)
+		return nil, fmt.Errorf("failed to parse JSON line in %s: %w", filename, err)
 	}
 
 	if entry.Name == "" {
-		return fmt.Errorf("missing mandatory 'name' field in %s: %s", filename, line)
+		return nil, fmt.Errorf("missing mandatory 'name' field in %s: %s", filename, line)
 	}
 
 	if len(entry.IPs)+len(entry.Ranges)+len(entry.RDNS) == 0 {
-		return fmt.Errorf("bot entry '%s' in %s has no identity verification (need at least one of ips/ranges/rdns)", entry.Name, filename)
+		return nil, fmt.Errorf("bot entry '%s' in %s has no identity verification (need at least one of ips/ranges/rdns)", entry.Name, filename)
 	}
 
 	var err error
 
 	if entry.UserAgent != "" {
 		if entry.uaRegex, err = compileBotRegex(entry.UserAgent); err != nil {
-			return fmt.Errorf("invalid user_agent regex for bot entry '%s' in %s: %w", entry.Name, filename, err)
+			return nil, fmt.Errorf("invalid user_agent regex for bot entry '%s' in %s: %w", entry.Name, filename, err)
 		}
 	}
 
 	for _, p := range entry.Paths {
 		re, err := compileBotRegex(p)
 		if err != nil {
-			return fmt.Errorf("invalid path regex '%s' for bot entry '%s' in %s: %w", p, entry.Name, filename, err)
+			return nil, fmt.Errorf("invalid path regex '%s' for bot entry '%s' in %s: %w", p, entry.Name, filename, err)
 		}
 
 		entry.pathRegexes = append(entry.pathRegexes, re)
@@ -85,7 +86,7 @@ func botFileInit(filename string, line string) error {
 	for _, ip := range entry.IPs {
 		addr, err := netip.ParseAddr(ip)
 		if err != nil {
-			return fmt.Errorf("invalid IP '%s' for bot entry '%s' in %s: %w", ip, entry.Name, filename, err)
+			return nil, fmt.Errorf("invalid IP '%s' for bot entry '%s' in %s: %w", ip, entry.Name, filename, err)
 		}
 
 		entry.ipSet[addr.Unmap()] = struct{}{}
@@ -94,7 +95,7 @@ func botFileInit(filename string, line string) error {
 	for _, r := range entry.Ranges {
 		prefix, err := netip.ParsePrefix(r)
 		if err != nil {
-			return fmt.Errorf("invalid CIDR range '%s' for bot entry '%s' in %s: %w", r, entry.Name, filename, err)
+			return nil, fmt.Errorf("invalid CIDR range '%s' for bot entry '%s' in %s: %w", r, entry.Name, filename, err)
 		}
 
 		entry.prefixes = append(entry.prefixes, prefix.Masked())
@@ -104,20 +105,18 @@ func botFileInit(filename string, line string) error {
 		// an empty pattern matches every PTR-confirmed host: almost
 		// certainly a mistake, reject it
 		if p == "" {
-			return fmt.Errorf("empty rdns pattern for bot entry '%s' in %s", entry.Name, filename)
+			return nil, fmt.Errorf("empty rdns pattern for bot entry '%s' in %s", entry.Name, filename)
 		}
 
 		re, err := compileBotRegex(p)
 		if err != nil {
-			return fmt.Errorf("invalid rdns regex '%s' for bot entry '%s' in %s: %w", p, entry.Name, filename, err)
+			return nil, fmt.Errorf("invalid rdns regex '%s' for bot entry '%s' in %s: %w", p, entry.Name, filename, err)
 		}
 
 		entry.rdnsRegexes = append(entry.rdnsRegexes, re)
 	}
 
-	dataFileBots[filename] = append(dataFileBots[filename], entry)
-
-	return nil
+	return entry, nil
 }
 
 // parseBotAddr normalizes a source address as found in HTTP contexts:
@@ -147,7 +146,11 @@ func parseBotAddr(s string) (netip.Addr, bool) {
 // checks across all named files, against every candidate entry at once — and is
 // cached per IP.
 func MatchKnownBot(ip string, ua string, path string, filenames ...string) bool {
-	if len(dataFileBots) == 0 || len(filenames) == 0 {
+	dataFilesMu.RLock()
+	bots := dataFileBots
+	dataFilesMu.RUnlock()
+
+	if len(bots) == 0 || len(filenames) == 0 {
 		return false
 	}
 
@@ -160,7 +163,10 @@ func MatchKnownBot(ip string, ua string, path string, filenames ...string) bool
 	var rdnsCandidates []*botEntry
 
 	for _, filename := range filenames {
-		entries, ok := dataFileBots[filename]
+		dataFilesMu.RLock()
+		entries, ok := bots[filename]
+		dataFilesMu.RUnlock()
+
 		if !ok {
 			log.Debugf("MatchKnownBot: unknown bot data file '%s'", filename)
 			continue
@@ -0,0 +1,346 @@
+package exprhelpers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/wasilibs/go-re2"
+
+	"github.com/crowdsecurity/go-cs-lib/downloader"
+
+	"github.com/crowdsecurity/crowdsec/pkg/csconfig"
+	"github.com/crowdsecurity/crowdsec/pkg/enrichment"
+	"github.com/crowdsecurity/crowdsec/pkg/fflag"
+	"github.com/crowdsecurity/crowdsec/pkg/metrics"
+)
+
+// dataFilesMu protects the content of the data files, which is replaced when
+// they change on disk.
+var dataFilesMu sync.RWMutex
+
+// dataFileSource is a loaded data file, with the types it was loaded as.
+type dataFileSource struct {
+	directory string
+	filename  string
+	types     []string
+	sourceURL string
+}
+
+func (s *dataFileSource) path() string {
+	return filepath.Join(s.directory, s.filename)
+}
+
+var (
+	// by file name, protected by dataFilesMu
+	dataFileSources = map[string]*dataFileSource{}
+	dataFileWatcher *fileWatcher
+	// stops the refresh of the files with a source_url
+	dataFileRefreshCancel context.CancelFunc
+)
+
+// the data files can be large, the default timeout of the expr helpers is too short
+var dataFileHTTPClient = &http.Client{
+	Timeout:   5 * time.Minute,
+	Transport: &httpHelperTransport{next: http.DefaultTransport},
+}
+
+// dataFileContent is a data file, parsed for one type.
+type dataFileContent struct {
+	strings  []string
+	regexps  []*regexp.Regexp
+	re2s     []*re2.Regexp
+	mapEntry *fileMapEntry
+	bots     []*botEntry
+}
+
+func readDataFile(path string, filename string, fileType string) (*dataFileContent, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	content := &dataFileContent{}
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if strings.HasPrefix(line, "#") { // allow comments
+			continue
+		}
+
+		if line == "" { // skip empty lines
+			continue
+		}
+
+		switch fileType {
+		case "regex", "regexp":
+			if fflag.Re2RegexpInfileSupport.IsEnabled() {
+				re, err := re2.Compile(line)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", filename, err)
+				}
+
+				content.re2s = append(content.re2s, re)
+
+				continue
+			}
+
+			re, err := regexp.Compile(line)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", filename, err)
+			}
+
+			content.regexps = append(content.regexps, re)
+		case "string":
+			content.strings = append(content.strings, line)
+		case "map":
+			row, err := parseMapLine(filename, line)
+			if err != nil {
+				return nil, err
+			}
+
+			if content.mapEntry == nil {
+				content.mapEntry = &fileMapEntry{filename: filename}
+			}
+
+			content.mapEntry.rows = append(content.mapEntry.rows, row)
+		case "bots":
+			entry, err := parseBotLine(filename, line)
+			if err != nil {
+				return nil, err
+			}
+
+			content.bots = append(content.bots, entry)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	// Build the match index eagerly so errors surface at load time.
+	if content.mapEntry != nil {
+		content.mapEntry.buildIndex()
+	}
+
+	return content, nil
+}
+
+func setOrDelete[T any](m map[string]T, key string, value T, empty bool) {
+	if empty {
+		delete(m, key)
+		return
+	}
+
+	m[key] = value
+}
+
+// storeDataFile must be called with dataFilesMu locked.
+func storeDataFile(filename string, fileType string, content *dataFileContent) {
+	switch fileType {
+	case "regex", "regexp":
+		if fflag.Re2RegexpInfileSupport.IsEnabled() {
+			setOrDelete(dataFileRe2, filename, content.re2s, len(content.re2s) == 0)
+		} else {
+			setOrDelete(dataFileRegex, filename, content.regexps, len(content.regexps) == 0)
+		}
+	case "string":
+		setOrDelete(dataFile, filename, content.strings, len(content.strings) == 0)
+	case "map":
+		setOrDelete(dataFileMap, filename, content.mapEntry, content.mapEntry == nil)
+	case "bots":
+		setOrDelete(dataFileBots, filename, content.bots, len(content.bots) == 0)
+	}
+
+	// the cached results may have changed
+	if cache, ok := dataFileRegexCache[filename]; ok {
+		cache.Purge()
+	}
+}
+
+// registerDataFile must be called with dataFilesMu locked.
+func registerDataFile(directory string, filename string, fileType string) {
+	src, ok := dataFileSources[filename]
+	if !ok {
+		src = &dataFileSource{directory: directory, filename: filename}
+		dataFileSources[filename] = src
+
+		if dataFileWatcher != nil {
+			dataFileWatcher.add(filename, src.path(), src.reload)
+		}
+	}
+
+	if !slices.Contains(src.types, fileType) {
+		src.types = append(src.types, fileType)
+	}
+}
+
+// reload parses the file for all its types before replacing the content, so
+// that a bad file doesn't replace any of them.
+func (s *dataFileSource) reload() error {
+	dataFilesMu.RLock()
+	types := slices.Clone(s.types)
+	dataFilesMu.RUnlock()
+
+	contents := make([]*dataFileContent, len(types))
+
+	for i, fileType := range types {
+		content, err := readDataFile(s.path(), s.filename, fileType)
+		if err != nil {
+			return err
+		}
+
+		contents[i] = content
+	}
+
+	dataFilesMu.Lock()
+	defer dataFilesMu.Unlock()
+
+	for i, fileType := range types {
+		storeDataFile(s.filename, fileType, contents[i])
+	}
+
+	return nil
+}
+
+// DataProviderInit loads a data file declared by a hub item. The file is
+// refreshed from its source_url if WatchDataFiles is configured to.
+func DataProviderInit(directory string, data *enrichment.DataProvider) error {
+	if err := FileInit(directory, data.DestPath, data.Type); err != nil {
+		return err
+	}
+
+	dataFilesMu.Lock()
+	defer dataFilesMu.Unlock()
+
+	if src, ok := dataFileSources[data.DestPath]; ok && data.SourceURL != "" {
+		src.sourceURL = data.SourceURL
+	}
+
+	return nil
+}
+
+// WatchDataFiles loads the data files again when they change on disk, and
+// downloads the ones with a source_url periodically. It applies to the files
+// loaded before and after the call, until StopWatchingDataFiles.
+func WatchDataFiles(cfg *csconfig.DataFilesCfg) {
+	StopWatchingDataFiles()
+
+	if cfg == nil {
+		cfg = &csconfig.DataFilesCfg{}
+	}
+
+	autoReload := cfg.AutoReload == nil || *cfg.AutoReload
+
+	dataFilesMu.Lock()
+	defer dataFilesMu.Unlock()
+
+	if autoReload {
+		dataFileWatcher = newFileWatcher(cfg.PollInterval)
+
+		for _, src := range dataFileSources {
+			dataFileWatcher.add(src.filename, src.path(), src.reload)
+		}
+	}
+
+	if cfg.RefreshInterval > 0 {
+		ctx, cancel := context.WithCancel(context.Background())
+		dataFileRefreshCancel = cancel
+
+		go refreshDataFiles(ctx, cfg.RefreshInterval)
+	}
+}
+
+// StopWatchingDataFiles stops the reload and refresh of the data files.
+func StopWatchingDataFiles() {
+	dataFilesMu.Lock()
+	defer dataFilesMu.Unlock()
+
+	if dataFileWatcher != nil {
+		dataFileWatcher.close()
+		dataFileWatcher = nil
+	}
+
+	if dataFileRefreshCancel != nil {
+		dataFileRefreshCancel()
+		dataFileRefreshCancel = nil
+	}
+}
+
+func refreshDataFiles(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			dataFilesMu.RLock()
+
+			var sources []*dataFileSource
+
+			for _, src := range dataFileSources {
+				if src.sourceURL != "" {
+					sources = append(sources, src)
+				}
+			}
+
+			dataFilesMu.RUnlock()
+
+			for _, src := range sources {
+				refreshDataFile(ctx, src)
+			}
+		}
+	}
+}
+
+// refreshDataFile downloads a data file if it has changed (with the ETag of
+// the previous download) and reloads it.
+func refreshDataFile(ctx context.Context, src *dataFileSource) {
+	path := src.path()
+
+	downloaded, err := downloader.
+		New().
+		WithHTTPClient(dataFileHTTPClient).
+		ToFile(path).
+		WithETagFile(path+".etag").
+		WithLogger(log.WithField("url", src.sourceURL)).
+		Download(ctx, src.sourceURL)
+	if err != nil {
+		log.Warningf("refreshing %s: %s", src.filename, err)
+		metrics.DataFileReloadFailures.WithLabelValues(src.filename, "download").Inc()
+
+		return
+	}
+
+	if !downloaded {
+		return
+	}
+
+	log.Infof("downloaded a new version of %s", src.filename)
+
+	dataFilesMu.RLock()
+	watcher := dataFileWatcher
+	dataFilesMu.RUnlock()
+
+	// the watcher would see the change too, this way it's loaded once
+	if watcher != nil {
+		watcher.trigger(path)
+		return
+	}
+
+	runReload(src.filename, path, src.reload)
+}
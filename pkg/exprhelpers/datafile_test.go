@@ -0,0 +1,190 @@
+package exprhelpers
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/crowdsecurity/crowdsec/pkg/csconfig"
+	"github.com/crowdsecurity/crowdsec/pkg/enrichment"
+	"github.com/crowdsecurity/crowdsec/pkg/metrics"
+)
+
+func setupDataFileTest(t *testing.T) string {
+	t.Helper()
+
+	require.NoError(t, Init(nil))
+	ResetDataFiles()
+
+	reloadDelay = 10 * time.Millisecond
+
+	t.Cleanup(func() {
+		reloadDelay = time.Second
+
+		ResetDataFiles()
+	})
+
+	return t.TempDir()
+}
+
+// replace writes the file like most tools do, so that the content is never partial
+func replace(t *testing.T, path string, content string) {
+	t.Helper()
+
+	tmp := path + ".tmp"
+	require.NoError(t, os.WriteFile(tmp, []byte(content), 0o600))
+	require.NoError(t, os.Rename(tmp, path))
+}
+
+func TestDataFileReload(t *testing.T) {
+	dir := setupDataFileTest(t)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "agents.txt"), []byte("curl\nwget\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "agents.re"), []byte("^curl/\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "tags.json"), []byte(`{"pattern": "/admin", "tag": "old", "type": "contains"}`+"\n"), 0o600))
+
+	require.NoError(t, FileInit(dir, "agents.txt", "string"))
+	require.NoError(t, FileInit(dir, "agents.re", "regexp"))
+	require.NoError(t, FileInit(dir, "tags.json", "map"))
+	require.NoError(t, RegexpCacheInit("agents.re", enrichment.DataProvider{Strategy: "LRU"}))
+
+	WatchDataFiles(&csconfig.DataFilesCfg{})
+
+	matched, err := RegexpInFile("sqlmap/1.0", "agents.re")
+	require.NoError(t, err)
+	assert.Equal(t, false, matched)
+
+	replace(t, filepath.Join(dir, "agents.txt"), "curl\nwget\nsqlmap\n")
+	replace(t, filepath.Join(dir, "agents.re"), "^curl/\n^sqlmap/\n")
+	replace(t, filepath.Join(dir, "tags.json"), `{"pattern": "/admin", "tag": "new", "type": "contains"}`+"\n")
+
+	require.Eventually(t, func() bool {
+		lines, _ := File("agents.txt")
+		tag, _ := LookupFile("/admin/login", "tags.json")
+		// the cached result is purged
+		matched, _ := RegexpInFile("sqlmap/1.0", "agents.re")
+
+		return len(lines.([]string)) == 3 && tag == "new" && matched == true
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// a bad file keeps the previous content
+	failures := testutil.ToFloat64(metrics.DataFileReloadFailures.WithLabelValues("agents.re", "load"))
+
+	replace(t, filepath.Join(dir, "agents.re"), "^curl/\n(unclosed\n")
+
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(metrics.DataFileReloadFailures.WithLabelValues("agents.re", "load")) == failures+1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	matched, err = RegexpInFile("sqlmap/1.0", "agents.re")
+	require.NoError(t, err)
+	assert.Equal(t, true, matched)
+}
+
+func TestDataFilePolling(t *testing.T) {
+	dir := setupDataFileTest(t)
+	path := filepath.Join(dir, "agents.txt")
+
+	require.NoError(t, os.WriteFile(path, []byte("curl\n"), 0o600))
+	require.NoError(t, FileInit(dir, "agents.txt", "string"))
+
+	// without inotify
+	w := &fileWatcher{
+		pollInterval: 10 * time.Millisecond,
+		files:        map[string]*watchedFile{},
+		dirs:         map[string]bool{},
+		done:         make(chan struct{}),
+		stopped:      make(chan struct{}),
+	}
+
+	go w.run()
+	t.Cleanup(w.close)
+
+	dataFilesMu.RLock()
+	src := dataFileSources["agents.txt"]
+	dataFilesMu.RUnlock()
+
+	w.add(src.filename, src.path(), src.reload)
+
+	// the size changes, the modification time can be the same
+	require.NoError(t, os.WriteFile(path, []byte("curl\nwget\n"), 0o600))
+
+	require.Eventually(t, func() bool {
+		lines, _ := File("agents.txt")
+		return len(lines.([]string)) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestDataFileRefresh(t *testing.T) {
+	dir := setupDataFileTest(t)
+
+	var (
+		content  atomic.Value
+		requests atomic.Int32
+	)
+
+	content.Store("curl\n")
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		body := content.Load().(string)
+		etag := fmt.Sprintf(`"%x"`, sha256.Sum256([]byte(body)))
+
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("ETag", etag)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(ts.Close)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "agents.txt"), []byte("curl\n"), 0o600))
+	require.NoError(t, DataProviderInit(dir, &enrichment.DataProvider{SourceURL: ts.URL, DestPath: "agents.txt", Type: "string"}))
+
+	dataFilesMu.RLock()
+	src := dataFileSources["agents.txt"]
+	dataFilesMu.RUnlock()
+
+	require.Equal(t, ts.URL, src.sourceURL)
+
+	ctx := context.Background()
+
+	// the ETag is stored
+	refreshDataFile(ctx, src)
+	assert.FileExists(t, filepath.Join(dir, "agents.txt.etag"))
+
+	// not modified
+	before := requests.Load()
+	refreshDataFile(ctx, src)
+	assert.Equal(t, int32(1), requests.Load()-before, "HEAD with If-None-Match only")
+
+	content.Store("curl\nwget\n")
+	refreshDataFile(ctx, src)
+
+	lines, err := File("agents.txt")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"curl", "wget"}, lines)
+
+	// download errors are counted
+	failures := testutil.ToFloat64(metrics.DataFileReloadFailures.WithLabelValues("agents.txt", "download"))
+
+	src.sourceURL = ts.URL + "/missing"
+	ts.Config.Handler = http.NotFoundHandler()
+	refreshDataFile(ctx, src)
+
+	assert.InDelta(t, failures+1, testutil.ToFloat64(metrics.DataFileReloadFailures.WithLabelValues("agents.txt", "download")), 0)
+}
@@ -445,6 +445,10 @@ func Run(program *vm.Program, env any, logger *log.Entry, debug bool) (any, erro
 		return ret, err
 	}
 
+	if l := limits.Load(); l != nil {
+		return runLimited(program, env, logger, l)
+	}
+
 	return expr.Run(program, env)
 }
 
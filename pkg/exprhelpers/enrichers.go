@@ -0,0 +1,452 @@
+package exprhelpers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/oschwald/maxminddb-golang"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/crowdsecurity/crowdsec/pkg/csconfig"
+	"github.com/crowdsecurity/crowdsec/pkg/metrics"
+)
+
+var (
+	customEnrichersMu sync.RWMutex
+	customEnrichers   = map[string]*customEnricher{}
+	enricherWatcher   *fileWatcher
+)
+
+// customEnricher is a mmdb database or an asset inventory declared in the
+// configuration. The loaded content is swapped when the file changes, so the
+// lookups never see a partial file.
+type customEnricher struct {
+	cfg       csconfig.EnricherCfg
+	mmdb      atomic.Pointer[maxminddb.Reader]
+	inventory atomic.Pointer[assetInventory]
+}
+
+// assetInventory indexes the rows of an inventory by hostname, address and range.
+type assetInventory struct {
+	hosts    map[string]map[string]string
+	prefixes map[netip.Prefix]map[string]string
+	// the lengths of the prefixes, longest first, for the most specific match
+	bits []int
+}
+
+func (e *customEnricher) load() error {
+	switch e.cfg.Type {
+	case csconfig.EnricherMMDB:
+		// read in memory rather than mmap'ed, the previous reader can still be in use after a reload
+		buf, err := os.ReadFile(e.cfg.Path)
+		if err != nil {
+			return err
+		}
+
+		reader, err := maxminddb.FromBytes(buf)
+		if err != nil {
+			return fmt.Errorf("%s: %w", e.cfg.Path, err)
+		}
+
+		e.mmdb.Store(reader)
+	case csconfig.EnricherInventory:
+		inv, err := loadInventory(e.cfg.Path, e.cfg.Key)
+		if err != nil {
+			return err
+		}
+
+		e.inventory.Store(inv)
+	default:
+		return fmt.Errorf("unknown enricher type %q", e.cfg.Type)
+	}
+
+	return nil
+}
+
+func loadInventory(path string, keyColumn string) (*assetInventory, error) {
+	fd, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+
+	var rows []map[string]string
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		rows, err = readJSONInventory(fd)
+	} else {
+		rows, err = readCSVInventory(fd)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	inv := &assetInventory{
+		hosts:    make(map[string]map[string]string),
+		prefixes: make(map[netip.Prefix]map[string]string),
+	}
+
+	for i, row := range rows {
+		key := strings.TrimSpace(row[keyColumn])
+		if key == "" {
+			return nil, fmt.Errorf("%s: entry %d has no %s", path, i+1, keyColumn)
+		}
+
+		inv.add(key, row)
+	}
+
+	return inv, nil
+}
+
+func (inv *assetInventory) add(key string, row map[string]string) {
+	var prefix netip.Prefix
+
+	if addr, err := netip.ParseAddr(key); err == nil {
+		addr = addr.Unmap()
+		prefix = netip.PrefixFrom(addr, addr.BitLen())
+	} else if p, err := netip.ParsePrefix(key); err == nil {
+		if p.Addr().Is4In6() && p.Bits() >= 96 {
+			p = netip.PrefixFrom(p.Addr().Unmap(), p.Bits()-96)
+		}
+
+		prefix = p.Masked()
+	} else {
+		inv.hosts[normalizeHost(key)] = row
+		return
+	}
+
+	inv.prefixes[prefix] = row
+
+	if !slices.Contains(inv.bits, prefix.Bits()) {
+		inv.bits = append(inv.bits, prefix.Bits())
+		slices.Sort(inv.bits)
+		slices.Reverse(inv.bits)
+	}
+}
+
+func (inv *assetInventory) lookup(key string) map[string]string {
+	addr, err := netip.ParseAddr(strings.TrimSpace(key))
+	if err != nil {
+		return inv.hosts[normalizeHost(key)]
+	}
+
+	addr = addr.Unmap()
+
+	for _, bits := range inv.bits {
+		if bits > addr.BitLen() {
+			continue
+		}
+
+		prefix, err := addr.Prefix(bits)
+		if err != nil {
+			continue
+		}
+
+		if row, ok := inv.prefixes[prefix]; ok {
+			return row
+		}
+	}
+
+	return nil
+}
+
+func normalizeHost(host string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+}
+
+func readCSVInventory(r io.Reader) ([]map[string]string, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []map[string]string
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		row := make(map[string]string, len(header))
+		for i, column := range header {
+			row[strings.TrimSpace(column)] = record[i]
+		}
+
+		rows = append(rows, row)
+	}
+}
+
+// readJSONInventory reads a list of objects. The values which are not strings
+// are kept in their JSON form.
+func readJSONInventory(r io.Reader) ([]map[string]string, error) {
+	var objects []map[string]json.RawMessage
+
+	if err := json.NewDecoder(r).Decode(&objects); err != nil {
+		return nil, err
+	}
+
+	rows := make([]map[string]string, 0, len(objects))
+
+	for _, obj := range objects {
+		row := make(map[string]string, len(obj))
+
+		for k, raw := range obj {
+			if string(raw) == "null" {
+				continue
+			}
+
+			var s string
+			if err := json.Unmarshal(raw, &s); err == nil {
+				row[k] = s
+				continue
+			}
+
+			row[k] = string(raw)
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// EnrichersInit loads the configured enrichers and reloads them when their
+// files change. It replaces the enrichers of a previous call.
+func EnrichersInit(cfgs []*csconfig.EnricherCfg) error {
+	EnrichersClose()
+
+	enrichers := make(map[string]*customEnricher, len(cfgs))
+
+	for _, cfg := range cfgs {
+		e := &customEnricher{cfg: *cfg}
+		if err := e.load(); err != nil {
+			return fmt.Errorf("enricher %s: %w", cfg.Name, err)
+		}
+
+		log.Infof("loaded enricher %s (%s) from %s", cfg.Name, cfg.Type, cfg.Path)
+		enrichers[cfg.Name] = e
+	}
+
+	customEnrichersMu.Lock()
+	defer customEnrichersMu.Unlock()
+
+	customEnrichers = enrichers
+
+	if len(enrichers) == 0 {
+		return nil
+	}
+
+	enricherWatcher = newFileWatcher(defaultPollInterval)
+
+	for _, e := range enrichers {
+		metrics.DataFileLastReload.WithLabelValues(e.cfg.Name).SetToCurrentTime()
+		enricherWatcher.add(e.cfg.Name, e.cfg.Path, e.load)
+	}
+
+	return nil
+}
+
+// EnrichersClose stops watching the files and removes the enrichers.
+func EnrichersClose() {
+	customEnrichersMu.Lock()
+	defer customEnrichersMu.Unlock()
+
+	if enricherWatcher != nil {
+		enricherWatcher.close()
+		enricherWatcher = nil
+	}
+
+	customEnrichers = map[string]*customEnricher{}
+}
+
+// EnricherNames returns the names of the configured enrichers.
+func EnricherNames() []string {
+	customEnrichersMu.RLock()
+	defer customEnrichersMu.RUnlock()
+
+	names := make([]string, 0, len(customEnrichers))
+	for name := range customEnrichers {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	return names
+}
+
+func getEnricher(name string) *customEnricher {
+	customEnrichersMu.RLock()
+	defer customEnrichersMu.RUnlock()
+
+	return customEnrichers[name]
+}
+
+// Enrich returns the fields of the configuration for the entry of value (an
+// IP address, or a hostname for an inventory), to be added to evt.Enriched.
+func Enrich(name string, value string) (map[string]string, error) {
+	e := getEnricher(name)
+	if e == nil {
+		return nil, fmt.Errorf("unknown enricher %q", name)
+	}
+
+	switch e.cfg.Type {
+	case csconfig.EnricherMMDB:
+		record, err := mmdbLookup(e, value)
+		if err != nil || record == nil {
+			return nil, err
+		}
+
+		ret := make(map[string]string, len(e.cfg.Fields))
+
+		for key, path := range e.cfg.Fields {
+			if v, ok := recordPath(record, path); ok {
+				ret[key] = v
+			}
+		}
+
+		return ret, nil
+	default:
+		row := e.inventory.Load().lookup(value)
+		if row == nil {
+			return nil, nil
+		}
+
+		if len(e.cfg.Fields) == 0 {
+			ret := make(map[string]string, len(row))
+			for k, v := range row {
+				if k != e.cfg.Key {
+					ret[k] = v
+				}
+			}
+
+			return ret, nil
+		}
+
+		ret := make(map[string]string, len(e.cfg.Fields))
+
+		for key, column := range e.cfg.Fields {
+			if v, ok := row[column]; ok {
+				ret[key] = v
+			}
+		}
+
+		return ret, nil
+	}
+}
+
+func mmdbLookup(e *customEnricher, ip string) (map[string]any, error) {
+	parsedIP := net.ParseIP(strings.TrimSpace(ip))
+	if parsedIP == nil {
+		return nil, nil
+	}
+
+	var record map[string]any
+
+	if err := e.mmdb.Load().Lookup(parsedIP, &record); err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// recordPath returns the value at a dotted path (country.names.en, subdivisions.0.iso_code) as a string.
+func recordPath(record map[string]any, path string) (string, bool) {
+	var cur any = record
+
+	for part := range strings.SplitSeq(path, ".") {
+		switch v := cur.(type) {
+		case map[string]any:
+			next, ok := v[part]
+			if !ok {
+				return "", false
+			}
+
+			cur = next
+		case []any:
+			idx, err := strconv.Atoi(part)
+			if err != nil || idx < 0 || idx >= len(v) {
+				return "", false
+			}
+
+			cur = v[idx]
+		default:
+			return "", false
+		}
+	}
+
+	switch v := cur.(type) {
+	case nil:
+		return "", false
+	case string:
+		return v, true
+	case map[string]any, []any:
+		buf, err := json.Marshal(v)
+		if err != nil {
+			return "", false
+		}
+
+		return string(buf), true
+	default:
+		return fmt.Sprint(v), true
+	}
+}
+
+// func MMDBLookup(name string, ip string) map[string]any {
+func MMDBLookup(params ...any) (any, error) {
+	name := params[0].(string)
+	ip := params[1].(string)
+
+	e := getEnricher(name)
+	if e == nil || e.cfg.Type != csconfig.EnricherMMDB {
+		log.Errorf("MMDBLookup: unknown mmdb enricher %q", name)
+		return map[string]any(nil), nil
+	}
+
+	record, err := mmdbLookup(e, ip)
+	if err != nil {
+		log.Errorf("MMDBLookup: %s: %s", name, err)
+		return map[string]any(nil), nil
+	}
+
+	return record, nil
+}
+
+// func AssetLookup(name string, value string) map[string]string {
+func AssetLookup(params ...any) (any, error) {
+	name := params[0].(string)
+	value := params[1].(string)
+
+	e := getEnricher(name)
+	if e == nil || e.cfg.Type != csconfig.EnricherInventory {
+		log.Errorf("AssetLookup: unknown inventory enricher %q", name)
+		return map[string]string(nil), nil
+	}
+
+	return e.inventory.Load().lookup(value), nil
+}
@@ -0,0 +1,175 @@
+package exprhelpers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/expr-lang/expr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/crowdsecurity/crowdsec/pkg/csconfig"
+)
+
+func TestAssetInventory(t *testing.T) {
+	err := EnrichersInit([]*csconfig.EnricherCfg{
+		{Name: "assets", Type: csconfig.EnricherInventory, Path: "testdata/test_assets.csv", Key: "key"},
+		{Name: "cmdb", Type: csconfig.EnricherInventory, Path: "testdata/test_assets.json", Key: "host", Fields: map[string]string{"Owner": "owner", "Tier": "tier"}},
+	})
+	require.NoError(t, err)
+	t.Cleanup(EnrichersClose)
+
+	assert.Equal(t, []string{"assets", "cmdb"}, EnricherNames())
+
+	tests := []struct {
+		name     string
+		enricher string
+		value    string
+		expected map[string]string
+	}{
+		{"address", "assets", "192.0.2.10", map[string]string{"owner": "web-team", "criticality": "high", "environment": "production"}},
+		{"range", "assets", "192.0.2.11", map[string]string{"owner": "network-team", "criticality": "medium", "environment": "staging"}},
+		{"mapped address", "assets", "::ffff:192.0.2.12", map[string]string{"owner": "network-team", "criticality": "medium", "environment": "staging"}},
+		{"ipv6 range", "assets", "2001:db8::1", map[string]string{"owner": "network-team", "criticality": "low", "environment": "lab"}},
+		{"hostname", "assets", "DB1.example.com", map[string]string{"owner": "dba-team", "criticality": "critical", "environment": "production"}},
+		{"not found", "assets", "198.51.100.1", nil},
+		{"field mapping", "cmdb", "10.1.2.3", map[string]string{"Owner": "ops", "Tier": "1"}},
+		{"null value", "cmdb", "build.example.com", map[string]string{"Owner": "ci"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ret, err := Enrich(tc.enricher, tc.value)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, ret)
+		})
+	}
+
+	_, err = Enrich("nope", "192.0.2.10")
+	require.Error(t, err)
+}
+
+func TestAssetLookupExpr(t *testing.T) {
+	require.NoError(t, Init(nil))
+
+	err := EnrichersInit([]*csconfig.EnricherCfg{
+		{Name: "assets", Type: csconfig.EnricherInventory, Path: "testdata/test_assets.csv", Key: "key"},
+	})
+	require.NoError(t, err)
+	t.Cleanup(EnrichersClose)
+
+	env := map[string]any{"target": ""}
+
+	program, err := expr.Compile(`AssetLookup("assets", target)["environment"] == "production" ? "24h" : "4h"`, GetExprOptions(env)...)
+	require.NoError(t, err)
+
+	for target, expected := range map[string]string{
+		"192.0.2.10":      "24h",
+		"192.0.2.20":      "4h",
+		"db1.example.com": "24h",
+		"unknown":         "4h",
+	} {
+		env["target"] = target
+
+		out, err := expr.Run(program, env)
+		require.NoError(t, err)
+		assert.Equal(t, expected, out, target)
+	}
+}
+
+func TestMMDBEnricher(t *testing.T) {
+	require.NoError(t, Init(nil))
+
+	err := EnrichersInit([]*csconfig.EnricherCfg{
+		{
+			Name:   "asn",
+			Type:   csconfig.EnricherMMDB,
+			Path:   "../parser/testdata/GeoLite2-ASN.mmdb",
+			Fields: map[string]string{"Org": "autonomous_system_organization", "Missing": "no.such.field"},
+		},
+	})
+	require.NoError(t, err)
+	t.Cleanup(EnrichersClose)
+
+	ret, err := Enrich("asn", "1.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"Org": "Google Inc."}, ret)
+
+	ret, err = Enrich("asn", "not an ip")
+	require.NoError(t, err)
+	assert.Nil(t, ret)
+
+	env := map[string]any{}
+
+	program, err := expr.Compile(`MMDBLookup("asn", "1.0.0.1")?.autonomous_system_organization`, GetExprOptions(env)...)
+	require.NoError(t, err)
+
+	out, err := expr.Run(program, env)
+	require.NoError(t, err)
+	assert.Equal(t, "Google Inc.", out)
+}
+
+func TestRecordPath(t *testing.T) {
+	record := map[string]any{
+		"country":      map[string]any{"iso_code": "FR", "names": map[string]any{"en": "France"}},
+		"subdivisions": []any{map[string]any{"iso_code": "IDF"}},
+		"asn":          uint64(64496),
+		"flags":        []any{"a", "b"},
+	}
+
+	for path, expected := range map[string]string{
+		"country.iso_code":        "FR",
+		"country.names.en":        "France",
+		"subdivisions.0.iso_code": "IDF",
+		"asn":                     "64496",
+		"flags":                   `["a","b"]`,
+	} {
+		v, ok := recordPath(record, path)
+		assert.True(t, ok, path)
+		assert.Equal(t, expected, v, path)
+	}
+
+	for _, path := range []string{"country.code", "subdivisions.1.iso_code", "asn.value", "subdivisions.x"} {
+		_, ok := recordPath(record, path)
+		assert.False(t, ok, path)
+	}
+}
+
+func TestEnricherReload(t *testing.T) {
+	reloadDelay = 10 * time.Millisecond
+
+	t.Cleanup(func() { reloadDelay = time.Second })
+
+	path := filepath.Join(t.TempDir(), "assets.csv")
+	require.NoError(t, os.WriteFile(path, []byte("key,environment\n192.0.2.1,staging\n"), 0o600))
+
+	err := EnrichersInit([]*csconfig.EnricherCfg{
+		{Name: "assets", Type: csconfig.EnricherInventory, Path: path, Key: "key"},
+	})
+	require.NoError(t, err)
+	t.Cleanup(EnrichersClose)
+
+	ret, err := Enrich("assets", "192.0.2.1")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"environment": "staging"}, ret)
+
+	// an invalid file keeps the previous content
+	require.NoError(t, os.WriteFile(path, []byte("key,environment\n,production\n"), 0o600))
+	time.Sleep(100 * time.Millisecond)
+
+	ret, err = Enrich("assets", "192.0.2.1")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"environment": "staging"}, ret)
+
+	// replaced, as most tools do
+	tmp := path + ".tmp"
+	require.NoError(t, os.WriteFile(tmp, []byte("key,environment\n192.0.2.1,production\n"), 0o600))
+	require.NoError(t, os.Rename(tmp, path))
+
+	require.Eventually(t, func() bool {
+		ret, err := Enrich("assets", "192.0.2.1")
+		return err == nil && ret["environment"] == "production"
+	}, 5*time.Second, 10*time.Millisecond)
+}
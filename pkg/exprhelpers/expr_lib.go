@@ -9,7 +9,6 @@ import (
 	"github.com/oschwald/geoip2-golang"
 
 	"github.com/crowdsecurity/crowdsec/pkg/cticlient"
-	"github.com/crowdsecurity/crowdsec/pkg/cticlient/ctiexpr"
 )
 
 type exprCustomFunc struct {
@@ -21,7 +20,7 @@ type exprCustomFunc struct {
 var exprFuncs = []exprCustomFunc{
 	{
 		name:     "CrowdsecCTI",
-		function: ctiexpr.CrowdsecCTI,
+		function: CrowdsecCTI,
 		signature: []any{
 			new(func(string) (*cticlient.SmokeItem, error)),
 		},
@@ -545,6 +544,27 @@ var exprFuncs = []exprCustomFunc{
 			new(func(string) string),
 		},
 	},
+	{
+		name:     "MMDBLookup",
+		function: MMDBLookup,
+		signature: []any{
+			new(func(string, string) map[string]any),
+		},
+	},
+	{
+		name:     "AssetLookup",
+		function: AssetLookup,
+		signature: []any{
+			new(func(string, string) map[string]string),
+		},
+	},
+	{
+		name:     "TIMatch",
+		function: TIMatch,
+		signature: []any{
+			new(func(string, string) *TIMatchResult),
+		},
+	},
 	{
 		name:     "JA4H",
 		function: JA4H,
@@ -2401,3 +2401,23 @@ func TestParseKvLax(t *testing.T) {
 		})
 	}
 }
+
+func TestGetPureExprOptions(t *testing.T) {
+	names := make(map[string]bool, len(exprFuncs))
+	for _, fn := range exprFuncs {
+		names[fn.name] = true
+	}
+
+	for name := range impureHelpers {
+		assert.True(t, names[name], "unknown helper %s", name)
+	}
+
+	_, err := expr.Compile(`Upper(value) == "FOO"`, GetPureExprOptions(map[string]any{"value": ""})...)
+	require.NoError(t, err)
+
+	_, err = expr.Compile(`HTTPGet(value)`, GetPureExprOptions(map[string]any{"value": ""})...)
+	require.Error(t, err)
+
+	_, err = expr.Compile(`File(value)`, GetPureExprOptions(map[string]any{"value": ""})...)
+	require.Error(t, err)
+}
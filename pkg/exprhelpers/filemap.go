@@ -39,29 +39,29 @@ type matchIndex struct {
 	regexToRow    []int // regex slice index → row index in fileMapEntry.rows
 }
 
-// fileMapInit parses a single JSON line and appends it to the fileMapEntry for the given filename.
+// parseMapLine parses a single JSON line of a map data file.
 // Three fields are mandatory: "pattern", "tag", and "type" (one of: "equals", "contains", "regex").
-func fileMapInit(filename string, line string) error {
+func parseMapLine(filename string, line string) (map[string]string, error) {
 	var record map[string]string
 	if err := json.Unmarshal([]byte(line), &record); err != nil {
-		return fmt.Errorf("failed to parse JSON line in %s: %w", filename, err)
+		return nil, fmt.Errorf("failed to parse JSON line in %s: %w", filename, err)
 	}
 
 	if record["pattern"] == "" {
-		return fmt.Errorf("missing mandatory 'pattern' field in %s: %s", filename, line)
+		return nil, fmt.Errorf("missing mandatory 'pattern' field in %s: %s", filename, line)
 	}
 
 	if record["tag"] == "" {
-		return fmt.Errorf("missing mandatory 'tag' field in %s: %s", filename, line)
+		return nil, fmt.Errorf("missing mandatory 'tag' field in %s: %s", filename, line)
 	}
 
 	entryType := record["type"]
 	if entryType == "" {
-		return fmt.Errorf("missing mandatory 'type' field in %s: %s", filename, line)
+		return nil, fmt.Errorf("missing mandatory 'type' field in %s: %s", filename, line)
 	}
 
 	if !slices.Contains(validMapEntryTypes, entryType) {
-		return fmt.Errorf("unknown entry type '%s' in %s (supported: %s): %s",
+		return nil, fmt.Errorf("unknown entry type '%s' in %s (supported: %s): %s",
 			entryType, filename, strings.Join(validMapEntryTypes, ", "), line)
 	}
 
@@ -71,13 +71,7 @@ func fileMapInit(filename string, line string) error {
 		}
 	}
 
-	if dataFileMap[filename] == nil {
-		dataFileMap[filename] = &fileMapEntry{filename: filename}
-	}
-
-	dataFileMap[filename].rows = append(dataFileMap[filename].rows, record)
-
-	return nil
+	return record, nil
 }
 
 // buildIndex builds the matchIndex from the parsed rows.
@@ -144,7 +138,10 @@ func (e *fileMapEntry) buildIndex() {
 func FileMap(params ...any) (any, error) {
 	filename := params[0].(string)
 
+	dataFilesMu.RLock()
 	entry, ok := dataFileMap[filename]
+	dataFilesMu.RUnlock()
+
 	if !ok {
 		log.Errorf("file '%s' (type:map) not found in expr library", filename)
 		return []map[string]string{}, nil
@@ -165,7 +162,10 @@ func LookupFile(params ...any) (any, error) {
 	haystack := params[0].(string)
 	filename := params[1].(string)
 
+	dataFilesMu.RLock()
 	entry, ok := dataFileMap[filename]
+	dataFilesMu.RUnlock()
+
 	if !ok {
 		log.Errorf("file '%s' (type:map) not found in expr library", filename)
 		return "", nil
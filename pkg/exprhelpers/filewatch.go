@@ -0,0 +1,231 @@
+package exprhelpers
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/crowdsecurity/crowdsec/pkg/metrics"
+)
+
+// files are often written in several steps: wait for them to settle before reloading
+var reloadDelay = time.Second
+
+const defaultPollInterval = 30 * time.Second
+
+// fileWatcher calls the reload function of a file when it changes. It watches
+// the parent directories with inotify, since the files are often replaced
+// rather than modified, and polls the files it can't watch that way.
+type fileWatcher struct {
+	pollInterval time.Duration
+	notify       *fsnotify.Watcher
+	mu           sync.Mutex
+	files        map[string]*watchedFile
+	dirs         map[string]bool
+	done         chan struct{}
+	stopped      chan struct{}
+}
+
+type watchedFile struct {
+	// for the logs and the metrics
+	name    string
+	path    string
+	reload  func() error
+	polled  bool
+	modTime time.Time
+	size    int64
+	timer   *time.Timer
+}
+
+func newFileWatcher(pollInterval time.Duration) *fileWatcher {
+	if pollInterval <= 0 {
+		pollInterval = defaultPollInterval
+	}
+
+	w := &fileWatcher{
+		pollInterval: pollInterval,
+		files:        make(map[string]*watchedFile),
+		dirs:         make(map[string]bool),
+		done:         make(chan struct{}),
+		stopped:      make(chan struct{}),
+	}
+
+	notify, err := fsnotify.NewWatcher()
+	if err != nil {
+		log.Warningf("inotify is not available, the files will be polled every %s: %s", pollInterval, err)
+	} else {
+		w.notify = notify
+	}
+
+	go w.run()
+
+	return w
+}
+
+func statFile(path string) (time.Time, int64) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, 0
+	}
+
+	return info.ModTime(), info.Size()
+}
+
+// add starts watching a file. The reload function must load the file and
+// replace the previous content only if it succeeds.
+func (w *fileWatcher) add(name string, path string, reload func() error) {
+	path = filepath.Clean(path)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, ok := w.files[path]; ok {
+		return
+	}
+
+	f := &watchedFile{name: name, path: path, reload: reload}
+	f.modTime, f.size = statFile(path)
+
+	dir := filepath.Dir(path)
+
+	switch {
+	case w.notify == nil:
+		f.polled = true
+	case w.dirs[dir]:
+	default:
+		if err := w.notify.Add(dir); err != nil {
+			log.Warningf("can't watch %s, %s will be polled every %s: %s", dir, path, w.pollInterval, err)
+
+			f.polled = true
+
+			break
+		}
+
+		w.dirs[dir] = true
+	}
+
+	w.files[path] = f
+}
+
+func (w *fileWatcher) run() {
+	defer close(w.stopped)
+
+	ticker := time.NewTicker(w.pollInterval)
+	defer ticker.Stop()
+
+	var (
+		events <-chan fsnotify.Event
+		errs   <-chan error
+	)
+
+	if w.notify != nil {
+		events = w.notify.Events
+		errs = w.notify.Errors
+	}
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case event, ok := <-events:
+			if !ok {
+				events = nil
+				continue
+			}
+
+			if event.Has(fsnotify.Write) || event.Has(fsnotify.Create) {
+				w.trigger(event.Name)
+			}
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+
+			log.Warningf("watching files: %s", err)
+		case <-ticker.C:
+			w.poll()
+		}
+	}
+}
+
+func (w *fileWatcher) poll() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, f := range w.files {
+		if !f.polled {
+			continue
+		}
+
+		modTime, size := statFile(f.path)
+		if modTime.Equal(f.modTime) && size == f.size {
+			continue
+		}
+
+		f.modTime, f.size = modTime, size
+		w.schedule(f)
+	}
+}
+
+// trigger reloads a file after it has changed, if it's watched.
+func (w *fileWatcher) trigger(path string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if f, ok := w.files[filepath.Clean(path)]; ok {
+		w.schedule(f)
+	}
+}
+
+// schedule must be called with the lock held. The changes received before
+// the delay expires are reloaded once.
+func (w *fileWatcher) schedule(f *watchedFile) {
+	if f.timer != nil {
+		f.timer.Reset(reloadDelay)
+		return
+	}
+
+	f.timer = time.AfterFunc(reloadDelay, func() {
+		// a polled file is not reloaded again for the same change
+		w.mu.Lock()
+		f.modTime, f.size = statFile(f.path)
+		w.mu.Unlock()
+
+		runReload(f.name, f.path, f.reload)
+	})
+}
+
+func runReload(name string, path string, reload func() error) {
+	if err := reload(); err != nil {
+		log.Errorf("reloading %s failed, keeping the previous content: %s", path, err)
+		metrics.DataFileReloadFailures.WithLabelValues(name, "load").Inc()
+
+		return
+	}
+
+	log.Infof("reloaded %s", path)
+	metrics.DataFileLastReload.WithLabelValues(name).SetToCurrentTime()
+}
+
+func (w *fileWatcher) close() {
+	close(w.done)
+	<-w.stopped
+
+	if w.notify != nil {
+		w.notify.Close()
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, f := range w.files {
+		if f.timer != nil {
+			f.timer.Stop()
+		}
+	}
+}
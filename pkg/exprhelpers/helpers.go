@@ -1,8 +1,6 @@
 package exprhelpers
 
 import (
-	"bufio"
-	"context"
 	"encoding/base64"
 	"errors"
 	"fmt"
@@ -35,6 +33,7 @@ import (
 	"github.com/crowdsecurity/go-cs-lib/cstime"
 
 	"github.com/crowdsecurity/crowdsec/pkg/cache"
+	"github.com/crowdsecurity/crowdsec/pkg/cticlient/ctiexpr"
 	"github.com/crowdsecurity/crowdsec/pkg/database"
 	"github.com/crowdsecurity/crowdsec/pkg/enrichment"
 	"github.com/crowdsecurity/crowdsec/pkg/fflag"
@@ -54,10 +53,44 @@ var dbClient *database.Client
 
 var exprFunctionOptions []expr.Option
 
+// the helpers that do I/O (network, files, databases), depend on the data loaded by the agent, or have side effects
+var impureHelpers = map[string]bool{
+	"CrowdsecCTI":                true,
+	"GetFromStash":               true,
+	"File":                       true,
+	"RegexpInFile":               true,
+	"FileMap":                    true,
+	"LookupFile":                 true,
+	"MatchKnownBot":              true,
+	"LookupHost":                 true,
+	"GetDecisionsCount":          true,
+	"GetActiveDecisionsCount":    true,
+	"GetActiveDecisionsTimeLeft": true,
+	"GetDecisionsSinceCount":     true,
+	"LogInfo":                    true,
+	"GeoIPEnrich":                true,
+	"GeoIPASNEnrich":             true,
+	"GeoIPRangeEnrich":           true,
+	"IPToCountry":                true,
+	"MMDBLookup":                 true,
+	"AssetLookup":                true,
+	"TIMatch":                    true,
+	"HTTPGet":                    true,
+	"HTTPHead":                   true,
+	"HTTPPost":                   true,
+	"HTTPRequest":                true,
+}
+
+var pureExprFunctionOptions []expr.Option
+
 func init() { //nolint:gochecknoinits
 	exprFunctionOptions = make([]expr.Option, len(exprFuncs))
 	for i, fn := range exprFuncs {
 		exprFunctionOptions[i] = expr.Function(fn.name, fn.function, fn.signature...)
+
+		if !impureHelpers[fn.name] {
+			pureExprFunctionOptions = append(pureExprFunctionOptions, exprFunctionOptions[i])
+		}
 	}
 }
 
@@ -79,6 +112,16 @@ func GetExprOptions(ctx map[string]any) []expr.Option {
 	return opts
 }
 
+// GetPureExprOptions is GetExprOptions without the helpers that do I/O or have side effects,
+// for the expressions that come from a user at runtime and must be cheap to run.
+func GetPureExprOptions(ctx map[string]any) []expr.Option {
+	opts := make([]expr.Option, len(pureExprFunctionOptions)+1)
+	copy(opts, pureExprFunctionOptions)
+	opts[len(opts)-1] = expr.Env(ctx)
+
+	return opts
+}
+
 func GeoIPInit(datadir string) error {
 	var err error
 
@@ -118,11 +161,14 @@ func GeoIPClose() {
 }
 
 func Init(databaseClient *database.Client) error {
+	dataFilesMu.Lock()
 	dataFile = make(map[string][]string)
 	dataFileRegex = make(map[string][]*regexp.Regexp)
 	dataFileRe2 = make(map[string][]*re2.Regexp)
 	dataFileMap = make(map[string]*fileMapEntry)
 	dataFileBots = make(map[string][]*botEntry)
+	dataFilesMu.Unlock()
+
 	dbClient = databaseClient
 
 	XMLCacheInit()
@@ -135,6 +181,12 @@ func Init(databaseClient *database.Client) error {
 // The DNS cache (pkg/dnscache) is deliberately kept: DNS facts don't change
 // with the configuration, and a reload shouldn't trigger a re-lookup storm.
 func ResetDataFiles() {
+	StopWatchingDataFiles()
+
+	dataFilesMu.Lock()
+	defer dataFilesMu.Unlock()
+
+	dataFileSources = map[string]*dataFileSource{}
 	dataFile = make(map[string][]string)
 	dataFileRegex = make(map[string][]*regexp.Regexp)
 	dataFileRe2 = make(map[string][]*re2.Regexp)
@@ -182,7 +234,10 @@ func RegexpCacheInit(filename string, cacheCfg enrichment.DataProvider) error {
 	}
 
 	cache := gc.Build()
+
+	dataFilesMu.Lock()
 	dataFileRegexCache[filename] = cache
+	dataFilesMu.Unlock()
 
 	return nil
 }
@@ -191,6 +246,9 @@ func RegexpCacheInit(filename string, cacheCfg enrichment.DataProvider) error {
 func UpdateRegexpCacheMetrics() {
 	metrics.RegexpCacheMetrics.Reset()
 
+	dataFilesMu.RLock()
+	defer dataFilesMu.RUnlock()
+
 	for name := range dataFileRegexCache {
 		metrics.RegexpCacheMetrics.With(prometheus.Labels{"name": name}).Set(float64(dataFileRegexCache[name].Len(true)))
 	}
@@ -210,7 +268,10 @@ func FileInit(directory string, filename string, fileType string) error {
 		return nil
 	}
 
+	dataFilesMu.RLock()
 	ok, err := existsInFileMaps(filename, fileType)
+	dataFilesMu.RUnlock()
+
 	if ok {
 		log.Debugf("ignored file %s%s because already loaded", directory, filename)
 		return nil
@@ -220,53 +281,18 @@ func FileInit(directory string, filename string, fileType string) error {
 		return err
 	}
 
-	file, err := os.Open(filepath.Join(directory, filename))
+	content, err := readDataFile(filepath.Join(directory, filename), filename, fileType)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		if strings.HasPrefix(scanner.Text(), "#") { // allow comments
-			continue
-		}
-
-		if scanner.Text() == "" { // skip empty lines
-			continue
-		}
 
-		switch fileType {
-		case "regex", "regexp":
-			if fflag.Re2RegexpInfileSupport.IsEnabled() {
-				dataFileRe2[filename] = append(dataFileRe2[filename], re2.MustCompile(scanner.Text()))
-				continue
-			}
+	dataFilesMu.Lock()
+	defer dataFilesMu.Unlock()
 
-			dataFileRegex[filename] = append(dataFileRegex[filename], regexp.MustCompile(scanner.Text()))
-		case "string":
-			dataFile[filename] = append(dataFile[filename], scanner.Text())
-		case "map":
-			if err := fileMapInit(filename, scanner.Text()); err != nil {
-				return err
-			}
-		case "bots":
-			if err := botFileInit(filename, scanner.Text()); err != nil {
-				return err
-			}
-		}
-	}
+	storeDataFile(filename, fileType, content)
+	registerDataFile(directory, filename, fileType)
 
-	if err := scanner.Err(); err != nil {
-		return err
-	}
-
-	// Build the match index eagerly so errors surface at load time.
-	if fileType == "map" {
-		if entry, ok := dataFileMap[filename]; ok {
-			entry.buildIndex()
-		}
-	}
+	metrics.DataFileLastReload.WithLabelValues(filename).SetToCurrentTime()
 
 	return nil
 }
@@ -471,6 +497,10 @@ func QueryUnescape(params ...any) (any, error) {
 // func File(filename string) []string {
 func File(params ...any) (any, error) {
 	filename := params[0].(string)
+
+	dataFilesMu.RLock()
+	defer dataFilesMu.RUnlock()
+
 	if _, ok := dataFile[filename]; ok {
 		return dataFile[filename], nil
 	}
@@ -488,22 +518,34 @@ func RegexpInFile(params ...any) (any, error) {
 
 	var hash uint64
 
-	hasCache := false
 	matched := false
 
-	if _, ok := dataFileRegexCache[filename]; ok {
-		hasCache = true
+	// the slices are replaced, not modified, when the file is reloaded
+	dataFilesMu.RLock()
+	cache, hasCache := dataFileRegexCache[filename]
+	regexps, hasRegexps := dataFileRegex[filename]
+	re2s, hasRe2s := dataFileRe2[filename]
+	dataFilesMu.RUnlock()
+
+	if hasCache {
 		hash = xxhash.Sum64String(data)
 
-		if val, err := dataFileRegexCache[filename].Get(hash); err == nil {
+		if val, err := cache.Get(hash); err == nil {
 			return val.(bool), nil
 		}
 	}
 
+	ctx, cancel := helperContext()
+	defer cancel()
+
 	switch fflag.Re2RegexpInfileSupport.IsEnabled() {
 	case true:
-		if _, ok := dataFileRe2[filename]; ok {
-			for _, re := range dataFileRe2[filename] {
+		if hasRe2s {
+			for _, re := range re2s {
+				if err := helperBudgetError(ctx); err != nil {
+					return false, err
+				}
+
 				if re.MatchString(data) {
 					matched = true
 					break
@@ -511,11 +553,17 @@ func RegexpInFile(params ...any) (any, error) {
 			}
 		} else {
 			log.Errorf("file '%s' (type:regexp) not found in expr library", filename)
+			dataFilesMu.RLock()
 			log.Errorf("expr library : %s", spew.Sdump(dataFileRe2))
+			dataFilesMu.RUnlock()
 		}
 	case false:
-		if _, ok := dataFileRegex[filename]; ok {
-			for _, re := range dataFileRegex[filename] {
+		if hasRegexps {
+			for _, re := range regexps {
+				if err := helperBudgetError(ctx); err != nil {
+					return false, err
+				}
+
 				if re.MatchString(data) {
 					matched = true
 					break
@@ -523,12 +571,14 @@ func RegexpInFile(params ...any) (any, error) {
 			}
 		} else {
 			log.Errorf("file '%s' (type:regexp) not found in expr library", filename)
+			dataFilesMu.RLock()
 			log.Errorf("expr library : %s", spew.Sdump(dataFileRegex))
+			dataFilesMu.RUnlock()
 		}
 	}
 
 	if hasCache {
-		dataFileRegexCache[filename].Set(hash, matched)
+		cache.Set(hash, matched)
 	}
 
 	return matched, nil
@@ -768,10 +818,15 @@ func GetDecisionsCount(params ...any) (any, error) {
 		return 0, nil
 	}
 
-	ctx := context.TODO()
+	ctx, cancel := helperContext()
+	defer cancel()
 
 	count, err := dbClient.CountDecisionsByValue(ctx, value, nil, false)
 	if err != nil {
+		if budgetErr := helperBudgetError(ctx); budgetErr != nil {
+			return 0, budgetErr
+		}
+
 		log.Errorf("Failed to get decisions count from value '%s'", value)
 		return 0, nil //nolint:nilerr // This helper did not return an error before the move to expr.Function, we keep this behavior for backward compatibility
 	}
@@ -779,6 +834,19 @@ func GetDecisionsCount(params ...any) (any, error) {
 	return count, nil
 }
 
+// func CrowdsecCTI(ip string) (*cticlient.SmokeItem, error) {
+func CrowdsecCTI(params ...any) (any, error) {
+	ctx, cancel := helperContext()
+	defer cancel()
+
+	item, err := ctiexpr.CrowdsecCTIContext(ctx, params...)
+	if budgetErr := helperBudgetError(ctx); err != nil && budgetErr != nil {
+		return item, budgetErr
+	}
+
+	return item, err
+}
+
 // func GetDecisionsSinceCount(value string, since string) int {
 func GetDecisionsSinceCount(params ...any) (any, error) {
 	value := params[0].(string)
@@ -795,11 +863,16 @@ func GetDecisionsSinceCount(params ...any) (any, error) {
 		return 0, nil
 	}
 
-	ctx := context.TODO()
+	ctx, cancel := helperContext()
+	defer cancel()
 	sinceTime := time.Now().UTC().Add(-sinceDuration)
 
 	count, err := dbClient.CountDecisionsByValue(ctx, value, &sinceTime, false)
 	if err != nil {
+		if budgetErr := helperBudgetError(ctx); budgetErr != nil {
+			return 0, budgetErr
+		}
+
 		log.Errorf("Failed to get decisions count from value '%s'", value)
 		return 0, nil //nolint:nilerr // This helper did not return an error before the move to expr.Function, we keep this behavior for backward compatibility
 	}
@@ -815,10 +888,15 @@ func GetActiveDecisionsCount(params ...any) (any, error) {
 		return 0, nil
 	}
 
-	ctx := context.TODO()
+	ctx, cancel := helperContext()
+	defer cancel()
 
 	count, err := dbClient.CountDecisionsByValue(ctx, value, nil, true)
 	if err != nil {
+		if budgetErr := helperBudgetError(ctx); budgetErr != nil {
+			return 0, budgetErr
+		}
+
 		log.Errorf("Failed to get active decisions count from value '%s'", value)
 		return 0, err
 	}
@@ -834,10 +912,15 @@ func GetActiveDecisionsTimeLeft(params ...any) (any, error) {
 		return 0, nil
 	}
 
-	ctx := context.TODO()
+	ctx, cancel := helperContext()
+	defer cancel()
 
 	timeLeft, err := dbClient.GetActiveDecisionsTimeLeftByValue(ctx, value)
 	if err != nil {
+		if budgetErr := helperBudgetError(ctx); budgetErr != nil {
+			return 0, budgetErr
+		}
+
 		log.Errorf("Failed to get active decisions time left from value '%s'", value)
 		return 0, err
 	}
@@ -849,8 +932,15 @@ func GetActiveDecisionsTimeLeft(params ...any) (any, error) {
 func LookupHost(params ...any) (any, error) {
 	value := params[0].(string)
 
-	addresses, err := net.LookupHost(value)
+	ctx, cancel := helperContext()
+	defer cancel()
+
+	addresses, err := net.DefaultResolver.LookupHost(ctx, value)
 	if err != nil {
+		if budgetErr := helperBudgetError(ctx); budgetErr != nil {
+			return []string{}, budgetErr
+		}
+
 		log.Errorf("Failed to lookup host '%s' : %s", value, err)
 		return []string{}, nil
 	}
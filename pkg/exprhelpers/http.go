@@ -1,7 +1,6 @@
 package exprhelpers
 
 import (
-	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -40,7 +39,10 @@ var exprHTTPClient = &http.Client{
 }
 
 func doHTTPRequest(method, uri string, headers map[string]string, body io.Reader) (*HTTPResponse, error) {
-	req, err := http.NewRequestWithContext(context.Background(), method, uri, body)
+	ctx, cancel := helperContext()
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, method, uri, body)
 	if err != nil {
 		return nil, err
 	}
@@ -51,6 +53,10 @@ func doHTTPRequest(method, uri string, headers map[string]string, body io.Reader
 
 	resp, err := exprHTTPClient.Do(req)
 	if err != nil {
+		if budgetErr := helperBudgetError(ctx); budgetErr != nil {
+			return nil, budgetErr
+		}
+
 		return nil, err
 	}
 	defer resp.Body.Close()
@@ -0,0 +1,263 @@
+package exprhelpers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+	"weak"
+
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/vm"
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/crowdsecurity/crowdsec/pkg/csconfig"
+	"github.com/crowdsecurity/crowdsec/pkg/metrics"
+)
+
+var (
+	ErrTimeBudgetExceeded = errors.New("expression time budget exceeded")
+	ErrStepBudgetExceeded = errors.New("expression step budget exceeded")
+)
+
+// exprLimits is the profiling and budget configuration, nil when they are all disabled.
+type exprLimits struct {
+	profile    bool
+	timeBudget time.Duration
+	stepBudget uint
+}
+
+var limits atomic.Pointer[exprLimits]
+
+// programLabel identifies a compiled program in the metrics.
+type programLabel struct {
+	item     string
+	field    string
+	once     sync.Once
+	duration prometheus.Observer
+	warned   atomic.Bool // an aborted evaluation has been logged
+}
+
+func (l *programLabel) observe(elapsed time.Duration) {
+	l.once.Do(func() {
+		l.duration = metrics.ExprDuration.WithLabelValues(l.item, l.field)
+	})
+
+	l.duration.Observe(elapsed.Seconds())
+}
+
+// the programs that were not labeled are reported together
+var unlabeled = &programLabel{item: "unknown", field: "unknown"}
+
+// by weak.Pointer[vm.Program], so that the programs of a previous configuration can be collected
+var programLabels sync.Map
+
+// Label associates a compiled program with the hub item and the field it was
+// compiled from, to report its evaluations in the metrics. A program keeps
+// its first label. It returns the program.
+func Label(program *vm.Program, item, field string) *vm.Program {
+	if program == nil {
+		return nil
+	}
+
+	key := weak.Make(program)
+
+	if _, loaded := programLabels.LoadOrStore(key, &programLabel{item: item, field: field}); !loaded {
+		runtime.AddCleanup(program, func(key weak.Pointer[vm.Program]) {
+			programLabels.Delete(key)
+		}, key)
+	}
+
+	return program
+}
+
+func labelOf(program *vm.Program) *programLabel {
+	if l, ok := programLabels.Load(weak.Make(program)); ok {
+		return l.(*programLabel)
+	}
+
+	return unlabeled
+}
+
+// ConfigureExpressions enables the duration histograms and the budgets of the
+// expressions evaluated with Run.
+//
+// With a budget, the program is run one instruction at a time, like with the
+// debugger, and aborted between two instructions when it goes over the number
+// of steps or the duration. The helpers that wait for the network or the
+// database get a context that ends with the time budget: they return
+// ErrTimeBudgetExceeded instead of blocking the evaluation. Stepping makes the
+// evaluations several times slower.
+//
+// The expressions of the items with debug enabled are evaluated by the
+// debugger, without profiling or budgets.
+func ConfigureExpressions(cfg *csconfig.ExpressionsCfg) {
+	if cfg == nil || (!cfg.Profile && cfg.TimeBudget <= 0 && cfg.StepBudget == 0) {
+		limits.Store(nil)
+		return
+	}
+
+	l := &exprLimits{
+		profile:    cfg.Profile,
+		timeBudget: cfg.TimeBudget,
+		stepBudget: cfg.StepBudget,
+	}
+
+	if !vmCanStep && (l.timeBudget > 0 || l.stepBudget > 0) {
+		log.Warning("the expression budgets require a build with the expr_debug tag, they are disabled")
+
+		l.timeBudget = 0
+		l.stepBudget = 0
+	}
+
+	limits.Store(l)
+}
+
+// helperContext is the context of the helpers that can block the evaluation,
+// with the time budget as timeout.
+func helperContext() (context.Context, context.CancelFunc) {
+	if l := limits.Load(); l != nil && l.timeBudget > 0 {
+		return context.WithTimeout(context.Background(), l.timeBudget)
+	}
+
+	return context.Background(), func() {}
+}
+
+// helperBudgetError returns ErrTimeBudgetExceeded if the context of a helper
+// ended with the time budget.
+func helperBudgetError(ctx context.Context) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return ErrTimeBudgetExceeded
+	}
+
+	return nil
+}
+
+type stepResult struct {
+	ret any
+	err error
+}
+
+// runStepped runs the program one instruction at a time, the way RunWithDebug
+// does, and aborts it over the budgets. It returns ErrStepBudgetExceeded or
+// ErrTimeBudgetExceeded, without waiting for an instruction that's still running.
+func runStepped(program *vm.Program, env any, l *exprLimits) (any, error) {
+	if len(program.Bytecode) == 0 {
+		return expr.Run(program, env)
+	}
+
+	// The VM can't be stopped: to abort, its copy of the program loses the
+	// arguments of the instructions, and it panics on the next one.
+	running := *program
+	machine := vm.Debug()
+	done := make(chan stepResult, 1)
+
+	go func() {
+		ret, err := machine.Run(&running, env)
+		done <- stepResult{ret: ret, err: err}
+	}()
+
+	// after a step, the VM either sends its position or returns
+	abort := func() {
+		select {
+		case ip, ok := <-machine.Position():
+			if ok && ip < len(running.Bytecode) {
+				running.Arguments = nil
+				machine.Step()
+			}
+		case <-done:
+		}
+	}
+
+	var deadline <-chan time.Time
+
+	if l.timeBudget > 0 {
+		timer := time.NewTimer(l.timeBudget)
+		defer timer.Stop()
+
+		deadline = timer.C
+	}
+
+	steps := uint(0)
+
+	machine.Step()
+
+	for {
+		select {
+		case r := <-done:
+			return r.ret, r.err
+		case <-deadline:
+			go abort()
+			return nil, ErrTimeBudgetExceeded
+		case ip, ok := <-machine.Position():
+			if !ok || ip >= len(running.Bytecode) {
+				// the VM is returning
+				r := <-done
+				return r.ret, r.err
+			}
+
+			steps++
+
+			if l.stepBudget > 0 && steps >= l.stepBudget {
+				running.Arguments = nil
+				machine.Step()
+				<-done
+
+				return nil, ErrStepBudgetExceeded
+			}
+
+			machine.Step()
+		}
+	}
+}
+
+func runLimited(program *vm.Program, env any, logger *log.Entry, l *exprLimits) (any, error) {
+	var (
+		ret any
+		err error
+	)
+
+	label := labelOf(program)
+
+	start := time.Now()
+
+	if l.timeBudget > 0 || l.stepBudget > 0 {
+		ret, err = runStepped(program, env, l)
+	} else {
+		ret, err = expr.Run(program, env)
+	}
+
+	elapsed := time.Since(start)
+
+	if l.profile {
+		label.observe(elapsed)
+	}
+
+	var budget string
+
+	switch {
+	case errors.Is(err, ErrTimeBudgetExceeded):
+		budget = "time"
+	case errors.Is(err, ErrStepBudgetExceeded):
+		budget = "steps"
+	default:
+		return ret, err
+	}
+
+	metrics.ExprBudgetExceeded.WithLabelValues(label.item, label.field, budget).Inc()
+
+	if label.warned.CompareAndSwap(false, true) {
+		if logger == nil {
+			logger = log.NewEntry(log.StandardLogger())
+		}
+
+		logger.Warningf("expression of %s (%s) aborted after %s: %s: %s",
+			label.item, label.field, elapsed.Round(time.Microsecond), err, cleanTextForDebug(program.Source().String()))
+	}
+
+	return nil, fmt.Errorf("%w in %s (%s)", err, label.item, label.field)
+}
@@ -0,0 +1,135 @@
+package exprhelpers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/vm"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/crowdsecurity/crowdsec/pkg/csconfig"
+	"github.com/crowdsecurity/crowdsec/pkg/metrics"
+)
+
+func compileTestExpr(t *testing.T, code string) *vm.Program {
+	t.Helper()
+
+	program, err := expr.Compile(code, expr.Function("sleep", func(params ...any) (any, error) {
+		time.Sleep(time.Duration(params[0].(int)) * time.Millisecond)
+		return true, nil
+	}))
+	require.NoError(t, err)
+
+	return program
+}
+
+func configureTestExpressions(t *testing.T, cfg *csconfig.ExpressionsCfg) {
+	t.Helper()
+
+	ConfigureExpressions(cfg)
+	t.Cleanup(func() { ConfigureExpressions(nil) })
+}
+
+func TestLabel(t *testing.T) {
+	program := compileTestExpr(t, "1 + 1")
+
+	assert.Same(t, unlabeled, labelOf(program))
+	assert.Same(t, program, Label(program, "crowdsecurity/test", "filter"))
+
+	l := labelOf(program)
+	assert.Equal(t, "crowdsecurity/test", l.item)
+	assert.Equal(t, "filter", l.field)
+
+	// a shared program keeps its first label
+	Label(program, "crowdsecurity/other", "groupby")
+	assert.Same(t, l, labelOf(program))
+
+	assert.Nil(t, Label(nil, "crowdsecurity/test", "filter"))
+}
+
+func TestRunProfile(t *testing.T) {
+	program := Label(compileTestExpr(t, "1 + 1 == 2"), "test/profile", "filter")
+	logger := log.NewEntry(log.New())
+
+	// disabled
+	ConfigureExpressions(&csconfig.ExpressionsCfg{})
+	assert.Nil(t, limits.Load())
+
+	configureTestExpressions(t, &csconfig.ExpressionsCfg{Profile: true})
+
+	for range 3 {
+		ret, err := Run(program, nil, logger, false)
+		require.NoError(t, err)
+		assert.Equal(t, true, ret)
+	}
+
+	m := &dto.Metric{}
+	require.NoError(t, metrics.ExprDuration.WithLabelValues("test/profile", "filter").(prometheus.Metric).Write(m))
+	assert.Equal(t, uint64(3), m.GetHistogram().GetSampleCount())
+}
+
+func TestRunTimeBudget(t *testing.T) {
+	program := Label(compileTestExpr(t, "sleep(500)"), "test/slow", "filter")
+	fast := Label(compileTestExpr(t, "1 + 1 == 2"), "test/fast", "filter")
+	logger := log.NewEntry(log.New())
+
+	configureTestExpressions(t, &csconfig.ExpressionsCfg{TimeBudget: 20 * time.Millisecond})
+
+	// the caller doesn't wait for the running instruction
+	start := time.Now()
+	_, err := Run(program, nil, logger, false)
+	require.ErrorIs(t, err, ErrTimeBudgetExceeded)
+	assert.Contains(t, err.Error(), "in test/slow (filter)")
+	assert.Less(t, time.Since(start), 400*time.Millisecond)
+
+	ret, err := Run(fast, nil, logger, false)
+	require.NoError(t, err)
+	assert.Equal(t, true, ret)
+
+	assert.InDelta(t, 1.0, testutil.ToFloat64(metrics.ExprBudgetExceeded.WithLabelValues("test/slow", "filter", "time")), 0)
+	assert.InDelta(t, 0.0, testutil.ToFloat64(metrics.ExprBudgetExceeded.WithLabelValues("test/fast", "filter", "time")), 0)
+	assert.True(t, labelOf(program).warned.Load())
+}
+
+func TestRunTimeBudgetHelper(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer ts.Close()
+
+	configureTestExpressions(t, &csconfig.ExpressionsCfg{TimeBudget: 20 * time.Millisecond})
+
+	start := time.Now()
+	_, err := HTTPGet(ts.URL)
+	require.ErrorIs(t, err, ErrTimeBudgetExceeded)
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+}
+
+func TestRunStepBudget(t *testing.T) {
+	program := Label(compileTestExpr(t, "len(filter(1..1000, # % 2 == 0))"), "test/loop", "groupby")
+	logger := log.NewEntry(log.New())
+
+	configureTestExpressions(t, &csconfig.ExpressionsCfg{StepBudget: 100})
+
+	_, err := Run(program, nil, logger, false)
+	require.ErrorIs(t, err, ErrStepBudgetExceeded)
+	assert.Contains(t, err.Error(), "in test/loop (groupby)")
+	assert.InDelta(t, 1.0, testutil.ToFloat64(metrics.ExprBudgetExceeded.WithLabelValues("test/loop", "groupby", "steps")), 0)
+
+	configureTestExpressions(t, &csconfig.ExpressionsCfg{StepBudget: 100000})
+
+	ret, err := Run(program, nil, logger, false)
+	require.NoError(t, err)
+	assert.Equal(t, 500, ret)
+}
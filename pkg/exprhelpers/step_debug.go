@@ -0,0 +1,6 @@
+//go:build expr_debug
+
+package exprhelpers
+
+// the expr VM only waits for the steps of vm.Debug() when built with expr_debug
+const vmCanStep = true
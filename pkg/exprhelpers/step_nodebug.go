@@ -0,0 +1,5 @@
+//go:build !expr_debug
+
+package exprhelpers
+
+const vmCanStep = false
@@ -0,0 +1,625 @@
+package exprhelpers
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/crowdsecurity/go-cs-lib/downloader"
+
+	"github.com/crowdsecurity/crowdsec/pkg/csconfig"
+	"github.com/crowdsecurity/crowdsec/pkg/metrics"
+)
+
+// the types of indicators
+const (
+	TIAddress = "ip"
+	TIRange   = "range"
+	TIDomain  = "domain"
+	TIHash    = "hash"
+)
+
+var (
+	threatIntelMu      sync.RWMutex
+	threatIntelFeeds   = map[string]*threatIntelFeed{}
+	threatIntelWatcher *fileWatcher
+	// stops the download of the feeds with an url
+	threatIntelCancel context.CancelFunc
+)
+
+// TIMatchResult is returned by TIMatch for a value that is in a feed.
+type TIMatchResult struct {
+	Feed       string
+	Type       string
+	Indicator  string
+	Tags       []string
+	Confidence int
+}
+
+// tiIndicator is an entry of a feed, the same indicator can appear several
+// times: the tags are merged and the highest confidence is kept.
+type tiIndicator struct {
+	typ        string
+	value      string
+	tags       []string
+	confidence int
+}
+
+func (i *tiIndicator) merge(tags []string, confidence int) {
+	for _, tag := range tags {
+		if !slices.Contains(i.tags, tag) {
+			i.tags = append(i.tags, tag)
+		}
+	}
+
+	i.confidence = max(i.confidence, confidence)
+}
+
+// tiIndex is the content of a feed, by type of indicator.
+type tiIndex struct {
+	prefixes map[netip.Prefix]*tiIndicator
+	// the lengths of the prefixes, longest first, for the most specific match
+	bits    []int
+	domains map[string]*tiIndicator
+	hashes  map[string]*tiIndicator
+}
+
+func newTIIndex() *tiIndex {
+	return &tiIndex{
+		prefixes: make(map[netip.Prefix]*tiIndicator),
+		domains:  make(map[string]*tiIndicator),
+		hashes:   make(map[string]*tiIndicator),
+	}
+}
+
+var hashRegexp = regexp.MustCompile(`^(?:[[:xdigit:]]{32}|[[:xdigit:]]{40}|[[:xdigit:]]{64}|[[:xdigit:]]{128})$`)
+
+// parseIndicator detects the type of an indicator and returns it in its canonical form.
+func parseIndicator(value string) (string, string, netip.Prefix, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", "", netip.Prefix{}, false
+	}
+
+	if addr, err := netip.ParseAddr(value); err == nil {
+		addr = addr.Unmap()
+		return TIAddress, addr.String(), netip.PrefixFrom(addr, addr.BitLen()), true
+	}
+
+	if p, err := netip.ParsePrefix(value); err == nil {
+		if p.Addr().Is4In6() && p.Bits() >= 96 {
+			p = netip.PrefixFrom(p.Addr().Unmap(), p.Bits()-96)
+		}
+
+		p = p.Masked()
+
+		return TIRange, p.String(), p, true
+	}
+
+	if hashRegexp.MatchString(value) {
+		return TIHash, strings.ToLower(value), netip.Prefix{}, true
+	}
+
+	domain := normalizeHost(value)
+	if domain == "" || strings.ContainsAny(domain, " /:@") {
+		return "", "", netip.Prefix{}, false
+	}
+
+	return TIDomain, domain, netip.Prefix{}, true
+}
+
+func (idx *tiIndex) add(value string, tags []string, confidence int) bool {
+	typ, canonical, prefix, ok := parseIndicator(value)
+	if !ok {
+		return false
+	}
+
+	var entries map[string]*tiIndicator
+
+	switch typ {
+	case TIAddress, TIRange:
+		if ind, ok := idx.prefixes[prefix]; ok {
+			ind.merge(tags, confidence)
+			return true
+		}
+
+		idx.prefixes[prefix] = &tiIndicator{typ: typ, value: canonical, tags: slices.Clone(tags), confidence: confidence}
+
+		if !slices.Contains(idx.bits, prefix.Bits()) {
+			idx.bits = append(idx.bits, prefix.Bits())
+			slices.Sort(idx.bits)
+			slices.Reverse(idx.bits)
+		}
+
+		return true
+	case TIDomain:
+		entries = idx.domains
+	default:
+		entries = idx.hashes
+	}
+
+	if ind, ok := entries[canonical]; ok {
+		ind.merge(tags, confidence)
+		return true
+	}
+
+	entries[canonical] = &tiIndicator{typ: typ, value: canonical, tags: slices.Clone(tags), confidence: confidence}
+
+	return true
+}
+
+// lookup returns the most specific indicator for an IP address, a domain (or
+// one of its parents) or a hash.
+func (idx *tiIndex) lookup(value string) *tiIndicator {
+	value = strings.TrimSpace(value)
+
+	if addr, err := netip.ParseAddr(value); err == nil {
+		addr = addr.Unmap()
+
+		for _, bits := range idx.bits {
+			if bits > addr.BitLen() {
+				continue
+			}
+
+			prefix, err := addr.Prefix(bits)
+			if err != nil {
+				continue
+			}
+
+			if ind, ok := idx.prefixes[prefix]; ok {
+				return ind
+			}
+		}
+
+		return nil
+	}
+
+	if hashRegexp.MatchString(value) {
+		return idx.hashes[strings.ToLower(value)]
+	}
+
+	domain := normalizeHost(value)
+
+	for domain != "" {
+		if ind, ok := idx.domains[domain]; ok {
+			return ind
+		}
+
+		_, parent, found := strings.Cut(domain, ".")
+		if !found {
+			break
+		}
+
+		domain = parent
+	}
+
+	return nil
+}
+
+func (idx *tiIndex) count() map[string]int {
+	counts := map[string]int{TIAddress: 0, TIRange: 0, TIDomain: len(idx.domains), TIHash: len(idx.hashes)}
+
+	for _, ind := range idx.prefixes {
+		counts[ind.typ]++
+	}
+
+	return counts
+}
+
+// threatIntelFeed is a feed declared in the configuration. The index is
+// swapped when the file changes, so the lookups never see a partial feed.
+type threatIntelFeed struct {
+	cfg   csconfig.ThreatIntelFeedCfg
+	index atomic.Pointer[tiIndex]
+}
+
+func (f *threatIntelFeed) load() error {
+	fd, err := os.Open(f.cfg.Path)
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+
+	idx := newTIIndex()
+
+	switch f.cfg.Format {
+	case csconfig.ThreatIntelPlain:
+		err = readPlainFeed(fd, idx, f.cfg.Tags, f.cfg.Confidence)
+	case csconfig.ThreatIntelCSV:
+		err = readCSVFeed(fd, idx, f.cfg.Key, f.cfg.Tags, f.cfg.Confidence)
+	case csconfig.ThreatIntelSTIX:
+		err = readSTIXFeed(fd, idx, f.cfg.Tags, f.cfg.Confidence, time.Now())
+	default:
+		err = fmt.Errorf("unknown format %q", f.cfg.Format)
+	}
+
+	if err != nil {
+		return fmt.Errorf("%s: %w", f.cfg.Path, err)
+	}
+
+	for typ, n := range idx.count() {
+		metrics.ThreatIntelIndicators.WithLabelValues(f.cfg.Name, typ).Set(float64(n))
+	}
+
+	f.index.Store(idx)
+
+	return nil
+}
+
+// readPlainFeed reads one indicator per line, with # comments.
+func readPlainFeed(r io.Reader, idx *tiIndex, tags []string, confidence int) error {
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+
+	for scanner.Scan() {
+		lineNum++
+
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		if !idx.add(line, tags, confidence) {
+			log.Debugf("threat intel: line %d: not an indicator: %q", lineNum, line)
+		}
+	}
+
+	return scanner.Err()
+}
+
+// readCSVFeed reads a CSV file with a header. The indicator is in the key
+// column, with optional "tags" (separated by ;) and "confidence" columns.
+func readCSVFeed(r io.Reader, idx *tiIndex, key string, tags []string, confidence int) error {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, column := range header {
+		columns[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+
+	keyIdx, ok := columns[strings.ToLower(key)]
+	if !ok {
+		return fmt.Errorf("no %q column", key)
+	}
+
+	column := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+
+		return strings.TrimSpace(record[i])
+	}
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if keyIdx >= len(record) {
+			continue
+		}
+
+		rowTags := tags
+
+		if v := column(record, "tags"); v != "" {
+			rowTags = slices.Clone(tags)
+
+			for tag := range strings.SplitSeq(v, ";") {
+				if tag = strings.TrimSpace(tag); tag != "" {
+					rowTags = append(rowTags, tag)
+				}
+			}
+		}
+
+		rowConfidence := confidence
+
+		if v := column(record, "confidence"); v != "" {
+			c, err := strconv.Atoi(v)
+			if err != nil {
+				line, _ := reader.FieldPos(keyIdx)
+				return fmt.Errorf("line %d: invalid confidence %q", line, v)
+			}
+
+			rowConfidence = c
+		}
+
+		if !idx.add(record[keyIdx], rowTags, rowConfidence) {
+			line, _ := reader.FieldPos(keyIdx)
+			log.Debugf("threat intel: line %d: not an indicator: %q", line, record[keyIdx])
+		}
+	}
+}
+
+type stixObject struct {
+	Type        string     `json:"type"`
+	Pattern     string     `json:"pattern"`
+	PatternType string     `json:"pattern_type"`
+	Labels      []string   `json:"labels"`
+	Types       []string   `json:"indicator_types"`
+	Confidence  *int       `json:"confidence"`
+	Revoked     bool       `json:"revoked"`
+	ValidUntil  *time.Time `json:"valid_until"`
+}
+
+// the comparisons of a STIX pattern with the indicators we can match
+var stixComparison = regexp.MustCompile(`(ipv4-addr|ipv6-addr|domain-name):value\s*=\s*'((?:[^'\\]|\\.)*)'|file:hashes\.(?:'[^']*'|[A-Za-z0-9-]+)\s*=\s*'([[:xdigit:]]+)'`)
+
+// readSTIXFeed reads the indicators of a STIX 2.1 bundle. Each comparison of
+// an address, domain or hash in a pattern is an indicator: the patterns that
+// need several observations ("AND", "FOLLOWEDBY") match more than they should.
+// The revoked and expired indicators are skipped.
+func readSTIXFeed(r io.Reader, idx *tiIndex, tags []string, confidence int, now time.Time) error {
+	var bundle struct {
+		Type    string       `json:"type"`
+		Objects []stixObject `json:"objects"`
+	}
+
+	if err := json.NewDecoder(r).Decode(&bundle); err != nil {
+		return err
+	}
+
+	if bundle.Type != "bundle" {
+		return fmt.Errorf("not a STIX bundle (type %q)", bundle.Type)
+	}
+
+	for _, obj := range bundle.Objects {
+		if obj.Type != "indicator" || obj.Revoked || (obj.PatternType != "" && obj.PatternType != "stix") {
+			continue
+		}
+
+		if obj.ValidUntil != nil && !now.Before(*obj.ValidUntil) {
+			continue
+		}
+
+		objTags := slices.Concat(tags, obj.Labels, obj.Types)
+
+		objConfidence := confidence
+		if obj.Confidence != nil {
+			objConfidence = *obj.Confidence
+		}
+
+		for _, m := range stixComparison.FindAllStringSubmatch(obj.Pattern, -1) {
+			value := m[2]
+			if m[3] != "" {
+				value = m[3]
+			}
+
+			value = strings.ReplaceAll(strings.ReplaceAll(value, `\'`, `'`), `\\`, `\`)
+
+			if !idx.add(value, objTags, objConfidence) {
+				log.Debugf("threat intel: not an indicator: %q in %q", value, obj.Pattern)
+			}
+		}
+	}
+
+	return nil
+}
+
+// download fetches the feed if it has changed, with the ETag of the previous download.
+func (f *threatIntelFeed) download(ctx context.Context) (bool, error) {
+	return downloader.
+		New().
+		WithHTTPClient(dataFileHTTPClient).
+		ToFile(f.cfg.Path).
+		WithETagFile(f.cfg.Path+".etag").
+		WithLogger(log.WithField("url", f.cfg.URL)).
+		Download(ctx, f.cfg.URL)
+}
+
+// update downloads the feed, and loads it if there's a new version.
+func (f *threatIntelFeed) update(ctx context.Context) {
+	downloaded, err := f.download(ctx)
+	if err != nil {
+		log.Warningf("threat intel %s: refreshing from %s: %s", f.cfg.Name, f.cfg.URL, err)
+		metrics.DataFileReloadFailures.WithLabelValues(f.cfg.Name, "download").Inc()
+
+		return
+	}
+
+	if !downloaded {
+		return
+	}
+
+	log.Infof("threat intel %s: downloaded a new version", f.cfg.Name)
+
+	threatIntelMu.RLock()
+	watcher := threatIntelWatcher
+	threatIntelMu.RUnlock()
+
+	// the watcher would see the change too, this way it's loaded once
+	if watcher != nil {
+		watcher.trigger(f.cfg.Path)
+		return
+	}
+
+	runReload(f.cfg.Name, f.cfg.Path, f.load)
+}
+
+// refresh downloads the feed now, then at each interval.
+func (f *threatIntelFeed) refresh(ctx context.Context) {
+	f.update(ctx)
+
+	ticker := time.NewTicker(f.cfg.RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			f.update(ctx)
+		}
+	}
+}
+
+// ThreatIntelInit loads the configured feeds, reloads them when their files
+// change and downloads the ones with an url. It replaces the feeds of a
+// previous call. The feeds with an url are loaded from their last download,
+// and downloaded in the background: they are empty until the first download
+// completes.
+func ThreatIntelInit(ctx context.Context, cfgs []*csconfig.ThreatIntelFeedCfg) error {
+	ThreatIntelClose()
+
+	feeds := make(map[string]*threatIntelFeed, len(cfgs))
+
+	for _, cfg := range cfgs {
+		f := &threatIntelFeed{cfg: *cfg}
+
+		err := f.load()
+
+		switch {
+		case err == nil:
+			log.Infof("loaded threat intel feed %s (%s) from %s", cfg.Name, cfg.Format, cfg.Path)
+		case cfg.URL != "" && errors.Is(err, os.ErrNotExist):
+			log.Infof("threat intel feed %s will be loaded once downloaded from %s", cfg.Name, cfg.URL)
+			f.index.Store(newTIIndex())
+		default:
+			return fmt.Errorf("threat intel %s: %w", cfg.Name, err)
+		}
+
+		feeds[cfg.Name] = f
+	}
+
+	threatIntelMu.Lock()
+	defer threatIntelMu.Unlock()
+
+	threatIntelFeeds = feeds
+
+	if len(feeds) == 0 {
+		return nil
+	}
+
+	threatIntelWatcher = newFileWatcher(defaultPollInterval)
+
+	refreshCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	threatIntelCancel = cancel
+
+	for _, f := range feeds {
+		metrics.DataFileLastReload.WithLabelValues(f.cfg.Name).SetToCurrentTime()
+		threatIntelWatcher.add(f.cfg.Name, f.cfg.Path, f.load)
+
+		if f.cfg.URL != "" {
+			go f.refresh(refreshCtx)
+		}
+	}
+
+	return nil
+}
+
+// ThreatIntelClose stops watching and downloading the feeds, and removes them.
+func ThreatIntelClose() {
+	threatIntelMu.Lock()
+	defer threatIntelMu.Unlock()
+
+	if threatIntelCancel != nil {
+		threatIntelCancel()
+		threatIntelCancel = nil
+	}
+
+	if threatIntelWatcher != nil {
+		threatIntelWatcher.close()
+		threatIntelWatcher = nil
+	}
+
+	threatIntelFeeds = map[string]*threatIntelFeed{}
+}
+
+func getThreatIntelFeeds(name string) []*threatIntelFeed {
+	threatIntelMu.RLock()
+	defer threatIntelMu.RUnlock()
+
+	if name != "" {
+		if f, ok := threatIntelFeeds[name]; ok {
+			return []*threatIntelFeed{f}
+		}
+
+		return nil
+	}
+
+	feeds := make([]*threatIntelFeed, 0, len(threatIntelFeeds))
+	for _, f := range threatIntelFeeds {
+		feeds = append(feeds, f)
+	}
+
+	// the same order for every call
+	slices.SortFunc(feeds, func(a, b *threatIntelFeed) int {
+		return strings.Compare(a.cfg.Name, b.cfg.Name)
+	})
+
+	return feeds
+}
+
+// func TIMatch(value string, feed string) *TIMatchResult {
+func TIMatch(params ...any) (any, error) {
+	value := params[0].(string)
+	name := params[1].(string)
+
+	feeds := getThreatIntelFeeds(name)
+	if len(feeds) == 0 && name != "" {
+		log.Errorf("TIMatch: unknown threat intel feed %q", name)
+		return (*TIMatchResult)(nil), nil
+	}
+
+	// with all the feeds, the one with the highest confidence
+	var ret *TIMatchResult
+
+	for _, f := range feeds {
+		ind := f.index.Load().lookup(value)
+		if ind == nil {
+			continue
+		}
+
+		if ret != nil && ret.Confidence >= ind.confidence {
+			continue
+		}
+
+		ret = &TIMatchResult{
+			Feed:       f.cfg.Name,
+			Type:       ind.typ,
+			Indicator:  ind.value,
+			Tags:       append([]string{}, ind.tags...),
+			Confidence: ind.confidence,
+		}
+	}
+
+	if ret != nil {
+		metrics.ThreatIntelMatches.WithLabelValues(ret.Feed).Inc()
+	}
+
+	return ret, nil
+}
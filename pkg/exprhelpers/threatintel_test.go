@@ -0,0 +1,194 @@
+package exprhelpers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/expr-lang/expr"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/crowdsecurity/go-cs-lib/cstest"
+
+	"github.com/crowdsecurity/crowdsec/pkg/csconfig"
+	"github.com/crowdsecurity/crowdsec/pkg/metrics"
+)
+
+func tiMatch(t *testing.T, value string, feed string) *TIMatchResult {
+	t.Helper()
+
+	ret, err := TIMatch(value, feed)
+	require.NoError(t, err)
+
+	return ret.(*TIMatchResult)
+}
+
+func TestThreatIntelFeeds(t *testing.T) {
+	err := ThreatIntelInit(t.Context(), []*csconfig.ThreatIntelFeedCfg{
+		{Name: "honeypot", Format: csconfig.ThreatIntelPlain, Path: "testdata/test_ti_plain.txt", Tags: []string{"honeypot"}, Confidence: 50},
+		{Name: "partners", Format: csconfig.ThreatIntelCSV, Path: "testdata/test_ti_feed.csv", Key: "indicator", Confidence: 30},
+		{Name: "misp", Format: csconfig.ThreatIntelSTIX, Path: "testdata/test_ti_bundle.json"},
+	})
+	require.NoError(t, err)
+	t.Cleanup(ThreatIntelClose)
+
+	tests := []struct {
+		name     string
+		feed     string
+		value    string
+		expected *TIMatchResult
+	}{
+		{"address", "honeypot", "192.0.2.1", &TIMatchResult{Feed: "honeypot", Type: TIAddress, Indicator: "192.0.2.1", Tags: []string{"honeypot"}, Confidence: 50}},
+		{"range", "honeypot", "198.51.100.42", &TIMatchResult{Feed: "honeypot", Type: TIRange, Indicator: "198.51.100.0/24", Tags: []string{"honeypot"}, Confidence: 50}},
+		{"mapped address", "honeypot", "::ffff:198.51.100.42", &TIMatchResult{Feed: "honeypot", Type: TIRange, Indicator: "198.51.100.0/24", Tags: []string{"honeypot"}, Confidence: 50}},
+		{"ipv6 range", "honeypot", "2001:db8:bad::1", &TIMatchResult{Feed: "honeypot", Type: TIRange, Indicator: "2001:db8:bad::/48", Tags: []string{"honeypot"}, Confidence: 50}},
+		{"domain", "honeypot", "Evil.Example.com", &TIMatchResult{Feed: "honeypot", Type: TIDomain, Indicator: "evil.example.com", Tags: []string{"honeypot"}, Confidence: 50}},
+		{"subdomain", "honeypot", "cdn.evil.example.com.", &TIMatchResult{Feed: "honeypot", Type: TIDomain, Indicator: "evil.example.com", Tags: []string{"honeypot"}, Confidence: 50}},
+		{"parent domain", "honeypot", "example.com", nil},
+		{"hash", "honeypot", "44D88612FEA8A8F36DE82E1278ABB02F", &TIMatchResult{Feed: "honeypot", Type: TIHash, Indicator: "44d88612fea8a8f36de82e1278abb02f", Tags: []string{"honeypot"}, Confidence: 50}},
+		{"not found", "honeypot", "192.0.2.2", nil},
+		{"csv duplicates are merged", "partners", "203.0.113.7", &TIMatchResult{Feed: "partners", Type: TIAddress, Indicator: "203.0.113.7", Tags: []string{"botnet", "c2", "scanner"}, Confidence: 90}},
+		{"csv domain", "partners", "phish.example.net", &TIMatchResult{Feed: "partners", Type: TIDomain, Indicator: "phish.example.net", Tags: []string{"phishing"}, Confidence: 60}},
+		{"stix address", "misp", "192.0.2.99", &TIMatchResult{Feed: "misp", Type: TIAddress, Indicator: "192.0.2.99", Tags: []string{"misp:apt", "malicious-activity"}, Confidence: 85}},
+		{"stix or", "misp", "192.0.2.200", &TIMatchResult{Feed: "misp", Type: TIRange, Indicator: "192.0.2.128/25", Tags: []string{"misp:apt", "malicious-activity"}, Confidence: 85}},
+		{"stix domain", "misp", "c2.example.org", &TIMatchResult{Feed: "misp", Type: TIDomain, Indicator: "c2.example.org", Tags: []string{}, Confidence: 0}},
+		{"stix hash", "misp", "aec070645fe53ee3b3763059376134f058cc337247c978add178b6ccdfb0019f", &TIMatchResult{Feed: "misp", Type: TIHash, Indicator: "aec070645fe53ee3b3763059376134f058cc337247c978add178b6ccdfb0019f", Tags: []string{}, Confidence: 0}},
+		{"stix revoked", "misp", "192.0.2.50", nil},
+		{"stix expired", "misp", "192.0.2.51", nil},
+		{"not a stix pattern", "misp", "192.0.2.52", nil},
+		{"all feeds, highest confidence", "", "192.0.2.1", &TIMatchResult{Feed: "honeypot", Type: TIAddress, Indicator: "192.0.2.1", Tags: []string{"honeypot"}, Confidence: 50}},
+		{"all feeds, not found", "", "192.0.2.3", nil},
+		{"unknown feed", "nope", "192.0.2.1", nil},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tiMatch(t, tc.value, tc.feed))
+		})
+	}
+
+	assert.InDelta(t, 1.0, testutil.ToFloat64(metrics.ThreatIntelIndicators.WithLabelValues("honeypot", TIAddress)), 0)
+	assert.InDelta(t, 2.0, testutil.ToFloat64(metrics.ThreatIntelIndicators.WithLabelValues("honeypot", TIRange)), 0)
+	assert.InDelta(t, 1.0, testutil.ToFloat64(metrics.ThreatIntelIndicators.WithLabelValues("misp", TIHash)), 0)
+}
+
+func TestThreatIntelInitError(t *testing.T) {
+	err := ThreatIntelInit(t.Context(), []*csconfig.ThreatIntelFeedCfg{
+		{Name: "misp", Format: csconfig.ThreatIntelSTIX, Path: "testdata/test_ti_feed.csv"},
+	})
+	cstest.RequireErrorContains(t, err, "threat intel misp: testdata/test_ti_feed.csv")
+
+	err = ThreatIntelInit(t.Context(), []*csconfig.ThreatIntelFeedCfg{
+		{Name: "partners", Format: csconfig.ThreatIntelCSV, Path: "testdata/test_ti_feed.csv", Key: "ip"},
+	})
+	cstest.RequireErrorContains(t, err, `no "ip" column`)
+}
+
+func TestTIMatchExpr(t *testing.T) {
+	require.NoError(t, Init(nil))
+
+	err := ThreatIntelInit(t.Context(), []*csconfig.ThreatIntelFeedCfg{
+		{Name: "partners", Format: csconfig.ThreatIntelCSV, Path: "testdata/test_ti_feed.csv", Key: "indicator"},
+	})
+	require.NoError(t, err)
+	t.Cleanup(ThreatIntelClose)
+
+	env := map[string]any{"ip": ""}
+
+	program, err := expr.Compile(`TIMatch(ip, "partners") != nil && "c2" in TIMatch(ip, "partners").Tags && TIMatch(ip, "partners").Confidence > 80`, GetExprOptions(env)...)
+	require.NoError(t, err)
+
+	for ip, expected := range map[string]bool{
+		"203.0.113.7": true,
+		"203.0.113.8": false,
+	} {
+		env["ip"] = ip
+
+		out, err := expr.Run(program, env)
+		require.NoError(t, err)
+		assert.Equal(t, expected, out, ip)
+	}
+}
+
+func TestThreatIntelRefresh(t *testing.T) {
+	setupDataFileTest(t)
+
+	content := atomic.Value{}
+	content.Store("192.0.2.1\n")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(content.Load().(string)))
+	}))
+	t.Cleanup(srv.Close)
+
+	path := filepath.Join(t.TempDir(), "feed.txt")
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	err := ThreatIntelInit(ctx, []*csconfig.ThreatIntelFeedCfg{
+		{Name: "remote", Format: csconfig.ThreatIntelPlain, Path: path, URL: srv.URL, RefreshInterval: 20 * time.Millisecond},
+	})
+	require.NoError(t, err)
+	t.Cleanup(ThreatIntelClose)
+
+	// empty until downloaded in the background
+	require.Eventually(t, func() bool {
+		return tiMatch(t, "192.0.2.1", "remote") != nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.FileExists(t, path)
+	assert.Nil(t, tiMatch(t, "192.0.2.2", "remote"))
+
+	content.Store("192.0.2.2\n")
+
+	require.Eventually(t, func() bool {
+		return tiMatch(t, "192.0.2.2", "remote") != nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	assert.Nil(t, tiMatch(t, "192.0.2.1", "remote"))
+
+	// the content survives a failed download
+	srv.Close()
+
+	require.NoError(t, os.WriteFile(path, []byte("not an indicator\n192.0.2.3\n"), 0o644))
+
+	require.Eventually(t, func() bool {
+		return tiMatch(t, "192.0.2.3", "remote") != nil
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestThreatIntelUnreachable(t *testing.T) {
+	setupDataFileTest(t)
+
+	// accepts the connection, never answers
+	srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	t.Cleanup(srv.Close)
+
+	path := filepath.Join(t.TempDir(), "feed.txt")
+	require.NoError(t, os.WriteFile(path, []byte("192.0.2.1\n"), 0o644))
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	start := time.Now()
+
+	err := ThreatIntelInit(ctx, []*csconfig.ThreatIntelFeedCfg{
+		{Name: "remote", Format: csconfig.ThreatIntelPlain, Path: path, URL: srv.URL, RefreshInterval: time.Hour},
+	})
+	require.NoError(t, err)
+	t.Cleanup(ThreatIntelClose)
+
+	// the previous download is used while the feed is unreachable
+	assert.Less(t, time.Since(start), time.Second)
+	assert.NotNil(t, tiMatch(t, "192.0.2.1", "remote"))
+}
@@ -14,6 +14,7 @@ var (
 	Re2DisableGrokSupport  = &Feature{Name: "re2_disable_grok_support", Description: "Disable RE2 support for GROK patterns (linux only)"}
 	Re2RegexpInfileSupport = &Feature{Name: "re2_regexp_in_file_support", Description: "Enable RE2 support for RegexpInFile expr helper"}
 	PProfBlockProfile      = &Feature{Name: "pprof_block_profile", Description: "Enable pprof block/mutex profiling. Do not use unless instructed by CrowdSec support"}
+	DisableGrokPrefilter   = &Feature{Name: "disable_grok_prefilter", Description: "Always run GROK patterns, even when a literal they require is missing from the line"}
 )
 
 //revive:disable:if-return
@@ -38,6 +39,10 @@ func RegisterAllFeatures() error {
 		return err
 	}
 
+	if err := Crowdsec.RegisterFeature(DisableGrokPrefilter); err != nil {
+		return err
+	}
+
 	if runtime.GOOS == "linux" {
 		// This cannot actually fail in a release, so the state will always be set
 		if err := Crowdsec.RegisterFeature(Re2DisableGrokSupport); err != nil {
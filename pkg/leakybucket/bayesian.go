@@ -46,6 +46,8 @@ func (p *BayesianProcessor) OnBucketInit(f *BucketFactory) error {
 		if err != nil {
 			return err
 		}
+
+		exprhelpers.Label(prog, f.Spec.Name, "bayesian_conditions")
 		bayesianEventArray[index] = &BayesianEvent{
 			rawCondition:             bcond,
 			conditionalFilterRuntime: prog,
@@ -87,7 +87,7 @@ func (p *CancelProcessor) OnBucketInit(f *BucketFactory) error {
 		f.logger.Errorf("reset_filter compile error : %s", err)
 		return err
 	}
-	p.CancelOnFilter = compiledExpr.CancelOnFilter
+	p.CancelOnFilter = exprhelpers.Label(compiledExpr.CancelOnFilter, f.Spec.Name, "cancel_on")
 	if f.Spec.Debug {
 		p.Debug = true
 	}
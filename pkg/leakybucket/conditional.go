@@ -37,7 +37,7 @@ func (p *ConditionalProcessor) OnBucketInit(f *BucketFactory) error {
 			return fmt.Errorf("conditional compile error : %w", err)
 		}
 
-		p.ConditionalFilterRuntime = compiledExpr
+		p.ConditionalFilterRuntime = exprhelpers.Label(compiledExpr, f.Spec.Name, "condition")
 		conditionalExprCacheLock.Lock()
 		conditionalExprCache[f.Spec.ConditionalOverflow] = compiledExpr
 		conditionalExprCacheLock.Unlock()
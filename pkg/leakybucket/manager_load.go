@@ -94,7 +94,13 @@ func (f *BucketFactory) Validate() error {
 		return fmt.Errorf("%s bucket: %w", f.Spec.Type, err)
 	}
 
-	return f.Spec.ScopeType.CompileFilter()
+	if err := f.Spec.ScopeType.CompileFilter(); err != nil {
+		return err
+	}
+
+	exprhelpers.Label(f.Spec.ScopeType.RunTimeFilter, f.Spec.Name, "scope.filter")
+
+	return nil
 }
 
 type SimulationChecker interface {
@@ -251,14 +257,14 @@ func (f *BucketFactory) compileExpr() error {
 	if err != nil {
 		return fmt.Errorf("invalid filter '%s' in %s: %w", f.Spec.Filter, f.Filename, err)
 	}
-	f.RunTimeFilter = runtimeFilter
+	f.RunTimeFilter = exprhelpers.Label(runtimeFilter, f.Spec.Name, "filter")
 
 	if f.Spec.GroupBy != "" {
 		runtimeGroupBy, err := compile(f.Spec.GroupBy, nil)
 		if err != nil {
 			return fmt.Errorf("invalid groupby '%s' in %s: %w", f.Spec.GroupBy, f.Filename, err)
 		}
-		f.RunTimeGroupBy = runtimeGroupBy
+		f.RunTimeGroupBy = exprhelpers.Label(runtimeGroupBy, f.Spec.Name, "groupby")
 	}
 
 	return nil
@@ -339,7 +345,7 @@ func (f *BucketFactory) initDataFiles() {
 			continue
 		}
 
-		if err := exprhelpers.FileInit(f.DataDir, data.DestPath, data.Type); err != nil {
+		if err := exprhelpers.DataProviderInit(f.DataDir, data); err != nil {
 			f.logger.Errorf("unable to init data for file '%s': %s", data.DestPath, err)
 		}
 
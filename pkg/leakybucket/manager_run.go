@@ -210,7 +210,27 @@ func PourItemToHolders(
 	buckets *BucketStore,
 	collector *PourCollector,
 ) (bool, error) {
-	var ok, condition, poured bool
+	poured, err := PourItemToHoldersTraced(ctx, parsed, holders, buckets, collector)
+	if err != nil {
+		return false, err
+	}
+
+	return len(poured) > 0, nil
+}
+
+// PourItemToHoldersTraced is PourItemToHolders, and returns the names of the
+// scenarios the event was poured into.
+func PourItemToHoldersTraced(
+	ctx context.Context,
+	parsed pipeline.Event,
+	holders []BucketFactory,
+	buckets *BucketStore,
+	collector *PourCollector,
+) ([]string, error) {
+	var (
+		ok, condition bool
+		poured        []string
+	)
 
 	if collector != nil {
 		evt := deepcopy.Copy(parsed).(pipeline.Event)
@@ -228,7 +248,7 @@ func PourItemToHolders(
 				holders[idx].Spec.Debug)
 			if err != nil {
 				holders[idx].logger.Errorf("failed parsing : %v", err)
-				return false, fmt.Errorf("leaky failed : %s", err)
+				return nil, fmt.Errorf("leaky failed : %s", err)
 			}
 			// we assume we a bool should add type check here
 			if condition, ok = output.(bool); !ok {
@@ -247,12 +267,12 @@ func PourItemToHolders(
 			tmpGroupBy, err := exprhelpers.Run(holders[idx].RunTimeGroupBy, map[string]any{"evt": &parsed}, holders[idx].logger, holders[idx].Spec.Debug)
 			if err != nil {
 				holders[idx].logger.Errorf("failed groupby : %v", err)
-				return false, errors.New("leaky failed :/")
+				return nil, errors.New("leaky failed :/")
 			}
 
 			if groupby, ok = tmpGroupBy.(string); !ok {
 				holders[idx].logger.Fatalf("failed groupby type : %v", err)
-				return false, errors.New("groupby wrong type")
+				return nil, errors.New("groupby wrong type")
 			}
 		}
 		buckey := holders[idx].BucketKey(groupby)
@@ -260,7 +280,7 @@ func PourItemToHolders(
 		// we need to either find the existing bucket, or create a new one (if it's the first event to hit it for this partition key)
 		bucket, err := LoadOrStoreBucketFromHolder(ctx, buckey, buckets, &holders[idx], parsed.ExpectMode)
 		if err != nil {
-			return false, fmt.Errorf("failed to load or store bucket: %w", err)
+			return nil, fmt.Errorf("failed to load or store bucket: %w", err)
 		}
 		// finally, pour the even into the bucket
 
@@ -284,9 +304,9 @@ func PourItemToHolders(
 		}
 
 		if err != nil {
-			return false, fmt.Errorf("failed to pour bucket: %w", err)
+			return nil, fmt.Errorf("failed to pour bucket: %w", err)
 		}
-		poured = true
+		poured = append(poured, holders[idx].Spec.Name)
 	}
 	return poured, nil
 }
@@ -27,6 +27,8 @@ func NewOverflowProcessor(f *BucketFactory) (*OverflowProcessor, error) {
 		f.logger.Errorf("Unable to compile filter : %v", err)
 		return nil, fmt.Errorf("unable to compile filter : %v", err)
 	}
+
+	exprhelpers.Label(u.FilterRuntime, f.Spec.Name, "overflow_filter")
 	return &u, nil
 }
 
@@ -3,9 +3,9 @@ package leakybucket
 import (
 	"sync"
 
-	"github.com/expr-lang/expr"
 	"github.com/expr-lang/expr/vm"
 
+	"github.com/crowdsecurity/crowdsec/pkg/exprhelpers"
 	"github.com/crowdsecurity/crowdsec/pkg/pipeline"
 )
 
@@ -73,13 +73,14 @@ func (p *UniqProcessor) OnBucketInit(f *BucketFactory) error {
 		uniqExprCache[f.Spec.Distinct] = *compiledExpr
 		uniqExprCacheLock.Unlock()
 	}
+	exprhelpers.Label(p.DistinctCompiled, f.Spec.Name, "distinct")
 	p.KeyCache = make(map[string]bool)
 	return nil
 }
 
 // getElement computes a string from an event and a filter
 func getElement(msg pipeline.Event, cFilter *vm.Program) (string, error) {
-	el, err := expr.Run(cFilter, map[string]any{"evt": &msg})
+	el, err := exprhelpers.Run(cFilter, map[string]any{"evt": &msg}, nil, false)
 	if err != nil {
 		return "", err
 	}
@@ -1,7 +1,31 @@
 package metrics
 
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
 var AcquisitionMetricsNames = []string{}
 
 func RegisterAcquisitionMetric(metricName string) {
 	AcquisitionMetricsNames = append(AcquisitionMetricsNames, metricName)
 }
+
+const AcquisitionThrottledLinesMetricName = "cs_acquisition_throttled_lines_total"
+
+var AcquisitionThrottledLines = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: AcquisitionThrottledLinesMetricName,
+		Help: "Total lines discarded because the datasource exceeded its rate limit.",
+	},
+	[]string{"source", "datasource_type"},
+)
+
+const AcquisitionDroppedLinesMetricName = "cs_acquisition_dropped_lines_total"
+
+var AcquisitionDroppedLines = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: AcquisitionDroppedLinesMetricName,
+		Help: "Total lines discarded or sampled out because the pipeline was full.",
+	},
+	[]string{"source", "datasource_type"},
+)
@@ -0,0 +1,21 @@
+//go:build !no_datasource_cri
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const CRIDataSourceLinesReadMetricName = "cs_crisource_hits_total"
+
+var CRIDataSourceLinesRead = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: CRIDataSourceLinesReadMetricName,
+		Help: "Total lines that were read.",
+	},
+	[]string{"source", "datasource_type", "acquis_type"})
+
+//nolint:gochecknoinits
+func init() {
+	RegisterAcquisitionMetric(CRIDataSourceLinesReadMetricName)
+}
@@ -0,0 +1,21 @@
+//go:build !no_datasource_elasticsearch
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const ElasticsearchDataSourceLinesReadMetricName = "cs_elasticsearchsource_hits_total"
+
+var ElasticsearchDataSourceLinesRead = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: ElasticsearchDataSourceLinesReadMetricName,
+		Help: "Total lines that were read.",
+	},
+	[]string{"source", "datasource_type", "acquis_type"})
+
+//nolint:gochecknoinits
+func init() {
+	RegisterAcquisitionMetric(ElasticsearchDataSourceLinesReadMetricName)
+}
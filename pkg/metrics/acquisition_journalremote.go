@@ -0,0 +1,21 @@
+//go:build !no_datasource_journalremote
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const JournalRemoteDataSourceLinesReadMetricName = "cs_journalremotesource_hits_total"
+
+var JournalRemoteDataSourceLinesRead = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: JournalRemoteDataSourceLinesReadMetricName,
+		Help: "Total lines that were read.",
+	},
+	[]string{"source", "datasource_type", "acquis_type"})
+
+//nolint:gochecknoinits
+func init() {
+	RegisterAcquisitionMetric(JournalRemoteDataSourceLinesReadMetricName)
+}
@@ -0,0 +1,23 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+const DataFileLastReloadMetricName = "cs_datafile_last_reload_timestamp_seconds"
+
+var DataFileLastReload = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: DataFileLastReloadMetricName,
+		Help: "Time of the last successful load of a data file.",
+	},
+	[]string{"name"},
+)
+
+const DataFileReloadFailuresMetricName = "cs_datafile_reload_failures_total"
+
+var DataFileReloadFailures = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: DataFileReloadFailuresMetricName,
+		Help: "Total failed reloads or refreshes of a data file.",
+	},
+	[]string{"name", "reason"},
+)
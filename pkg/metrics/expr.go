@@ -11,3 +11,24 @@ var RegexpCacheMetrics = prometheus.NewGaugeVec(
 	},
 	[]string{"name"},
 )
+
+const ExprDurationMetricName = "cs_expr_duration_seconds"
+
+var ExprDuration = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    ExprDurationMetricName,
+		Help:    "Time spent evaluating the expressions, by item and field.",
+		Buckets: prometheus.ExponentialBuckets(0.00001, 4, 10), // 10µs to 2.6s
+	},
+	[]string{"item", "field"},
+)
+
+const ExprBudgetExceededMetricName = "cs_expr_budget_exceeded_total"
+
+var ExprBudgetExceeded = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: ExprBudgetExceededMetricName,
+		Help: "Evaluations of the expressions aborted over the time or step budget.",
+	},
+	[]string{"item", "field", "budget"},
+)
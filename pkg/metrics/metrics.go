@@ -38,16 +38,24 @@ func RegisterMetrics(metricsLevel MetricsLevelConfig) error {
 			BucketsUnderflow, BucketsCanceled, BucketsInstantiation, BucketsOverflow,
 			LapiRouteHits,
 			BucketsCurrentCount,
-			CacheMetrics, RegexpCacheMetrics, NodesWlHitsOk, NodesWlHits,
+			CacheMetrics, RegexpCacheMetrics, DataFileLastReload, DataFileReloadFailures, NodesWlHitsOk, NodesWlHits,
+			NodesWlEntryHits, NodesWlEntryLastHit, NodesWlEntryExpiry,
+			ExprDuration, ExprBudgetExceeded,
+			ThreatIntelIndicators, ThreatIntelMatches,
+			AcquisitionThrottledLines, AcquisitionDroppedLines,
 			PapiOrdersReceived, PapiInvalidOrdersReceived, PapiLastPullTimestamp, PapiPollErrors)
 	case MetricsLevelFull:
 		prometheus.MustRegister(GlobalParserHits, GlobalParserHitsOk, GlobalParserHitsKo,
-			NodesHits, NodesHitsOk, NodesHitsKo,
+			NodesHits, NodesHitsOk, NodesHitsKo, NodesGrokSkipped,
 			GlobalCsInfo, GlobalParsingHistogram, GlobalPourHistogram,
 			LapiRouteHits, LapiMachineHits, LapiBouncerHits, LapiNilDecisions, LapiNonNilDecisions, LapiResponseTime,
 			BucketsPour, BucketsUnderflow, BucketsCanceled, BucketsInstantiation, BucketsOverflow, BucketsCurrentCount,
 			GlobalActiveDecisions, GlobalAlerts, GlobalMachinesLastHeartbeatTimestamp, NodesWlHitsOk, NodesWlHits,
-			CacheMetrics, RegexpCacheMetrics,
+			NodesWlEntryHits, NodesWlEntryLastHit, NodesWlEntryExpiry,
+			ExprDuration, ExprBudgetExceeded,
+			ThreatIntelIndicators, ThreatIntelMatches,
+			CacheMetrics, RegexpCacheMetrics, DataFileLastReload, DataFileReloadFailures,
+			AcquisitionThrottledLines, AcquisitionDroppedLines,
 			PapiOrdersReceived, PapiInvalidOrdersReceived, PapiLastPullTimestamp, PapiPollErrors)
 	default:
 		return fmt.Errorf("%w: %s", ErrInvalidMetricsLevel, metricsLevel)
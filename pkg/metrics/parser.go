@@ -51,3 +51,43 @@ var NodesWlHits = prometheus.NewCounterVec(
 	},
 	[]string{"source", "type", "name", "reason", "stage", "acquis_type"},
 )
+
+const NodesGrokSkippedMetricName = "cs_node_grok_skipped_total"
+
+var NodesGrokSkipped = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: NodesGrokSkippedMetricName,
+		Help: "Total grok evaluations skipped by the prefilter.",
+	},
+	[]string{"source", "type", "name", "stage", "acquis_type"},
+)
+
+const NodesWlEntryHitsMetricName = "cs_node_wl_entry_hits_total"
+
+var NodesWlEntryHits = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: NodesWlEntryHitsMetricName,
+		Help: "Total events whitelisted by a whitelist entry.",
+	},
+	[]string{"name", "id"},
+)
+
+const NodesWlEntryLastHitMetricName = "cs_node_wl_entry_last_hit_timestamp_seconds"
+
+var NodesWlEntryLastHit = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: NodesWlEntryLastHitMetricName,
+		Help: "Time of the last event whitelisted by a whitelist entry.",
+	},
+	[]string{"name", "id"},
+)
+
+const NodesWlEntryExpiryMetricName = "cs_node_wl_entry_expiry_timestamp_seconds"
+
+var NodesWlEntryExpiry = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: NodesWlEntryExpiryMetricName,
+		Help: "Expiration time of a whitelist entry.",
+	},
+	[]string{"name", "id"},
+)
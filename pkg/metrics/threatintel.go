@@ -0,0 +1,23 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+const ThreatIntelIndicatorsMetricName = "cs_threat_intel_indicators"
+
+var ThreatIntelIndicators = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: ThreatIntelIndicatorsMetricName,
+		Help: "Indicators loaded from a threat intel feed, by type.",
+	},
+	[]string{"feed", "type"},
+)
+
+const ThreatIntelMatchesMetricName = "cs_threat_intel_matches_total"
+
+var ThreatIntelMatches = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: ThreatIntelMatchesMetricName,
+		Help: "Total values found in a threat intel feed with TIMatch.",
+	},
+	[]string{"feed"},
+)
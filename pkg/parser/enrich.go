@@ -1,8 +1,11 @@
 package parser
 
 import (
+	"fmt"
+
 	log "github.com/sirupsen/logrus"
 
+	"github.com/crowdsecurity/crowdsec/pkg/exprhelpers"
 	"github.com/crowdsecurity/crowdsec/pkg/pipeline"
 )
 
@@ -58,5 +61,35 @@ func Loadplugin() (EnricherCtx, error) {
 		enricherCtx.Registered[enricher.Name] = enricher
 	}
 
+	// the mmdb databases and inventories of the configuration
+	for _, name := range exprhelpers.EnricherNames() {
+		if _, ok := enricherCtx.Registered[name]; ok {
+			return enricherCtx, fmt.Errorf("enricher %s: the name is already used by a builtin enricher", name)
+		}
+
+		enricherCtx.Registered[name] = &Enricher{
+			Name:       name,
+			EnrichFunc: customEnricher(name),
+		}
+
+		log.Infof("Successfully registered enricher '%s'", name)
+	}
+
 	return enricherCtx, nil
 }
+
+func customEnricher(name string) EnrichFunc {
+	return func(field string, _ *pipeline.Event, plog *log.Entry) (map[string]string, error) {
+		if field == "" {
+			return nil, nil
+		}
+
+		ret, err := exprhelpers.Enrich(name, field)
+		if err != nil {
+			plog.Errorf("Unable to enrich '%s' with %s: %s", field, name, err)
+			return nil, nil //nolint:nilerr
+		}
+
+		return ret, nil
+	}
+}
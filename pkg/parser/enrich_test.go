@@ -0,0 +1,47 @@
+package parser
+
+import (
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/crowdsecurity/go-cs-lib/cstest"
+
+	"github.com/crowdsecurity/crowdsec/pkg/csconfig"
+	"github.com/crowdsecurity/crowdsec/pkg/exprhelpers"
+	"github.com/crowdsecurity/crowdsec/pkg/pipeline"
+)
+
+func TestCustomEnrichers(t *testing.T) {
+	err := exprhelpers.EnrichersInit([]*csconfig.EnricherCfg{
+		{Name: "assets", Type: csconfig.EnricherInventory, Path: "../exprhelpers/testdata/test_assets.csv", Key: "key"},
+	})
+	require.NoError(t, err)
+	t.Cleanup(exprhelpers.EnrichersClose)
+
+	ectx, err := Loadplugin()
+	require.NoError(t, err)
+	require.Contains(t, ectx.Registered, "assets")
+
+	logger := log.NewEntry(log.StandardLogger())
+	enrich := ectx.Registered["assets"].EnrichFunc
+
+	ret, err := enrich("192.0.2.10", &pipeline.Event{}, logger)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"owner": "web-team", "criticality": "high", "environment": "production"}, ret)
+
+	ret, err = enrich("", &pipeline.Event{}, logger)
+	require.NoError(t, err)
+	assert.Nil(t, ret)
+
+	// the builtin enrichers can't be replaced
+	err = exprhelpers.EnrichersInit([]*csconfig.EnricherCfg{
+		{Name: "GeoIpASN", Type: csconfig.EnricherInventory, Path: "../exprhelpers/testdata/test_assets.csv", Key: "key"},
+	})
+	require.NoError(t, err)
+
+	_, err = Loadplugin()
+	cstest.RequireErrorContains(t, err, "enricher GeoIpASN: the name is already used by a builtin enricher")
+}
@@ -35,8 +35,13 @@ type Node struct {
 	EnrichFunctions EnricherCtx
 
 	RuntimeGrok RuntimeGrokPattern `yaml:"-"`
+	RuntimeStructured *RuntimeStructuredPattern `yaml:"-"`
 	RuntimeStatics []RuntimeStatic `yaml:"-"`
 	RuntimeStashes []RuntimeStash `yaml:"-"`
+
+	// the literals required by the grok pattern, in the prefilter of the stage
+	prefilter         *grokPrefilter
+	prefilterLiterals []int
 }
 
 func (n *Node) UnmarshalYAML(unmarshal func(any) error) error {
@@ -165,12 +170,56 @@ func (n *Node) processWhitelist(cachedExprEnv map[string]any, p *pipeline.Event)
 	return isWhitelisted, nil
 }
 
-func (n *Node) processGrok(p *pipeline.Event, cachedExprEnv map[string]any) (bool, bool, error) {
+// patternInput returns the string a grok or structured pattern applies to:
+// a field of the event, or the output of an expression.
+func (n *Node) patternInput(targetField string, program *vm.Program, p *pipeline.Event, cachedExprEnv map[string]any) (string, bool) {
+	clog := n.Logger
+
+	// for unparsed, parsed etc. set sensible defaults to reduce user hassle
+	if targetField != "" {
+		// it's a hack to avoid using real reflect
+		if targetField == "Line.Raw" {
+			return p.Line.Raw, true
+		}
+
+		if val, ok := p.Parsed[targetField]; ok {
+			return val, true
+		}
+
+		clog.Debugf("(%s) target field %q doesn't exist in %v", n.rn, targetField, p.Parsed)
+
+		return "", false
+	}
+
+	if program == nil {
+		return "", true
+	}
+
+	output, err := exprhelpers.Run(program, cachedExprEnv, clog, n.Debug)
+	if err != nil {
+		clog.Warningf("failed to run RunTimeValue: %v", err)
+		return "", false
+	}
+
+	switch out := output.(type) {
+	case string:
+		return out, true
+	case int:
+		return strconv.Itoa(out), true
+	case float64, float32:
+		return fmt.Sprintf("%f", out), true
+	default:
+		clog.Errorf("unexpected return type for RunTimeValue: %T", output)
+	}
+
+	return "", true
+}
+
+func (n *Node) processGrok(p *pipeline.Event, cache *prefilterCache, cachedExprEnv map[string]any) (bool, bool, error) {
 	// Process grok if present, should be exclusive with nodes :)
 	var nodeHasOKGrok bool
 
 	clog := n.Logger
-	gstr := ""
 
 	if n.RuntimeGrok.RunTimeRegexp == nil {
 		clog.Tracef("! No grok pattern: %p", n.RuntimeGrok.RunTimeRegexp)
@@ -178,34 +227,10 @@ func (n *Node) processGrok(p *pipeline.Event, cachedExprEnv map[string]any) (boo
 	}
 
 	clog.Tracef("Processing grok pattern: %s: %p", n.Grok.RegexpName, n.RuntimeGrok.RunTimeRegexp)
-	// for unparsed, parsed etc. set sensible defaults to reduce user hassle
-	if n.Grok.TargetField != "" {
-		// it's a hack to avoid using real reflect
-		if n.Grok.TargetField == "Line.Raw" {
-			gstr = p.Line.Raw
-		} else if val, ok := p.Parsed[n.Grok.TargetField]; ok {
-			gstr = val
-		} else {
-			clog.Debugf("(%s) target field %q doesn't exist in %v", n.rn, n.Grok.TargetField, p.Parsed)
-			return false, false, nil
-		}
-	} else if n.RuntimeGrok.RunTimeValue != nil {
-		output, err := exprhelpers.Run(n.RuntimeGrok.RunTimeValue, cachedExprEnv, clog, n.Debug)
-		if err != nil {
-			clog.Warningf("failed to run RunTimeValue: %v", err)
-			return false, false, nil
-		}
 
-		switch out := output.(type) {
-		case string:
-			gstr = out
-		case int:
-			gstr = strconv.Itoa(out)
-		case float64, float32:
-			gstr = fmt.Sprintf("%f", out)
-		default:
-			clog.Errorf("unexpected return type for RunTimeValue: %T", output)
-		}
+	gstr, ok := n.patternInput(n.Grok.TargetField, n.RuntimeGrok.RunTimeValue, p, cachedExprEnv)
+	if !ok {
+		return false, false, nil
 	}
 
 	var groklabel string
@@ -215,6 +240,17 @@ func (n *Node) processGrok(p *pipeline.Event, cachedExprEnv map[string]any) (boo
 		groklabel = n.Grok.RegexpName
 	}
 
+	if n.prefilter != nil && cache != nil && !cache.match(n.prefilter, n.prefilterLiterals, gstr) {
+		// the pattern can't match, same as a failed grok
+		clog.Debugf("+ Grok %q skipped, a required literal is missing from %q", groklabel, gstr)
+
+		if n.Name != "" {
+			n.bumpNodeMetric(metrics.NodesGrokSkipped, p)
+		}
+
+		return false, false, nil
+	}
+
 	grok := n.RuntimeGrok.RunTimeRegexp.Parse(gstr)
 
 	if len(grok) == 0 {
@@ -242,6 +278,38 @@ func (n *Node) processGrok(p *pipeline.Event, cachedExprEnv map[string]any) (boo
 	return true, nodeHasOKGrok, nil
 }
 
+func (n *Node) processStructured(p *pipeline.Event, cachedExprEnv map[string]any) (bool, bool, error) {
+	clog := n.Logger
+	rs := n.RuntimeStructured
+
+	input, ok := n.patternInput(rs.Config.TargetField, rs.RunTimeValue, p, cachedExprEnv)
+	if !ok {
+		return false, false, nil
+	}
+
+	values, err := rs.Parse(input)
+	if err != nil {
+		// not parsed, node failed
+		clog.Debugf("+ %s didn't parse %q: %v", rs.Kind, input, err)
+		return false, false, nil
+	}
+
+	clog.Debugf("+ %s returned %d entries to merge in Parsed", rs.Kind, len(values))
+
+	for k, v := range values {
+		clog.Debugf("\t.Parsed[%q] = %q", k, v)
+		p.Parsed[k] = v
+	}
+
+	if err := rs.ProcessStatics(p, n.EnrichFunctions, clog, n.Debug); err != nil {
+		clog.Errorf("(%s) Failed to process statics: %v", n.rn, err)
+		return false, false, err
+	}
+
+	// a successful parse counts as a successful grok
+	return true, true, nil
+}
+
 func (n *Node) processStash(_ *pipeline.Event, cachedExprEnv map[string]any) error {
 	for idx, stash := range n.RuntimeStashes {
 		stash.Apply(idx, cachedExprEnv, n.Logger, n.Debug)
@@ -316,13 +384,20 @@ func (n *Node) process(p *pipeline.Event, ctx UnixParserCtx, expressionEnv map[s
 		return false, err
 	}
 
-	nodeState, nodeHasOKGrok, err := n.processGrok(p, cachedExprEnv)
+	var nodeHasOKGrok bool
+
+	if n.RuntimeStructured != nil {
+		nodeState, nodeHasOKGrok, err = n.processStructured(p, cachedExprEnv)
+	} else {
+		nodeState, nodeHasOKGrok, err = n.processGrok(p, ctx.prefilterCache, cachedExprEnv)
+	}
+
 	if err != nil {
 		return false, err
 	}
 
 	// Process the stash (data collection) if: a grok was present and succeeded, or if there is no grok
-	if nodeHasOKGrok || n.RuntimeGrok.RunTimeRegexp == nil {
+	if nodeHasOKGrok || (n.RuntimeGrok.RunTimeRegexp == nil && n.RuntimeStructured == nil) {
 		if err := n.processStash(p, cachedExprEnv); err != nil {
 			return false, err
 		}
@@ -463,6 +538,20 @@ func (n *Node) compile(pctx *UnixParserCtx, ectx EnricherCtx) error {
 		valid = true
 	}
 
+	structured, err := n.compileStructured()
+	if err != nil {
+		return err
+	}
+
+	if structured != nil {
+		if n.RuntimeGrok.RunTimeRegexp != nil {
+			return fmt.Errorf("%s and grok can't be used in the same node", structured.Kind)
+		}
+
+		n.RuntimeStructured = structured
+		valid = true
+	}
+
 	for _, stash := range n.Stashes {
 		compiled, err := stash.Compile(n.Logger)
 		if err != nil {
@@ -524,9 +613,42 @@ func (n *Node) compile(pctx *UnixParserCtx, ectx EnricherCtx) error {
 		return errors.New("Node is empty")
 	}
 
+	n.labelExpressions()
+
 	return n.validate(ectx)
 }
 
+// labelExpressions names the compiled expressions of the node in the profiling metrics.
+func (n *Node) labelExpressions() {
+	exprhelpers.Label(n.RunTimeFilter, n.Name, "filter")
+	exprhelpers.Label(n.RuntimeGrok.RunTimeValue, n.Name, "grok.expression")
+
+	for _, static := range n.RuntimeGrok.RuntimeStatics {
+		exprhelpers.Label(static.RunTimeValue, n.Name, "grok.statics")
+	}
+
+	if n.RuntimeStructured != nil {
+		exprhelpers.Label(n.RuntimeStructured.RunTimeValue, n.Name, n.RuntimeStructured.Kind+".expression")
+
+		for _, static := range n.RuntimeStructured.RuntimeStatics {
+			exprhelpers.Label(static.RunTimeValue, n.Name, n.RuntimeStructured.Kind+".statics")
+		}
+	}
+
+	for _, static := range n.RuntimeStatics {
+		exprhelpers.Label(static.RunTimeValue, n.Name, "statics")
+	}
+
+	for _, stash := range n.RuntimeStashes {
+		exprhelpers.Label(stash.KeyExpression, n.Name, "stash.key")
+		exprhelpers.Label(stash.ValueExpression, n.Name, "stash.value")
+	}
+
+	for _, item := range n.Whitelist.B_Exprs {
+		exprhelpers.Label(item.Filter, n.Name, "whitelist.expression")
+	}
+}
+
 func (n *Node) bumpNodeMetric(counter *prometheus.CounterVec, p *pipeline.Event) {
 	// better safe than sorry
 	acquisType := p.Line.Labels["type"]
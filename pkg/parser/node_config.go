@@ -34,6 +34,11 @@ type NodeConfig struct {
 
 	// Holds a grok pattern
 	Grok GrokPattern `yaml:"grok,omitempty"`
+	// Parse structured logs without grok, exclusive with grok and each other
+	JSON    *JSONPattern    `yaml:"json,omitempty"`
+	Logfmt  *LogfmtPattern  `yaml:"logfmt,omitempty"`
+	CSV     *CSVPattern     `yaml:"csv,omitempty"`
+	Dissect *DissectPattern `yaml:"dissect,omitempty"`
 	// Statics can be present in any type of node and is executed last
 	Statics []Static `yaml:"statics,omitempty"`
 	// Stash allows to capture data from the log line and store it in an accessible cache
@@ -152,6 +152,47 @@ func testOneParser(t require.TestingT, pctx *UnixParserCtx, ectx EnricherCtx, di
 	return nil
 }
 
+func TestParseTraced(t *testing.T) {
+	pctx, ectx := prepTests(t)
+
+	dir := "./testdata/multi-stage-grok"
+
+	nodes, err := LoadStages([]Stagefile{
+		{Filename: filepath.Join(dir, "base-grok-s00.yaml"), Stage: "s00-raw"},
+		{Filename: filepath.Join(dir, "base-grok-s01.yaml"), Stage: "s01-raw"},
+	}, pctx, ectx)
+	require.NoError(t, err)
+
+	tests := []struct {
+		raw      string
+		process  bool
+		expected []NodeResult
+	}{
+		{"xxheader VALUE1 trailing stuff", true, []NodeResult{
+			{Stage: "s00-raw", Node: "tests/base-grok", Success: true},
+			{Stage: "s01-raw", Node: "tests/second-stage-grok", Success: true},
+		}},
+		{"xxheader VALUE2 trailing stuff", false, []NodeResult{
+			{Stage: "s00-raw", Node: "tests/base-grok", Success: true},
+			{Stage: "s01-raw", Node: "tests/second-stage-grok", Success: false},
+		}},
+		{"something else", false, []NodeResult{
+			{Stage: "s00-raw", Node: "tests/base-grok", Success: false},
+		}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.raw, func(t *testing.T) {
+			in := pipeline.Event{Line: pipeline.Line{Raw: tc.raw, Labels: map[string]string{"type": "testlog"}}}
+
+			out, results, err := ParseTraced(*pctx, in, nodes, nil)
+			require.NoError(t, err)
+			assert.Equal(t, tc.process, out.Process)
+			assert.Equal(t, tc.expected, results)
+		})
+	}
+}
+
 // prepTests is going to do the initialisation of parser : it's going to load enrichment plugins and load the patterns. This is done here so that we don't redo it for each test
 func prepTests(t require.TestingT) (*UnixParserCtx, EnricherCtx) {
 	var (
@@ -0,0 +1,194 @@
+package parser
+
+import (
+	"regexp/syntax"
+
+	aho_corasick "github.com/petar-dambovaliev/aho-corasick"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/crowdsecurity/grokky"
+)
+
+// minPrefilterLiteralLen is the minimum length of the literals used by the
+// prefilter: shorter ones are found in almost every line.
+const minPrefilterLiteralLen = 3
+
+// grokPrefilter finds in one pass which of the literals required by the grok
+// patterns of a stage are present in an input, so that the patterns which
+// can't match are skipped without running the regexp.
+type grokPrefilter struct {
+	stage    string
+	matcher  aho_corasick.AhoCorasick
+	literals []string
+}
+
+func (pf *grokPrefilter) scan(input string) []bool {
+	found := make([]bool, len(pf.literals))
+
+	iter := pf.matcher.IterOverlapping(input)
+	for m := iter.Next(); m != nil; m = iter.Next() {
+		found[m.Pattern()] = true
+	}
+
+	return found
+}
+
+// prefilterCache keeps the literals found in the inputs of an event,
+// so that each input is scanned once per stage.
+type prefilterCache struct {
+	entries []prefilterCacheEntry
+}
+
+type prefilterCacheEntry struct {
+	prefilter *grokPrefilter
+	input     string
+	found     []bool
+}
+
+// match returns false if one of the literals is missing from the input.
+func (c *prefilterCache) match(pf *grokPrefilter, literals []int, input string) bool {
+	var found []bool
+
+	for i := range c.entries {
+		if c.entries[i].prefilter == pf && c.entries[i].input == input {
+			found = c.entries[i].found
+			break
+		}
+	}
+
+	if found == nil {
+		found = pf.scan(input)
+		c.entries = append(c.entries, prefilterCacheEntry{prefilter: pf, input: input, found: found})
+	}
+
+	for _, id := range literals {
+		if !found[id] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// requiredLiterals returns the literal strings that are part of every match of the regexp.
+func requiredLiterals(re *syntax.Regexp) []string {
+	switch re.Op {
+	case syntax.OpLiteral:
+		// case folding is not limited to ascii, leave it to the regexp
+		if re.Flags&syntax.FoldCase != 0 {
+			return nil
+		}
+
+		return []string{string(re.Rune)}
+	case syntax.OpCapture, syntax.OpPlus:
+		return requiredLiterals(re.Sub[0])
+	case syntax.OpRepeat:
+		if re.Min > 0 {
+			return requiredLiterals(re.Sub[0])
+		}
+	case syntax.OpConcat:
+		var ret []string
+
+		for _, sub := range re.Sub {
+			ret = append(ret, requiredLiterals(sub)...)
+		}
+
+		return ret
+	}
+
+	return nil
+}
+
+// grokLiterals returns the literals required by a compiled grok pattern, long
+// enough to be worth checking. The RE2 and Go syntaxes are close enough to
+// read both: if the pattern can't be parsed, it is not prefiltered.
+func grokLiterals(pattern grokky.Pattern) []string {
+	re, err := syntax.Parse(pattern.String(), syntax.Perl)
+	if err != nil {
+		return nil
+	}
+
+	var ret []string
+
+	seen := make(map[string]bool)
+
+	for _, lit := range requiredLiterals(re.Simplify()) {
+		if len(lit) < minPrefilterLiteralLen || seen[lit] {
+			continue
+		}
+
+		seen[lit] = true
+
+		ret = append(ret, lit)
+	}
+
+	return ret
+}
+
+// buildGrokPrefilters builds the prefilter of each stage, for the grok
+// patterns of the nodes and their children.
+func buildGrokPrefilters(nodes []Node) {
+	type stageBuilder struct {
+		prefilter *grokPrefilter
+		ids       map[string]int
+		nodes     int
+	}
+
+	stages := make(map[string]*stageBuilder)
+
+	var walk func(n *Node)
+
+	walk = func(n *Node) {
+		for idx := range n.LeavesNodes {
+			walk(&n.LeavesNodes[idx])
+		}
+
+		if n.RuntimeGrok.RunTimeRegexp == nil {
+			return
+		}
+
+		literals := grokLiterals(n.RuntimeGrok.RunTimeRegexp)
+		if len(literals) == 0 {
+			return
+		}
+
+		sb, ok := stages[n.Stage]
+		if !ok {
+			sb = &stageBuilder{
+				prefilter: &grokPrefilter{stage: n.Stage},
+				ids:       make(map[string]int),
+			}
+			stages[n.Stage] = sb
+		}
+
+		n.prefilter = sb.prefilter
+		n.prefilterLiterals = nil
+
+		for _, lit := range literals {
+			id, ok := sb.ids[lit]
+			if !ok {
+				id = len(sb.prefilter.literals)
+				sb.ids[lit] = id
+				sb.prefilter.literals = append(sb.prefilter.literals, lit)
+			}
+
+			n.prefilterLiterals = append(n.prefilterLiterals, id)
+		}
+
+		sb.nodes++
+	}
+
+	for idx := range nodes {
+		walk(&nodes[idx])
+	}
+
+	for _, sb := range stages {
+		builder := aho_corasick.NewAhoCorasickBuilder(aho_corasick.Opts{
+			MatchKind: aho_corasick.StandardMatch,
+			DFA:       true,
+		})
+		sb.prefilter.matcher = builder.Build(sb.prefilter.literals)
+
+		log.Infof("Grok prefilter for stage %s: %d literals for %d patterns", sb.prefilter.stage, len(sb.prefilter.literals), sb.nodes)
+	}
+}
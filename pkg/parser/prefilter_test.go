@@ -0,0 +1,94 @@
+package parser
+
+import (
+	"regexp/syntax"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/crowdsecurity/grokky"
+
+	"github.com/crowdsecurity/crowdsec/pkg/pipeline"
+)
+
+func TestRequiredLiterals(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		expected []string
+	}{
+		{`^Failed password for (\S+) from (\d+\.\d+\.\d+\.\d+)`, []string{"Failed password for ", " from ", ".", ".", "."}},
+		{`sshd(?:\[\d+\])?: (?P<msg>.*)`, []string{"sshd", ": "}},
+		{`(?:GET|POST) /index`, []string{" /index"}},
+		{`(?i)login failed`, nil},
+		{`(?:abc)+ (?:def)* (?:ghi){2,3}`, []string{"abc", " ", " ", "ghi", "ghi"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.pattern, func(t *testing.T) {
+			re, err := syntax.Parse(tc.pattern, syntax.Perl)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, requiredLiterals(re.Simplify()))
+		})
+	}
+}
+
+func TestGrokPrefilter(t *testing.T) {
+	host := grokky.NewBase()
+
+	compile := func(name string, pattern string) Node {
+		n := Node{
+			NodeConfig: NodeConfig{
+				Name:  name,
+				Stage: "s01-parse",
+				Grok:  GrokPattern{RegexpValue: pattern, TargetField: "Line.Raw"},
+			},
+			Logger: log.NewEntry(log.New()),
+		}
+
+		rg, err := n.Grok.Compile(&UnixParserCtx{Grok: host}, n.Logger)
+		require.NoError(t, err)
+
+		n.RuntimeGrok = *rg
+
+		return n
+	}
+
+	parent := Node{NodeConfig: NodeConfig{Stage: "s01-parse"}}
+	parent.LeavesNodes = []Node{compile("child", `^Invalid user %{USERNAME:user} from %{IP:ip}`)}
+
+	nodes := []Node{
+		compile("failed", `^Failed password for %{USERNAME:user} from %{IP:ip}`),
+		compile("short", `^%{IP:ip} -`),
+		parent,
+	}
+
+	buildGrokPrefilters(nodes)
+
+	require.NotNil(t, nodes[0].prefilter)
+	assert.Nil(t, nodes[1].prefilter, "no literal long enough")
+	assert.Same(t, nodes[0].prefilter, nodes[2].LeavesNodes[0].prefilter)
+	assert.Equal(t, []string{"Failed password for ", " from ", "Invalid user "}, nodes[0].prefilter.literals)
+
+	cache := &prefilterCache{}
+	line := "Invalid user admin from 192.0.2.1"
+
+	assert.False(t, cache.match(nodes[0].prefilter, nodes[0].prefilterLiterals, line))
+	assert.True(t, cache.match(nodes[0].prefilter, nodes[2].LeavesNodes[0].prefilterLiterals, line))
+	assert.Len(t, cache.entries, 1, "the input is scanned once")
+
+	// a skipped grok is a failed grok
+	evt := pipeline.Event{Line: pipeline.Line{Raw: line}, Parsed: map[string]string{}}
+
+	ok, hasOKGrok, err := nodes[0].processGrok(&evt, cache, nil)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.False(t, hasOKGrok)
+
+	ok, hasOKGrok, err = nodes[2].LeavesNodes[0].processGrok(&evt, cache, nil)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, hasOKGrok)
+	assert.Equal(t, "admin", evt.Parsed["user"])
+}
@@ -230,7 +230,27 @@ func (rg *RuntimeGrokPattern) ProcessStatics(event *pipeline.Event, ectx Enriche
 	return nil
 }
 
+// NodeResult is the outcome of a node for an event, see ParseTraced.
+type NodeResult struct {
+	Stage   string `json:"stage"`
+	Node    string `json:"node"`
+	Success bool   `json:"success"`
+}
+
 func Parse(ctx UnixParserCtx, event pipeline.Event, nodes []Node, collector *StageParseCollector) (pipeline.Event, error) {
+	return parse(ctx, event, nodes, collector, nil)
+}
+
+// ParseTraced is Parse, and also returns the nodes that were evaluated, in order.
+func ParseTraced(ctx UnixParserCtx, event pipeline.Event, nodes []Node, collector *StageParseCollector) (pipeline.Event, []NodeResult, error) {
+	var results []NodeResult
+
+	event, err := parse(ctx, event, nodes, collector, &results)
+
+	return event, results, err
+}
+
+func parse(ctx UnixParserCtx, event pipeline.Event, nodes []Node, collector *StageParseCollector, results *[]NodeResult) (pipeline.Event, error) {
 	/* the stage is undefined, probably line is freshly acquired, set to first stage !*/
 	if event.Stage == "" && len(ctx.Stages) > 0 {
 		event.Stage = ctx.Stages[0]
@@ -264,6 +284,8 @@ func Parse(ctx UnixParserCtx, event pipeline.Event, nodes []Node, collector *Sta
 
 	exprEnv := map[string]any{"evt": &event}
 
+	ctx.prefilterCache = &prefilterCache{}
+
 	for _, stage := range ctx.Stages {
 		/* if the node is forward in stages, seek to this stage */
 		/* this is for example used by testing system to inject logs in post-syslog-parsing phase*/
@@ -313,6 +335,10 @@ func Parse(ctx UnixParserCtx, event pipeline.Event, nodes []Node, collector *Sta
 				collector.Add(stage, nodes[idx].Name, event, ret)
 			}
 
+			if results != nil {
+				*results = append(*results, NodeResult{Stage: stage, Node: nodes[idx].Name, Success: ret})
+			}
+
 			if ret {
 				isStageOK = true
 			}
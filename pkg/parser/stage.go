@@ -23,6 +23,7 @@ import (
 
 	"github.com/crowdsecurity/crowdsec/pkg/cwversion/constraint"
 	"github.com/crowdsecurity/crowdsec/pkg/exprhelpers"
+	"github.com/crowdsecurity/crowdsec/pkg/fflag"
 )
 
 var seed namegenerator.Generator = namegenerator.NewNameGenerator(time.Now().UTC().UnixNano())
@@ -61,6 +62,10 @@ func LoadStages(stageFiles []Stagefile, pctx *UnixParserCtx, ectx EnricherCtx) (
 	sort.Strings(pctx.Stages)
 	log.Infof("Loaded %d nodes from %d stages", len(allNodes), len(pctx.Stages))
 
+	if !fflag.DisableGrokPrefilter.IsEnabled() {
+		buildGrokPrefilters(allNodes)
+	}
+
 	return allNodes, nil
 }
 
@@ -146,7 +151,7 @@ func processStageFile(stageFile Stagefile, pctx *UnixParserCtx, ectx EnricherCtx
 		}
 
 		for _, data := range node.Data {
-			err = exprhelpers.FileInit(pctx.DataFolder, data.DestPath, data.Type)
+			err = exprhelpers.DataProviderInit(pctx.DataFolder, data)
 			if err != nil {
 				log.Error(err.Error())
 			}
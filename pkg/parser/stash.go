@@ -21,6 +21,7 @@ type Stash struct {
 	TTL        string `yaml:"ttl,omitempty"`
 	MaxMapSize int    `yaml:"size,omitempty"`
 	Strategy   string `yaml:"strategy,omitempty"`
+	Backend    string `yaml:"backend,omitempty"` // memory (default), disk or redis
 }
 
 type RuntimeStash struct {
@@ -56,6 +57,12 @@ func (s *Stash) Validate() error {
 		s.MaxMapSize = 100
 	}
 
+	switch s.Backend {
+	case "", cache.BackendMemory, cache.BackendDisk, cache.BackendRedis:
+	default:
+		return fmt.Errorf("%s: backend must be one of %s, %s, %s", s.Name, cache.BackendMemory, cache.BackendDisk, cache.BackendRedis)
+	}
+
 	return nil
 }
 
@@ -88,6 +95,7 @@ func (s *Stash) Compile(logger *log.Entry) (*RuntimeStash, error) {
 		TTL:      rs.TTLVal,
 		Name:     s.Name,
 		Strategy: s.Strategy,
+		Backend:  s.Backend,
 		LogLevel: logger.Logger.GetLevel(),
 	}
 
@@ -0,0 +1,586 @@
+package parser
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/vm"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/crowdsecurity/crowdsec/pkg/exprhelpers"
+	"github.com/crowdsecurity/crowdsec/pkg/pipeline"
+)
+
+// StructuredPattern holds the settings shared by the directives that parse
+// structured logs (json, logfmt, csv, dissect) into Parsed, without grok.
+// Like a grok pattern, it applies to a field or to the output of an expression,
+// and the node is successful if the input could be parsed.
+type StructuredPattern struct {
+	TargetField string   `yaml:"apply_on,omitempty"`   // the field to parse, Line.Raw by default
+	ExpValue    string   `yaml:"expression,omitempty"` // the output of the expression is parsed instead
+	Prefix      string   `yaml:"prefix,omitempty"`     // prepended to the keys written in Parsed
+	Fields      []string `yaml:"fields,omitempty"`     // if set, only these keys are written in Parsed
+	Statics     []Static `yaml:"statics,omitempty"`    // apply if the input could be parsed
+}
+
+// JSONPattern parses a JSON object. Nested objects are flattened, their keys
+// joined with the separator. Arrays are kept as JSON.
+type JSONPattern struct {
+	StructuredPattern `yaml:",inline"`
+	Separator         string `yaml:"separator,omitempty"`
+}
+
+// LogfmtPattern parses key=value pairs, separated by spaces. Values can be quoted.
+type LogfmtPattern struct {
+	StructuredPattern `yaml:",inline"`
+}
+
+// CSVPattern parses a line of delimited values into named columns.
+type CSVPattern struct {
+	StructuredPattern `yaml:",inline"`
+	// the name of each column, an empty name skips the column
+	Columns   []string `yaml:"columns"`
+	Delimiter string   `yaml:"delimiter,omitempty"`
+}
+
+// DissectPattern splits the input on the delimiters between %{field} placeholders.
+type DissectPattern struct {
+	StructuredPattern `yaml:",inline"`
+	Pattern           string `yaml:"pattern"`
+}
+
+type RuntimeStructuredPattern struct {
+	Kind   string
+	Config *StructuredPattern
+
+	RunTimeValue   *vm.Program // the actual compiled expression
+	RuntimeStatics []RuntimeStatic
+
+	parse  func(string) (map[string]string, error)
+	names  []string // the keys that can be written, if they are known in advance
+	fields map[string]bool
+}
+
+func (s *StructuredPattern) compile(kind string, logger *log.Entry) (*RuntimeStructuredPattern, error) {
+	var err error
+
+	if s.TargetField != "" && s.ExpValue != "" {
+		return nil, fmt.Errorf("%s: 'apply_on' and 'expression' are mutually exclusive", kind)
+	}
+
+	if s.TargetField == "" && s.ExpValue == "" {
+		s.TargetField = "Line.Raw"
+	}
+
+	rs := &RuntimeStructuredPattern{
+		Kind:   kind,
+		Config: s,
+	}
+
+	if s.ExpValue != "" {
+		rs.RunTimeValue, err = expr.Compile(s.ExpValue,
+			exprhelpers.GetExprOptions(map[string]any{"evt": &pipeline.Event{}})...)
+		if err != nil {
+			return nil, fmt.Errorf("while compiling %s expression: %w", kind, err)
+		}
+	}
+
+	if len(s.Fields) > 0 {
+		rs.fields = make(map[string]bool, len(s.Fields))
+		for _, f := range s.Fields {
+			rs.fields[f] = true
+		}
+	}
+
+	for _, static := range s.Statics {
+		compiled, err := static.Compile()
+		if err != nil {
+			return nil, err
+		}
+
+		rs.RuntimeStatics = append(rs.RuntimeStatics, *compiled)
+	}
+
+	logger.Tracef("%s parser on %s%s", kind, s.TargetField, s.ExpValue)
+
+	return rs, nil
+}
+
+func (j *JSONPattern) Compile(logger *log.Entry) (*RuntimeStructuredPattern, error) {
+	rs, err := j.compile("json", logger)
+	if err != nil {
+		return nil, err
+	}
+
+	sep := j.Separator
+	if sep == "" {
+		sep = "."
+	}
+
+	rs.parse = func(s string) (map[string]string, error) {
+		return parseJSONObject(s, sep)
+	}
+
+	return rs, nil
+}
+
+func (l *LogfmtPattern) Compile(logger *log.Entry) (*RuntimeStructuredPattern, error) {
+	rs, err := l.compile("logfmt", logger)
+	if err != nil {
+		return nil, err
+	}
+
+	rs.parse = parseLogfmt
+
+	return rs, nil
+}
+
+func (c *CSVPattern) Compile(logger *log.Entry) (*RuntimeStructuredPattern, error) {
+	rs, err := c.compile("csv", logger)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(c.Columns) == 0 {
+		return nil, errors.New("csv: 'columns' is required")
+	}
+
+	delim := ','
+
+	if c.Delimiter != "" {
+		r, size := utf8.DecodeRuneInString(c.Delimiter)
+		if size != len(c.Delimiter) || r == '"' || r == '\r' || r == '\n' || r == utf8.RuneError {
+			return nil, fmt.Errorf("csv: invalid delimiter %q", c.Delimiter)
+		}
+
+		delim = r
+	}
+
+	columns := c.Columns
+
+	for _, col := range columns {
+		if col != "" {
+			rs.names = append(rs.names, col)
+		}
+	}
+
+	rs.parse = func(s string) (map[string]string, error) {
+		return parseCSVLine(s, delim, columns)
+	}
+
+	return rs, nil
+}
+
+func (d *DissectPattern) Compile(logger *log.Entry) (*RuntimeStructuredPattern, error) {
+	rs, err := d.compile("dissect", logger)
+	if err != nil {
+		return nil, err
+	}
+
+	dissector, err := compileDissect(d.Pattern)
+	if err != nil {
+		return nil, fmt.Errorf("dissect: %w", err)
+	}
+
+	for _, f := range dissector.fields {
+		if !f.skip && !f.appendTo {
+			rs.names = append(rs.names, f.name)
+		}
+	}
+
+	rs.parse = dissector.parse
+
+	return rs, nil
+}
+
+// Parse returns the entries to merge in Parsed, after the allow-list and the prefix.
+func (rs *RuntimeStructuredPattern) Parse(s string) (map[string]string, error) {
+	values, err := rs.parse(s)
+	if err != nil {
+		return nil, err
+	}
+
+	if rs.fields == nil && rs.Config.Prefix == "" {
+		return values, nil
+	}
+
+	ret := make(map[string]string, len(values))
+
+	for k, v := range values {
+		if rs.fields != nil && !rs.fields[k] {
+			continue
+		}
+
+		ret[rs.Config.Prefix+k] = v
+	}
+
+	return ret, nil
+}
+
+// Names returns the keys that can be written in Parsed, when they are known
+// from the configuration (csv columns, dissect fields or allow-list).
+func (rs *RuntimeStructuredPattern) Names() []string {
+	names := rs.names
+	if len(rs.Config.Fields) > 0 {
+		names = rs.Config.Fields
+	}
+
+	ret := make([]string, 0, len(names))
+
+	for _, name := range names {
+		if rs.fields != nil && !rs.fields[name] {
+			continue
+		}
+
+		ret = append(ret, rs.Config.Prefix+name)
+	}
+
+	return ret
+}
+
+func (rs *RuntimeStructuredPattern) ProcessStatics(event *pipeline.Event, ectx EnricherCtx, logger *log.Entry, debug bool) error {
+	for _, static := range rs.RuntimeStatics {
+		if err := static.Apply(event, ectx, logger, debug); err != nil {
+			return fmt.Errorf("applying %s: %w", static.Config.targetExpr(), err)
+		}
+	}
+
+	return nil
+}
+
+func parseJSONObject(s string, sep string) (map[string]string, error) {
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+
+	var obj map[string]any
+
+	if err := dec.Decode(&obj); err != nil {
+		return nil, err
+	}
+
+	if obj == nil {
+		return nil, errors.New("not a JSON object")
+	}
+
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return nil, errors.New("trailing data after the JSON object")
+	}
+
+	ret := make(map[string]string, len(obj))
+	flattenJSON(ret, "", sep, obj)
+
+	return ret, nil
+}
+
+func flattenJSON(out map[string]string, key string, sep string, value any) {
+	switch v := value.(type) {
+	case map[string]any:
+		if len(v) == 0 && key != "" {
+			out[key] = "{}"
+		}
+
+		for k, child := range v {
+			if key != "" {
+				k = key + sep + k
+			}
+
+			flattenJSON(out, k, sep, child)
+		}
+	case []any:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return
+		}
+
+		out[key] = string(b)
+	case string:
+		out[key] = v
+	case json.Number:
+		out[key] = v.String()
+	case bool:
+		out[key] = strconv.FormatBool(v)
+	case nil:
+		out[key] = ""
+	}
+}
+
+func isLogfmtSpace(c byte) bool {
+	return c == ' ' || c == '\t'
+}
+
+// parseLogfmt reads key=value pairs. A key without a value is set to an empty
+// string, but there must be at least one pair for the line to be logfmt.
+func parseLogfmt(s string) (map[string]string, error) {
+	ret := make(map[string]string)
+	pairs := 0
+	i := 0
+
+	for {
+		for i < len(s) && isLogfmtSpace(s[i]) {
+			i++
+		}
+
+		if i >= len(s) {
+			break
+		}
+
+		start := i
+
+		for i < len(s) && s[i] != '=' && !isLogfmtSpace(s[i]) {
+			if s[i] == '"' {
+				return nil, fmt.Errorf("unexpected quote at position %d", i)
+			}
+
+			i++
+		}
+
+		key := s[start:i]
+		if key == "" {
+			return nil, fmt.Errorf("missing key at position %d", start)
+		}
+
+		if i >= len(s) || s[i] != '=' {
+			ret[key] = ""
+			continue
+		}
+
+		i++
+		pairs++
+
+		if i < len(s) && s[i] == '"' {
+			quoted, err := strconv.QuotedPrefix(s[i:])
+			if err != nil {
+				return nil, fmt.Errorf("invalid quoted value for %q", key)
+			}
+
+			value, err := strconv.Unquote(quoted)
+			if err != nil {
+				return nil, fmt.Errorf("invalid quoted value for %q: %w", key, err)
+			}
+
+			i += len(quoted)
+
+			if i < len(s) && !isLogfmtSpace(s[i]) {
+				return nil, fmt.Errorf("unexpected character after the value of %q", key)
+			}
+
+			ret[key] = value
+
+			continue
+		}
+
+		start = i
+
+		for i < len(s) && !isLogfmtSpace(s[i]) {
+			i++
+		}
+
+		ret[key] = s[start:i]
+	}
+
+	if pairs == 0 {
+		return nil, errors.New("no key=value pair")
+	}
+
+	return ret, nil
+}
+
+func parseCSVLine(s string, delim rune, columns []string) (map[string]string, error) {
+	r := csv.NewReader(strings.NewReader(s))
+	r.Comma = delim
+	r.LazyQuotes = true
+	r.FieldsPerRecord = -1
+
+	record, err := r.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(record) < len(columns) {
+		return nil, fmt.Errorf("%d values, expected %d", len(record), len(columns))
+	}
+
+	ret := make(map[string]string, len(columns))
+
+	for i, col := range columns {
+		if col != "" {
+			ret[col] = record[i]
+		}
+	}
+
+	return ret, nil
+}
+
+type dissectField struct {
+	name string
+	// the text up to the next field, or the end of the input
+	delim string
+	// %{} or %{?name}: match, but don't keep
+	skip bool
+	// %{+name}: append to the value of name, with a space
+	appendTo bool
+	// %{name->}: ignore the repetitions of the delimiter, for padded columns
+	padded bool
+}
+
+type dissector struct {
+	prefix string
+	fields []dissectField
+}
+
+func compileDissect(pattern string) (*dissector, error) {
+	idx := strings.Index(pattern, "%{")
+	if idx < 0 {
+		return nil, errors.New("the pattern has no %{field}")
+	}
+
+	d := &dissector{prefix: pattern[:idx]}
+	rest := pattern[idx:]
+
+	for rest != "" {
+		end := strings.IndexByte(rest, '}')
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated field %q", rest)
+		}
+
+		key := rest[2:end]
+		rest = rest[end+1:]
+
+		f := dissectField{}
+
+		if k, ok := strings.CutSuffix(key, "->"); ok {
+			f.padded = true
+			key = k
+		}
+
+		switch {
+		case key == "":
+			f.skip = true
+		case key[0] == '?':
+			f.skip = true
+			key = key[1:]
+		case key[0] == '+':
+			f.appendTo = true
+			key = key[1:]
+		}
+
+		if key == "" && !f.skip {
+			return nil, errors.New("empty field name")
+		}
+
+		f.name = key
+
+		next := strings.Index(rest, "%{")
+		if next < 0 {
+			f.delim = rest
+			rest = ""
+		} else {
+			f.delim = rest[:next]
+			rest = rest[next:]
+		}
+
+		if f.delim == "" && rest != "" {
+			return nil, fmt.Errorf("no delimiter between %%{%s} and the next field", f.name)
+		}
+
+		d.fields = append(d.fields, f)
+	}
+
+	return d, nil
+}
+
+func (d *dissector) parse(s string) (map[string]string, error) {
+	rest, ok := strings.CutPrefix(s, d.prefix)
+	if !ok {
+		return nil, fmt.Errorf("missing prefix %q", d.prefix)
+	}
+
+	ret := make(map[string]string, len(d.fields))
+
+	for i, f := range d.fields {
+		var value string
+
+		if f.delim == "" {
+			// last field, takes everything
+			value, rest = rest, ""
+		} else {
+			idx := strings.Index(rest, f.delim)
+			if idx < 0 {
+				return nil, fmt.Errorf("delimiter %q not found", f.delim)
+			}
+
+			value, rest = rest[:idx], rest[idx+len(f.delim):]
+
+			if f.padded {
+				for strings.HasPrefix(rest, f.delim) {
+					rest = rest[len(f.delim):]
+				}
+			}
+
+			if i == len(d.fields)-1 && rest != "" {
+				return nil, fmt.Errorf("unexpected data after %q", f.delim)
+			}
+		}
+
+		switch {
+		case f.skip:
+		case f.appendTo:
+			if prev, ok := ret[f.name]; ok {
+				value = prev + " " + value
+			}
+
+			ret[f.name] = value
+		default:
+			ret[f.name] = value
+		}
+	}
+
+	return ret, nil
+}
+
+// compileStructured compiles the json, logfmt, csv or dissect directive of
+// the node, if any. They are mutually exclusive.
+func (n *Node) compileStructured() (*RuntimeStructuredPattern, error) {
+	var (
+		rs    *RuntimeStructuredPattern
+		kinds []string
+		err   error
+	)
+
+	if n.JSON != nil {
+		kinds = append(kinds, "json")
+		rs, err = n.JSON.Compile(n.Logger)
+	}
+
+	if n.Logfmt != nil && err == nil {
+		kinds = append(kinds, "logfmt")
+		rs, err = n.Logfmt.Compile(n.Logger)
+	}
+
+	if n.CSV != nil && err == nil {
+		kinds = append(kinds, "csv")
+		rs, err = n.CSV.Compile(n.Logger)
+	}
+
+	if n.Dissect != nil && err == nil {
+		kinds = append(kinds, "dissect")
+		rs, err = n.Dissect.Compile(n.Logger)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(kinds) > 1 {
+		return nil, fmt.Errorf("%s can't be used in the same node", strings.Join(kinds, ", "))
+	}
+
+	return rs, nil
+}
@@ -0,0 +1,189 @@
+package parser
+
+import (
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/crowdsecurity/go-cs-lib/cstest"
+)
+
+func TestParseLogfmt(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expected    map[string]string
+		expectedErr string
+	}{
+		{
+			name:     "simple",
+			input:    "a=1 b=two",
+			expected: map[string]string{"a": "1", "b": "two"},
+		},
+		{
+			name:     "quoted, bare key and empty value",
+			input:    `msg="hello \"world\"\tagain"  flag empty=`,
+			expected: map[string]string{"msg": "hello \"world\"\tagain", "flag": "", "empty": ""},
+		},
+		{
+			name:        "plain text",
+			input:       "hello world",
+			expectedErr: "no key=value pair",
+		},
+		{
+			name:        "unterminated quote",
+			input:       `a="oops`,
+			expectedErr: `invalid quoted value for "a"`,
+		},
+		{
+			name:        "missing key",
+			input:       "=value",
+			expectedErr: "missing key at position 0",
+		},
+		{
+			name:        "garbage after quoted value",
+			input:       `a="x"y b=1`,
+			expectedErr: `unexpected character after the value of "a"`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseLogfmt(tc.input)
+			cstest.RequireErrorContains(t, err, tc.expectedErr)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
+
+func TestDissect(t *testing.T) {
+	tests := []struct {
+		name        string
+		pattern     string
+		input       string
+		expected    map[string]string
+		expectedErr string
+	}{
+		{
+			name:     "prefix, skip and trailing field",
+			pattern:  "<%{pri}>%{?host} %{} %{msg}",
+			input:    "<13>myhost sshd[42] Accepted password for root",
+			expected: map[string]string{"pri": "13", "msg": "Accepted password for root"},
+		},
+		{
+			name:     "padding and append",
+			pattern:  "%{a->} %{+a} [%{b}]",
+			input:    "x    y [z]",
+			expected: map[string]string{"a": "x y", "b": "z"},
+		},
+		{
+			name:        "missing delimiter",
+			pattern:     "%{a} - %{b}",
+			input:       "no separator",
+			expectedErr: `delimiter " - " not found`,
+		},
+		{
+			name:        "missing prefix",
+			pattern:     "[%{a}]",
+			input:       "a]",
+			expectedErr: `missing prefix "["`,
+		},
+		{
+			name:        "trailing data",
+			pattern:     "%{a}]",
+			input:       "x] more",
+			expectedErr: `unexpected data after "]"`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			d, err := compileDissect(tc.pattern)
+			require.NoError(t, err)
+
+			got, err := d.parse(tc.input)
+			cstest.RequireErrorContains(t, err, tc.expectedErr)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
+
+func TestCompileDissectErrors(t *testing.T) {
+	_, err := compileDissect("no field")
+	cstest.RequireErrorContains(t, err, "the pattern has no %{field}")
+
+	_, err = compileDissect("%{a}%{b}")
+	cstest.RequireErrorContains(t, err, "no delimiter between %{a} and the next field")
+
+	_, err = compileDissect("%{a")
+	cstest.RequireErrorContains(t, err, "unterminated field")
+
+	_, err = compileDissect("%{+} x")
+	cstest.RequireErrorContains(t, err, "empty field name")
+}
+
+func TestStructuredPattern(t *testing.T) {
+	logger := log.NewEntry(log.New())
+
+	j := &JSONPattern{
+		StructuredPattern: StructuredPattern{
+			Prefix: "j_",
+			Fields: []string{"a.b", "n", "missing"},
+		},
+	}
+
+	rs, err := j.Compile(logger)
+	require.NoError(t, err)
+	assert.Equal(t, "Line.Raw", j.TargetField)
+	assert.Equal(t, []string{"j_a.b", "j_n", "j_missing"}, rs.Names())
+
+	got, err := rs.Parse(`{"a": {"b": "x", "c": "y"}, "n": 1.50, "z": null}`)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"j_a.b": "x", "j_n": "1.50"}, got)
+
+	for _, input := range []string{`[1, 2]`, `null`, `{"a": 1} trailing`, `not json`} {
+		_, err = rs.Parse(input)
+		require.Error(t, err, input)
+	}
+
+	c := &CSVPattern{
+		StructuredPattern: StructuredPattern{ExpValue: "evt.Parsed.message"},
+		Columns:           []string{"a", "", "c"},
+		Delimiter:         "\t",
+	}
+
+	rs, err = c.Compile(logger)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "c"}, rs.Names())
+
+	got, err = rs.Parse("1\t2\t3\t4")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"a": "1", "c": "3"}, got)
+
+	_, err = rs.Parse("1\t2")
+	cstest.RequireErrorContains(t, err, "2 values, expected 3")
+
+	_, err = (&CSVPattern{Columns: []string{"a"}, Delimiter: ",,"}).Compile(logger)
+	cstest.RequireErrorContains(t, err, `csv: invalid delimiter ",,"`)
+
+	_, err = (&CSVPattern{}).Compile(logger)
+	cstest.RequireErrorContains(t, err, "csv: 'columns' is required")
+
+	_, err = (&LogfmtPattern{StructuredPattern{TargetField: "message", ExpValue: "evt.Line.Raw"}}).Compile(logger)
+	cstest.RequireErrorContains(t, err, "logfmt: 'apply_on' and 'expression' are mutually exclusive")
+}
+
+func TestCompileStructuredExclusive(t *testing.T) {
+	n := &Node{
+		Logger: log.NewEntry(log.New()),
+		NodeConfig: NodeConfig{
+			JSON:   &JSONPattern{},
+			Logfmt: &LogfmtPattern{},
+		},
+	}
+
+	_, err := n.compileStructured()
+	cstest.RequireErrorContains(t, err, "json, logfmt can't be used in the same node")
+}
@@ -22,6 +22,9 @@ type UnixParserCtx struct {
 	Stages     []string
 	Profiling  bool
 	DataFolder string
+
+	// per event, set by Parse
+	prefilterCache *prefilterCache
 }
 
 type Parsers struct {
@@ -3,10 +3,13 @@ package parser
 import (
 	"fmt"
 	"net/netip"
+	"sync/atomic"
+	"time"
 
 	"github.com/expr-lang/expr"
 	"github.com/expr-lang/expr/vm"
 	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
 
 	"github.com/crowdsecurity/crowdsec/pkg/exprhelpers"
 	"github.com/crowdsecurity/crowdsec/pkg/metrics"
@@ -14,17 +17,74 @@ import (
 )
 
 type Whitelist struct {
-	Reason  string   `yaml:"reason,omitempty"`
-	Ips     []string `yaml:"ip,omitempty"`
-	B_Ips   []netip.Addr
-	Cidrs   []string `yaml:"cidr,omitempty"`
-	B_Cidrs []netip.Prefix
-	Exprs   []string `yaml:"expression,omitempty"`
-	B_Exprs []*ExprWhitelist
+	Reason  string           `yaml:"reason,omitempty"`
+	Ips     []string         `yaml:"ip,omitempty"`
+	Cidrs   []string         `yaml:"cidr,omitempty"`
+	Exprs   []string         `yaml:"expression,omitempty"`
+	Entries []WhitelistEntry `yaml:"entries,omitempty"`
+	// default expiration of the entries
+	ExpiresAt *time.Time `yaml:"expires_at,omitempty"`
+
+	B_Ips   []*WhitelistItem `yaml:"-"`
+	B_Cidrs []*WhitelistItem `yaml:"-"`
+	B_Exprs []*WhitelistItem `yaml:"-"`
+}
+
+// WhitelistEntry is a whitelist item with its own identifier, reported in the
+// metrics, and expiration. It has one of ip, cidr or expression.
+type WhitelistEntry struct {
+	ID         string     `yaml:"id,omitempty"`
+	IP         string     `yaml:"ip,omitempty"`
+	Cidr       string     `yaml:"cidr,omitempty"`
+	Expression string     `yaml:"expression,omitempty"`
+	ExpiresAt  *time.Time `yaml:"expires_at,omitempty"`
+}
+
+// WhitelistItem is a compiled whitelist entry. The items of the ip, cidr and
+// expression lists are identified by their value.
+type WhitelistItem struct {
+	ID        string
+	ExpiresAt time.Time // zero if it doesn't expire
+	Addr      netip.Addr
+	Prefix    netip.Prefix
+	Filter    *vm.Program
+	loadedAt  time.Time
+	lastHit   atomic.Int64 // unix nanoseconds
+	expired   atomic.Bool  // the expiration has been logged
+	stale     atomic.Bool  // the absence of hits has been logged
+}
+
+// active tells if the item has not expired yet.
+func (w *WhitelistItem) active(n *Node, now time.Time) bool {
+	if w.ExpiresAt.IsZero() || now.Before(w.ExpiresAt) {
+		return true
+	}
+
+	if w.expired.CompareAndSwap(false, true) {
+		n.Logger.Infof("whitelist entry %q of %s has expired, reason [%s]", w.ID, n.Name, n.Whitelist.Reason)
+	}
+
+	return false
+}
+
+func (w *WhitelistItem) hit(n *Node, now time.Time) {
+	w.lastHit.Store(now.UnixNano())
+	w.stale.Store(false)
+
+	labels := prometheus.Labels{"name": n.Name, "id": w.ID}
+
+	metrics.NodesWlEntryHits.With(labels).Inc()
+	metrics.NodesWlEntryLastHit.With(labels).Set(float64(now.Unix()))
 }
 
-type ExprWhitelist struct {
-	Filter *vm.Program
+// LastHit returns the time of the last event whitelisted by the item, zero if there was none.
+func (w *WhitelistItem) LastHit() time.Time {
+	ns := w.lastHit.Load()
+	if ns == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(0, ns)
 }
 
 func (n *Node) ContainsWLs() bool {
@@ -40,105 +100,187 @@ func (n *Node) ContainsIPLists() bool {
 }
 
 func (n *Node) CheckIPsWL(p *pipeline.Event) bool {
-	srcs := p.ParseIPSources()
-	isWhitelisted := false
 	if !n.ContainsIPLists() {
-		return isWhitelisted
+		return false
 	}
+
 	n.bumpWhitelistMetric(metrics.NodesWlHits, p)
-	for _, src := range srcs {
-		if isWhitelisted {
-			break
-		}
+
+	now := time.Now()
+
+	for _, src := range p.ParseIPSources() {
 		for _, v := range n.Whitelist.B_Ips {
-			if v == src {
-				n.Logger.Debugf("Event from [%s] is whitelisted by IP (%s), reason [%s]", src, v, n.Whitelist.Reason)
-				isWhitelisted = true
-				break
+			if v.Addr != src || !v.active(n, now) {
+				n.Logger.Tracef("whitelist: %s is not eq [%s]", src, v.Addr)
+				continue
 			}
-			n.Logger.Tracef("whitelist: %s is not eq [%s]", src, v)
+
+			n.Logger.Debugf("Event from [%s] is whitelisted by IP (%s), reason [%s]", src, v.ID, n.Whitelist.Reason)
+			v.hit(n, now)
+			n.bumpWhitelistMetric(metrics.NodesWlHitsOk, p)
+
+			return true
 		}
+
 		for _, v := range n.Whitelist.B_Cidrs {
-			if v.Contains(src) {
-				n.Logger.Debugf("Event from [%s] is whitelisted by CIDR (%s), reason [%s]", src, v, n.Whitelist.Reason)
-				isWhitelisted = true
-				break
+			if !v.Prefix.Contains(src) || !v.active(n, now) {
+				n.Logger.Tracef("whitelist: %s not in [%s]", src, v.Prefix)
+				continue
 			}
-			n.Logger.Tracef("whitelist: %s not in [%s]", src, v)
+
+			n.Logger.Debugf("Event from [%s] is whitelisted by CIDR (%s), reason [%s]", src, v.ID, n.Whitelist.Reason)
+			v.hit(n, now)
+			n.bumpWhitelistMetric(metrics.NodesWlHitsOk, p)
+
+			return true
 		}
 	}
-	if isWhitelisted {
-		n.bumpWhitelistMetric(metrics.NodesWlHitsOk, p)
-	}
-	return isWhitelisted
+
+	return false
 }
 
 func (n *Node) CheckExprWL(cachedExprEnv map[string]any, p *pipeline.Event) (bool, error) {
-	isWhitelisted := false
-
 	if !n.ContainsExprLists() {
 		return false, nil
 	}
+
 	n.bumpWhitelistMetric(metrics.NodesWlHits, p)
-	/* run whitelist expression tests anyway */
-	for eidx, e := range n.Whitelist.B_Exprs {
-		// if we already know the event is whitelisted, skip the rest of the expressions
-		if isWhitelisted {
-			break
+
+	now := time.Now()
+
+	for _, e := range n.Whitelist.B_Exprs {
+		if !e.active(n, now) {
+			continue
 		}
 
 		output, err := exprhelpers.Run(e.Filter, cachedExprEnv, n.Logger, n.Debug)
 		if err != nil {
 			n.Logger.Warningf("failed to run whitelist expr : %v", err)
 			n.Logger.Debug("Event leaving node : ko")
-			return isWhitelisted, err
+
+			return false, err
 		}
+
 		switch out := output.(type) {
 		case bool:
 			if out {
-				n.Logger.Debugf("Event is whitelisted by expr, reason [%s]", n.Whitelist.Reason)
-				isWhitelisted = true
+				n.Logger.Debugf("Event is whitelisted by expr (%s), reason [%s]", e.ID, n.Whitelist.Reason)
+				e.hit(n, now)
+				n.bumpWhitelistMetric(metrics.NodesWlHitsOk, p)
+
+				return true, nil
 			}
 		default:
-			n.Logger.Errorf("unexpected type %t (%v) while running '%s'", output, output, n.Whitelist.Exprs[eidx])
+			n.Logger.Errorf("unexpected type %t (%v) while running '%s'", output, output, e.ID)
 		}
 	}
-	if isWhitelisted {
-		n.bumpWhitelistMetric(metrics.NodesWlHitsOk, p)
-	}
-	return isWhitelisted, nil
+
+	return false, nil
 }
 
-func (n *Node) CompileWLs() (bool, error) {
-	for _, v := range n.Whitelist.Ips {
-		addr, err := netip.ParseAddr(v)
+func (n *Node) compileWLEntry(entry WhitelistEntry, now time.Time) error {
+	item := &WhitelistItem{ID: entry.ID, loadedAt: now}
+
+	expiresAt := entry.ExpiresAt
+	if expiresAt == nil {
+		expiresAt = n.Whitelist.ExpiresAt
+	}
+
+	if expiresAt != nil {
+		item.ExpiresAt = *expiresAt
+	}
+
+	switch {
+	case entry.IP != "" && entry.Cidr == "" && entry.Expression == "":
+		addr, err := netip.ParseAddr(entry.IP)
+		if err != nil {
+			return fmt.Errorf("parsing whitelist: %w", err)
+		}
+
+		item.Addr = addr
+		n.Whitelist.B_Ips = append(n.Whitelist.B_Ips, item)
+	case entry.Cidr != "" && entry.IP == "" && entry.Expression == "":
+		prefix, err := netip.ParsePrefix(entry.Cidr)
+		if err != nil {
+			return fmt.Errorf("parsing whitelist: %w", err)
+		}
+
+		item.Prefix = prefix
+		n.Whitelist.B_Cidrs = append(n.Whitelist.B_Cidrs, item)
+	case entry.Expression != "" && entry.IP == "" && entry.Cidr == "":
+		program, err := expr.Compile(entry.Expression, exprhelpers.GetExprOptions(map[string]any{"evt": &pipeline.Event{}})...)
 		if err != nil {
-			return false, fmt.Errorf("parsing whitelist: %w", err)
+			return fmt.Errorf("unable to compile whitelist expression '%s' : %v", entry.Expression, err)
+		}
+
+		item.Filter = program
+		n.Whitelist.B_Exprs = append(n.Whitelist.B_Exprs, item)
+	default:
+		return fmt.Errorf("whitelist entry %q: exactly one of ip, cidr or expression is required", entry.ID)
+	}
+
+	if item.ID == "" {
+		item.ID = entry.IP + entry.Cidr + entry.Expression
+	}
+
+	if !item.ExpiresAt.IsZero() {
+		labels := prometheus.Labels{"name": n.Name, "id": item.ID}
+		metrics.NodesWlEntryExpiry.With(labels).Set(float64(item.ExpiresAt.Unix()))
+
+		if !now.Before(item.ExpiresAt) {
+			n.Logger.Warningf("whitelist entry %q of %s expired on %s, it is ignored", item.ID, n.Name, item.ExpiresAt.Format(time.RFC3339))
+			item.expired.Store(true)
 		}
+	}
+
+	n.Logger.Debugf("adding %s to whitelists", item.ID)
+
+	return nil
+}
+
+func (n *Node) CompileWLs() (bool, error) {
+	n.Whitelist.B_Ips = nil
+	n.Whitelist.B_Cidrs = nil
+	n.Whitelist.B_Exprs = nil
 
-		n.Whitelist.B_Ips = append(n.Whitelist.B_Ips, addr)
-		n.Logger.Debugf("adding ip %s to whitelists", addr)
+	now := time.Now()
+
+	entries := make([]WhitelistEntry, 0, len(n.Whitelist.Ips)+len(n.Whitelist.Cidrs)+len(n.Whitelist.Exprs)+len(n.Whitelist.Entries))
+
+	for _, v := range n.Whitelist.Ips {
+		entries = append(entries, WhitelistEntry{IP: v})
 	}
 
 	for _, v := range n.Whitelist.Cidrs {
-		tnet, err := netip.ParsePrefix(v)
-		if err != nil {
-			return false, fmt.Errorf("parsing whitelist: %w", err)
+		entries = append(entries, WhitelistEntry{Cidr: v})
+	}
+
+	for _, v := range n.Whitelist.Exprs {
+		entries = append(entries, WhitelistEntry{Expression: v})
+	}
+
+	ids := make(map[string]bool)
+
+	for _, entry := range n.Whitelist.Entries {
+		if entry.ID == "" {
+			continue
 		}
-		n.Whitelist.B_Cidrs = append(n.Whitelist.B_Cidrs, tnet)
-		n.Logger.Debugf("adding cidr %s to whitelists", tnet)
+
+		if ids[entry.ID] {
+			return false, fmt.Errorf("duplicate whitelist entry id %q", entry.ID)
+		}
+
+		ids[entry.ID] = true
 	}
 
-	for _, filter := range n.Whitelist.Exprs {
-		var err error
-		expression := &ExprWhitelist{}
-		expression.Filter, err = expr.Compile(filter, exprhelpers.GetExprOptions(map[string]any{"evt": &pipeline.Event{}})...)
-		if err != nil {
-			return false, fmt.Errorf("unable to compile whitelist expression '%s' : %v", filter, err)
+	entries = append(entries, n.Whitelist.Entries...)
+
+	for _, entry := range entries {
+		if err := n.compileWLEntry(entry, now); err != nil {
+			return false, err
 		}
-		n.Whitelist.B_Exprs = append(n.Whitelist.B_Exprs, expression)
-		n.Logger.Debugf("adding expression %s to whitelists", filter)
 	}
+
 	return n.ContainsWLs(), nil
 }
 
@@ -160,3 +302,48 @@ func (n *Node) bumpWhitelistMetric(counter *prometheus.CounterVec, p *pipeline.E
 
 	counter.With(labels).Inc()
 }
+
+// whitelistItems calls fn for the whitelist items of the nodes and their children.
+func whitelistItems(nodes []Node, fn func(n *Node, item *WhitelistItem)) {
+	for idx := range nodes {
+		n := &nodes[idx]
+
+		for _, items := range [][]*WhitelistItem{n.Whitelist.B_Ips, n.Whitelist.B_Cidrs, n.Whitelist.B_Exprs} {
+			for _, item := range items {
+				fn(n, item)
+			}
+		}
+
+		whitelistItems(n.LeavesNodes, fn)
+	}
+}
+
+// WarnStaleWhitelists logs a warning for the whitelist entries that have not
+// whitelisted any event in the given period (since they were loaded if they
+// never did). An entry is reported once, until it matches again.
+func WarnStaleWhitelists(nodes []Node, staleAfter time.Duration, now time.Time) {
+	whitelistItems(nodes, func(n *Node, item *WhitelistItem) {
+		if item.expired.Load() {
+			return
+		}
+
+		since := item.LastHit()
+		if since.IsZero() {
+			since = item.loadedAt
+		}
+
+		if now.Sub(since) < staleAfter || !item.stale.CompareAndSwap(false, true) {
+			return
+		}
+
+		if item.LastHit().IsZero() {
+			log.Warningf("whitelist entry %q of %s has not matched any event since it was loaded, %s ago (reason [%s])",
+				item.ID, n.Name, now.Sub(since).Round(time.Second), n.Whitelist.Reason)
+
+			return
+		}
+
+		log.Warningf("whitelist entry %q of %s has not matched any event since %s (reason [%s])",
+			item.ID, n.Name, since.Format(time.RFC3339), n.Whitelist.Reason)
+	})
+}
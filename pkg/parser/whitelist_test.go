@@ -2,12 +2,19 @@ package parser
 
 import (
 	"testing"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	log "github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v2"
 
 	"github.com/crowdsecurity/go-cs-lib/cstest"
 
+	"github.com/crowdsecurity/crowdsec/pkg/metrics"
 	"github.com/crowdsecurity/crowdsec/pkg/models"
 	"github.com/crowdsecurity/crowdsec/pkg/pipeline"
 )
@@ -59,6 +66,44 @@ func TestWhitelistCompile(t *testing.T) {
 			},
 			expectedErr: "pipeline.Event has no field",
 		},
+		{
+			name: "Valid entries",
+			whitelist: Whitelist{
+				Reason: "test",
+				Entries: []WhitelistEntry{
+					{ID: "office", Cidr: "192.0.2.0/24"},
+					{IP: "192.0.2.1"},
+					{ID: "monitoring", Expression: "evt.Meta.source_ip == '192.0.2.2'"},
+				},
+			},
+		},
+		{
+			name: "Entry without value",
+			whitelist: Whitelist{
+				Reason:  "test",
+				Entries: []WhitelistEntry{{ID: "office"}},
+			},
+			expectedErr: `whitelist entry "office": exactly one of ip, cidr or expression is required`,
+		},
+		{
+			name: "Entry with two values",
+			whitelist: Whitelist{
+				Reason:  "test",
+				Entries: []WhitelistEntry{{ID: "office", IP: "192.0.2.1", Cidr: "192.0.2.0/24"}},
+			},
+			expectedErr: `whitelist entry "office": exactly one of ip, cidr or expression is required`,
+		},
+		{
+			name: "Duplicate entry id",
+			whitelist: Whitelist{
+				Reason: "test",
+				Entries: []WhitelistEntry{
+					{ID: "office", IP: "192.0.2.1"},
+					{ID: "office", IP: "192.0.2.2"},
+				},
+			},
+			expectedErr: `duplicate whitelist entry id "office"`,
+		},
 	}
 
 	for _, tt := range tests {
@@ -296,3 +341,113 @@ func TestWhitelistCheck(t *testing.T) {
 		})
 	}
 }
+
+func TestWhitelistEntries(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+
+	node := &Node{
+		NodeConfig: NodeConfig{
+			Name: "test/entries",
+			Whitelist: Whitelist{
+				Reason:    "test",
+				Ips:       []string{"192.0.2.1"},
+				ExpiresAt: &future,
+				Entries: []WhitelistEntry{
+					{ID: "expired", IP: "192.0.2.2", ExpiresAt: &past},
+					{ID: "office", Cidr: "198.51.100.0/24"},
+					{ID: "monitoring", Expression: "evt.Meta.source_ip == '203.0.113.1'"},
+				},
+			},
+		},
+		Logger: log.NewEntry(log.New()),
+	}
+
+	ok, err := node.CompileWLs()
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	require.Len(t, node.Whitelist.B_Ips, 2)
+	assert.Equal(t, "192.0.2.1", node.Whitelist.B_Ips[0].ID)
+	assert.Equal(t, future, node.Whitelist.B_Ips[0].ExpiresAt, "the whitelist expiration is the default")
+	assert.Equal(t, past, node.Whitelist.B_Ips[1].ExpiresAt)
+
+	event := func(ip string) *pipeline.Event {
+		return &pipeline.Event{Meta: map[string]string{"source_ip": ip}}
+	}
+
+	assert.True(t, node.CheckIPsWL(event("192.0.2.1")))
+	assert.False(t, node.CheckIPsWL(event("192.0.2.2")), "expired entries don't match")
+	assert.True(t, node.CheckIPsWL(event("198.51.100.7")))
+
+	evt := event("203.0.113.1")
+	ok, err = node.CheckExprWL(map[string]any{"evt": evt}, evt)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	assert.False(t, node.Whitelist.B_Ips[0].LastHit().IsZero())
+	assert.True(t, node.Whitelist.B_Ips[1].LastHit().IsZero())
+
+	labels := prometheus.Labels{"name": "test/entries", "id": "office"}
+	assert.InDelta(t, 1.0, testutil.ToFloat64(metrics.NodesWlEntryHits.With(labels)), 0)
+	assert.Positive(t, testutil.ToFloat64(metrics.NodesWlEntryLastHit.With(labels)))
+
+	labels = prometheus.Labels{"name": "test/entries", "id": "expired"}
+	assert.InDelta(t, float64(past.Unix()), testutil.ToFloat64(metrics.NodesWlEntryExpiry.With(labels)), 0)
+}
+
+func TestWhitelistEntryYaml(t *testing.T) {
+	var wl Whitelist
+
+	err := yaml.Unmarshal([]byte(`
+reason: test
+entries:
+  - id: partner
+    cidr: 192.0.2.0/24
+    expires_at: 2030-01-02T03:04:05Z
+`), &wl)
+	require.NoError(t, err)
+	require.Len(t, wl.Entries, 1)
+	require.NotNil(t, wl.Entries[0].ExpiresAt)
+	assert.Equal(t, time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC), wl.Entries[0].ExpiresAt.UTC())
+}
+
+func TestWarnStaleWhitelists(t *testing.T) {
+	hook := logtest.NewGlobal()
+
+	nodes := []Node{{
+		NodeConfig: NodeConfig{
+			Name: "test/stale",
+			Whitelist: Whitelist{
+				Reason: "test",
+				Entries: []WhitelistEntry{
+					{ID: "unused", IP: "192.0.2.1"},
+					{ID: "used", IP: "192.0.2.2"},
+				},
+			},
+		},
+		Logger: log.NewEntry(log.New()),
+	}}
+
+	_, err := nodes[0].CompileWLs()
+	require.NoError(t, err)
+
+	require.True(t, nodes[0].CheckIPsWL(&pipeline.Event{Meta: map[string]string{"source_ip": "192.0.2.2"}}))
+
+	now := time.Now()
+
+	WarnStaleWhitelists(nodes, time.Hour, now)
+	assert.Empty(t, hook.AllEntries())
+
+	later := now.Add(2 * time.Hour)
+
+	WarnStaleWhitelists(nodes, time.Hour, later)
+	require.Len(t, hook.AllEntries(), 2)
+	assert.Contains(t, hook.AllEntries()[0].Message, `whitelist entry "unused" of test/stale has not matched any event since it was loaded`)
+	assert.Contains(t, hook.AllEntries()[1].Message, `whitelist entry "used" of test/stale has not matched any event since`)
+
+	// reported only once
+	hook.Reset()
+	WarnStaleWhitelists(nodes, time.Hour, later)
+	assert.Empty(t, hook.AllEntries())
+}
@@ -0,0 +1,84 @@
+package tap
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+type StreamOptions struct {
+	Filter     string
+	Kind       string
+	BufferSize int // on the agent side, 0 for the agent's setting
+}
+
+// Stream subscribes to the tap of the agent listening on the socket, and calls
+// fn for each record until the context is canceled, the agent closes the
+// connection or fn returns an error.
+func Stream(ctx context.Context, socket string, opts StreamOptions, fn func(*Record) error) error {
+	var dialer net.Dialer
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return dialer.DialContext(ctx, "unix", socket)
+			},
+		},
+	}
+
+	query := url.Values{}
+
+	if opts.Filter != "" {
+		query.Set("filter", opts.Filter)
+	}
+
+	if opts.Kind != "" {
+		query.Set("kind", opts.Kind)
+	}
+
+	if opts.BufferSize > 0 {
+		query.Set("buffer", strconv.Itoa(opts.BufferSize))
+	}
+
+	// the host is ignored
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://tap"+eventsPath+"?"+query.Encode(), http.NoBody)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("connecting to %s (is the tap enabled in the agent?): %w", socket, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("tap: %s", strings.TrimSpace(string(body)))
+	}
+
+	dec := json.NewDecoder(resp.Body)
+
+	for {
+		var rec Record
+
+		if err := dec.Decode(&rec); err != nil {
+			if errors.Is(err, io.EOF) || ctx.Err() != nil {
+				return nil
+			}
+
+			return fmt.Errorf("reading from %s: %w", socket, err)
+		}
+
+		if err := fn(&rec); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package tap
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/crowdsecurity/crowdsec/pkg/csnet"
+)
+
+const eventsPath = "/v1/events"
+
+// Server streams the records of a hub on a unix socket, as JSON lines. The
+// socket is only accessible by the user running the agent.
+type Server struct {
+	hub        *Hub
+	socket     string
+	bufferSize int
+}
+
+func NewServer(hub *Hub, socket string, bufferSize int) *Server {
+	return &Server{
+		hub:        hub,
+		socket:     socket,
+		bufferSize: bufferSize,
+	}
+}
+
+// Run serves the subscribers until the context is canceled.
+func (s *Server) Run(ctx context.Context) error {
+	if err := os.Remove(s.socket); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("can't remove socket %s: %w", s.socket, err)
+	}
+
+	var lc net.ListenConfig
+
+	listener, err := lc.Listen(ctx, "unix", s.socket)
+	if err != nil {
+		return csnet.WrapSockErr(err, s.socket)
+	}
+
+	defer func() {
+		if err := os.Remove(s.socket); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			log.Errorf("can't remove socket %s: %s", s.socket, err)
+		}
+	}()
+
+	if err := os.Chmod(s.socket, 0o600); err != nil {
+		listener.Close()
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+eventsPath, s.handleEvents)
+
+	srv := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+		// the streams end with the context
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			log.Warningf("tap: shutting down: %s", err)
+		}
+	}()
+
+	log.Infof("event tap listening on Unix socket %s", s.socket)
+
+	if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
+
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	bufferSize := s.bufferSize
+
+	if v := query.Get("buffer"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			http.Error(w, "invalid buffer size "+v, http.StatusBadRequest)
+			return
+		}
+
+		// the agent's setting is the maximum
+		bufferSize = min(n, s.bufferSize)
+	}
+
+	sub, err := s.hub.Subscribe(query.Get("filter"), query.Get("kind"), bufferSize)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer sub.Close()
+
+	flusher, _ := w.(http.Flusher)
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+
+	if flusher != nil {
+		flusher.Flush()
+	}
+
+	log.Infof("tap: new subscriber (kind: %q, filter: %q)", query.Get("kind"), query.Get("filter"))
+
+	for {
+		select {
+		case <-r.Context().Done():
+			log.Info("tap: subscriber left")
+			return
+		case data, ok := <-sub.C():
+			if !ok || writeRecord(w, sub, data) != nil {
+				return
+			}
+
+			// write what's ready before flushing
+			for ready := true; ready; {
+				select {
+				case data, ok := <-sub.C():
+					if !ok || writeRecord(w, sub, data) != nil {
+						return
+					}
+				default:
+					ready = false
+				}
+			}
+
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+	}
+}
+
+// writeRecord writes a record, then how many have been dropped before it if any.
+func writeRecord(w http.ResponseWriter, sub *Subscription, data []byte) error {
+	if err := writeLine(w, data); err != nil {
+		return err
+	}
+
+	if dropped := sub.TakeDropped(); dropped > 0 {
+		notice, _ := json.Marshal(Record{Kind: KindDropped, Time: time.Now().UTC(), Dropped: dropped})
+		return writeLine(w, notice)
+	}
+
+	return nil
+}
+
+func writeLine(w http.ResponseWriter, data []byte) error {
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+
+	_, err := w.Write([]byte("\n"))
+
+	return err
+}
@@ -0,0 +1,222 @@
+// Package tap streams the events processed by a running agent to local
+// subscribers, for debugging. The pipeline never waits for a subscriber: the
+// records that don't fit in a subscriber's buffer are dropped and counted, and
+// they are filtered and serialized by the subscriber.
+package tap
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/vm"
+	"github.com/mohae/deepcopy"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/crowdsecurity/crowdsec/pkg/exprhelpers"
+	"github.com/crowdsecurity/crowdsec/pkg/parser"
+	"github.com/crowdsecurity/crowdsec/pkg/pipeline"
+)
+
+const (
+	KindParse   = "parse"   // after the parsers and the whitelists
+	KindPour    = "pour"    // after the event has been poured in the buckets
+	KindDropped = "dropped" // records lost because the subscriber was too slow
+)
+
+var Kinds = []string{KindParse, KindPour}
+
+type Record struct {
+	Kind            string              `json:"kind"`
+	Time            time.Time           `json:"time"`
+	Event           *pipeline.Event     `json:"event,omitempty"`
+	Parsed          bool                `json:"parsed,omitempty"` // the event went through all the stages
+	Nodes           []parser.NodeResult `json:"nodes,omitempty"`
+	Whitelisted     bool                `json:"whitelisted,omitempty"`
+	WhitelistReason string              `json:"whitelist_reason,omitempty"`
+	Buckets         []string            `json:"buckets,omitempty"` // the scenarios the event was poured into
+	Dropped         uint64              `json:"dropped,omitempty"`
+}
+
+// Hub dispatches the records to the subscribers whose filter matches.
+type Hub struct {
+	mu     sync.RWMutex
+	subs   map[*Subscription]struct{}
+	active atomic.Int32
+}
+
+func NewHub() *Hub {
+	return &Hub{
+		subs: make(map[*Subscription]struct{}),
+	}
+}
+
+// Active tells if there are subscribers: the records are expensive to build,
+// and should not be when nobody is listening.
+func (h *Hub) Active() bool {
+	return h.active.Load() > 0
+}
+
+type Subscription struct {
+	hub    *Hub
+	kind   string
+	filter *vm.Program
+	// the records published for the subscriber, then the ones that match, serialized
+	in      chan *Record
+	out     chan []byte
+	done    chan struct{}
+	dropped atomic.Uint64
+}
+
+// Subscribe returns a subscription to the records of the given kind (all if
+// empty) whose event matches the filter (all if empty). The expression has
+// access to the event as `evt`, and to the helpers without side effects.
+func (h *Hub) Subscribe(filter string, kind string, bufferSize int) (*Subscription, error) {
+	if kind != "" && !slices.Contains(Kinds, kind) {
+		return nil, fmt.Errorf("unknown kind %q, expected one of %v", kind, Kinds)
+	}
+
+	if bufferSize <= 0 {
+		return nil, fmt.Errorf("invalid buffer size %d", bufferSize)
+	}
+
+	s := &Subscription{
+		hub:  h,
+		kind: kind,
+		in:   make(chan *Record, bufferSize),
+		out:  make(chan []byte),
+		done: make(chan struct{}),
+	}
+
+	if filter != "" {
+		program, err := expr.Compile(filter,
+			append(exprhelpers.GetPureExprOptions(map[string]any{"evt": &pipeline.Event{}}), expr.AsBool())...)
+		if err != nil {
+			return nil, fmt.Errorf("invalid filter: %w", err)
+		}
+
+		s.filter = program
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.subs[s] = struct{}{}
+	h.active.Add(1)
+
+	go s.run()
+
+	return s, nil
+}
+
+// C receives the records, serialized to JSON. It's closed with the subscription.
+func (s *Subscription) C() <-chan []byte {
+	return s.out
+}
+
+// TakeDropped returns the number of records dropped since the previous call.
+func (s *Subscription) TakeDropped() uint64 {
+	return s.dropped.Swap(0)
+}
+
+func (s *Subscription) Close() {
+	h := s.hub
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.subs[s]; !ok {
+		return
+	}
+
+	delete(h.subs, s)
+	h.active.Add(-1)
+
+	// Publish holds the read lock while sending
+	close(s.in)
+	close(s.done)
+}
+
+// run filters and serializes the records, out of the pipeline.
+func (s *Subscription) run() {
+	defer close(s.out)
+
+	for rec := range s.in {
+		if !s.match(rec) {
+			continue
+		}
+
+		data, err := json.Marshal(rec)
+		if err != nil {
+			log.Errorf("tap: serializing %s record: %s", rec.Kind, err)
+			continue
+		}
+
+		select {
+		case s.out <- data:
+		case <-s.done:
+			return
+		}
+	}
+}
+
+func (s *Subscription) match(rec *Record) bool {
+	if s.filter == nil {
+		return true
+	}
+
+	out, err := expr.Run(s.filter, map[string]any{"evt": rec.Event})
+	if err != nil {
+		log.Debugf("tap filter: %s", err)
+		return false
+	}
+
+	ret, ok := out.(bool)
+
+	return ok && ret
+}
+
+// Publish hands the record to the subscribers of its kind, without blocking.
+// The event is copied, the caller can modify it afterwards.
+func (h *Hub) Publish(rec *Record) {
+	if !h.Active() {
+		return
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	var published *Record
+
+	for s := range h.subs {
+		if s.kind != "" && s.kind != rec.Kind {
+			continue
+		}
+
+		if published == nil {
+			published = copyRecord(rec)
+		}
+
+		select {
+		case s.in <- published:
+		default:
+			s.dropped.Add(1)
+		}
+	}
+}
+
+// copyRecord returns a record the subscribers can read while the pipeline goes on with the event.
+func copyRecord(rec *Record) *Record {
+	ret := *rec
+
+	if rec.Event != nil {
+		evt := deepcopy.Copy(*rec.Event).(pipeline.Event)
+		ret.Event = &evt
+	}
+
+	return &ret
+}
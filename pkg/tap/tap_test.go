@@ -0,0 +1,201 @@
+package tap
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/crowdsecurity/go-cs-lib/cstest"
+
+	"github.com/crowdsecurity/crowdsec/pkg/exprhelpers"
+	"github.com/crowdsecurity/crowdsec/pkg/parser"
+	"github.com/crowdsecurity/crowdsec/pkg/pipeline"
+)
+
+func testEvent(src string) *pipeline.Event {
+	evt := pipeline.MakeEvent(false, pipeline.LOG, true)
+	evt.Line.Src = src
+	evt.Line.Raw = "a log line from " + src
+
+	return &evt
+}
+
+func receive(t *testing.T, sub *Subscription) *Record {
+	t.Helper()
+
+	select {
+	case data := <-sub.C():
+		rec := &Record{}
+		require.NoError(t, json.Unmarshal(data, rec))
+
+		return rec
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "timeout waiting for a record")
+	}
+
+	return nil
+}
+
+func TestHubSubscribe(t *testing.T) {
+	require.NoError(t, exprhelpers.Init(nil))
+
+	hub := NewHub()
+	assert.False(t, hub.Active())
+
+	_, err := hub.Subscribe("", "nope", 10)
+	cstest.RequireErrorContains(t, err, `unknown kind "nope"`)
+
+	_, err = hub.Subscribe(`evt.Line.Src`, "", 10)
+	cstest.RequireErrorContains(t, err, "invalid filter")
+
+	// no I/O in the filters
+	_, err = hub.Subscribe(`HTTPGet("http://example.com") != ""`, "", 10)
+	cstest.RequireErrorContains(t, err, "invalid filter")
+
+	sub, err := hub.Subscribe(`evt.Line.Src == "/var/log/auth.log"`, KindParse, 10)
+	require.NoError(t, err)
+	assert.True(t, hub.Active())
+
+	hub.Publish(&Record{Kind: KindParse, Event: testEvent("/var/log/syslog")})
+	hub.Publish(&Record{Kind: KindPour, Event: testEvent("/var/log/auth.log")})
+
+	evt := testEvent("/var/log/auth.log")
+	hub.Publish(&Record{
+		Kind:   KindParse,
+		Event:  evt,
+		Parsed: true,
+		Nodes:  []parser.NodeResult{{Stage: "s01-parse", Node: "crowdsecurity/sshd-logs", Success: true}},
+	})
+
+	// the pipeline goes on with the event
+	evt.Line.Src = "/var/log/syslog"
+
+	rec := receive(t, sub)
+	assert.Equal(t, KindParse, rec.Kind)
+	assert.True(t, rec.Parsed)
+	assert.Equal(t, "/var/log/auth.log", rec.Event.Line.Src)
+	assert.Equal(t, "crowdsecurity/sshd-logs", rec.Nodes[0].Node)
+
+	sub.Close()
+	sub.Close()
+	assert.False(t, hub.Active())
+
+	_, ok := <-sub.C()
+	assert.False(t, ok)
+}
+
+func TestHubDrop(t *testing.T) {
+	require.NoError(t, exprhelpers.Init(nil))
+
+	hub := NewHub()
+
+	slow, err := hub.Subscribe("", "", 2)
+	require.NoError(t, err)
+
+	fast, err := hub.Subscribe("", "", 10)
+	require.NoError(t, err)
+
+	for range 5 {
+		hub.Publish(&Record{Kind: KindPour, Event: testEvent("/var/log/auth.log"), Buckets: []string{"crowdsecurity/ssh-bf"}})
+	}
+
+	// the publisher doesn't wait: the records that don't fit in the buffer are dropped
+	fastRecords := 0
+
+	for range 5 {
+		receive(t, fast)
+
+		fastRecords++
+	}
+
+	assert.Equal(t, 5, fastRecords)
+	assert.Equal(t, uint64(0), fast.TakeDropped())
+
+	dropped := slow.TakeDropped()
+	assert.GreaterOrEqual(t, dropped, uint64(2))
+
+	for range 5 - dropped {
+		receive(t, slow)
+	}
+
+	assert.Equal(t, uint64(0), slow.TakeDropped())
+
+	slow.Close()
+	fast.Close()
+}
+
+func TestServer(t *testing.T) {
+	require.NoError(t, exprhelpers.Init(nil))
+
+	// t.TempDir() can exceed the maximum length of a socket path
+	dir, err := os.MkdirTemp("", "tap")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	socket := filepath.Join(dir, "tap.sock")
+	hub := NewHub()
+
+	ctx, cancel := context.WithCancel(t.Context())
+
+	done := make(chan error)
+
+	go func() {
+		done <- NewServer(hub, socket, 100).Run(ctx)
+	}()
+
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(socket)
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	info, err := os.Stat(socket)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	err = Stream(ctx, socket, StreamOptions{Filter: "evt.Line.Src"}, func(*Record) error { return nil })
+	cstest.RequireErrorContains(t, err, "invalid filter")
+
+	// publish until the subscriber is connected
+	go func() {
+		for ctx.Err() == nil {
+			hub.Publish(&Record{Kind: KindParse, Event: testEvent("/var/log/syslog")})
+			hub.Publish(&Record{Kind: KindParse, Event: testEvent("/var/log/auth.log"), Whitelisted: true, WhitelistReason: "private ip"})
+			time.Sleep(10 * time.Millisecond)
+		}
+	}()
+
+	errStop := errors.New("stop")
+
+	var got []*Record
+
+	err = Stream(ctx, socket, StreamOptions{Filter: `evt.Line.Src endsWith "auth.log"`, BufferSize: 1000}, func(rec *Record) error {
+		got = append(got, rec)
+		if len(got) == 3 {
+			return errStop
+		}
+
+		return nil
+	})
+	require.ErrorIs(t, err, errStop)
+
+	for _, rec := range got {
+		assert.Equal(t, "/var/log/auth.log", rec.Event.Line.Src)
+		assert.True(t, rec.Whitelisted)
+		assert.Equal(t, "private ip", rec.WhitelistReason)
+	}
+
+	// the subscription ends with the connection
+	require.Eventually(t, func() bool { return !hub.Active() }, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+
+	assert.NoFileExists(t, socket)
+}
@@ -0,0 +1,86 @@
+package types
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Permission is an operation on the local API that can be granted to a machine by its roles.
+type Permission string
+
+const (
+	PermAlertsCreate    Permission = "alerts:create"
+	PermAlertsRead      Permission = "alerts:read"
+	PermAlertsDelete    Permission = "alerts:delete"
+	PermDecisionsDelete Permission = "decisions:delete"
+	PermAllowlistsRead  Permission = "allowlists:read"
+)
+
+const (
+	// RoleAdmin has all the permissions. It's also the role of the machines without roles.
+	RoleAdmin = "admin"
+	// RoleAgent can only push alerts: it's enough for a log processor.
+	RoleAgent = "agent"
+	// RoleViewer has read-only access to the alerts.
+	RoleViewer = "viewer"
+	// RoleDecisionAdmin can read alerts, add decisions (as alerts) and delete decisions, but not delete alerts.
+	RoleDecisionAdmin = "decision-admin"
+)
+
+var rolePermissions = map[string][]Permission{
+	RoleAdmin: {
+		PermAlertsCreate,
+		PermAlertsRead,
+		PermAlertsDelete,
+		PermDecisionsDelete,
+		PermAllowlistsRead,
+	},
+	RoleAgent: {
+		PermAlertsCreate,
+		PermAllowlistsRead,
+	},
+	RoleViewer: {
+		PermAlertsRead,
+		PermAllowlistsRead,
+	},
+	RoleDecisionAdmin: {
+		PermAlertsCreate,
+		PermAlertsRead,
+		PermDecisionsDelete,
+		PermAllowlistsRead,
+	},
+}
+
+// Roles returns the names of the machine roles.
+func Roles() []string {
+	return slices.Sorted(maps.Keys(rolePermissions))
+}
+
+// ValidateRoles returns an error if a role is unknown.
+func ValidateRoles(roles []string) error {
+	for _, role := range roles {
+		if _, ok := rolePermissions[role]; !ok {
+			return fmt.Errorf("unknown role '%s' (valid roles: %s)", role, strings.Join(Roles(), ", "))
+		}
+	}
+
+	return nil
+}
+
+// RolesPermit returns true if one of the roles grants the permission.
+// Machines without roles have all the permissions.
+func RolesPermit(roles []string, perm Permission) bool {
+	if len(roles) == 0 {
+		return true
+	}
+
+	for _, role := range roles {
+		if slices.Contains(rolePermissions[role], perm) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,31 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/crowdsecurity/go-cs-lib/cstest"
+)
+
+func TestRolesPermit(t *testing.T) {
+	// machines created before roles existed
+	assert.True(t, RolesPermit(nil, PermDecisionsDelete))
+
+	assert.True(t, RolesPermit([]string{RoleAdmin}, PermAlertsDelete))
+	assert.True(t, RolesPermit([]string{RoleAgent}, PermAlertsCreate))
+	assert.False(t, RolesPermit([]string{RoleAgent}, PermAlertsRead))
+	assert.False(t, RolesPermit([]string{RoleAgent}, PermDecisionsDelete))
+	assert.False(t, RolesPermit([]string{RoleViewer}, PermAlertsCreate))
+	assert.True(t, RolesPermit([]string{RoleDecisionAdmin}, PermDecisionsDelete))
+	assert.False(t, RolesPermit([]string{RoleDecisionAdmin}, PermAlertsDelete))
+	assert.True(t, RolesPermit([]string{RoleAgent, RoleViewer}, PermAlertsRead))
+	assert.False(t, RolesPermit([]string{"unknown"}, PermAlertsRead))
+}
+
+func TestValidateRoles(t *testing.T) {
+	assert.Equal(t, []string{"admin", "agent", "decision-admin", "viewer"}, Roles())
+
+	cstest.RequireErrorContains(t, ValidateRoles([]string{RoleAgent, RoleViewer}), "")
+	cstest.RequireErrorContains(t, ValidateRoles([]string{"root"}), "unknown role 'root' (valid roles: admin, agent, decision-admin, viewer)")
+}
@@ -229,3 +229,31 @@ teardown() {
     rune -0 ./instance-db exec_sql "UPDATE decisions SET ... WHERE id=${DECISION_ID}"
     ./instance-crowdsec start
 }
+
+@test "cscli alerts list (pages)" {
+    rune -0 cscli alerts delete --all
+    rune -0 cscli decisions add -i 1.2.3.4 -d 1h -R crowdsecurity/test
+    rune -0 cscli decisions add -i 1.2.3.5 -d 1h -R crowdsecurity/test
+
+    # one page: the json output is a list
+    rune -0 cscli alerts list -l 2 -o json
+    rune -0 jq -r 'type' <(output)
+    assert_output "array"
+
+    rune -0 cscli alerts list -l 1 -o json
+    rune -0 jq -r '.next_cursor' <(output)
+    refute_output ""
+    CURSOR="$output"
+
+    rune -0 cscli alerts list -l 1 -o raw
+    assert_stderr --partial "use --cursor $CURSOR for the next page"
+
+    # a full page may be followed by an empty one
+    rune -0 cscli alerts list -l 1 --cursor "$CURSOR" -o json
+    rune -0 jq -r '.next_cursor' <(output)
+    CURSOR="$output"
+
+    rune -0 cscli alerts list -l 1 --cursor "$CURSOR" -o json
+    rune -0 jq -c '[(.alerts | length), .next_cursor]' <(output)
+    assert_json '[0,""]'
+}